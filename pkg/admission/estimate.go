@@ -0,0 +1,160 @@
+package admission
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/opencost/opencost/pkg/cloud/models"
+	"github.com/opencost/opencost/pkg/util/json"
+	"github.com/opencost/opencost/pkg/util/timeutil"
+)
+
+// gpuResource is the extended resource requested for NVIDIA GPUs
+const gpuResource v1.ResourceName = "nvidia.com/gpu"
+
+// Pricing is the average hourly price of each resource across the cluster's
+// nodes, used to estimate the cost of workloads before they are scheduled.
+type Pricing struct {
+	CPUHourly    float64 `json:"cpuHourly"`
+	RAMGiBHourly float64 `json:"ramGiBHourly"`
+	GPUHourly    float64 `json:"gpuHourly"`
+}
+
+// AveragePricing averages the prices of the nodes. Resources with no priced
+// nodes fall back to the custom pricing defaults.
+func AveragePricing(nodes map[string]*models.Node, defaults *models.CustomPricing) *Pricing {
+	var cpu, ram, gpu []float64
+	for _, node := range nodes {
+		if c, ok := parsePrice(node.VCPUCost); ok {
+			cpu = append(cpu, c)
+		}
+		if r, ok := parsePrice(node.RAMCost); ok {
+			ram = append(ram, r)
+		}
+		if g, ok := parsePrice(node.GPUCost); ok && node.GPU != "" && node.GPU != "0" {
+			gpu = append(gpu, g)
+		}
+	}
+
+	pricing := &Pricing{
+		CPUHourly:    average(cpu),
+		RAMGiBHourly: average(ram),
+		GPUHourly:    average(gpu),
+	}
+	if defaults != nil {
+		if len(cpu) == 0 {
+			pricing.CPUHourly, _ = parsePrice(defaults.CPU)
+		}
+		if len(ram) == 0 {
+			pricing.RAMGiBHourly, _ = parsePrice(defaults.RAM)
+		}
+		if len(gpu) == 0 {
+			pricing.GPUHourly, _ = parsePrice(defaults.GPU)
+		}
+	}
+	return pricing
+}
+
+func parsePrice(s string) (float64, bool) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) || f < 0 {
+		return 0, false
+	}
+	return f, true
+}
+
+func average(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// Estimate is the estimated cost of a workload from its requests.
+type Estimate struct {
+	Replicas int32 `json:"replicas"`
+
+	// CPU, RAMBytes and GPU are the requests of all replicas combined.
+	CPU      float64 `json:"cpu"`
+	RAMBytes float64 `json:"ramBytes"`
+	GPU      float64 `json:"gpu"`
+
+	HourlyCost  float64 `json:"hourlyCost"`
+	MonthlyCost float64 `json:"monthlyCost"`
+}
+
+// EstimateWorkload estimates the cost of a Deployment or StatefulSet encoded
+// as JSON. A nil estimate is returned for other kinds.
+func EstimateWorkload(kind string, raw []byte, pricing *Pricing) (*Estimate, error) {
+	var replicas *int32
+	var spec *v1.PodSpec
+
+	switch kind {
+	case "Deployment":
+		d := &appsv1.Deployment{}
+		if err := json.Unmarshal(raw, d); err != nil {
+			return nil, fmt.Errorf("decoding deployment: %w", err)
+		}
+		replicas, spec = d.Spec.Replicas, &d.Spec.Template.Spec
+	case "StatefulSet":
+		ss := &appsv1.StatefulSet{}
+		if err := json.Unmarshal(raw, ss); err != nil {
+			return nil, fmt.Errorf("decoding statefulset: %w", err)
+		}
+		replicas, spec = ss.Spec.Replicas, &ss.Spec.Template.Spec
+	default:
+		return nil, nil
+	}
+
+	// replicas default to one when unset
+	n := int32(1)
+	if replicas != nil {
+		n = *replicas
+	}
+
+	requests := podRequests(spec)
+	e := &Estimate{
+		Replicas: n,
+		CPU:      float64(n) * requests.Cpu().AsApproximateFloat64(),
+		RAMBytes: float64(n) * requests.Memory().AsApproximateFloat64(),
+	}
+	if gpu, ok := requests[gpuResource]; ok {
+		e.GPU = float64(n) * gpu.AsApproximateFloat64()
+	}
+
+	e.HourlyCost = e.CPU*pricing.CPUHourly + e.RAMBytes/1024/1024/1024*pricing.RAMGiBHourly + e.GPU*pricing.GPUHourly
+	e.MonthlyCost = e.HourlyCost * timeutil.HoursPerMonth
+	return e, nil
+}
+
+// podRequests returns the effective requests of a pod: for each resource, the
+// greater of the sum of its containers' requests and the largest request of
+// its init containers, which run one at a time.
+func podRequests(spec *v1.PodSpec) v1.ResourceList {
+	requests := v1.ResourceList{}
+	for _, c := range spec.Containers {
+		for name, q := range c.Resources.Requests {
+			total := requests[name]
+			total.Add(q)
+			requests[name] = total
+		}
+	}
+
+	for _, c := range spec.InitContainers {
+		for name, q := range c.Resources.Requests {
+			if total, ok := requests[name]; !ok || q.Cmp(total) > 0 {
+				requests[name] = q.DeepCopy()
+			}
+		}
+	}
+
+	return requests
+}
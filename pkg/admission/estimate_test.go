@@ -0,0 +1,56 @@
+package admission
+
+import (
+	"math"
+	"testing"
+
+	"github.com/opencost/opencost/pkg/cloud/models"
+)
+
+func TestEstimateWorkload(t *testing.T) {
+	pricing := AveragePricing(map[string]*models.Node{
+		"node1": {VCPUCost: "0.02", RAMCost: "0.004"},
+		"node2": {VCPUCost: "0.04", RAMCost: "0.004"},
+	}, &models.CustomPricing{GPU: "0.95"})
+
+	if math.Abs(pricing.CPUHourly-0.03) > 1e-9 || pricing.GPUHourly != 0.95 {
+		t.Fatalf("unexpected pricing %+v", pricing)
+	}
+
+	deployment := []byte(`{
+		"apiVersion": "apps/v1",
+		"kind": "Deployment",
+		"metadata": {"name": "web", "namespace": "default"},
+		"spec": {
+			"replicas": 3,
+			"template": {
+				"spec": {
+					"initContainers": [{"name": "init", "resources": {"requests": {"cpu": "2"}}}],
+					"containers": [
+						{"name": "app", "resources": {"requests": {"cpu": "500m", "memory": "1Gi"}}},
+						{"name": "sidecar", "resources": {"requests": {"cpu": "250m", "memory": "1Gi", "nvidia.com/gpu": "1"}}}
+					]
+				}
+			}
+		}
+	}`)
+
+	e, err := EstimateWorkload("Deployment", deployment, pricing)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// the init container's request exceeds the containers' combined CPU
+	if e.CPU != 6 || e.RAMBytes != 6*1024*1024*1024 || e.GPU != 3 {
+		t.Fatalf("unexpected requests %+v", e)
+	}
+
+	expected := 6*0.03 + 6*0.004 + 3*0.95
+	if math.Abs(e.HourlyCost-expected) > 1e-9 {
+		t.Errorf("expected hourly cost %f; got %f", expected, e.HourlyCost)
+	}
+
+	if e, err := EstimateWorkload("DaemonSet", []byte(`{}`), pricing); err != nil || e != nil {
+		t.Errorf("expected no estimate for other kinds; got %v, %v", e, err)
+	}
+}
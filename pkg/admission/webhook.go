@@ -0,0 +1,326 @@
+package admission
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/opencost/opencost/pkg/cloud/models"
+	"github.com/opencost/opencost/pkg/errors"
+	"github.com/opencost/opencost/pkg/exporter"
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/log"
+	"github.com/opencost/opencost/pkg/util/atomic"
+	"github.com/opencost/opencost/pkg/util/json"
+)
+
+// Annotations set on admitted workloads
+const (
+	MonthlyCostAnnotation = "opencost.io/estimated-monthly-cost"
+	HourlyCostAnnotation  = "opencost.io/estimated-hourly-cost"
+)
+
+// CostSource provides the node pricing and namespace costs used to estimate
+// and admit workloads. It is implemented by the CostModel.
+type CostSource interface {
+	GetNodeCost(cp models.Provider) (map[string]*models.Node, error)
+	ComputeAllocation(start, end time.Time, resolution time.Duration) (*kubecost.AllocationSet, error)
+}
+
+// WebhookConfig contains the options of a Webhook.
+type WebhookConfig struct {
+	// ClusterID is the cluster budgets are matched against.
+	ClusterID string
+
+	// Budgets, if set, are enforced by the validating webhook: a workload is
+	// rejected if its estimated cost would raise its namespace's projected
+	// spend above a budget selecting it.
+	Budgets []*exporter.Budget
+
+	// Window is the trailing window whose namespace cost rates are projected.
+	Window time.Duration
+
+	// Resolution is the query resolution used to compute allocations.
+	Resolution time.Duration
+
+	// RefreshInterval is how often pricing and namespace costs are refreshed.
+	RefreshInterval time.Duration
+}
+
+// Webhook is a mutating and validating admission webhook estimating the cost
+// of incoming Deployments and StatefulSets from their requests and the
+// current node pricing. The mutating webhook annotates each workload with its
+// estimate; the validating webhook rejects workloads exceeding a namespace
+// budget. Pricing and namespace costs are refreshed in the background, so that
+// admission never waits on the cost model.
+type Webhook struct {
+	source   CostSource
+	provider models.Provider
+	config   *WebhookConfig
+
+	lock    sync.RWMutex
+	pricing *Pricing
+	gauges  map[string]*exporter.CostGauge
+
+	runState atomic.AtomicRunState
+
+	now func() time.Time
+}
+
+// NewWebhook creates a new Webhook. Start must be called to refresh pricing
+// and namespace costs before workloads are estimated.
+func NewWebhook(source CostSource, provider models.Provider, config *WebhookConfig) *Webhook {
+	return &Webhook{
+		source:   source,
+		provider: provider,
+		config:   config,
+		now:      time.Now,
+	}
+}
+
+// Start begins refreshing pricing and namespace costs on the configured
+// interval. Returns false if the webhook is already refreshing.
+func (wh *Webhook) Start() bool {
+	wh.runState.WaitForReset()
+	if !wh.runState.Start() {
+		log.Warnf("Admission: attempted to start refreshing when already running")
+		return false
+	}
+
+	go func() {
+		defer errors.HandlePanic()
+
+		for {
+			if err := wh.refresh(); err != nil {
+				log.Errorf("Admission: refreshing pricing: %s", err)
+			}
+
+			select {
+			case <-wh.runState.OnStop():
+				wh.runState.Reset()
+				return
+			case <-time.After(wh.config.RefreshInterval):
+			}
+		}
+	}()
+
+	return true
+}
+
+// Stop halts the refresh loop.
+func (wh *Webhook) Stop() {
+	wh.runState.Stop()
+}
+
+// cached returns the pricing and namespace gauges of the last refresh.
+func (wh *Webhook) cached() (*Pricing, map[string]*exporter.CostGauge, error) {
+	wh.lock.RLock()
+	defer wh.lock.RUnlock()
+
+	if wh.pricing == nil {
+		return nil, nil, fmt.Errorf("pricing has not been refreshed yet")
+	}
+	return wh.pricing, wh.gauges, nil
+}
+
+// refresh recomputes the pricing and namespace gauges, replacing the cached
+// values only if both are computed.
+func (wh *Webhook) refresh() error {
+	now := wh.now()
+
+	nodes, err := wh.source.GetNodeCost(wh.provider)
+	if err != nil {
+		return fmt.Errorf("getting node pricing: %w", err)
+	}
+	defaults, _ := wh.provider.GetConfig()
+	pricing := AveragePricing(nodes, defaults)
+
+	gauges := make(map[string]*exporter.CostGauge)
+	if len(wh.config.Budgets) > 0 {
+		end := now.UTC().Truncate(wh.config.Resolution)
+		as, err := wh.source.ComputeAllocation(end.Add(-wh.config.Window), end, wh.config.Resolution)
+		if err != nil {
+			return fmt.Errorf("computing allocations: %w", err)
+		}
+		for _, gauge := range exporter.CostGaugesFor(as) {
+			if gauge.Level == exporter.CostGaugeLevelNamespace && gauge.Cluster == wh.config.ClusterID {
+				gauges[gauge.Namespace] = gauge
+			}
+		}
+	}
+
+	wh.lock.Lock()
+	wh.pricing, wh.gauges = pricing, gauges
+	wh.lock.Unlock()
+
+	return nil
+}
+
+// Mutate annotates Deployments and StatefulSets with their estimated cost.
+func (wh *Webhook) Mutate(w http.ResponseWriter, r *http.Request) {
+	wh.serve(w, r, func(req *admissionv1.AdmissionRequest) (*admissionv1.AdmissionResponse, error) {
+		pricing, _, err := wh.cached()
+		if err != nil {
+			return nil, err
+		}
+
+		estimate, err := EstimateWorkload(req.Kind.Kind, req.Object.Raw, pricing)
+		if err != nil || estimate == nil {
+			return allowed(req), err
+		}
+
+		patch, err := annotationPatch(req.Object.Raw, map[string]string{
+			MonthlyCostAnnotation: fmt.Sprintf("%.2f", estimate.MonthlyCost),
+			HourlyCostAnnotation:  fmt.Sprintf("%.4f", estimate.HourlyCost),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		resp := allowed(req)
+		patchType := admissionv1.PatchTypeJSONPatch
+		resp.Patch = patch
+		resp.PatchType = &patchType
+		return resp, nil
+	})
+}
+
+// Validate rejects Deployments and StatefulSets whose estimated cost would
+// raise their namespace's projected spend above a budget selecting it. For
+// updates, the estimate of the previous version is replaced.
+func (wh *Webhook) Validate(w http.ResponseWriter, r *http.Request) {
+	wh.serve(w, r, func(req *admissionv1.AdmissionRequest) (*admissionv1.AdmissionResponse, error) {
+		if len(wh.config.Budgets) == 0 {
+			return allowed(req), nil
+		}
+
+		pricing, gauges, err := wh.cached()
+		if err != nil {
+			return nil, err
+		}
+
+		estimate, err := EstimateWorkload(req.Kind.Kind, req.Object.Raw, pricing)
+		if err != nil || estimate == nil {
+			return allowed(req), err
+		}
+
+		hourly := estimate.HourlyCost
+		if len(req.OldObject.Raw) > 0 {
+			if old, err := EstimateWorkload(req.Kind.Kind, req.OldObject.Raw, pricing); err == nil && old != nil {
+				hourly -= old.HourlyCost
+			}
+		}
+
+		gauge, ok := gauges[req.Namespace]
+		if !ok {
+			gauge = &exporter.CostGauge{
+				Level:     exporter.CostGaugeLevelNamespace,
+				Cluster:   wh.config.ClusterID,
+				Namespace: req.Namespace,
+			}
+		}
+
+		for _, budget := range wh.config.Budgets {
+			if !budget.Matches(gauge) {
+				continue
+			}
+
+			projected := (gauge.TotalCost + hourly) * budget.Period.Hours()
+			if projected > budget.Limit {
+				resp := allowed(req)
+				resp.Allowed = false
+				resp.Result = &metav1.Status{
+					Code: http.StatusForbidden,
+					Message: fmt.Sprintf("%s %s/%s is estimated to cost %.2f per month, raising namespace %s to %.2f per %s and exceeding budget %s of %.2f",
+						req.Kind.Kind, req.Namespace, req.Name, estimate.MonthlyCost, req.Namespace, projected, budget.Period, budget.Name, budget.Limit),
+				}
+				return resp, nil
+			}
+		}
+
+		return allowed(req), nil
+	})
+}
+
+func allowed(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{
+		UID:     req.UID,
+		Allowed: true,
+	}
+}
+
+// serve decodes an AdmissionReview and responds with the result of review. A
+// review error is logged and the request allowed, so that an unavailable
+// cost model never blocks deployments.
+func (wh *Webhook) serve(w http.ResponseWriter, r *http.Request, review func(*admissionv1.AdmissionRequest) (*admissionv1.AdmissionResponse, error)) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("reading request: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	ar := &admissionv1.AdmissionReview{}
+	if err := json.Unmarshal(body, ar); err != nil || ar.Request == nil {
+		http.Error(w, "invalid AdmissionReview", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := review(ar.Request)
+	if err != nil {
+		log.Warnf("Admission: allowing %s %s/%s: %s", ar.Request.Kind.Kind, ar.Request.Namespace, ar.Request.Name, err)
+		resp = allowed(ar.Request)
+	}
+
+	ar.Response = resp
+	ar.Request = nil
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(ar); err != nil {
+		log.Errorf("Admission: writing response: %s", err)
+	}
+}
+
+// annotationPatch returns a JSON patch setting the annotations on the object.
+func annotationPatch(raw []byte, annotations map[string]string) ([]byte, error) {
+	obj := &metav1.PartialObjectMetadata{}
+	if err := json.Unmarshal(raw, obj); err != nil {
+		return nil, fmt.Errorf("decoding object metadata: %w", err)
+	}
+
+	type operation struct {
+		Op    string      `json:"op"`
+		Path  string      `json:"path"`
+		Value interface{} `json:"value"`
+	}
+
+	if len(obj.Annotations) == 0 {
+		return json.Marshal([]operation{{Op: "add", Path: "/metadata/annotations", Value: annotations}})
+	}
+
+	var ops []operation
+	for k, v := range annotations {
+		ops = append(ops, operation{Op: "add", Path: "/metadata/annotations/" + escapeJSONPointer(k), Value: v})
+	}
+	return json.Marshal(ops)
+}
+
+// escapeJSONPointer escapes a JSON pointer reference token per RFC 6901.
+func escapeJSONPointer(s string) string {
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '~':
+			b = append(b, '~', '0')
+		case '/':
+			b = append(b, '~', '1')
+		default:
+			b = append(b, s[i])
+		}
+	}
+	return string(b)
+}
@@ -4,12 +4,15 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/sha256"
 	"encoding/csv"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -184,6 +187,9 @@ type AWS struct {
 	ClusterRegion               string
 	ClusterAccountID            string
 	clusterProvisioner          string
+	instanceTypes               map[string]*models.InstanceType
+	pricingDataset              *models.PricingDataset
+	spotPricingDataset          *models.PricingDataset
 }
 
 // AWSAccessKey holds AWS credentials and fulfils the awsV2.CredentialsProvider interface
@@ -248,6 +254,9 @@ type AWSProductAttributes struct {
 	InstanceFamily  string `json:"instanceFamily"`
 	CapacityStatus  string `json:"capacitystatus"`
 	GPU             string `json:"gpu"` // GPU represents the number of GPU on the instance
+
+	// PhysicalProcessor is the processor model, e.g. "AWS Graviton2 Processor"
+	PhysicalProcessor string `json:"physicalProcessor"`
 }
 
 // AWSPricingTerms are how you pay for the node: OnDemand, Reserved, or (TODO) Spot
@@ -467,6 +476,9 @@ func (aws *AWS) GetConfig() (*models.CustomPricing, error) {
 	if c.ShareTenancyCosts == "" {
 		c.ShareTenancyCosts = models.DefaultShareTenancyCost
 	}
+	if c.PublicIPHourlyCost == "" {
+		c.PublicIPHourlyCost = strconv.FormatFloat(AWSHourlyPublicIPCost, 'f', -1, 64)
+	}
 
 	return c, nil
 }
@@ -944,8 +956,12 @@ func (aws *AWS) DownloadPricingData() error {
 
 func (aws *AWS) populatePricing(resp *http.Response, inputkeys map[string]bool) error {
 	aws.Pricing = make(map[string]*AWSProductTerms)
+	aws.instanceTypes = make(map[string]*models.InstanceType)
 	skusToKeys := make(map[string]string)
-	dec := json.NewDecoder(resp.Body)
+	// The pricing file is checksummed as it is decoded, to identify the
+	// version of the prices it was populated from.
+	h := sha256.New()
+	dec := json.NewDecoder(io.TeeReader(resp.Body, h))
 	for {
 		t, err := dec.Token()
 		if err == io.EOF {
@@ -992,6 +1008,11 @@ func (aws *AWS) populatePricing(resp *http.Response, inputkeys map[string]bool)
 					}
 					aws.ValidPricingKeys[key] = true
 					aws.ValidPricingKeys[spotKey] = true
+
+					// Every instance type of the region is indexed, not only
+					// those of the cluster's nodes, for comparisons against
+					// the instance types the cluster could use instead.
+					aws.instanceTypes[product.Sku] = newInstanceType(product)
 				} else if strings.Contains(product.Attributes.UsageType, "EBS:Volume") {
 					// UsageTypes may be prefixed with a region code - we're removing this when using
 					// volTypes to keep lookups generic
@@ -1049,6 +1070,10 @@ func (aws *AWS) populatePricing(resp *http.Response, inputkeys map[string]bool)
 						log.Errorf("Error decoding AWS Offer Term: " + err.Error())
 					}
 
+					if it, ok := aws.instanceTypes[sku.(string)]; ok {
+						it.Cost = onDemandHourlyCost(sku.(string), offerTerm)
+					}
+
 					key, ok := skusToKeys[sku.(string)]
 					spotKey := key + ",preemptible"
 					if ok {
@@ -1087,9 +1112,114 @@ func (aws *AWS) populatePricing(resp *http.Response, inputkeys map[string]bool)
 			}
 		}
 	}
+
+	aws.pricingDataset = &models.PricingDataset{
+		Name:      "AmazonEC2",
+		Source:    resp.Request.URL.String(),
+		FetchedAt: time.Now().UTC(),
+		Checksum:  hex.EncodeToString(h.Sum(nil)),
+	}
 	return nil
 }
 
+// PricingDatasets returns the pricing file the on-demand prices were last
+// populated from and, if enabled, the spot data feed.
+func (aws *AWS) PricingDatasets() []*models.PricingDataset {
+	var datasets []*models.PricingDataset
+
+	aws.DownloadPricingDataLock.RLock()
+	if aws.pricingDataset != nil {
+		datasets = append(datasets, aws.pricingDataset)
+	}
+	aws.DownloadPricingDataLock.RUnlock()
+
+	aws.SpotPricingLock.RLock()
+	if aws.spotPricingDataset != nil {
+		datasets = append(datasets, aws.spotPricingDataset)
+	}
+	aws.SpotPricingLock.RUnlock()
+
+	return datasets
+}
+
+// newInstanceType returns the InstanceType of the product, unpriced.
+func newInstanceType(product *AWSProduct) *models.InstanceType {
+	attrs := product.Attributes
+
+	it := &models.InstanceType{
+		Name:            attrs.InstanceType,
+		Region:          locationToRegion[attrs.Location],
+		OperatingSystem: strings.ToLower(attrs.OperatingSystem),
+		Family:          strings.Split(attrs.InstanceType, ".")[0],
+		Architecture:    models.ArchitectureAMD64,
+	}
+
+	switch {
+	case strings.Contains(attrs.PhysicalProcessor, "Graviton"):
+		it.Architecture = models.ArchitectureARM64
+		it.Processor = models.ProcessorGraviton
+	case strings.Contains(attrs.PhysicalProcessor, "AMD"):
+		it.Processor = models.ProcessorAMD
+	case strings.Contains(attrs.PhysicalProcessor, "Intel"):
+		it.Processor = models.ProcessorIntel
+	}
+
+	it.VCPU, _ = strconv.ParseFloat(attrs.VCpu, 64)
+	it.GPU, _ = strconv.ParseFloat(attrs.GPU, 64)
+
+	// Memory is of the form "1,952 GiB"
+	if fields := strings.Fields(attrs.Memory); len(fields) > 0 {
+		gib, _ := strconv.ParseFloat(strings.ReplaceAll(fields[0], ",", ""), 64)
+		it.RAMBytes = gib * 1024 * 1024 * 1024
+	}
+
+	return it
+}
+
+// onDemandHourlyCost returns the hourly cost of the on-demand offer term of
+// the sku, or 0 if the term is not an on-demand term.
+func onDemandHourlyCost(sku string, offerTerm *AWSOfferTerm) float64 {
+	var price string
+	if _, isMatch := OnDemandRateCodes[offerTerm.OfferTermCode]; isMatch {
+		if rate, ok := offerTerm.PriceDimensions[strings.Join([]string{sku, offerTerm.OfferTermCode, HourlyRateCode}, ".")]; ok && rate != nil {
+			price = rate.PricePerUnit.USD
+		}
+	} else if _, isMatch := OnDemandRateCodesCn[offerTerm.OfferTermCode]; isMatch {
+		if rate, ok := offerTerm.PriceDimensions[strings.Join([]string{sku, offerTerm.OfferTermCode, HourlyRateCodeCn}, ".")]; ok && rate != nil {
+			price = rate.PricePerUnit.CNY
+		}
+	}
+
+	cost, _ := strconv.ParseFloat(price, 64)
+	return cost
+}
+
+// InstanceTypePricing returns the on-demand price of every instance type of
+// the regions of the cluster's nodes.
+func (aws *AWS) InstanceTypePricing() ([]*models.InstanceType, error) {
+	aws.DownloadPricingDataLock.RLock()
+	defer aws.DownloadPricingDataLock.RUnlock()
+
+	if len(aws.instanceTypes) == 0 {
+		return nil, fmt.Errorf("instance type pricing not downloaded")
+	}
+
+	instanceTypes := make([]*models.InstanceType, 0, len(aws.instanceTypes))
+	for _, it := range aws.instanceTypes {
+		if it.Cost <= 0 {
+			continue
+		}
+		instanceTypes = append(instanceTypes, it)
+	}
+	sort.Slice(instanceTypes, func(i, j int) bool {
+		if instanceTypes[i].Region != instanceTypes[j].Region {
+			return instanceTypes[i].Region < instanceTypes[j].Region
+		}
+		return instanceTypes[i].Name < instanceTypes[j].Name
+	})
+	return instanceTypes, nil
+}
+
 func (aws *AWS) refreshSpotPricing(force bool) {
 	aws.SpotPricingLock.Lock()
 	defer aws.SpotPricingLock.Unlock()
@@ -1113,6 +1243,20 @@ func (aws *AWS) refreshSpotPricing(force bool) {
 	// update time last updated
 	aws.SpotPricingUpdatedAt = &now
 	aws.SpotPricingByInstanceID = sp
+
+	// The spot data feed is many files, so its parsed prices are
+	// checksummed instead
+	data, err := json.Marshal(sp)
+	if err != nil {
+		log.Warnf("checksumming spot pricing: %s", err)
+	}
+	checksum := sha256.Sum256(data)
+	aws.spotPricingDataset = &models.PricingDataset{
+		Name:      "spot",
+		Source:    fmt.Sprintf("s3://%s/%s", aws.SpotDataBucket, aws.SpotDataPrefix),
+		FetchedAt: now,
+		Checksum:  hex.EncodeToString(checksum[:]),
+	}
 }
 
 // Stubbed NetworkPricing for AWS. Pull directly from aws.json for now
@@ -1555,6 +1699,230 @@ func (aws *AWS) GetAddresses() ([]byte, error) {
 	})
 }
 
+// UnattachedAddresses returns the Elastic IP addresses of the cluster's region
+// which are associated with nothing, and so are billed without serving the
+// cluster or anything else.
+func (aws *AWS) UnattachedAddresses() ([]string, error) {
+	if aws.ClusterRegion == "" {
+		return nil, fmt.Errorf("cluster region unknown")
+	}
+
+	aws.ConfigureAuth() // load authentication data into env vars
+
+	resp, err := aws.getAddressesForRegion(context.TODO(), aws.ClusterRegion)
+	if err != nil {
+		return nil, fmt.Errorf("describing addresses in %s: %w", aws.ClusterRegion, err)
+	}
+
+	var addresses []string
+	for i := range resp.Addresses {
+		address := &resp.Addresses[i]
+		if aws.isAddressOrphaned(address) && address.PublicIp != nil {
+			addresses = append(addresses, *address.PublicIp)
+		}
+	}
+	return addresses, nil
+}
+
+// NATGateways returns the available NAT gateways of the VPCs of the cluster's
+// node instances, priced by the custom pricing. A NAT gateway is routed if a
+// route table of the subnets of the nodes routes through it, and so processes
+// the cluster's egress.
+func (aws *AWS) NATGateways() ([]*models.NATGateway, error) {
+	if aws.ClusterRegion == "" {
+		return nil, fmt.Errorf("cluster region unknown")
+	}
+
+	aws.ConfigureAuth() // load authentication data into env vars
+
+	aak, err := aws.GetAWSAccessKey()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := aak.CreateConfig(aws.ClusterRegion)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := aws.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	hourlyCost := c.GetNATGatewayHourlyCost()
+	dataProcessingCost := c.GetNATGatewayDataProcessingCost()
+
+	cli := ec2.NewFromConfig(cfg)
+
+	vpcIDs, subnetIDs, err := aws.nodeNetworks(context.TODO(), cli)
+	if err != nil {
+		return nil, err
+	}
+	if len(vpcIDs) == 0 {
+		return nil, fmt.Errorf("no VPCs found for the nodes of the cluster")
+	}
+
+	routed, err := routedNATGateways(context.TODO(), cli, vpcIDs, subnetIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &ec2.DescribeNatGatewaysInput{
+		Filter: []ec2Types.Filter{
+			{
+				Name:   awsSDK.String("state"),
+				Values: []string{string(ec2Types.NatGatewayStateAvailable)},
+			},
+			{
+				Name:   awsSDK.String("vpc-id"),
+				Values: vpcIDs,
+			},
+		},
+	}
+
+	var natGateways []*models.NATGateway
+	for {
+		resp, err := cli.DescribeNatGateways(context.TODO(), input)
+		if err != nil {
+			return nil, fmt.Errorf("describing NAT gateways in %s: %w", aws.ClusterRegion, err)
+		}
+		for _, ng := range resp.NatGateways {
+			id := awsSDK.ToString(ng.NatGatewayId)
+			natGateways = append(natGateways, &models.NATGateway{
+				ID:                 id,
+				Region:             aws.ClusterRegion,
+				Routed:             routed[id],
+				Cost:               hourlyCost,
+				DataProcessingCost: dataProcessingCost,
+			})
+		}
+		if resp.NextToken == nil {
+			break
+		}
+		input.NextToken = resp.NextToken
+	}
+
+	return natGateways, nil
+}
+
+// nodeNetworks returns the VPCs and subnets of the instances of the cluster's
+// nodes, identified by their provider IDs.
+func (aws *AWS) nodeNetworks(ctx context.Context, cli *ec2.Client) ([]string, []string, error) {
+	var instanceIDs []string
+	for _, n := range aws.Clientset.GetAllNodes() {
+		if match := provIdRx.FindStringSubmatch(n.Spec.ProviderID); len(match) > 2 {
+			instanceIDs = append(instanceIDs, match[2])
+		}
+	}
+	if len(instanceIDs) == 0 {
+		return nil, nil, nil
+	}
+
+	vpcs := map[string]bool{}
+	subnets := map[string]bool{}
+	input := &ec2.DescribeInstancesInput{InstanceIds: instanceIDs}
+	for {
+		resp, err := cli.DescribeInstances(ctx, input)
+		if err != nil {
+			return nil, nil, fmt.Errorf("describing node instances in %s: %w", aws.ClusterRegion, err)
+		}
+		for _, reservation := range resp.Reservations {
+			for _, instance := range reservation.Instances {
+				if instance.VpcId != nil {
+					vpcs[*instance.VpcId] = true
+				}
+				if instance.SubnetId != nil {
+					subnets[*instance.SubnetId] = true
+				}
+			}
+		}
+		if resp.NextToken == nil {
+			break
+		}
+		input.NextToken = resp.NextToken
+	}
+
+	return mapKeys(vpcs), mapKeys(subnets), nil
+}
+
+// routedNATGateways returns the IDs of the NAT gateways routed through by the
+// route tables of the subnets. Subnets without a route table of their own are
+// routed by the main route table of their VPC.
+func routedNATGateways(ctx context.Context, cli *ec2.Client, vpcIDs, subnetIDs []string) (map[string]bool, error) {
+	input := &ec2.DescribeRouteTablesInput{
+		Filters: []ec2Types.Filter{
+			{
+				Name:   awsSDK.String("vpc-id"),
+				Values: vpcIDs,
+			},
+		},
+	}
+
+	var routeTables []ec2Types.RouteTable
+	for {
+		resp, err := cli.DescribeRouteTables(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("describing route tables: %w", err)
+		}
+		routeTables = append(routeTables, resp.RouteTables...)
+		if resp.NextToken == nil {
+			break
+		}
+		input.NextToken = resp.NextToken
+	}
+
+	subnets := map[string]bool{}
+	for _, id := range subnetIDs {
+		subnets[id] = true
+	}
+
+	// the subnets associated with a route table of their own
+	associated := map[string]bool{}
+	for _, rt := range routeTables {
+		for _, assoc := range rt.Associations {
+			if assoc.SubnetId != nil {
+				associated[*assoc.SubnetId] = true
+			}
+		}
+	}
+	unassociated := false
+	for id := range subnets {
+		if !associated[id] {
+			unassociated = true
+		}
+	}
+
+	routed := map[string]bool{}
+	for _, rt := range routeTables {
+		routesNodes := false
+		for _, assoc := range rt.Associations {
+			// the main association is of neither a subnet nor a gateway
+			main := assoc.SubnetId == nil && assoc.GatewayId == nil
+			if (assoc.SubnetId != nil && subnets[*assoc.SubnetId]) || (main && unassociated) {
+				routesNodes = true
+			}
+		}
+		if !routesNodes {
+			continue
+		}
+		for _, route := range rt.Routes {
+			if route.NatGatewayId != nil {
+				routed[*route.NatGatewayId] = true
+			}
+		}
+	}
+	return routed, nil
+}
+
+// mapKeys returns the keys of the set, sorted.
+func mapKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func (aws *AWS) isAddressOrphaned(address *ec2Types.Address) bool {
 	if address.AssociationId != nil {
 		return false
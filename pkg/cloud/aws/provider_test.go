@@ -494,3 +494,85 @@ func Test_populate_pricing(t *testing.T) {
 	}
 
 }
+
+func Test_newInstanceType(t *testing.T) {
+	tests := []struct {
+		name       string
+		attributes AWSProductAttributes
+		want       *models.InstanceType
+	}{
+		{
+			name: "Intel",
+			attributes: AWSProductAttributes{
+				Location:          "US East (Ohio)",
+				InstanceType:      "m5.large",
+				Memory:            "8 GiB",
+				VCpu:              "2",
+				OperatingSystem:   "Linux",
+				PhysicalProcessor: "Intel Xeon Platinum 8175",
+			},
+			want: &models.InstanceType{
+				Name:            "m5.large",
+				Region:          "us-east-2",
+				OperatingSystem: "linux",
+				Family:          "m5",
+				Architecture:    models.ArchitectureAMD64,
+				Processor:       models.ProcessorIntel,
+				VCPU:            2,
+				RAMBytes:        8 * 1024 * 1024 * 1024,
+			},
+		},
+		{
+			name: "Graviton with thousands of GiB",
+			attributes: AWSProductAttributes{
+				Location:          "US East (Ohio)",
+				InstanceType:      "x2gd.metal",
+				Memory:            "1,024 GiB",
+				VCpu:              "64",
+				OperatingSystem:   "Linux",
+				PhysicalProcessor: "AWS Graviton2 Processor",
+			},
+			want: &models.InstanceType{
+				Name:            "x2gd.metal",
+				Region:          "us-east-2",
+				OperatingSystem: "linux",
+				Family:          "x2gd",
+				Architecture:    models.ArchitectureARM64,
+				Processor:       models.ProcessorGraviton,
+				VCPU:            64,
+				RAMBytes:        1024 * 1024 * 1024 * 1024,
+			},
+		},
+		{
+			name: "AMD with GPUs",
+			attributes: AWSProductAttributes{
+				Location:          "US East (Ohio)",
+				InstanceType:      "g4ad.xlarge",
+				Memory:            "16 GiB",
+				VCpu:              "4",
+				GPU:               "1",
+				OperatingSystem:   "Linux",
+				PhysicalProcessor: "AMD EPYC 7R32",
+			},
+			want: &models.InstanceType{
+				Name:            "g4ad.xlarge",
+				Region:          "us-east-2",
+				OperatingSystem: "linux",
+				Family:          "g4ad",
+				Architecture:    models.ArchitectureAMD64,
+				Processor:       models.ProcessorAMD,
+				VCPU:            4,
+				RAMBytes:        16 * 1024 * 1024 * 1024,
+				GPU:             1,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newInstanceType(&AWSProduct{Attributes: tt.attributes})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newInstanceType() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package models
+
+// Architectures of instance types, as in the kubernetes.io/arch node label.
+const (
+	ArchitectureAMD64 = "amd64"
+	ArchitectureARM64 = "arm64"
+)
+
+// Processor vendors of instance types.
+const (
+	ProcessorIntel    = "intel"
+	ProcessorAMD      = "amd"
+	ProcessorGraviton = "graviton"
+)
+
+// InstanceType is the interface by which the provider and cost model
+// communicate the capacity and on-demand price of an instance type offered in
+// a region, whether or not it is in use by the cluster.
+// The provider will best-effort try to fill out this struct.
+type InstanceType struct {
+	Name            string  `json:"name"`
+	Region          string  `json:"region"`
+	OperatingSystem string  `json:"operatingSystem"`
+	Family          string  `json:"family"`
+	Architecture    string  `json:"architecture"`
+	Processor       string  `json:"processor"`
+	VCPU            float64 `json:"vcpu"`
+	RAMBytes        float64 `json:"ramBytes"`
+	GPU             float64 `json:"gpu"`
+	Cost            float64 `json:"hourlyCost"`
+}
+
+// InstanceTypePricingProvider is implemented by providers able to price the
+// instance types of the regions of the cluster, beyond those of its nodes.
+type InstanceTypePricingProvider interface {
+	InstanceTypePricing() ([]*InstanceType, error)
+}
@@ -193,8 +193,22 @@ type CustomPricing struct {
 	GoogleAnalyticsTag           string `json:"googleAnalyticsTag"`
 	ExcludeProviderID            string `json:"excludeProviderID"`
 	DefaultLBPrice               string `json:"defaultLBPrice"`
+	// PublicIPHourlyCost is a string-encoded float describing the hourly cost
+	// of a public IP address allocated to the cluster.
+	PublicIPHourlyCost string `json:"publicIPHourlyCost,omitempty"`
+	// NATGatewayHourlyCost is a string-encoded float describing the hourly
+	// cost of a NAT gateway serving the cluster.
+	NATGatewayHourlyCost string `json:"natGatewayHourlyCost,omitempty"`
+	// NATGatewayDataProcessingCost is a string-encoded float describing the
+	// cost per GiB of data processed by a NAT gateway.
+	NATGatewayDataProcessingCost string `json:"natGatewayDataProcessingCost,omitempty"`
 }
 
+const (
+	defaultNATGatewayHourlyCost         = 0.045
+	defaultNATGatewayDataProcessingCost = 0.045
+)
+
 // GetSharedOverheadCostPerMonth parses and returns a float64 representation
 // of the configured monthly shared overhead cost. If the string version cannot
 // be parsed into a float, an error is logged and 0.0 is returned.
@@ -214,6 +228,43 @@ func (cp *CustomPricing) GetSharedOverheadCostPerMonth() float64 {
 	return sharedCostPerMonth
 }
 
+// GetPublicIPHourlyCost parses and returns a float64 representation of the
+// configured hourly cost of a public IP address. As the price varies between
+// providers, and some do not charge for addresses at all, it defaults to 0.
+func (cp *CustomPricing) GetPublicIPHourlyCost() float64 {
+	return parseCustomPrice("PublicIPHourlyCost", cp.PublicIPHourlyCost, 0)
+}
+
+// GetNATGatewayHourlyCost parses and returns a float64 representation of the
+// configured hourly cost of a NAT gateway, defaulting to the list price common
+// to the major providers.
+func (cp *CustomPricing) GetNATGatewayHourlyCost() float64 {
+	return parseCustomPrice("NATGatewayHourlyCost", cp.NATGatewayHourlyCost, defaultNATGatewayHourlyCost)
+}
+
+// GetNATGatewayDataProcessingCost parses and returns a float64 representation
+// of the configured cost per GiB processed by a NAT gateway, defaulting to the
+// list price common to the major providers.
+func (cp *CustomPricing) GetNATGatewayDataProcessingCost() float64 {
+	return parseCustomPrice("NATGatewayDataProcessingCost", cp.NATGatewayDataProcessingCost, defaultNATGatewayDataProcessingCost)
+}
+
+// parseCustomPrice parses the string-encoded price of the named field, logging
+// and returning the default if it is empty or cannot be parsed.
+func parseCustomPrice(field, value string, defaultPrice float64) float64 {
+	if value == "" {
+		return defaultPrice
+	}
+
+	price, err := strconv.ParseFloat(value, 64)
+	if err != nil || price < 0 {
+		log.Errorf("%s: failed to parse price \"%s\"; defaulting to %f", field, value, defaultPrice)
+		return defaultPrice
+	}
+
+	return price
+}
+
 func SetCustomPricingField(obj *CustomPricing, name string, value string) error {
 
 	structValue := reflect.ValueOf(obj).Elem()
@@ -238,6 +289,23 @@ func SetCustomPricingField(obj *CustomPricing, name string, value string) error
 	return nil
 }
 
+// GetCustomPricingField returns the value of the string field of CustomPricing
+// with the given name.
+func GetCustomPricingField(obj *CustomPricing, name string) (string, error) {
+	if !HasCustomPricingField(name) {
+		return "", fmt.Errorf("No such field: %s in obj", name)
+	}
+
+	return reflect.ValueOf(obj).Elem().FieldByName(name).String(), nil
+}
+
+// HasCustomPricingField returns true if name is the name of a field of
+// CustomPricing which can be set by SetCustomPricingField.
+func HasCustomPricingField(name string) bool {
+	field, ok := reflect.TypeOf(CustomPricing{}).FieldByName(name)
+	return ok && field.IsExported() && field.Type.Kind() == reflect.String
+}
+
 type PricingSources struct {
 	PricingSources map[string]*PricingSource
 }
@@ -249,6 +317,22 @@ type PricingSource struct {
 	Error     string `json:"error"`
 }
 
+// PricingDataset describes a dataset of prices downloaded by the provider:
+// where it was fetched from, when, and the SHA-256 checksum of its contents.
+type PricingDataset struct {
+	Name      string    `json:"name"`
+	Source    string    `json:"source"`
+	FetchedAt time.Time `json:"fetchedAt"`
+	Checksum  string    `json:"checksum"`
+}
+
+// PricingDatasetProvider is implemented by providers able to describe the
+// datasets their prices were last downloaded from. PricingDatasets is called
+// for every API response, so must not compute the descriptions.
+type PricingDatasetProvider interface {
+	PricingDatasets() []*PricingDataset
+}
+
 type PricingType string
 
 const (
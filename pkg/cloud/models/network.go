@@ -19,3 +19,29 @@ type LoadBalancer struct {
 	IngressIPAddresses []string `json:"IngressIPAddresses"`
 	Cost               float64  `json:"hourlyCost"`
 }
+
+// NATGateway is the interface by which the provider and cost model communicate
+// the NAT gateways serving the cluster and their prices.
+// The provider will best-effort try to fill out this struct.
+type NATGateway struct {
+	ID     string `json:"id"`
+	Region string `json:"region"`
+	// Routed is true if a route table of the cluster's subnets routes through
+	// the NAT gateway, so that it processes the cluster's egress.
+	Routed             bool    `json:"routed"`
+	Cost               float64 `json:"hourlyCost"`
+	DataProcessingCost float64 `json:"dataProcessingCost"` // per GiB
+}
+
+// NATGatewayProvider is implemented by providers able to list the NAT gateways
+// of the cluster's network, which are otherwise invisible to Kubernetes.
+type NATGatewayProvider interface {
+	NATGateways() ([]*NATGateway, error)
+}
+
+// UnattachedAddressProvider is implemented by providers able to list the public
+// IP addresses allocated in the cluster's region but attached to nothing, which
+// are billed nonetheless.
+type UnattachedAddressProvider interface {
+	UnattachedAddresses() ([]string, error)
+}
@@ -59,6 +59,11 @@ func (pc *ProviderConfig) onConfigFileUpdated(changeType config.ChangeType, data
 		customPricing := new(models.CustomPricing)
 		err := json.Unmarshal(data, customPricing)
 		if err != nil {
+			// keep the last valid config rather than reverting prices mid-edit
+			if pc.customPricing != nil {
+				log.Warnf("Could not decode Custom Pricing file at path %s. Keeping previous config.", pc.configFile.Path())
+				return
+			}
 			log.Infof("Could not decode Custom Pricing file at path %s. Using default.", pc.configFile.Path())
 			customPricing = DefaultPricing()
 		}
@@ -169,16 +174,18 @@ func (pc *ProviderConfig) Update(updateFunc func(*models.CustomPricing) error) (
 
 	// Load Config, set flag to _not_ write if failure to find file.
 	// We're about to write the updated values, so we don't want to double write.
-	c, _ := pc.loadConfig(false)
+	cached, _ := pc.loadConfig(false)
 
-	// Execute Update - On error, return the in-memory config but don't update cache
-	// explicitly
+	// Execute Update on a copy, so that a failed update leaves the cached config
+	// untouched rather than partially updated
+	c := new(models.CustomPricing)
+	*c = *cached
 	err := updateFunc(c)
 	if err != nil {
-		return c, err
+		return cached, err
 	}
 
-	// Cache Update (possible the ptr already references the cached value)
+	// Cache Update
 	pc.customPricing = c
 
 	cj, err := json.Marshal(c)
@@ -0,0 +1,228 @@
+package cloudcost
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/pkg/filter"
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/util/timeutil"
+)
+
+var (
+	database = &kubecost.CloudCostProperties{
+		ProviderID: "db-1",
+		Provider:   kubecost.AWSProvider,
+		Service:    "AmazonRDS",
+		Labels:     kubecost.CloudCostLabels{"team": "payments"},
+	}
+	bucket = &kubecost.CloudCostProperties{
+		ProviderID: "bucket-1",
+		Provider:   kubecost.AWSProvider,
+		Service:    "AmazonS3",
+		Labels:     kubecost.CloudCostLabels{"team": "search"},
+	}
+	node = &kubecost.CloudCostProperties{
+		ProviderID: "i-1",
+		Provider:   kubecost.AWSProvider,
+		Service:    "AmazonEC2",
+		Labels:     kubecost.CloudCostLabels{"team": "payments"},
+	}
+)
+
+// dailyIntegration returns a net cost of 1 for the database, 2 for the bucket
+// and 4 for the node, which is entirely Kubernetes, for each day.
+type dailyIntegration struct {
+	err   error
+	calls [][2]time.Time
+}
+
+func (di *dailyIntegration) GetCloudCost(start, end time.Time) (*kubecost.CloudCostSetRange, error) {
+	di.calls = append(di.calls, [2]time.Time{start, end})
+	if di.err != nil {
+		return nil, di.err
+	}
+
+	ccsr, err := kubecost.NewCloudCostSetRange(start, end, timeutil.Day, "integration")
+	if err != nil {
+		return nil, err
+	}
+	for _, ccs := range ccsr.CloudCostSets {
+		s, e := *ccs.Window.Start(), *ccs.Window.End()
+		ccs.Insert(kubecost.NewCloudCost(s, e, database, 0.0, 1, 1, 1, 1, 1))
+		ccs.Insert(kubecost.NewCloudCost(s, e, bucket, 0.0, 2, 2, 2, 2, 2))
+		ccs.Insert(kubecost.NewCloudCost(s, e, node, 1.0, 4, 4, 4, 4, 4))
+	}
+	return ccsr, nil
+}
+
+func TestIngestor_Ingest(t *testing.T) {
+	repo := NewMemoryRepository()
+	integration := &dailyIntegration{}
+	ing := NewIngestor("integration", integration, repo, IngestorConfig{
+		RefreshInterval: time.Hour,
+		RunWindow:       2 * timeutil.Day,
+		Retention:       7 * timeutil.Day,
+	})
+
+	now := time.Date(2023, 1, 10, 12, 0, 0, 0, time.UTC)
+	if err := ing.ingest(now); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// the first run backfills the retention window, up to the end of today
+	end := time.Date(2023, 1, 11, 0, 0, 0, 0, time.UTC)
+	if call := integration.calls[0]; !call[0].Equal(end.Add(-7*timeutil.Day)) || !call[1].Equal(end) {
+		t.Errorf("expected first ingestion of the retention window; got [%s, %s)", call[0], call[1])
+	}
+
+	// later runs ingest the run window, and expire data beyond retention
+	if err := ing.ingest(now.Add(timeutil.Day)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if call := integration.calls[1]; !call[0].Equal(end.Add(-timeutil.Day)) {
+		t.Errorf("expected ingestion of the run window; got [%s, %s)", call[0], call[1])
+	}
+
+	status := ing.Status()
+	if !status.Start.Equal(end.Add(-6*timeutil.Day)) || !status.End.Equal(end.Add(timeutil.Day)) {
+		t.Errorf("expected coverage of the last 7 days; got [%s, %s)", status.Start, status.End)
+	}
+
+	integration.err = errors.New("athena unavailable")
+	if err := ing.ingest(now.Add(2 * timeutil.Day)); err == nil {
+		t.Errorf("expected error")
+	}
+	if status := ing.Status(); status.LastError == "" || !status.LastSuccess.Equal(now.Add(timeutil.Day)) {
+		t.Errorf("expected status to record the failure; got %+v", status)
+	}
+}
+
+func TestMemoryRepository_Query(t *testing.T) {
+	repo := NewMemoryRepository()
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(2 * timeutil.Day)
+
+	ccsr, _ := (&dailyIntegration{}).GetCloudCost(start, end)
+	for _, ccs := range ccsr.CloudCostSets {
+		if err := repo.Put("integration", ccs); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	netCosts := func(ccsr *kubecost.CloudCostSetRange) map[string]float64 {
+		costs := map[string]float64{}
+		for _, ccs := range ccsr.CloudCostSets {
+			for key, cc := range ccs.CloudCosts {
+				costs[key] += cc.NetCost.Cost
+			}
+		}
+		return costs
+	}
+
+	testCases := map[string]struct {
+		request  *QueryRequest
+		sets     int
+		expected map[string]float64
+	}{
+		"aggregated by tag": {
+			request:  &QueryRequest{AggregateBy: []string{"label:team"}},
+			sets:     2,
+			expected: map[string]float64{"payments": 10, "search": 4},
+		},
+		"non-Kubernetes by tag, accumulated": {
+			request:  &QueryRequest{AggregateBy: []string{"label:team"}, ExcludeKubernetes: true, Accumulate: true},
+			sets:     1,
+			expected: map[string]float64{"payments": 2, "search": 4},
+		},
+		"filtered by service": {
+			request: &QueryRequest{
+				AggregateBy: []string{kubecost.CloudCostServiceProp},
+				Filter: filter.StringProperty[*kubecost.CloudCost]{
+					Field: kubecost.CloudCostServiceProp,
+					Op:    filter.StringEquals,
+					Value: "AmazonS3",
+				},
+			},
+			sets:     2,
+			expected: map[string]float64{"AmazonS3": 4},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// partial days are expanded to whole days
+			tc.request.Start = start.Add(time.Hour)
+			tc.request.End = end.Add(-time.Hour)
+
+			result, err := repo.Query(tc.request)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if len(result.CloudCostSets) != tc.sets {
+				t.Fatalf("expected %d sets; got %d", tc.sets, len(result.CloudCostSets))
+			}
+
+			actual := netCosts(result)
+			if len(actual) != len(tc.expected) {
+				t.Errorf("expected %v; got %v", tc.expected, actual)
+			}
+			for key, cost := range tc.expected {
+				if actual[key] != cost {
+					t.Errorf("%s: expected net cost %f; got %f", key, cost, actual[key])
+				}
+			}
+		})
+	}
+}
+
+func TestParseTagRules(t *testing.T) {
+	rules, err := ParseTagRules("team, cost-center=costcenter")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := []TagRule{{Tag: "team", NamespaceLabel: "team"}, {Tag: "cost-center", NamespaceLabel: "costcenter"}}
+	if len(rules) != len(expected) || rules[0] != expected[0] || rules[1] != expected[1] {
+		t.Errorf("expected %v; got %v", expected, rules)
+	}
+
+	if _, err := ParseTagRules("team="); err == nil {
+		t.Errorf("expected error parsing a rule without a label")
+	}
+}
+
+func TestJoinExternalCosts(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(timeutil.Day)
+
+	ccsr, _ := (&dailyIntegration{}).GetCloudCost(start, end)
+	rules := []TagRule{{Tag: "team", NamespaceLabel: "team"}}
+	namespaceLabels := map[string]map[string]string{
+		"payments-api":    {"team": "payments"},
+		"payments-worker": {"team": "payments"},
+		"kube-system":     {},
+	}
+
+	ecs, err := JoinExternalCosts(ccsr.CloudCostSets[0], rules, namespaceLabels, kubecost.NetCostMetric, 0.5)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// the bucket of the search team is not linked to any namespace, and the
+	// node is entirely Kubernetes
+	if len(ecs.Tenants) != 1 {
+		t.Fatalf("expected 1 tenant; got %d", len(ecs.Tenants))
+	}
+	tenant := ecs.Tenants[0]
+	if tenant.Tenant != "team=payments" || len(tenant.Namespaces) != 2 {
+		t.Errorf("expected tenant team=payments of 2 namespaces; got %s of %v", tenant.Tenant, tenant.Namespaces)
+	}
+	if len(tenant.Items) != 1 || tenant.Items[0].ProviderID != "db-1" || tenant.TotalCost != 0.5 {
+		t.Errorf("expected the database, pro-rated to 0.5; got %v totalling %f", tenant.Items, tenant.TotalCost)
+	}
+
+	if _, err := JoinExternalCosts(ccsr.CloudCostSets[0], rules, namespaceLabels, "Unknown", 1); err == nil {
+		t.Errorf("expected error for an unknown cost metric")
+	}
+}
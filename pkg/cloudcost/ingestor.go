@@ -0,0 +1,157 @@
+package cloudcost
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/opencost/opencost/pkg/cloud"
+	"github.com/opencost/opencost/pkg/log"
+	"github.com/opencost/opencost/pkg/util/atomic"
+	"github.com/opencost/opencost/pkg/util/timeutil"
+)
+
+// IngestorConfig contains the options of an Ingestor.
+type IngestorConfig struct {
+	// RefreshInterval is the time between ingestions.
+	RefreshInterval time.Duration
+
+	// RunWindow is the trailing window ingested on each refresh, over which
+	// billing data may still be revised by the provider.
+	RunWindow time.Duration
+
+	// Retention is the window ingested on the first run, beyond which data is
+	// expired.
+	Retention time.Duration
+}
+
+// IngestorStatus describes the state of the ingestion of an integration.
+type IngestorStatus struct {
+	Integration string    `json:"integration"`
+	LastRun     time.Time `json:"lastRun"`
+	LastSuccess time.Time `json:"lastSuccess"`
+	LastError   string    `json:"lastError,omitempty"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+}
+
+// Ingestor periodically retrieves the daily cloud costs of an integration from
+// its billing export, storing them in a repository.
+type Ingestor struct {
+	key         string
+	integration cloud.CloudCostIntegration
+	repo        *MemoryRepository
+	config      IngestorConfig
+	runState    atomic.AtomicRunState
+	lock        sync.Mutex
+	status      IngestorStatus
+	backfilled  bool
+}
+
+// NewIngestor creates an Ingestor of the integration identified by the key.
+func NewIngestor(key string, integration cloud.CloudCostIntegration, repo *MemoryRepository, config IngestorConfig) *Ingestor {
+	return &Ingestor{
+		key:         key,
+		integration: integration,
+		repo:        repo,
+		config:      config,
+		status:      IngestorStatus{Integration: key},
+	}
+}
+
+// Start begins ingesting immediately and on every refresh interval thereafter.
+// It returns false if the Ingestor is already running.
+func (ing *Ingestor) Start() bool {
+	ing.runState.WaitForReset()
+	if !ing.runState.Start() {
+		return false
+	}
+
+	go func() {
+		ticker := time.NewTicker(ing.config.RefreshInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := ing.ingest(time.Now()); err != nil {
+				log.Errorf("CloudCost[%s]: ingestion failed: %s", ing.key, err)
+			}
+
+			select {
+			case <-ing.runState.OnStop():
+				ing.runState.Reset()
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return true
+}
+
+// Stop stops ingesting, waiting for any ingestion in progress to complete.
+func (ing *Ingestor) Stop() bool {
+	return ing.runState.Stop()
+}
+
+// Status returns the state of the ingestion.
+func (ing *Ingestor) Status() IngestorStatus {
+	ing.lock.Lock()
+	defer ing.lock.Unlock()
+
+	status := ing.status
+	if w, ok := ing.repo.Coverage()[ing.key]; ok {
+		status.Start = *w.Start()
+		status.End = *w.End()
+	}
+	return status
+}
+
+// ingest retrieves the cloud costs of the days in the run window preceding
+// now, or the whole retention window on the first successful run, and expires
+// those beyond retention.
+func (ing *Ingestor) ingest(now time.Time) error {
+	ing.lock.Lock()
+	ing.status.LastRun = now
+	window := ing.config.RunWindow
+	if !ing.backfilled {
+		window = ing.config.Retention
+	}
+	ing.lock.Unlock()
+
+	end := now.UTC().Truncate(timeutil.Day).Add(timeutil.Day)
+	start := end.Add(-window)
+
+	err := ing.load(start, end)
+
+	ing.lock.Lock()
+	defer ing.lock.Unlock()
+
+	if err != nil {
+		ing.status.LastError = err.Error()
+		return err
+	}
+
+	ing.backfilled = true
+	ing.status.LastSuccess = now
+	ing.status.LastError = ""
+	ing.repo.Expire(ing.key, end.Add(-ing.config.Retention))
+
+	return nil
+}
+
+func (ing *Ingestor) load(start, end time.Time) error {
+	log.Infof("CloudCost[%s]: ingesting [%s, %s)", ing.key, start.Format(time.RFC3339), end.Format(time.RFC3339))
+
+	ccsr, err := ing.integration.GetCloudCost(start, end)
+	if err != nil {
+		return fmt.Errorf("retrieving cloud costs: %w", err)
+	}
+
+	for _, ccs := range ccsr.CloudCostSets {
+		if err := ing.repo.Put(ing.key, ccs); err != nil {
+			return fmt.Errorf("storing cloud costs: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,169 @@
+package cloudcost
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+)
+
+// TagRule links cloud resources to Kubernetes tenants: a resource with the tag
+// belongs to the tenant of the namespaces whose label has the same value as
+// the tag, e.g. resources tagged team=payments belong to the namespaces labeled
+// team=payments.
+type TagRule struct {
+	Tag            string `json:"tag"`
+	NamespaceLabel string `json:"namespaceLabel"`
+}
+
+// ParseTagRules parses a comma-separated list of rules, each a tag, joined to
+// the namespace label of the same name, or of the form "<tag>=<label>", e.g.
+// "team,cost-center=costcenter".
+func ParseTagRules(s string) ([]TagRule, error) {
+	var rules []TagRule
+	for _, raw := range strings.Split(s, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+
+		tag, label, ok := strings.Cut(raw, "=")
+		if !ok {
+			label = tag
+		}
+		tag, label = strings.TrimSpace(tag), strings.TrimSpace(label)
+		if tag == "" || label == "" {
+			return nil, fmt.Errorf("invalid tag rule %q: expected <tag> or <tag>=<label>", raw)
+		}
+
+		rules = append(rules, TagRule{Tag: tag, NamespaceLabel: label})
+	}
+
+	return rules, nil
+}
+
+// ExternalCostItem is the cost of a cloud resource outside of Kubernetes.
+type ExternalCostItem struct {
+	ProviderID string  `json:"providerID"`
+	AccountID  string  `json:"accountID,omitempty"`
+	Service    string  `json:"service"`
+	Category   string  `json:"category,omitempty"`
+	Cost       float64 `json:"cost"`
+}
+
+// TenantExternalCosts are the costs of the cloud resources outside of
+// Kubernetes belonging to a tenant, identified by the tag and value shared by
+// the resources and the labels of the tenant's namespaces.
+type TenantExternalCosts struct {
+	Tenant     string              `json:"tenant"`
+	Namespaces []string            `json:"namespaces"`
+	TotalCost  float64             `json:"totalCost"`
+	Items      []*ExternalCostItem `json:"items"`
+}
+
+// ExternalCostSet are the external costs of each tenant over a window.
+type ExternalCostSet struct {
+	Window     kubecost.Window        `json:"window"`
+	CostMetric string                 `json:"costMetric"`
+	Tenants    []*TenantExternalCosts `json:"tenants"`
+}
+
+// ConvertCurrency multiplies every cost of the set by the exchange rate,
+// converting it to another currency in place.
+func (ecs *ExternalCostSet) ConvertCurrency(rate float64) {
+	if ecs == nil {
+		return
+	}
+
+	for _, tenant := range ecs.Tenants {
+		tenant.TotalCost *= rate
+		for _, item := range tenant.Items {
+			item.Cost *= rate
+		}
+	}
+}
+
+// JoinExternalCosts links the portion of each CloudCost outside of Kubernetes
+// to tenants, per the first of the rules matching both a tag of the resource
+// and the labels of any namespace, given by name. Costs are multiplied by
+// scale, pro-rating costs of whole days to shorter windows. Resources not
+// linked to any namespace are omitted.
+func JoinExternalCosts(ccs *kubecost.CloudCostSet, rules []TagRule, namespaceLabels map[string]map[string]string, costMetric string, scale float64) (*ExternalCostSet, error) {
+	ecs := &ExternalCostSet{
+		Window:     ccs.Window.Clone(),
+		CostMetric: costMetric,
+		Tenants:    []*TenantExternalCosts{},
+	}
+
+	tenants := map[string]*TenantExternalCosts{}
+	for _, cc := range ccs.NonKubernetes().CloudCosts {
+		if cc.Properties == nil {
+			continue
+		}
+
+		metric, err := cc.GetCostMetric(costMetric)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rule := range rules {
+			value, ok := cc.Properties.Labels[rule.Tag]
+			if !ok || value == "" {
+				continue
+			}
+
+			name := fmt.Sprintf("%s=%s", rule.Tag, value)
+			tenant, ok := tenants[name]
+			if !ok {
+				namespaces := namespacesWithLabel(namespaceLabels, rule.NamespaceLabel, value)
+				if len(namespaces) == 0 {
+					continue
+				}
+				tenant = &TenantExternalCosts{
+					Tenant:     name,
+					Namespaces: namespaces,
+					Items:      []*ExternalCostItem{},
+				}
+				tenants[name] = tenant
+			}
+
+			cost := metric.Cost * scale
+			tenant.TotalCost += cost
+			tenant.Items = append(tenant.Items, &ExternalCostItem{
+				ProviderID: cc.Properties.ProviderID,
+				AccountID:  cc.Properties.AccountID,
+				Service:    cc.Properties.Service,
+				Category:   cc.Properties.Category,
+				Cost:       cost,
+			})
+			break
+		}
+	}
+
+	for _, tenant := range tenants {
+		sort.Slice(tenant.Items, func(i, j int) bool {
+			if tenant.Items[i].Cost != tenant.Items[j].Cost {
+				return tenant.Items[i].Cost > tenant.Items[j].Cost
+			}
+			return tenant.Items[i].ProviderID < tenant.Items[j].ProviderID
+		})
+		ecs.Tenants = append(ecs.Tenants, tenant)
+	}
+	sort.Slice(ecs.Tenants, func(i, j int) bool {
+		return ecs.Tenants[i].Tenant < ecs.Tenants[j].Tenant
+	})
+
+	return ecs, nil
+}
+
+func namespacesWithLabel(namespaceLabels map[string]map[string]string, label, value string) []string {
+	var namespaces []string
+	for namespace, labels := range namespaceLabels {
+		if labels[label] == value {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
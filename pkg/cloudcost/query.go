@@ -0,0 +1,73 @@
+package cloudcost
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/opencost/opencost/pkg/filter"
+	"github.com/opencost/opencost/pkg/kubecost"
+)
+
+// QueryRequest describes a query of the cloud costs of a repository.
+type QueryRequest struct {
+	Start time.Time
+	End   time.Time
+
+	// AggregateBy are the properties, including labels, as "label:<name>", by
+	// which CloudCosts are aggregated. CloudCosts are not aggregated if empty.
+	AggregateBy []string
+
+	// Accumulate sums the daily sets of the range into a single set.
+	Accumulate bool
+
+	// Filter selects the CloudCosts included. All are included if nil.
+	Filter filter.Filter[*kubecost.CloudCost]
+
+	// ExcludeKubernetes includes only the portion of each CloudCost which is
+	// not Kubernetes spend, such as managed databases, buckets and queues.
+	ExcludeKubernetes bool
+}
+
+// Query returns the cloud costs of each day of the request's range, or of the
+// whole range if accumulated, expanded to whole UTC days.
+func (r *MemoryRepository) Query(req *QueryRequest) (*kubecost.CloudCostSetRange, error) {
+	sets, err := r.Get(req.Start, req.End)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, ccs := range sets {
+		if req.Filter != nil {
+			ccs = ccs.Filter(req.Filter)
+		}
+
+		if req.ExcludeKubernetes {
+			ccs = ccs.NonKubernetes()
+		}
+
+		if len(req.AggregateBy) > 0 {
+			ccs, err = ccs.Aggregate(req.AggregateBy)
+			if err != nil {
+				return nil, fmt.Errorf("aggregating cloud costs: %w", err)
+			}
+		}
+
+		sets[i] = ccs
+	}
+
+	start, end := dayBounds(req.Start, req.End)
+	ccsr := &kubecost.CloudCostSetRange{
+		CloudCostSets: sets,
+		Window:        kubecost.NewClosedWindow(start, end),
+	}
+
+	if req.Accumulate {
+		ccs, err := ccsr.Accumulate()
+		if err != nil {
+			return nil, fmt.Errorf("accumulating cloud costs: %w", err)
+		}
+		ccsr.CloudCostSets = []*kubecost.CloudCostSet{ccs}
+	}
+
+	return ccsr, nil
+}
@@ -0,0 +1,126 @@
+package cloudcost
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/util/timeutil"
+)
+
+// MemoryRepository is an in-memory store of the daily CloudCostSets ingested
+// from each cloud cost integration.
+type MemoryRepository struct {
+	lock sync.RWMutex
+	// data maps the key of an integration to its sets, by the Unix time of the
+	// start of each day
+	data map[string]map[int64]*kubecost.CloudCostSet
+}
+
+// NewMemoryRepository creates an empty MemoryRepository.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		data: map[string]map[int64]*kubecost.CloudCostSet{},
+	}
+}
+
+// Put stores the daily set of the integration, replacing any previously stored
+// for the day.
+func (r *MemoryRepository) Put(integration string, ccs *kubecost.CloudCostSet) error {
+	if ccs == nil {
+		return fmt.Errorf("cannot store nil CloudCostSet")
+	}
+	if ccs.Window.IsOpen() || ccs.Window.Duration() != timeutil.Day {
+		return fmt.Errorf("cannot store CloudCostSet with window %s: expected a window of one day", ccs.Window)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, ok := r.data[integration]; !ok {
+		r.data[integration] = map[int64]*kubecost.CloudCostSet{}
+	}
+	r.data[integration][ccs.Window.Start().Unix()] = ccs.Clone()
+
+	return nil
+}
+
+// Get returns a set for each day of [start, end), containing the CloudCosts of
+// every integration for the day. Days without data yield empty sets.
+func (r *MemoryRepository) Get(start, end time.Time) ([]*kubecost.CloudCostSet, error) {
+	start, end = dayBounds(start, end)
+	if !start.Before(end) {
+		return nil, fmt.Errorf("invalid range: [%s, %s)", start, end)
+	}
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	var sets []*kubecost.CloudCostSet
+	for s := start; s.Before(end); s = s.Add(timeutil.Day) {
+		result := kubecost.NewCloudCostSet(s, s.Add(timeutil.Day))
+		for _, days := range r.data {
+			ccs, ok := days[s.Unix()]
+			if !ok {
+				continue
+			}
+			for _, cc := range ccs.CloudCosts {
+				result.Insert(cc)
+			}
+		}
+		sets = append(sets, result)
+	}
+
+	return sets, nil
+}
+
+// Expire deletes the sets of the integration for days before the given time.
+func (r *MemoryRepository) Expire(integration string, before time.Time) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for day := range r.data[integration] {
+		if time.Unix(day, 0).Before(before) {
+			delete(r.data[integration], day)
+		}
+	}
+}
+
+// Coverage returns the window from the first to the last day stored for each
+// integration.
+func (r *MemoryRepository) Coverage() map[string]kubecost.Window {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	coverage := map[string]kubecost.Window{}
+	for integration, days := range r.data {
+		if len(days) == 0 {
+			continue
+		}
+
+		starts := make([]int64, 0, len(days))
+		for day := range days {
+			starts = append(starts, day)
+		}
+		sort.Slice(starts, func(i, j int) bool { return starts[i] < starts[j] })
+
+		s := time.Unix(starts[0], 0).UTC()
+		e := time.Unix(starts[len(starts)-1], 0).UTC().Add(timeutil.Day)
+		coverage[integration] = kubecost.NewClosedWindow(s, e)
+	}
+
+	return coverage
+}
+
+// dayBounds expands [start, end) to whole UTC days.
+func dayBounds(start, end time.Time) (time.Time, time.Time) {
+	start = start.UTC().Truncate(timeutil.Day)
+	if e := end.UTC().Truncate(timeutil.Day); e.Before(end) {
+		end = e.Add(timeutil.Day)
+	} else {
+		end = e
+	}
+	return start, end
+}
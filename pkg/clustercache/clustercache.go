@@ -12,7 +12,9 @@ import (
 	"k8s.io/api/policy/v1beta1"
 	stv1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/fields"
+	rt "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 )
 
 // ClusterCache defines an contract for an object which caches components within a cluster, ensuring
@@ -89,6 +91,7 @@ type KubernetesClusterCache struct {
 	jobsWatch                  WatchController
 	pdbWatch                   WatchController
 	replicationControllerWatch WatchController
+	informers                  *SharedInformerFactory
 	stop                       chan struct{}
 }
 
@@ -107,23 +110,42 @@ func NewKubernetesClusterCache(client kubernetes.Interface) ClusterCache {
 	kubecostNamespace := env.GetKubecostNamespace()
 	log.Infof("NAMESPACE: %s", kubecostNamespace)
 
+	// in namespace-scoped mode, namespaced resources are watched in each of
+	// the configured namespaces and cluster-scoped resources are not watched
+	namespaces := env.GetWatchNamespaces()
+	if len(namespaces) > 0 {
+		log.Infof("Restricting the cluster cache to namespaces %v", namespaces)
+	}
+	// the informers of the cache are its own, and run until it is stopped
+	informers := NewSharedInformerFactory()
+	clusterScoped := func(restClient rest.Interface, resource string, resourceType rt.Object) WatchController {
+		if len(namespaces) > 0 {
+			return disabledWatchController{}
+		}
+		return NewCachingWatcher(informers, restClient, resource, resourceType, "", fields.Everything())
+	}
+	namespaced := func(restClient rest.Interface, resource string, resourceType rt.Object) WatchController {
+		return NewNamespacedWatcher(informers, restClient, resource, resourceType, namespaces, fields.Everything())
+	}
+
 	kcc := &KubernetesClusterCache{
 		client:                     client,
-		namespaceWatch:             NewCachingWatcher(coreRestClient, "namespaces", &v1.Namespace{}, "", fields.Everything()),
-		nodeWatch:                  NewCachingWatcher(coreRestClient, "nodes", &v1.Node{}, "", fields.Everything()),
-		podWatch:                   NewCachingWatcher(coreRestClient, "pods", &v1.Pod{}, "", fields.Everything()),
-		kubecostConfigMapWatch:     NewCachingWatcher(coreRestClient, "configmaps", &v1.ConfigMap{}, kubecostNamespace, fields.Everything()),
-		serviceWatch:               NewCachingWatcher(coreRestClient, "services", &v1.Service{}, "", fields.Everything()),
-		daemonsetsWatch:            NewCachingWatcher(appsRestClient, "daemonsets", &appsv1.DaemonSet{}, "", fields.Everything()),
-		deploymentsWatch:           NewCachingWatcher(appsRestClient, "deployments", &appsv1.Deployment{}, "", fields.Everything()),
-		statefulsetWatch:           NewCachingWatcher(appsRestClient, "statefulsets", &appsv1.StatefulSet{}, "", fields.Everything()),
-		replicasetWatch:            NewCachingWatcher(appsRestClient, "replicasets", &appsv1.ReplicaSet{}, "", fields.Everything()),
-		pvWatch:                    NewCachingWatcher(coreRestClient, "persistentvolumes", &v1.PersistentVolume{}, "", fields.Everything()),
-		pvcWatch:                   NewCachingWatcher(coreRestClient, "persistentvolumeclaims", &v1.PersistentVolumeClaim{}, "", fields.Everything()),
-		storageClassWatch:          NewCachingWatcher(storageRestClient, "storageclasses", &stv1.StorageClass{}, "", fields.Everything()),
-		jobsWatch:                  NewCachingWatcher(batchClient, "jobs", &batchv1.Job{}, "", fields.Everything()),
-		pdbWatch:                   NewCachingWatcher(pdbClient, "poddisruptionbudgets", &v1beta1.PodDisruptionBudget{}, "", fields.Everything()),
-		replicationControllerWatch: NewCachingWatcher(coreRestClient, "replicationcontrollers", &v1.ReplicationController{}, "", fields.Everything()),
+		namespaceWatch:             clusterScoped(coreRestClient, "namespaces", &v1.Namespace{}),
+		nodeWatch:                  clusterScoped(coreRestClient, "nodes", &v1.Node{}),
+		podWatch:                   namespaced(coreRestClient, "pods", &v1.Pod{}),
+		kubecostConfigMapWatch:     NewCachingWatcher(informers, coreRestClient, "configmaps", &v1.ConfigMap{}, kubecostNamespace, fields.Everything()),
+		serviceWatch:               namespaced(coreRestClient, "services", &v1.Service{}),
+		daemonsetsWatch:            namespaced(appsRestClient, "daemonsets", &appsv1.DaemonSet{}),
+		deploymentsWatch:           namespaced(appsRestClient, "deployments", &appsv1.Deployment{}),
+		statefulsetWatch:           namespaced(appsRestClient, "statefulsets", &appsv1.StatefulSet{}),
+		replicasetWatch:            namespaced(appsRestClient, "replicasets", &appsv1.ReplicaSet{}),
+		pvWatch:                    clusterScoped(coreRestClient, "persistentvolumes", &v1.PersistentVolume{}),
+		pvcWatch:                   namespaced(coreRestClient, "persistentvolumeclaims", &v1.PersistentVolumeClaim{}),
+		storageClassWatch:          clusterScoped(storageRestClient, "storageclasses", &stv1.StorageClass{}),
+		jobsWatch:                  namespaced(batchClient, "jobs", &batchv1.Job{}),
+		pdbWatch:                   namespaced(pdbClient, "poddisruptionbudgets", &v1beta1.PodDisruptionBudget{}),
+		replicationControllerWatch: namespaced(coreRestClient, "replicationcontrollers", &v1.ReplicationController{}),
+		informers:                  informers,
 	}
 
 	// Wait for each caching watcher to initialize
@@ -162,6 +184,10 @@ func (kcc *KubernetesClusterCache) Run() {
 	if kcc.stop != nil {
 		return
 	}
+	if kcc.informers.IsShutdown() {
+		log.Warnf("Attempted to run a stopped cluster cache")
+		return
+	}
 	stopCh := make(chan struct{})
 
 	go kcc.namespaceWatch.Run(1, stopCh)
@@ -183,13 +209,15 @@ func (kcc *KubernetesClusterCache) Run() {
 	kcc.stop = stopCh
 }
 
+// Stop halts the watch controllers and informers of the cache, which cannot be
+// run again.
 func (kcc *KubernetesClusterCache) Stop() {
-	if kcc.stop == nil {
-		return
+	if kcc.stop != nil {
+		close(kcc.stop)
+		kcc.stop = nil
 	}
 
-	close(kcc.stop)
-	kcc.stop = nil
+	kcc.informers.Shutdown()
 }
 
 func (kcc *KubernetesClusterCache) GetAllNamespaces() []*v1.Namespace {
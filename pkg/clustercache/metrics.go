@@ -0,0 +1,176 @@
+package clustercache
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rt "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Metrics on the health of the cluster cache, which tell operators when stale
+// cluster state, rather than Prometheus, explains unexpected allocations.
+var (
+	cacheMetricsOnce sync.Once
+
+	cacheListDuration     *prometheus.HistogramVec
+	cacheWatchDisconnects *prometheus.CounterVec
+	cacheSyncDuration     *prometheus.GaugeVec
+	cacheLastEvent        *prometheus.GaugeVec
+
+	cacheObjectsDesc = prometheus.NewDesc(
+		"opencost_cluster_cache_objects",
+		"opencost_cluster_cache_objects Number of objects in the cluster cache",
+		[]string{"resource"}, nil,
+	)
+	cacheQueueDepthDesc = prometheus.NewDesc(
+		"opencost_cluster_cache_queue_depth",
+		"opencost_cluster_cache_queue_depth Number of events waiting to be handled by the cluster cache",
+		[]string{"resource"}, nil,
+	)
+
+	cacheControllersLock sync.Mutex
+	cacheControllers     []*CachingWatchController
+)
+
+// initCacheMetrics registers the cluster cache metrics with the default
+// registry the first time a watch controller is created.
+func initCacheMetrics() {
+	cacheMetricsOnce.Do(func() {
+		cacheListDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "opencost_cluster_cache_list_duration_seconds",
+			Help:    "opencost_cluster_cache_list_duration_seconds Duration of the full lists which initialize and resync the cluster cache",
+			Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+		}, []string{"resource"})
+
+		cacheWatchDisconnects = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "opencost_cluster_cache_watch_disconnects_total",
+			Help: "opencost_cluster_cache_watch_disconnects_total Number of times a cluster cache watch ended and was re-established",
+		}, []string{"resource"})
+
+		cacheSyncDuration = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "opencost_cluster_cache_sync_duration_seconds",
+			Help: "opencost_cluster_cache_sync_duration_seconds Duration of the initial sync of the cluster cache",
+		}, []string{"resource"})
+
+		cacheLastEvent = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "opencost_cluster_cache_last_event_timestamp_seconds",
+			Help: "opencost_cluster_cache_last_event_timestamp_seconds Unix time of the last add, update or delete received by the cluster cache",
+		}, []string{"resource"})
+
+		prometheus.MustRegister(cacheListDuration, cacheWatchDisconnects, cacheSyncDuration, cacheLastEvent, cacheCollector{})
+	})
+}
+
+// registerCacheController adds the controller's object count and queue depth
+// to the cluster cache metrics.
+func registerCacheController(c *CachingWatchController) {
+	cacheControllersLock.Lock()
+	defer cacheControllersLock.Unlock()
+
+	cacheControllers = append(cacheControllers, c)
+}
+
+// unregisterCacheControllers removes the controllers whose informers are
+// stopped by the channel from the cluster cache metrics.
+func unregisterCacheControllers(stopCh <-chan struct{}) {
+	cacheControllersLock.Lock()
+	defer cacheControllersLock.Unlock()
+
+	var running []*CachingWatchController
+	for _, c := range cacheControllers {
+		if c.shared.stopCh != stopCh {
+			running = append(running, c)
+		}
+	}
+	cacheControllers = running
+}
+
+// cacheCollector collects the object count and queue depth of each watch
+// controller when scraped.
+type cacheCollector struct{}
+
+// Describe sends the descriptors of the cluster cache gauges.
+func (cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- cacheObjectsDesc
+	ch <- cacheQueueDepthDesc
+}
+
+// Collect sends the object count and queue depth of each watch controller.
+func (cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, stats := range Stats() {
+		ch <- prometheus.MustNewConstMetric(cacheObjectsDesc, prometheus.GaugeValue, float64(stats.Objects), stats.Resource)
+		ch <- prometheus.MustNewConstMetric(cacheQueueDepthDesc, prometheus.GaugeValue, float64(stats.QueueDepth), stats.Resource)
+	}
+}
+
+// ResourceStats contains the size of the cluster cache of a resource.
+type ResourceStats struct {
+	Resource   string `json:"resource"`
+	Objects    int    `json:"objects"`
+	QueueDepth int    `json:"queueDepth"`
+}
+
+// Stats returns the number of cached objects and of events waiting to be
+// handled for each resource watched by the cluster cache, sorted by resource.
+func Stats() []ResourceStats {
+	cacheControllersLock.Lock()
+	defer cacheControllersLock.Unlock()
+
+	// a resource may be watched in more than one namespace, and controllers
+	// of the same resource and namespace share an informer whose objects are
+	// counted once
+	byResource := make(map[string]*ResourceStats)
+	counted := make(map[*sharedInformer]bool)
+	for _, c := range cacheControllers {
+		stats, ok := byResource[c.resource]
+		if !ok {
+			stats = &ResourceStats{Resource: c.resource}
+			byResource[c.resource] = stats
+		}
+		if !counted[c.shared] {
+			stats.Objects += len(c.indexer.ListKeys())
+			counted[c.shared] = true
+		}
+		stats.QueueDepth += c.queue.Len()
+	}
+
+	all := make([]ResourceStats, 0, len(byResource))
+	for _, stats := range byResource {
+		all = append(all, *stats)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Resource < all[j].Resource
+	})
+	return all
+}
+
+// instrumentListWatch times the lists of the ListWatch and counts the watches
+// which replace a disconnected watch.
+func instrumentListWatch(lw *cache.ListWatch, resource string) {
+	list, watchFunc := lw.ListFunc, lw.WatchFunc
+	watched := false
+	var watchedLock sync.Mutex
+
+	lw.ListFunc = func(options metav1.ListOptions) (rt.Object, error) {
+		start := time.Now()
+		obj, err := list(options)
+		cacheListDuration.WithLabelValues(resource).Observe(time.Since(start).Seconds())
+		return obj, err
+	}
+
+	lw.WatchFunc = func(options metav1.ListOptions) (watch.Interface, error) {
+		watchedLock.Lock()
+		if watched {
+			cacheWatchDisconnects.WithLabelValues(resource).Inc()
+		}
+		watched = true
+		watchedLock.Unlock()
+
+		return watchFunc(options)
+	}
+}
@@ -0,0 +1,102 @@
+package clustercache
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/fields"
+	rt "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+)
+
+// NamespacedWatchController watches a namespaced resource in each of a set of
+// namespaces, so that the resource can be cached with only namespaced RBAC.
+type NamespacedWatchController struct {
+	controllers []WatchController
+}
+
+// NewNamespacedWatcher creates a watch controller caching the resource in each
+// of the namespaces. If no namespaces are given, the resource is watched in all
+// namespaces.
+func NewNamespacedWatcher(factory *SharedInformerFactory, restClient rest.Interface, resource string, resourceType rt.Object, namespaces []string, fieldSelector fields.Selector) WatchController {
+	if len(namespaces) == 0 {
+		return NewCachingWatcher(factory, restClient, resource, resourceType, "", fieldSelector)
+	}
+
+	nwc := &NamespacedWatchController{}
+	for _, ns := range namespaces {
+		nwc.controllers = append(nwc.controllers, NewCachingWatcher(factory, restClient, resource, resourceType, ns, fieldSelector))
+	}
+	return nwc
+}
+
+// WarmUp initializes the cache of each namespace concurrently.
+func (nwc *NamespacedWatchController) WarmUp(cancel chan struct{}) {
+	var wg sync.WaitGroup
+	wg.Add(len(nwc.controllers))
+	for _, c := range nwc.controllers {
+		go func(c WatchController) {
+			defer wg.Done()
+			c.WarmUp(cancel)
+		}(c)
+	}
+	wg.Wait()
+}
+
+// Run starts watching each namespace.
+func (nwc *NamespacedWatchController) Run(threadiness int, stopCh chan struct{}) {
+	var wg sync.WaitGroup
+	wg.Add(len(nwc.controllers))
+	for _, c := range nwc.controllers {
+		go func(c WatchController) {
+			defer wg.Done()
+			c.Run(threadiness, stopCh)
+		}(c)
+	}
+	wg.Wait()
+}
+
+// GetAll returns the resources of all namespaces.
+func (nwc *NamespacedWatchController) GetAll() []interface{} {
+	var all []interface{}
+	for _, c := range nwc.controllers {
+		all = append(all, c.GetAll()...)
+	}
+	return all
+}
+
+func (nwc *NamespacedWatchController) SetUpdateHandler(handler WatchHandler) WatchController {
+	for _, c := range nwc.controllers {
+		c.SetUpdateHandler(handler)
+	}
+	return nwc
+}
+
+func (nwc *NamespacedWatchController) SetRemovedHandler(handler WatchHandler) WatchController {
+	for _, c := range nwc.controllers {
+		c.SetRemovedHandler(handler)
+	}
+	return nwc
+}
+
+// disabledWatchController stands in for the watch controller of a
+// cluster-scoped resource when the cache is restricted to a set of namespaces,
+// as watching it would require cluster-wide RBAC. It caches nothing.
+type disabledWatchController struct{}
+
+func (disabledWatchController) WarmUp(chan struct{}) {}
+
+func (disabledWatchController) Run(_ int, stopCh chan struct{}) {
+	<-stopCh
+}
+
+func (disabledWatchController) GetAll() []interface{} {
+	return nil
+}
+
+func (dwc disabledWatchController) SetUpdateHandler(WatchHandler) WatchController {
+	return dwc
+}
+
+func (dwc disabledWatchController) SetRemovedHandler(WatchHandler) WatchController {
+	return dwc
+}
@@ -0,0 +1,80 @@
+package clustercache
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+// lastAppliedConfigAnnotation holds a full copy of objects applied with
+// kubectl, which would otherwise double their size in the cache.
+const lastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// transformObject strips the fields OpenCost does not use from objects before
+// they are cached, which on large clusters cuts the memory used by the cache
+// severalfold. It is only set on informers if CLUSTER_CACHE_TRANSFORM_ENABLED
+// is true. Objects of types which are not transformed, and tombstones of
+// deleted objects, are returned as-is.
+func transformObject(obj interface{}) (interface{}, error) {
+	switch o := obj.(type) {
+	case *v1.Pod:
+		stripPodSpec(&o.Spec, false)
+		o.Status.Conditions = nil
+	case *v1.Node:
+		// images cached on the node, often hundreds per node
+		o.Status.Images = nil
+	case *appsv1.Deployment:
+		stripPodSpec(&o.Spec.Template.Spec, false)
+	case *appsv1.StatefulSet:
+		stripPodSpec(&o.Spec.Template.Spec, false)
+		o.Spec.VolumeClaimTemplates = nil
+	case *appsv1.ReplicaSet:
+		stripPodSpec(&o.Spec.Template.Spec, false)
+	case *appsv1.DaemonSet:
+		// daemonset arguments are read to find the number of vGPUs
+		stripPodSpec(&o.Spec.Template.Spec, true)
+	case *batchv1.Job:
+		stripPodSpec(&o.Spec.Template.Spec, false)
+	case *v1.ReplicationController:
+		if o.Spec.Template != nil {
+			stripPodSpec(&o.Spec.Template.Spec, false)
+		}
+	}
+
+	if accessor, err := meta.Accessor(obj); err == nil {
+		accessor.SetManagedFields(nil)
+		if annotations := accessor.GetAnnotations(); annotations[lastAppliedConfigAnnotation] != "" {
+			delete(annotations, lastAppliedConfigAnnotation)
+			accessor.SetAnnotations(annotations)
+		}
+	}
+
+	return obj, nil
+}
+
+// stripPodSpec removes the container fields which do not affect cost from the
+// pod spec. Resources, images and ports are kept.
+func stripPodSpec(spec *v1.PodSpec, keepArgs bool) {
+	stripContainers(spec.InitContainers, keepArgs)
+	stripContainers(spec.Containers, keepArgs)
+	spec.EphemeralContainers = nil
+}
+
+func stripContainers(containers []v1.Container, keepArgs bool) {
+	for i := range containers {
+		c := &containers[i]
+		c.Env = nil
+		c.EnvFrom = nil
+		c.Command = nil
+		c.LivenessProbe = nil
+		c.ReadinessProbe = nil
+		c.StartupProbe = nil
+		c.Lifecycle = nil
+		c.VolumeMounts = nil
+		c.VolumeDevices = nil
+		if !keepArgs {
+			c.Args = nil
+		}
+	}
+}
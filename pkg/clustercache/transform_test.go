@@ -0,0 +1,83 @@
+package clustercache
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestTransformObject(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:          "pod1",
+			Namespace:     "namespace1",
+			Labels:        map[string]string{"app": "app1"},
+			Annotations:   map[string]string{lastAppliedConfigAnnotation: "{}", "team": "team1"},
+			ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+		},
+		Spec: v1.PodSpec{
+			NodeName: "node1",
+			Containers: []v1.Container{{
+				Name:           "container1",
+				Image:          "image1",
+				Env:            []v1.EnvVar{{Name: "KEY", Value: "value"}},
+				Args:           []string{"--flag"},
+				LivenessProbe:  &v1.Probe{},
+				ReadinessProbe: &v1.Probe{},
+				Resources: v1.ResourceRequirements{
+					Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m")},
+				},
+			}},
+		},
+		Status: v1.PodStatus{
+			Phase:      v1.PodRunning,
+			Conditions: []v1.PodCondition{{Type: v1.PodReady}},
+		},
+	}
+
+	obj, err := transformObject(pod)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	pod = obj.(*v1.Pod)
+
+	if len(pod.ManagedFields) != 0 {
+		t.Errorf("expected managed fields to be stripped")
+	}
+	if _, ok := pod.Annotations[lastAppliedConfigAnnotation]; ok {
+		t.Errorf("expected last applied configuration to be stripped")
+	}
+	if pod.Annotations["team"] != "team1" || pod.Labels["app"] != "app1" {
+		t.Errorf("expected labels and annotations to be kept")
+	}
+
+	c := pod.Spec.Containers[0]
+	if c.Env != nil || c.Args != nil || c.LivenessProbe != nil || c.ReadinessProbe != nil {
+		t.Errorf("expected container environment, arguments and probes to be stripped")
+	}
+	if c.Image != "image1" || c.Resources.Requests.Cpu().MilliValue() != 500 || pod.Spec.NodeName != "node1" {
+		t.Errorf("expected image, requests and node to be kept")
+	}
+	if pod.Status.Conditions != nil || pod.Status.Phase != v1.PodRunning {
+		t.Errorf("expected conditions to be stripped and phase to be kept")
+	}
+
+	// daemonset arguments are kept
+	ds := &appsv1.DaemonSet{}
+	ds.Spec.Template.Spec.Containers = []v1.Container{{Args: []string{"--vgpu=10"}, Env: []v1.EnvVar{{Name: "KEY"}}}}
+	obj, _ = transformObject(ds)
+	c = obj.(*appsv1.DaemonSet).Spec.Template.Spec.Containers[0]
+	if len(c.Args) != 1 || c.Env != nil {
+		t.Errorf("expected daemonset arguments to be kept and environment stripped")
+	}
+
+	// tombstones are passed through
+	tombstone := cache.DeletedFinalStateUnknown{Key: "namespace1/pod1"}
+	if obj, err = transformObject(tombstone); err != nil || obj != tombstone {
+		t.Errorf("expected tombstone to be returned as-is")
+	}
+}
@@ -3,8 +3,10 @@ package clustercache
 import (
 	"fmt"
 	"reflect"
+	"sync"
 	"time"
 
+	"github.com/opencost/opencost/pkg/env"
 	"github.com/opencost/opencost/pkg/log"
 
 	"k8s.io/apimachinery/pkg/fields"
@@ -41,9 +43,10 @@ type WatchController interface {
 // CachingWatchController composites the watching behavior and a cache to ensure that all
 // up to date resources are readily available
 type CachingWatchController struct {
+	shared   *sharedInformer
 	indexer  cache.Indexer
 	queue    workqueue.RateLimitingInterface
-	informer cache.Controller
+	informer cache.SharedIndexInformer
 
 	resource     string
 	resourceType string
@@ -52,23 +55,128 @@ type CachingWatchController struct {
 	removeHandler WatchHandler
 }
 
-func NewCachingWatcher(restClient rest.Interface, resource string, resourceType rt.Object, namespace string, fieldSelector fields.Selector) WatchController {
-	resourceCache := cache.NewListWatchFromClient(restClient, resource, namespace, fieldSelector)
+// sharedInformer is an informer shared by all of the watch controllers of a
+// resource, so that each resource is listed, watched and cached only once.
+type sharedInformer struct {
+	informer cache.SharedIndexInformer
+	stopCh   <-chan struct{}
+	run      sync.Once
+}
+
+// start runs the informer until its factory is shut down, if it is not
+// already running.
+func (si *sharedInformer) start() {
+	si.run.Do(func() {
+		go si.informer.Run(si.stopCh)
+	})
+}
+
+type sharedInformerKey struct {
+	client        rest.Interface
+	resource      string
+	namespace     string
+	fieldSelector string
+}
+
+// SharedInformerFactory creates the informers of a cluster cache, sharing an
+// informer between its watch controllers of the same resource, namespace and
+// field selector. The informers run until the factory is shut down, regardless
+// of the channels their watch controllers warm up and run with.
+type SharedInformerFactory struct {
+	lock      sync.Mutex
+	informers map[sharedInformerKey]*sharedInformer
+	stopCh    chan struct{}
+	shutdown  bool
+}
+
+// NewSharedInformerFactory creates a factory without informers.
+func NewSharedInformerFactory() *SharedInformerFactory {
+	return &SharedInformerFactory{
+		informers: make(map[sharedInformerKey]*sharedInformer),
+		stopCh:    make(chan struct{}),
+	}
+}
+
+// Shutdown stops the informers of the factory, and removes their watch
+// controllers from the cluster cache metrics.
+func (f *SharedInformerFactory) Shutdown() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if !f.shutdown {
+		close(f.stopCh)
+		f.shutdown = true
+		unregisterCacheControllers(f.stopCh)
+	}
+}
+
+// IsShutdown returns true if the informers of the factory were stopped.
+func (f *SharedInformerFactory) IsShutdown() bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return f.shutdown
+}
+
+// informerFor returns the informer of the resource, creating it if it does
+// not exist. Objects are transformed to strip the fields OpenCost does not use
+// before being cached.
+func (f *SharedInformerFactory) informerFor(restClient rest.Interface, resource string, resourceType rt.Object, namespace string, fieldSelector fields.Selector) *sharedInformer {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	key := sharedInformerKey{
+		client:        restClient,
+		resource:      resource,
+		namespace:     namespace,
+		fieldSelector: fieldSelector.String(),
+	}
+	if si, ok := f.informers[key]; ok {
+		return si
+	}
+
+	lw := cache.NewListWatchFromClient(restClient, resource, namespace, fieldSelector)
+	instrumentListWatch(lw, resource)
+
+	informer := cache.NewSharedIndexInformer(lw, resourceType, 0, cache.Indexers{
+		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+	})
+	if env.IsClusterCacheTransformEnabled() {
+		if err := informer.SetTransform(transformObject); err != nil {
+			log.Warnf("Unable to set the transform of the %s informer: %s", resource, err)
+		}
+	}
+
+	si := &sharedInformer{informer: informer, stopCh: f.stopCh}
+	f.informers[key] = si
+	return si
+}
+
+// NewCachingWatcher creates a watch controller caching the resource with an
+// informer of the factory.
+func NewCachingWatcher(factory *SharedInformerFactory, restClient rest.Interface, resource string, resourceType rt.Object, namespace string, fieldSelector fields.Selector) WatchController {
+	initCacheMetrics()
+
+	shared := factory.informerFor(restClient, resource, resourceType, namespace, fieldSelector)
+
 	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
-	indexer, informer := cache.NewIndexerInformer(resourceCache, resourceType, 0, cache.ResourceEventHandlerFuncs{
+	shared.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
+			cacheLastEvent.WithLabelValues(resource).SetToCurrentTime()
 			key, err := cache.MetaNamespaceKeyFunc(obj)
 			if err == nil {
 				queue.Add(key)
 			}
 		},
 		UpdateFunc: func(old interface{}, new interface{}) {
+			cacheLastEvent.WithLabelValues(resource).SetToCurrentTime()
 			key, err := cache.MetaNamespaceKeyFunc(new)
 			if err == nil {
 				queue.Add(key)
 			}
 		},
 		DeleteFunc: func(obj interface{}) {
+			cacheLastEvent.WithLabelValues(resource).SetToCurrentTime()
 			// IndexerInformer uses a delta queue, therefore for deletes we have to use this
 			// key function.
 			key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
@@ -76,15 +184,19 @@ func NewCachingWatcher(restClient rest.Interface, resource string, resourceType
 				queue.Add(key)
 			}
 		},
-	}, cache.Indexers{})
+	})
 
-	return &CachingWatchController{
-		indexer:      indexer,
+	c := &CachingWatchController{
+		shared:       shared,
+		indexer:      shared.informer.GetIndexer(),
 		queue:        queue,
-		informer:     informer,
+		informer:     shared.informer,
 		resource:     resource,
 		resourceType: reflect.TypeOf(resourceType).String(),
 	}
+	registerCacheController(c)
+
+	return c
 }
 
 func (c *CachingWatchController) GetAll() []interface{} {
@@ -177,14 +289,18 @@ func (c *CachingWatchController) handleErr(err error, key interface{}) {
 	log.Infof("Dropping %s %q out of the queue: %v", c.resourceType, key, err)
 }
 
+// WarmUp starts the informer of the controller, if not already started, and
+// waits for its cache to sync or the cancel channel to close.
 func (c *CachingWatchController) WarmUp(cancelCh chan struct{}) {
-	go c.informer.Run(cancelCh)
+	start := time.Now()
+	c.shared.start()
 
 	// Wait for all involved caches to be synced, before processing items from the queue is started
 	if !cache.WaitForCacheSync(cancelCh, c.informer.HasSynced) {
 		runtime.HandleError(fmt.Errorf("Timed out waiting for caches to sync"))
 		return
 	}
+	cacheSyncDuration.WithLabelValues(c.resource).Set(time.Since(start).Seconds())
 }
 
 func (c *CachingWatchController) Run(threadiness int, stopCh chan struct{}) {
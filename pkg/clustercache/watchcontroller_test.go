@@ -0,0 +1,73 @@
+package clustercache
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/rest"
+)
+
+func TestSharedInformerFactory(t *testing.T) {
+	client := &rest.RESTClient{}
+
+	f1 := NewSharedInformerFactory()
+	pods := f1.informerFor(client, "pods", &v1.Pod{}, "", fields.Everything())
+	if f1.informerFor(client, "pods", &v1.Pod{}, "", fields.Everything()) != pods {
+		t.Errorf("expected watch controllers of the same resource to share an informer")
+	}
+	if f1.informerFor(client, "pods", &v1.Pod{}, "namespace1", fields.Everything()) == pods {
+		t.Errorf("expected a separate informer for another namespace")
+	}
+
+	// the informers of one cache are not shared with another, or stopped
+	// with it
+	f2 := NewSharedInformerFactory()
+	if f2.informerFor(client, "pods", &v1.Pod{}, "", fields.Everything()) == pods {
+		t.Errorf("expected factories not to share informers")
+	}
+
+	f1.Shutdown()
+	f1.Shutdown()
+	if !f1.IsShutdown() || f2.IsShutdown() {
+		t.Errorf("expected only the shut down factory to be shut down")
+	}
+	select {
+	case <-pods.stopCh:
+	default:
+		t.Errorf("expected the informers of the factory to be stopped")
+	}
+}
+
+func TestSharedInformerFactory_ShutdownUnregisters(t *testing.T) {
+	client := &rest.RESTClient{}
+
+	watched := func(resource string) int {
+		var n int
+		for _, stats := range Stats() {
+			if stats.Resource == resource {
+				n++
+			}
+		}
+		return n
+	}
+
+	f1 := NewSharedInformerFactory()
+	NewCachingWatcher(f1, client, "replicationcontrollers", &v1.ReplicationController{}, "", fields.Everything())
+	f2 := NewSharedInformerFactory()
+	defer f2.Shutdown()
+	NewCachingWatcher(f2, client, "persistentvolumeclaims", &v1.PersistentVolumeClaim{}, "", fields.Everything())
+
+	if watched("replicationcontrollers") != 1 || watched("persistentvolumeclaims") != 1 {
+		t.Fatalf("expected the watch controllers of both caches in the stats; got %+v", Stats())
+	}
+
+	// the watch controllers of a stopped cache are no longer reported
+	f1.Shutdown()
+	if watched("replicationcontrollers") != 0 {
+		t.Errorf("expected the watch controllers of the shut down cache to be removed; got %+v", Stats())
+	}
+	if watched("persistentvolumeclaims") != 1 {
+		t.Errorf("expected the watch controllers of the running cache to remain; got %+v", Stats())
+	}
+}
@@ -22,7 +22,6 @@ import (
 
 	prometheus "github.com/prometheus/client_golang/api"
 	prometheusAPI "github.com/prometheus/client_golang/api/prometheus/v1"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/rs/cors"
@@ -74,7 +73,7 @@ func newPrometheusClient() (prometheus.Client, error) {
 	log.Infof("Prometheus Client Max Concurrency set to %d", queryConcurrency)
 
 	timeout := 120 * time.Second
-	keepAlive := 120 * time.Second
+	keepAlive := env.GetPrometheusKeepAlive()
 	tlsHandshakeTimeout := 10 * time.Second
 
 	var rateLimitRetryOpts *prom.RateLimitRetryOpts = nil
@@ -96,8 +95,13 @@ func newPrometheusClient() (prometheus.Client, error) {
 			Password:    env.GetDBBasicAuthUserPassword(),
 			BearerToken: env.GetDBBearerToken(),
 		},
-		QueryConcurrency: queryConcurrency,
-		QueryLogFile:     "",
+		QueryConcurrency:      queryConcurrency,
+		QueryLogFile:          "",
+		MaxIdleConns:          env.GetPrometheusMaxIdleConns(),
+		MaxIdleConnsPerHost:   env.GetPrometheusMaxIdleConnsPerHost(),
+		IdleConnTimeout:       env.GetPrometheusIdleConnTimeout(),
+		ResponseHeaderTimeout: env.GetPrometheusResponseHeaderTimeout(),
+		HTTP2:                 env.IsPrometheusHTTP2Enabled(),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("Failed to create prometheus client, Error: %v", err)
@@ -220,7 +224,7 @@ func Execute(opts *AgentOpts) error {
 
 	rootMux := http.NewServeMux()
 	rootMux.HandleFunc("/healthz", Healthz)
-	rootMux.Handle("/metrics", promhttp.Handler())
+	rootMux.Handle("/metrics", metrics.Handler())
 	telemetryHandler := metrics.ResponseMetricMiddleware(rootMux)
 	handler := cors.AllowAll().Handler(telemetryHandler)
 
@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/opencost/opencost/pkg/cmd/agent"
 	"github.com/opencost/opencost/pkg/cmd/costmodel"
+	"github.com/opencost/opencost/pkg/cmd/query"
 	"github.com/opencost/opencost/pkg/log"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -21,6 +23,12 @@ const (
 
 	// CommandAgent executes the application in agent mode, which provides only metrics exporting.
 	CommandAgent string = "agent"
+
+	// CommandBackfill recomputes allocation and asset data for a past range into the durable store.
+	CommandBackfill string = "backfill"
+
+	// CommandQuery queries a running OpenCost API and renders the results in the terminal.
+	CommandQuery string = "query"
 )
 
 // Execute runs the root command for the application. By default, if no command argument is provided,
@@ -88,6 +96,8 @@ func newRootCommand(costModelCmd *cobra.Command, cmds ...*cobra.Command) *cobra.
 		append([]*cobra.Command{
 			costModelCmd,
 			newAgentCommand(),
+			newBackfillCommand(),
+			newQueryCommand(),
 		}, cmds...)...,
 	)
 
@@ -137,6 +147,54 @@ func newAgentCommand() *cobra.Command {
 	return agentCmd
 }
 
+func newBackfillCommand() *cobra.Command {
+	opts := &costmodel.BackfillOpts{}
+
+	backfillCmd := &cobra.Command{
+		Use:   CommandBackfill,
+		Short: "Recompute allocation and asset data for a past range into the durable store.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.InitLogging(true)
+			return costmodel.Backfill(opts)
+		},
+	}
+
+	backfillCmd.Flags().StringVar(&opts.Start, "start", "", "Start of the range, as an RFC3339 time or a date (required)")
+	backfillCmd.Flags().StringVar(&opts.End, "end", "", "End of the range, as an RFC3339 time or a date (default now)")
+	backfillCmd.MarkFlagRequired("start")
+
+	return backfillCmd
+}
+
+func newQueryCommand() *cobra.Command {
+	queryCmd := &cobra.Command{
+		Use:   CommandQuery,
+		Short: "Query a running OpenCost API and render the results as a table, CSV or JSON.",
+	}
+
+	opts := &query.AllocationOpts{}
+
+	allocationCmd := &cobra.Command{
+		Use:   "allocation",
+		Short: "Query allocation costs, e.g. 'query allocation --window 7d --aggregate namespace'.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return query.QueryAllocation(cmd.OutOrStdout(), opts)
+		},
+	}
+
+	allocationCmd.Flags().StringVar(&opts.Server, "server", "http://localhost:9003", "Base URL of the OpenCost API")
+	allocationCmd.Flags().StringVar(&opts.Window, "window", "1d", "Window to query, e.g. '7d', 'today' or 'lastweek'")
+	allocationCmd.Flags().StringVar(&opts.Aggregate, "aggregate", "", "Comma-separated properties to aggregate by, e.g. 'namespace' or 'namespace,label:app'")
+	allocationCmd.Flags().BoolVar(&opts.IncludeIdle, "include-idle", false, "Include idle costs")
+	allocationCmd.Flags().StringVarP(&opts.Output, "output", "o", query.OutputTable, "Output format: table, csv or json")
+	allocationCmd.Flags().DurationVar(&opts.Timeout, "timeout", 5*time.Minute, "Timeout of the request to the API")
+
+	queryCmd.AddCommand(allocationCmd)
+
+	return queryCmd
+}
+
 // validate checks the command's use to see if it matches an expected command name.
 func validate(cmd *cobra.Command, command string) error {
 	if cmd.Use != command {
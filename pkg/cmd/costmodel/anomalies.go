@@ -0,0 +1,86 @@
+package costmodel
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/opencost/opencost/pkg/costmodel"
+	"github.com/opencost/opencost/pkg/env"
+	"github.com/opencost/opencost/pkg/exporter"
+	"github.com/opencost/opencost/pkg/log"
+	"github.com/opencost/opencost/pkg/util/httputil"
+)
+
+// StartCostAnomalyDetector starts analyzing daily namespace and asset costs for spikes, which are
+// emitted as events and sent to the channels configured by NOTIFICATIONS_CONFIG_PATH, if set. The
+// anomalies detected are persisted to cost-anomalies.json in the config path, so that none is
+// reported twice. An error is returned if anomaly detection is disabled.
+func StartCostAnomalyDetector(a *costmodel.Accesses) (*exporter.CostAnomalyDetector, error) {
+	if !env.IsCostAnomalyEnabled() {
+		return nil, fmt.Errorf("%s is not true", env.CostAnomalyEnabledEnvVar)
+	}
+
+	var notifier *exporter.Notifier
+	if path := env.GetNotificationsConfigPath(); path != "" {
+		channels, err := exporter.LoadNotificationChannels(path)
+		if err != nil {
+			return nil, err
+		}
+		notifier = exporter.NewNotifier(channels)
+	}
+
+	file := a.ConfigFileManager.ConfigFileAt(path.Join(env.GetConfigPathWithDefault("/var/configs/"), "cost-anomalies.json"))
+	detector, err := exporter.NewCostAnomalyDetector(a.Model, notifier, file, &exporter.CostAnomalyDetectorConfig{
+		Interval:   env.GetCostAnomalyInterval(),
+		WindowDays: env.GetCostAnomalyWindowDays(),
+		Threshold:  env.GetCostAnomalyThreshold(),
+		MinCost:    env.GetCostAnomalyMinCost(),
+		Resolution: env.GetETLResolution(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	startWorker(detector)
+
+	log.Infof("Detecting cost anomalies over %d days", env.GetCostAnomalyWindowDays())
+	return detector, nil
+}
+
+// RegisterCostAnomalyHandlers adds an endpoint listing the cost anomalies detected over the
+// window, newest first, optionally filtered by level, cluster and namespace:
+//
+//	GET /anomalies?level=namespace&cluster=cluster-one&namespace=kubecost
+func RegisterCostAnomalyHandlers(router *httprouter.Router, d *exporter.CostAnomalyDetector) {
+	router.GET("/anomalies", func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+
+		qp := httputil.NewQueryParams(r.URL.Query())
+		level := qp.Get("level", "")
+		cluster := qp.Get("cluster", "")
+		namespace := qp.Get("namespace", "")
+
+		if level != "" && level != string(exporter.CostAnomalyLevelNamespace) && level != string(exporter.CostAnomalyLevelAsset) {
+			http.Error(w, fmt.Sprintf("Invalid 'level' parameter: %s", level), http.StatusBadRequest)
+			return
+		}
+
+		anomalies := []*exporter.CostAnomaly{}
+		for _, anomaly := range d.Anomalies() {
+			if level != "" && string(anomaly.Level) != level {
+				continue
+			}
+			if cluster != "" && anomaly.Cluster != cluster {
+				continue
+			}
+			if namespace != "" && anomaly.Namespace != namespace {
+				continue
+			}
+			anomalies = append(anomalies, anomaly)
+		}
+
+		w.Write(costmodel.WrapData(anomalies, nil))
+	})
+}
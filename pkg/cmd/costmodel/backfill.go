@@ -0,0 +1,120 @@
+package costmodel
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/opencost/opencost/pkg/costmodel"
+	"github.com/opencost/opencost/pkg/exporter"
+	"github.com/opencost/opencost/pkg/log"
+	"github.com/opencost/opencost/pkg/store"
+	"github.com/opencost/opencost/pkg/util/httputil"
+)
+
+// BackfillOpts contains the range recomputed by the backfill command.
+type BackfillOpts struct {
+	// Start is the start of the range, as an RFC3339 time or a date.
+	Start string
+
+	// End is the end of the range, as an RFC3339 time or a date. Defaults to
+	// now, in which case the range ends with the most recent finalized window.
+	End string
+}
+
+// Backfill recomputes allocation and asset data for a past range from
+// Prometheus, or Thanos if enabled, window by window, and writes it to the
+// durable store. Windows already in the store are skipped, so an interrupted
+// backfill resumes where it stopped when run again.
+func Backfill(opts *BackfillOpts) error {
+	start, end, err := parseBackfillRange(opts.Start, opts.End)
+	if err != nil {
+		return err
+	}
+
+	s, err := newStore()
+	if err != nil {
+		return fmt.Errorf("backfill requires a durable store: %w", err)
+	}
+	defer s.Close()
+
+	a := costmodel.Initialize()
+	exp := exporter.NewExporter(a.Model, store.NewCheckpoints(s), exporterConfig(), s)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	job, err := exporter.NewBackfiller(exp).Run(ctx, start, end, func(job *exporter.BackfillJob) {
+		log.Infof("Backfill: %s, last %s", job, job.Current)
+	})
+	if err != nil {
+		return err
+	}
+	if job.State != exporter.BackfillStateCompleted {
+		return fmt.Errorf("backfill %s", job)
+	}
+
+	return nil
+}
+
+func parseBackfillRange(startStr, endStr string) (time.Time, time.Time, error) {
+	start, err := parseExportBackfillStart(startStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid start '%s': %w", startStr, err)
+	}
+
+	end := time.Now().UTC()
+	if endStr != "" {
+		end, err = parseExportBackfillStart(endStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid end '%s': %w", endStr, err)
+		}
+	}
+
+	return start, end, nil
+}
+
+// RegisterBackfillHandlers adds endpoints to start, follow and cancel backfills
+// through the exporter's sinks:
+//
+//	POST /backfill?start=2023-01-01&end=2023-02-01
+//	GET /backfill
+//	DELETE /backfill
+func RegisterBackfillHandlers(router *httprouter.Router, b *exporter.Backfiller) {
+	router.POST("/backfill", func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+
+		qp := httputil.NewQueryParams(r.URL.Query())
+		start, end, err := parseBackfillRange(qp.Get("start", ""), qp.Get("end", ""))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		job, err := b.Start(start, end)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+		w.Write(costmodel.WrapData(job, nil))
+	})
+
+	router.GET("/backfill", func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(costmodel.WrapData(b.Status(), nil))
+	})
+
+	router.DELETE("/backfill", func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+		b.Cancel()
+		w.Write(costmodel.WrapData(b.Status(), nil))
+	})
+}
@@ -2,20 +2,31 @@ package costmodel
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/julienschmidt/httprouter"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/cors"
+	"k8s.io/client-go/dynamic"
 
+	"github.com/opencost/opencost/pkg/admission"
+	"github.com/opencost/opencost/pkg/config"
 	"github.com/opencost/opencost/pkg/costmodel"
+	"github.com/opencost/opencost/pkg/crd"
 	"github.com/opencost/opencost/pkg/env"
 	"github.com/opencost/opencost/pkg/errors"
+	"github.com/opencost/opencost/pkg/events"
+	"github.com/opencost/opencost/pkg/exporter"
 	"github.com/opencost/opencost/pkg/filemanager"
+	"github.com/opencost/opencost/pkg/kubeconfig"
+	"github.com/opencost/opencost/pkg/leader"
 	"github.com/opencost/opencost/pkg/log"
 	"github.com/opencost/opencost/pkg/metrics"
+	"github.com/opencost/opencost/pkg/storage"
+	"github.com/opencost/opencost/pkg/store"
 	"github.com/opencost/opencost/pkg/version"
 )
 
@@ -24,6 +35,20 @@ type CostModelOpts struct {
 	// Stubbed for future configuration
 }
 
+// elector, if leader election is enabled, runs the background workers only
+// while this replica is the leader
+var elector *leader.Elector
+
+// startWorker starts a background worker, or, if leader election is enabled,
+// registers it to run only while this replica is the leader.
+func startWorker(w leader.Worker) {
+	if elector != nil {
+		elector.Add(w)
+		return
+	}
+	w.Start()
+}
+
 func Healthz(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
 	w.WriteHeader(200)
 	w.Header().Set("Content-Length", "0")
@@ -32,26 +57,111 @@ func Healthz(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
 
 func Execute(opts *CostModelOpts) error {
 	log.Infof("Starting cost-model version %s", version.FriendlyVersion())
+
+	// start the event dispatcher first so that failures during initialization
+	// are delivered
+	err := StartEventDispatcher()
+	if err != nil {
+		log.Infof("Event dispatcher not started: %v", err)
+	}
+
 	a := costmodel.Initialize()
 
-	err := StartExportWorker(context.Background(), a.Model)
+	err = StartLeaderElection(a)
+	if err != nil {
+		log.Infof("Leader election not started: %v", err)
+
+		// the router leaves the emitter to the elector when leader election
+		// is enabled, so without one this replica emits the metrics
+		if env.IsLeaderElectionEnabled() && !env.IsKubecostMetricsPodEnabled() {
+			log.Warnf("Leader election failed; emitting metrics from this replica")
+			a.MetricsEmitter.Start()
+		}
+	}
+
+	err = StartExportWorker(context.Background(), a.Model)
 	if err != nil {
 		log.Errorf("couldn't start CSV export worker: %v", err)
 	}
 
+	err = StartStore(a.Model)
+	if err != nil {
+		log.Infof("Durable store not started: %v", err)
+	}
+
+	exp, err := StartExporter(a.Model)
+	if err != nil {
+		log.Infof("Exporter not started: %v", err)
+	}
+
+	err = StartConfigReloader(a, exp)
+	if err != nil {
+		log.Infof("Config reloader not started: %v", err)
+	}
+
+	err = StartMetricsPusher(a.Model)
+	if err != nil {
+		log.Infof("Metrics pusher not started: %v", err)
+	}
+
+	anomalies, err := StartCostAnomalyDetector(a)
+	if err != nil {
+		log.Infof("Cost anomaly detector not started: %v", err)
+	}
+
+	err = StartCRDController(a)
+	if err != nil {
+		log.Infof("CRD controller not started: %v", err)
+	}
+
+	err = StartAdmissionWebhook(a)
+	if err != nil {
+		log.Infof("Admission webhook not started: %v", err)
+	}
+
 	rootMux := http.NewServeMux()
 	a.Router.GET("/healthz", Healthz)
 	a.Router.GET("/allocation", a.ComputeAllocationHandler)
 	a.Router.GET("/allocation/summary", a.ComputeAllocationHandlerSummary)
 	a.Router.GET("/assets", a.ComputeAssetsHandler)
+	if exp != nil {
+		RegisterBackfillHandlers(a.Router, exporter.NewBackfiller(exp))
+	}
+	if anomalies != nil {
+		RegisterCostAnomalyHandlers(a.Router, anomalies)
+	}
 	rootMux.Handle("/", a.Router)
-	rootMux.Handle("/metrics", promhttp.Handler())
+	rootMux.Handle("/metrics", metrics.Handler())
 	telemetryHandler := metrics.ResponseMetricMiddleware(rootMux)
 	handler := cors.AllowAll().Handler(telemetryHandler)
 
 	return http.ListenAndServe(":9003", errors.PanicHandlerMiddleware(handler))
 }
 
+// StartEventDispatcher delivers lifecycle events to the webhooks configured by
+// EVENTS_WEBHOOKS_CONFIG_PATH.
+func StartEventDispatcher() error {
+	configPath := env.GetEventsWebhooksConfigPath()
+	if configPath == "" {
+		return fmt.Errorf("%s is not set", env.EventsWebhooksConfigPathEnvVar)
+	}
+
+	endpoints, err := events.LoadEndpoints(configPath)
+	if err != nil {
+		return err
+	}
+
+	config := events.DefaultDispatcherConfig()
+	config.QueueSize = env.GetEventsQueueSize()
+	if attempts := env.GetEventsRetryAttempts(); attempts > 0 {
+		config.RetryAttempts = uint(attempts)
+	}
+
+	events.SetDefaultDispatcher(events.NewDispatcher(endpoints, config))
+	log.Infof("Events: delivering to %d endpoints", len(endpoints))
+	return nil
+}
+
 func StartExportWorker(ctx context.Context, model costmodel.AllocationModel) error {
 	exportPath := env.GetExportCSVFile()
 	if exportPath == "" {
@@ -61,7 +171,17 @@ func StartExportWorker(ctx context.Context, model costmodel.AllocationModel) err
 	if err != nil {
 		return fmt.Errorf("could not create file manager: %v", err)
 	}
-	go func() {
+	startWorker(leader.NewFuncWorker(func(workerCtx context.Context) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go func() {
+			select {
+			case <-workerCtx.Done():
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
 		log.Info("Starting CSV exporter worker...")
 
 		// perform first update immediately
@@ -82,6 +202,480 @@ func StartExportWorker(ctx context.Context, model costmodel.AllocationModel) err
 				nextRunAt = time.Date(now.Year(), now.Month(), now.Day(), 0, 10, 0, 0, now.Location()).AddDate(0, 0, 1)
 			}
 		}
+	}))
+	return nil
+}
+
+// StartExporter starts exporting finalized allocation and asset windows to each of the
+// configured sinks. An error is returned if no sinks are configured.
+func StartExporter(model *costmodel.CostModel) (*exporter.Exporter, error) {
+	var sinks []exporter.Sink
+	var checkpoints exporter.Checkpoints
+
+	if bucketConfig := env.GetExportBucketConfig(); bucketConfig != "" {
+		store, err := newExportBucketStorage(bucketConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		codec, err := costmodel.ParseParquetCompression(env.GetExportCompression())
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", env.ExportCompressionEnvVar, err)
+		}
+
+		sinks = append(sinks, exporter.NewParquetStorageSink(store, codec, nil, nil))
+		checkpoints = exporter.NewStorageCheckpoints(store, "_checkpoints")
+	}
+
+	if kafkaURL := env.GetExportKafkaRESTURL(); kafkaURL != "" {
+		publisher, err := exporter.NewKafkaRESTPublisher(&exporter.KafkaRESTConfig{
+			URL:      kafkaURL,
+			Encoding: exporter.MessageEncoding(env.GetExportKafkaEncoding()),
+			Username: env.GetExportKafkaUsername(),
+			Password: env.GetExportKafkaPassword(),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		keyFunc, err := exporter.ParseEventKeyFunc(env.GetExportKafkaKey())
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", env.ExportKafkaKeyEnvVar, err)
+		}
+
+		sinks = append(sinks, exporter.NewMessageSink(publisher, env.GetExportKafkaTopic(), keyFunc, env.GetExportKafkaBatchSize()))
+	}
+
+	if projectID := env.GetExportBigQueryProjectID(); projectID != "" {
+		sink, err := exporter.NewBigQuerySink(context.Background(), &exporter.BigQueryConfig{
+			ProjectID:       projectID,
+			Dataset:         env.GetExportBigQueryDataset(),
+			Location:        env.GetExportBigQueryLocation(),
+			AllocationTable: env.GetExportBigQueryAllocationTable(),
+			AssetTable:      env.GetExportBigQueryAssetTable(),
+			CredentialsFile: env.GetExportBigQueryCredentialsFile(),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		sinks = append(sinks, sink)
+	}
+
+	if bucketConfig := env.GetExportWarehouseBucketConfig(); bucketConfig != "" {
+		sink, err := newWarehouseSink(bucketConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		sinks = append(sinks, sink)
+	}
+
+	if s := model.Store(); s != nil {
+		sinks = append(sinks, s)
+
+		// the store records which windows it contains, so exports resume across
+		// restarts without checkpoints of their own
+		if len(sinks) == 1 {
+			checkpoints = store.NewCheckpoints(s)
+		}
+	}
+
+	if len(sinks) == 0 {
+		return nil, fmt.Errorf("no export sinks configured")
+	}
+
+	var backfillStart time.Time
+	if s := env.GetExportBackfillStart(); s != "" {
+		t, err := parseExportBackfillStart(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", env.ExportBackfillStartEnvVar, err)
+		}
+		backfillStart = t
+	}
+
+	config := exporterConfig()
+	config.BackfillStart = backfillStart
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", env.ExportIntervalEnvVar, err)
+	}
+
+	exp := exporter.NewExporter(model, checkpoints, config, sinks...)
+
+	log.Infof("Starting exporter with %d sink(s)", len(sinks))
+	startWorker(exp)
+
+	return exp, nil
+}
+
+// exporterConfig returns the exporter options configured by the environment.
+func exporterConfig() *exporter.ExporterConfig {
+	return &exporter.ExporterConfig{
+		Interval:       env.GetExportInterval(),
+		WindowDuration: env.GetExportWindowDuration(),
+		Delay:          env.GetExportDelay(),
+		Lookback:       env.GetExportLookback(),
+		Resolution:     env.GetETLResolution(),
+		RetryAttempts:  env.GetExportRetryAttempts(),
+		RetryDelay:     env.GetExportRetryDelay(),
+	}
+}
+
+// StartStore configures the durable store of finalized windows, which serves historical
+// queries and is populated by the exporter, and starts its compaction.
+func StartStore(model *costmodel.CostModel) error {
+	s, err := newStore()
+	if err != nil {
+		return err
+	}
+
+	model.SetStore(s, env.GetStoreQueryAfter())
+	startWorker(store.NewCompactor(s, env.GetStoreCompactionInterval()))
+
+	log.Infof("Durable store: using %s", s.Name())
+	return nil
+}
+
+// newStore creates the configured durable store of finalized windows.
+func newStore() (store.Store, error) {
+	retention := store.RetentionConfig{
+		Retention:   env.GetStoreRetention(),
+		RollupAfter: env.GetStoreRollupAfter(),
+	}
+
+	switch {
+	case env.GetStorePostgresDSN() != "":
+		return store.NewPostgresStore(context.Background(), &store.PostgresConfig{
+			DSN:             env.GetStorePostgresDSN(),
+			ClusterID:       env.GetClusterID(),
+			RetentionConfig: retention,
+		})
+	case env.GetStoreBoltPath() != "":
+		return store.NewBoltStore(&store.BoltConfig{
+			Path:            env.GetStoreBoltPath(),
+			MaxSize:         int64(env.GetStoreBoltMaxSizeMB()) * 1024 * 1024,
+			RetentionConfig: retention,
+		})
+	default:
+		return nil, fmt.Errorf("neither %s nor %s is set", env.StorePostgresDSNEnvVar, env.StoreBoltPathEnvVar)
+	}
+}
+
+// newExportBucketStorage creates the bucket storage described by the configuration file at the given path.
+func newExportBucketStorage(configPath string) (storage.Storage, error) {
+	config, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading export bucket config: %w", err)
+	}
+
+	store, err := storage.NewBucketStorage(config)
+	if err != nil {
+		return nil, fmt.Errorf("creating export bucket storage: %w", err)
+	}
+
+	return store, nil
+}
+
+// StartConfigReloader reloads custom pricing, discounts, label mappings and, if the exporter is
+// running, exporter settings from the files in CONFIG_RELOAD_PATH whenever they change. Unlike
+// the background workers, it runs on every replica, as each serves the API.
+func StartConfigReloader(a *costmodel.Accesses, exp *exporter.Exporter) error {
+	path := env.GetConfigReloadPath()
+	if path == "" {
+		return fmt.Errorf("%s is not set", env.ConfigReloadPathEnvVar)
+	}
+
+	reloader := config.NewReloader(path, env.GetConfigReloadInterval())
+	reloader.Register("pricing", costmodel.PricingReloadFunc(a.CloudProvider))
+	reloader.Register("labels", costmodel.ReloadLabelConfig)
+	if exp != nil {
+		reloader.Register("exporter", exp.ReloadConfig)
+	}
+	reloader.Start()
+
+	log.Infof("Reloading configuration from %s", path)
+	return nil
+}
+
+// StartMetricsPusher starts pushing namespace and workload cost metrics to each of the configured
+// metrics backends, and sending notifications to the configured chat channels. An error is
+// returned if neither are configured.
+func StartMetricsPusher(model *costmodel.CostModel) error {
+	var publishers []exporter.CostMetricsPublisher
+
+	if endpoint := env.GetOTLPEndpoint(); endpoint != "" {
+		publisher, err := exporter.NewOTLPPublisher(&exporter.OTLPConfig{
+			Endpoint:           endpoint,
+			Headers:            env.GetOTLPHeaders(),
+			ResourceAttributes: env.GetOTLPResourceAttributes(),
+			Currency:           func() string { return costmodel.SourceCurrency(model.Provider) },
+		})
+		if err != nil {
+			return err
+		}
+
+		publishers = append(publishers, publisher)
+	}
+
+	if apiKey := env.GetDatadogAPIKey(); apiKey != "" {
+		publisher, err := exporter.NewDatadogPublisher(&exporter.DatadogConfig{
+			APIKey:    apiKey,
+			Site:      env.GetDatadogSite(),
+			TagLabels: env.GetDatadogTagLabels(),
+			Tags:      env.GetDatadogTags(),
+			BatchSize: env.GetDatadogBatchSize(),
+		})
+		if err != nil {
+			return err
+		}
+
+		publishers = append(publishers, publisher)
+	}
+
+	var notifier *exporter.Notifier
+	if path := env.GetNotificationsConfigPath(); path != "" {
+		channels, err := exporter.LoadNotificationChannels(path)
+		if err != nil {
+			return err
+		}
+		notifier = exporter.NewNotifier(channels)
+
+		if interval := env.GetSavingsReportInterval(); interval > 0 {
+			reporter := exporter.NewSavingsReporter(model, notifier, &exporter.SavingsReporterConfig{
+				Interval:   interval,
+				Top:        env.GetSavingsReportTop(),
+				Resolution: env.GetETLResolution(),
+			})
+			startWorker(reporter)
+		}
+	}
+
+	if len(publishers) == 0 && notifier == nil {
+		return fmt.Errorf("no metrics backends configured")
+	}
+
+	var budgets []*exporter.Budget
+	if path := env.GetBudgetsConfigPath(); path != "" {
+		var err error
+		budgets, err = exporter.LoadBudgets(path)
+		if err != nil {
+			return err
+		}
+	}
+
+	pusherConfig := &exporter.MetricsPusherConfig{
+		Interval:   env.GetMetricsPushInterval(),
+		Window:     env.GetMetricsPushWindow(),
+		Resolution: env.GetETLResolution(),
+		Budgets:    budgets,
+	}
+	if err := pusherConfig.Validate(); err != nil {
+		return fmt.Errorf("invalid %s: %w", env.MetricsPushIntervalEnvVar, err)
+	}
+
+	pusher := exporter.NewMetricsPusher(model, pusherConfig, publishers...)
+	if notifier != nil {
+		pusher.AddBudgetBreachPublisher(notifier)
+	}
+
+	log.Infof("Starting metrics pusher with %d backend(s)", len(publishers))
+	startWorker(pusher)
+
+	return nil
+}
+
+// StartLeaderElection starts electing a leader among the replicas through a Kubernetes Lease.
+// Metrics emission and the background workers started afterwards run only on the leader, while
+// all replicas serve the API. An error is returned if leader election is disabled.
+func StartLeaderElection(a *costmodel.Accesses) error {
+	if !env.IsLeaderElectionEnabled() {
+		return fmt.Errorf("%s is not true", env.LeaderElectionEnabledEnvVar)
+	}
+
+	identity := env.GetPodName()
+	if identity == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return fmt.Errorf("determining leader election identity: %w", err)
+		}
+		identity = hostname
+	}
+
+	client, err := kubeconfig.LoadKubeClient("")
+	if err != nil {
+		return fmt.Errorf("loading kube client: %w", err)
+	}
+
+	e, err := leader.NewElector(client, &leader.ElectorConfig{
+		Namespace:     env.GetLeaderElectionNamespace(),
+		LeaseName:     env.GetLeaderElectionLeaseName(),
+		Identity:      identity,
+		LeaseDuration: env.GetLeaderElectionLeaseDuration(),
+		RenewDeadline: env.GetLeaderElectionRenewDeadline(),
+		RetryPeriod:   env.GetLeaderElectionRetryPeriod(),
+	})
+	if err != nil {
+		return err
+	}
+
+	elector = e
+	if !env.IsKubecostMetricsPodEnabled() {
+		elector.Add(a.MetricsEmitter)
+	}
+	elector.Start(context.Background())
+
+	log.Infof("Leader election: %s campaigning for lease %s/%s", identity, env.GetLeaderElectionNamespace(), env.GetLeaderElectionLeaseName())
+	return nil
+}
+
+// StartCRDController starts reconciling the CostBudget, CostReport and PricingOverride custom
+// resources. Reports and budget breaches are sent to the channels configured by
+// NOTIFICATIONS_CONFIG_PATH, if set.
+func StartCRDController(a *costmodel.Accesses) error {
+	if !env.IsCRDControllerEnabled() {
+		return fmt.Errorf("%s is not true", env.CRDControllerEnabledEnvVar)
+	}
+
+	config, err := kubeconfig.LoadKubeconfig("")
+	if err != nil {
+		return fmt.Errorf("loading kubeconfig: %w", err)
+	}
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("creating dynamic client: %w", err)
+	}
+
+	var notifier *exporter.Notifier
+	if path := env.GetNotificationsConfigPath(); path != "" {
+		channels, err := exporter.LoadNotificationChannels(path)
+		if err != nil {
+			return err
+		}
+		notifier = exporter.NewNotifier(channels)
+	}
+
+	controller := crd.NewController(client, a.Model, a.CloudProvider, notifier, &crd.ControllerConfig{
+		Interval:     env.GetCRDControllerInterval(),
+		BudgetWindow: env.GetMetricsPushWindow(),
+		Resolution:   env.GetETLResolution(),
+	})
+	startWorker(controller)
+
+	log.Infof("Started CRD controller")
+	return nil
+}
+
+// StartAdmissionWebhook serves the mutating admission webhook at /mutate, which annotates
+// Deployments and StatefulSets with their estimated cost, and the validating admission webhook
+// at /validate, which enforces namespace budgets if ADMISSION_WEBHOOK_ENFORCE_BUDGETS is true.
+func StartAdmissionWebhook(a *costmodel.Accesses) error {
+	certFile := env.GetAdmissionWebhookTLSCertFile()
+	if certFile == "" {
+		return fmt.Errorf("%s is not set", env.AdmissionWebhookTLSCertFileEnvVar)
+	}
+
+	var budgets []*exporter.Budget
+	if env.IsAdmissionWebhookEnforceBudgets() {
+		path := env.GetBudgetsConfigPath()
+		if path == "" {
+			return fmt.Errorf("%s is required to enforce budgets", env.BudgetsConfigPathEnvVar)
+		}
+
+		var err error
+		budgets, err = exporter.LoadBudgets(path)
+		if err != nil {
+			return err
+		}
+	}
+
+	webhook := admission.NewWebhook(a.Model, a.CloudProvider, &admission.WebhookConfig{
+		ClusterID:       env.GetClusterID(),
+		Budgets:         budgets,
+		Window:          env.GetMetricsPushWindow(),
+		Resolution:      env.GetETLResolution(),
+		RefreshInterval: env.GetAdmissionWebhookRefreshInterval(),
+	})
+	webhook.Start()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/mutate", webhook.Mutate)
+	mux.HandleFunc("/validate", webhook.Validate)
+
+	server := &http.Server{
+		Addr:    fmt.Sprintf(":%d", env.GetAdmissionWebhookPort()),
+		Handler: errors.PanicHandlerMiddleware(mux),
+	}
+	go func() {
+		defer errors.HandlePanic()
+
+		log.Infof("Serving admission webhook on %s", server.Addr)
+		if err := server.ListenAndServeTLS(certFile, env.GetAdmissionWebhookTLSKeyFile()); err != nil {
+			log.Errorf("Admission webhook: %s", err)
+		}
 	}()
+
 	return nil
 }
+
+// newWarehouseSink creates a sink staging files to the bucket and loading them with either the
+// Snowflake SQL API or the configured database/sql driver.
+func newWarehouseSink(bucketConfig string) (*exporter.WarehouseSink, error) {
+	store, err := newExportBucketStorage(bucketConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	codec, err := costmodel.ParseParquetCompression(env.GetExportCompression())
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", env.ExportCompressionEnvVar, err)
+	}
+
+	dialect := exporter.SnowflakeDialect
+	if file := env.GetExportWarehouseDialectFile(); file != "" {
+		dialect, err = exporter.LoadWarehouseDialect(file)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var loader exporter.WarehouseLoader
+	if accountURL := env.GetExportSnowflakeAccountURL(); accountURL != "" {
+		loader, err = exporter.NewSnowflakeLoader(&exporter.SnowflakeConfig{
+			AccountURL: accountURL,
+			TokenFile:  env.GetExportSnowflakeTokenFile(),
+			TokenType:  env.GetExportSnowflakeTokenType(),
+			Database:   env.GetExportSnowflakeDatabase(),
+			Schema:     env.GetExportSnowflakeSchema(),
+			Warehouse:  env.GetExportSnowflakeWarehouse(),
+			Role:       env.GetExportSnowflakeRole(),
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else if dsn := env.GetExportWarehouseSQLDSN(); dsn != "" {
+		db, err := sql.Open(env.GetExportWarehouseSQLDriver(), dsn)
+		if err != nil {
+			return nil, fmt.Errorf("opening warehouse database: %w", err)
+		}
+		loader = exporter.NewSQLLoader(db)
+	} else {
+		return nil, fmt.Errorf("%s requires either %s or %s", env.ExportWarehouseBucketConfigEnvVar, env.ExportSnowflakeAccountURLEnvVar, env.ExportWarehouseSQLDSNEnvVar)
+	}
+
+	return exporter.NewWarehouseSink(store, loader, dialect, &exporter.WarehouseConfig{
+		Stage:           env.GetExportWarehouseStage(),
+		StagePrefix:     env.GetExportWarehouseStagePrefix(),
+		Format:          costmodel.ResponseFormat(env.GetExportWarehouseFormat()),
+		Codec:           codec,
+		AllocationTable: env.GetExportWarehouseAllocationTable(),
+		AssetTable:      env.GetExportWarehouseAssetTable(),
+	}, nil, nil)
+}
+
+// parseExportBackfillStart parses an RFC3339 time or a YYYY-MM-DD date in UTC.
+func parseExportBackfillStart(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
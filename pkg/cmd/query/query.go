@@ -0,0 +1,222 @@
+package query
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/opencost/opencost/pkg/util/json"
+)
+
+// Output formats supported by the query commands
+const (
+	OutputTable = "table"
+	OutputCSV   = "csv"
+	OutputJSON  = "json"
+)
+
+// AllocationOpts contains the options of the allocation query command.
+type AllocationOpts struct {
+	// Server is the base URL of the OpenCost API.
+	Server string
+
+	// Window is the query window, e.g. "7d", "today" or an RFC3339 range.
+	Window string
+
+	// Aggregate is the comma-separated list of properties to aggregate by,
+	// e.g. "namespace" or "namespace,label:app".
+	Aggregate string
+
+	// IncludeIdle includes idle allocations in the results.
+	IncludeIdle bool
+
+	// Output is the output format: table, csv or json.
+	Output string
+
+	// Timeout is the timeout of the request to the API.
+	Timeout time.Duration
+}
+
+// Allocation is the subset of an allocation's fields rendered by the query
+// commands.
+type Allocation struct {
+	Name             string  `json:"name"`
+	CPUCost          float64 `json:"cpuCost"`
+	GPUCost          float64 `json:"gpuCost"`
+	RAMCost          float64 `json:"ramCost"`
+	PVCost           float64 `json:"pvCost"`
+	NetworkCost      float64 `json:"networkCost"`
+	LoadBalancerCost float64 `json:"loadBalancerCost"`
+	SharedCost       float64 `json:"sharedCost"`
+	ExternalCost     float64 `json:"externalCost"`
+	TotalCost        float64 `json:"totalCost"`
+}
+
+func (a *Allocation) add(that *Allocation) {
+	a.CPUCost += that.CPUCost
+	a.GPUCost += that.GPUCost
+	a.RAMCost += that.RAMCost
+	a.PVCost += that.PVCost
+	a.NetworkCost += that.NetworkCost
+	a.LoadBalancerCost += that.LoadBalancerCost
+	a.SharedCost += that.SharedCost
+	a.ExternalCost += that.ExternalCost
+	a.TotalCost += that.TotalCost
+}
+
+// allocationResponse is the response of the /allocation endpoint: a range of
+// allocation sets keyed by allocation name.
+type allocationResponse struct {
+	Code    int                      `json:"code"`
+	Message string                   `json:"message"`
+	Data    []map[string]*Allocation `json:"data"`
+}
+
+// QueryAllocation queries a running OpenCost API for allocations accumulated
+// over the window and writes them to w in the requested output format.
+func QueryAllocation(w io.Writer, opts *AllocationOpts) error {
+	switch opts.Output {
+	case OutputTable, OutputCSV, OutputJSON:
+	default:
+		return fmt.Errorf("unsupported output format %q: expected one of %s, %s, %s", opts.Output, OutputTable, OutputCSV, OutputJSON)
+	}
+
+	allocs, err := fetchAllocations(opts)
+	if err != nil {
+		return err
+	}
+
+	return RenderAllocations(w, opts.Output, allocs)
+}
+
+func fetchAllocations(opts *AllocationOpts) ([]*Allocation, error) {
+	u, err := url.Parse(strings.TrimSuffix(opts.Server, "/") + "/allocation")
+	if err != nil {
+		return nil, fmt.Errorf("invalid server %q: %w", opts.Server, err)
+	}
+
+	q := u.Query()
+	q.Set("window", opts.Window)
+	q.Set("accumulate", "true")
+	q.Set("includeIdle", strconv.FormatBool(opts.IncludeIdle))
+	if opts.Aggregate != "" {
+		q.Set("aggregate", opts.Aggregate)
+	}
+	u.RawQuery = q.Encode()
+
+	client := &http.Client{Timeout: opts.Timeout}
+	resp, err := client.Get(u.String())
+	if err != nil {
+		return nil, fmt.Errorf("querying %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+
+	ar := &allocationResponse{}
+	if err := json.Unmarshal(body, ar); err != nil {
+		return nil, fmt.Errorf("decoding response (status %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || ar.Code != http.StatusOK {
+		return nil, fmt.Errorf("query failed (status %d): %s", resp.StatusCode, ar.Message)
+	}
+
+	return mergeAllocations(ar.Data), nil
+}
+
+// mergeAllocations sums the allocations of each set by name and sorts them by
+// descending total cost.
+func mergeAllocations(sets []map[string]*Allocation) []*Allocation {
+	byName := make(map[string]*Allocation)
+	for _, set := range sets {
+		for name, alloc := range set {
+			if alloc == nil {
+				continue
+			}
+			if existing, ok := byName[name]; ok {
+				existing.add(alloc)
+				continue
+			}
+			merged := *alloc
+			merged.Name = name
+			byName[name] = &merged
+		}
+	}
+
+	allocs := make([]*Allocation, 0, len(byName))
+	for _, alloc := range byName {
+		allocs = append(allocs, alloc)
+	}
+	sort.Slice(allocs, func(i, j int) bool {
+		if allocs[i].TotalCost != allocs[j].TotalCost {
+			return allocs[i].TotalCost > allocs[j].TotalCost
+		}
+		return allocs[i].Name < allocs[j].Name
+	})
+	return allocs
+}
+
+var allocationColumns = []string{"NAME", "CPU", "GPU", "RAM", "PV", "NETWORK", "LB", "SHARED", "EXTERNAL", "TOTAL"}
+
+func allocationRow(a *Allocation) []string {
+	return []string{
+		a.Name,
+		formatCost(a.CPUCost),
+		formatCost(a.GPUCost),
+		formatCost(a.RAMCost),
+		formatCost(a.PVCost),
+		formatCost(a.NetworkCost),
+		formatCost(a.LoadBalancerCost),
+		formatCost(a.SharedCost),
+		formatCost(a.ExternalCost),
+		formatCost(a.TotalCost),
+	}
+}
+
+func formatCost(f float64) string {
+	return strconv.FormatFloat(f, 'f', 2, 64)
+}
+
+// RenderAllocations writes the allocations to w as a table, CSV or JSON. The
+// table output ends with a row totalling all allocations.
+func RenderAllocations(w io.Writer, output string, allocs []*Allocation) error {
+	switch output {
+	case OutputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(allocs)
+	case OutputCSV:
+		cw := csv.NewWriter(w)
+		cw.Write(allocationColumns)
+		for _, a := range allocs {
+			cw.Write(allocationRow(a))
+		}
+		cw.Flush()
+		return cw.Error()
+	case OutputTable:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.Join(allocationColumns, "\t"))
+
+		total := &Allocation{Name: "TOTAL"}
+		for _, a := range allocs {
+			fmt.Fprintln(tw, strings.Join(allocationRow(a), "\t"))
+			total.add(a)
+		}
+		if len(allocs) > 1 {
+			fmt.Fprintln(tw, strings.Join(allocationRow(total), "\t"))
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unsupported output format %q", output)
+	}
+}
@@ -0,0 +1,81 @@
+package query
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQueryAllocation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/allocation" {
+			http.NotFound(w, r)
+			return
+		}
+		q := r.URL.Query()
+		if q.Get("window") != "7d" || q.Get("aggregate") != "namespace" || q.Get("accumulate") != "true" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `{"code":200,"status":"success","data":[
+			{"kube-system":{"name":"kube-system","cpuCost":1,"ramCost":0.5,"totalCost":1.5},
+			 "default":{"name":"default","cpuCost":2,"ramCost":1,"totalCost":3}},
+			{"default":{"name":"default","cpuCost":1,"totalCost":1}}
+		]}`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		output   string
+		expected string
+	}{
+		{
+			output: OutputCSV,
+			expected: "NAME,CPU,GPU,RAM,PV,NETWORK,LB,SHARED,EXTERNAL,TOTAL\n" +
+				"default,3.00,0.00,1.00,0.00,0.00,0.00,0.00,0.00,4.00\n" +
+				"kube-system,1.00,0.00,0.50,0.00,0.00,0.00,0.00,0.00,1.50\n",
+		},
+		{
+			output: OutputTable,
+			expected: "NAME         CPU   GPU   RAM   PV    NETWORK  LB    SHARED  EXTERNAL  TOTAL\n" +
+				"default      3.00  0.00  1.00  0.00  0.00     0.00  0.00    0.00      4.00\n" +
+				"kube-system  1.00  0.00  0.50  0.00  0.00     0.00  0.00    0.00      1.50\n" +
+				"TOTAL        4.00  0.00  1.50  0.00  0.00     0.00  0.00    0.00      5.50\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.output, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			err := QueryAllocation(buf, &AllocationOpts{
+				Server:    server.URL,
+				Window:    "7d",
+				Aggregate: "namespace",
+				Output:    test.output,
+				Timeout:   time.Minute,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if buf.String() != test.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", test.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestQueryAllocation_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"code":400,"message":"Error: invalid window"}`)
+	}))
+	defer server.Close()
+
+	err := QueryAllocation(&bytes.Buffer{}, &AllocationOpts{Server: server.URL, Window: "bad", Output: OutputTable})
+	if err == nil || !strings.Contains(err.Error(), "invalid window") {
+		t.Errorf("expected error containing the API message; got %v", err)
+	}
+}
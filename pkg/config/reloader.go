@@ -0,0 +1,251 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/opencost/opencost/pkg/errors"
+	"github.com/opencost/opencost/pkg/log"
+	"github.com/opencost/opencost/pkg/util/atomic"
+)
+
+//--------------------------------------------------------------------------
+//  Reloader
+//--------------------------------------------------------------------------
+
+// configMapDataDir is the symlink through which the files of a mounted
+// ConfigMap are atomically swapped by the kubelet on update
+const configMapDataDir = "..data"
+
+var (
+	reloadMetricsOnce sync.Once
+
+	reloadGeneration *prometheus.GaugeVec
+	reloadFailures   *prometheus.CounterVec
+	reloadLast       *prometheus.GaugeVec
+)
+
+// initReloadMetrics registers the reload metrics with the default registry.
+func initReloadMetrics() {
+	reloadMetricsOnce.Do(func() {
+		reloadGeneration = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "opencost_config_generation",
+			Help: "opencost_config_generation Number of configuration versions applied since startup, starting at 1",
+		}, []string{"path"})
+
+		reloadFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "opencost_config_reload_failures_total",
+			Help: "opencost_config_reload_failures_total Number of configuration versions rejected because they could not be read or were invalid",
+		}, []string{"path"})
+
+		reloadLast = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "opencost_config_last_reload_success_timestamp_seconds",
+			Help: "opencost_config_last_reload_success_timestamp_seconds Unix time at which the current configuration version was applied",
+		}, []string{"path"})
+
+		prometheus.MustRegister(reloadGeneration, reloadFailures, reloadLast)
+	})
+}
+
+// ReloadFunc parses a version of the configuration, provided as a map of file
+// names to contents, and returns a func applying it. An error is returned if
+// the configuration is invalid, in which case nothing may be applied. Keys not
+// relevant to the ReloadFunc must be ignored.
+type ReloadFunc func(data map[string]string) (apply func(), err error)
+
+type namedReloadFunc struct {
+	name   string
+	reload ReloadFunc
+}
+
+// Reloader watches a directory of configuration files, such as a mounted
+// ConfigMap, and reloads them into each registered ReloadFunc when their
+// contents change. A change is applied atomically: every ReloadFunc parses
+// the new version first, and only if all succeed are they applied, so that a
+// partially invalid change never leaves subsystems configured inconsistently.
+type Reloader struct {
+	dir      string
+	interval time.Duration
+
+	lock       sync.Mutex
+	funcs      []namedReloadFunc
+	version    string
+	rejected   string
+	generation int
+
+	runState atomic.AtomicRunState
+}
+
+// NewReloader creates a new Reloader of the files in the directory, checked
+// for changes on the interval. Use Start to begin watching.
+func NewReloader(dir string, interval time.Duration) *Reloader {
+	initReloadMetrics()
+
+	return &Reloader{
+		dir:      dir,
+		interval: interval,
+	}
+}
+
+// Register adds a ReloadFunc, which is called with the current configuration
+// on the next reload and on each change thereafter.
+func (r *Reloader) Register(name string, reload ReloadFunc) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.funcs = append(r.funcs, namedReloadFunc{name: name, reload: reload})
+
+	// force the next reload to apply the current version to the new func
+	r.version, r.rejected = "", ""
+}
+
+// Generation returns the number of configuration versions applied.
+func (r *Reloader) Generation() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.generation
+}
+
+// Reload reads the configuration files and, if they changed since the last
+// applied version, applies them. The previous version remains applied if an
+// error is returned.
+func (r *Reloader) Reload() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	data, err := readConfigDir(r.dir)
+	if err != nil {
+		reloadFailures.WithLabelValues(r.dir).Inc()
+		return err
+	}
+
+	// a rejected version is not retried until it changes
+	version := hashConfig(data)
+	if version == r.version || version == r.rejected {
+		return nil
+	}
+
+	applies := make([]func(), 0, len(r.funcs))
+	for _, f := range r.funcs {
+		apply, err := f.reload(data)
+		if err != nil {
+			r.rejected = version
+			reloadFailures.WithLabelValues(r.dir).Inc()
+			return fmt.Errorf("invalid %s configuration: %w", f.name, err)
+		}
+		if apply != nil {
+			applies = append(applies, apply)
+		}
+	}
+
+	for _, apply := range applies {
+		apply()
+	}
+
+	r.version, r.rejected = version, ""
+	r.generation++
+	reloadGeneration.WithLabelValues(r.dir).Set(float64(r.generation))
+	reloadLast.WithLabelValues(r.dir).SetToCurrentTime()
+	log.Infof("Config: applied generation %d from %s", r.generation, r.dir)
+
+	return nil
+}
+
+// Start applies the current configuration, then checks for changes on the
+// configured interval. Returns false if the reloader is already running.
+func (r *Reloader) Start() bool {
+	r.runState.WaitForReset()
+	if !r.runState.Start() {
+		log.Warnf("Config: attempted to start reloader when already running")
+		return false
+	}
+
+	go func() {
+		defer errors.HandlePanic()
+
+		for {
+			if err := r.Reload(); err != nil {
+				log.Errorf("Config: keeping generation %d: %s", r.Generation(), err)
+			}
+
+			select {
+			case <-r.runState.OnStop():
+				r.runState.Reset()
+				return
+			case <-time.After(r.interval):
+			}
+		}
+	}()
+
+	return true
+}
+
+// Stop halts watching for changes.
+func (r *Reloader) Stop() {
+	r.runState.Stop()
+}
+
+// readConfigDir reads the regular files of the directory into a map of file
+// names to contents. If the directory is a mounted ConfigMap, the files are
+// read through the resolved data directory, so that all files come from the
+// same version even if the ConfigMap is updated while reading.
+func readConfigDir(dir string) (map[string]string, error) {
+	if resolved, err := filepath.EvalSymlinks(filepath.Join(dir, configMapDataDir)); err == nil {
+		dir = resolved
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading config directory: %w", err)
+	}
+
+	data := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		// skip the ConfigMap's hidden data directories and symlinks
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading config file: %w", err)
+		}
+		if info.IsDir() {
+			continue
+		}
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading config file: %w", err)
+		}
+		data[entry.Name()] = strings.TrimSpace(string(b))
+	}
+
+	return data, nil
+}
+
+// hashConfig returns a digest of the configuration identifying its version.
+func hashConfig(data map[string]string) string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, k := range keys {
+		fmt.Fprintf(h, "%d:%s%d:%s", len(k), k, len(data[k]), data[k])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeConfigFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+			t.Fatalf("writing %s: %s", name, err)
+		}
+	}
+}
+
+// recordingReloadFunc records the value of a key each time it is applied, and
+// rejects the value "invalid".
+func recordingReloadFunc(key string, applied *[]string) ReloadFunc {
+	return func(data map[string]string) (func(), error) {
+		v := data[key]
+		if v == "invalid" {
+			return nil, fmt.Errorf("invalid %s", key)
+		}
+		return func() { *applied = append(*applied, v) }, nil
+	}
+}
+
+func TestReloader_Reload(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFiles(t, dir, map[string]string{"a": "1", "b": "1\n"})
+
+	var appliedA, appliedB []string
+	r := NewReloader(dir, 0)
+	r.Register("a", recordingReloadFunc("a", &appliedA))
+	r.Register("b", recordingReloadFunc("b", &appliedB))
+
+	if err := r.Reload(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if r.Generation() != 1 || len(appliedA) != 1 || appliedB[0] != "1" {
+		t.Fatalf("expected generation 1 to be applied; got generation %d, a=%v, b=%v", r.Generation(), appliedA, appliedB)
+	}
+
+	// unchanged contents are not reapplied
+	if err := r.Reload(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if r.Generation() != 1 || len(appliedA) != 1 {
+		t.Fatalf("expected unchanged config not to be reapplied; got generation %d, a=%v", r.Generation(), appliedA)
+	}
+
+	// an invalid value for one func is applied to none
+	writeConfigFiles(t, dir, map[string]string{"a": "2", "b": "invalid"})
+	if err := r.Reload(); err == nil {
+		t.Fatalf("expected error for invalid config")
+	}
+	if r.Generation() != 1 || len(appliedA) != 1 || len(appliedB) != 1 {
+		t.Fatalf("expected invalid config not to be applied; got generation %d, a=%v, b=%v", r.Generation(), appliedA, appliedB)
+	}
+
+	// a rejected version is not retried
+	if err := r.Reload(); err != nil {
+		t.Fatalf("expected rejected config not to be retried; got %s", err)
+	}
+
+	writeConfigFiles(t, dir, map[string]string{"b": "2"})
+	if err := r.Reload(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if r.Generation() != 2 || appliedA[1] != "2" || appliedB[1] != "2" {
+		t.Fatalf("expected generation 2 to be applied; got generation %d, a=%v, b=%v", r.Generation(), appliedA, appliedB)
+	}
+}
+
+func TestReloader_ReloadConfigMap(t *testing.T) {
+	// mimic the layout of a mounted ConfigMap: the files are symlinks through
+	// ..data to a timestamped directory
+	dir := t.TempDir()
+	version := filepath.Join(dir, "..2024_01_01_00_00_00.000000000")
+	if err := os.Mkdir(version, 0755); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	writeConfigFiles(t, version, map[string]string{"a": "1"})
+	if err := os.Symlink(filepath.Base(version), filepath.Join(dir, configMapDataDir)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := os.Symlink(filepath.Join(configMapDataDir, "a"), filepath.Join(dir, "a")); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	data, err := readConfigDir(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(data) != 1 || data["a"] != "1" {
+		t.Fatalf("expected only file a; got %v", data)
+	}
+}
+
+func TestReloader_ReloadMissingDir(t *testing.T) {
+	r := NewReloader(filepath.Join(t.TempDir(), "missing"), 0)
+	if err := r.Reload(); err == nil {
+		t.Fatalf("expected error for missing directory")
+	}
+	if r.Generation() != 0 {
+		t.Fatalf("expected generation 0; got %d", r.Generation())
+	}
+}
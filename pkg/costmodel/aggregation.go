@@ -16,6 +16,7 @@ import (
 	prometheusClient "github.com/prometheus/client_golang/api"
 
 	"github.com/opencost/opencost/pkg/cloud/models"
+	"github.com/opencost/opencost/pkg/cloudcost"
 	"github.com/opencost/opencost/pkg/env"
 	"github.com/opencost/opencost/pkg/errors"
 	"github.com/opencost/opencost/pkg/kubecost"
@@ -2163,6 +2164,21 @@ func (a *Accesses) ComputeAllocationHandlerSummary(w http.ResponseWriter, r *htt
 	// sums each Set in the Range, producing one Set.
 	accumulate := qp.GetBool("accumulate", false)
 
+	// Currency is an optional parameter, defaulting to the configured display
+	// currency, giving the currency to which costs are converted.
+	conversion, err := a.responseCurrency(r)
+	if err != nil {
+		writeCurrencyError(w, err)
+		return
+	}
+
+	release, err := a.admitAllocationQuery(r.Context(), window, resolution, step, false)
+	if err != nil {
+		writeAdmissionError(w, err)
+		return
+	}
+	defer release()
+
 	// Query for AllocationSets in increments of the given step duration,
 	// appending each to the AllocationSetRange.
 	asr := kubecost.NewAllocationSetRange()
@@ -2205,8 +2221,11 @@ func (a *Accesses) ComputeAllocationHandlerSummary(w http.ResponseWriter, r *htt
 		sasl = append(sasl, sas)
 	}
 	sasr := kubecost.NewSummaryAllocationSetRange(sasl...)
+	if !conversion.IsIdentity() {
+		sasr.ConvertCurrency(conversion.Rate)
+	}
 
-	w.Write(WrapData(sasr, nil))
+	w.Write(WrapDataWithCurrency(sasr, conversion))
 }
 
 // ComputeAllocationHandler computes an AllocationSetRange from the CostModel.
@@ -2215,6 +2234,26 @@ func (a *Accesses) ComputeAllocationHandler(w http.ResponseWriter, r *http.Reque
 
 	qp := httputil.NewQueryParams(r.URL.Query())
 
+	// Format is an optional parameter, which may also be requested via the
+	// Accept header, selecting json (default), csv, or parquet output.
+	format, err := ParseResponseFormat(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid 'format' parameter: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// Columns is an optional comma-separated list of columns to include in
+	// csv or parquet output, defaulting to the configured column set.
+	// Examples: "Namespace,TotalCost", "Pod,label:app,CPUCost"
+	var columns []TabularColumn[AllocationRow]
+	if format != ResponseFormatJSON {
+		columns, err = SelectAllocationColumns(tabularColumnNames(qp, env.GetAllocationTabularColumns()))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid 'columns' parameter: %s", err), http.StatusBadRequest)
+			return
+		}
+	}
+
 	// Window is a required field describing the window of time over which to
 	// compute allocation data.
 	window, err := kubecost.ParseWindowWithOffset(qp.Get("window", ""), env.GetParsedUTCOffset())
@@ -2267,6 +2306,25 @@ func (a *Accesses) ComputeAllocationHandler(w http.ResponseWriter, r *http.Reque
 	// include aggregated labels/annotations if true
 	includeAggregatedMetadata := qp.GetBool("includeAggregatedMetadata", true)
 
+	// IncludeExternalCosts, if true, includes the costs of cloud resources
+	// outside of Kubernetes, linked to tenants by the configured tag rules.
+	includeExternalCosts := qp.GetBool("includeExternalCosts", false)
+
+	// Currency is an optional parameter, defaulting to the configured display
+	// currency, giving the currency to which costs are converted.
+	conversion, err := a.responseCurrency(r)
+	if err != nil {
+		writeCurrencyError(w, err)
+		return
+	}
+
+	release, err := a.admitAllocationQuery(r.Context(), window, resolution, step, true)
+	if err != nil {
+		writeAdmissionError(w, err)
+		return
+	}
+	defer release()
+
 	asr, err := a.Model.QueryAllocation(window, resolution, step, aggregateBy, includeIdle, idleByNode, includeProportionalAssetResourceCosts, includeAggregatedMetadata)
 	if err != nil {
 		if strings.Contains(strings.ToLower(err.Error()), "bad request") {
@@ -2288,7 +2346,50 @@ func (a *Accesses) ComputeAllocationHandler(w http.ResponseWriter, r *http.Reque
 		}
 	}
 
-	w.Write(WrapData(asr, nil))
+	var externalCosts []*cloudcost.ExternalCostSet
+	if includeExternalCosts && format == ResponseFormatJSON {
+		// External costs depend only on the configuration of the server, so
+		// failing to compute them is not the fault of the request
+		externalCosts, err = a.computeExternalCosts(asr)
+		if err != nil {
+			log.Errorf("Error computing external costs: %s", err)
+			WriteError(w, InternalServerError(err.Error()))
+			return
+		}
+	}
+
+	if !conversion.IsIdentity() {
+		asr.ConvertCurrency(conversion.Rate)
+		for _, ecs := range externalCosts {
+			ecs.ConvertCurrency(conversion.Rate)
+		}
+	}
+
+	if format != ResponseFormatJSON {
+		codec, err := ParseParquetCompression(env.GetParquetCompression())
+		if err != nil {
+			log.Warnf("Invalid %s, using default: %s", env.ParquetCompressionEnvVar, err)
+		}
+		writeTabularResponse(w, format, "allocation", columns, AllocationRowsFor(asr), codec)
+		return
+	}
+
+	if includeExternalCosts {
+		w.Write(WrapDataWithCurrency(&allocationsWithExternalCosts{
+			Allocations:   asr,
+			ExternalCosts: externalCosts,
+		}, conversion))
+		return
+	}
+
+	w.Write(WrapDataWithCurrency(asr, conversion))
+}
+
+// allocationsWithExternalCosts is the response to an allocation query which
+// includes the external costs of tenants.
+type allocationsWithExternalCosts struct {
+	Allocations   *kubecost.AllocationSetRange `json:"allocations"`
+	ExternalCosts []*cloudcost.ExternalCostSet `json:"externalCosts"`
 }
 
 // The below was transferred from a different package in order to maintain
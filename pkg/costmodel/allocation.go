@@ -13,52 +13,55 @@ import (
 )
 
 const (
-	queryFmtPods                        = `avg(kube_pod_container_status_running{}) by (pod, namespace, %s)[%s:%s]`
-	queryFmtPodsUID                     = `avg(kube_pod_container_status_running{}) by (pod, namespace, uid, %s)[%s:%s]`
-	queryFmtRAMBytesAllocated           = `avg(avg_over_time(container_memory_allocation_bytes{container!="", container!="POD", node!=""}[%s])) by (container, pod, namespace, node, %s, provider_id)`
-	queryFmtRAMRequests                 = `avg(avg_over_time(kube_pod_container_resource_requests{resource="memory", unit="byte", container!="", container!="POD", node!=""}[%s])) by (container, pod, namespace, node, %s)`
-	queryFmtRAMUsageAvg                 = `avg(avg_over_time(container_memory_working_set_bytes{container!="", container_name!="POD", container!="POD"}[%s])) by (container_name, container, pod_name, pod, namespace, instance, %s)`
-	queryFmtRAMUsageMax                 = `max(max_over_time(container_memory_working_set_bytes{container!="", container_name!="POD", container!="POD"}[%s])) by (container_name, container, pod_name, pod, namespace, instance, %s)`
-	queryFmtCPUCoresAllocated           = `avg(avg_over_time(container_cpu_allocation{container!="", container!="POD", node!=""}[%s])) by (container, pod, namespace, node, %s)`
-	queryFmtCPURequests                 = `avg(avg_over_time(kube_pod_container_resource_requests{resource="cpu", unit="core", container!="", container!="POD", node!=""}[%s])) by (container, pod, namespace, node, %s)`
-	queryFmtCPUUsageAvg                 = `avg(rate(container_cpu_usage_seconds_total{container!="", container_name!="POD", container!="POD"}[%s])) by (container_name, container, pod_name, pod, namespace, instance, %s)`
-	queryFmtGPUsRequested               = `avg(avg_over_time(kube_pod_container_resource_requests{resource="nvidia_com_gpu", container!="",container!="POD", node!=""}[%s])) by (container, pod, namespace, node, %s)`
-	queryFmtGPUsAllocated               = `avg(avg_over_time(container_gpu_allocation{container!="", container!="POD", node!=""}[%s])) by (container, pod, namespace, node, %s)`
-	queryFmtNodeCostPerCPUHr            = `avg(avg_over_time(node_cpu_hourly_cost[%s])) by (node, %s, instance_type, provider_id)`
-	queryFmtNodeCostPerRAMGiBHr         = `avg(avg_over_time(node_ram_hourly_cost[%s])) by (node, %s, instance_type, provider_id)`
-	queryFmtNodeCostPerGPUHr            = `avg(avg_over_time(node_gpu_hourly_cost[%s])) by (node, %s, instance_type, provider_id)`
-	queryFmtNodeIsSpot                  = `avg_over_time(kubecost_node_is_spot[%s])`
-	queryFmtPVCInfo                     = `avg(kube_persistentvolumeclaim_info{volumename != ""}) by (persistentvolumeclaim, storageclass, volumename, namespace, %s)[%s:%s]`
-	queryFmtPodPVCAllocation            = `avg(avg_over_time(pod_pvc_allocation[%s])) by (persistentvolume, persistentvolumeclaim, pod, namespace, %s)`
-	queryFmtPVCBytesRequested           = `avg(avg_over_time(kube_persistentvolumeclaim_resource_requests_storage_bytes{}[%s])) by (persistentvolumeclaim, namespace, %s)`
-	queryFmtPVActiveMins                = `count(kube_persistentvolume_capacity_bytes) by (persistentvolume, %s)[%s:%s]`
-	queryFmtPVBytes                     = `avg(avg_over_time(kube_persistentvolume_capacity_bytes[%s])) by (persistentvolume, %s)`
-	queryFmtPVCostPerGiBHour            = `avg(avg_over_time(pv_hourly_cost[%s])) by (volumename, %s)`
-	queryFmtNetZoneGiB                  = `sum(increase(kubecost_pod_network_egress_bytes_total{internet="false", sameZone="false", sameRegion="true"}[%s])) by (pod_name, namespace, %s) / 1024 / 1024 / 1024`
-	queryFmtNetZoneCostPerGiB           = `avg(avg_over_time(kubecost_network_zone_egress_cost{}[%s])) by (%s)`
-	queryFmtNetRegionGiB                = `sum(increase(kubecost_pod_network_egress_bytes_total{internet="false", sameZone="false", sameRegion="false"}[%s])) by (pod_name, namespace, %s) / 1024 / 1024 / 1024`
-	queryFmtNetRegionCostPerGiB         = `avg(avg_over_time(kubecost_network_region_egress_cost{}[%s])) by (%s)`
-	queryFmtNetInternetGiB              = `sum(increase(kubecost_pod_network_egress_bytes_total{internet="true"}[%s])) by (pod_name, namespace, %s) / 1024 / 1024 / 1024`
-	queryFmtNetInternetCostPerGiB       = `avg(avg_over_time(kubecost_network_internet_egress_cost{}[%s])) by (%s)`
-	queryFmtNetReceiveBytes             = `sum(increase(container_network_receive_bytes_total{pod!=""}[%s])) by (pod_name, pod, namespace, %s)`
-	queryFmtNetTransferBytes            = `sum(increase(container_network_transmit_bytes_total{pod!=""}[%s])) by (pod_name, pod, namespace, %s)`
-	queryFmtNodeLabels                  = `avg_over_time(kube_node_labels[%s])`
-	queryFmtNamespaceLabels             = `avg_over_time(kube_namespace_labels[%s])`
-	queryFmtNamespaceAnnotations        = `avg_over_time(kube_namespace_annotations[%s])`
-	queryFmtPodLabels                   = `avg_over_time(kube_pod_labels[%s])`
-	queryFmtPodAnnotations              = `avg_over_time(kube_pod_annotations[%s])`
-	queryFmtServiceLabels               = `avg_over_time(service_selector_labels[%s])`
-	queryFmtDeploymentLabels            = `avg_over_time(deployment_match_labels[%s])`
-	queryFmtStatefulSetLabels           = `avg_over_time(statefulSet_match_labels[%s])`
-	queryFmtDaemonSetLabels             = `sum(avg_over_time(kube_pod_owner{owner_kind="DaemonSet"}[%s])) by (pod, owner_name, namespace, %s)`
-	queryFmtJobLabels                   = `sum(avg_over_time(kube_pod_owner{owner_kind="Job"}[%s])) by (pod, owner_name, namespace ,%s)`
-	queryFmtPodsWithReplicaSetOwner     = `sum(avg_over_time(kube_pod_owner{owner_kind="ReplicaSet"}[%s])) by (pod, owner_name, namespace ,%s)`
-	queryFmtReplicaSetsWithoutOwners    = `avg(avg_over_time(kube_replicaset_owner{owner_kind="<none>", owner_name="<none>"}[%s])) by (replicaset, namespace, %s)`
-	queryFmtReplicaSetsWithRolloutOwner = `avg(avg_over_time(kube_replicaset_owner{owner_kind="Rollout"}[%s])) by (replicaset, namespace, owner_kind, owner_name, %s)`
-	queryFmtLBCostPerHr                 = `avg(avg_over_time(kubecost_load_balancer_cost[%s])) by (namespace, service_name, %s)`
-	queryFmtLBActiveMins                = `count(kubecost_load_balancer_cost) by (namespace, service_name, %s)[%s:%s]`
-	queryFmtOldestSample                = `min_over_time(timestamp(group(node_cpu_hourly_cost))[%s:%s])`
-	queryFmtNewestSample                = `max_over_time(timestamp(group(node_cpu_hourly_cost))[%s:%s])`
+	queryFmtPods                           = `avg(kube_pod_container_status_running{}) by (pod, namespace, %s)[%s:%s]`
+	queryFmtPodsUID                        = `avg(kube_pod_container_status_running{}) by (pod, namespace, uid, %s)[%s:%s]`
+	queryFmtRAMBytesAllocated              = `avg(avg_over_time(container_memory_allocation_bytes{container!="", container!="POD", node!=""}[%s])) by (container, pod, namespace, node, %s, provider_id)`
+	queryFmtRAMRequests                    = `avg(avg_over_time(kube_pod_container_resource_requests{resource="memory", unit="byte", container!="", container!="POD", node!=""}[%s])) by (container, pod, namespace, node, %s)`
+	queryFmtRAMUsageAvg                    = `avg(avg_over_time(container_memory_working_set_bytes{container!="", container_name!="POD", container!="POD"}[%s])) by (container_name, container, pod_name, pod, namespace, instance, %s)`
+	queryFmtRAMUsageMax                    = `max(max_over_time(container_memory_working_set_bytes{container!="", container_name!="POD", container!="POD"}[%s])) by (container_name, container, pod_name, pod, namespace, instance, %s)`
+	queryFmtCPUCoresAllocated              = `avg(avg_over_time(container_cpu_allocation{container!="", container!="POD", node!=""}[%s])) by (container, pod, namespace, node, %s)`
+	queryFmtCPURequests                    = `avg(avg_over_time(kube_pod_container_resource_requests{resource="cpu", unit="core", container!="", container!="POD", node!=""}[%s])) by (container, pod, namespace, node, %s)`
+	queryFmtCPUUsageAvg                    = `avg(rate(container_cpu_usage_seconds_total{container!="", container_name!="POD", container!="POD"}[%s])) by (container_name, container, pod_name, pod, namespace, instance, %s)`
+	queryFmtGPUsRequested                  = `avg(avg_over_time(kube_pod_container_resource_requests{resource="nvidia_com_gpu", container!="",container!="POD", node!=""}[%s])) by (container, pod, namespace, node, %s)`
+	queryFmtGPUsAllocated                  = `avg(avg_over_time(container_gpu_allocation{container!="", container!="POD", node!=""}[%s])) by (container, pod, namespace, node, %s)`
+	queryFmtNodeCostPerCPUHr               = `avg(avg_over_time(node_cpu_hourly_cost[%s])) by (node, %s, instance_type, provider_id)`
+	queryFmtNodeCostPerRAMGiBHr            = `avg(avg_over_time(node_ram_hourly_cost[%s])) by (node, %s, instance_type, provider_id)`
+	queryFmtNodeCostPerGPUHr               = `avg(avg_over_time(node_gpu_hourly_cost[%s])) by (node, %s, instance_type, provider_id)`
+	queryFmtNodeIsSpot                     = `avg_over_time(kubecost_node_is_spot[%s])`
+	queryFmtPVCInfo                        = `avg(kube_persistentvolumeclaim_info{volumename != ""}) by (persistentvolumeclaim, storageclass, volumename, namespace, %s)[%s:%s]`
+	queryFmtPodPVCAllocation               = `avg(avg_over_time(pod_pvc_allocation[%s])) by (persistentvolume, persistentvolumeclaim, pod, namespace, %s)`
+	queryFmtPVCBytesRequested              = `avg(avg_over_time(kube_persistentvolumeclaim_resource_requests_storage_bytes{}[%s])) by (persistentvolumeclaim, namespace, %s)`
+	queryFmtPVActiveMins                   = `count(kube_persistentvolume_capacity_bytes) by (persistentvolume, %s)[%s:%s]`
+	queryFmtPVBytes                        = `avg(avg_over_time(kube_persistentvolume_capacity_bytes[%s])) by (persistentvolume, %s)`
+	queryFmtPVCostPerGiBHour               = `avg(avg_over_time(pv_hourly_cost[%s])) by (volumename, %s)`
+	queryFmtNetZoneGiB                     = `sum(increase(kubecost_pod_network_egress_bytes_total{internet="false", sameZone="false", sameRegion="true"}[%s])) by (pod_name, namespace, %s) / 1024 / 1024 / 1024`
+	queryFmtNetZoneCostPerGiB              = `avg(avg_over_time(kubecost_network_zone_egress_cost{}[%s])) by (%s)`
+	queryFmtNetRegionGiB                   = `sum(increase(kubecost_pod_network_egress_bytes_total{internet="false", sameZone="false", sameRegion="false"}[%s])) by (pod_name, namespace, %s) / 1024 / 1024 / 1024`
+	queryFmtNetRegionCostPerGiB            = `avg(avg_over_time(kubecost_network_region_egress_cost{}[%s])) by (%s)`
+	queryFmtNetInternetGiB                 = `sum(increase(kubecost_pod_network_egress_bytes_total{internet="true"}[%s])) by (pod_name, namespace, %s) / 1024 / 1024 / 1024`
+	queryFmtNetInternetCostPerGiB          = `avg(avg_over_time(kubecost_network_internet_egress_cost{}[%s])) by (%s)`
+	queryFmtNetReceiveBytes                = `sum(increase(container_network_receive_bytes_total{pod!=""}[%s])) by (pod_name, pod, namespace, %s)`
+	queryFmtNetTransferBytes               = `sum(increase(container_network_transmit_bytes_total{pod!=""}[%s])) by (pod_name, pod, namespace, %s)`
+	queryFmtNodeLabels                     = `avg_over_time(kube_node_labels[%s])`
+	queryFmtNamespaceLabels                = `avg_over_time(kube_namespace_labels[%s])`
+	queryFmtNamespaceAnnotations           = `avg_over_time(kube_namespace_annotations[%s])`
+	queryFmtPodLabels                      = `avg_over_time(kube_pod_labels[%s])`
+	queryFmtPodAnnotations                 = `avg_over_time(kube_pod_annotations[%s])`
+	queryFmtServiceLabels                  = `avg_over_time(service_selector_labels[%s])`
+	queryFmtDeploymentLabels               = `avg_over_time(deployment_match_labels[%s])`
+	queryFmtStatefulSetLabels              = `avg_over_time(statefulSet_match_labels[%s])`
+	queryFmtDaemonSetLabels                = `sum(avg_over_time(kube_pod_owner{owner_kind="DaemonSet"}[%s])) by (pod, owner_name, namespace, %s)`
+	queryFmtJobLabels                      = `sum(avg_over_time(kube_pod_owner{owner_kind="Job"}[%s])) by (pod, owner_name, namespace ,%s)`
+	queryFmtPodsWithReplicaSetOwner        = `sum(avg_over_time(kube_pod_owner{owner_kind="ReplicaSet"}[%s])) by (pod, owner_name, namespace ,%s)`
+	queryFmtReplicaSetsWithoutOwners       = `avg(avg_over_time(kube_replicaset_owner{owner_kind="<none>", owner_name="<none>"}[%s])) by (replicaset, namespace, %s)`
+	queryFmtReplicaSetsWithRolloutOwner    = `avg(avg_over_time(kube_replicaset_owner{owner_kind="Rollout"}[%s])) by (replicaset, namespace, owner_kind, owner_name, %s)`
+	queryFmtReplicaSetsWithDeploymentOwner = `avg(avg_over_time(kube_replicaset_owner{owner_kind="Deployment"}[%s])) by (replicaset, namespace, owner_name, %s)`
+	queryFmtPodsWithStatefulSetOwner       = `sum(avg_over_time(kube_pod_owner{owner_kind="StatefulSet"}[%s])) by (pod, owner_name, namespace, %s)`
+	queryFmtJobOwners                      = `sum(avg_over_time(kube_job_owner[%s])) by (job_name, owner_kind, owner_name, namespace, %s)`
+	queryFmtLBCostPerHr                    = `avg(avg_over_time(kubecost_load_balancer_cost[%s])) by (namespace, service_name, %s)`
+	queryFmtLBActiveMins                   = `count(kubecost_load_balancer_cost) by (namespace, service_name, %s)[%s:%s]`
+	queryFmtOldestSample                   = `min_over_time(timestamp(group(node_cpu_hourly_cost))[%s:%s])`
+	queryFmtNewestSample                   = `max_over_time(timestamp(group(node_cpu_hourly_cost))[%s:%s])`
 
 	// Because we use container_cpu_usage_seconds_total to calculate CPU usage
 	// at any given "instant" of time, we need to use an irate or rate. To then
@@ -85,8 +88,18 @@ const (
 	// in case the Prom scrape duration has been reduced to be equal to the
 	// ETL resolution.
 	queryFmtCPUUsageMaxSubquery = `max(max_over_time(irate(container_cpu_usage_seconds_total{container_name!="POD", container_name!=""}[%s])[%s:%s])) by (container_name, container, pod_name, pod, namespace, instance, %s)`
+
+	// The usage quantile queries take the quantile first, e.g. 0.95, and are
+	// otherwise the quantile equivalents of the usage max queries above.
+	queryFmtRAMUsageQuantile              = `max(quantile_over_time(%s, container_memory_working_set_bytes{container!="", container_name!="POD", container!="POD"}[%s])) by (container_name, container, pod_name, pod, namespace, instance, %s)`
+	queryFmtCPUUsageQuantileRecordingRule = `max(quantile_over_time(%s, kubecost_container_cpu_usage_irate{}[%s])) by (container_name, container, pod_name, pod, namespace, instance, %s)`
+	queryFmtCPUUsageQuantileSubquery      = `max(quantile_over_time(%s, irate(container_cpu_usage_seconds_total{container_name!="POD", container_name!=""}[%s])[%s:%s])) by (container_name, container, pod_name, pod, namespace, instance, %s)`
 )
 
+// usageQuantiles are the quantiles of CPU and RAM usage computed for each
+// allocation, if enabled.
+var usageQuantiles = []string{"0.5", "0.95", "0.99"}
+
 // Constants for Network Cost Subtype
 const (
 	networkCrossZoneCost   = "NetworkCrossZoneCost"
@@ -120,40 +133,17 @@ func (cm *CostModel) ComputeAllocation(start, end time.Time, resolution time.Dur
 	}
 
 	// If the duration exceeds the configured MaxPrometheusQueryDuration, then
-	// query for maximum-sized AllocationSets, collect them, and accumulate.
-
-	// s and e track the coverage of the entire given window over multiple
-	// internal queries.
-	s, e := start, start
-
-	// Collect AllocationSets in a range, then accumulate
+	// query for maximum-sized AllocationSets in parallel, collect them, and
+	// accumulate. Ranges checkpointed by an interrupted computation of the
+	// same window are resumed from rather than recomputed.
 	// TODO optimize by collecting consecutive AllocationSets, accumulating as we go
-	asr := kubecost.NewAllocationSetRange()
-
-	for e.Before(end) {
-		// By default, query for the full remaining duration. But do not let
-		// any individual query duration exceed the configured max Prometheus
-		// query duration.
-		duration := end.Sub(e)
-		if duration > cm.MaxPrometheusQueryDuration {
-			duration = cm.MaxPrometheusQueryDuration
-		}
-
-		// Set start and end parameters (s, e) for next individual computation.
-		e = s.Add(duration)
-
-		// Compute the individual AllocationSet for just (s, e)
-		as, _, err := cm.computeAllocation(s, e, resolution)
-		if err != nil {
-			return kubecost.NewAllocationSet(start, end), fmt.Errorf("error computing allocation for %s: %s", kubecost.NewClosedWindow(s, e), err)
-		}
-
-		// Append to the range
-		asr.Append(as)
-
-		// Set s equal to e to set up the next query, if one exists.
-		s = e
+	ranges := splitTimeRange(start, end, cm.MaxPrometheusQueryDuration)
+	checkpoints := cm.loadAllocationCheckpoints(start, end, resolution, ranges)
+	sets, err := cm.computeAllocationRanges(ranges, resolution, checkpoints)
+	if err != nil {
+		return kubecost.NewAllocationSet(start, end), err
 	}
+	asr := kubecost.NewAllocationSetRange(sets...)
 
 	// Populate annotations, labels, and services on each Allocation. This is
 	// necessary because Properties.Intersection does not propagate any values
@@ -206,8 +196,12 @@ func (cm *CostModel) ComputeAllocation(start, end time.Time, resolution time.Dur
 	// recomputed.
 	resultASR, err := asr.Accumulate(kubecost.AccumulateOptionAll)
 	if err != nil {
-		return kubecost.NewAllocationSet(start, end), fmt.Errorf("error accumulating data for %s: %s", kubecost.NewClosedWindow(s, e), err)
+		return kubecost.NewAllocationSet(start, end), fmt.Errorf("error accumulating data for %s: %s", kubecost.NewClosedWindow(start, end), err)
 	}
+
+	// Every range is computed, so the checkpoints are no longer needed
+	checkpoints.clear()
+
 	if resultASR != nil && len(resultASR.Allocations) == 0 {
 		return kubecost.NewAllocationSet(start, end), nil
 	}
@@ -262,13 +256,7 @@ func (cm *CostModel) ComputeAllocation(start, end time.Time, resolution time.Dur
 				continue
 			}
 
-			if alloc.RawAllocationOnly.CPUCoreUsageMax > resultAlloc.RawAllocationOnly.CPUCoreUsageMax {
-				resultAlloc.RawAllocationOnly.CPUCoreUsageMax = alloc.RawAllocationOnly.CPUCoreUsageMax
-			}
-
-			if alloc.RawAllocationOnly.RAMBytesUsageMax > resultAlloc.RawAllocationOnly.RAMBytesUsageMax {
-				resultAlloc.RawAllocationOnly.RAMBytesUsageMax = alloc.RawAllocationOnly.RAMBytesUsageMax
-			}
+			accumulateRawAllocationOnly(resultAlloc.RawAllocationOnly, alloc.RawAllocationOnly)
 		}
 	}
 
@@ -360,6 +348,13 @@ func (cm *CostModel) computeAllocation(start, end time.Time, resolution time.Dur
 	if err != nil {
 		log.Errorf("CostModel.ComputeAllocation: failed to build pod map: %s", err.Error())
 	}
+
+	// Pods are only known to the resolution of the query above, so refine the
+	// times of those still in the cluster cache, and add those which ran
+	// between two samples.
+	if cm.Cache != nil && env.IsAllocationPodLifetimesEnabled() {
+		applyPodLifetimes(window, resolution, podMap, cm.Cache.GetAllPods(), ingestPodUID, podUIDKeyMap)
+	}
 	// (2) Run and apply remaining queries
 
 	// Query for the duration between start and end
@@ -397,8 +392,11 @@ func (cm *CostModel) computeAllocation(start, end time.Time, resolution time.Dur
 	queryCPUUsageMax := fmt.Sprintf(queryFmtCPUUsageMaxRecordingRule, durStr, env.GetPromClusterLabel())
 	resChCPUUsageMax := ctx.QueryAtTime(queryCPUUsageMax, end)
 	resCPUUsageMax, _ := resChCPUUsageMax.Await()
+	cpuUsageRecordingRule := true
 	// If the recording rule has no data, try to fall back to the subquery.
 	if len(resCPUUsageMax) == 0 {
+		cpuUsageRecordingRule = false
+
 		// The parameter after the metric ...{}[<thisone>] should be set to 2x
 		// the resolution, to make sure the irate always has two points to query
 		// in case the Prom scrape duration has been reduced to be equal to the
@@ -415,6 +413,20 @@ func (cm *CostModel) computeAllocation(start, end time.Time, resolution time.Dur
 		}
 	}
 
+	// Usage percentiles are queried as one query per resource, the union of
+	// the results of each quantile, labeled by their quantile.
+	var resChCPUUsageQuantiles, resChRAMUsageQuantiles prom.QueryResultsChan
+	if env.IsAllocationUsagePercentilesEnabled() {
+		cpuQuantileFmt := queryFmtCPUUsageQuantileRecordingRule
+		cpuQuantileArgs := []interface{}{durStr, env.GetPromClusterLabel()}
+		if !cpuUsageRecordingRule {
+			cpuQuantileFmt = queryFmtCPUUsageQuantileSubquery
+			cpuQuantileArgs = []interface{}{timeutil.DurationString(2 * resolution), durStr, resStr, env.GetPromClusterLabel()}
+		}
+		resChCPUUsageQuantiles = ctx.QueryAtTime(usageQuantilesQuery(cpuQuantileFmt, cpuQuantileArgs...), end)
+		resChRAMUsageQuantiles = ctx.QueryAtTime(usageQuantilesQuery(queryFmtRAMUsageQuantile, durStr, env.GetPromClusterLabel()), end)
+	}
+
 	queryGPUsRequested := fmt.Sprintf(queryFmtGPUsRequested, durStr, env.GetPromClusterLabel())
 	resChGPUsRequested := ctx.QueryAtTime(queryGPUsRequested, end)
 
@@ -514,9 +526,18 @@ func (cm *CostModel) computeAllocation(start, end time.Time, resolution time.Dur
 	queryReplicaSetsWithRolloutOwner := fmt.Sprintf(queryFmtReplicaSetsWithRolloutOwner, durStr, env.GetPromClusterLabel())
 	resChReplicaSetsWithRolloutOwner := ctx.QueryAtTime(queryReplicaSetsWithRolloutOwner, end)
 
+	queryReplicaSetsWithDeploymentOwner := fmt.Sprintf(queryFmtReplicaSetsWithDeploymentOwner, durStr, env.GetPromClusterLabel())
+	resChReplicaSetsWithDeploymentOwner := ctx.QueryAtTime(queryReplicaSetsWithDeploymentOwner, end)
+
+	queryPodsWithStatefulSetOwner := fmt.Sprintf(queryFmtPodsWithStatefulSetOwner, durStr, env.GetPromClusterLabel())
+	resChPodsWithStatefulSetOwner := ctx.QueryAtTime(queryPodsWithStatefulSetOwner, end)
+
 	queryJobLabels := fmt.Sprintf(queryFmtJobLabels, durStr, env.GetPromClusterLabel())
 	resChJobLabels := ctx.QueryAtTime(queryJobLabels, end)
 
+	queryJobOwners := fmt.Sprintf(queryFmtJobOwners, durStr, env.GetPromClusterLabel())
+	resChJobOwners := ctx.QueryAtTime(queryJobOwners, end)
+
 	queryLBCostPerHr := fmt.Sprintf(queryFmtLBCostPerHr, durStr, env.GetPromClusterLabel())
 	resChLBCostPerHr := ctx.QueryAtTime(queryLBCostPerHr, end)
 
@@ -530,6 +551,11 @@ func (cm *CostModel) computeAllocation(start, end time.Time, resolution time.Dur
 	resRAMRequests, _ := resChRAMRequests.Await()
 	resRAMUsageAvg, _ := resChRAMUsageAvg.Await()
 	resRAMUsageMax, _ := resChRAMUsageMax.Await()
+	var resCPUUsageQuantiles, resRAMUsageQuantiles []*prom.QueryResult
+	if resChCPUUsageQuantiles != nil {
+		resCPUUsageQuantiles, _ = resChCPUUsageQuantiles.Await()
+		resRAMUsageQuantiles, _ = resChRAMUsageQuantiles.Await()
+	}
 	resGPUsRequested, _ := resChGPUsRequested.Await()
 	resGPUsAllocated, _ := resChGPUsAllocated.Await()
 
@@ -573,7 +599,10 @@ func (cm *CostModel) computeAllocation(start, end time.Time, resolution time.Dur
 	resPodsWithReplicaSetOwner, _ := resChPodsWithReplicaSetOwner.Await()
 	resReplicaSetsWithoutOwners, _ := resChReplicaSetsWithoutOwners.Await()
 	resReplicaSetsWithRolloutOwner, _ := resChReplicaSetsWithRolloutOwner.Await()
+	resReplicaSetsWithDeploymentOwner, _ := resChReplicaSetsWithDeploymentOwner.Await()
+	resPodsWithStatefulSetOwner, _ := resChPodsWithStatefulSetOwner.Await()
 	resJobLabels, _ := resChJobLabels.Await()
+	resJobOwners, _ := resChJobOwners.Await()
 	resLBCostPerHr, _ := resChLBCostPerHr.Await()
 	resLBActiveMins, _ := resChLBActiveMins.Await()
 
@@ -596,6 +625,8 @@ func (cm *CostModel) computeAllocation(start, end time.Time, resolution time.Dur
 	applyRAMBytesRequested(podMap, resRAMRequests, podUIDKeyMap)
 	applyRAMBytesUsedAvg(podMap, resRAMUsageAvg, podUIDKeyMap)
 	applyRAMBytesUsedMax(podMap, resRAMUsageMax, podUIDKeyMap)
+	applyUsageQuantiles(podMap, resCPUUsageQuantiles, podUIDKeyMap, setCPUCoreUsageQuantile)
+	applyUsageQuantiles(podMap, resRAMUsageQuantiles, podUIDKeyMap, setRAMBytesUsageQuantile)
 	applyGPUsAllocated(podMap, resGPUsRequested, resGPUsAllocated, podUIDKeyMap)
 	applyNetworkTotals(podMap, resNetTransferBytes, resNetReceiveBytes, podUIDKeyMap)
 	applyNetworkAllocation(podMap, resNetZoneGiB, resNetZoneCostPerGiB, podUIDKeyMap, networkCrossZoneCost)
@@ -624,13 +655,20 @@ func (cm *CostModel) computeAllocation(start, end time.Time, resolution time.Dur
 	applyLabels(podMap, nodeLabels, namespaceLabels, podLabels)
 	applyAnnotations(podMap, namespaceAnnotations, podAnnotations)
 
+	// Controllers matched by label selector are applied first, such that the
+	// owner references, where available, override them. Pods with no owner
+	// keep no controller, and aggregate as unallocated by controller.
 	podDeploymentMap := labelsToPodControllerMap(podLabels, resToDeploymentLabels(resDeploymentLabels))
 	podStatefulSetMap := labelsToPodControllerMap(podLabels, resToStatefulSetLabels(resStatefulSetLabels))
+	podDeploymentOwnerMap := resToPodDeploymentMap(resPodsWithReplicaSetOwner, resReplicaSetsWithDeploymentOwner, podUIDKeyMap, ingestPodUID)
+	podStatefulSetOwnerMap := resToPodStatefulSetMap(resPodsWithStatefulSetOwner, podUIDKeyMap, ingestPodUID)
 	podDaemonSetMap := resToPodDaemonSetMap(resDaemonSetLabels, podUIDKeyMap, ingestPodUID)
-	podJobMap := resToPodJobMap(resJobLabels, podUIDKeyMap, ingestPodUID)
+	podJobMap := resToPodJobMap(resJobLabels, resJobOwners, podUIDKeyMap, ingestPodUID)
 	podReplicaSetMap := resToPodReplicaSetMap(resPodsWithReplicaSetOwner, resReplicaSetsWithoutOwners, resReplicaSetsWithRolloutOwner, podUIDKeyMap, ingestPodUID)
 	applyControllersToPods(podMap, podDeploymentMap)
 	applyControllersToPods(podMap, podStatefulSetMap)
+	applyControllersToPods(podMap, podDeploymentOwnerMap)
+	applyControllersToPods(podMap, podStatefulSetOwnerMap)
 	applyControllersToPods(podMap, podDaemonSetMap)
 	applyControllersToPods(podMap, podJobMap)
 	applyControllersToPods(podMap, podReplicaSetMap)
@@ -684,22 +722,11 @@ func (cm *CostModel) computeAllocation(start, end time.Time, resolution time.Dur
 	applyNodeSpot(nodeMap, resNodeIsSpot)
 	applyNodeDiscount(nodeMap, cm)
 	applyExtendedNodeData(nodeMap, nodeExtendedData)
-	cm.applyNodesToPod(podMap, nodeMap)
-
-	// (3) Build out AllocationSet from Pod map
-	for _, pod := range podMap {
-		for _, alloc := range pod.Allocations {
-			cluster := alloc.Properties.Cluster
-			nodeName := alloc.Properties.Node
-			namespace := alloc.Properties.Namespace
-			podName := alloc.Properties.Pod
-			container := alloc.Properties.Container
-
-			// Make sure that the name is correct (node may not be present at this
-			// point due to it missing from queryMinutes) then insert.
-			alloc.Name = fmt.Sprintf("%s/%s/%s/%s/%s", cluster, nodeName, namespace, podName, container)
-			allocSet.Set(alloc)
-		}
+
+	// (3) Build out AllocationSet from Pod map, pricing each allocation by
+	// its node
+	if err := cm.applyNodesToAllocations(allocSet, podMap, nodeMap); err != nil {
+		return nil, nil, err
 	}
 
 	return allocSet, nodeMap, nil
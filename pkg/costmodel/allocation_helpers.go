@@ -13,6 +13,7 @@ import (
 	"github.com/opencost/opencost/pkg/log"
 	"github.com/opencost/opencost/pkg/prom"
 	"github.com/opencost/opencost/pkg/util/timeutil"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
 )
 
@@ -194,6 +195,117 @@ func applyPodResults(window kubecost.Window, resolution time.Duration, podMap ma
 	}
 }
 
+// applyPodLifetimes refines the start and end of pods, which the pod query
+// only resolves to within a resolution, with the times recorded on the pods in
+// the cluster cache. Times are only refined where they agree with the query to
+// within a resolution, such that a pod which reused the name of an earlier pod
+// is not mistaken for it. Pods which ran for less than a resolution, between
+// two samples, are missed by the query altogether; those are added from the
+// cache, allocated the resources they requested. Pods which have since been
+// deleted are no longer cached, and keep the times of the query.
+func applyPodLifetimes(window kubecost.Window, resolution time.Duration, podMap map[podKey]*pod, cachedPods []*v1.Pod, ingestPodUID bool, podUIDKeyMap map[podKey][]podKey) {
+	cluster := env.GetClusterID()
+
+	for _, cachedPod := range cachedPods {
+		start, end, ok := podLifetime(cachedPod, window)
+		if !ok {
+			continue
+		}
+
+		key := newPodKey(cluster, cachedPod.Namespace, cachedPod.Name)
+		nameKey := key
+		if ingestPodUID {
+			key = newPodKey(cluster, cachedPod.Namespace, cachedPod.Name+" "+string(cachedPod.UID))
+		}
+
+		if thisPod, ok := podMap[key]; ok {
+			if absDuration(thisPod.Start.Sub(start)) <= resolution {
+				thisPod.Start = start
+			}
+			if absDuration(thisPod.End.Sub(end)) <= resolution {
+				thisPod.End = end
+			}
+			continue
+		}
+
+		if end.Sub(start) >= resolution {
+			continue
+		}
+
+		thisPod := &pod{
+			Window:      window.Clone(),
+			Start:       start,
+			End:         end,
+			Key:         key,
+			Node:        cachedPod.Spec.NodeName,
+			Allocations: map[string]*kubecost.Allocation{},
+		}
+
+		hours := end.Sub(start).Hours()
+		for _, container := range cachedPod.Spec.Containers {
+			thisPod.appendContainer(container.Name)
+
+			alloc := thisPod.Allocations[container.Name]
+			alloc.Properties.Node = cachedPod.Spec.NodeName
+
+			if cpu, ok := container.Resources.Requests[v1.ResourceCPU]; ok {
+				alloc.CPUCoreRequestAverage = cpu.AsApproximateFloat64()
+				alloc.CPUCoreHours = alloc.CPUCoreRequestAverage * hours
+			}
+			if ram, ok := container.Resources.Requests[v1.ResourceMemory]; ok {
+				alloc.RAMBytesRequestAverage = ram.AsApproximateFloat64()
+				alloc.RAMByteHours = alloc.RAMBytesRequestAverage * hours
+			}
+		}
+
+		podMap[key] = thisPod
+		if ingestPodUID {
+			podUIDKeyMap[nameKey] = append(podUIDKeyMap[nameKey], key)
+		}
+	}
+}
+
+// podLifetime returns the times a pod ran within the window, per its status. A
+// pod which has not terminated is taken to run through the end of the window.
+func podLifetime(p *v1.Pod, window kubecost.Window) (time.Time, time.Time, bool) {
+	if p.Status.StartTime == nil {
+		return time.Time{}, time.Time{}, false
+	}
+	start := p.Status.StartTime.Time.UTC()
+
+	end := *window.End()
+	if now := time.Now().UTC(); end.After(now) {
+		end = now
+	}
+	if p.Status.Phase == v1.PodSucceeded || p.Status.Phase == v1.PodFailed {
+		var finished time.Time
+		for _, cs := range p.Status.ContainerStatuses {
+			if cs.State.Terminated != nil && cs.State.Terminated.FinishedAt.Time.After(finished) {
+				finished = cs.State.Terminated.FinishedAt.Time.UTC()
+			}
+		}
+		if !finished.IsZero() && finished.Before(end) {
+			end = finished
+		}
+	}
+
+	if start.Before(*window.Start()) {
+		start = *window.Start()
+	}
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, false
+	}
+
+	return start, end, true
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
 func applyCPUCoresAllocated(podMap map[podKey]*pod, resCPUCoresAllocated []*prom.QueryResult, podUIDKeyMap map[podKey][]podKey) {
 	for _, res := range resCPUCoresAllocated {
 		key, err := resultPodKey(res, env.GetPromClusterLabel(), "namespace")
@@ -405,6 +517,108 @@ func applyCPUCoresUsedMax(podMap map[podKey]*pod, resCPUCoresUsedMax []*prom.Que
 	}
 }
 
+// usageQuantilesQuery returns the union of the queries of each of the usage
+// quantiles, labeled by their quantile. The quantile is the first argument of
+// the query format, followed by the args.
+func usageQuantilesQuery(queryFmt string, args ...interface{}) string {
+	queries := make([]string, 0, len(usageQuantiles))
+	for _, q := range usageQuantiles {
+		query := fmt.Sprintf(queryFmt, append([]interface{}{q}, args...)...)
+		queries = append(queries, fmt.Sprintf(`label_replace(%s, "quantile", "%s", "", "")`, query, q))
+	}
+	return strings.Join(queries, " or ")
+}
+
+func applyUsageQuantiles(podMap map[podKey]*pod, resUsageQuantiles []*prom.QueryResult, podUIDKeyMap map[podKey][]podKey, set func(*kubecost.RawAllocationOnlyData, string, float64)) {
+	for _, res := range resUsageQuantiles {
+		key, err := resultPodKey(res, env.GetPromClusterLabel(), "namespace")
+		if err != nil {
+			log.DedupedWarningf(10, "CostModel.ComputeAllocation: usage quantile result missing field: %s", err)
+			continue
+		}
+
+		quantile, err := res.GetString("quantile")
+		if err != nil {
+			log.DedupedWarningf(10, "CostModel.ComputeAllocation: usage quantile query result missing 'quantile': %s", key)
+			continue
+		}
+
+		container, err := res.GetString("container")
+		if container == "" || err != nil {
+			container, err = res.GetString("container_name")
+			if err != nil {
+				log.DedupedWarningf(10, "CostModel.ComputeAllocation: usage quantile query result missing 'container': %s", key)
+				continue
+			}
+		}
+
+		var pods []*pod
+		if thisPod, ok := podMap[key]; !ok {
+			if uidKeys, ok := podUIDKeyMap[key]; ok {
+				for _, uidKey := range uidKeys {
+					thisPod, ok = podMap[uidKey]
+					if ok {
+						pods = append(pods, thisPod)
+					}
+				}
+			} else {
+				continue
+			}
+		} else {
+			pods = []*pod{thisPod}
+		}
+
+		for _, thisPod := range pods {
+
+			if _, ok := thisPod.Allocations[container]; !ok {
+				thisPod.appendContainer(container)
+			}
+
+			if thisPod.Allocations[container].RawAllocationOnly == nil {
+				thisPod.Allocations[container].RawAllocationOnly = &kubecost.RawAllocationOnlyData{}
+			}
+			set(thisPod.Allocations[container].RawAllocationOnly, quantile, res.Values[0].Value)
+		}
+	}
+}
+
+func setCPUCoreUsageQuantile(raw *kubecost.RawAllocationOnlyData, quantile string, value float64) {
+	switch quantile {
+	case "0.5":
+		raw.CPUCoreUsageP50 = value
+	case "0.95":
+		raw.CPUCoreUsageP95 = value
+	case "0.99":
+		raw.CPUCoreUsageP99 = value
+	}
+}
+
+func setRAMBytesUsageQuantile(raw *kubecost.RawAllocationOnlyData, quantile string, value float64) {
+	switch quantile {
+	case "0.5":
+		raw.RAMBytesUsageP50 = value
+	case "0.95":
+		raw.RAMBytesUsageP95 = value
+	case "0.99":
+		raw.RAMBytesUsageP99 = value
+	}
+}
+
+// accumulateRawAllocationOnly accumulates the raw data of an allocation of one
+// range of a window into that of the whole window. The usage percentiles of
+// the window cannot be computed from those of its ranges, so the max of the
+// ranges is kept as an upper bound, as for the usage maxes.
+func accumulateRawAllocationOnly(dst, src *kubecost.RawAllocationOnlyData) {
+	dst.CPUCoreUsageMax = math.Max(dst.CPUCoreUsageMax, src.CPUCoreUsageMax)
+	dst.RAMBytesUsageMax = math.Max(dst.RAMBytesUsageMax, src.RAMBytesUsageMax)
+	dst.CPUCoreUsageP50 = math.Max(dst.CPUCoreUsageP50, src.CPUCoreUsageP50)
+	dst.CPUCoreUsageP95 = math.Max(dst.CPUCoreUsageP95, src.CPUCoreUsageP95)
+	dst.CPUCoreUsageP99 = math.Max(dst.CPUCoreUsageP99, src.CPUCoreUsageP99)
+	dst.RAMBytesUsageP50 = math.Max(dst.RAMBytesUsageP50, src.RAMBytesUsageP50)
+	dst.RAMBytesUsageP95 = math.Max(dst.RAMBytesUsageP95, src.RAMBytesUsageP95)
+	dst.RAMBytesUsageP99 = math.Max(dst.RAMBytesUsageP99, src.RAMBytesUsageP99)
+}
+
 func applyRAMBytesAllocated(podMap map[podKey]*pod, resRAMBytesAllocated []*prom.QueryResult, podUIDKeyMap map[podKey][]podKey) {
 	for _, res := range resRAMBytesAllocated {
 		key, err := resultPodKey(res, env.GetPromClusterLabel(), "namespace")
@@ -1113,7 +1327,41 @@ func resToPodDaemonSetMap(resDaemonSetLabels []*prom.QueryResult, podUIDKeyMap m
 	return daemonSetLabels
 }
 
-func resToPodJobMap(resJobLabels []*prom.QueryResult, podUIDKeyMap map[podKey][]podKey, ingestPodUID bool) map[podKey]controllerKey {
+// resToPodJobMap maps pods owned by Jobs to their controller. Jobs owned by a
+// CronJob, per kube_job_owner, are attributed to the CronJob. Where the owner
+// of a Job is unknown, e.g. kube_job_owner is not scraped, pods are attributed
+// to the Job under its own name, as a timestamp suffix alone does not identify
+// a CronJob.
+//
+// Completed Jobs need no special handling: the minutes of their pods are those
+// the pods ran within the window, so a Job which finished mid-window is only
+// charged for the part of the window it ran.
+func resToPodJobMap(resJobLabels []*prom.QueryResult, resJobOwners []*prom.QueryResult, podUIDKeyMap map[podKey][]podKey, ingestPodUID bool) map[podKey]controllerKey {
+	// Jobs, keyed by their own controllerKey, mapped to their CronJob. Jobs
+	// with any other owner, or with none, map to themselves.
+	jobOwners := map[controllerKey]controllerKey{}
+
+	for _, res := range resJobOwners {
+		jobKey, err := resultJobKey(res, env.GetPromClusterLabel(), "namespace", "job_name")
+		if err != nil {
+			continue
+		}
+
+		ownerKind, _ := res.GetString("owner_kind")
+		if ownerKind != "CronJob" {
+			if _, ok := jobOwners[jobKey]; !ok {
+				jobOwners[jobKey] = jobKey
+			}
+			continue
+		}
+
+		cronJobKey, err := resultCronJobKey(res, env.GetPromClusterLabel(), "namespace", "owner_name")
+		if err != nil {
+			continue
+		}
+		jobOwners[jobKey] = cronJobKey
+	}
+
 	jobLabels := map[podKey]controllerKey{}
 
 	for _, res := range resJobLabels {
@@ -1122,11 +1370,8 @@ func resToPodJobMap(resJobLabels []*prom.QueryResult, podUIDKeyMap map[podKey][]
 			continue
 		}
 
-		// Convert the name of Jobs generated by CronJobs to the name of the
-		// CronJob by stripping the timestamp off the end.
-		match := isCron.FindStringSubmatch(controllerKey.Controller)
-		if match != nil {
-			controllerKey.Controller = match[1]
+		if ownerKey, ok := jobOwners[controllerKey]; ok {
+			controllerKey = ownerKey
 		}
 
 		pod, err := res.GetString("pod")
@@ -1156,6 +1401,87 @@ func resToPodJobMap(resJobLabels []*prom.QueryResult, podUIDKeyMap map[podKey][]
 	return jobLabels
 }
 
+// resToPodDeploymentMap maps pods to the Deployments owning their ReplicaSets,
+// per kube_pod_owner and kube_replicaset_owner.
+func resToPodDeploymentMap(resPodsWithReplicaSetOwner []*prom.QueryResult, resReplicaSetsWithDeploymentOwner []*prom.QueryResult, podUIDKeyMap map[podKey][]podKey, ingestPodUID bool) map[podKey]controllerKey {
+	// ReplicaSets, keyed by their own controllerKey, mapped to their Deployment
+	deployments := map[controllerKey]controllerKey{}
+
+	for _, res := range resReplicaSetsWithDeploymentOwner {
+		replicaSetKey, err := resultReplicaSetKey(res, env.GetPromClusterLabel(), "namespace", "replicaset")
+		if err != nil {
+			continue
+		}
+
+		deploymentKey, err := resultDeploymentKey(res, env.GetPromClusterLabel(), "namespace", "owner_name")
+		if err != nil {
+			continue
+		}
+
+		deployments[replicaSetKey] = deploymentKey
+	}
+
+	podToDeployment := map[podKey]controllerKey{}
+
+	for _, res := range resPodsWithReplicaSetOwner {
+		replicaSetKey, err := resultReplicaSetKey(res, env.GetPromClusterLabel(), "namespace", "owner_name")
+		if err != nil {
+			continue
+		}
+
+		deploymentKey, ok := deployments[replicaSetKey]
+		if !ok {
+			continue
+		}
+
+		pod, err := res.GetString("pod")
+		if err != nil {
+			log.Warnf("CostModel.ComputeAllocation: ReplicaSet result without pod: %s", replicaSetKey)
+			continue
+		}
+
+		for _, key := range podKeysForResult(newPodKey(replicaSetKey.Cluster, replicaSetKey.Namespace, pod), podUIDKeyMap, ingestPodUID) {
+			podToDeployment[key] = deploymentKey
+		}
+	}
+
+	return podToDeployment
+}
+
+// resToPodStatefulSetMap maps pods to the StatefulSets owning them, per
+// kube_pod_owner.
+func resToPodStatefulSetMap(resPodsWithStatefulSetOwner []*prom.QueryResult, podUIDKeyMap map[podKey][]podKey, ingestPodUID bool) map[podKey]controllerKey {
+	podToStatefulSet := map[podKey]controllerKey{}
+
+	for _, res := range resPodsWithStatefulSetOwner {
+		controllerKey, err := resultStatefulSetKey(res, env.GetPromClusterLabel(), "namespace", "owner_name")
+		if err != nil {
+			continue
+		}
+
+		pod, err := res.GetString("pod")
+		if err != nil {
+			log.Warnf("CostModel.ComputeAllocation: StatefulSet result without pod: %s", controllerKey)
+			continue
+		}
+
+		for _, key := range podKeysForResult(newPodKey(controllerKey.Cluster, controllerKey.Namespace, pod), podUIDKeyMap, ingestPodUID) {
+			podToStatefulSet[key] = controllerKey
+		}
+	}
+
+	return podToStatefulSet
+}
+
+// podKeysForResult returns the keys of the pods a query result applies to,
+// which, when ingesting pod UIDs, are those of every pod of the same name.
+func podKeysForResult(key podKey, podUIDKeyMap map[podKey][]podKey, ingestPodUID bool) []podKey {
+	if !ingestPodUID {
+		return []podKey{key}
+	}
+	return podUIDKeyMap[key]
+}
+
 func resToPodReplicaSetMap(resPodsWithReplicaSetOwner []*prom.QueryResult, resReplicaSetsWithoutOwners []*prom.QueryResult, resReplicaSetsWithRolloutOwner []*prom.QueryResult, podUIDKeyMap map[podKey][]podKey, ingestPodUID bool) map[podKey]controllerKey {
 	// Build out set of ReplicaSets that have no owners, themselves, such that
 	// the ReplicaSet should be used as the owner of the Pods it controls.
@@ -1570,22 +1896,6 @@ func applyNodeDiscount(nodeMap map[nodeKey]*nodePricing, cm *CostModel) {
 	}
 }
 
-func (cm *CostModel) applyNodesToPod(podMap map[podKey]*pod, nodeMap map[nodeKey]*nodePricing) {
-	for _, pod := range podMap {
-		for _, alloc := range pod.Allocations {
-			cluster := alloc.Properties.Cluster
-			nodeName := alloc.Properties.Node
-			thisNodeKey := newNodeKey(cluster, nodeName)
-
-			node := cm.getNodePricing(nodeMap, thisNodeKey)
-			alloc.Properties.ProviderID = node.ProviderID
-			alloc.CPUCost = alloc.CPUCoreHours * node.CostPerCPUHr
-			alloc.RAMCost = (alloc.RAMByteHours / 1024 / 1024 / 1024) * node.CostPerRAMGiBHr
-			alloc.GPUCost = alloc.GPUHours * node.CostPerGPUHr
-		}
-	}
-}
-
 // getCustomNodePricing converts the CostModel's configured custom pricing
 // values into a nodePricing instance.
 func (cm *CostModel) getCustomNodePricing(spot bool, providerID string) *nodePricing {
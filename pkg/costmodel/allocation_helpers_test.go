@@ -2,11 +2,16 @@ package costmodel
 
 import (
 	"fmt"
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/pkg/env"
 	"github.com/opencost/opencost/pkg/kubecost"
 	"github.com/opencost/opencost/pkg/prom"
 	"github.com/opencost/opencost/pkg/util"
-	"testing"
-	"time"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const Ki = 1024
@@ -508,3 +513,282 @@ func TestCalculateStartAndEnd(t *testing.T) {
 		})
 	}
 }
+
+func TestResToPodJobMap(t *testing.T) {
+	jobPod := func(pod, job string) *prom.QueryResult {
+		return &prom.QueryResult{
+			Metric: map[string]interface{}{
+				"cluster_id": "cluster1",
+				"namespace":  "namespace1",
+				"pod":        pod,
+				"owner_name": job,
+			},
+		}
+	}
+	jobOwner := func(job, ownerKind, ownerName string) *prom.QueryResult {
+		return &prom.QueryResult{
+			Metric: map[string]interface{}{
+				"cluster_id": "cluster1",
+				"namespace":  "namespace1",
+				"job_name":   job,
+				"owner_kind": ownerKind,
+				"owner_name": ownerName,
+			},
+		}
+	}
+
+	resJobLabels := []*prom.QueryResult{
+		jobPod("pod1", "backup-27983520"),
+		jobPod("pod2", "migrate-1234567890"),
+		jobPod("pod3", "report-27983520"),
+		jobPod("pod4", "standalone"),
+	}
+	resJobOwners := []*prom.QueryResult{
+		jobOwner("backup-27983520", "CronJob", "backup"),
+		jobOwner("migrate-1234567890", "<none>", "<none>"),
+	}
+
+	podJobMap := resToPodJobMap(resJobLabels, resJobOwners, nil, false)
+
+	expected := map[string]controllerKey{
+		// owned by a CronJob
+		"pod1": newControllerKey("cluster1", "namespace1", "cronjob", "backup"),
+		// known to have no owner, despite the timestamp suffix
+		"pod2": newControllerKey("cluster1", "namespace1", "job", "migrate-1234567890"),
+		// owner unknown, so the name is kept along with the kind
+		"pod3": newControllerKey("cluster1", "namespace1", "job", "report-27983520"),
+		"pod4": newControllerKey("cluster1", "namespace1", "job", "standalone"),
+	}
+	for pod, exp := range expected {
+		act, ok := podJobMap[newPodKey("cluster1", "namespace1", pod)]
+		if !ok {
+			t.Errorf("%s: expected controller %s; got none", pod, exp)
+			continue
+		}
+		if act != exp {
+			t.Errorf("%s: expected controller %s; got %s", pod, exp, act)
+		}
+	}
+
+	// without kube_job_owner, no Job is attributed to a CronJob
+	podJobMap = resToPodJobMap(resJobLabels, nil, nil, false)
+
+	for _, pod := range []string{"pod1", "pod2", "pod3", "pod4"} {
+		act, ok := podJobMap[newPodKey("cluster1", "namespace1", pod)]
+		if !ok {
+			t.Errorf("%s: expected controller; got none", pod)
+			continue
+		}
+		if act.ControllerKind != "job" {
+			t.Errorf("%s: expected kind job; got %s", pod, act)
+		}
+	}
+	if act := podJobMap[newPodKey("cluster1", "namespace1", "pod1")]; act.Controller != "backup-27983520" {
+		t.Errorf("pod1: expected the Job's name; got %s", act)
+	}
+}
+
+func TestResToPodDeploymentMap(t *testing.T) {
+	resPodsWithReplicaSetOwner := []*prom.QueryResult{
+		{
+			Metric: map[string]interface{}{
+				"cluster_id": "cluster1",
+				"namespace":  "namespace1",
+				"pod":        "pod1",
+				"owner_name": "web-5d8f7c9b4",
+			},
+		},
+		{
+			Metric: map[string]interface{}{
+				"cluster_id": "cluster1",
+				"namespace":  "namespace1",
+				"pod":        "pod2",
+				"owner_name": "bare-replicaset",
+			},
+		},
+	}
+	resReplicaSetsWithDeploymentOwner := []*prom.QueryResult{
+		{
+			Metric: map[string]interface{}{
+				"cluster_id": "cluster1",
+				"namespace":  "namespace1",
+				"replicaset": "web-5d8f7c9b4",
+				"owner_name": "web",
+			},
+		},
+	}
+
+	podDeploymentMap := resToPodDeploymentMap(resPodsWithReplicaSetOwner, resReplicaSetsWithDeploymentOwner, nil, false)
+
+	expected := newControllerKey("cluster1", "namespace1", "deployment", "web")
+	if act := podDeploymentMap[newPodKey("cluster1", "namespace1", "pod1")]; act != expected {
+		t.Errorf("expected controller %s; got %s", expected, act)
+	}
+	if _, ok := podDeploymentMap[newPodKey("cluster1", "namespace1", "pod2")]; ok {
+		t.Errorf("expected no deployment for pod of a ReplicaSet without a Deployment")
+	}
+}
+
+func TestApplyPodLifetimes(t *testing.T) {
+	resolution := 5 * time.Minute
+	cluster := env.GetClusterID()
+	at := func(d time.Duration) *metav1.Time {
+		t := metav1.NewTime(windowStart.Add(d))
+		return &t
+	}
+	ciPod := func(name string, start, finished time.Duration) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ci", Name: name},
+			Spec: v1.PodSpec{
+				NodeName: "node1",
+				Containers: []v1.Container{{
+					Name: "build",
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{
+							v1.ResourceCPU:    resource.MustParse("2"),
+							v1.ResourceMemory: resource.MustParse("1Gi"),
+						},
+					},
+				}},
+			},
+			Status: v1.PodStatus{
+				Phase:     v1.PodSucceeded,
+				StartTime: at(start),
+				ContainerStatuses: []v1.ContainerStatus{{
+					Name:  "build",
+					State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{FinishedAt: *at(finished)}},
+				}},
+			},
+		}
+	}
+
+	podMap := map[podKey]*pod{
+		// sampled once, at 01:05, so the query counts it from 01:00 to 01:05
+		newPodKey(cluster, "ci", "build-1"): {
+			Window:      window.Clone(),
+			Start:       windowStart.Add(time.Hour),
+			End:         windowStart.Add(time.Hour + 5*time.Minute),
+			Key:         newPodKey(cluster, "ci", "build-1"),
+			Allocations: map[string]*kubecost.Allocation{},
+		},
+		// a later pod of the same name as a cached pod
+		newPodKey(cluster, "ci", "build-3"): {
+			Window:      window.Clone(),
+			Start:       windowStart.Add(10 * time.Hour),
+			End:         windowStart.Add(11 * time.Hour),
+			Key:         newPodKey(cluster, "ci", "build-3"),
+			Allocations: map[string]*kubecost.Allocation{},
+		},
+	}
+
+	cachedPods := []*v1.Pod{
+		// ran 01:02:00 to 01:04:30
+		ciPod("build-1", time.Hour+2*time.Minute, time.Hour+4*time.Minute+30*time.Second),
+		// ran 02:01 to 02:03, between two samples
+		ciPod("build-2", 2*time.Hour+time.Minute, 2*time.Hour+3*time.Minute),
+		// ran 03:00 to 03:02
+		ciPod("build-3", 3*time.Hour, 3*time.Hour+2*time.Minute),
+		// ran 23:00 the day before
+		ciPod("build-4", -time.Hour, -time.Hour+2*time.Minute),
+	}
+
+	applyPodLifetimes(window, resolution, podMap, cachedPods, false, map[podKey][]podKey{})
+
+	build1 := podMap[newPodKey(cluster, "ci", "build-1")]
+	if !build1.Start.Equal(windowStart.Add(time.Hour+2*time.Minute)) || !build1.End.Equal(windowStart.Add(time.Hour+4*time.Minute+30*time.Second)) {
+		t.Errorf("build-1: expected times of the cached pod; got %s to %s", build1.Start, build1.End)
+	}
+
+	build2, ok := podMap[newPodKey(cluster, "ci", "build-2")]
+	if !ok {
+		t.Fatalf("build-2: expected pod missed by the query to be added")
+	}
+	alloc := build2.Allocations["build"]
+	if alloc == nil || alloc.Minutes() != 2 {
+		t.Fatalf("build-2: expected allocation of 2 minutes; got %v", alloc)
+	}
+	if alloc.CPUCoreHours != 2*2.0/60.0 || alloc.RAMByteHours != Gi*2.0/60.0 {
+		t.Errorf("build-2: expected requested resources for 2 minutes; got %f core-hours, %f byte-hours", alloc.CPUCoreHours, alloc.RAMByteHours)
+	}
+	if alloc.Properties.Node != "node1" {
+		t.Errorf("build-2: expected node1; got %s", alloc.Properties.Node)
+	}
+
+	build3 := podMap[newPodKey(cluster, "ci", "build-3")]
+	if !build3.Start.Equal(windowStart.Add(10*time.Hour)) || !build3.End.Equal(windowStart.Add(11*time.Hour)) {
+		t.Errorf("build-3: expected times of the query; got %s to %s", build3.Start, build3.End)
+	}
+
+	if _, ok := podMap[newPodKey(cluster, "ci", "build-4")]; ok {
+		t.Errorf("build-4: expected pod outside of the window to be ignored")
+	}
+}
+
+func TestApplyUsageQuantiles(t *testing.T) {
+	key := newPodKey("cluster1", "namespace1", "pod1")
+	podMap := map[podKey]*pod{
+		key: {
+			Window:      window.Clone(),
+			Start:       windowStart,
+			End:         windowEnd,
+			Key:         key,
+			Allocations: map[string]*kubecost.Allocation{},
+		},
+	}
+
+	quantile := func(container, q string, value float64) *prom.QueryResult {
+		return &prom.QueryResult{
+			Metric: map[string]interface{}{
+				"cluster_id": "cluster1",
+				"namespace":  "namespace1",
+				"pod":        "pod1",
+				"container":  container,
+				"quantile":   q,
+			},
+			Values: []*util.Vector{{Value: value}},
+		}
+	}
+
+	resCPUUsageQuantiles := []*prom.QueryResult{
+		quantile("container1", "0.5", 0.25),
+		quantile("container1", "0.95", 0.75),
+		quantile("container1", "0.99", 1.0),
+	}
+	resRAMUsageQuantiles := []*prom.QueryResult{
+		quantile("container1", "0.5", 1*Gi),
+		quantile("container1", "0.95", 2*Gi),
+		quantile("container1", "0.99", 3*Gi),
+		// unknown quantiles are ignored
+		quantile("container1", "0.9", 4*Gi),
+	}
+
+	applyUsageQuantiles(podMap, resCPUUsageQuantiles, map[podKey][]podKey{}, setCPUCoreUsageQuantile)
+	applyUsageQuantiles(podMap, resRAMUsageQuantiles, map[podKey][]podKey{}, setRAMBytesUsageQuantile)
+
+	alloc, ok := podMap[key].Allocations["container1"]
+	if !ok {
+		t.Fatalf("expected container1 to be appended")
+	}
+
+	expected := &kubecost.RawAllocationOnlyData{
+		CPUCoreUsageP50:  0.25,
+		CPUCoreUsageP95:  0.75,
+		CPUCoreUsageP99:  1.0,
+		RAMBytesUsageP50: 1 * Gi,
+		RAMBytesUsageP95: 2 * Gi,
+		RAMBytesUsageP99: 3 * Gi,
+	}
+	if !alloc.RawAllocationOnly.Equal(expected) {
+		t.Errorf("expected %+v; got %+v", expected, alloc.RawAllocationOnly)
+	}
+}
+
+func TestUsageQuantilesQuery(t *testing.T) {
+	query := usageQuantilesQuery(`quantile_over_time(%s, metric[%s])`, "1h")
+	expected := `label_replace(quantile_over_time(0.5, metric[1h]), "quantile", "0.5", "", "") or ` +
+		`label_replace(quantile_over_time(0.95, metric[1h]), "quantile", "0.95", "", "") or ` +
+		`label_replace(quantile_over_time(0.99, metric[1h]), "quantile", "0.99", "", "")`
+	if query != expected {
+		t.Errorf("expected %s; got %s", expected, query)
+	}
+}
@@ -0,0 +1,258 @@
+package costmodel
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"time"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/log"
+	"github.com/opencost/opencost/pkg/util/worker"
+)
+
+// timeRange is a range of time for which allocations are computed.
+type timeRange struct {
+	start time.Time
+	end   time.Time
+}
+
+// allocationResult is the outcome of computing allocations for a timeRange.
+type allocationResult struct {
+	set *kubecost.AllocationSet
+	err error
+}
+
+// computeConcurrency returns the number of workers used for n independent
+// inputs, bounded by the configured concurrency.
+func (cm *CostModel) computeConcurrency(n int) int {
+	workers := cm.ComputeConcurrency
+	if workers > n {
+		workers = n
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	return workers
+}
+
+// concurrentDo runs the work on each input in a pool of at most the configured
+// number of workers, returning the outputs in the order of the inputs. A panic
+// in the work on any input is recovered, and returned as an error once the
+// work on every input is done.
+func concurrentDo[T any, U any](cm *CostModel, work worker.Worker[T, U], inputs []T) ([]U, error) {
+	type outcome struct {
+		value U
+		err   error
+	}
+
+	safeWork := func(input T) (o outcome) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Errorf("Recovered from panic computing allocations: %v\n%s", r, debug.Stack())
+				o.err = fmt.Errorf("panic computing allocations: %v", r)
+			}
+		}()
+		o.value = work(input)
+		return o
+	}
+
+	var outcomes []outcome
+	workers := cm.computeConcurrency(len(inputs))
+	if workers == 1 {
+		// Without parallelism, avoid the overhead of the pool
+		outcomes = make([]outcome, len(inputs))
+		for i, input := range inputs {
+			outcomes[i] = safeWork(input)
+		}
+	} else {
+		pool := worker.NewWorkerPool(workers, safeWork)
+		defer pool.Shutdown()
+
+		group := worker.NewOrderedGroup(pool, len(inputs))
+		for _, input := range inputs {
+			group.Push(input)
+		}
+		outcomes = group.Wait()
+	}
+
+	results := make([]U, len(outcomes))
+	for i, o := range outcomes {
+		if o.err != nil {
+			return nil, o.err
+		}
+		results[i] = o.value
+	}
+	return results, nil
+}
+
+// splitTimeRange divides [start, end) into consecutive ranges of at most max.
+func splitTimeRange(start, end time.Time, max time.Duration) []timeRange {
+	var ranges []timeRange
+	for s := start; s.Before(end); {
+		e := s.Add(max)
+		if e.After(end) {
+			e = end
+		}
+		ranges = append(ranges, timeRange{start: s, end: e})
+		s = e
+	}
+	return ranges
+}
+
+// computeAllocationRanges computes the allocations of each of the disjoint
+// ranges in parallel, returning them in order, or the error of the first range
+// which failed. Ranges in the checkpoints are not recomputed, and computed
+// ranges are added to them.
+func (cm *CostModel) computeAllocationRanges(ranges []timeRange, resolution time.Duration, checkpoints *allocationCheckpoints) ([]*kubecost.AllocationSet, error) {
+	results, err := concurrentDo(cm, func(r timeRange) allocationResult {
+		if as, ok := checkpoints.get(r); ok {
+			return allocationResult{set: as}
+		}
+
+		as, _, err := cm.computeAllocation(r.start, r.end, resolution)
+		if err != nil {
+			err = fmt.Errorf("error computing allocation for %s: %s", kubecost.NewClosedWindow(r.start, r.end), err)
+		} else {
+			checkpoints.save(as)
+		}
+		return allocationResult{set: as, err: err}
+	}, ranges)
+	if err != nil {
+		return nil, err
+	}
+
+	sets := make([]*kubecost.AllocationSet, len(results))
+	for i, result := range results {
+		if result.err != nil {
+			return nil, result.err
+		}
+		sets[i] = result.set
+	}
+	return sets, nil
+}
+
+// queryAllocationSteps queries the allocations of each of the steps in
+// parallel, including idle allocations if requested, returning them in order,
+// or the error of the first step which failed.
+func (cm *CostModel) queryAllocationSteps(steps []timeRange, resolution time.Duration, includeIdle bool) ([]*kubecost.AllocationSet, error) {
+	results, err := concurrentDo(cm, func(r timeRange) allocationResult {
+		as, err := cm.queryAllocationStep(r.start, r.end, resolution, includeIdle)
+		return allocationResult{set: as, err: err}
+	}, steps)
+	if err != nil {
+		return nil, err
+	}
+
+	sets := make([]*kubecost.AllocationSet, len(results))
+	for i, result := range results {
+		if result.err != nil {
+			return nil, result.err
+		}
+		sets[i] = result.set
+	}
+	return sets, nil
+}
+
+func (cm *CostModel) queryAllocationStep(start, end time.Time, resolution time.Duration, includeIdle bool) (*kubecost.AllocationSet, error) {
+	allocSet, err := cm.QueryAllocationSet(start, end, resolution)
+	if err != nil {
+		return nil, fmt.Errorf("error computing allocations for %s: %w", kubecost.NewClosedWindow(start, end), err)
+	}
+
+	if includeIdle {
+		assetSet, err := cm.QueryAssetSet(start, end)
+		if err != nil {
+			return nil, fmt.Errorf("error computing assets for %s: %w", kubecost.NewClosedWindow(start, end), err)
+		}
+
+		idleSet, err := computeIdleAllocations(allocSet, assetSet, true)
+		if err != nil {
+			return nil, fmt.Errorf("error computing idle allocations for %s: %w", kubecost.NewClosedWindow(start, end), err)
+		}
+
+		for _, idleAlloc := range idleSet.Allocations {
+			allocSet.Insert(idleAlloc)
+		}
+	}
+
+	return allocSet, nil
+}
+
+// clusterAllocations are the allocations of one cluster, along with the
+// pricing of its nodes.
+type clusterAllocations struct {
+	allocs  []*kubecost.Allocation
+	nodeMap map[nodeKey]*nodePricing
+}
+
+// applyNodesToAllocations prices and names the allocations of each pod, and
+// inserts them into the set. Clusters share no nodes, so are processed in
+// parallel, each with its own partition of the node map, to which the pricing
+// of nodes missing from the map is added. The partitions are merged back into
+// the node map once every cluster is processed.
+func (cm *CostModel) applyNodesToAllocations(allocSet *kubecost.AllocationSet, podMap map[podKey]*pod, nodeMap map[nodeKey]*nodePricing) error {
+	byCluster := map[string]*clusterAllocations{}
+	partition := func(cluster string) *clusterAllocations {
+		ca, ok := byCluster[cluster]
+		if !ok {
+			ca = &clusterAllocations{nodeMap: map[nodeKey]*nodePricing{}}
+			byCluster[cluster] = ca
+		}
+		return ca
+	}
+	for _, pod := range podMap {
+		for _, alloc := range pod.Allocations {
+			ca := partition(alloc.Properties.Cluster)
+			ca.allocs = append(ca.allocs, alloc)
+		}
+	}
+	for key, node := range nodeMap {
+		ca := partition(key.Cluster)
+		ca.nodeMap[key] = node
+	}
+
+	// Process clusters in a stable order, largest first, so that the largest
+	// is not left until last
+	clusters := make([]*clusterAllocations, 0, len(byCluster))
+	for _, ca := range byCluster {
+		clusters = append(clusters, ca)
+	}
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return len(clusters[i].allocs) > len(clusters[j].allocs)
+	})
+
+	_, err := concurrentDo(cm, func(ca *clusterAllocations) struct{} {
+		for _, alloc := range ca.allocs {
+			cluster := alloc.Properties.Cluster
+			nodeName := alloc.Properties.Node
+			namespace := alloc.Properties.Namespace
+			podName := alloc.Properties.Pod
+			container := alloc.Properties.Container
+
+			node := cm.getNodePricing(ca.nodeMap, newNodeKey(cluster, nodeName))
+			alloc.Properties.ProviderID = node.ProviderID
+			alloc.CPUCost = alloc.CPUCoreHours * node.CostPerCPUHr
+			alloc.RAMCost = (alloc.RAMByteHours / 1024 / 1024 / 1024) * node.CostPerRAMGiBHr
+			alloc.GPUCost = alloc.GPUHours * node.CostPerGPUHr
+
+			// Make sure that the name is correct (node may not be present at this
+			// point due to it missing from queryMinutes).
+			alloc.Name = fmt.Sprintf("%s/%s/%s/%s/%s", cluster, nodeName, namespace, podName, container)
+		}
+		return struct{}{}
+	}, clusters)
+	if err != nil {
+		return err
+	}
+
+	for _, ca := range clusters {
+		for key, node := range ca.nodeMap {
+			nodeMap[key] = node
+		}
+		for _, alloc := range ca.allocs {
+			allocSet.Set(alloc)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,275 @@
+package costmodel
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+)
+
+// latencyPromClient responds to every query with an empty result after a
+// delay, as a remote Prometheus would.
+type latencyPromClient struct {
+	emptyPromClient
+	latency time.Duration
+}
+
+func (lpc latencyPromClient) Do(ctx context.Context, req *http.Request) (*http.Response, []byte, error) {
+	time.Sleep(lpc.latency)
+	return lpc.emptyPromClient.Do(ctx, req)
+}
+
+func TestSplitTimeRange(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(60 * time.Hour)
+
+	ranges := splitTimeRange(start, end, 24*time.Hour)
+	if len(ranges) != 3 {
+		t.Fatalf("expected 3 ranges; got %d", len(ranges))
+	}
+	if !ranges[0].start.Equal(start) || !ranges[2].end.Equal(end) {
+		t.Errorf("expected ranges to cover [%s, %s); got %v", start, end, ranges)
+	}
+	for i := 1; i < len(ranges); i++ {
+		if !ranges[i].start.Equal(ranges[i-1].end) {
+			t.Errorf("expected consecutive ranges; got %v", ranges)
+		}
+	}
+	if d := ranges[2].end.Sub(ranges[2].start); d != 12*time.Hour {
+		t.Errorf("expected last range of 12h; got %s", d)
+	}
+}
+
+func TestConcurrentDo_Order(t *testing.T) {
+	cm := &CostModel{ComputeConcurrency: 4}
+
+	inputs := make([]int, 50)
+	for i := range inputs {
+		inputs[i] = i
+	}
+
+	results, err := concurrentDo(cm, func(i int) int {
+		// finish out of order
+		time.Sleep(time.Duration(50-i) * 100 * time.Microsecond)
+		return i * i
+	}, inputs)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for i, r := range results {
+		if r != i*i {
+			t.Fatalf("expected results in order of inputs; got %d at %d", r, i)
+		}
+	}
+}
+
+func TestConcurrentDo_Panic(t *testing.T) {
+	for _, concurrency := range []int{1, 4} {
+		cm := &CostModel{ComputeConcurrency: concurrency}
+
+		_, err := concurrentDo(cm, func(i int) int {
+			if i == 2 {
+				panic("range failed")
+			}
+			return i
+		}, []int{0, 1, 2, 3})
+		if err == nil || !strings.Contains(err.Error(), "range failed") {
+			t.Errorf("concurrency %d: expected the panic as an error; got %v", concurrency, err)
+		}
+	}
+}
+
+func TestApplyNodesToAllocations(t *testing.T) {
+	cm := NewCostModel(emptyPromClient{}, pricingProvider{}, nil, nil, time.Minute)
+	cm.ComputeConcurrency = 4
+
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	podMap := map[podKey]*pod{}
+	for _, cluster := range []string{"cluster1", "cluster2", "cluster3"} {
+		key := newPodKey(cluster, "ns", "pod")
+		podMap[key] = &pod{
+			Key: key,
+			Allocations: map[string]*kubecost.Allocation{
+				"app": {
+					Properties: &kubecost.AllocationProperties{
+						Cluster:   cluster,
+						Node:      "node1",
+						Namespace: "ns",
+						Pod:       "pod",
+						Container: "app",
+					},
+					Window:       kubecost.NewClosedWindow(start, end),
+					Start:        start,
+					End:          end,
+					CPUCoreHours: 2,
+					RAMByteHours: 1024 * 1024 * 1024,
+				},
+			},
+		}
+	}
+
+	nodeMap := map[nodeKey]*nodePricing{
+		newNodeKey("cluster1", "node1"): {ProviderID: "i-1", CostPerCPUHr: 1, CostPerRAMGiBHr: 0.5},
+		newNodeKey("cluster2", "node1"): {ProviderID: "i-2", CostPerCPUHr: 2, CostPerRAMGiBHr: 0.25},
+	}
+
+	allocSet := kubecost.NewAllocationSet(start, end)
+	if err := cm.applyNodesToAllocations(allocSet, podMap, nodeMap); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if allocSet.Length() != 3 {
+		t.Fatalf("expected 3 allocations; got %d", allocSet.Length())
+	}
+
+	expected := map[string]struct {
+		providerID string
+		cpuCost    float64
+		ramCost    float64
+	}{
+		"cluster1": {"i-1", 2, 0.5},
+		"cluster2": {"i-2", 4, 0.25},
+		// the missing node is priced by the custom pricing
+		"cluster3": {"", 0.06, 0.004},
+	}
+	for cluster, exp := range expected {
+		name := fmt.Sprintf("%s/node1/ns/pod/app", cluster)
+		alloc := allocSet.Get(name)
+		if alloc == nil {
+			t.Fatalf("expected allocation %s", name)
+		}
+		if alloc.Properties.ProviderID != exp.providerID {
+			t.Errorf("%s: expected provider id %q; got %q", cluster, exp.providerID, alloc.Properties.ProviderID)
+		}
+		if fmt.Sprintf("%.4f", alloc.CPUCost) != fmt.Sprintf("%.4f", exp.cpuCost) || fmt.Sprintf("%.4f", alloc.RAMCost) != fmt.Sprintf("%.4f", exp.ramCost) {
+			t.Errorf("%s: expected cpu and ram costs of %f and %f; got %f and %f", cluster, exp.cpuCost, exp.ramCost, alloc.CPUCost, alloc.RAMCost)
+		}
+	}
+
+	if _, ok := nodeMap[newNodeKey("cluster3", "node1")]; !ok {
+		t.Errorf("expected pricing of the missing node to be added to the node map")
+	}
+}
+
+// benchmarkComputeAllocation computes allocations over a week, in daily
+// ranges, from a Prometheus responding to each query after 2ms.
+func benchmarkComputeAllocation(b *testing.B, concurrency int) {
+	cm := NewCostModel(latencyPromClient{latency: 2 * time.Millisecond}, pricingProvider{}, nil, nil, time.Minute)
+	cm.MaxPrometheusQueryDuration = 24 * time.Hour
+	cm.ComputeConcurrency = concurrency
+
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(7 * 24 * time.Hour)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := cm.ComputeAllocation(start, end, 5*time.Minute); err != nil {
+			b.Fatalf("unexpected error: %s", err)
+		}
+	}
+}
+
+func BenchmarkComputeAllocation_Sequential(b *testing.B) {
+	benchmarkComputeAllocation(b, 1)
+}
+
+func BenchmarkComputeAllocation_Parallel4(b *testing.B) {
+	benchmarkComputeAllocation(b, 4)
+}
+
+func BenchmarkComputeAllocation_Parallel7(b *testing.B) {
+	benchmarkComputeAllocation(b, 7)
+}
+
+// benchmarkQueryAllocation queries daily allocations over a week, from a
+// Prometheus responding to each query after 2ms.
+func benchmarkQueryAllocation(b *testing.B, concurrency int) {
+	cm := NewCostModel(latencyPromClient{latency: 2 * time.Millisecond}, pricingProvider{}, nil, nil, time.Minute)
+	cm.ComputeConcurrency = concurrency
+
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	window := kubecost.NewClosedWindow(start, start.Add(7*24*time.Hour))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := cm.QueryAllocation(window, 5*time.Minute, 24*time.Hour, nil, false, false, false, false); err != nil {
+			b.Fatalf("unexpected error: %s", err)
+		}
+	}
+}
+
+func BenchmarkQueryAllocation_Sequential(b *testing.B) {
+	benchmarkQueryAllocation(b, 1)
+}
+
+func BenchmarkQueryAllocation_Parallel4(b *testing.B) {
+	benchmarkQueryAllocation(b, 4)
+}
+
+// benchmarkApplyNodesToAllocations prices the allocations of 1000 pods on each
+// of 8 clusters.
+func benchmarkApplyNodesToAllocations(b *testing.B, concurrency int) {
+	cm := NewCostModel(emptyPromClient{}, pricingProvider{}, nil, nil, time.Minute)
+	cm.ComputeConcurrency = concurrency
+
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	podMap := map[podKey]*pod{}
+	nodeMap := map[nodeKey]*nodePricing{}
+	for c := 0; c < 8; c++ {
+		cluster := fmt.Sprintf("cluster%d", c)
+		for n := 0; n < 10; n++ {
+			nodeMap[newNodeKey(cluster, fmt.Sprintf("node%d", n))] = &nodePricing{CostPerCPUHr: 1, CostPerRAMGiBHr: 0.5}
+		}
+		for p := 0; p < 1000; p++ {
+			key := newPodKey(cluster, "ns", fmt.Sprintf("pod%d", p))
+			podMap[key] = &pod{
+				Key: key,
+				Allocations: map[string]*kubecost.Allocation{
+					"app": {
+						Properties: &kubecost.AllocationProperties{
+							Cluster:   cluster,
+							Node:      fmt.Sprintf("node%d", p%10),
+							Namespace: "ns",
+							Pod:       key.Pod,
+							Container: "app",
+						},
+						Window:       kubecost.NewClosedWindow(start, end),
+						Start:        start,
+						End:          end,
+						CPUCoreHours: 2,
+						RAMByteHours: 1024 * 1024 * 1024,
+					},
+				},
+			}
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		allocSet := kubecost.NewAllocationSet(start, end)
+		if err := cm.applyNodesToAllocations(allocSet, podMap, nodeMap); err != nil {
+			b.Fatalf("unexpected error: %s", err)
+		}
+	}
+}
+
+func BenchmarkApplyNodesToAllocations_Sequential(b *testing.B) {
+	benchmarkApplyNodesToAllocations(b, 1)
+}
+
+func BenchmarkApplyNodesToAllocations_Parallel4(b *testing.B) {
+	benchmarkApplyNodesToAllocations(b, 4)
+}
+
+func BenchmarkApplyNodesToAllocations_Parallel8(b *testing.B) {
+	benchmarkApplyNodesToAllocations(b, 8)
+}
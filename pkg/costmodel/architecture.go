@@ -0,0 +1,273 @@
+package costmodel
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/opencost/opencost/pkg/cloud/models"
+	"github.com/opencost/opencost/pkg/env"
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/log"
+	"github.com/opencost/opencost/pkg/util/httputil"
+)
+
+// MigrationCandidate is an instance type of another architecture or processor
+// vendor with at least the capacity of the instance type a workload runs on,
+// and what the workload would have cost running on it instead.
+type MigrationCandidate struct {
+	InstanceType   string  `json:"instanceType"`
+	Family         string  `json:"family"`
+	Architecture   string  `json:"architecture"`
+	Processor      string  `json:"processor"`
+	HourlyCost     float64 `json:"hourlyCost"`
+	ProjectedCost  float64 `json:"projectedCost"`
+	Savings        float64 `json:"savings"`
+	SavingsPercent float64 `json:"savingsPercent"`
+	// RequiresMultiArchImages is true if the candidate is of another
+	// architecture, so the workload's images must be built for it.
+	RequiresMultiArchImages bool `json:"requiresMultiArchImages"`
+}
+
+// WorkloadArchitectureComparison compares the cost of a workload on the nodes
+// of one instance type with its cost on the cheapest equivalent instance type
+// of each other architecture and processor vendor, ordered by savings.
+type WorkloadArchitectureComparison struct {
+	Cluster        string                `json:"cluster"`
+	Namespace      string                `json:"namespace"`
+	ControllerKind string                `json:"controllerKind"`
+	Controller     string                `json:"controller"`
+	Region         string                `json:"region"`
+	InstanceType   string                `json:"instanceType"`
+	Family         string                `json:"family"`
+	Architecture   string                `json:"architecture"`
+	Processor      string                `json:"processor"`
+	HourlyCost     float64               `json:"hourlyCost"`
+	Cost           float64               `json:"cost"`
+	Candidates     []*MigrationCandidate `json:"candidates"`
+}
+
+// ComputeArchitectureComparisons compares the cost of each workload in the
+// window against its cost on equivalent capacity of the other architectures
+// and processor vendors offered by the provider, e.g. x86 against Graviton.
+func (cm *CostModel) ComputeArchitectureComparisons(start, end time.Time, resolution time.Duration) ([]*WorkloadArchitectureComparison, error) {
+	itp, ok := cm.Provider.(models.InstanceTypePricingProvider)
+	if !ok {
+		return nil, fmt.Errorf("instance type pricing is not supported by the provider")
+	}
+
+	instanceTypes, err := itp.InstanceTypePricing()
+	if err != nil {
+		return nil, fmt.Errorf("pricing instance types: %w", err)
+	}
+
+	nodes, err := cm.ClusterNodes(start, end)
+	if err != nil {
+		return nil, fmt.Errorf("computing nodes: %w", err)
+	}
+
+	as, err := cm.ComputeAllocation(start, end, resolution)
+	if err != nil {
+		return nil, fmt.Errorf("computing allocations: %w", err)
+	}
+
+	// Nodes without region labels are assumed to be in the cluster's region
+	var clusterRegion string
+	if info, err := cm.Provider.ClusterInfo(); err == nil {
+		clusterRegion = info["region"]
+	} else {
+		log.Warnf("Architecture comparisons: failed to get cluster info: %s", err)
+	}
+
+	return computeArchitectureComparisons(as, nodes, instanceTypes, clusterRegion), nil
+}
+
+// instanceTypeKey identifies an instance type by region and operating system,
+// which vary its price.
+func instanceTypeKey(region, name, os string) string {
+	return fmt.Sprintf("%s,%s,%s", region, name, os)
+}
+
+// nodeInstanceType returns the priced instance type of the node, or nil if it
+// has none. Nodes without region labels are in the default region.
+func nodeInstanceType(node *Node, instanceTypes map[string]*models.InstanceType, defaultRegion string) *models.InstanceType {
+	region := node.Labels["label_topology_kubernetes_io_region"]
+	if region == "" {
+		region = node.Labels["label_failure_domain_beta_kubernetes_io_region"]
+	}
+	if region == "" {
+		region = defaultRegion
+	}
+	os := node.Labels["label_kubernetes_io_os"]
+	if os == "" {
+		os = "linux"
+	}
+
+	return instanceTypes[instanceTypeKey(region, node.NodeType, os)]
+}
+
+// migrationCandidates returns the cheapest instance type of each architecture
+// and processor vendor other than that of the current instance type, which has
+// at least its CPU and RAM, the same number of GPUs, and costs less.
+func migrationCandidates(current *models.InstanceType, alternatives []*models.InstanceType) []*models.InstanceType {
+	cheapest := map[string]*models.InstanceType{}
+	for _, it := range alternatives {
+		if it.Processor == "" || (it.Architecture == current.Architecture && it.Processor == current.Processor) {
+			continue
+		}
+		if it.VCPU < current.VCPU || it.RAMBytes < current.RAMBytes || it.GPU != current.GPU || it.Cost >= current.Cost {
+			continue
+		}
+
+		group := it.Architecture + "/" + it.Processor
+		if c, ok := cheapest[group]; !ok || it.Cost < c.Cost || (it.Cost == c.Cost && it.Name < c.Name) {
+			cheapest[group] = it
+		}
+	}
+
+	candidates := make([]*models.InstanceType, 0, len(cheapest))
+	for _, it := range cheapest {
+		candidates = append(candidates, it)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Cost < candidates[j].Cost
+	})
+	return candidates
+}
+
+// computeArchitectureComparisons projects the cost of each workload on the
+// migration candidates of the instance types of the nodes it ran on. Only the
+// CPU, RAM and GPU costs of a workload depend on its nodes, so the projected
+// cost scales those by the ratio of the candidate's price to the current
+// instance type's. Pods without a controller are excluded, as are nodes of
+// instance types the provider does not price. Nodes without region labels are
+// assumed to be in the cluster region.
+func computeArchitectureComparisons(as *kubecost.AllocationSet, nodes map[NodeIdentifier]*Node, instanceTypes []*models.InstanceType, clusterRegion string) []*WorkloadArchitectureComparison {
+	instanceTypesByKey := map[string]*models.InstanceType{}
+	alternativesByRegion := map[string][]*models.InstanceType{}
+	for _, it := range instanceTypes {
+		instanceTypesByKey[instanceTypeKey(it.Region, it.Name, it.OperatingSystem)] = it
+		regionKey := it.Region + "," + it.OperatingSystem
+		alternativesByRegion[regionKey] = append(alternativesByRegion[regionKey], it)
+	}
+
+	nodeTypes := map[nodeKey]*models.InstanceType{}
+	for _, node := range nodes {
+		if it := nodeInstanceType(node, instanceTypesByKey, clusterRegion); it != nil {
+			nodeTypes[newNodeKey(node.Cluster, node.Name)] = it
+		}
+	}
+
+	type comparisonKey struct {
+		workload     controllerKey
+		instanceType *models.InstanceType
+	}
+
+	costs := map[comparisonKey]float64{}
+	for _, alloc := range as.Allocations {
+		if alloc.Properties == nil || alloc.Properties.Controller == "" {
+			continue
+		}
+		props := alloc.Properties
+
+		it, ok := nodeTypes[newNodeKey(props.Cluster, props.Node)]
+		if !ok {
+			continue
+		}
+
+		key := comparisonKey{
+			workload:     newControllerKey(props.Cluster, props.Namespace, props.ControllerKind, props.Controller),
+			instanceType: it,
+		}
+		costs[key] += alloc.CPUTotalCost() + alloc.RAMTotalCost() + alloc.GPUTotalCost()
+	}
+
+	candidatesByType := map[*models.InstanceType][]*models.InstanceType{}
+	var comparisons []*WorkloadArchitectureComparison
+	for key, cost := range costs {
+		current := key.instanceType
+		if cost <= 0 {
+			continue
+		}
+
+		candidates, ok := candidatesByType[current]
+		if !ok {
+			candidates = migrationCandidates(current, alternativesByRegion[current.Region+","+current.OperatingSystem])
+			candidatesByType[current] = candidates
+		}
+		if len(candidates) == 0 {
+			continue
+		}
+
+		wac := &WorkloadArchitectureComparison{
+			Cluster:        key.workload.Cluster,
+			Namespace:      key.workload.Namespace,
+			ControllerKind: key.workload.ControllerKind,
+			Controller:     key.workload.Controller,
+			Region:         current.Region,
+			InstanceType:   current.Name,
+			Family:         current.Family,
+			Architecture:   current.Architecture,
+			Processor:      current.Processor,
+			HourlyCost:     current.Cost,
+			Cost:           cost,
+		}
+		for _, it := range candidates {
+			projected := cost * it.Cost / current.Cost
+			wac.Candidates = append(wac.Candidates, &MigrationCandidate{
+				InstanceType:   it.Name,
+				Family:         it.Family,
+				Architecture:   it.Architecture,
+				Processor:      it.Processor,
+				HourlyCost:     it.Cost,
+				ProjectedCost:  projected,
+				Savings:        cost - projected,
+				SavingsPercent: (cost - projected) / cost * 100,
+
+				RequiresMultiArchImages: it.Architecture != current.Architecture,
+			})
+		}
+		comparisons = append(comparisons, wac)
+	}
+
+	sort.Slice(comparisons, func(i, j int) bool {
+		si, sj := comparisons[i].Candidates[0].Savings, comparisons[j].Candidates[0].Savings
+		if si != sj {
+			return si > sj
+		}
+		return fmt.Sprintf("%s/%s/%s/%s/%s", comparisons[i].Cluster, comparisons[i].Namespace, comparisons[i].ControllerKind, comparisons[i].Controller, comparisons[i].InstanceType) <
+			fmt.Sprintf("%s/%s/%s/%s/%s", comparisons[j].Cluster, comparisons[j].Namespace, comparisons[j].ControllerKind, comparisons[j].Controller, comparisons[j].InstanceType)
+	})
+
+	if comparisons == nil {
+		comparisons = []*WorkloadArchitectureComparison{}
+	}
+	return comparisons
+}
+
+// ComputeArchitectureComparisonHandler responds with the potential savings of
+// migrating each workload to another architecture or processor vendor.
+func (a *Accesses) ComputeArchitectureComparisonHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	w.Header().Set("Content-Type", "application/json")
+
+	qp := httputil.NewQueryParams(r.URL.Query())
+
+	window, err := kubecost.ParseWindowWithOffset(qp.Get("window", ""), env.GetParsedUTCOffset())
+	if err != nil || window.IsOpen() {
+		http.Error(w, fmt.Sprintf("Invalid 'window' parameter: %s", qp.Get("window", "")), http.StatusBadRequest)
+		return
+	}
+	resolution := qp.GetDuration("resolution", env.GetETLResolution())
+
+	comparisons, err := a.Model.ComputeArchitectureComparisons(*window.Start(), *window.End(), resolution)
+	if err != nil {
+		log.Errorf("Error computing architecture comparisons: %s", err)
+		WriteError(w, InternalServerError(err.Error()))
+		return
+	}
+
+	w.Write(WrapData(comparisons, nil))
+}
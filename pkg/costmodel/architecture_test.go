@@ -0,0 +1,142 @@
+package costmodel
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/pkg/cloud/models"
+	"github.com/opencost/opencost/pkg/kubecost"
+)
+
+func TestComputeArchitectureComparisons(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+
+	instanceType := func(name, arch, processor string, vcpu, ramGiB, cost float64) *models.InstanceType {
+		return &models.InstanceType{
+			Name:            name,
+			Region:          "us-east-1",
+			OperatingSystem: "linux",
+			Family:          name[:3],
+			Architecture:    arch,
+			Processor:       processor,
+			VCPU:            vcpu,
+			RAMBytes:        ramGiB * 1024 * 1024 * 1024,
+			Cost:            cost,
+		}
+	}
+
+	instanceTypes := []*models.InstanceType{
+		instanceType("m6i.large", models.ArchitectureAMD64, models.ProcessorIntel, 2, 8, 0.096),
+		instanceType("m6a.large", models.ArchitectureAMD64, models.ProcessorAMD, 2, 8, 0.0864),
+		instanceType("m6g.large", models.ArchitectureARM64, models.ProcessorGraviton, 2, 8, 0.077),
+		// cheaper, but smaller
+		instanceType("t4g.small", models.ArchitectureARM64, models.ProcessorGraviton, 2, 2, 0.0168),
+		// equivalent, but more expensive than m6g.large
+		instanceType("m7g.large", models.ArchitectureARM64, models.ProcessorGraviton, 2, 8, 0.0816),
+	}
+
+	node := func(name, nodeType string) *Node {
+		return &Node{
+			Cluster:  "cluster1",
+			Name:     name,
+			NodeType: nodeType,
+			Labels: map[string]string{
+				"label_topology_kubernetes_io_region": "us-east-1",
+				"label_kubernetes_io_os":              "linux",
+			},
+		}
+	}
+	nodes := map[NodeIdentifier]*Node{
+		{Cluster: "cluster1", Name: "node1"}: node("node1", "m6i.large"),
+		{Cluster: "cluster1", Name: "node2"}: node("node2", "m6g.large"),
+		{Cluster: "cluster1", Name: "node3"}: node("node3", "unknown.large"),
+		// nodes without region labels are in the cluster region
+		{Cluster: "cluster1", Name: "node4"}: {Cluster: "cluster1", Name: "node4", NodeType: "m6a.large"},
+	}
+
+	alloc := func(pod, node, controller string, cpuCost, pvCost float64) *kubecost.Allocation {
+		return &kubecost.Allocation{
+			Name: pod,
+			Properties: &kubecost.AllocationProperties{
+				Cluster:        "cluster1",
+				Node:           node,
+				Namespace:      "namespace1",
+				ControllerKind: "deployment",
+				Controller:     controller,
+				Pod:            pod,
+				Container:      "container1",
+			},
+			Window:  kubecost.NewWindow(&start, &end),
+			Start:   start,
+			End:     end,
+			CPUCost: cpuCost,
+			PVs: kubecost.PVAllocations{
+				{Cluster: "cluster1", Name: "pv-" + pod}: {Cost: pvCost},
+			},
+		}
+	}
+
+	as := kubecost.NewAllocationSet(start, end,
+		alloc("web-1", "node1", "web", 6, 10),
+		alloc("web-2", "node1", "web", 3, 10),
+		// already on the cheapest equivalent
+		alloc("api-1", "node2", "api", 5, 0),
+		// nodes of unpriced instance types are excluded
+		alloc("batch-1", "node3", "batch", 5, 0),
+		// bare pods are excluded
+		alloc("bare", "node1", "", 5, 0),
+		alloc("worker-1", "node4", "worker", 3, 0),
+	)
+
+	comparisons := computeArchitectureComparisons(as, nodes, instanceTypes, "us-east-1")
+	if len(comparisons) != 2 {
+		t.Fatalf("expected 2 comparisons; got %d", len(comparisons))
+	}
+
+	wac := comparisons[0]
+	if wac.Controller != "web" || wac.InstanceType != "m6i.large" {
+		t.Fatalf("expected web on m6i.large; got %s on %s", wac.Controller, wac.InstanceType)
+	}
+	// PV costs do not depend on the instance type
+	if wac.Cost != 9 {
+		t.Errorf("expected cost 9; got %f", wac.Cost)
+	}
+
+	expected := map[string]float64{
+		"m6g.large": 9 * 0.077 / 0.096,
+		"m6a.large": 9 * 0.0864 / 0.096,
+	}
+	multiArch := map[string]bool{
+		"m6g.large": true,
+		"m6a.large": false,
+	}
+	if len(wac.Candidates) != len(expected) {
+		t.Fatalf("expected %d candidates; got %d", len(expected), len(wac.Candidates))
+	}
+	if wac.Candidates[0].InstanceType != "m6g.large" {
+		t.Errorf("expected the cheapest candidate first; got %s", wac.Candidates[0].InstanceType)
+	}
+	for _, c := range wac.Candidates {
+		projected, ok := expected[c.InstanceType]
+		if !ok {
+			t.Errorf("unexpected candidate %s", c.InstanceType)
+			continue
+		}
+		if math.Abs(c.ProjectedCost-projected) > 1e-9 || math.Abs(c.Savings-(9-projected)) > 1e-9 {
+			t.Errorf("%s: expected projected cost %f; got %f, saving %f", c.InstanceType, projected, c.ProjectedCost, c.Savings)
+		}
+		if c.RequiresMultiArchImages != multiArch[c.InstanceType] {
+			t.Errorf("%s: expected requiresMultiArchImages %t", c.InstanceType, multiArch[c.InstanceType])
+		}
+	}
+
+	worker := comparisons[1]
+	if worker.Controller != "worker" || worker.Region != "us-east-1" {
+		t.Fatalf("expected worker in us-east-1; got %s in %s", worker.Controller, worker.Region)
+	}
+	if len(worker.Candidates) != 1 || worker.Candidates[0].InstanceType != "m6g.large" || !worker.Candidates[0].RequiresMultiArchImages {
+		t.Errorf("expected m6g.large, requiring multi-arch images, as the only candidate; got %+v", worker.Candidates)
+	}
+}
@@ -27,6 +27,16 @@ func (cm *CostModel) ComputeAssets(start, end time.Time) (*kubecost.AssetSet, er
 		return nil, fmt.Errorf("error computing disk assets for %s: %w", kubecost.NewClosedWindow(start, end), err)
 	}
 
+	publicIPMap, err := cm.ClusterPublicIPs(start, end)
+	if err != nil {
+		return nil, fmt.Errorf("error computing public IP assets for %s: %w", kubecost.NewClosedWindow(start, end), err)
+	}
+
+	natGatewayMap, err := cm.ClusterNATGateways(start, end)
+	if err != nil {
+		return nil, fmt.Errorf("error computing NAT gateway assets for %s: %w", kubecost.NewClosedWindow(start, end), err)
+	}
+
 	for _, d := range diskMap {
 		s := d.Start
 		if s.Before(start) || s.After(end) {
@@ -90,6 +100,26 @@ func (cm *CostModel) ComputeAssets(start, end time.Time) (*kubecost.AssetSet, er
 		assetSet.Insert(loadBalancer, nil)
 	}
 
+	for _, ip := range publicIPMap {
+		s, e := clampToWindow(ip.Start, ip.End, start, end)
+
+		publicIP := kubecost.NewPublicIP(ip.IP, ip.Cluster, ip.IP, s, e, kubecost.NewWindow(&start, &end))
+		cm.PropertiesFromCluster(publicIP.Properties)
+		publicIP.Cost = ip.Cost
+		assetSet.Insert(publicIP, nil)
+	}
+
+	for _, ng := range natGatewayMap {
+		s, e := clampToWindow(ng.Start, ng.End, start, end)
+
+		natGateway := kubecost.NewNATGateway(ng.ID, ng.Cluster, ng.ID, s, e, kubecost.NewWindow(&start, &end))
+		cm.PropertiesFromCluster(natGateway.Properties)
+		natGateway.Cost = ng.Cost
+		natGateway.DataProcessingCost = ng.DataProcessingCost
+		natGateway.DataProcessedBytes = ng.DataProcessedBytes
+		assetSet.Insert(natGateway, nil)
+	}
+
 	for _, n := range nodeMap {
 		// check label, to see if node from fargate, if so ignore.
 		if n.Labels != nil {
@@ -134,6 +164,20 @@ func (cm *CostModel) ComputeAssets(start, end time.Time) (*kubecost.AssetSet, er
 		node.GPUCount = n.GPUCount
 		node.RAMCost = n.RAMCost
 
+		// GPUs attached to the node are itemized as an asset of their own,
+		// named after the node, so the node is left with the cost of its CPU
+		// and RAM.
+		if n.GPUCount > 0 && n.GPUCost > 0 {
+			gpu := kubecost.NewGPU(n.Name, n.Cluster, n.ProviderID, s, e, kubecost.NewWindow(&start, &end))
+			cm.PropertiesFromCluster(gpu.Properties)
+			gpu.Cost = n.GPUCost
+			gpu.GPUHours = n.GPUCount * hours
+			gpu.SetLabels(kubecost.AssetLabels(n.Labels))
+			assetSet.Insert(gpu, nil)
+
+			node.GPUCost = 0.0
+		}
+
 		if n.Overhead != nil {
 			node.Overhead = &kubecost.NodeOverhead{
 				RamOverheadFraction: n.Overhead.RamOverheadFraction,
@@ -163,6 +207,14 @@ func (cm *CostModel) ClusterLoadBalancers(start, end time.Time) (map[LoadBalance
 	return ClusterLoadBalancers(cm.PrometheusClient, start, end)
 }
 
+func (cm *CostModel) ClusterPublicIPs(start, end time.Time) (map[PublicIPIdentifier]*PublicIP, error) {
+	return ClusterPublicIPs(cm.PrometheusClient, start, end)
+}
+
+func (cm *CostModel) ClusterNATGateways(start, end time.Time) (map[NATGatewayIdentifier]*NATGateway, error) {
+	return ClusterNATGateways(cm.PrometheusClient, start, end)
+}
+
 func (cm *CostModel) ClusterNodes(start, end time.Time) (map[NodeIdentifier]*Node, error) {
 	return ClusterNodes(cm.Provider, cm.PrometheusClient, start, end)
 }
@@ -185,3 +237,15 @@ func (cm *CostModel) PropertiesFromCluster(props *kubecost.AssetProperties) {
 	props.Account = ci.Account
 	props.Provider = ci.Provider
 }
+
+// clampToWindow returns the start and end of an asset clamped to the window
+// [start, end).
+func clampToWindow(s, e, start, end time.Time) (time.Time, time.Time) {
+	if s.Before(start) || s.After(end) {
+		s = start
+	}
+	if e.Before(start) || e.After(end) {
+		e = end
+	}
+	return s, e
+}
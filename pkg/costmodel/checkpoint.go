@@ -0,0 +1,96 @@
+package costmodel
+
+import (
+	"context"
+	"time"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/log"
+	"github.com/opencost/opencost/pkg/store"
+)
+
+// allocationCheckpoints are the ranges of a window already computed at a
+// resolution by an earlier, interrupted computation of the window, which are
+// resumed from rather than recomputed.
+type allocationCheckpoints struct {
+	checkpointer store.Checkpointer
+	window       kubecost.Window
+	resolution   time.Duration
+	sets         map[checkpointKey]*kubecost.AllocationSet
+}
+
+// checkpointKey identifies a range by its start and end in Unix seconds, as
+// decoded times may differ in location from those of the range, and the
+// resolution at which it was computed.
+type checkpointKey struct {
+	start      int64
+	end        int64
+	resolution time.Duration
+}
+
+// loadAllocationCheckpoints returns the checkpoints of [start, end) computed at
+// the resolution, or nil if the window is not checkpointed. Only finalized
+// windows split into more than one range are checkpointed, as the allocations
+// of a window which has not ended change as it progresses, and a window of a
+// single range has nothing to resume from.
+func (cm *CostModel) loadAllocationCheckpoints(start, end time.Time, resolution time.Duration, ranges []timeRange) *allocationCheckpoints {
+	if !cm.CheckpointAllocations || len(ranges) <= 1 || end.After(time.Now()) {
+		return nil
+	}
+	checkpointer, ok := cm.store.(store.Checkpointer)
+	if !ok {
+		return nil
+	}
+
+	window := kubecost.NewClosedWindow(start, end)
+	cp := &allocationCheckpoints{
+		checkpointer: checkpointer,
+		window:       window,
+		resolution:   resolution,
+		sets:         map[checkpointKey]*kubecost.AllocationSet{},
+	}
+
+	sets, err := checkpointer.LoadCheckpoints(context.Background(), window, resolution)
+	if err != nil {
+		log.Warnf("CostModel: loading checkpoints of %s from %s store: %s", window, cm.store.Name(), err)
+		return cp
+	}
+	for _, as := range sets {
+		cp.sets[checkpointKey{start: as.Start().Unix(), end: as.End().Unix(), resolution: resolution}] = as
+	}
+	if len(sets) > 0 {
+		log.Infof("CostModel: resuming computation of %s from %d checkpointed ranges", window, len(sets))
+	}
+	return cp
+}
+
+// get returns the checkpointed allocations of the range, if any.
+func (cp *allocationCheckpoints) get(r timeRange) (*kubecost.AllocationSet, bool) {
+	if cp == nil {
+		return nil, false
+	}
+	as, ok := cp.sets[checkpointKey{start: r.start.Unix(), end: r.end.Unix(), resolution: cp.resolution}]
+	return as, ok
+}
+
+// save checkpoints the computed allocations of a range. Failing to do so only
+// costs recomputing the range if the computation is interrupted, so is not an
+// error.
+func (cp *allocationCheckpoints) save(as *kubecost.AllocationSet) {
+	if cp == nil {
+		return
+	}
+	if err := cp.checkpointer.SaveCheckpoint(context.Background(), cp.window, cp.resolution, as); err != nil {
+		log.Warnf("CostModel: checkpointing %s: %s", cp.window, err)
+	}
+}
+
+// clear deletes the checkpoints of the window, once it is computed.
+func (cp *allocationCheckpoints) clear() {
+	if cp == nil {
+		return
+	}
+	if err := cp.checkpointer.ClearCheckpoints(context.Background(), cp.window, cp.resolution); err != nil {
+		log.Warnf("CostModel: clearing checkpoints of %s: %s", cp.window, err)
+	}
+}
@@ -0,0 +1,87 @@
+package costmodel
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/store"
+)
+
+// checkpointStore records the checkpoints of a single window in memory.
+type checkpointStore struct {
+	store.Store
+
+	lock    sync.Mutex
+	sets    map[int64]*kubecost.AllocationSet
+	cleared bool
+}
+
+func (cs *checkpointStore) Name() string {
+	return "checkpoint"
+}
+
+func (cs *checkpointStore) SaveCheckpoint(ctx context.Context, window kubecost.Window, resolution time.Duration, as *kubecost.AllocationSet) error {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+	cs.sets[as.Start().Unix()] = as
+	return nil
+}
+
+func (cs *checkpointStore) LoadCheckpoints(ctx context.Context, window kubecost.Window, resolution time.Duration) ([]*kubecost.AllocationSet, error) {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+	var sets []*kubecost.AllocationSet
+	for _, as := range cs.sets {
+		sets = append(sets, as)
+	}
+	return sets, nil
+}
+
+func (cs *checkpointStore) ClearCheckpoints(ctx context.Context, window kubecost.Window, resolution time.Duration) error {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+	cs.sets = map[int64]*kubecost.AllocationSet{}
+	cs.cleared = true
+	return nil
+}
+
+func TestComputeAllocation_ResumesFromCheckpoints(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(3 * 24 * time.Hour)
+	day2 := start.Add(24 * time.Hour)
+
+	// the second day was computed before the computation was interrupted
+	checkpointed := kubecost.NewAllocationSet(day2, day2.Add(24*time.Hour))
+	checkpointed.Set(kubecost.NewMockUnitAllocation("", day2, 24*time.Hour, nil))
+
+	cs := &checkpointStore{sets: map[int64]*kubecost.AllocationSet{day2.Unix(): checkpointed}}
+
+	cm := NewCostModel(emptyPromClient{}, pricingProvider{}, nil, nil, time.Minute)
+	cm.MaxPrometheusQueryDuration = 24 * time.Hour
+	cm.CheckpointAllocations = true
+	cm.SetStore(cs, 24*time.Hour)
+
+	as, err := cm.ComputeAllocation(start, end, 5*time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if as.Length() != 1 || as.TotalCost() != checkpointed.TotalCost() {
+		t.Errorf("expected the checkpointed allocation in the result; got %d allocations costing %f", as.Length(), as.TotalCost())
+	}
+	if !cs.cleared || len(cs.sets) != 0 {
+		t.Errorf("expected checkpoints to be cleared once the window is computed")
+	}
+
+	// windows which have not ended are not checkpointed
+	cs.cleared = false
+	now := time.Now().Truncate(time.Hour)
+	if _, err := cm.ComputeAllocation(now.Add(-36*time.Hour), now.Add(time.Hour), 5*time.Minute); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if cs.cleared {
+		t.Errorf("expected a window which has not ended not to be checkpointed")
+	}
+}
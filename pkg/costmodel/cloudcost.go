@@ -0,0 +1,197 @@
+package costmodel
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/opencost/opencost/pkg/cloud"
+	"github.com/opencost/opencost/pkg/cloud/aws"
+	"github.com/opencost/opencost/pkg/cloud/models"
+	"github.com/opencost/opencost/pkg/cloudcost"
+	"github.com/opencost/opencost/pkg/env"
+	filterutil "github.com/opencost/opencost/pkg/filter/util"
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/log"
+	"github.com/opencost/opencost/pkg/util/httputil"
+	"github.com/opencost/opencost/pkg/util/timeutil"
+)
+
+// cloudCostIntegrations returns the integrations with the billing exports
+// configured for the provider, by key.
+func cloudCostIntegrations(provider models.Provider) map[string]cloud.CloudCostIntegration {
+	integrations := map[string]cloud.CloudCostIntegration{}
+
+	if awsProvider, ok := provider.(*aws.AWS); ok {
+		aai, err := awsProvider.GetAWSAthenaInfo()
+		if err != nil {
+			log.Warnf("CloudCost: failed to read Athena configuration: %s", err)
+			return integrations
+		}
+
+		ac, ok := aws.ConvertAwsAthenaInfoToConfig(*aai).(*aws.AthenaConfiguration)
+		if !ok {
+			return integrations
+		}
+		if err := ac.Validate(); err != nil {
+			log.Warnf("CloudCost: invalid Athena configuration: %s", err)
+			return integrations
+		}
+
+		integrations[ac.Key()] = &aws.AthenaIntegration{
+			AthenaQuerier: aws.AthenaQuerier{AthenaConfiguration: *ac},
+		}
+	}
+
+	return integrations
+}
+
+// startCloudCostIngestors starts ingesting the cloud costs of each integration
+// configured for the provider into the repository.
+func startCloudCostIngestors(provider models.Provider, repo *cloudcost.MemoryRepository) []*cloudcost.Ingestor {
+	config := cloudcost.IngestorConfig{
+		RefreshInterval: env.GetCloudCostRefreshInterval(),
+		RunWindow:       time.Duration(env.GetCloudCostRunWindowDays()) * timeutil.Day,
+		Retention:       time.Duration(env.GetCloudCostRetentionDays()) * timeutil.Day,
+	}
+
+	var ingestors []*cloudcost.Ingestor
+	for key, integration := range cloudCostIntegrations(provider) {
+		log.Infof("CloudCost: ingesting cloud costs of %s", key)
+
+		ingestor := cloudcost.NewIngestor(key, integration, repo, config)
+		ingestor.Start()
+		ingestors = append(ingestors, ingestor)
+	}
+
+	if len(ingestors) == 0 {
+		log.Warnf("CloudCost: no billing export integrations are configured")
+	}
+
+	return ingestors
+}
+
+// ComputeCloudCostHandler responds with the cloud costs ingested from the
+// billing exports, including spend outside of Kubernetes, such as managed
+// databases, buckets and queues, in daily sets.
+func (a *Accesses) ComputeCloudCostHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	w.Header().Set("Content-Type", "application/json")
+
+	qp := httputil.NewQueryParams(r.URL.Query())
+
+	// Window is a required field describing the window of time over which to
+	// query cloud costs, expanded to whole days.
+	window, err := kubecost.ParseWindowWithOffset(qp.Get("window", ""), env.GetParsedUTCOffset())
+	if err != nil || window.IsOpen() {
+		http.Error(w, fmt.Sprintf("Invalid 'window' parameter: %s", qp.Get("window", "")), http.StatusBadRequest)
+		return
+	}
+
+	// Aggregate is an optional comma-separated list of properties by which to
+	// aggregate cloud costs, including tags, given as "label:<name>".
+	// Examples: "service", "accountID,label:team"
+	var aggregateBy []string
+	for _, raw := range qp.GetList("aggregate", ",") {
+		prop, err := kubecost.ParseCloudCostProperty(raw)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid 'aggregate' parameter: %s", err), http.StatusBadRequest)
+			return
+		}
+		aggregateBy = append(aggregateBy, prop)
+	}
+
+	// Currency is an optional parameter, defaulting to the configured display
+	// currency, giving the currency to which costs are converted.
+	conversion, err := a.responseCurrency(r)
+	if err != nil {
+		writeCurrencyError(w, err)
+		return
+	}
+
+	ccsr, err := a.CloudCostRepository.Query(&cloudcost.QueryRequest{
+		Start:             *window.Start(),
+		End:               *window.End(),
+		AggregateBy:       aggregateBy,
+		Accumulate:        qp.GetBool("accumulate", false),
+		Filter:            filterutil.CloudCostFilterFromParams(qp),
+		ExcludeKubernetes: qp.GetBool("excludeKubernetes", false),
+	})
+	if err != nil {
+		WriteError(w, InternalServerError(err.Error()))
+		return
+	}
+
+	if !conversion.IsIdentity() {
+		ccsr.ConvertCurrency(conversion.Rate)
+	}
+
+	w.Write(WrapDataWithCurrency(ccsr, conversion))
+}
+
+// GetCloudCostStatus responds with the state of the ingestion of each
+// integration.
+func (a *Accesses) GetCloudCostStatus(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	w.Header().Set("Content-Type", "application/json")
+
+	statuses := []cloudcost.IngestorStatus{}
+	for _, ingestor := range a.cloudCostIngestors {
+		statuses = append(statuses, ingestor.Status())
+	}
+
+	w.Write(WrapData(statuses, nil))
+}
+
+// computeExternalCosts links the cloud costs outside of Kubernetes over the
+// window of each set of the range to the tenants of the cluster's namespaces,
+// per the configured tag rules.
+func (a *Accesses) computeExternalCosts(asr *kubecost.AllocationSetRange) ([]*cloudcost.ExternalCostSet, error) {
+	if a.CloudCostRepository == nil {
+		return nil, fmt.Errorf("external costs require cloud costs to be enabled with %s", env.CloudCostEnabledEnvVar)
+	}
+
+	rules, err := cloudcost.ParseTagRules(env.GetExternalCostTagRules())
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", env.ExternalCostTagRulesEnvVar, err)
+	}
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("external costs require tag rules to be configured with %s", env.ExternalCostTagRulesEnvVar)
+	}
+
+	namespaceLabels := map[string]map[string]string{}
+	if a.ClusterCache != nil {
+		for _, ns := range a.ClusterCache.GetAllNamespaces() {
+			namespaceLabels[ns.Name] = ns.Labels
+		}
+	}
+
+	var sets []*cloudcost.ExternalCostSet
+	for _, as := range asr.Slice() {
+		if as.Window.IsOpen() {
+			continue
+		}
+
+		ccsr, err := a.CloudCostRepository.Query(&cloudcost.QueryRequest{
+			Start:             *as.Window.Start(),
+			End:               *as.Window.End(),
+			Accumulate:        true,
+			ExcludeKubernetes: true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("querying cloud costs: %w", err)
+		}
+
+		// Cloud costs are daily, so are pro-rated to windows of part of a day
+		scale := as.Window.Duration().Hours() / ccsr.Window.Duration().Hours()
+
+		ecs, err := cloudcost.JoinExternalCosts(ccsr.CloudCostSets[0], rules, namespaceLabels, kubecost.AmortizedNetCostMetric, scale)
+		if err != nil {
+			return nil, err
+		}
+		ecs.Window = as.Window.Clone()
+		sets = append(sets, ecs)
+	}
+
+	return sets, nil
+}
@@ -3,6 +3,7 @@ package costmodel
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/opencost/opencost/pkg/cloud/provider"
@@ -848,6 +849,266 @@ func ClusterLoadBalancers(client prometheus.Client, start, end time.Time) (map[L
 	return loadBalancerMap, nil
 }
 
+// hourlyAsset is the activity and cost of one series of the metric of an asset
+// billed by the hour, such as a public IP or NAT gateway.
+type hourlyAsset struct {
+	Cluster string
+	Labels  map[string]string
+	Start   time.Time
+	End     time.Time
+	Minutes float64
+	Cost    float64
+}
+
+// queryHourlyAssets queries the activity and average hourly cost of each
+// series of the metric over [start, end), identified by the labels. The first
+// label is required; the others may be absent, and are then empty.
+func queryHourlyAssets(client prometheus.Client, metric string, labels []string, start, end time.Time) (map[string]*hourlyAsset, error) {
+	// Query for the duration between start and end
+	durStr := timeutil.DurationString(end.Sub(start))
+	if durStr == "" {
+		return nil, fmt.Errorf("illegal duration value for %s", kubecost.NewClosedWindow(start, end))
+	}
+
+	minsPerResolution := int(env.GetETLResolution().Minutes())
+	if minsPerResolution < 1 {
+		minsPerResolution = 1
+	}
+
+	by := strings.Join(append(append([]string{}, labels...), env.GetPromClusterLabel()), ", ")
+
+	ctx := prom.NewNamedContext(client, prom.ClusterContextName)
+
+	queryCost := fmt.Sprintf(`avg(avg_over_time(%s[%s])) by (%s)`, metric, durStr, by)
+	queryActiveMins := fmt.Sprintf(`avg(%s) by (%s)[%s:%dm]`, metric, by, durStr, minsPerResolution)
+
+	resChCost := ctx.QueryAtTime(queryCost, end)
+	resChActiveMins := ctx.QueryAtTime(queryActiveMins, end)
+
+	resCost, _ := resChCost.Await()
+	resActiveMins, _ := resChActiveMins.Await()
+
+	if ctx.HasErrors() {
+		return nil, ctx.ErrorCollection()
+	}
+
+	keyOf := func(result *prom.QueryResult) (string, string, map[string]string, bool) {
+		cluster, err := result.GetString(env.GetPromClusterLabel())
+		if err != nil {
+			cluster = env.GetClusterID()
+		}
+		values := make(map[string]string, len(labels))
+		key := cluster
+		for i, label := range labels {
+			value, err := result.GetString(label)
+			if err != nil && i == 0 {
+				log.DedupedWarningf(5, "queryHourlyAssets: %s data missing %s", metric, label)
+				return "", "", nil, false
+			}
+			values[label] = value
+			key += "/" + value
+		}
+		return key, cluster, values, true
+	}
+
+	assets := make(map[string]*hourlyAsset, len(resActiveMins))
+
+	for _, result := range resActiveMins {
+		key, cluster, values, ok := keyOf(result)
+		if !ok || len(result.Values) == 0 {
+			continue
+		}
+
+		s := time.Unix(int64(result.Values[0].Timestamp), 0)
+		e := time.Unix(int64(result.Values[len(result.Values)-1].Timestamp), 0)
+		assets[key] = &hourlyAsset{
+			Cluster: cluster,
+			Labels:  values,
+			Start:   s,
+			End:     e,
+			Minutes: e.Sub(s).Minutes(),
+		}
+	}
+
+	for _, result := range resCost {
+		key, _, _, ok := keyOf(result)
+		if !ok || len(result.Values) == 0 {
+			continue
+		}
+
+		// Apply cost as price-per-hour * hours
+		if asset, ok := assets[key]; ok {
+			asset.Cost += result.Values[0].Value * asset.Minutes / 60.0
+		} else {
+			log.DedupedWarningf(20, "queryHourlyAssets: found %s cost for key that does not exist: %s", metric, key)
+		}
+	}
+
+	return assets, nil
+}
+
+type PublicIPIdentifier struct {
+	Cluster string
+	IP      string
+}
+
+type PublicIP struct {
+	Cluster string
+	IP      string
+	Node    string
+	Cost    float64
+	Start   time.Time
+	End     time.Time
+	Minutes float64
+}
+
+// ClusterPublicIPs returns the public IP addresses of nodes and load balancers,
+// and those allocated but unattached, over [start, end), as recorded by
+// kubecost_public_ip_cost. Only the addresses of nodes have a node.
+func ClusterPublicIPs(client prometheus.Client, start, end time.Time) (map[PublicIPIdentifier]*PublicIP, error) {
+	assets, err := queryHourlyAssets(client, "kubecost_public_ip_cost", []string{"ip", "node"}, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	publicIPMap := make(map[PublicIPIdentifier]*PublicIP, len(assets))
+	for _, asset := range assets {
+		key := PublicIPIdentifier{
+			Cluster: asset.Cluster,
+			IP:      asset.Labels["ip"],
+		}
+
+		// An IP moved between nodes is a single address
+		if ip, ok := publicIPMap[key]; ok {
+			ip.Cost += asset.Cost
+			if asset.Start.Before(ip.Start) {
+				ip.Start = asset.Start
+			}
+			if asset.End.After(ip.End) {
+				ip.End = asset.End
+			}
+			ip.Minutes = ip.End.Sub(ip.Start).Minutes()
+			continue
+		}
+
+		publicIPMap[key] = &PublicIP{
+			Cluster: asset.Cluster,
+			IP:      asset.Labels["ip"],
+			Node:    asset.Labels["node"],
+			Cost:    asset.Cost,
+			Start:   asset.Start,
+			End:     asset.End,
+			Minutes: asset.Minutes,
+		}
+	}
+
+	return publicIPMap, nil
+}
+
+type NATGatewayIdentifier struct {
+	Cluster string
+	ID      string
+}
+
+type NATGateway struct {
+	Cluster            string
+	ID                 string
+	Region             string
+	Cost               float64
+	DataProcessingCost float64
+	DataProcessedBytes float64
+	Start              time.Time
+	End                time.Time
+	Minutes            float64
+}
+
+// ClusterNATGateways returns the NAT gateways of each cluster's network over
+// [start, end), as recorded by kubecost_nat_gateway_cost. The data processed
+// by those routed through by the cluster, for which
+// kubecost_nat_gateway_data_processing_cost is recorded, is estimated as the
+// internet egress of the cluster's pods, split evenly between them, and priced
+// as recorded. Egress is not assigned to NAT gateways which are not routed.
+func ClusterNATGateways(client prometheus.Client, start, end time.Time) (map[NATGatewayIdentifier]*NATGateway, error) {
+	assets, err := queryHourlyAssets(client, "kubecost_nat_gateway_cost", []string{"nat_gateway_id", "region"}, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	natGatewayMap := make(map[NATGatewayIdentifier]*NATGateway, len(assets))
+	for _, asset := range assets {
+		key := NATGatewayIdentifier{
+			Cluster: asset.Cluster,
+			ID:      asset.Labels["nat_gateway_id"],
+		}
+		ng := &NATGateway{
+			Cluster: asset.Cluster,
+			ID:      asset.Labels["nat_gateway_id"],
+			Region:  asset.Labels["region"],
+			Cost:    asset.Cost,
+			Start:   asset.Start,
+			End:     asset.End,
+			Minutes: asset.Minutes,
+		}
+		natGatewayMap[key] = ng
+	}
+
+	if len(natGatewayMap) == 0 {
+		return natGatewayMap, nil
+	}
+
+	durStr := timeutil.DurationString(end.Sub(start))
+
+	ctx := prom.NewNamedContext(client, prom.ClusterContextName)
+
+	queryEgressBytes := fmt.Sprintf(`sum(increase(kubecost_pod_network_egress_bytes_total{internet="true"}[%s])) by (%s)`, durStr, env.GetPromClusterLabel())
+	queryDataCost := fmt.Sprintf(`avg(avg_over_time(kubecost_nat_gateway_data_processing_cost[%s])) by (nat_gateway_id, %s)`, durStr, env.GetPromClusterLabel())
+
+	resChEgressBytes := ctx.QueryAtTime(queryEgressBytes, end)
+	resChDataCost := ctx.QueryAtTime(queryDataCost, end)
+
+	resEgressBytes, _ := resChEgressBytes.Await()
+	resDataCost, _ := resChDataCost.Await()
+
+	if ctx.HasErrors() {
+		return nil, ctx.ErrorCollection()
+	}
+
+	// The data processing cost is only recorded for routed NAT gateways
+	costPerGiB := map[*NATGateway]float64{}
+	routedByCluster := map[string][]*NATGateway{}
+	for _, result := range resDataCost {
+		cluster, err := result.GetString(env.GetPromClusterLabel())
+		if err != nil {
+			cluster = env.GetClusterID()
+		}
+		id, err := result.GetString("nat_gateway_id")
+		if err != nil || len(result.Values) == 0 {
+			continue
+		}
+		if ng, ok := natGatewayMap[NATGatewayIdentifier{Cluster: cluster, ID: id}]; ok {
+			costPerGiB[ng] = result.Values[0].Value
+			routedByCluster[cluster] = append(routedByCluster[cluster], ng)
+		}
+	}
+
+	for _, result := range resEgressBytes {
+		cluster, err := result.GetString(env.GetPromClusterLabel())
+		if err != nil {
+			cluster = env.GetClusterID()
+		}
+		ngs := routedByCluster[cluster]
+		if len(ngs) == 0 || len(result.Values) == 0 {
+			continue
+		}
+		for _, ng := range ngs {
+			ng.DataProcessedBytes = result.Values[0].Value / float64(len(ngs))
+			ng.DataProcessingCost = ng.DataProcessedBytes / 1024 / 1024 / 1024 * costPerGiB[ng]
+		}
+	}
+
+	return natGatewayMap, nil
+}
+
 // ComputeClusterCosts gives the cumulative and monthly-rate cluster costs over a window of time for all clusters.
 func (a *Accesses) ComputeClusterCosts(client prometheus.Client, provider models.Provider, window, offset time.Duration, withBreakdown bool) (map[string]*ClusterCosts, error) {
 	if window < 10*time.Minute {
@@ -16,6 +16,7 @@ import (
 	"github.com/opencost/opencost/pkg/kubecost"
 	"github.com/opencost/opencost/pkg/log"
 	"github.com/opencost/opencost/pkg/prom"
+	"github.com/opencost/opencost/pkg/store"
 	"github.com/opencost/opencost/pkg/util"
 	prometheus "github.com/prometheus/client_golang/api"
 	prometheusClient "github.com/prometheus/client_golang/api"
@@ -59,6 +60,19 @@ type CostModel struct {
 	PrometheusClient           prometheus.Client
 	Provider                   costAnalyzerCloud.Provider
 	pricingMetadata            *costAnalyzerCloud.PricingMatchMetadata
+	store                      store.Store
+	storeQueryAfter            time.Duration
+
+	// ComputeConcurrency is the number of time ranges, and of clusters, for
+	// which allocations are computed in parallel.
+	ComputeConcurrency int
+
+	// CheckpointAllocations, if the store supports it, persists each range of
+	// a finalized window split into more than one range of at most
+	// MaxPrometheusQueryDuration as it is computed, so that an interrupted
+	// computation of the window at the same resolution resumes from the
+	// ranges already computed.
+	CheckpointAllocations bool
 }
 
 func NewCostModel(client prometheus.Client, provider costAnalyzerCloud.Provider, cache clustercache.ClusterCache, clusterMap clusters.ClusterMap, scrapeInterval time.Duration) *CostModel {
@@ -73,6 +87,8 @@ func NewCostModel(client prometheus.Client, provider costAnalyzerCloud.Provider,
 		Provider:                   provider,
 		RequestGroup:               requestGroup,
 		ScrapeInterval:             scrapeInterval,
+		ComputeConcurrency:         env.GetAllocationComputeConcurrency(),
+		CheckpointAllocations:      env.IsAllocationCheckpointsEnabled(),
 	}
 }
 
@@ -2311,36 +2327,25 @@ func (cm *CostModel) QueryAllocation(window kubecost.Window, resolution, step ti
 	// Begin with empty response
 	asr := kubecost.NewAllocationSetRange()
 
-	// Query for AllocationSets in increments of the given step duration,
-	// appending each to the response.
-	stepStart := *window.Start()
-	stepEnd := stepStart.Add(step)
-	for window.End().After(stepStart) {
-		allocSet, err := cm.ComputeAllocation(stepStart, stepEnd, resolution)
-		if err != nil {
-			return nil, fmt.Errorf("error computing allocations for %s: %w", kubecost.NewClosedWindow(stepStart, stepEnd), err)
-		}
-
-		if includeIdle {
-			assetSet, err := cm.ComputeAssets(stepStart, stepEnd)
-			if err != nil {
-				return nil, fmt.Errorf("error computing assets for %s: %w", kubecost.NewClosedWindow(stepStart, stepEnd), err)
-			}
-
-			idleSet, err := computeIdleAllocations(allocSet, assetSet, true)
-			if err != nil {
-				return nil, fmt.Errorf("error computing idle allocations for %s: %w", kubecost.NewClosedWindow(stepStart, stepEnd), err)
-			}
+	// Query for AllocationSets in increments of the given step duration, in
+	// parallel, appending each to the response.
+	if step <= 0 {
+		step = window.Duration()
+	}
+	var steps []timeRange
+	for stepStart := *window.Start(); window.End().After(stepStart); stepStart = stepStart.Add(step) {
+		steps = append(steps, timeRange{start: stepStart, end: stepStart.Add(step)})
+	}
 
-			for _, idleAlloc := range idleSet.Allocations {
-				allocSet.Insert(idleAlloc)
-			}
+	sets, err := cm.queryAllocationSteps(steps, resolution, includeIdle)
+	if err != nil {
+		return nil, err
+	}
+	for _, allocSet := range sets {
+		if cm.store != nil && allocSet.FromSource == cm.store.Name() {
+			asr.FromStore = allocSet.FromSource
 		}
-
 		asr.Append(allocSet)
-
-		stepStart = stepEnd
-		stepEnd = stepStart.Add(step)
 	}
 
 	// Set aggregation options and aggregate
@@ -2351,7 +2356,7 @@ func (cm *CostModel) QueryAllocation(window kubecost.Window, resolution, step ti
 	}
 
 	// Aggregate
-	err := asr.AggregateBy(aggregate, opts)
+	err = asr.AggregateBy(aggregate, opts)
 	if err != nil {
 		return nil, fmt.Errorf("error aggregating for %s: %w", window, err)
 	}
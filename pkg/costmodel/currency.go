@@ -0,0 +1,142 @@
+package costmodel
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/opencost/opencost/pkg/cloud/models"
+	"github.com/opencost/opencost/pkg/currency"
+	"github.com/opencost/opencost/pkg/env"
+	"github.com/opencost/opencost/pkg/log"
+	"github.com/opencost/opencost/pkg/util/httputil"
+	"github.com/opencost/opencost/pkg/util/json"
+)
+
+// defaultCurrency is the currency of prices which do not specify one.
+const defaultCurrency = "USD"
+
+var (
+	sourceCurrencyLock sync.RWMutex
+	sourceCurrencyFunc func() string
+)
+
+// setSourceCurrency sets the function returning the currency of the
+// provider's prices, which responses not converted to another currency report
+// their costs in.
+func setSourceCurrency(f func() string) {
+	sourceCurrencyLock.Lock()
+	defer sourceCurrencyLock.Unlock()
+
+	sourceCurrencyFunc = f
+}
+
+// unconvertedCurrency describes the currency of costs in a response which were
+// not converted from the currency of the provider's prices.
+func unconvertedCurrency() *currency.Conversion {
+	sourceCurrencyLock.RLock()
+	f := sourceCurrencyFunc
+	sourceCurrencyLock.RUnlock()
+
+	code := defaultCurrency
+	if f != nil {
+		code = f()
+	}
+	return &currency.Conversion{Code: code, OriginalCode: code, Rate: 1}
+}
+
+// newCurrencyConverter creates the Converter of the configured rate source.
+func newCurrencyConverter() (*currency.Converter, error) {
+	var source currency.RateSource
+	switch env.GetCurrencyRateSource() {
+	case "ecb":
+		source = currency.NewECBSource()
+	case "static":
+		static, err := currency.ParseStaticRates(defaultCurrency, env.GetCurrencyStaticRates())
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", env.CurrencyStaticRatesEnvVar, err)
+		}
+		source = static
+	default:
+		return nil, fmt.Errorf("unknown %s: %s", env.CurrencyRateSourceEnvVar, env.GetCurrencyRateSource())
+	}
+
+	return currency.NewConverter(source, env.GetCurrencyRateRefreshInterval()), nil
+}
+
+// sourceCurrency returns the currency of the provider's prices, and so of all
+// costs computed from them.
+func (a *Accesses) sourceCurrency() string {
+	return SourceCurrency(a.CloudProvider)
+}
+
+// SourceCurrency returns the currency of the provider's prices, defaulting to
+// USD if the provider is nil or its prices do not specify one.
+func SourceCurrency(provider models.Provider) string {
+	if provider == nil {
+		return defaultCurrency
+	}
+
+	cp, err := provider.GetConfig()
+	if err != nil || cp.CurrencyCode == "" {
+		return defaultCurrency
+	}
+	return currency.Normalize(cp.CurrencyCode)
+}
+
+// responseCurrency returns the conversion of costs to the currency requested
+// by the "currency" parameter, defaulting to the configured display currency,
+// or to the currency of the provider's prices if neither is set. The error is
+// currency.ErrUnknownCurrency if the currency is not a currency code, or
+// currency.ErrRatesUnavailable if costs cannot be converted to it.
+func (a *Accesses) responseCurrency(r *http.Request) (*currency.Conversion, error) {
+	qp := httputil.NewQueryParams(r.URL.Query())
+
+	source := a.sourceCurrency()
+	target := qp.Get("currency", env.GetDisplayCurrency())
+	if target == "" {
+		target = source
+	}
+
+	if a.Currency == nil {
+		if !currency.IsCode(target) {
+			return nil, fmt.Errorf("%w: %s", currency.ErrUnknownCurrency, currency.Normalize(target))
+		}
+		if currency.Normalize(target) != source {
+			return nil, fmt.Errorf("%w: currency conversion is not configured", currency.ErrRatesUnavailable)
+		}
+		return &currency.Conversion{Code: source, OriginalCode: source, Rate: 1}, nil
+	}
+
+	return a.Currency.Convert(source, target)
+}
+
+// writeCurrencyError responds to a request for costs in a currency to which
+// they could not be converted: an unknown currency is a bad request, while
+// unavailable exchange rates are not the fault of the request.
+func writeCurrencyError(w http.ResponseWriter, err error) {
+	if errors.Is(err, currency.ErrUnknownCurrency) {
+		WriteError(w, BadRequest(err.Error()))
+		return
+	}
+
+	WriteError(w, Error{StatusCode: http.StatusServiceUnavailable, Body: err.Error()})
+}
+
+// WrapDataWithCurrency wraps data, the costs of which are in the currency of
+// the conversion, in a Response.
+func WrapDataWithCurrency(data interface{}, conversion *currency.Conversion) []byte {
+	resp, err := json.Marshal(&Response{
+		Code:     http.StatusOK,
+		Status:   "success",
+		Data:     data,
+		Currency: conversion,
+		Pricing:  currentPricingDataVersion(),
+	})
+	if err != nil {
+		log.Errorf("error marshaling response json: %s", err.Error())
+	}
+
+	return resp
+}
@@ -0,0 +1,63 @@
+package costmodel
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/pkg/currency"
+	"github.com/opencost/opencost/pkg/env"
+)
+
+func TestResponseCurrency(t *testing.T) {
+	source, err := currency.ParseStaticRates("USD", "EUR=0.9")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	a := &Accesses{
+		CloudProvider: pricingProvider{},
+		Currency:      currency.NewConverter(source, time.Hour),
+	}
+
+	t.Setenv(env.DisplayCurrencyEnvVar, "")
+	conversion, err := a.responseCurrency(httptest.NewRequest("GET", "/allocation", nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if conversion.Code != "USD" || !conversion.IsIdentity() {
+		t.Errorf("expected costs in USD without a display currency; got %+v", conversion)
+	}
+
+	t.Setenv(env.DisplayCurrencyEnvVar, "EUR")
+	conversion, err = a.responseCurrency(httptest.NewRequest("GET", "/allocation", nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if conversion.Code != "EUR" || conversion.OriginalCode != "USD" || conversion.Rate != 0.9 {
+		t.Errorf("expected conversion from USD to EUR at 0.9; got %+v", conversion)
+	}
+
+	// the parameter takes precedence over the display currency
+	conversion, err = a.responseCurrency(httptest.NewRequest("GET", "/allocation?currency=usd", nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if conversion.Code != "USD" || !conversion.IsIdentity() {
+		t.Errorf("expected costs in USD; got %+v", conversion)
+	}
+
+	// an unknown currency is a bad request, while a currency without a rate
+	// is unavailable
+	for query, status := range map[string]int{"XYZ": http.StatusBadRequest, "JPY": http.StatusServiceUnavailable} {
+		_, err := a.responseCurrency(httptest.NewRequest("GET", "/allocation?currency="+query, nil))
+		if err == nil {
+			t.Fatalf("%s: expected error", query)
+		}
+		w := httptest.NewRecorder()
+		writeCurrencyError(w, err)
+		if w.Code != status {
+			t.Errorf("%s: expected status %d; got %d", query, status, w.Code)
+		}
+	}
+}
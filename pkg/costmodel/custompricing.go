@@ -0,0 +1,484 @@
+package costmodel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/opencost/opencost/pkg/cloud/models"
+	"github.com/opencost/opencost/pkg/config"
+	"github.com/opencost/opencost/pkg/env"
+	"github.com/opencost/opencost/pkg/log"
+	"github.com/opencost/opencost/pkg/util/json"
+)
+
+// maxPricingVersions is the number of versions of the custom pricing kept in
+// its history, beyond which the oldest are pruned.
+const maxPricingVersions = 100
+
+// monthlyPricingFields are the fields which the pricing-configs ConfigMap, and
+// so Provider.UpdateConfigFromConfigMap, takes as monthly prices, but which are
+// stored and served by the API as hourly prices.
+var monthlyPricingFields = map[string]bool{
+	"CPU":     true,
+	"SpotCPU": true,
+	"RAM":     true,
+	"SpotRAM": true,
+	"GPU":     true,
+	"Storage": true,
+}
+
+// managedPricingFields returns the custom pricing fields managed by the API,
+// in order: prices, discounts and the currency.
+func managedPricingFields() []string {
+	var fields []string
+	for f := range reloadablePriceFields {
+		fields = append(fields, f)
+	}
+	for f := range reloadableDiscountFields {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	return append(fields, "CurrencyCode")
+}
+
+// PricingChange is the change of one custom pricing field.
+type PricingChange struct {
+	Field    string `json:"field"`
+	Previous string `json:"previous"`
+	Current  string `json:"current"`
+}
+
+// PricingVersion is a version of the custom pricing, recorded each time it is
+// changed through the API, along with who changed what.
+type PricingVersion struct {
+	Version    int               `json:"version"`
+	Timestamp  time.Time         `json:"timestamp"`
+	Author     string            `json:"author"`
+	Comment    string            `json:"comment,omitempty"`
+	RollbackOf *int              `json:"rollbackOf,omitempty"`
+	Changes    []*PricingChange  `json:"changes"`
+	Pricing    map[string]string `json:"pricing"`
+}
+
+// PricingHistory manages the custom pricing of a provider, validating changes
+// and recording each as a new version, to which it can later be rolled back.
+// Each change is written to the pricing-configs ConfigMap, from which the
+// provider loads the custom pricing on start and on change, if a client is
+// given. The history is persisted to a config file, if one is given.
+type PricingHistory struct {
+	// TrustedProxies are the networks of the authenticating proxies whose
+	// user headers identify the authors of changes.
+	TrustedProxies []*net.IPNet
+
+	lock     sync.Mutex
+	provider models.Provider
+	client   kubernetes.Interface
+	file     *config.ConfigFile
+	versions []*PricingVersion
+}
+
+// NewPricingHistory creates a PricingHistory for the provider, loading the
+// versions persisted to the file.
+func NewPricingHistory(provider models.Provider, client kubernetes.Interface, file *config.ConfigFile) (*PricingHistory, error) {
+	ph := &PricingHistory{
+		provider: provider,
+		client:   client,
+		file:     file,
+	}
+
+	if file == nil {
+		return ph, nil
+	}
+
+	exists, err := file.Exists()
+	if err != nil {
+		return nil, fmt.Errorf("checking pricing history: %w", err)
+	}
+	if !exists {
+		return ph, nil
+	}
+
+	b, err := file.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading pricing history: %w", err)
+	}
+	if err := json.Unmarshal(b, &ph.versions); err != nil {
+		return nil, fmt.Errorf("decoding pricing history: %w", err)
+	}
+
+	return ph, nil
+}
+
+// Current returns the managed fields of the current custom pricing.
+func (ph *PricingHistory) Current() (map[string]string, error) {
+	cp, err := ph.provider.GetConfig()
+	if err != nil {
+		return nil, fmt.Errorf("reading custom pricing: %w", err)
+	}
+
+	pricing := make(map[string]string)
+	for _, field := range managedPricingFields() {
+		v, err := models.GetCustomPricingField(cp, field)
+		if err != nil {
+			return nil, err
+		}
+		pricing[field] = v
+	}
+	return pricing, nil
+}
+
+// Versions returns the recorded versions, newest first.
+func (ph *PricingHistory) Versions() []*PricingVersion {
+	ph.lock.Lock()
+	defer ph.lock.Unlock()
+
+	versions := make([]*PricingVersion, len(ph.versions))
+	for i, v := range ph.versions {
+		versions[len(versions)-1-i] = v
+	}
+	return versions
+}
+
+// Update validates and applies the changes to the custom pricing, keyed by
+// field, with prices given hourly, and records the result as a new version.
+func (ph *PricingHistory) Update(author, comment string, changes map[string]string) (*PricingVersion, error) {
+	ph.lock.Lock()
+	defer ph.lock.Unlock()
+
+	return ph.update(author, comment, nil, changes)
+}
+
+// Rollback restores the custom pricing of the given version, recording the
+// result as a new version.
+func (ph *PricingHistory) Rollback(author, comment string, version int) (*PricingVersion, error) {
+	ph.lock.Lock()
+	defer ph.lock.Unlock()
+
+	for _, v := range ph.versions {
+		if v.Version == version {
+			return ph.update(author, comment, &version, v.Pricing)
+		}
+	}
+	return nil, fmt.Errorf("%w: version %d", errPricingVersionNotFound, version)
+}
+
+var (
+	errPricingVersionNotFound = errors.New("pricing version not found")
+	errNoPricingChanges       = errors.New("no changes to custom pricing")
+)
+
+func (ph *PricingHistory) update(author, comment string, rollbackOf *int, changes map[string]string) (*PricingVersion, error) {
+	managed := make(map[string]bool)
+	for _, field := range managedPricingFields() {
+		managed[field] = true
+	}
+	for field, v := range changes {
+		if !managed[field] {
+			return nil, fmt.Errorf("%s: not a custom pricing field", field)
+		}
+		if err := validateCustomPricingField(field, v); err != nil {
+			return nil, fmt.Errorf("%s: %w", field, err)
+		}
+	}
+
+	previous, err := ph.Current()
+	if err != nil {
+		return nil, err
+	}
+
+	// The configuration preceding the first change through the API is recorded
+	// as version 0, such that it can be rolled back to.
+	if len(ph.versions) == 0 {
+		ph.versions = append(ph.versions, &PricingVersion{
+			Version:   0,
+			Timestamp: time.Now().UTC(),
+			Author:    "system",
+			Comment:   "custom pricing before the first change",
+			Changes:   []*PricingChange{},
+			Pricing:   previous,
+		})
+	}
+
+	update := make(map[string]string)
+	for field, v := range changes {
+		if previous[field] == v {
+			continue
+		}
+		if monthlyPricingFields[field] && v != "" {
+			f, _ := strconv.ParseFloat(v, 64)
+			v = strconv.FormatFloat(f*730, 'f', -1, 64)
+		}
+		update[field] = v
+	}
+	if len(update) == 0 {
+		return nil, errNoPricingChanges
+	}
+
+	// The ConfigMap is written first, so that a change which would be reverted
+	// by the next load of the ConfigMap is not applied
+	if err := ph.updateConfigMap(update); err != nil {
+		return nil, err
+	}
+	if _, err := ph.provider.UpdateConfigFromConfigMap(update); err != nil {
+		return nil, fmt.Errorf("updating custom pricing: %w", err)
+	}
+
+	current, err := ph.Current()
+	if err != nil {
+		return nil, err
+	}
+
+	version := &PricingVersion{
+		Version:    ph.versions[len(ph.versions)-1].Version + 1,
+		Timestamp:  time.Now().UTC(),
+		Author:     author,
+		Comment:    comment,
+		RollbackOf: rollbackOf,
+		Changes:    []*PricingChange{},
+		Pricing:    current,
+	}
+	for _, field := range managedPricingFields() {
+		if previous[field] != current[field] {
+			version.Changes = append(version.Changes, &PricingChange{
+				Field:    field,
+				Previous: previous[field],
+				Current:  current[field],
+			})
+		}
+	}
+
+	ph.versions = append(ph.versions, version)
+	if len(ph.versions) > maxPricingVersions {
+		ph.versions = ph.versions[len(ph.versions)-maxPricingVersions:]
+	}
+
+	for _, c := range version.Changes {
+		log.Infof("Pricing: version %d: %s changed %s from %q to %q", version.Version, author, c.Field, c.Previous, c.Current)
+	}
+
+	if err := ph.persist(); err != nil {
+		log.Errorf("Pricing: failed to persist history: %s", err)
+	}
+
+	return version, nil
+}
+
+// updateConfigMap sets the fields of the update in the pricing-configs
+// ConfigMap, creating it if it does not exist.
+func (ph *PricingHistory) updateConfigMap(update map[string]string) error {
+	if ph.client == nil {
+		return nil
+	}
+
+	ctx := context.Background()
+	configMaps := ph.client.CoreV1().ConfigMaps(env.GetKubecostNamespace())
+	name := env.GetPricingConfigmapName()
+
+	cm, err := configMaps.Get(ctx, name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		cm = &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Data:       update,
+		}
+		if _, err := configMaps.Create(ctx, cm, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("creating %s ConfigMap: %w", name, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("getting %s ConfigMap: %w", name, err)
+	}
+
+	if cm.Data == nil {
+		cm.Data = make(map[string]string)
+	}
+	for field, v := range update {
+		cm.Data[field] = v
+	}
+	if _, err := configMaps.Update(ctx, cm, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("updating %s ConfigMap: %w", name, err)
+	}
+	return nil
+}
+
+func (ph *PricingHistory) persist() error {
+	if ph.file == nil {
+		return nil
+	}
+
+	b, err := json.Marshal(ph.versions)
+	if err != nil {
+		return fmt.Errorf("encoding pricing history: %w", err)
+	}
+	return ph.file.Write(b)
+}
+
+// ParseTrustedProxies parses the networks of trusted proxies, given as CIDRs
+// or single addresses.
+func ParseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, p := range proxies {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if !strings.Contains(p, "/") {
+			ip := net.ParseIP(p)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid proxy address %q", p)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy network %q: %w", p, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// isTrustedProxy returns true if the client of the request is one of the
+// trusted proxies.
+func isTrustedProxy(r *http.Request, trustedProxies []*net.IPNet) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, network := range trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// requestAuthor identifies who made a request, by the user asserted by an
+// authenticating proxy, if the request came through one of the trusted
+// proxies, or the basic auth user, falling back to the address of the client.
+func requestAuthor(r *http.Request, trustedProxies []*net.IPNet) string {
+	if isTrustedProxy(r, trustedProxies) {
+		for _, h := range []string{"X-Forwarded-User", "X-Auth-Request-User", "X-Remote-User"} {
+			if user := r.Header.Get(h); user != "" {
+				return user
+			}
+		}
+	}
+	if user, _, ok := r.BasicAuth(); ok && user != "" {
+		return user
+	}
+	return r.RemoteAddr
+}
+
+// customPricingRequest is the body of a request to change the custom pricing.
+type customPricingRequest struct {
+	Pricing map[string]string `json:"pricing"`
+	Comment string            `json:"comment"`
+}
+
+func writePricingError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errPricingVersionNotFound) {
+		WriteError(w, Error{StatusCode: http.StatusNotFound, Body: err.Error()})
+		return
+	}
+	WriteError(w, BadRequest(err.Error()))
+}
+
+// GetCustomPricing responds with the current custom pricing, with prices given
+// hourly, and its latest version.
+func (a *Accesses) GetCustomPricing(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	w.Header().Set("Content-Type", "application/json")
+
+	pricing, err := a.PricingHistory.Current()
+	if err != nil {
+		WriteError(w, InternalServerError(err.Error()))
+		return
+	}
+
+	resp := struct {
+		Version *int              `json:"version"`
+		Pricing map[string]string `json:"pricing"`
+	}{Pricing: pricing}
+	if versions := a.PricingHistory.Versions(); len(versions) > 0 {
+		resp.Version = &versions[0].Version
+	}
+
+	w.Write(WrapData(resp, nil))
+}
+
+// UpdateCustomPricing validates and applies the changes to the custom pricing
+// in the body of the request, responding with the resulting version.
+func (a *Accesses) UpdateCustomPricing(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	w.Header().Set("Content-Type", "application/json")
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		WriteError(w, BadRequest(fmt.Sprintf("reading body: %s", err)))
+		return
+	}
+	req := &customPricingRequest{}
+	if err := json.Unmarshal(b, req); err != nil {
+		WriteError(w, BadRequest(fmt.Sprintf("decoding body: %s", err)))
+		return
+	}
+
+	version, err := a.PricingHistory.Update(requestAuthor(r, a.PricingHistory.TrustedProxies), req.Comment, req.Pricing)
+	if err != nil {
+		writePricingError(w, err)
+		return
+	}
+
+	w.Write(WrapData(version, nil))
+}
+
+// GetCustomPricingVersions responds with the versions of the custom pricing,
+// newest first.
+func (a *Accesses) GetCustomPricingVersions(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	w.Header().Set("Content-Type", "application/json")
+
+	w.Write(WrapData(a.PricingHistory.Versions(), nil))
+}
+
+// RollbackCustomPricing restores the custom pricing of the version given by
+// the path, responding with the resulting version.
+func (a *Accesses) RollbackCustomPricing(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	w.Header().Set("Content-Type", "application/json")
+
+	version, err := strconv.Atoi(ps.ByName("version"))
+	if err != nil {
+		WriteError(w, BadRequest(fmt.Sprintf("invalid version: %s", ps.ByName("version"))))
+		return
+	}
+
+	v, err := a.PricingHistory.Rollback(requestAuthor(r, a.PricingHistory.TrustedProxies), r.URL.Query().Get("comment"), version)
+	if err != nil {
+		writePricingError(w, err)
+		return
+	}
+
+	w.Write(WrapData(v, nil))
+}
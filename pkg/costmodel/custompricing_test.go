@@ -0,0 +1,175 @@
+package costmodel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/opencost/opencost/pkg/cloud/models"
+	"github.com/opencost/opencost/pkg/config"
+	"github.com/opencost/opencost/pkg/env"
+	"github.com/opencost/opencost/pkg/storage"
+)
+
+// configMapProvider updates its custom pricing the way providers do from the
+// pricing-configs ConfigMap, converting monthly prices to hourly.
+type configMapProvider struct {
+	models.Provider
+	pricing *models.CustomPricing
+}
+
+func (cmp *configMapProvider) GetConfig() (*models.CustomPricing, error) {
+	return cmp.pricing, nil
+}
+
+func (cmp *configMapProvider) UpdateConfigFromConfigMap(a map[string]string) (*models.CustomPricing, error) {
+	for k, v := range a {
+		if monthlyPricingFields[k] {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, err
+			}
+			v = fmt.Sprintf("%f", f/730)
+		}
+		if err := models.SetCustomPricingField(cmp.pricing, k, v); err != nil {
+			return nil, err
+		}
+	}
+	return cmp.pricing, nil
+}
+
+func TestPricingHistory(t *testing.T) {
+	file := config.NewConfigFile(storage.NewFileStorage(t.TempDir()), "pricing-history.json")
+	provider := &configMapProvider{pricing: &models.CustomPricing{CPU: "0.031611", RAM: "0.004237", Discount: "10%"}}
+
+	ph, err := NewPricingHistory(provider, nil, file)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// invalid changes are rejected, and change nothing
+	for _, changes := range []map[string]string{
+		{"CPU": "-1"},
+		{"Discount": "120%"},
+		{"ServiceKeySecret": "secret"},
+		{"CurrencyCode": "XYZ"},
+	} {
+		if _, err := ph.Update("alice", "", changes); err == nil {
+			t.Errorf("expected error updating %v", changes)
+		}
+	}
+	if provider.pricing.CPU != "0.031611" || len(ph.Versions()) != 0 {
+		t.Fatalf("expected rejected changes not to apply")
+	}
+
+	v1, err := ph.Update("alice", "negotiated rates", map[string]string{"CPU": "0.025", "Discount": "15%"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if v1.Version != 1 || v1.Author != "alice" || len(v1.Changes) != 2 {
+		t.Fatalf("expected version 1 by alice with 2 changes; got %+v", v1)
+	}
+	if provider.pricing.CPU != "0.025000" || provider.pricing.Discount != "15%" {
+		t.Errorf("expected hourly CPU price 0.025000 and discount 15%%; got %s and %s", provider.pricing.CPU, provider.pricing.Discount)
+	}
+
+	if _, err := ph.Update("alice", "", map[string]string{"Discount": "15%"}); !errors.Is(err, errNoPricingChanges) {
+		t.Errorf("expected no changes; got %v", err)
+	}
+
+	// the history survives a restart
+	ph, err = NewPricingHistory(provider, nil, file)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	v2, err := ph.Rollback("bob", "", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if v2.Version != 2 || v2.RollbackOf == nil || *v2.RollbackOf != 0 {
+		t.Fatalf("expected version 2 rolling back version 0; got %+v", v2)
+	}
+	if provider.pricing.CPU != "0.031611" || provider.pricing.Discount != "10%" {
+		t.Errorf("expected original pricing; got CPU %s and discount %s", provider.pricing.CPU, provider.pricing.Discount)
+	}
+
+	versions := ph.Versions()
+	if len(versions) != 3 || versions[0].Version != 2 || versions[2].Version != 0 {
+		t.Errorf("expected versions 2, 1, 0; got %d versions", len(versions))
+	}
+
+	if _, err := ph.Rollback("bob", "", 7); !errors.Is(err, errPricingVersionNotFound) {
+		t.Errorf("expected version not found; got %v", err)
+	}
+}
+
+func TestPricingHistory_ConfigMap(t *testing.T) {
+	namespace, name := env.GetKubecostNamespace(), env.GetPricingConfigmapName()
+	client := fake.NewSimpleClientset(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Data:       map[string]string{"RAM": "3.093", "spotLabel": "lifecycle"},
+	})
+	provider := &configMapProvider{pricing: &models.CustomPricing{CPU: "0.031611", RAM: "0.004237"}}
+
+	ph, err := NewPricingHistory(provider, client, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := ph.Update("alice", "", map[string]string{"CPU": "0.5", "CurrencyCode": "eur"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// changes are written to the ConfigMap as the provider loads them from
+	// it, keeping the fields not changed
+	cm, err := client.CoreV1().ConfigMaps(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := map[string]string{"CPU": "365", "CurrencyCode": "eur", "RAM": "3.093", "spotLabel": "lifecycle"}
+	for k, v := range expected {
+		if cm.Data[k] != v {
+			t.Errorf("expected %s=%s in the ConfigMap; got %q", k, v, cm.Data[k])
+		}
+	}
+}
+
+func TestRequestAuthor(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := ParseTrustedProxies([]string{"proxy"}); err == nil {
+		t.Errorf("expected error parsing an invalid proxy")
+	}
+
+	request := func(remoteAddr string) *http.Request {
+		r := httptest.NewRequest("POST", "/customPricing", nil)
+		r.RemoteAddr = remoteAddr
+		r.Header.Set("X-Forwarded-User", "alice")
+		return r
+	}
+
+	for remoteAddr, expected := range map[string]string{
+		"10.1.2.3:51234":    "alice",
+		"192.168.1.1:51234": "alice",
+		// the user headers of clients other than the proxies are ignored
+		"192.168.1.2:51234": "192.168.1.2:51234",
+	} {
+		if author := requestAuthor(request(remoteAddr), proxies); author != expected {
+			t.Errorf("%s: expected author %s; got %s", remoteAddr, expected, author)
+		}
+	}
+
+	if author := requestAuthor(request("10.1.2.3:51234"), nil); author != "10.1.2.3:51234" {
+		t.Errorf("expected user headers to be ignored without trusted proxies; got %s", author)
+	}
+}
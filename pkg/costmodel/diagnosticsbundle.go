@@ -0,0 +1,236 @@
+package costmodel
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"runtime"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/opencost/opencost/pkg/clustercache"
+	"github.com/opencost/opencost/pkg/env"
+	"github.com/opencost/opencost/pkg/log"
+	"github.com/opencost/opencost/pkg/prom"
+	"github.com/opencost/opencost/pkg/util/json"
+	"github.com/opencost/opencost/pkg/version"
+)
+
+const (
+	// defaultBundleCPUProfile is the duration of the CPU profile included in a
+	// diagnostics bundle unless the seconds parameter is given.
+	defaultBundleCPUProfile = 10 * time.Second
+
+	// maxBundleCPUProfile bounds the duration of the requested CPU profile.
+	maxBundleCPUProfile = 60 * time.Second
+
+	redactedValue = "<redacted>"
+)
+
+// sensitiveEnvKeys are the substrings of the names of environment variables
+// whose values are redacted from diagnostics bundles.
+var sensitiveEnvKeys = []string{"PASSWORD", "SECRET", "TOKEN", "KEY", "CREDENTIAL", "AUTH", "DSN", "HEADER"}
+
+// passwordPairPattern matches the passwords of values of key=value pairs, as in
+// a connection string, e.g. "host=db password=hunter2".
+var passwordPairPattern = regexp.MustCompile(`(?i)\b(password|pwd)=('[^']*'|[^\s;]*)`)
+
+// bundleProfiles are the runtime profiles included in a diagnostics bundle, by
+// name, with the debug level at which they are written.
+var bundleProfiles = []struct {
+	name  string
+	debug int
+	file  string
+}{
+	{name: "goroutine", debug: 2, file: "goroutine.txt"},
+	{name: "heap", debug: 0, file: "heap.pb.gz"},
+	{name: "allocs", debug: 0, file: "allocs.pb.gz"},
+	{name: "block", debug: 0, file: "block.pb.gz"},
+	{name: "mutex", debug: 0, file: "mutex.pb.gz"},
+	{name: "threadcreate", debug: 0, file: "threadcreate.pb.gz"},
+}
+
+// BundleVersionInfo identifies the build and runtime of the process which
+// produced a diagnostics bundle.
+type BundleVersionInfo struct {
+	Version      string    `json:"version"`
+	GitCommit    string    `json:"gitCommit"`
+	GoVersion    string    `json:"goVersion"`
+	Platform     string    `json:"platform"`
+	ClusterID    string    `json:"clusterId"`
+	NumGoroutine int       `json:"numGoroutine"`
+	NumCPU       int       `json:"numCPU"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// GetDiagnosticsBundle responds with a gzipped tar archive of the version, redacted
+// environment, recent slow queries, pricing freshness, cluster cache stats and runtime
+// profiles of the process, to be attached to bug reports. The seconds parameter sets the
+// duration of the CPU profile; zero omits it.
+func (a *Accesses) GetDiagnosticsBundle(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	cpuProfile := defaultBundleCPUProfile
+	if s := r.URL.Query().Get("seconds"); s != "" {
+		seconds, err := strconv.Atoi(s)
+		if err != nil || seconds < 0 {
+			http.Error(w, fmt.Sprintf("invalid seconds %q", s), http.StatusBadRequest)
+			return
+		}
+		cpuProfile = time.Duration(seconds) * time.Second
+		if cpuProfile > maxBundleCPUProfile {
+			cpuProfile = maxBundleCPUProfile
+		}
+	}
+
+	// build the archive before responding, so that a failure can be reported
+	// with an error status rather than a truncated archive
+	var buf bytes.Buffer
+	if err := a.writeDiagnosticsBundle(&buf, cpuProfile); err != nil {
+		log.Errorf("Error creating diagnostics bundle: %s", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	name := fmt.Sprintf("opencost-diagnostics-%s.tar.gz", time.Now().UTC().Format("20060102T150405Z"))
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.Write(buf.Bytes())
+}
+
+func (a *Accesses) writeDiagnosticsBundle(buf *bytes.Buffer, cpuProfile time.Duration) error {
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+	now := time.Now().UTC()
+
+	add := func(name string, data []byte) error {
+		err := tw.WriteHeader(&tar.Header{
+			Name:    "opencost-diagnostics/" + name,
+			Mode:    0644,
+			Size:    int64(len(data)),
+			ModTime: now,
+		})
+		if err != nil {
+			return fmt.Errorf("writing %s: %w", name, err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			return fmt.Errorf("writing %s: %w", name, err)
+		}
+		return nil
+	}
+	addJSON := func(name string, v interface{}) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("encoding %s: %w", name, err)
+		}
+		return add(name, data)
+	}
+
+	err := addJSON("version.json", &BundleVersionInfo{
+		Version:      version.Version,
+		GitCommit:    version.GitCommit,
+		GoVersion:    runtime.Version(),
+		Platform:     runtime.GOOS + "/" + runtime.GOARCH,
+		ClusterID:    env.GetClusterID(),
+		NumGoroutine: runtime.NumGoroutine(),
+		NumCPU:       runtime.NumCPU(),
+		CreatedAt:    now,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := addJSON("env.json", redactEnv(os.Environ())); err != nil {
+		return err
+	}
+
+	if err := addJSON("slow-queries.json", prom.RecentSlowQueries()); err != nil {
+		return err
+	}
+
+	pricing := map[string]interface{}{
+		"refresh": a.PricingRefreshStatus(),
+	}
+	if a.CloudProvider != nil {
+		pricing["sources"] = a.CloudProvider.PricingSourceStatus()
+	}
+	if err := addJSON("pricing.json", pricing); err != nil {
+		return err
+	}
+
+	if err := addJSON("cluster-cache.json", clustercache.Stats()); err != nil {
+		return err
+	}
+
+	for _, p := range bundleProfiles {
+		profile := pprof.Lookup(p.name)
+		if profile == nil {
+			continue
+		}
+		var pb bytes.Buffer
+		if err := profile.WriteTo(&pb, p.debug); err != nil {
+			return fmt.Errorf("writing %s profile: %w", p.name, err)
+		}
+		if err := add("pprof/"+p.file, pb.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	if cpuProfile > 0 {
+		var pb bytes.Buffer
+		if err := pprof.StartCPUProfile(&pb); err != nil {
+			// another CPU profile is running, e.g. from a concurrent request
+			log.Warnf("Diagnostics bundle: omitting CPU profile: %s", err)
+		} else {
+			time.Sleep(cpuProfile)
+			pprof.StopCPUProfile()
+			if err := add("pprof/cpu.pb.gz", pb.Bytes()); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("closing archive: %w", err)
+	}
+	return gw.Close()
+}
+
+// redactEnv returns the environment as a map of names to values, replacing the
+// values of sensitive variables, and the passwords of URLs and of connection
+// strings, with a placeholder.
+func redactEnv(environ []string) map[string]string {
+	redacted := make(map[string]string, len(environ))
+	for _, kv := range environ {
+		k, v, _ := strings.Cut(kv, "=")
+		redacted[k] = redactEnvValue(k, v)
+	}
+	return redacted
+}
+
+func redactEnvValue(key, value string) string {
+	if value == "" {
+		return value
+	}
+
+	upper := strings.ToUpper(key)
+	for _, s := range sensitiveEnvKeys {
+		if strings.Contains(upper, s) {
+			return redactedValue
+		}
+	}
+
+	if u, err := url.Parse(value); err == nil && u.User != nil {
+		return u.Redacted()
+	}
+
+	return passwordPairPattern.ReplaceAllString(value, "${1}="+redactedValue)
+}
@@ -0,0 +1,269 @@
+package costmodel
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/opencost/opencost/pkg/env"
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/log"
+	"github.com/opencost/opencost/pkg/prom"
+	"github.com/opencost/opencost/pkg/util/httputil"
+	"github.com/opencost/opencost/pkg/util/timeutil"
+)
+
+const queryFmtNodeInterruptions = `avg(kubecost_node_interruption) by (node, reason, %s)[%s:%s]`
+
+// NodeDisruption is the period over which a node showed signs of being
+// preempted or interrupted, until it stopped running.
+type NodeDisruption struct {
+	Cluster string    `json:"cluster"`
+	Node    string    `json:"node"`
+	Reason  string    `json:"reason"`
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+}
+
+// DisruptionEvent is the cost to a workload of one of its pods running on a
+// disrupted node while its replacement was already running elsewhere.
+type DisruptionEvent struct {
+	Node             string    `json:"node"`
+	Reason           string    `json:"reason"`
+	Pod              string    `json:"pod"`
+	Container        string    `json:"container"`
+	Start            time.Time `json:"start"`
+	End              time.Time `json:"end"`
+	DuplicateMinutes float64   `json:"duplicateMinutes"`
+	Cost             float64   `json:"cost"`
+}
+
+// WorkloadDisruption is the disruption cost of a workload: the cost of the
+// resource-hours it paid for twice while rescheduling off of preempted or
+// interrupted nodes.
+type WorkloadDisruption struct {
+	Cluster          string             `json:"cluster"`
+	Namespace        string             `json:"namespace"`
+	ControllerKind   string             `json:"controllerKind"`
+	Controller       string             `json:"controller"`
+	DuplicateMinutes float64            `json:"duplicateMinutes"`
+	DisruptionCost   float64            `json:"disruptionCost"`
+	Events           []*DisruptionEvent `json:"events"`
+}
+
+// ComputeWorkloadDisruptions computes the disruption cost of each workload
+// which was rescheduled off of a preempted or interrupted node in the window.
+func (cm *CostModel) ComputeWorkloadDisruptions(start, end time.Time, resolution time.Duration) ([]*WorkloadDisruption, error) {
+	disruptions, err := cm.queryNodeDisruptions(start, end, resolution)
+	if err != nil {
+		return nil, err
+	}
+
+	// Without disruptions, there is no need to compute allocations
+	if len(disruptions) == 0 {
+		return []*WorkloadDisruption{}, nil
+	}
+
+	as, err := cm.ComputeAllocation(start, end, resolution)
+	if err != nil {
+		return nil, fmt.Errorf("computing allocations: %w", err)
+	}
+
+	return computeWorkloadDisruptions(as, disruptions), nil
+}
+
+// queryNodeDisruptions returns the periods over which nodes were preempted or
+// interrupted in the window, per kubecost_node_interruption.
+func (cm *CostModel) queryNodeDisruptions(start, end time.Time, resolution time.Duration) ([]*NodeDisruption, error) {
+	durStr := timeutil.DurationString(end.Sub(start))
+	if durStr == "" {
+		return nil, fmt.Errorf("illegal duration value for %s", kubecost.NewClosedWindow(start, end))
+	}
+	resStr := timeutil.DurationString(resolution)
+
+	ctx := prom.NewNamedContext(cm.PrometheusClient, prom.AllocationContextName)
+	query := fmt.Sprintf(queryFmtNodeInterruptions, env.GetPromClusterLabel(), durStr, resStr)
+	res, err := ctx.QueryAtTime(query, end).Await()
+	if err != nil {
+		return nil, fmt.Errorf("querying node interruptions: %w", err)
+	}
+
+	var disruptions []*NodeDisruption
+	for _, r := range res {
+		key, err := resultNodeKey(r, env.GetPromClusterLabel(), "node")
+		if err != nil || len(r.Values) == 0 {
+			continue
+		}
+		reason, _ := r.GetString("reason")
+
+		s, e := calculateStartAndEnd(r, resolution)
+		disruptions = append(disruptions, &NodeDisruption{
+			Cluster: key.Cluster,
+			Node:    key.Node,
+			Reason:  reason,
+			Start:   s,
+			End:     e,
+		})
+	}
+
+	return disruptions, nil
+}
+
+// computeWorkloadDisruptions attributes disruption cost to workloads. For each
+// allocation running on a node while it was disrupted, the time it overlaps with
+// replacement pods of the same workload, scheduled elsewhere after the
+// disruption began, is duplicated, and costs the allocation's rate over that
+// time. Pods without a controller are not rescheduled, so are excluded.
+func computeWorkloadDisruptions(as *kubecost.AllocationSet, disruptions []*NodeDisruption) []*WorkloadDisruption {
+	disruptionsByNode := map[nodeKey][]*NodeDisruption{}
+	for _, d := range disruptions {
+		key := newNodeKey(d.Cluster, d.Node)
+		disruptionsByNode[key] = append(disruptionsByNode[key], d)
+	}
+
+	allocsByWorkload := map[controllerKey][]*kubecost.Allocation{}
+	for _, alloc := range as.Allocations {
+		if alloc.Properties == nil || alloc.Properties.Controller == "" {
+			continue
+		}
+		props := alloc.Properties
+		key := newControllerKey(props.Cluster, props.Namespace, props.ControllerKind, props.Controller)
+		allocsByWorkload[key] = append(allocsByWorkload[key], alloc)
+	}
+
+	var workloads []*WorkloadDisruption
+	for key, allocs := range allocsByWorkload {
+		wd := &WorkloadDisruption{
+			Cluster:        key.Cluster,
+			Namespace:      key.Namespace,
+			ControllerKind: key.ControllerKind,
+			Controller:     key.Controller,
+		}
+
+		for _, alloc := range allocs {
+			minutes := alloc.Minutes()
+			if minutes <= 0 {
+				continue
+			}
+
+			for _, d := range disruptionsByNode[newNodeKey(alloc.Properties.Cluster, alloc.Properties.Node)] {
+				s, e := alloc.Start, alloc.End
+				if d.Start.After(s) {
+					s = d.Start
+				}
+				if d.End.Before(e) {
+					e = d.End
+				}
+				if !s.Before(e) {
+					continue
+				}
+
+				var replacements []disruptionInterval
+				for _, other := range allocs {
+					if other.Properties.Pod == alloc.Properties.Pod || other.Properties.Node == d.Node || other.Start.Before(d.Start) {
+						continue
+					}
+					replacements = append(replacements, disruptionInterval{start: other.Start, end: other.End})
+				}
+
+				duplicateMinutes := overlapMinutes(s, e, replacements)
+				if duplicateMinutes <= 0 {
+					continue
+				}
+
+				cost := alloc.TotalCost() * duplicateMinutes / minutes
+				wd.DuplicateMinutes += duplicateMinutes
+				wd.DisruptionCost += cost
+				wd.Events = append(wd.Events, &DisruptionEvent{
+					Node:             d.Node,
+					Reason:           d.Reason,
+					Pod:              alloc.Properties.Pod,
+					Container:        alloc.Properties.Container,
+					Start:            s,
+					End:              e,
+					DuplicateMinutes: duplicateMinutes,
+					Cost:             cost,
+				})
+			}
+		}
+
+		if len(wd.Events) > 0 {
+			sort.Slice(wd.Events, func(i, j int) bool {
+				return wd.Events[i].Start.Before(wd.Events[j].Start)
+			})
+			workloads = append(workloads, wd)
+		}
+	}
+
+	sort.Slice(workloads, func(i, j int) bool {
+		if workloads[i].DisruptionCost != workloads[j].DisruptionCost {
+			return workloads[i].DisruptionCost > workloads[j].DisruptionCost
+		}
+		return fmt.Sprintf("%s/%s/%s/%s", workloads[i].Cluster, workloads[i].Namespace, workloads[i].ControllerKind, workloads[i].Controller) <
+			fmt.Sprintf("%s/%s/%s/%s", workloads[j].Cluster, workloads[j].Namespace, workloads[j].ControllerKind, workloads[j].Controller)
+	})
+
+	if workloads == nil {
+		workloads = []*WorkloadDisruption{}
+	}
+	return workloads
+}
+
+type disruptionInterval struct {
+	start time.Time
+	end   time.Time
+}
+
+// overlapMinutes returns the minutes of [start, end) covered by any of the
+// intervals, counting time covered by more than one interval once.
+func overlapMinutes(start, end time.Time, intervals []disruptionInterval) float64 {
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].start.Before(intervals[j].start)
+	})
+
+	var covered time.Duration
+	cursor := start
+	for _, iv := range intervals {
+		s, e := iv.start, iv.end
+		if s.Before(cursor) {
+			s = cursor
+		}
+		if e.After(end) {
+			e = end
+		}
+		if !s.Before(e) {
+			continue
+		}
+		covered += e.Sub(s)
+		cursor = e
+	}
+
+	return covered.Minutes()
+}
+
+// ComputeAllocationDisruptionHandler responds with the disruption cost of each
+// workload rescheduled off of a preempted or interrupted node in the window.
+func (a *Accesses) ComputeAllocationDisruptionHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	w.Header().Set("Content-Type", "application/json")
+
+	qp := httputil.NewQueryParams(r.URL.Query())
+
+	window, err := kubecost.ParseWindowWithOffset(qp.Get("window", ""), env.GetParsedUTCOffset())
+	if err != nil || window.IsOpen() {
+		http.Error(w, fmt.Sprintf("Invalid 'window' parameter: %s", qp.Get("window", "")), http.StatusBadRequest)
+		return
+	}
+	resolution := qp.GetDuration("resolution", env.GetETLResolution())
+
+	workloads, err := a.Model.ComputeWorkloadDisruptions(*window.Start(), *window.End(), resolution)
+	if err != nil {
+		log.Errorf("Error computing disruption costs: %s", err)
+		WriteError(w, InternalServerError(err.Error()))
+		return
+	}
+
+	w.Write(WrapData(workloads, nil))
+}
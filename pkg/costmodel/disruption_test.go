@@ -0,0 +1,82 @@
+package costmodel
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+)
+
+func TestComputeWorkloadDisruptions(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(10 * time.Hour)
+
+	alloc := func(pod, node, controller string, s, e time.Time, cost float64) *kubecost.Allocation {
+		return &kubecost.Allocation{
+			Name: pod,
+			Properties: &kubecost.AllocationProperties{
+				Cluster:        "cluster1",
+				Node:           node,
+				Namespace:      "namespace1",
+				ControllerKind: "deployment",
+				Controller:     controller,
+				Pod:            pod,
+				Container:      "container1",
+			},
+			Window:  kubecost.NewWindow(&start, &end),
+			Start:   s,
+			End:     e,
+			CPUCost: cost,
+		}
+	}
+
+	as := kubecost.NewAllocationSet(start, end,
+		// interrupted at 04:00, replaced at 04:30, gone at 05:00: 30m duplicated
+		alloc("web-1", "spot-node", "web", start, start.Add(5*time.Hour), 5),
+		alloc("web-2", "node2", "web", start.Add(270*time.Minute), end, 5.5),
+		// interrupted, but never replaced, so nothing is duplicated
+		alloc("batch-1", "spot-node", "batch", start, start.Add(5*time.Hour), 5),
+		// replicas running elsewhere since before the disruption are not replacements
+		alloc("api-1", "spot-node", "api", start, start.Add(5*time.Hour), 5),
+		alloc("api-2", "node2", "api", start, end, 10),
+		// bare pods are not rescheduled
+		alloc("bare", "spot-node", "", start, start.Add(5*time.Hour), 5),
+	)
+
+	disruptions := []*NodeDisruption{
+		{Cluster: "cluster1", Node: "spot-node", Reason: "SpotInterruption", Start: start.Add(4 * time.Hour), End: start.Add(5 * time.Hour)},
+	}
+
+	workloads := computeWorkloadDisruptions(as, disruptions)
+	if len(workloads) != 1 {
+		t.Fatalf("expected 1 disrupted workload; got %d", len(workloads))
+	}
+
+	wd := workloads[0]
+	if wd.Controller != "web" || len(wd.Events) != 1 {
+		t.Fatalf("expected one disruption of web; got %+v", wd)
+	}
+	if wd.DuplicateMinutes != 30 {
+		t.Errorf("expected 30 duplicate minutes; got %f", wd.DuplicateMinutes)
+	}
+	// $5 over 300 minutes, for 30 minutes
+	if math.Abs(wd.DisruptionCost-0.5) > 1e-9 {
+		t.Errorf("expected disruption cost 0.5; got %f", wd.DisruptionCost)
+	}
+}
+
+func TestOverlapMinutes(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(m int) time.Time { return start.Add(time.Duration(m) * time.Minute) }
+
+	// overlapping intervals are counted once, and clamped to [10, 60)
+	intervals := []disruptionInterval{
+		{start: at(40), end: at(90)},
+		{start: at(0), end: at(20)},
+		{start: at(15), end: at(30)},
+	}
+	if m := overlapMinutes(at(10), at(60), intervals); m != 40 {
+		t.Errorf("expected 40 minutes; got %f", m)
+	}
+}
@@ -0,0 +1,65 @@
+package costmodel
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/opencost/opencost/pkg/cloud/models"
+	"github.com/opencost/opencost/pkg/prom"
+
+	promclient "github.com/prometheus/client_golang/api"
+	v1 "k8s.io/api/core/v1"
+)
+
+// gpuModelLabels are the node labels which identify the GPU model, in order
+// of preference, for nodes whose pricing does not include it.
+var gpuModelLabels = []string{
+	"nvidia.com/gpu.product",           // NVIDIA GPU feature discovery
+	"cloud.google.com/gke-accelerator", // GKE
+	"k8s.amazonaws.com/accelerator",    // EKS
+	"accelerator",                      // AKS
+}
+
+// gpuModel returns the model of the node's GPUs from its pricing or, failing
+// that, its labels. It returns an empty string if the model is unknown.
+func gpuModel(node *models.Node, kubeNode *v1.Node) string {
+	if node.GPUName != "" {
+		return node.GPUName
+	}
+	if kubeNode == nil {
+		return ""
+	}
+	for _, label := range gpuModelLabels {
+		if model, ok := kubeNode.Labels[label]; ok && model != "" {
+			return model
+		}
+	}
+	return ""
+}
+
+// queryGPUUsage returns the number of GPUs used by each container, keyed by
+// namespace, pod and container, from the utilization reported by the NVIDIA
+// DCGM exporter. It returns an empty map if the exporter is not scraped.
+func queryGPUUsage(client promclient.Client) (map[string]float64, error) {
+	query := `sum(avg_over_time(DCGM_FI_DEV_GPU_UTIL{pod!="", container!=""}[5m])) by (namespace, pod, container) / 100`
+
+	ctx := prom.NewNamedContext(client, prom.ComputeCostDataContextName)
+	res, _, err := ctx.QuerySync(query)
+	if err != nil {
+		return nil, fmt.Errorf("querying GPU usage: %w", err)
+	}
+
+	usage := make(map[string]float64, len(res))
+	for _, r := range res {
+		labels, err := r.GetStrings("namespace", "pod", "container")
+		if err != nil || len(r.Values) == 0 {
+			continue
+		}
+		usage[gpuUsageKey(labels["namespace"], labels["pod"], labels["container"])] = r.Values[0].Value
+	}
+	return usage, nil
+}
+
+func gpuUsageKey(namespace, pod, container string) string {
+	return strings.Join([]string{namespace, pod, container}, "/")
+}
@@ -0,0 +1,39 @@
+package costmodel
+
+import (
+	"testing"
+
+	"github.com/opencost/opencost/pkg/cloud/models"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGPUModel(t *testing.T) {
+	kubeNode := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				"cloud.google.com/gke-accelerator": "nvidia-tesla-t4",
+				"nvidia.com/gpu.product":           "Tesla-T4",
+			},
+		},
+	}
+
+	cases := map[string]struct {
+		node     *models.Node
+		kubeNode *v1.Node
+		expected string
+	}{
+		"pricing":      {&models.Node{GPUName: "nvidia-tesla-a100"}, kubeNode, "nvidia-tesla-a100"},
+		"label":        {&models.Node{}, kubeNode, "Tesla-T4"},
+		"unknown":      {&models.Node{}, &v1.Node{}, ""},
+		"missing node": {&models.Node{}, nil, ""},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if actual := gpuModel(tc.node, tc.kubeNode); actual != tc.expected {
+				t.Errorf("expected %q; got %q", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/julienschmidt/httprouter"
 	"github.com/opencost/opencost/pkg/env"
 	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/log"
 	"github.com/opencost/opencost/pkg/util/httputil"
 )
 
@@ -16,6 +17,23 @@ func (a *Accesses) ComputeAssetsHandler(w http.ResponseWriter, r *http.Request,
 
 	qp := httputil.NewQueryParams(r.URL.Query())
 
+	// Format is an optional parameter, which may also be requested via the
+	// Accept header, selecting json (default), csv, or parquet output.
+	format, err := ParseResponseFormat(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid 'format' parameter: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	var columns []TabularColumn[AssetRow]
+	if format != ResponseFormatJSON {
+		columns, err = SelectAssetColumns(tabularColumnNames(qp, env.GetAssetsTabularColumns()))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid 'columns' parameter: %s", err), http.StatusBadRequest)
+			return
+		}
+	}
+
 	// Window is a required field describing the window of time over which to
 	// compute allocation data.
 	window, err := kubecost.ParseWindowWithOffset(qp.Get("window", ""), env.GetParsedUTCOffset())
@@ -24,11 +42,32 @@ func (a *Accesses) ComputeAssetsHandler(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
-	assetSet, err := a.Model.ComputeAssets(*window.Start(), *window.End())
+	// Currency is an optional parameter, defaulting to the configured display
+	// currency, giving the currency to which costs are converted.
+	conversion, err := a.responseCurrency(r)
+	if err != nil {
+		writeCurrencyError(w, err)
+		return
+	}
+
+	assetSet, err := a.Model.QueryAssetSet(*window.Start(), *window.End())
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Error computing asset set: %s", err), http.StatusInternalServerError)
 		return
 	}
 
-	w.Write(WrapData(assetSet, nil))
+	if !conversion.IsIdentity() {
+		assetSet.ConvertCurrency(conversion.Rate)
+	}
+
+	if format != ResponseFormatJSON {
+		codec, err := ParseParquetCompression(env.GetParquetCompression())
+		if err != nil {
+			log.Warnf("Invalid %s, using default: %s", env.ParquetCompressionEnvVar, err)
+		}
+		writeTabularResponse(w, format, "assets", columns, AssetRowsFor(assetSet), codec)
+		return
+	}
+
+	w.Write(WrapDataWithCurrency(assetSet, conversion))
 }
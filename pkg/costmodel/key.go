@@ -237,6 +237,12 @@ func resultJobKey(res *prom.QueryResult, clusterLabel, namespaceLabel, controlle
 	return resultControllerKey("job", res, clusterLabel, namespaceLabel, controllerLabel)
 }
 
+// resultCronJobKey creates a controllerKey for a CronJob.
+// (See resultControllerKey for more.)
+func resultCronJobKey(res *prom.QueryResult, clusterLabel, namespaceLabel, controllerLabel string) (controllerKey, error) {
+	return resultControllerKey("cronjob", res, clusterLabel, namespaceLabel, controllerLabel)
+}
+
 // resultReplicaSetKey creates a controllerKey for a Job.
 // (See resultControllerKey for more.)
 func resultReplicaSetKey(res *prom.QueryResult, clusterLabel, namespaceLabel, controllerLabel string) (controllerKey, error) {
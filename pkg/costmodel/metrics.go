@@ -2,6 +2,7 @@ package costmodel
 
 import (
 	"math"
+	"net"
 	"strconv"
 	"strings"
 	"sync"
@@ -121,18 +122,25 @@ var (
 	ramGv                      *prometheus.GaugeVec
 	gpuGv                      *prometheus.GaugeVec
 	gpuCountGv                 *prometheus.GaugeVec
+	gpuModelGv                 *prometheus.GaugeVec
 	pvGv                       *prometheus.GaugeVec
 	spotGv                     *prometheus.GaugeVec
 	totalGv                    *prometheus.GaugeVec
 	ramAllocGv                 *prometheus.GaugeVec
 	cpuAllocGv                 *prometheus.GaugeVec
 	gpuAllocGv                 *prometheus.GaugeVec
+	gpuRequestCostGv           *prometheus.GaugeVec
+	gpuUsageCostGv             *prometheus.GaugeVec
 	pvAllocGv                  *prometheus.GaugeVec
+	podNetworkEgressCostGv     *prometheus.GaugeVec
 	networkZoneEgressCostG     prometheus.Gauge
 	networkRegionEgressCostG   prometheus.Gauge
 	networkInternetEgressCostG prometheus.Gauge
 	clusterManagementCostGv    *prometheus.GaugeVec
 	lbCostGv                   *prometheus.GaugeVec
+	publicIPCostGv             *prometheus.GaugeVec
+	natGatewayCostGv           *prometheus.GaugeVec
+	natGatewayDataCostGv       *prometheus.GaugeVec
 )
 
 // initCostModelMetrics uses a sync.Once to ensure that these metrics are only created once
@@ -176,6 +184,14 @@ func initCostModelMetrics(clusterCache clustercache.ClusterCache, provider model
 			toRegisterGV = append(toRegisterGV, gpuCountGv)
 		}
 
+		gpuModelGv = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "node_gpu_model_hourly_cost",
+			Help: "node_gpu_model_hourly_cost hourly cost for each gpu on this node by gpu model",
+		}, []string{"instance", "node", "instance_type", "region", "provider_id", "gpu_model"})
+		if _, disabled := disabledMetrics["node_gpu_model_hourly_cost"]; !disabled {
+			toRegisterGV = append(toRegisterGV, gpuModelGv)
+		}
+
 		pvGv = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "pv_hourly_cost",
 			Help: "pv_hourly_cost Cost per GB per hour on a persistent disk",
@@ -224,6 +240,22 @@ func initCostModelMetrics(clusterCache clustercache.ClusterCache, provider model
 			toRegisterGV = append(toRegisterGV, gpuAllocGv)
 		}
 
+		gpuRequestCostGv = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "container_gpu_request_hourly_cost",
+			Help: "container_gpu_request_hourly_cost Hourly cost of the gpus requested",
+		}, []string{"namespace", "pod", "container", "instance", "node", "gpu_model"})
+		if _, disabled := disabledMetrics["container_gpu_request_hourly_cost"]; !disabled {
+			toRegisterGV = append(toRegisterGV, gpuRequestCostGv)
+		}
+
+		gpuUsageCostGv = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "container_gpu_usage_hourly_cost",
+			Help: "container_gpu_usage_hourly_cost Hourly cost of the gpus used, from DCGM exporter utilization",
+		}, []string{"namespace", "pod", "container", "instance", "node", "gpu_model"})
+		if _, disabled := disabledMetrics["container_gpu_usage_hourly_cost"]; !disabled {
+			toRegisterGV = append(toRegisterGV, gpuUsageCostGv)
+		}
+
 		pvAllocGv = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "pod_pvc_allocation",
 			Help: "pod_pvc_allocation Bytes used by a PVC attached to a pod",
@@ -232,6 +264,14 @@ func initCostModelMetrics(clusterCache clustercache.ClusterCache, provider model
 			toRegisterGV = append(toRegisterGV, pvAllocGv)
 		}
 
+		podNetworkEgressCostGv = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "pod_network_egress_hourly_cost",
+			Help: "pod_network_egress_hourly_cost Cost of the network egress of a pod over the last hour by traffic class",
+		}, []string{"namespace", "pod", "traffic_class"})
+		if _, disabled := disabledMetrics["pod_network_egress_hourly_cost"]; !disabled {
+			toRegisterGV = append(toRegisterGV, podNetworkEgressCostGv)
+		}
+
 		networkZoneEgressCostG = prometheus.NewGauge(prometheus.GaugeOpts{
 			Name: "kubecost_network_zone_egress_cost",
 			Help: "kubecost_network_zone_egress_cost Total cost per GB egress across zones",
@@ -272,6 +312,30 @@ func initCostModelMetrics(clusterCache clustercache.ClusterCache, provider model
 			toRegisterGV = append(toRegisterGV, lbCostGv)
 		}
 
+		publicIPCostGv = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "kubecost_public_ip_cost",
+			Help: "kubecost_public_ip_cost Hourly cost of a public IP address of a node or load balancer, or allocated but unattached",
+		}, []string{"ip", "node"})
+		if _, disabled := disabledMetrics["kubecost_public_ip_cost"]; !disabled {
+			toRegisterGV = append(toRegisterGV, publicIPCostGv)
+		}
+
+		natGatewayCostGv = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "kubecost_nat_gateway_cost",
+			Help: "kubecost_nat_gateway_cost Hourly cost of a NAT gateway",
+		}, []string{"nat_gateway_id", "region"})
+		if _, disabled := disabledMetrics["kubecost_nat_gateway_cost"]; !disabled {
+			toRegisterGV = append(toRegisterGV, natGatewayCostGv)
+		}
+
+		natGatewayDataCostGv = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "kubecost_nat_gateway_data_processing_cost",
+			Help: "kubecost_nat_gateway_data_processing_cost Cost per GiB of data processed by a NAT gateway",
+		}, []string{"nat_gateway_id", "region"})
+		if _, disabled := disabledMetrics["kubecost_nat_gateway_data_processing_cost"]; !disabled {
+			toRegisterGV = append(toRegisterGV, natGatewayDataCostGv)
+		}
+
 		// Register cost-model metrics for emission
 		for _, gv := range toRegisterGV {
 			prometheus.MustRegister(gv)
@@ -306,14 +370,21 @@ type CostModelMetricsEmitter struct {
 	PersistentVolumePriceRecorder *prometheus.GaugeVec
 	GPUPriceRecorder              *prometheus.GaugeVec
 	GPUCountRecorder              *prometheus.GaugeVec
+	GPUModelPriceRecorder         *prometheus.GaugeVec
 	PVAllocationRecorder          *prometheus.GaugeVec
 	NodeSpotRecorder              *prometheus.GaugeVec
 	NodeTotalPriceRecorder        *prometheus.GaugeVec
 	RAMAllocationRecorder         *prometheus.GaugeVec
 	CPUAllocationRecorder         *prometheus.GaugeVec
 	GPUAllocationRecorder         *prometheus.GaugeVec
+	GPURequestCostRecorder        *prometheus.GaugeVec
+	GPUUsageCostRecorder          *prometheus.GaugeVec
 	ClusterManagementCostRecorder *prometheus.GaugeVec
 	LBCostRecorder                *prometheus.GaugeVec
+	PublicIPCostRecorder          *prometheus.GaugeVec
+	NATGatewayCostRecorder        *prometheus.GaugeVec
+	NATGatewayDataCostRecorder    *prometheus.GaugeVec
+	PodNetworkEgressCostRecorder  *prometheus.GaugeVec
 	NetworkZoneEgressRecorder     prometheus.Gauge
 	NetworkRegionEgressRecorder   prometheus.Gauge
 	NetworkInternetEgressRecorder prometheus.Gauge
@@ -357,18 +428,25 @@ func NewCostModelMetricsEmitter(promClient promclient.Client, clusterCache clust
 		RAMPriceRecorder:              ramGv,
 		GPUPriceRecorder:              gpuGv,
 		GPUCountRecorder:              gpuCountGv,
+		GPUModelPriceRecorder:         gpuModelGv,
 		PersistentVolumePriceRecorder: pvGv,
 		NodeSpotRecorder:              spotGv,
 		NodeTotalPriceRecorder:        totalGv,
 		RAMAllocationRecorder:         ramAllocGv,
 		CPUAllocationRecorder:         cpuAllocGv,
 		GPUAllocationRecorder:         gpuAllocGv,
+		GPURequestCostRecorder:        gpuRequestCostGv,
+		GPUUsageCostRecorder:          gpuUsageCostGv,
 		PVAllocationRecorder:          pvAllocGv,
+		PodNetworkEgressCostRecorder:  podNetworkEgressCostGv,
 		NetworkZoneEgressRecorder:     networkZoneEgressCostG,
 		NetworkRegionEgressRecorder:   networkRegionEgressCostG,
 		NetworkInternetEgressRecorder: networkInternetEgressCostG,
 		ClusterManagementCostRecorder: clusterManagementCostGv,
 		LBCostRecorder:                lbCostGv,
+		PublicIPCostRecorder:          publicIPCostGv,
+		NATGatewayCostRecorder:        natGatewayCostGv,
+		NATGatewayDataCostRecorder:    natGatewayDataCostGv,
 	}
 }
 
@@ -404,8 +482,13 @@ func (cmme *CostModelMetricsEmitter) Start() bool {
 		containerSeen := make(map[string]bool)
 		nodeSeen := make(map[string]bool)
 		loadBalancerSeen := make(map[string]bool)
+		publicIPSeen := make(map[string]bool)
+		natGatewaySeen := make(map[string]bool)
 		pvSeen := make(map[string]bool)
 		pvcSeen := make(map[string]bool)
+		gpuNodeSeen := make(map[string]bool)
+		gpuContainerSeen := make(map[string]bool)
+		podNetworkSeen := make(map[string]bool)
 		nodeCostAverages := make(map[string]NodeCostAverages)
 
 		getKeyFromLabelStrings := func(labels ...string) string {
@@ -415,6 +498,13 @@ func (cmme *CostModelMetricsEmitter) Start() bool {
 			return strings.Split(key, ",")
 		}
 
+		// NAT gateways and unattached addresses are listed from the provider's
+		// API, so are refreshed far less often than prices are recorded
+		var natGateways []*models.NATGateway
+		var natGatewaysRefreshed time.Time
+		var unattachedAddresses []string
+		var unattachedAddressesRefreshed time.Time
+
 		var defaultRegion string = ""
 		nodeList := cmme.KubeClusterCache.GetAllNodes()
 		if len(nodeList) > 0 {
@@ -449,6 +539,18 @@ func (cmme *CostModelMetricsEmitter) Start() bool {
 				cmme.NetworkZoneEgressRecorder.Set(networkCosts.ZoneNetworkEgressCost)
 				cmme.NetworkRegionEgressRecorder.Set(networkCosts.RegionNetworkEgressCost)
 				cmme.NetworkInternetEgressRecorder.Set(networkCosts.InternetNetworkEgressCost)
+
+				// Record the cost of each pod's egress, available when network traffic is monitored
+				networkUsage, err := QueryNetworkUsageData(cmme.PrometheusClient, "1h")
+				if err != nil {
+					log.Debugf("Failed to retrieve network usage: %s", err.Error())
+				}
+				for _, usage := range networkUsage {
+					for class, cost := range GetNetworkCostByTrafficClass(usage, networkCosts) {
+						cmme.PodNetworkEgressCostRecorder.WithLabelValues(usage.Namespace, usage.PodName, class).Set(cost)
+						podNetworkSeen[getKeyFromLabelStrings(usage.Namespace, usage.PodName, class)] = true
+					}
+				}
 			}
 
 			// TODO: Pass PrometheusClient and CloudProvider into CostModel on instantiation so this isn't so awkward
@@ -468,6 +570,23 @@ func (cmme *CostModelMetricsEmitter) Start() bool {
 				data = map[string]*CostData{}
 			}
 
+			gpuUsage, err := queryGPUUsage(cmme.PrometheusClient)
+			if err != nil {
+				log.Debugf("Metric emission: %s", err)
+			}
+
+			kubeNodes := make(map[string]*v1.Node)
+			for _, n := range cmme.KubeClusterCache.GetAllNodes() {
+				kubeNodes[n.Name] = n
+			}
+
+			// GPU model and hourly cost per GPU of each node with GPUs
+			type nodeGPU struct {
+				model string
+				cost  float64
+			}
+			nodeGPUs := make(map[string]nodeGPU)
+
 			// TODO: Pass CloudProvider into CostModel on instantiation so this isn't so awkward
 			nodes, err := cmme.Model.GetNodeCost(cmme.CloudProvider)
 			if err != nil {
@@ -531,6 +650,14 @@ func (cmme *CostModelMetricsEmitter) Start() bool {
 				cmme.GPUCountRecorder.WithLabelValues(nodeName, nodeName, nodeType, nodeRegion, node.ProviderID).Set(gpu)
 				cmme.GPUPriceRecorder.WithLabelValues(nodeName, nodeName, nodeType, nodeRegion, node.ProviderID).Set(gpuCost)
 
+				if gpu > 0 {
+					model := gpuModel(node, kubeNodes[nodeName])
+					nodeGPUs[nodeName] = nodeGPU{model: model, cost: gpuCost}
+
+					cmme.GPUModelPriceRecorder.WithLabelValues(nodeName, nodeName, nodeType, nodeRegion, node.ProviderID, model).Set(gpuCost)
+					gpuNodeSeen[getKeyFromLabelStrings(nodeName, nodeName, nodeType, nodeRegion, node.ProviderID, model)] = true
+				}
+
 				const outlierFactor float64 = 30
 				// don't record cpuCost, ramCost, or gpuCost in the case of wild outliers
 				// k8s api sometimes causes cost spikes as described here:
@@ -585,6 +712,75 @@ func (cmme *CostModelMetricsEmitter) Start() bool {
 				loadBalancerSeen[labelKey] = true
 			}
 
+			// Record the public IP addresses of nodes and load balancers, and
+			// those allocated but unattached, billed by the hour regardless of
+			// traffic by providers which charge for them
+			if cfg != nil && cfg.GetPublicIPHourlyCost() > 0 {
+				publicIPCost := cfg.GetPublicIPHourlyCost()
+				recordPublicIP := func(address, nodeName string) {
+					cmme.PublicIPCostRecorder.WithLabelValues(address, nodeName).Set(publicIPCost)
+					publicIPSeen[getKeyFromLabelStrings(address, nodeName)] = true
+				}
+
+				for nodeName, n := range kubeNodes {
+					for _, addr := range n.Status.Addresses {
+						if addr.Type != v1.NodeExternalIP || addr.Address == "" {
+							continue
+						}
+						recordPublicIP(addr.Address, nodeName)
+					}
+				}
+
+				// Load balancers addressed by hostname rather than IP are
+				// skipped, as are internal load balancers
+				for _, lb := range loadBalancers {
+					for _, address := range lb.IngressIPAddresses {
+						if ip := net.ParseIP(address); ip != nil && !ip.IsPrivate() {
+							recordPublicIP(address, "")
+						}
+					}
+				}
+
+				if uap, ok := cmme.CloudProvider.(models.UnattachedAddressProvider); ok {
+					if time.Since(unattachedAddressesRefreshed) > time.Hour {
+						addresses, err := uap.UnattachedAddresses()
+						if err != nil {
+							log.DedupedWarningf(5, "Metric emission: error getting unattached addresses: %s", err)
+						} else {
+							unattachedAddresses = addresses
+						}
+						unattachedAddressesRefreshed = time.Now()
+					}
+					for _, address := range unattachedAddresses {
+						recordPublicIP(address, "")
+					}
+				}
+			}
+
+			// Record the NAT gateways of providers able to list them
+			if ngp, ok := cmme.CloudProvider.(models.NATGatewayProvider); ok {
+				if time.Since(natGatewaysRefreshed) > time.Hour {
+					ngs, err := ngp.NATGateways()
+					if err != nil {
+						log.DedupedWarningf(5, "Metric emission: error getting NAT gateways: %s", err)
+					} else {
+						natGateways = ngs
+					}
+					natGatewaysRefreshed = time.Now()
+				}
+				for _, ng := range natGateways {
+					cmme.NATGatewayCostRecorder.WithLabelValues(ng.ID, ng.Region).Set(ng.Cost)
+					// Only NAT gateways routed through by the cluster process
+					// its egress, so only their data processing is priced
+					if ng.Routed {
+						cmme.NATGatewayDataCostRecorder.WithLabelValues(ng.ID, ng.Region).Set(ng.DataProcessingCost)
+					} else {
+						cmme.NATGatewayDataCostRecorder.DeleteLabelValues(ng.ID, ng.Region)
+					}
+					natGatewaySeen[getKeyFromLabelStrings(ng.ID, ng.Region)] = true
+				}
+			}
+
 			for _, costs := range data {
 				nodeName := costs.NodeName
 
@@ -616,6 +812,22 @@ func (cmme *CostModelMetricsEmitter) Start() bool {
 					// allocation here is set to the request because shared GPU usage not yet supported.
 					cmme.GPUAllocationRecorder.WithLabelValues(namespace, podName, containerName, nodeName, nodeName).Set(costs.GPUReq[0].Value)
 				}
+				if ng, ok := nodeGPUs[nodeName]; ok && podStatus[podName] == v1.PodRunning {
+					gpuLabels := []string{namespace, podName, containerName, nodeName, nodeName, ng.model}
+					emitted := false
+					if len(costs.GPUReq) > 0 && costs.GPUReq[0].Value > 0 {
+						cmme.GPURequestCostRecorder.WithLabelValues(gpuLabels...).Set(costs.GPUReq[0].Value * ng.cost)
+						emitted = true
+					}
+					if used, ok := gpuUsage[gpuUsageKey(namespace, podName, containerName)]; ok {
+						cmme.GPUUsageCostRecorder.WithLabelValues(gpuLabels...).Set(used * ng.cost)
+						emitted = true
+					}
+					if emitted {
+						gpuContainerSeen[getKeyFromLabelStrings(gpuLabels...)] = true
+					}
+				}
+
 				labelKey := getKeyFromLabelStrings(namespace, podName, containerName, nodeName, nodeName)
 				if podStatus[podName] == v1.PodRunning { // Only report data for current pods
 					containerSeen[labelKey] = true
@@ -724,6 +936,25 @@ func (cmme *CostModelMetricsEmitter) Start() bool {
 					loadBalancerSeen[labelString] = false
 				}
 			}
+			for labelString, seen := range publicIPSeen {
+				if !seen {
+					labels := getLabelStringsFromKey(labelString)
+					cmme.PublicIPCostRecorder.DeleteLabelValues(labels...)
+					delete(publicIPSeen, labelString)
+				} else {
+					publicIPSeen[labelString] = false
+				}
+			}
+			for labelString, seen := range natGatewaySeen {
+				if !seen {
+					labels := getLabelStringsFromKey(labelString)
+					cmme.NATGatewayCostRecorder.DeleteLabelValues(labels...)
+					cmme.NATGatewayDataCostRecorder.DeleteLabelValues(labels...)
+					delete(natGatewaySeen, labelString)
+				} else {
+					natGatewaySeen[labelString] = false
+				}
+			}
 			for labelString, seen := range containerSeen {
 				if !seen {
 					labels := getLabelStringsFromKey(labelString)
@@ -735,6 +966,34 @@ func (cmme *CostModelMetricsEmitter) Start() bool {
 					containerSeen[labelString] = false
 				}
 			}
+			for labelString, seen := range gpuNodeSeen {
+				if !seen {
+					labels := getLabelStringsFromKey(labelString)
+					cmme.GPUModelPriceRecorder.DeleteLabelValues(labels...)
+					delete(gpuNodeSeen, labelString)
+				} else {
+					gpuNodeSeen[labelString] = false
+				}
+			}
+			for labelString, seen := range gpuContainerSeen {
+				if !seen {
+					labels := getLabelStringsFromKey(labelString)
+					cmme.GPURequestCostRecorder.DeleteLabelValues(labels...)
+					cmme.GPUUsageCostRecorder.DeleteLabelValues(labels...)
+					delete(gpuContainerSeen, labelString)
+				} else {
+					gpuContainerSeen[labelString] = false
+				}
+			}
+			for labelString, seen := range podNetworkSeen {
+				if !seen {
+					labels := getLabelStringsFromKey(labelString)
+					cmme.PodNetworkEgressCostRecorder.DeleteLabelValues(labels...)
+					delete(podNetworkSeen, labelString)
+				} else {
+					podNetworkSeen[labelString] = false
+				}
+			}
 			for labelString, seen := range pvSeen {
 				if !seen {
 					labels := getLabelStringsFromKey(labelString)
@@ -757,6 +1016,7 @@ func (cmme *CostModelMetricsEmitter) Start() bool {
 			select {
 			case <-time.After(time.Minute):
 			case <-cmme.runState.OnStop():
+				cmme.reset()
 				cmme.runState.Reset()
 				return
 			}
@@ -766,6 +1026,38 @@ func (cmme *CostModelMetricsEmitter) Start() bool {
 	return true
 }
 
+// reset removes all series recorded by the emitter, so that a replica which
+// stops emitting, e.g. after losing leader election, does not continue to
+// expose stale costs alongside the new leader's.
+func (cmme *CostModelMetricsEmitter) reset() {
+	for _, gv := range []*prometheus.GaugeVec{
+		cmme.CPUPriceRecorder,
+		cmme.RAMPriceRecorder,
+		cmme.PersistentVolumePriceRecorder,
+		cmme.GPUPriceRecorder,
+		cmme.GPUCountRecorder,
+		cmme.GPUModelPriceRecorder,
+		cmme.PVAllocationRecorder,
+		cmme.NodeSpotRecorder,
+		cmme.NodeTotalPriceRecorder,
+		cmme.RAMAllocationRecorder,
+		cmme.CPUAllocationRecorder,
+		cmme.GPUAllocationRecorder,
+		cmme.GPURequestCostRecorder,
+		cmme.GPUUsageCostRecorder,
+		cmme.ClusterManagementCostRecorder,
+		cmme.LBCostRecorder,
+		cmme.PublicIPCostRecorder,
+		cmme.NATGatewayCostRecorder,
+		cmme.NATGatewayDataCostRecorder,
+		cmme.PodNetworkEgressCostRecorder,
+	} {
+		if gv != nil {
+			gv.Reset()
+		}
+	}
+}
+
 // Stop halts the metrics emission loop after the current emission is completed
 // or if the emission is paused.
 func (cmme *CostModelMetricsEmitter) Stop() {
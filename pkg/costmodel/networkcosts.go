@@ -1,11 +1,22 @@
 package costmodel
 
 import (
+	"fmt"
+
 	costAnalyzerCloud "github.com/opencost/opencost/pkg/cloud/models"
 	"github.com/opencost/opencost/pkg/env"
 	"github.com/opencost/opencost/pkg/log"
 	"github.com/opencost/opencost/pkg/prom"
 	"github.com/opencost/opencost/pkg/util"
+
+	prometheusClient "github.com/prometheus/client_golang/api"
+)
+
+// Traffic classes of pod network egress, by destination
+const (
+	NetworkTrafficZone     = "zone"
+	NetworkTrafficRegion   = "region"
+	NetworkTrafficInternet = "internet"
 )
 
 // NetworkUsageVNetworkUsageDataector contains the network usage values for egress network traffic
@@ -93,6 +104,39 @@ func GetNetworkUsageData(zr []*prom.QueryResult, rr []*prom.QueryResult, ir []*p
 	return usageData, nil
 }
 
+// QueryNetworkUsageData queries the GB of network egress of each namespace+pod over the window, e.g. "1h".
+func QueryNetworkUsageData(cli prometheusClient.Client, window string) (map[string]*NetworkUsageData, error) {
+	ctx := prom.NewNamedContext(cli, prom.ComputeCostDataContextName)
+	resChZone := ctx.Query(fmt.Sprintf(queryZoneNetworkUsage, window, "", env.GetPromClusterLabel()))
+	resChRegion := ctx.Query(fmt.Sprintf(queryRegionNetworkUsage, window, "", env.GetPromClusterLabel()))
+	resChInternet := ctx.Query(fmt.Sprintf(queryInternetNetworkUsage, window, "", env.GetPromClusterLabel()))
+
+	resZone, _ := resChZone.Await()
+	resRegion, _ := resChRegion.Await()
+	resInternet, _ := resChInternet.Await()
+	if ctx.HasErrors() {
+		return nil, ctx.ErrorCollection()
+	}
+
+	return GetNetworkUsageData(resZone, resRegion, resInternet, env.GetClusterID())
+}
+
+// GetNetworkCostByTrafficClass computes the cost of the most recent NetworkUsageData values for each traffic class
+// with usage.
+func GetNetworkCostByTrafficClass(usage *NetworkUsageData, pricing *costAnalyzerCloud.Network) map[string]float64 {
+	costs := make(map[string]float64, 3)
+	if n := len(usage.NetworkZoneEgress); n > 0 {
+		costs[NetworkTrafficZone] = usage.NetworkZoneEgress[n-1].Value * pricing.ZoneNetworkEgressCost
+	}
+	if n := len(usage.NetworkRegionEgress); n > 0 {
+		costs[NetworkTrafficRegion] = usage.NetworkRegionEgress[n-1].Value * pricing.RegionNetworkEgressCost
+	}
+	if n := len(usage.NetworkInternetEgress); n > 0 {
+		costs[NetworkTrafficInternet] = usage.NetworkInternetEgress[n-1].Value * pricing.InternetNetworkEgressCost
+	}
+	return costs
+}
+
 // GetNetworkCost computes the actual cost for NetworkUsageData based on data provided by the Provider.
 func GetNetworkCost(usage *NetworkUsageData, cloud costAnalyzerCloud.Provider) ([]*util.Vector, error) {
 	var results []*util.Vector
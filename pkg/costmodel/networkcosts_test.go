@@ -0,0 +1,36 @@
+package costmodel
+
+import (
+	"math"
+	"testing"
+
+	"github.com/opencost/opencost/pkg/cloud/models"
+	"github.com/opencost/opencost/pkg/util"
+)
+
+func TestGetNetworkCostByTrafficClass(t *testing.T) {
+	usage := &NetworkUsageData{
+		NetworkZoneEgress:     []*util.Vector{{Value: 1}, {Value: 2}},
+		NetworkInternetEgress: []*util.Vector{{Value: 3}},
+	}
+	pricing := &models.Network{
+		ZoneNetworkEgressCost:     0.01,
+		RegionNetworkEgressCost:   0.02,
+		InternetNetworkEgressCost: 0.12,
+	}
+
+	costs := GetNetworkCostByTrafficClass(usage, pricing)
+
+	expected := map[string]float64{
+		NetworkTrafficZone:     0.02,
+		NetworkTrafficInternet: 0.36,
+	}
+	if len(costs) != len(expected) {
+		t.Fatalf("expected %d traffic classes; got %v", len(expected), costs)
+	}
+	for class, cost := range expected {
+		if math.Abs(costs[class]-cost) > 1e-9 {
+			t.Errorf("%s: expected cost %f; got %f", class, cost, costs[class])
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package costmodel
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/opencost/opencost/pkg/cloud/models"
+	errs "github.com/opencost/opencost/pkg/errors"
+	"github.com/opencost/opencost/pkg/log"
+	"github.com/opencost/opencost/pkg/util/json"
+)
+
+// PricingDataVersion identifies the version of the provider's pricing data with
+// which the costs of a response were computed.
+type PricingDataVersion struct {
+	Version   string    `json:"version"`
+	FetchedAt time.Time `json:"fetchedAt"`
+	// Stale is true if the pricing data has not been downloaded, or any of
+	// its datasets is older than the maximum age.
+	Stale bool `json:"stale"`
+}
+
+// PricingDatasetStatus describes a dataset of the pricing data, and whether it
+// is older than the maximum age.
+type PricingDatasetStatus struct {
+	*models.PricingDataset
+	Stale bool `json:"stale"`
+}
+
+var (
+	pricingDataVersionLock sync.RWMutex
+	pricingDataVersionFunc func() *PricingDataVersion
+)
+
+// setPricingDataVersion sets the function returning the version of the pricing
+// data with which responses are annotated.
+func setPricingDataVersion(f func() *PricingDataVersion) {
+	pricingDataVersionLock.Lock()
+	defer pricingDataVersionLock.Unlock()
+
+	pricingDataVersionFunc = f
+}
+
+// currentPricingDataVersion returns the version of the pricing data with which
+// costs are currently computed, or nil if unknown.
+func currentPricingDataVersion() *PricingDataVersion {
+	pricingDataVersionLock.RLock()
+	f := pricingDataVersionFunc
+	pricingDataVersionLock.RUnlock()
+
+	if f == nil {
+		return nil
+	}
+	return f()
+}
+
+// pricingDataVersion returns the version of the last downloaded pricing data.
+func (a *Accesses) pricingDataVersion() *PricingDataVersion {
+	status := a.PricingRefreshStatus()
+	if status.Stale {
+		log.DedupedWarningf(5, "Pricing data is stale: last downloaded at %s", status.LastSuccess)
+	}
+
+	return &PricingDataVersion{
+		Version:   status.Version,
+		FetchedAt: status.LastSuccess,
+		Stale:     status.Stale,
+	}
+}
+
+// recordedPricingDatasets returns the datasets of the provider's pricing data,
+// just downloaded at the time, which the provider does not describe itself.
+// Providers unable to describe their datasets are described by a checksum of
+// their node pricing. The custom pricing, which every provider falls back on,
+// is always a dataset.
+func recordedPricingDatasets(cp models.Provider, fetchedAt time.Time) []*models.PricingDataset {
+	var datasets []*models.PricingDataset
+
+	if _, ok := cp.(models.PricingDatasetProvider); !ok {
+		if nodePricing, err := cp.AllNodePricing(); err == nil {
+			var source string
+			if info, err := cp.ClusterInfo(); err == nil {
+				source = info["provider"]
+			}
+			datasets = append(datasets, &models.PricingDataset{
+				Name:      "nodePricing",
+				Source:    source,
+				FetchedAt: fetchedAt,
+				Checksum:  jsonChecksum(nodePricing),
+			})
+		}
+	}
+
+	if customPricing, err := cp.GetConfig(); err == nil {
+		datasets = append(datasets, &models.PricingDataset{
+			Name:      "customPricing",
+			Source:    "config",
+			FetchedAt: fetchedAt,
+			Checksum:  jsonChecksum(customPricing),
+		})
+	}
+
+	return datasets
+}
+
+// jsonChecksum returns the SHA-256 checksum of the JSON encoding of v.
+func jsonChecksum(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		log.Warnf("checksumming pricing data: %s", err)
+	}
+	checksum := sha256.Sum256(data)
+	return hex.EncodeToString(checksum[:])
+}
+
+// pricingDatasetsVersion returns the version of the pricing data of the
+// datasets, which changes whenever the contents of any of them do.
+func pricingDatasetsVersion(datasets []*models.PricingDataset) string {
+	if len(datasets) == 0 {
+		return ""
+	}
+
+	lines := make([]string, 0, len(datasets))
+	for _, ds := range datasets {
+		lines = append(lines, fmt.Sprintf("%s:%s", ds.Name, ds.Checksum))
+	}
+	sort.Strings(lines)
+
+	h := sha256.New()
+	for _, line := range lines {
+		fmt.Fprintln(h, line)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:12]
+}
+
+// pricingDatasetStatuses returns the status of each of the datasets at the
+// time, and whether any is older than the maximum age. A maximum age of 0
+// disables staleness.
+func pricingDatasetStatuses(datasets []*models.PricingDataset, now time.Time, maxAge time.Duration) ([]*PricingDatasetStatus, bool) {
+	var anyStale bool
+	statuses := make([]*PricingDatasetStatus, 0, len(datasets))
+	for _, ds := range datasets {
+		stale := maxAge > 0 && now.Sub(ds.FetchedAt) > maxAge
+		anyStale = anyStale || stale
+		statuses = append(statuses, &PricingDatasetStatus{PricingDataset: ds, Stale: stale})
+	}
+	return statuses, anyStale
+}
+
+// refreshPricingData downloads the provider's pricing data at the interval, so
+// that long-running instances do not serve stale prices.
+func (a *Accesses) refreshPricingData(interval time.Duration) {
+	defer errs.HandlePanic()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := a.downloadPricingData(); err != nil {
+			log.Warnf("Failed to refresh pricing data: %s", err)
+		}
+	}
+}
+
+// GetPricingRefreshStatus responds with the outcome of the last download of
+// the provider's pricing data, and the version and freshness of its datasets.
+func (a *Accesses) GetPricingRefreshStatus(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	w.Write(WrapData(a.PricingRefreshStatus(), nil))
+}
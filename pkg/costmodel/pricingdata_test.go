@@ -0,0 +1,108 @@
+package costmodel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/pkg/cloud/models"
+)
+
+func TestPricingDatasetsVersion(t *testing.T) {
+	fetchedAt := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	dataset := func(name, checksum string) *models.PricingDataset {
+		return &models.PricingDataset{Name: name, Source: "test", FetchedAt: fetchedAt, Checksum: checksum}
+	}
+
+	v0 := pricingDatasetsVersion([]*models.PricingDataset{dataset("nodePricing", "abc"), dataset("customPricing", "def")})
+	if len(v0) != 12 {
+		t.Fatalf("expected a 12 character version; got '%s'", v0)
+	}
+
+	// the order of the datasets does not matter
+	v1 := pricingDatasetsVersion([]*models.PricingDataset{dataset("customPricing", "def"), dataset("nodePricing", "abc")})
+	if v0 != v1 {
+		t.Errorf("expected the same version regardless of order; got %s and %s", v0, v1)
+	}
+
+	// a change to the contents of any dataset changes the version
+	v2 := pricingDatasetsVersion([]*models.PricingDataset{dataset("nodePricing", "abc"), dataset("customPricing", "xyz")})
+	if v0 == v2 {
+		t.Errorf("expected a new version for changed contents; got %s", v2)
+	}
+
+	if v := pricingDatasetsVersion(nil); v != "" {
+		t.Errorf("expected no version without datasets; got %s", v)
+	}
+}
+
+func TestPricingDatasetStatuses(t *testing.T) {
+	now := time.Date(2023, 1, 8, 0, 0, 0, 0, time.UTC)
+	datasets := []*models.PricingDataset{
+		{Name: "AmazonEC2", FetchedAt: now.Add(-8 * 24 * time.Hour)},
+		{Name: "spot", FetchedAt: now.Add(-time.Hour)},
+	}
+
+	statuses, stale := pricingDatasetStatuses(datasets, now, 7*24*time.Hour)
+	if !stale {
+		t.Errorf("expected pricing data older than the max age to be stale")
+	}
+	if len(statuses) != 2 || !statuses[0].Stale || statuses[1].Stale {
+		t.Errorf("expected only AmazonEC2 to be stale; got %+v, %+v", statuses[0], statuses[1])
+	}
+
+	// a max age of 0 disables staleness
+	if _, stale := pricingDatasetStatuses(datasets, now, 0); stale {
+		t.Errorf("expected no staleness without a max age")
+	}
+}
+
+func TestAccesses_PricingRefreshStatus(t *testing.T) {
+	a := &Accesses{}
+
+	// pricing data which has never been downloaded is stale
+	if status := a.PricingRefreshStatus(); !status.Stale {
+		t.Errorf("expected stale status before any download")
+	}
+
+	a.pricingRefresh.LastSuccess = time.Now().UTC()
+	a.pricingDatasets = []*models.PricingDataset{{Name: "customPricing", FetchedAt: a.pricingRefresh.LastSuccess}}
+	if status := a.PricingRefreshStatus(); status.Stale || len(status.Datasets) != 1 {
+		t.Errorf("expected fresh status with 1 dataset; got %+v", status)
+	}
+}
+
+type datasetProvider struct {
+	models.Provider
+	datasets []*models.PricingDataset
+}
+
+func (dp *datasetProvider) PricingDatasets() []*models.PricingDataset {
+	return dp.datasets
+}
+
+func TestAccesses_PricingRefreshStatus_ProviderDatasets(t *testing.T) {
+	now := time.Now().UTC()
+	downloaded := now.Add(-30 * 24 * time.Hour)
+
+	dp := &datasetProvider{
+		datasets: []*models.PricingDataset{{Name: "AmazonEC2", FetchedAt: downloaded, Checksum: "abc"}},
+	}
+	a := &Accesses{CloudProvider: dp}
+	a.pricingRefresh.LastSuccess = downloaded
+
+	status := a.PricingRefreshStatus()
+	if !status.Stale {
+		t.Fatalf("expected stale status for a dataset older than the max age")
+	}
+
+	// the provider refreshing its datasets without a download through the
+	// API is reflected in the status
+	dp.datasets = []*models.PricingDataset{{Name: "AmazonEC2", FetchedAt: now, Checksum: "def"}}
+	refreshed := a.PricingRefreshStatus()
+	if refreshed.Stale {
+		t.Errorf("expected fresh status after the provider refreshed its datasets")
+	}
+	if refreshed.Version == status.Version {
+		t.Errorf("expected a new version after the provider refreshed its datasets; got %s", refreshed.Version)
+	}
+}
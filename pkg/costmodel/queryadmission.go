@@ -0,0 +1,321 @@
+package costmodel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/opencost/opencost/pkg/clustercache"
+	"github.com/opencost/opencost/pkg/env"
+	"github.com/opencost/opencost/pkg/kubecost"
+)
+
+const (
+	// allocationSeriesPerContainer is the approximate number of series
+	// returned per container by the queries computing an AllocationSet.
+	allocationSeriesPerContainer = 24
+
+	// bytesPerSample is the approximate memory held per sample of a query
+	// result, once decoded.
+	bytesPerSample = 48
+
+	// bytesPerAllocation is the approximate memory held per Allocation of
+	// each set of the result, including its properties and labels.
+	bytesPerAllocation = 4096
+)
+
+var (
+	errQueryTooLarge     = errors.New("query exceeds the memory budget")
+	errQueryQueueFull    = errors.New("too many queries waiting for memory")
+	errQueryQueueTimeout = errors.New("timed out waiting for memory")
+)
+
+var (
+	queryAdmissionMetricsOnce sync.Once
+
+	queryMemoryBudget   prometheus.Gauge
+	queryMemoryReserved prometheus.Gauge
+	queriesInFlight     prometheus.Gauge
+	queriesQueued       prometheus.Gauge
+	queriesRejected     *prometheus.CounterVec
+)
+
+// initQueryAdmissionMetrics registers the query admission metrics with the
+// default registry.
+func initQueryAdmissionMetrics() {
+	queryAdmissionMetricsOnce.Do(func() {
+		queryMemoryBudget = prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "opencost_query_memory_budget_bytes",
+			Help: "opencost_query_memory_budget_bytes Memory which may be reserved by in-flight queries",
+		})
+
+		queryMemoryReserved = prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "opencost_query_memory_reserved_bytes",
+			Help: "opencost_query_memory_reserved_bytes Memory reserved by in-flight queries, as estimated on admission",
+		})
+
+		queriesInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "opencost_queries_in_flight",
+			Help: "opencost_queries_in_flight Number of admitted queries being computed",
+		})
+
+		queriesQueued = prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "opencost_queries_queued",
+			Help: "opencost_queries_queued Number of queries waiting for memory to be released",
+		})
+
+		queriesRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "opencost_queries_rejected_total",
+			Help: "opencost_queries_rejected_total Number of queries rejected for exceeding the memory budget",
+		}, []string{"reason"})
+
+		prometheus.MustRegister(queryMemoryBudget, queryMemoryReserved, queriesInFlight, queriesQueued, queriesRejected)
+	})
+}
+
+// QueryEstimate is the predicted footprint of a query.
+type QueryEstimate struct {
+	// Sets is the number of sets in the result.
+	Sets int64 `json:"sets"`
+
+	// Ranges is the number of ranges each set is split into, so that no query
+	// to Prometheus spans more than the maximum query duration.
+	Ranges int64 `json:"ranges"`
+
+	// Series is the number of series queried for each range.
+	Series int64 `json:"series"`
+
+	// Samples is the number of samples queried for each range.
+	Samples int64 `json:"samples"`
+
+	// Bytes is the peak memory held by the query. Up to the concurrency, the
+	// ranges of a set are computed at once, as may be the sets themselves, so
+	// the samples of each of the ranges in flight are held alongside the result.
+	Bytes int64 `json:"bytes"`
+}
+
+// EstimateAllocationQuery predicts the footprint of computing allocations over
+// the window, in sets of the step, at the resolution, for a cluster running the
+// given number of containers. Each set is computed in ranges of at most the
+// maximum query duration, up to concurrency ranges at once, and, if
+// concurrentSets is true, up to concurrency sets are computed at once.
+func EstimateAllocationQuery(window kubecost.Window, resolution, step, maxQueryDuration time.Duration, containers, concurrency int, concurrentSets bool) *QueryEstimate {
+	duration := window.Duration()
+	if step <= 0 || step > duration {
+		step = duration
+	}
+	if resolution <= 0 {
+		resolution = time.Minute
+	}
+
+	sets := int64(1)
+	if step > 0 {
+		sets = int64(math.Ceil(float64(duration) / float64(step)))
+	}
+
+	// Sets longer than the maximum query duration are split into ranges
+	rangeDuration := step
+	ranges := int64(1)
+	if maxQueryDuration > 0 && step > maxQueryDuration {
+		rangeDuration = maxQueryDuration
+		ranges = int64(math.Ceil(float64(step) / float64(maxQueryDuration)))
+	}
+
+	points := int64(rangeDuration / resolution)
+	if points < 1 {
+		points = 1
+	}
+
+	series := int64(containers) * allocationSeriesPerContainer
+	samples := series * points
+
+	concurrentRanges := boundConcurrency(concurrency, ranges)
+	inFlight := concurrentRanges
+	if concurrentSets {
+		inFlight *= boundConcurrency(concurrency, sets)
+	}
+
+	return &QueryEstimate{
+		Sets:    sets,
+		Ranges:  ranges,
+		Series:  series,
+		Samples: samples,
+		Bytes:   inFlight*samples*bytesPerSample + sets*int64(containers)*bytesPerAllocation,
+	}
+}
+
+// boundConcurrency returns the number of n inputs computed at once by up to
+// concurrency workers.
+func boundConcurrency(concurrency int, n int64) int64 {
+	c := int64(concurrency)
+	if c > n {
+		c = n
+	}
+	if c < 1 {
+		c = 1
+	}
+	return c
+}
+
+// countContainers returns the number of containers of the pods in the cache.
+func countContainers(cache clustercache.ClusterCache) int {
+	if cache == nil {
+		return 0
+	}
+
+	containers := 0
+	for _, pod := range cache.GetAllPods() {
+		containers += len(pod.Spec.Containers)
+	}
+	return containers
+}
+
+// QueryAdmitter admits queries while the memory they are estimated to hold is
+// within a budget. Queries which would exceed the budget wait for in-flight
+// queries to release memory, up to a limit on the number waiting, and queries
+// which alone exceed the budget are rejected. Waiting queries are not admitted
+// in order, so a large query may wait on smaller ones admitted after it.
+type QueryAdmitter struct {
+	budget    int64
+	maxQueued int
+	timeout   time.Duration
+
+	lock     sync.Mutex
+	reserved int64
+	inFlight int
+	queued   int
+	released chan struct{}
+}
+
+// NewQueryAdmitter creates a QueryAdmitter of the budget in bytes, where up to
+// maxQueued queries wait up to timeout for memory to be released.
+func NewQueryAdmitter(budget int64, maxQueued int, timeout time.Duration) *QueryAdmitter {
+	initQueryAdmissionMetrics()
+	queryMemoryBudget.Set(float64(budget))
+
+	return &QueryAdmitter{
+		budget:    budget,
+		maxQueued: maxQueued,
+		timeout:   timeout,
+		released:  make(chan struct{}),
+	}
+}
+
+// Admit reserves the memory of the estimate, waiting for it to be available if
+// necessary, and returns a func releasing it once the query is complete.
+func (qa *QueryAdmitter) Admit(ctx context.Context, estimate *QueryEstimate) (func(), error) {
+	bytes := estimate.Bytes
+	if bytes > qa.budget {
+		queriesRejected.WithLabelValues("too_large").Inc()
+		return nil, fmt.Errorf("%w: estimated %d MB of %d MB", errQueryTooLarge, bytes>>20, qa.budget>>20)
+	}
+
+	var timer *time.Timer
+	waiting := false
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+		if waiting {
+			qa.lock.Lock()
+			qa.queued--
+			queriesQueued.Set(float64(qa.queued))
+			qa.lock.Unlock()
+		}
+	}()
+
+	for {
+		qa.lock.Lock()
+		if qa.reserved+bytes <= qa.budget {
+			qa.reserved += bytes
+			qa.inFlight++
+			queryMemoryReserved.Set(float64(qa.reserved))
+			queriesInFlight.Set(float64(qa.inFlight))
+			qa.lock.Unlock()
+
+			var once sync.Once
+			return func() {
+				once.Do(func() { qa.release(bytes) })
+			}, nil
+		}
+
+		if !waiting {
+			if qa.queued >= qa.maxQueued {
+				qa.lock.Unlock()
+				queriesRejected.WithLabelValues("queue_full").Inc()
+				return nil, errQueryQueueFull
+			}
+			qa.queued++
+			queriesQueued.Set(float64(qa.queued))
+			waiting = true
+			timer = time.NewTimer(qa.timeout)
+		}
+		released := qa.released
+		qa.lock.Unlock()
+
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer.C:
+			queriesRejected.WithLabelValues("timeout").Inc()
+			return nil, fmt.Errorf("%w after %s", errQueryQueueTimeout, qa.timeout)
+		}
+	}
+}
+
+func (qa *QueryAdmitter) release(bytes int64) {
+	qa.lock.Lock()
+	defer qa.lock.Unlock()
+
+	qa.reserved -= bytes
+	qa.inFlight--
+	queryMemoryReserved.Set(float64(qa.reserved))
+	queriesInFlight.Set(float64(qa.inFlight))
+
+	// wake every waiting query to check whether it now fits
+	close(qa.released)
+	qa.released = make(chan struct{})
+}
+
+// newQueryAdmitter creates the QueryAdmitter of the configured budget, or nil
+// if admission is disabled.
+func newQueryAdmitter() *QueryAdmitter {
+	budget := env.GetQueryMemoryBudgetMB()
+	if budget <= 0 {
+		return nil
+	}
+	return NewQueryAdmitter(int64(budget)<<20, env.GetQueryMaxQueued(), env.GetQueryQueueTimeout())
+}
+
+// admitAllocationQuery admits an allocation query over the window if its
+// estimated memory is within the budget, returning a func to be called once the
+// query is complete. Steps are computed in parallel if concurrentSteps is true.
+// Every query is admitted if admission is disabled.
+func (a *Accesses) admitAllocationQuery(ctx context.Context, window kubecost.Window, resolution, step time.Duration, concurrentSteps bool) (func(), error) {
+	if a.QueryAdmitter == nil {
+		return func() {}, nil
+	}
+
+	estimate := EstimateAllocationQuery(window, resolution, step, a.Model.MaxPrometheusQueryDuration, countContainers(a.ClusterCache), a.Model.ComputeConcurrency, concurrentSteps)
+	return a.QueryAdmitter.Admit(ctx, estimate)
+}
+
+// writeAdmissionError responds to a query which was not admitted: one which is
+// too large for the budget is a bad request, while one which could not be
+// admitted in time may be retried.
+func writeAdmissionError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errQueryTooLarge) {
+		WriteError(w, BadRequest(fmt.Sprintf("%s; narrow the window, or increase the step or resolution", err)))
+		return
+	}
+
+	w.Header().Set("Retry-After", "30")
+	WriteError(w, Error{StatusCode: http.StatusServiceUnavailable, Body: err.Error()})
+}
@@ -0,0 +1,174 @@
+package costmodel
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+)
+
+func TestEstimateAllocationQuery(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	window := kubecost.NewClosedWindow(start, start.Add(7*24*time.Hour))
+
+	series := int64(100 * allocationSeriesPerContainer)
+	result := 100 * int64(bytesPerAllocation)
+
+	testCases := map[string]struct {
+		resolution     time.Duration
+		step           time.Duration
+		maxQuery       time.Duration
+		concurrency    int
+		concurrentSets bool
+
+		sets    int64
+		ranges  int64
+		samples int64
+		bytes   int64
+	}{
+		"one set over the window": {
+			resolution:  5 * time.Minute,
+			concurrency: 1,
+			sets:        1,
+			ranges:      1,
+			samples:     series * 2016,
+			bytes:       series*2016*bytesPerSample + result,
+		},
+		"coarser resolution": {
+			resolution:  time.Hour,
+			concurrency: 1,
+			sets:        1,
+			ranges:      1,
+			samples:     series * 168,
+			bytes:       series*168*bytesPerSample + result,
+		},
+		"daily sets computed in turn": {
+			resolution:  5 * time.Minute,
+			step:        24 * time.Hour,
+			concurrency: 4,
+			sets:        7,
+			ranges:      1,
+			samples:     series * 288,
+			bytes:       series*288*bytesPerSample + 7*result,
+		},
+		"daily sets computed concurrently": {
+			resolution:     5 * time.Minute,
+			step:           24 * time.Hour,
+			concurrency:    4,
+			concurrentSets: true,
+			sets:           7,
+			ranges:         1,
+			samples:        series * 288,
+			bytes:          4*series*288*bytesPerSample + 7*result,
+		},
+		"concurrency beyond the number of sets": {
+			resolution:     5 * time.Minute,
+			step:           24 * time.Hour,
+			concurrency:    10,
+			concurrentSets: true,
+			sets:           7,
+			ranges:         1,
+			samples:        series * 288,
+			bytes:          7*series*288*bytesPerSample + 7*result,
+		},
+		"set split into ranges computed concurrently": {
+			resolution:  5 * time.Minute,
+			maxQuery:    24 * time.Hour,
+			concurrency: 4,
+			sets:        1,
+			ranges:      7,
+			samples:     series * 288,
+			bytes:       4*series*288*bytesPerSample + result,
+		},
+		"split sets computed concurrently": {
+			resolution:     5 * time.Minute,
+			step:           2 * 24 * time.Hour,
+			maxQuery:       24 * time.Hour,
+			concurrency:    2,
+			concurrentSets: true,
+			sets:           4,
+			ranges:         2,
+			samples:        series * 288,
+			bytes:          2*2*series*288*bytesPerSample + 4*result,
+		},
+		"sets within the maximum query duration": {
+			resolution:     5 * time.Minute,
+			step:           24 * time.Hour,
+			maxQuery:       2 * 24 * time.Hour,
+			concurrency:    1,
+			concurrentSets: true,
+			sets:           7,
+			ranges:         1,
+			samples:        series * 288,
+			bytes:          series*288*bytesPerSample + 7*result,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			estimate := EstimateAllocationQuery(window, tc.resolution, tc.step, tc.maxQuery, 100, tc.concurrency, tc.concurrentSets)
+			if estimate.Sets != tc.sets {
+				t.Errorf("expected %d sets; got %d", tc.sets, estimate.Sets)
+			}
+			if estimate.Ranges != tc.ranges {
+				t.Errorf("expected %d ranges; got %d", tc.ranges, estimate.Ranges)
+			}
+			if estimate.Series != series {
+				t.Errorf("expected %d series; got %d", series, estimate.Series)
+			}
+			if estimate.Samples != tc.samples {
+				t.Errorf("expected %d samples; got %d", tc.samples, estimate.Samples)
+			}
+			if estimate.Bytes != tc.bytes {
+				t.Errorf("expected %d bytes; got %d", tc.bytes, estimate.Bytes)
+			}
+		})
+	}
+}
+
+func TestQueryAdmitter_Admit(t *testing.T) {
+	qa := NewQueryAdmitter(100, 1, 50*time.Millisecond)
+	ctx := context.Background()
+
+	if _, err := qa.Admit(ctx, &QueryEstimate{Bytes: 101}); !errors.Is(err, errQueryTooLarge) {
+		t.Errorf("expected query too large; got %v", err)
+	}
+
+	release, err := qa.Admit(ctx, &QueryEstimate{Bytes: 60})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// a query which does not fit waits until the timeout
+	if _, err := qa.Admit(ctx, &QueryEstimate{Bytes: 60}); !errors.Is(err, errQueryQueueTimeout) {
+		t.Errorf("expected queue timeout; got %v", err)
+	}
+
+	// a waiting query is admitted once memory is released
+	admitted := make(chan error, 1)
+	go func() {
+		r, err := qa.Admit(ctx, &QueryEstimate{Bytes: 60})
+		if err == nil {
+			r()
+		}
+		admitted <- err
+	}()
+
+	// while one query waits, the queue is full
+	time.Sleep(10 * time.Millisecond)
+	if _, err := qa.Admit(ctx, &QueryEstimate{Bytes: 60}); !errors.Is(err, errQueryQueueFull) {
+		t.Errorf("expected queue full; got %v", err)
+	}
+
+	release()
+	release()
+	if err := <-admitted; err != nil {
+		t.Errorf("expected waiting query to be admitted; got %s", err)
+	}
+
+	if qa.reserved != 0 || qa.inFlight != 0 || qa.queued != 0 {
+		t.Errorf("expected nothing reserved, in flight or queued; got %d, %d and %d", qa.reserved, qa.inFlight, qa.queued)
+	}
+}
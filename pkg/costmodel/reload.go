@@ -0,0 +1,127 @@
+package costmodel
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/opencost/opencost/pkg/cloud/models"
+	"github.com/opencost/opencost/pkg/cloud/utils"
+	"github.com/opencost/opencost/pkg/config"
+	"github.com/opencost/opencost/pkg/currency"
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/log"
+	"github.com/opencost/opencost/pkg/util/json"
+)
+
+// reloadablePriceFields are the custom pricing fields which must hold a price.
+var reloadablePriceFields = map[string]bool{
+	"CPU":                          true,
+	"SpotCPU":                      true,
+	"RAM":                          true,
+	"SpotRAM":                      true,
+	"GPU":                          true,
+	"SpotGPU":                      true,
+	"Storage":                      true,
+	"ZoneNetworkEgress":            true,
+	"RegionNetworkEgress":          true,
+	"InternetNetworkEgress":        true,
+	"FirstFiveForwardingRulesCost": true,
+	"AdditionalForwardingRuleCost": true,
+	"LBIngressDataCost":            true,
+	"DefaultLBPrice":               true,
+	"SharedOverhead":               true,
+	"PublicIPHourlyCost":           true,
+	"NATGatewayHourlyCost":         true,
+	"NATGatewayDataProcessingCost": true,
+}
+
+// reloadableDiscountFields are the custom pricing fields which must hold a
+// percentage.
+var reloadableDiscountFields = map[string]bool{
+	"Discount":           true,
+	"NegotiatedDiscount": true,
+}
+
+// validateCustomPricingField returns an error if the value is not valid for the
+// custom pricing field: prices must be non-negative numbers, discounts
+// percentages between 0% and 100%, and the currency an ISO 4217 code. Other
+// fields are not validated.
+func validateCustomPricingField(field, value string) error {
+	if reloadablePriceFields[field] && value != "" {
+		if f, err := strconv.ParseFloat(value, 64); err != nil || f < 0 {
+			return fmt.Errorf("invalid price %q", value)
+		}
+	}
+	if reloadableDiscountFields[field] {
+		if d, err := ParsePercentString(value); err != nil || d < 0 || d > 1 {
+			return fmt.Errorf("invalid percentage %q", value)
+		}
+	}
+	if field == "CurrencyCode" && value != "" && !currency.IsCode(value) {
+		return fmt.Errorf("unknown currency code %q", value)
+	}
+	return nil
+}
+
+// PricingReloadFunc returns a config.ReloadFunc applying the custom pricing
+// fields, such as prices and discount percentages, present in the
+// configuration data to the provider. Keys are matched to fields the same way
+// as those of the pricing-configs ConfigMap.
+func PricingReloadFunc(provider models.Provider) config.ReloadFunc {
+	return func(data map[string]string) (func(), error) {
+		pricing := make(map[string]string)
+		for k, v := range data {
+			field := utils.ToTitle.String(k)
+			if !models.HasCustomPricingField(field) {
+				continue
+			}
+
+			if err := validateCustomPricingField(field, v); err != nil {
+				return nil, fmt.Errorf("%s: %w", k, err)
+			}
+
+			pricing[k] = v
+		}
+
+		if len(pricing) == 0 {
+			return nil, nil
+		}
+
+		return func() {
+			if _, err := provider.UpdateConfigFromConfigMap(pricing); err != nil {
+				log.Errorf("Config: failed to update custom pricing: %s", err)
+			}
+		}, nil
+	}
+}
+
+// ReloadLabelConfig parses the label mapping present in the configuration data,
+// keyed like the fields of kubecost.LabelConfig (e.g. "team_label"), and
+// returns a func setting it as the default label config, for use as a
+// config.ReloadFunc. Labels which are absent take their default values.
+func ReloadLabelConfig(data map[string]string) (func(), error) {
+	labels := make(map[string]string)
+	for k := range kubecost.NewLabelConfig().Map() {
+		v, ok := data[k]
+		if !ok {
+			continue
+		}
+		if v == "" {
+			return nil, fmt.Errorf("%s: label must not be empty", k)
+		}
+		labels[k] = v
+	}
+
+	lc := kubecost.NewLabelConfig()
+	if len(labels) > 0 {
+		b, err := json.Marshal(labels)
+		if err != nil {
+			return nil, fmt.Errorf("encoding label config: %w", err)
+		}
+		if err := json.Unmarshal(b, lc); err != nil {
+			return nil, fmt.Errorf("decoding label config: %w", err)
+		}
+	}
+
+	return func() { kubecost.SetDefaultLabelConfig(lc) }, nil
+}
@@ -0,0 +1,142 @@
+package costmodel
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/opencost/opencost/pkg/cloud/models"
+	"github.com/opencost/opencost/pkg/env"
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/log"
+	"github.com/opencost/opencost/pkg/prom"
+	"github.com/opencost/opencost/pkg/util/httputil"
+	"github.com/opencost/opencost/pkg/util/json"
+)
+
+// AllocationCapture records the inputs of one allocation compute pass: the
+// raw Prometheus responses and the pricing configuration. Replaying it with
+// ReplayAllocation reproduces the allocations offline.
+type AllocationCapture struct {
+	Start                      time.Time             `json:"start"`
+	End                        time.Time             `json:"end"`
+	Resolution                 time.Duration         `json:"resolution"`
+	MaxPrometheusQueryDuration time.Duration         `json:"maxPrometheusQueryDuration"`
+	CustomPricing              *models.CustomPricing `json:"customPricing"`
+	Prometheus                 *prom.Capture         `json:"prometheus"`
+}
+
+// CaptureAllocation computes the allocations of the window, recording the
+// Prometheus responses and pricing configuration they were computed from.
+func (cm *CostModel) CaptureAllocation(start, end time.Time, resolution time.Duration) (*AllocationCapture, *kubecost.AllocationSet, error) {
+	pricing, err := cm.Provider.GetConfig()
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading pricing config: %w", err)
+	}
+
+	client := prom.NewCapturingClient(cm.PrometheusClient)
+	capturing := NewCostModel(client, cm.Provider, cm.Cache, cm.ClusterMap, cm.ScrapeInterval)
+	capturing.MaxPrometheusQueryDuration = cm.MaxPrometheusQueryDuration
+
+	as, err := capturing.ComputeAllocation(start, end, resolution)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &AllocationCapture{
+		Start:                      start,
+		End:                        end,
+		Resolution:                 resolution,
+		MaxPrometheusQueryDuration: cm.MaxPrometheusQueryDuration,
+		CustomPricing:              redactPricingSecrets(pricing),
+		Prometheus:                 client.Capture(),
+	}, as, nil
+}
+
+// redactPricingSecrets returns a copy of the pricing configuration without the
+// credentials it may contain, so that captures can be attached to bug reports.
+func redactPricingSecrets(pricing *models.CustomPricing) *models.CustomPricing {
+	redacted := *pricing
+	redacted.ServiceKeySecret = ""
+	redacted.AlibabaServiceKeySecret = ""
+	redacted.AzureClientSecret = ""
+	redacted.AzureStorageAccessKey = ""
+	redacted.KubecostToken = ""
+	return &redacted
+}
+
+// ReplayAllocation re-runs the allocation pipeline against the captured
+// Prometheus responses and pricing configuration. Node discounts are combined
+// as for custom pricing, regardless of the provider of the capture.
+func ReplayAllocation(capture *AllocationCapture) (*kubecost.AllocationSet, error) {
+	if capture.Prometheus == nil || capture.CustomPricing == nil {
+		return nil, fmt.Errorf("incomplete capture")
+	}
+
+	client, err := prom.NewReplayClient(capture.Prometheus)
+	if err != nil {
+		return nil, err
+	}
+
+	cm := NewCostModel(client, &replayProvider{pricing: capture.CustomPricing}, nil, nil, 0)
+	cm.MaxPrometheusQueryDuration = capture.MaxPrometheusQueryDuration
+
+	return cm.ComputeAllocation(capture.Start, capture.End, capture.Resolution)
+}
+
+// LoadAllocationCapture reads an AllocationCapture from a file.
+func LoadAllocationCapture(path string) (*AllocationCapture, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading capture: %w", err)
+	}
+
+	capture := &AllocationCapture{}
+	if err := json.Unmarshal(b, capture); err != nil {
+		return nil, fmt.Errorf("decoding capture %s: %w", path, err)
+	}
+	return capture, nil
+}
+
+// replayProvider provides the captured pricing configuration to a replayed
+// compute pass. The allocation pipeline uses no other provider methods.
+type replayProvider struct {
+	models.Provider
+	pricing *models.CustomPricing
+}
+
+func (rp *replayProvider) GetConfig() (*models.CustomPricing, error) {
+	return rp.pricing, nil
+}
+
+func (rp *replayProvider) CombinedDiscountForNode(_ string, _ bool, defaultDiscount, negotiatedDiscount float64) float64 {
+	return 1.0 - ((1.0 - defaultDiscount) * (1.0 - negotiatedDiscount))
+}
+
+// GetAllocationCapture computes the allocations of the window and responds with the
+// AllocationCapture of the compute pass, which reproduces it offline with ReplayAllocation.
+func (a *Accesses) GetAllocationCapture(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	qp := httputil.NewQueryParams(r.URL.Query())
+
+	window, err := kubecost.ParseWindowWithOffset(qp.Get("window", ""), env.GetParsedUTCOffset())
+	if err != nil || window.IsOpen() {
+		http.Error(w, fmt.Sprintf("Invalid 'window' parameter: %s", qp.Get("window", "")), http.StatusBadRequest)
+		return
+	}
+	resolution := qp.GetDuration("resolution", env.GetETLResolution())
+
+	capture, _, err := a.Model.CaptureAllocation(*window.Start(), *window.End(), resolution)
+	if err != nil {
+		log.Errorf("Error capturing allocation: %s", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	name := fmt.Sprintf("allocation-capture-%s-%s.json", window.Start().UTC().Format("20060102T150405Z"), window.End().UTC().Format("20060102T150405Z"))
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	json.NewEncoder(w).Encode(capture)
+}
@@ -0,0 +1,282 @@
+package costmodel
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/pkg/cloud/models"
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/prom"
+	"github.com/opencost/opencost/pkg/util/json"
+)
+
+// updateGolden rewrites the golden files of the replay tests from the current
+// output of the allocation pipeline: go test ./pkg/costmodel -run Replay -update
+var updateGolden = flag.Bool("update", false, "update the golden files of the replay tests")
+
+// replayDir contains captures, named <case>.capture.json, made with the
+// /diagnostics/allocationCapture endpoint, and the allocations expected from
+// replaying each, named <case>.golden.json.
+const replayDir = "testdata/replay"
+
+// basicCapture is a capture of two pods on one node, one running for the whole
+// day and the other for its second half.
+var basicCapture = filepath.Join(replayDir, "basic.capture.json")
+
+// emptyPromClient responds to every query with an empty result.
+type emptyPromClient struct{}
+
+func (emptyPromClient) URL(ep string, args map[string]string) *url.URL {
+	return &url.URL{Scheme: "http", Host: "prometheus", Path: ep}
+}
+
+func (emptyPromClient) Do(context.Context, *http.Request) (*http.Response, []byte, error) {
+	return &http.Response{StatusCode: http.StatusOK}, []byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`), nil
+}
+
+type pricingProvider struct {
+	models.Provider
+}
+
+func (pricingProvider) GetConfig() (*models.CustomPricing, error) {
+	return &models.CustomPricing{CPU: "0.03", RAM: "0.004", GPU: "0.95", Discount: "10%", KubecostToken: "secret"}, nil
+}
+
+func (pricingProvider) CombinedDiscountForNode(_ string, _ bool, defaultDiscount, negotiatedDiscount float64) float64 {
+	return 1.0 - ((1.0 - defaultDiscount) * (1.0 - negotiatedDiscount))
+}
+
+func marshalAllocationSet(t *testing.T, as *kubecost.AllocationSet) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(as); err != nil {
+		t.Fatalf("encoding allocations: %s", err)
+	}
+	return buf.Bytes()
+}
+
+// allocationSummary is the resource usage and costs of an allocation compared
+// by the golden files of the replay tests.
+type allocationSummary struct {
+	Start            time.Time `json:"start"`
+	End              time.Time `json:"end"`
+	CPUCoreHours     float64   `json:"cpuCoreHours"`
+	CPUCost          float64   `json:"cpuCost"`
+	GPUCost          float64   `json:"gpuCost"`
+	RAMByteHours     float64   `json:"ramByteHours"`
+	RAMCost          float64   `json:"ramCost"`
+	PVCost           float64   `json:"pvCost"`
+	NetworkCost      float64   `json:"networkCost"`
+	LoadBalancerCost float64   `json:"loadBalancerCost"`
+	TotalCost        float64   `json:"totalCost"`
+}
+
+func summarizeAllocations(as *kubecost.AllocationSet) map[string]*allocationSummary {
+	summaries := map[string]*allocationSummary{}
+	for name, alloc := range as.Allocations {
+		summaries[name] = &allocationSummary{
+			Start:            alloc.Start.UTC(),
+			End:              alloc.End.UTC(),
+			CPUCoreHours:     alloc.CPUCoreHours,
+			CPUCost:          alloc.CPUTotalCost(),
+			GPUCost:          alloc.GPUTotalCost(),
+			RAMByteHours:     alloc.RAMByteHours,
+			RAMCost:          alloc.RAMTotalCost(),
+			PVCost:           alloc.PVTotalCost(),
+			NetworkCost:      alloc.NetworkTotalCost(),
+			LoadBalancerCost: alloc.LBTotalCost(),
+			TotalCost:        alloc.TotalCost(),
+		}
+	}
+	return summaries
+}
+
+// floatsEqual compares floats to a precision beyond that of any cost, so that
+// golden files are independent of the order of floating point operations.
+func floatsEqual(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(1, math.Abs(b))
+}
+
+// diffSummaries returns a description of each difference between the expected
+// and actual summaries.
+func diffSummaries(expected, actual map[string]*allocationSummary) []string {
+	var diffs []string
+	for name, e := range expected {
+		a, ok := actual[name]
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("%s: missing", name))
+			continue
+		}
+		if !a.Start.Equal(e.Start) || !a.End.Equal(e.End) {
+			diffs = append(diffs, fmt.Sprintf("%s: expected window [%s, %s]; got [%s, %s]", name, e.Start, e.End, a.Start, a.End))
+		}
+		for _, f := range []struct {
+			field            string
+			expected, actual float64
+		}{
+			{"cpuCoreHours", e.CPUCoreHours, a.CPUCoreHours},
+			{"cpuCost", e.CPUCost, a.CPUCost},
+			{"gpuCost", e.GPUCost, a.GPUCost},
+			{"ramByteHours", e.RAMByteHours, a.RAMByteHours},
+			{"ramCost", e.RAMCost, a.RAMCost},
+			{"pvCost", e.PVCost, a.PVCost},
+			{"networkCost", e.NetworkCost, a.NetworkCost},
+			{"loadBalancerCost", e.LoadBalancerCost, a.LoadBalancerCost},
+			{"totalCost", e.TotalCost, a.TotalCost},
+		} {
+			if !floatsEqual(f.expected, f.actual) {
+				diffs = append(diffs, fmt.Sprintf("%s: expected %s %f; got %f", name, f.field, f.expected, f.actual))
+			}
+		}
+	}
+	for name := range actual {
+		if _, ok := expected[name]; !ok {
+			diffs = append(diffs, fmt.Sprintf("%s: unexpected", name))
+		}
+	}
+	sort.Strings(diffs)
+	return diffs
+}
+
+func TestCaptureAllocation_Replay(t *testing.T) {
+	// serve the responses of a committed capture in place of Prometheus
+	fixture, err := LoadAllocationCapture(basicCapture)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	client, err := prom.NewReplayClient(fixture.Prometheus)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	cm := NewCostModel(client, pricingProvider{}, nil, nil, time.Minute)
+	cm.MaxPrometheusQueryDuration = fixture.MaxPrometheusQueryDuration
+
+	capture, captured, err := cm.CaptureAllocation(fixture.Start, fixture.End, fixture.Resolution)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// node prices are discounted by the 10% of the pricing config
+	expected := map[string]*allocationSummary{
+		"cluster-one/node1/prod/web-1/app": {
+			Start:        fixture.Start,
+			End:          fixture.End,
+			CPUCoreHours: 24,
+			CPUCost:      24 * 0.04 * 0.9,
+			RAMByteHours: 48 * 1024 * 1024 * 1024,
+			RAMCost:      48 * 0.005 * 0.9,
+			TotalCost:    (24*0.04 + 48*0.005) * 0.9,
+		},
+		"cluster-one/node1/prod/batch-1/worker": {
+			Start:        fixture.Start.Add(12 * time.Hour),
+			End:          fixture.End,
+			CPUCoreHours: 6,
+			CPUCost:      6 * 0.04 * 0.9,
+			RAMByteHours: 12 * 1024 * 1024 * 1024,
+			RAMCost:      12 * 0.005 * 0.9,
+			TotalCost:    (6*0.04 + 12*0.005) * 0.9,
+		},
+	}
+	if diffs := diffSummaries(expected, summarizeAllocations(captured)); len(diffs) > 0 {
+		t.Fatalf("unexpected captured allocations:\n%s", strings.Join(diffs, "\n"))
+	}
+
+	if len(capture.Prometheus.Responses) == 0 {
+		t.Fatalf("expected captured responses")
+	}
+	if capture.CustomPricing.KubecostToken != "" {
+		t.Errorf("expected secrets to be redacted from the capture")
+	}
+
+	// replay the capture as read from a file
+	b, err := json.Marshal(capture)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	path := filepath.Join(t.TempDir(), "capture.json")
+	if err := os.WriteFile(path, b, 0644); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	loaded, err := LoadAllocationCapture(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	replayed, err := ReplayAllocation(loaded)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !bytes.Equal(marshalAllocationSet(t, captured), marshalAllocationSet(t, replayed)) {
+		t.Errorf("expected replayed allocations to equal captured allocations")
+	}
+}
+
+func TestReplayAllocation_Golden(t *testing.T) {
+	captures, err := filepath.Glob(filepath.Join(replayDir, "*.capture.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(captures) == 0 {
+		t.Fatalf("no captures in %s", replayDir)
+	}
+
+	for _, path := range captures {
+		name := strings.TrimSuffix(filepath.Base(path), ".capture.json")
+		t.Run(name, func(t *testing.T) {
+			capture, err := LoadAllocationCapture(path)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			as, err := ReplayAllocation(capture)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if as.Length() == 0 {
+				t.Fatalf("expected allocations from replaying %s", path)
+			}
+			actual := summarizeAllocations(as)
+
+			goldenPath := filepath.Join(replayDir, name+".golden.json")
+			if *updateGolden {
+				var buf bytes.Buffer
+				enc := json.NewEncoder(&buf)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(actual); err != nil {
+					t.Fatalf("encoding golden file: %s", err)
+				}
+				if err := os.WriteFile(goldenPath, buf.Bytes(), 0644); err != nil {
+					t.Fatalf("writing golden file: %s", err)
+				}
+				return
+			}
+
+			b, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatalf("reading golden file (run with -update to create it): %s", err)
+			}
+			var expected map[string]*allocationSummary
+			if err := json.Unmarshal(b, &expected); err != nil {
+				t.Fatalf("decoding golden file: %s", err)
+			}
+			if diffs := diffSummaries(expected, actual); len(diffs) > 0 {
+				t.Errorf("allocations differ from %s; run with -update if the change is intended\n%s", goldenPath, strings.Join(diffs, "\n"))
+			}
+		})
+	}
+}
@@ -19,7 +19,9 @@ import (
 	"github.com/opencost/opencost/pkg/cloud/aws"
 	"github.com/opencost/opencost/pkg/cloud/gcp"
 	"github.com/opencost/opencost/pkg/cloud/provider"
+	"github.com/opencost/opencost/pkg/cloudcost"
 	"github.com/opencost/opencost/pkg/config"
+	"github.com/opencost/opencost/pkg/currency"
 	"github.com/opencost/opencost/pkg/kubeconfig"
 	"github.com/opencost/opencost/pkg/metrics"
 	"github.com/opencost/opencost/pkg/services"
@@ -42,6 +44,7 @@ import (
 	"github.com/opencost/opencost/pkg/costmodel/clusters"
 	"github.com/opencost/opencost/pkg/env"
 	"github.com/opencost/opencost/pkg/errors"
+	"github.com/opencost/opencost/pkg/events"
 	"github.com/opencost/opencost/pkg/kubecost"
 	"github.com/opencost/opencost/pkg/log"
 	"github.com/opencost/opencost/pkg/prom"
@@ -107,6 +110,31 @@ type Accesses struct {
 	settingsMutex       sync.Mutex
 	// registered http service instances
 	httpServices services.HTTPServices
+	// PricingHistory versions changes to the custom pricing made through the API
+	PricingHistory *PricingHistory
+	// pricingRefresh records the outcome of the last pricing data download,
+	// and pricingDatasets the datasets of the last successful download which
+	// the provider does not describe itself
+	pricingRefresh  PricingRefreshStatus
+	pricingDatasets []*models.PricingDataset
+	pricingLock     sync.Mutex
+	// Currency converts costs to the currency in which they are displayed
+	Currency *currency.Converter
+	// CloudCostRepository stores the cloud costs ingested from billing exports
+	CloudCostRepository *cloudcost.MemoryRepository
+	cloudCostIngestors  []*cloudcost.Ingestor
+	// QueryAdmitter limits the memory held by in-flight allocation queries
+	QueryAdmitter *QueryAdmitter
+}
+
+// PricingRefreshStatus describes the freshness of the cloud provider's pricing data.
+type PricingRefreshStatus struct {
+	LastAttempt time.Time               `json:"lastAttempt"`
+	LastSuccess time.Time               `json:"lastSuccess"`
+	LastError   string                  `json:"lastError,omitempty"`
+	Version     string                  `json:"version,omitempty"`
+	Stale       bool                    `json:"stale"`
+	Datasets    []*PricingDatasetStatus `json:"datasets,omitempty"`
 }
 
 // GetPrometheusClient decides whether the default Prometheus client or the Thanos client
@@ -171,6 +199,10 @@ type Response struct {
 	Data    interface{} `json:"data"`
 	Message string      `json:"message,omitempty"`
 	Warning string      `json:"warning,omitempty"`
+	// Currency is the currency of the costs in the response
+	Currency *currency.Conversion `json:"currency,omitempty"`
+	// Pricing is the version of the pricing data the costs were computed with
+	Pricing *PricingDataVersion `json:"pricing,omitempty"`
 }
 
 // FilterFunc is a filter that returns true iff the given CostData should be filtered out, and the environment that was used as the filter criteria, if it was an aggregate
@@ -277,16 +309,20 @@ func WrapData(data interface{}, err error) []byte {
 	if err != nil {
 		log.Errorf("Error returned to client: %s", err.Error())
 		resp, _ = json.Marshal(&Response{
-			Code:    http.StatusInternalServerError,
-			Status:  "error",
-			Message: err.Error(),
-			Data:    data,
+			Code:     http.StatusInternalServerError,
+			Status:   "error",
+			Message:  err.Error(),
+			Data:     data,
+			Currency: unconvertedCurrency(),
+			Pricing:  currentPricingDataVersion(),
 		})
 	} else {
 		resp, err = json.Marshal(&Response{
-			Code:   http.StatusOK,
-			Status: "success",
-			Data:   data,
+			Code:     http.StatusOK,
+			Status:   "success",
+			Data:     data,
+			Currency: unconvertedCurrency(),
+			Pricing:  currentPricingDataVersion(),
 		})
 		if err != nil {
 			log.Errorf("error marshaling response json: %s", err.Error())
@@ -302,17 +338,21 @@ func WrapDataWithMessage(data interface{}, err error, message string) []byte {
 	if err != nil {
 		log.Errorf("Error returned to client: %s", err.Error())
 		resp, _ = json.Marshal(&Response{
-			Code:    http.StatusInternalServerError,
-			Status:  "error",
-			Message: err.Error(),
-			Data:    data,
+			Code:     http.StatusInternalServerError,
+			Status:   "error",
+			Message:  err.Error(),
+			Data:     data,
+			Currency: unconvertedCurrency(),
+			Pricing:  currentPricingDataVersion(),
 		})
 	} else {
 		resp, _ = json.Marshal(&Response{
-			Code:    http.StatusOK,
-			Status:  "success",
-			Data:    data,
-			Message: message,
+			Code:     http.StatusOK,
+			Status:   "success",
+			Data:     data,
+			Currency: unconvertedCurrency(),
+			Pricing:  currentPricingDataVersion(),
+			Message:  message,
 		})
 	}
 
@@ -325,18 +365,22 @@ func WrapDataWithWarning(data interface{}, err error, warning string) []byte {
 	if err != nil {
 		log.Errorf("Error returned to client: %s", err.Error())
 		resp, _ = json.Marshal(&Response{
-			Code:    http.StatusInternalServerError,
-			Status:  "error",
-			Message: err.Error(),
-			Warning: warning,
-			Data:    data,
+			Code:     http.StatusInternalServerError,
+			Status:   "error",
+			Message:  err.Error(),
+			Warning:  warning,
+			Data:     data,
+			Currency: unconvertedCurrency(),
+			Pricing:  currentPricingDataVersion(),
 		})
 	} else {
 		resp, _ = json.Marshal(&Response{
-			Code:    http.StatusOK,
-			Status:  "success",
-			Data:    data,
-			Warning: warning,
+			Code:     http.StatusOK,
+			Status:   "success",
+			Data:     data,
+			Currency: unconvertedCurrency(),
+			Pricing:  currentPricingDataVersion(),
+			Warning:  warning,
 		})
 	}
 
@@ -349,19 +393,23 @@ func WrapDataWithMessageAndWarning(data interface{}, err error, message, warning
 	if err != nil {
 		log.Errorf("Error returned to client: %s", err.Error())
 		resp, _ = json.Marshal(&Response{
-			Code:    http.StatusInternalServerError,
-			Status:  "error",
-			Message: err.Error(),
-			Warning: warning,
-			Data:    data,
+			Code:     http.StatusInternalServerError,
+			Status:   "error",
+			Message:  err.Error(),
+			Warning:  warning,
+			Data:     data,
+			Currency: unconvertedCurrency(),
+			Pricing:  currentPricingDataVersion(),
 		})
 	} else {
 		resp, _ = json.Marshal(&Response{
-			Code:    http.StatusOK,
-			Status:  "success",
-			Data:    data,
-			Message: message,
-			Warning: warning,
+			Code:     http.StatusOK,
+			Status:   "success",
+			Data:     data,
+			Currency: unconvertedCurrency(),
+			Pricing:  currentPricingDataVersion(),
+			Message:  message,
+			Warning:  warning,
 		})
 	}
 
@@ -381,7 +429,7 @@ func (a *Accesses) RefreshPricingData(w http.ResponseWriter, r *http.Request, ps
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 
-	err := a.CloudProvider.DownloadPricingData()
+	err := a.downloadPricingData()
 	if err != nil {
 		log.Errorf("Error refreshing pricing data: %s", err.Error())
 	}
@@ -389,6 +437,65 @@ func (a *Accesses) RefreshPricingData(w http.ResponseWriter, r *http.Request, ps
 	w.Write(WrapData(nil, err))
 }
 
+// PricingRefreshStatus returns the outcome of the last download of the cloud provider's pricing data,
+// and whether any of its datasets is older than the maximum age.
+func (a *Accesses) PricingRefreshStatus() PricingRefreshStatus {
+	a.pricingLock.Lock()
+	status := a.pricingRefresh
+	recorded := a.pricingDatasets
+	a.pricingLock.Unlock()
+
+	// Providers describing their own datasets are read every time, as they
+	// refresh them without a download through the API.
+	var datasets []*models.PricingDataset
+	if pdp, ok := a.CloudProvider.(models.PricingDatasetProvider); ok {
+		datasets = append(datasets, pdp.PricingDatasets()...)
+	}
+	datasets = append(datasets, recorded...)
+
+	status.Version = pricingDatasetsVersion(datasets)
+	status.Datasets, status.Stale = pricingDatasetStatuses(datasets, time.Now().UTC(), env.GetPricingMaxAge())
+	if status.LastSuccess.IsZero() {
+		status.Stale = true
+	}
+	return status
+}
+
+// downloadPricingData downloads the cloud provider's pricing data, emitting a
+// PricingRefreshFailed event on failure.
+func (a *Accesses) downloadPricingData() error {
+	err := a.CloudProvider.DownloadPricingData()
+	now := time.Now().UTC()
+
+	var datasets []*models.PricingDataset
+	if err == nil {
+		datasets = recordedPricingDatasets(a.CloudProvider, now)
+	}
+
+	a.pricingLock.Lock()
+	a.pricingRefresh.LastAttempt = now
+	if err != nil {
+		a.pricingRefresh.LastError = err.Error()
+	} else {
+		a.pricingRefresh.LastSuccess = a.pricingRefresh.LastAttempt
+		a.pricingRefresh.LastError = ""
+		a.pricingDatasets = datasets
+	}
+	a.pricingLock.Unlock()
+
+	if err != nil {
+		var provider string
+		if info, ierr := a.CloudProvider.ClusterInfo(); ierr == nil {
+			provider = info["provider"]
+		}
+		events.Emit(events.PricingRefreshFailed, provider, map[string]string{
+			"provider": provider,
+			"error":    err.Error(),
+		})
+	}
+	return err
+}
+
 func (a *Accesses) CostDataModel(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -593,7 +700,7 @@ func (a *Accesses) UpdateSpotInfoConfigs(w http.ResponseWriter, r *http.Request,
 		return
 	}
 	w.Write(WrapData(data, err))
-	err = a.CloudProvider.DownloadPricingData()
+	err = a.downloadPricingData()
 	if err != nil {
 		log.Errorf("Error redownloading data on config update: %s", err.Error())
 	}
@@ -1204,40 +1311,35 @@ func (a *Accesses) GetInstallInfo(w http.ResponseWriter, r *http.Request, _ http
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 
-	pods, err := a.KubeClientSet.CoreV1().Pods(env.GetKubecostNamespace()).List(context.Background(), metav1.ListOptions{
-		LabelSelector: "app=cost-analyzer",
-		FieldSelector: "status.phase=Running",
-		Limit:         1,
-	})
-	if err != nil {
-		writeErrorResponse(w, 500, fmt.Sprintf("Unable to list pods: %s", err.Error()))
-		return
-	}
+	cachePods := a.ClusterCache.GetAllPods()
 
 	info := InstallInfo{
 		ClusterInfo: make(map[string]string),
 		Version:     version.FriendlyVersion(),
 	}
 
-	// If we have zero pods either something is weird with the install since the app selector is not exposed in the helm
+	// If no pod is found either something is weird with the install since the app selector is not exposed in the helm
 	// chart or more likely we are running locally - in either case Images field will return as null
-	if len(pods.Items) > 0 {
-		for _, pod := range pods.Items {
-			for _, container := range pod.Status.ContainerStatuses {
-				c := ContainerInfo{
-					ContainerName: container.Name,
-					Image:         container.Image,
-					ImageID:       container.ImageID,
-					StartTime:     pod.Status.StartTime.String(),
-					Restarts:      container.RestartCount,
-				}
-				info.Containers = append(info.Containers, c)
+	namespace := env.GetKubecostNamespace()
+	for _, pod := range cachePods {
+		if pod.Namespace != namespace || pod.Labels["app"] != "cost-analyzer" || pod.Status.Phase != v1.PodRunning {
+			continue
+		}
+
+		for _, container := range pod.Status.ContainerStatuses {
+			c := ContainerInfo{
+				ContainerName: container.Name,
+				Image:         container.Image,
+				ImageID:       container.ImageID,
+				StartTime:     pod.Status.StartTime.String(),
+				Restarts:      container.RestartCount,
 			}
+			info.Containers = append(info.Containers, c)
 		}
+		break
 	}
 
 	nodes := a.ClusterCache.GetAllNodes()
-	cachePods := a.ClusterCache.GetAllPods()
 
 	info.ClusterInfo["nodeCount"] = strconv.Itoa(len(nodes))
 	info.ClusterInfo["podCount"] = strconv.Itoa(len(cachePods))
@@ -1510,7 +1612,7 @@ func Initialize(additionalConfigWatchers ...*watcher.ConfigMapWatcher) *Accesses
 	log.Infof("Prometheus/Thanos Client Max Concurrency set to %d", queryConcurrency)
 
 	timeout := 120 * time.Second
-	keepAlive := 120 * time.Second
+	keepAlive := env.GetPrometheusKeepAlive()
 	tlsHandshakeTimeout := 10 * time.Second
 	scrapeInterval := time.Minute
 
@@ -1533,8 +1635,13 @@ func Initialize(additionalConfigWatchers ...*watcher.ConfigMapWatcher) *Accesses
 			Password:    env.GetDBBasicAuthUserPassword(),
 			BearerToken: env.GetDBBearerToken(),
 		},
-		QueryConcurrency: queryConcurrency,
-		QueryLogFile:     "",
+		QueryConcurrency:      queryConcurrency,
+		QueryLogFile:          "",
+		MaxIdleConns:          env.GetPrometheusMaxIdleConns(),
+		MaxIdleConnsPerHost:   env.GetPrometheusMaxIdleConnsPerHost(),
+		IdleConnTimeout:       env.GetPrometheusIdleConnTimeout(),
+		ResponseHeaderTimeout: env.GetPrometheusResponseHeaderTimeout(),
+		HTTP2:                 env.IsPrometheusHTTP2Enabled(),
 	})
 	if err != nil {
 		log.Fatalf("Failed to create prometheus client, Error: %v", err)
@@ -1648,8 +1755,13 @@ func Initialize(additionalConfigWatchers ...*watcher.ConfigMapWatcher) *Accesses
 					Password:    env.GetMultiClusterBasicAuthPassword(),
 					BearerToken: env.GetMultiClusterBearerToken(),
 				},
-				QueryConcurrency: queryConcurrency,
-				QueryLogFile:     env.GetQueryLoggingFile(),
+				QueryConcurrency:      queryConcurrency,
+				QueryLogFile:          env.GetQueryLoggingFile(),
+				MaxIdleConns:          env.GetPrometheusMaxIdleConns(),
+				MaxIdleConnsPerHost:   env.GetPrometheusMaxIdleConnsPerHost(),
+				IdleConnTimeout:       env.GetPrometheusIdleConnTimeout(),
+				ResponseHeaderTimeout: env.GetPrometheusResponseHeaderTimeout(),
+				HTTP2:                 env.IsPrometheusHTTP2Enabled(),
 			})
 
 			_, err = prom.Validate(thanosCli)
@@ -1738,12 +1850,24 @@ func Initialize(additionalConfigWatchers ...*watcher.ConfigMapWatcher) *Accesses
 	// TODO clean this up once ETL is open-sourced.
 	a.AggAPI = a
 
+	a.Currency, err = newCurrencyConverter()
+	if err != nil {
+		log.Errorf("Failed to configure currency conversion: %s", err)
+	}
+	setSourceCurrency(a.sourceCurrency)
+	setPricingDataVersion(a.pricingDataVersion)
+
+	a.QueryAdmitter = newQueryAdmitter()
+
 	// Initialize mechanism for subscribing to settings changes
 	a.InitializeSettingsPubSub()
-	err = a.CloudProvider.DownloadPricingData()
+	err = a.downloadPricingData()
 	if err != nil {
 		log.Infof("Failed to download pricing data: " + err.Error())
 	}
+	if interval := env.GetPricingRefreshInterval(); interval > 0 {
+		go a.refreshPricingData(interval)
+	}
 
 	// Warm the aggregate cache unless explicitly set to false
 	if env.IsCacheWarmingEnabled() {
@@ -1753,7 +1877,9 @@ func Initialize(additionalConfigWatchers ...*watcher.ConfigMapWatcher) *Accesses
 		log.Infof("Init: AggregateCostModel cache warming disabled")
 	}
 
-	if !env.IsKubecostMetricsPodEnabled() {
+	// with leader election enabled, the emitter is started by the elector on
+	// the leader only
+	if !env.IsKubecostMetricsPodEnabled() && !env.IsLeaderElectionEnabled() {
 		a.MetricsEmitter.Start()
 	}
 
@@ -1762,6 +1888,8 @@ func Initialize(additionalConfigWatchers ...*watcher.ConfigMapWatcher) *Accesses
 	a.Router.GET("/aggregatedCostModel", a.AggregateCostModelHandler)
 	a.Router.GET("/allocation/compute", a.ComputeAllocationHandler)
 	a.Router.GET("/allocation/compute/summary", a.ComputeAllocationHandlerSummary)
+	a.Router.GET("/allocation/disruption", a.ComputeAllocationDisruptionHandler)
+	a.Router.GET("/allocation/architectureSavings", a.ComputeArchitectureComparisonHandler)
 	a.Router.GET("/allNodePricing", a.GetAllNodePricing)
 	a.Router.POST("/refreshPricing", a.RefreshPricingData)
 	a.Router.GET("/clusterCostsOverTime", a.ClusterCostsOverTime)
@@ -1775,6 +1903,7 @@ func Initialize(additionalConfigWatchers ...*watcher.ConfigMapWatcher) *Accesses
 	a.Router.GET("/pricingSourceStatus", a.GetPricingSourceStatus)
 	a.Router.GET("/pricingSourceSummary", a.GetPricingSourceSummary)
 	a.Router.GET("/pricingSourceCounts", a.GetPricingSourceCounts)
+	a.Router.GET("/pricingRefreshStatus", a.GetPricingRefreshStatus)
 
 	// endpoints migrated from server
 	a.Router.GET("/allPersistentVolumes", a.GetAllPersistentVolumes)
@@ -1806,6 +1935,36 @@ func Initialize(additionalConfigWatchers ...*watcher.ConfigMapWatcher) *Accesses
 	// diagnostics
 	a.Router.GET("/diagnostics/requestQueue", a.GetPrometheusQueueState)
 	a.Router.GET("/diagnostics/prometheusMetrics", a.GetPrometheusMetrics)
+	if env.IsDiagnosticsBundleEnabled() {
+		a.Router.GET("/diagnostics/bundle", a.GetDiagnosticsBundle)
+	}
+	if env.IsAllocationCaptureEnabled() {
+		a.Router.GET("/diagnostics/allocationCapture", a.GetAllocationCapture)
+	}
+
+	// custom pricing
+	if env.IsCustomPricingAPIEnabled() {
+		pricingHistory, err := NewPricingHistory(cloudProvider, kubeClientset, confManager.ConfigFileAt(path.Join(configPrefix, "pricing-history.json")))
+		if err != nil {
+			log.Errorf("Failed to load custom pricing history: %s", err)
+		} else if pricingHistory.TrustedProxies, err = ParseTrustedProxies(env.GetCustomPricingTrustedProxies()); err != nil {
+			log.Errorf("Invalid %s: %s", env.CustomPricingTrustedProxiesEnvVar, err)
+		} else {
+			a.PricingHistory = pricingHistory
+			a.Router.GET("/customPricing", a.GetCustomPricing)
+			a.Router.POST("/customPricing", a.UpdateCustomPricing)
+			a.Router.GET("/customPricing/versions", a.GetCustomPricingVersions)
+			a.Router.POST("/customPricing/versions/:version/rollback", a.RollbackCustomPricing)
+		}
+	}
+
+	// cloud costs
+	if env.IsCloudCostEnabled() {
+		a.CloudCostRepository = cloudcost.NewMemoryRepository()
+		a.cloudCostIngestors = startCloudCostIngestors(cloudProvider, a.CloudCostRepository)
+		a.Router.GET("/cloudCost", a.ComputeCloudCostHandler)
+		a.Router.GET("/cloudCost/status", a.GetCloudCostStatus)
+	}
 
 	a.Router.GET("/logs/level", a.GetLogLevel)
 	a.Router.POST("/logs/level", a.SetLogLevel)
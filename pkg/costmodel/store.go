@@ -0,0 +1,68 @@
+package costmodel
+
+import (
+	"context"
+	"time"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/log"
+	"github.com/opencost/opencost/pkg/store"
+)
+
+// SetStore configures a durable store of finalized windows. Queries for windows
+// ending more than queryAfter ago are served from the store when it covers
+// them, falling back to Prometheus otherwise.
+func (cm *CostModel) SetStore(s store.Store, queryAfter time.Duration) {
+	cm.store = s
+	cm.storeQueryAfter = queryAfter
+}
+
+// Store returns the durable store of finalized windows, or nil if none is
+// configured.
+func (cm *CostModel) Store() store.Store {
+	return cm.store
+}
+
+// useStore returns true if a query for a window ending at end should be served
+// from the store.
+func (cm *CostModel) useStore(end time.Time) bool {
+	return cm.store != nil && !end.After(time.Now().Add(-cm.storeQueryAfter))
+}
+
+// QueryAllocationSet returns the allocations for [start, end) from the store
+// if it covers the window, or computes them from Prometheus otherwise. The
+// source of the data is recorded in the set's FromSource.
+func (cm *CostModel) QueryAllocationSet(start, end time.Time, resolution time.Duration) (*kubecost.AllocationSet, error) {
+	if cm.useStore(end) {
+		sets, err := cm.store.QueryAllocations(context.Background(), start, end)
+		if err != nil {
+			log.Warnf("CostModel: querying allocations from %s store: %s", cm.store.Name(), err)
+		} else if as, ok, err := store.AccumulateAllocations(sets, start, end); err != nil {
+			log.Warnf("CostModel: accumulating allocations from %s store: %s", cm.store.Name(), err)
+		} else if ok {
+			as.FromSource = cm.store.Name()
+			return as, nil
+		}
+	}
+
+	return cm.ComputeAllocation(start, end, resolution)
+}
+
+// QueryAssetSet returns the assets for [start, end) from the store if it
+// covers the window, or computes them from Prometheus otherwise. The source of
+// the data is recorded in the set's FromSource.
+func (cm *CostModel) QueryAssetSet(start, end time.Time) (*kubecost.AssetSet, error) {
+	if cm.useStore(end) {
+		sets, err := cm.store.QueryAssets(context.Background(), start, end)
+		if err != nil {
+			log.Warnf("CostModel: querying assets from %s store: %s", cm.store.Name(), err)
+		} else if as, ok, err := store.AccumulateAssets(sets, start, end); err != nil {
+			log.Warnf("CostModel: accumulating assets from %s store: %s", cm.store.Name(), err)
+		} else if ok {
+			as.FromSource = cm.store.Name()
+			return as, nil
+		}
+	}
+
+	return cm.ComputeAssets(start, end)
+}
@@ -0,0 +1,497 @@
+package costmodel
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow/go/v10/arrow"
+	"github.com/apache/arrow/go/v10/arrow/array"
+	"github.com/apache/arrow/go/v10/arrow/memory"
+	"github.com/apache/arrow/go/v10/parquet"
+	"github.com/apache/arrow/go/v10/parquet/compress"
+	"github.com/apache/arrow/go/v10/parquet/pqarrow"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/log"
+	"github.com/opencost/opencost/pkg/util/httputil"
+)
+
+// ResponseFormat identifies the encoding used to write an API response body.
+type ResponseFormat string
+
+const (
+	ResponseFormatJSON    ResponseFormat = "json"
+	ResponseFormatCSV     ResponseFormat = "csv"
+	ResponseFormatParquet ResponseFormat = "parquet"
+)
+
+const (
+	contentTypeJSON    = "application/json"
+	contentTypeCSV     = "text/csv"
+	contentTypeParquet = "application/vnd.apache.parquet"
+)
+
+// labelColumnPrefix is used to request a column containing the value of a
+// single label, e.g. "label:app".
+const labelColumnPrefix = "label:"
+
+// ContentType returns the MIME type written for the response format.
+func (rf ResponseFormat) ContentType() string {
+	switch rf {
+	case ResponseFormatCSV:
+		return contentTypeCSV
+	case ResponseFormatParquet:
+		return contentTypeParquet
+	default:
+		return contentTypeJSON
+	}
+}
+
+// FileExtension returns the extension used when the response is downloaded as a file.
+func (rf ResponseFormat) FileExtension() string {
+	switch rf {
+	case ResponseFormatCSV:
+		return ".csv"
+	case ResponseFormatParquet:
+		return ".parquet"
+	default:
+		return ".json"
+	}
+}
+
+// ParseResponseFormat determines the requested response format. An explicit
+// "format" query parameter takes precedence over the Accept header. Requests
+// expressing no preference receive JSON.
+func ParseResponseFormat(r *http.Request) (ResponseFormat, error) {
+	if f := strings.TrimSpace(r.URL.Query().Get("format")); f != "" {
+		switch ResponseFormat(strings.ToLower(f)) {
+		case ResponseFormatJSON:
+			return ResponseFormatJSON, nil
+		case ResponseFormatCSV:
+			return ResponseFormatCSV, nil
+		case ResponseFormatParquet:
+			return ResponseFormatParquet, nil
+		}
+		return "", fmt.Errorf("unsupported format '%s'", f)
+	}
+
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err != nil {
+			continue
+		}
+
+		switch mediaType {
+		case contentTypeCSV, "application/csv":
+			return ResponseFormatCSV, nil
+		case contentTypeParquet, "application/parquet", "application/x-parquet":
+			return ResponseFormatParquet, nil
+		case contentTypeJSON:
+			return ResponseFormatJSON, nil
+		}
+	}
+
+	return ResponseFormatJSON, nil
+}
+
+// ParseParquetCompression converts a codec name into a parquet compression codec.
+// An empty name selects snappy, which is the default for most readers, as does
+// an unsupported name, along with an error.
+func ParseParquetCompression(name string) (compress.Compression, error) {
+	switch strings.ToLower(name) {
+	case "", "snappy":
+		return compress.Codecs.Snappy, nil
+	case "gzip":
+		return compress.Codecs.Gzip, nil
+	case "zstd":
+		return compress.Codecs.Zstd, nil
+	case "none", "uncompressed":
+		return compress.Codecs.Uncompressed, nil
+	}
+	return compress.Codecs.Snappy, fmt.Errorf("unsupported parquet compression '%s'", name)
+}
+
+//--------------------------------------------------------------------------
+//  Tabular Columns
+//--------------------------------------------------------------------------
+
+// TabularColumn defines a single column of a tabular (CSV or Parquet)
+// representation of T. Exactly one of String or Float must be set, which
+// also determines the column type in typed formats like Parquet.
+type TabularColumn[T any] struct {
+	Name   string
+	String func(T) string
+	Float  func(T) float64
+}
+
+// IsNumeric returns true if the column holds floating point values.
+func (tc TabularColumn[T]) IsNumeric() bool {
+	return tc.Float != nil
+}
+
+// TabularRow is an item rendered as a single row, along with the window of
+// the set it was drawn from.
+type TabularRow[T any] struct {
+	Start time.Time
+	End   time.Time
+	Item  T
+}
+
+// AllocationRow is a single row in a tabular allocation response.
+type AllocationRow = TabularRow[*kubecost.Allocation]
+
+// AssetRow is a single row in a tabular assets response.
+type AssetRow = TabularRow[kubecost.Asset]
+
+func fmtTabularTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func fmtTabularFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func allocProps(a *kubecost.Allocation) *kubecost.AllocationProperties {
+	if a.Properties == nil {
+		return &kubecost.AllocationProperties{}
+	}
+	return a.Properties
+}
+
+func assetProps(a kubecost.Asset) *kubecost.AssetProperties {
+	if props := a.GetProperties(); props != nil {
+		return props
+	}
+	return &kubecost.AssetProperties{}
+}
+
+func allocStr(f func(*kubecost.Allocation) string) func(AllocationRow) string {
+	return func(r AllocationRow) string { return f(r.Item) }
+}
+
+func allocFloat(f func(*kubecost.Allocation) float64) func(AllocationRow) float64 {
+	return func(r AllocationRow) float64 { return f(r.Item) }
+}
+
+// AllocationColumns contains every built-in allocation column, in the default
+// column order.
+var AllocationColumns = []TabularColumn[AllocationRow]{
+	{Name: "WindowStart", String: func(r AllocationRow) string { return fmtTabularTime(r.Start) }},
+	{Name: "WindowEnd", String: func(r AllocationRow) string { return fmtTabularTime(r.End) }},
+	{Name: "Name", String: allocStr(func(a *kubecost.Allocation) string { return a.Name })},
+	{Name: "Start", String: allocStr(func(a *kubecost.Allocation) string { return fmtTabularTime(a.Start) })},
+	{Name: "End", String: allocStr(func(a *kubecost.Allocation) string { return fmtTabularTime(a.End) })},
+	{Name: "Cluster", String: allocStr(func(a *kubecost.Allocation) string { return allocProps(a).Cluster })},
+	{Name: "Node", String: allocStr(func(a *kubecost.Allocation) string { return allocProps(a).Node })},
+	{Name: "Namespace", String: allocStr(func(a *kubecost.Allocation) string { return allocProps(a).Namespace })},
+	{Name: "ControllerKind", String: allocStr(func(a *kubecost.Allocation) string { return allocProps(a).ControllerKind })},
+	{Name: "ControllerName", String: allocStr(func(a *kubecost.Allocation) string { return allocProps(a).Controller })},
+	{Name: "Pod", String: allocStr(func(a *kubecost.Allocation) string { return allocProps(a).Pod })},
+	{Name: "Container", String: allocStr(func(a *kubecost.Allocation) string { return allocProps(a).Container })},
+	{Name: "Minutes", Float: allocFloat(func(a *kubecost.Allocation) float64 { return a.Minutes() })},
+	{Name: "CPUCoreHours", Float: allocFloat(func(a *kubecost.Allocation) float64 { return a.CPUCoreHours })},
+	{Name: "CPUCoreRequestAverage", Float: allocFloat(func(a *kubecost.Allocation) float64 { return a.CPUCoreRequestAverage })},
+	{Name: "CPUCoreUsageAverage", Float: allocFloat(func(a *kubecost.Allocation) float64 { return a.CPUCoreUsageAverage })},
+	{Name: "RAMByteHours", Float: allocFloat(func(a *kubecost.Allocation) float64 { return a.RAMByteHours })},
+	{Name: "RAMBytesRequestAverage", Float: allocFloat(func(a *kubecost.Allocation) float64 { return a.RAMBytesRequestAverage })},
+	{Name: "RAMBytesUsageAverage", Float: allocFloat(func(a *kubecost.Allocation) float64 { return a.RAMBytesUsageAverage })},
+	{Name: "GPUHours", Float: allocFloat(func(a *kubecost.Allocation) float64 { return a.GPUHours })},
+	{Name: "PVByteHours", Float: allocFloat(func(a *kubecost.Allocation) float64 { return a.PVByteHours() })},
+	{Name: "NetworkTransferBytes", Float: allocFloat(func(a *kubecost.Allocation) float64 { return a.NetworkTransferBytes })},
+	{Name: "NetworkReceiveBytes", Float: allocFloat(func(a *kubecost.Allocation) float64 { return a.NetworkReceiveBytes })},
+	{Name: "CPUCost", Float: allocFloat((*kubecost.Allocation).CPUTotalCost)},
+	{Name: "RAMCost", Float: allocFloat((*kubecost.Allocation).RAMTotalCost)},
+	{Name: "GPUCost", Float: allocFloat((*kubecost.Allocation).GPUTotalCost)},
+	{Name: "PVCost", Float: allocFloat((*kubecost.Allocation).PVTotalCost)},
+	{Name: "NetworkCost", Float: allocFloat((*kubecost.Allocation).NetworkTotalCost)},
+	{Name: "LoadBalancerCost", Float: allocFloat((*kubecost.Allocation).LBTotalCost)},
+	{Name: "SharedCost", Float: allocFloat((*kubecost.Allocation).SharedTotalCost)},
+	{Name: "ExternalCost", Float: allocFloat(func(a *kubecost.Allocation) float64 { return a.ExternalCost })},
+	{Name: "TotalCost", Float: allocFloat((*kubecost.Allocation).TotalCost)},
+	{Name: "CPUEfficiency", Float: allocFloat((*kubecost.Allocation).CPUEfficiency)},
+	{Name: "RAMEfficiency", Float: allocFloat((*kubecost.Allocation).RAMEfficiency)},
+	{Name: "TotalEfficiency", Float: allocFloat((*kubecost.Allocation).TotalEfficiency)},
+}
+
+// AssetColumns contains every built-in asset column, in the default column order.
+var AssetColumns = []TabularColumn[AssetRow]{
+	{Name: "WindowStart", String: func(r AssetRow) string { return fmtTabularTime(r.Start) }},
+	{Name: "WindowEnd", String: func(r AssetRow) string { return fmtTabularTime(r.End) }},
+	{Name: "Type", String: func(r AssetRow) string { return r.Item.Type().String() }},
+	{Name: "Start", String: func(r AssetRow) string { return fmtTabularTime(r.Item.GetStart()) }},
+	{Name: "End", String: func(r AssetRow) string { return fmtTabularTime(r.Item.GetEnd()) }},
+	{Name: "Category", String: func(r AssetRow) string { return assetProps(r.Item).Category }},
+	{Name: "Provider", String: func(r AssetRow) string { return assetProps(r.Item).Provider }},
+	{Name: "Account", String: func(r AssetRow) string { return assetProps(r.Item).Account }},
+	{Name: "Project", String: func(r AssetRow) string { return assetProps(r.Item).Project }},
+	{Name: "Service", String: func(r AssetRow) string { return assetProps(r.Item).Service }},
+	{Name: "Cluster", String: func(r AssetRow) string { return assetProps(r.Item).Cluster }},
+	{Name: "Name", String: func(r AssetRow) string { return assetProps(r.Item).Name }},
+	{Name: "ProviderID", String: func(r AssetRow) string { return assetProps(r.Item).ProviderID }},
+	{Name: "Minutes", Float: func(r AssetRow) float64 { return r.Item.Minutes() }},
+	{Name: "Adjustment", Float: func(r AssetRow) float64 { return r.Item.GetAdjustment() }},
+	{Name: "TotalCost", Float: func(r AssetRow) float64 { return r.Item.TotalCost() }},
+}
+
+// allocationLabelColumn creates a column containing the value of the given label.
+func allocationLabelColumn(label string) TabularColumn[AllocationRow] {
+	return TabularColumn[AllocationRow]{
+		Name: "Label_" + label,
+		String: func(r AllocationRow) string {
+			return allocProps(r.Item).Labels[label]
+		},
+	}
+}
+
+// assetLabelColumn creates a column containing the value of the given label.
+func assetLabelColumn(label string) TabularColumn[AssetRow] {
+	return TabularColumn[AssetRow]{
+		Name: "Label_" + label,
+		String: func(r AssetRow) string {
+			return r.Item.GetLabels()[label]
+		},
+	}
+}
+
+// SelectTabularColumns returns the subset of the available columns matching the
+// requested names, in the requested order. Names prefixed with "label:" are
+// resolved with the labelColumn func. If no names are requested, all available
+// columns are returned.
+func SelectTabularColumns[T any](available []TabularColumn[T], names []string, labelColumn func(string) TabularColumn[T]) ([]TabularColumn[T], error) {
+	if len(names) == 0 {
+		return available, nil
+	}
+
+	byName := make(map[string]TabularColumn[T], len(available))
+	for _, col := range available {
+		byName[strings.ToLower(col.Name)] = col
+	}
+
+	selected := make([]TabularColumn[T], 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		if strings.HasPrefix(name, labelColumnPrefix) {
+			label := strings.TrimPrefix(name, labelColumnPrefix)
+			if label == "" || labelColumn == nil {
+				return nil, fmt.Errorf("invalid column '%s'", name)
+			}
+			selected = append(selected, labelColumn(label))
+			continue
+		}
+
+		col, ok := byName[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown column '%s'", name)
+		}
+		selected = append(selected, col)
+	}
+
+	return selected, nil
+}
+
+// tabularColumnNames returns the column names requested by the "columns" query
+// parameter, falling back to the provided defaults when absent.
+func tabularColumnNames(qp httputil.QueryParams, defaults []string) []string {
+	if names := qp.GetList("columns", ","); len(names) > 0 {
+		return names
+	}
+	return defaults
+}
+
+// SelectAllocationColumns resolves the requested allocation column names.
+func SelectAllocationColumns(names []string) ([]TabularColumn[AllocationRow], error) {
+	return SelectTabularColumns(AllocationColumns, names, allocationLabelColumn)
+}
+
+// SelectAssetColumns resolves the requested asset column names.
+func SelectAssetColumns(names []string) ([]TabularColumn[AssetRow], error) {
+	return SelectTabularColumns(AssetColumns, names, assetLabelColumn)
+}
+
+// AllocationRowsFor flattens an AllocationSetRange into rows, ordered by set
+// and then by allocation name.
+func AllocationRowsFor(asr *kubecost.AllocationSetRange) []AllocationRow {
+	var rows []AllocationRow
+	if asr == nil {
+		return rows
+	}
+
+	for _, as := range asr.Slice() {
+		if as == nil {
+			continue
+		}
+
+		names := make([]string, 0, len(as.Allocations))
+		for name := range as.Allocations {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var start, end time.Time
+		if as.Window.Start() != nil {
+			start = *as.Window.Start()
+		}
+		if as.Window.End() != nil {
+			end = *as.Window.End()
+		}
+
+		for _, name := range names {
+			rows = append(rows, AllocationRow{Start: start, End: end, Item: as.Allocations[name]})
+		}
+	}
+
+	return rows
+}
+
+// AssetRowsFor flattens an AssetSet into rows, ordered by asset key.
+func AssetRowsFor(as *kubecost.AssetSet) []AssetRow {
+	var rows []AssetRow
+	if as == nil {
+		return rows
+	}
+
+	keys := make([]string, 0, len(as.Assets))
+	for key := range as.Assets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var start, end time.Time
+	if as.Window.Start() != nil {
+		start = *as.Window.Start()
+	}
+	if as.Window.End() != nil {
+		end = *as.Window.End()
+	}
+
+	for _, key := range keys {
+		rows = append(rows, AssetRow{Start: start, End: end, Item: as.Assets[key]})
+	}
+
+	return rows
+}
+
+//--------------------------------------------------------------------------
+//  Tabular Writers
+//--------------------------------------------------------------------------
+
+// WriteTabularCSV writes the header and one record per row to w.
+func WriteTabularCSV[T any](w io.Writer, columns []TabularColumn[T], rows []T) error {
+	csvWriter := csv.NewWriter(w)
+
+	header := make([]string, 0, len(columns))
+	for _, col := range columns {
+		header = append(header, col.Name)
+	}
+	if err := csvWriter.Write(header); err != nil {
+		return fmt.Errorf("writing csv header: %w", err)
+	}
+
+	record := make([]string, len(columns))
+	for _, row := range rows {
+		for i, col := range columns {
+			if col.IsNumeric() {
+				record[i] = fmtTabularFloat(col.Float(row))
+			} else {
+				record[i] = col.String(row)
+			}
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return fmt.Errorf("writing csv row: %w", err)
+		}
+	}
+
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		return fmt.Errorf("flushing csv: %w", err)
+	}
+	return nil
+}
+
+// WriteTabularParquet writes rows to w as a single parquet file using the
+// provided compression codec. String columns are written as UTF8 and numeric
+// columns as DOUBLE.
+func WriteTabularParquet[T any](w io.Writer, columns []TabularColumn[T], rows []T, codec compress.Compression) error {
+	fields := make([]arrow.Field, 0, len(columns))
+	for _, col := range columns {
+		var dt arrow.DataType = arrow.BinaryTypes.String
+		if col.IsNumeric() {
+			dt = arrow.PrimitiveTypes.Float64
+		}
+		fields = append(fields, arrow.Field{Name: col.Name, Type: dt})
+	}
+	schema := arrow.NewSchema(fields, nil)
+
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer builder.Release()
+
+	for i, col := range columns {
+		switch b := builder.Field(i).(type) {
+		case *array.Float64Builder:
+			b.Reserve(len(rows))
+			for _, row := range rows {
+				b.Append(col.Float(row))
+			}
+		case *array.StringBuilder:
+			b.Reserve(len(rows))
+			for _, row := range rows {
+				b.Append(col.String(row))
+			}
+		default:
+			return fmt.Errorf("unexpected builder type %T for column %s", b, col.Name)
+		}
+	}
+
+	record := builder.NewRecord()
+	defer record.Release()
+
+	props := parquet.NewWriterProperties(parquet.WithCompression(codec))
+	fw, err := pqarrow.NewFileWriter(schema, w, props, pqarrow.DefaultWriterProps())
+	if err != nil {
+		return fmt.Errorf("creating parquet writer: %w", err)
+	}
+
+	if err := fw.Write(record); err != nil {
+		fw.Close()
+		return fmt.Errorf("writing parquet record: %w", err)
+	}
+
+	if err := fw.Close(); err != nil {
+		return fmt.Errorf("closing parquet writer: %w", err)
+	}
+	return nil
+}
+
+// writeTabularResponse writes rows to the response in the given format, which
+// must be either CSV or Parquet. The filename is used as the attachment name
+// so that browsers and curl -OJ save a sensibly named file.
+func writeTabularResponse[T any](w http.ResponseWriter, format ResponseFormat, filename string, columns []TabularColumn[T], rows []T, codec compress.Compression) {
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+format.FileExtension()))
+
+	var err error
+	switch format {
+	case ResponseFormatCSV:
+		err = WriteTabularCSV(w, columns, rows)
+	case ResponseFormatParquet:
+		err = WriteTabularParquet(w, columns, rows, codec)
+	default:
+		err = fmt.Errorf("unsupported tabular format '%s'", format)
+	}
+
+	// headers have already been sent, so the best we can do is log the failure
+	if err != nil {
+		log.Errorf("Failed to write %s response: %s", format, err)
+	}
+}
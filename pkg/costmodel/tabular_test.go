@@ -0,0 +1,126 @@
+package costmodel
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v10/parquet/compress"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+)
+
+func Test_ParseResponseFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		accept   string
+		expected ResponseFormat
+		wantErr  bool
+	}{
+		{name: "no preference", url: "/allocation", expected: ResponseFormatJSON},
+		{name: "format param csv", url: "/allocation?format=csv", expected: ResponseFormatCSV},
+		{name: "format param parquet upper", url: "/allocation?format=PARQUET", expected: ResponseFormatParquet},
+		{name: "format param wins over accept", url: "/allocation?format=json", accept: "text/csv", expected: ResponseFormatJSON},
+		{name: "accept csv", url: "/allocation", accept: "text/csv; charset=utf-8", expected: ResponseFormatCSV},
+		{name: "accept parquet", url: "/allocation", accept: "application/vnd.apache.parquet", expected: ResponseFormatParquet},
+		{name: "accept list", url: "/allocation", accept: "text/html, application/x-parquet;q=0.9", expected: ResponseFormatParquet},
+		{name: "accept anything", url: "/allocation", accept: "*/*", expected: ResponseFormatJSON},
+		{name: "unknown format", url: "/allocation?format=xml", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tt.url, nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+
+			format, err := ParseResponseFormat(r)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, format)
+		})
+	}
+}
+
+func Test_ParseParquetCompression(t *testing.T) {
+	codec, err := ParseParquetCompression("ZSTD")
+	require.NoError(t, err)
+	assert.Equal(t, compress.Codecs.Zstd, codec)
+
+	codec, err = ParseParquetCompression("")
+	require.NoError(t, err)
+	assert.Equal(t, compress.Codecs.Snappy, codec)
+
+	// an unsupported codec falls back to the default
+	codec, err = ParseParquetCompression("lz4")
+	assert.Error(t, err)
+	assert.Equal(t, compress.Codecs.Snappy, codec)
+}
+
+func Test_SelectAllocationColumns(t *testing.T) {
+	t.Run("defaults to all columns", func(t *testing.T) {
+		cols, err := SelectAllocationColumns(nil)
+		require.NoError(t, err)
+		assert.Len(t, cols, len(AllocationColumns))
+	})
+
+	t.Run("selects in requested order, case-insensitive", func(t *testing.T) {
+		cols, err := SelectAllocationColumns([]string{"totalcost", "Namespace", "label:app"})
+		require.NoError(t, err)
+		require.Len(t, cols, 3)
+		assert.Equal(t, "TotalCost", cols[0].Name)
+		assert.Equal(t, "Namespace", cols[1].Name)
+		assert.Equal(t, "Label_app", cols[2].Name)
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		_, err := SelectAllocationColumns([]string{"Namespace", "Bogus"})
+		assert.Error(t, err)
+	})
+}
+
+func Test_WriteTabularCSV(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+
+	as := kubecost.NewAllocationSet(start, end)
+	as.Set(&kubecost.Allocation{
+		Name:    "b",
+		Start:   start,
+		End:     end,
+		CPUCost: 1.5,
+		Properties: &kubecost.AllocationProperties{
+			Namespace: "ns-b",
+			Labels:    map[string]string{"app": "b-app"},
+		},
+	})
+	as.Set(&kubecost.Allocation{
+		Name:    "a",
+		Start:   start,
+		End:     end,
+		RAMCost: 0.25,
+		Properties: &kubecost.AllocationProperties{
+			Namespace: "ns-a",
+		},
+	})
+
+	cols, err := SelectAllocationColumns([]string{"WindowStart", "Name", "Namespace", "label:app", "TotalCost"})
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	err = WriteTabularCSV(buf, cols, AllocationRowsFor(kubecost.NewAllocationSetRange(as)))
+	require.NoError(t, err)
+
+	expected := "WindowStart,Name,Namespace,Label_app,TotalCost\n" +
+		"2023-01-01T00:00:00Z,a,ns-a,,0.25\n" +
+		"2023-01-01T00:00:00Z,b,ns-b,b-app,1.5\n"
+	assert.Equal(t, expected, buf.String())
+}
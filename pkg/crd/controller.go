@@ -0,0 +1,584 @@
+package crd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/opencost/opencost/pkg/cloud/models"
+	"github.com/opencost/opencost/pkg/cloud/utils"
+	"github.com/opencost/opencost/pkg/errors"
+	"github.com/opencost/opencost/pkg/events"
+	"github.com/opencost/opencost/pkg/exporter"
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/log"
+	"github.com/opencost/opencost/pkg/util/atomic"
+	"github.com/opencost/opencost/pkg/util/json"
+)
+
+// ControllerConfig contains the options of a Controller.
+type ControllerConfig struct {
+	// Interval is the duration between reconciliations.
+	Interval time.Duration
+
+	// BudgetWindow is the trailing window whose cost rate is projected over
+	// each budget's period.
+	BudgetWindow time.Duration
+
+	// Resolution is the query resolution used to compute allocations.
+	Resolution time.Duration
+}
+
+// Controller reconciles the CostBudget, CostReport and PricingOverride
+// custom resources, so that budgets, scheduled reports and custom pricing can
+// be managed declaratively. Each reconciliation evaluates every budget,
+// runs the reports which are due and applies the pricing overrides, and
+// records the results in the status of each resource.
+type Controller struct {
+	client   dynamic.Interface
+	source   exporter.Source
+	provider models.Provider
+	notifier *exporter.Notifier
+	config   *ControllerConfig
+
+	// appliedPricing is the merged pricing most recently applied, so that
+	// unchanged overrides are not re-applied
+	appliedPricing map[string]string
+
+	// basePricing is the value of each overridden field before it was first
+	// overridden, to which it is restored once no override sets it
+	basePricing map[string]string
+
+	now func() time.Time
+
+	runState atomic.AtomicRunState
+}
+
+// NewController creates a new Controller. The notifier is optional; if nil,
+// reports are recorded in their status only.
+func NewController(client dynamic.Interface, source exporter.Source, provider models.Provider, notifier *exporter.Notifier, config *ControllerConfig) *Controller {
+	return &Controller{
+		client:   client,
+		source:   source,
+		provider: provider,
+		notifier: notifier,
+		config:   config,
+		now:      time.Now,
+
+		appliedPricing: map[string]string{},
+		basePricing:    map[string]string{},
+	}
+}
+
+// Start begins reconciling at each interval. Returns false if the controller
+// is already running.
+func (c *Controller) Start() bool {
+	c.runState.WaitForReset()
+	if !c.runState.Start() {
+		log.Warnf("CRD controller: attempted to start when already running")
+		return false
+	}
+
+	go func() {
+		defer errors.HandlePanic()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go func() {
+			<-c.runState.OnStop()
+			cancel()
+		}()
+
+		for {
+			if err := c.Reconcile(ctx); err != nil {
+				log.Errorf("CRD controller: %s", err)
+			}
+
+			select {
+			case <-c.runState.OnStop():
+				c.runState.Reset()
+				return
+			case <-time.After(c.config.Interval):
+			}
+		}
+	}()
+
+	return true
+}
+
+// Stop halts the reconciliation loop.
+func (c *Controller) Stop() {
+	c.runState.Stop()
+}
+
+// Reconcile applies the pricing overrides, then evaluates the budgets and
+// runs the due reports. A failure of one kind of resource does not prevent
+// the others from being reconciled.
+func (c *Controller) Reconcile(ctx context.Context) error {
+	var errs []string
+	if err := c.reconcilePricingOverrides(ctx); err != nil {
+		errs = append(errs, fmt.Sprintf("pricing overrides: %s", err))
+	}
+	if err := c.reconcileBudgets(ctx); err != nil {
+		errs = append(errs, fmt.Sprintf("budgets: %s", err))
+	}
+	if err := c.reconcileReports(ctx); err != nil {
+		errs = append(errs, fmt.Sprintf("reports: %s", err))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("reconciling: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (c *Controller) list(ctx context.Context, resource schema.GroupVersionResource) ([]unstructured.Unstructured, error) {
+	list, err := c.client.Resource(resource).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing %s: %w", resource.Resource, err)
+	}
+
+	items := list.Items
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].GetName() < items[j].GetName()
+	})
+	return items, nil
+}
+
+func (c *Controller) updateStatus(ctx context.Context, resource schema.GroupVersionResource, u *unstructured.Unstructured, status interface{}) error {
+	updated, err := withStatus(u, status)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.client.Resource(resource).UpdateStatus(ctx, updated, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("updating status of %s %s: %w", resource.Resource, u.GetName(), err)
+	}
+	return nil
+}
+
+func setCondition(conditions *[]metav1.Condition, generation int64, conditionType string, ok bool, reason, message string) {
+	status := metav1.ConditionFalse
+	if ok {
+		status = metav1.ConditionTrue
+	}
+
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// reconcileBudgets evaluates each CostBudget against the namespace cost rates
+// of the trailing window. Namespaces which begin to breach a budget emit a
+// BudgetBreached event and are sent to the notification channels.
+func (c *Controller) reconcileBudgets(ctx context.Context) error {
+	items, err := c.list(ctx, CostBudgetResource)
+	if err != nil || len(items) == 0 {
+		return err
+	}
+
+	end := c.now().UTC().Truncate(c.config.Resolution)
+	start := end.Add(-c.config.BudgetWindow)
+	window := kubecost.NewClosedWindow(start, end)
+
+	as, err := c.source.ComputeAllocation(start, end, c.config.Resolution)
+	if err != nil {
+		return fmt.Errorf("computing allocations: %w", err)
+	}
+	gauges := exporter.CostGaugesFor(as)
+
+	var errs []string
+	for i := range items {
+		u := &items[i]
+
+		cb := &CostBudget{}
+		if err := fromUnstructured(u, cb); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+
+		status := cb.Status
+		status.ObservedGeneration = cb.Generation
+		now := metav1.NewTime(c.now())
+		status.LastEvaluated = &now
+
+		budget := cb.Budget()
+		if err := budget.Validate(); err != nil {
+			setCondition(&status.Conditions, cb.Generation, ConditionReady, false, "InvalidSpec", err.Error())
+		} else {
+			setCondition(&status.Conditions, cb.Generation, ConditionReady, true, "Valid", "")
+
+			status.ProjectedCost = 0
+			for _, gauge := range gauges {
+				if budget.Matches(gauge) {
+					status.ProjectedCost = math.Max(status.ProjectedCost, gauge.TotalCost*budget.Period.Hours())
+				}
+			}
+
+			breaches := exporter.EvaluateBudgets([]*exporter.Budget{budget}, window, gauges)
+			c.publishNewBreaches(ctx, cb.Status.BreachedNamespaces, breaches)
+
+			status.BreachedNamespaces = nil
+			for _, breach := range breaches {
+				status.BreachedNamespaces = append(status.BreachedNamespaces, breach.Cluster+"/"+breach.Namespace)
+			}
+
+			if len(breaches) > 0 {
+				setCondition(&status.Conditions, cb.Generation, ConditionBreached, true, "LimitExceeded",
+					fmt.Sprintf("%d namespaces are projected to exceed %.2f per %s", len(breaches), budget.Limit, budget.Period))
+			} else {
+				setCondition(&status.Conditions, cb.Generation, ConditionBreached, false, "WithinLimit", "")
+			}
+		}
+
+		if err := c.updateStatus(ctx, CostBudgetResource, u, status); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// publishNewBreaches publishes the breaches of namespaces which were not
+// breaching at the previous evaluation.
+func (c *Controller) publishNewBreaches(ctx context.Context, previous []string, breaches []*exporter.BudgetBreach) {
+	active := make(map[string]bool, len(previous))
+	for _, key := range previous {
+		active[key] = true
+	}
+
+	var newBreaches []*exporter.BudgetBreach
+	for _, breach := range breaches {
+		if !active[breach.Cluster+"/"+breach.Namespace] {
+			newBreaches = append(newBreaches, breach)
+			events.Emit(events.BudgetBreached, breach.Namespace, breach.Event())
+		}
+	}
+
+	if c.notifier != nil && len(newBreaches) > 0 {
+		if err := c.notifier.PublishBudgetBreaches(ctx, newBreaches); err != nil {
+			log.Errorf("CRD controller: failed to publish budget breaches: %s", err)
+		}
+	}
+}
+
+// reconcileReports runs each CostReport whose next run time has passed.
+func (c *Controller) reconcileReports(ctx context.Context) error {
+	items, err := c.list(ctx, CostReportResource)
+	if err != nil {
+		return err
+	}
+
+	var errs []string
+	for i := range items {
+		u := &items[i]
+
+		cr := &CostReport{}
+		if err := fromUnstructured(u, cr); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+
+		now := c.now().UTC()
+		status := cr.Status
+		status.ObservedGeneration = cr.Generation
+
+		if err := validateReport(&cr.Spec); err != nil {
+			setCondition(&status.Conditions, cr.Generation, ConditionReady, false, "InvalidSpec", err.Error())
+		} else {
+			setCondition(&status.Conditions, cr.Generation, ConditionReady, true, "Valid", "")
+
+			// reports run at interval boundaries, once the interval is complete
+			interval := cr.Spec.Interval.Duration
+			if status.NextRunTime == nil || cr.Status.ObservedGeneration != cr.Generation {
+				next := metav1.NewTime(now.Truncate(interval).Add(interval))
+				status.NextRunTime = &next
+			}
+
+			if !now.Before(status.NextRunTime.Time) {
+				end := now.Truncate(interval)
+				if err := c.runReport(ctx, cr, end, &status); err != nil {
+					setCondition(&status.Conditions, cr.Generation, ConditionSucceeded, false, "ReportFailed", err.Error())
+				} else {
+					setCondition(&status.Conditions, cr.Generation, ConditionSucceeded, true, "ReportSent", "")
+				}
+
+				last := metav1.NewTime(now)
+				next := metav1.NewTime(end.Add(interval))
+				status.LastRunTime = &last
+				status.NextRunTime = &next
+			}
+		}
+
+		if reflect.DeepEqual(status, cr.Status) {
+			continue
+		}
+		if err := c.updateStatus(ctx, CostReportResource, u, status); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func validateReport(spec *CostReportSpec) error {
+	switch spec.Type {
+	case "":
+		spec.Type = CostReportTypeAllocation
+	case CostReportTypeAllocation, CostReportTypeSavings:
+	default:
+		return fmt.Errorf("unsupported type '%s'", spec.Type)
+	}
+	if spec.Interval.Duration < time.Hour {
+		return fmt.Errorf("interval must be at least 1h")
+	}
+	if spec.Top <= 0 {
+		spec.Top = 10
+	}
+	if spec.Aggregate == "" {
+		spec.Aggregate = kubecost.AllocationNamespaceProp
+	}
+	_, err := parseAggregate(spec.Aggregate)
+	return err
+}
+
+func parseAggregate(aggregate string) ([]string, error) {
+	var aggregateBy []string
+	for _, agg := range strings.Split(aggregate, ",") {
+		agg = strings.TrimSpace(agg)
+		if prop, err := kubecost.ParseProperty(agg); err == nil {
+			aggregateBy = append(aggregateBy, prop)
+		} else if strings.HasPrefix(agg, "label:") || strings.HasPrefix(agg, "annotation:") {
+			aggregateBy = append(aggregateBy, agg)
+		} else {
+			return nil, fmt.Errorf("invalid aggregate '%s'", agg)
+		}
+	}
+	return aggregateBy, nil
+}
+
+// runReport computes the report for the interval ending at end, records it in
+// the status and sends it to the notification channels.
+func (c *Controller) runReport(ctx context.Context, cr *CostReport, end time.Time, status *CostReportStatus) error {
+	start := end.Add(-cr.Spec.Interval.Duration)
+
+	as, err := c.source.ComputeAllocation(start, end, c.config.Resolution)
+	if err != nil {
+		return fmt.Errorf("computing allocations: %w", err)
+	}
+
+	var notification *exporter.Notification
+	switch cr.Spec.Type {
+	case CostReportTypeSavings:
+		report := exporter.NewSavingsReport(as, cr.Spec.Top)
+		status.Total = report.Total
+		status.Entries = nil
+		for _, ws := range report.Workloads {
+			status.Entries = append(status.Entries, CostReportEntry{
+				Name: fmt.Sprintf("%s/%s %s/%s", ws.Cluster, ws.Namespace, ws.ControllerKind, ws.Controller),
+				Cost: ws.TotalSavings(),
+			})
+		}
+		notification = exporter.NewSavingsReportNotification(report)
+	default:
+		aggregateBy, _ := parseAggregate(cr.Spec.Aggregate)
+		if err := as.AggregateBy(aggregateBy, nil); err != nil {
+			return fmt.Errorf("aggregating allocations: %w", err)
+		}
+
+		var entries []CostReportEntry
+		for name, alloc := range as.Allocations {
+			entries = append(entries, CostReportEntry{Name: name, Cost: alloc.TotalCost()})
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].Cost > entries[j].Cost
+		})
+		if len(entries) > cr.Spec.Top {
+			entries = entries[:cr.Spec.Top]
+		}
+
+		status.Total = as.TotalCost()
+		status.Entries = entries
+		notification = newCostReportNotification(cr, as.Window, status)
+	}
+	status.Window = kubecost.NewClosedWindow(start, end).String()
+
+	if c.notifier == nil {
+		return nil
+	}
+	return c.notifier.Notify(ctx, notification)
+}
+
+func newCostReportNotification(cr *CostReport, window kubecost.Window, status *CostReportStatus) *exporter.Notification {
+	var text strings.Builder
+	fmt.Fprintf(&text, "Total cost from %s to %s: %.2f\n",
+		window.Start().UTC().Format(time.RFC3339), window.End().UTC().Format(time.RFC3339), status.Total)
+	for _, entry := range status.Entries {
+		fmt.Fprintf(&text, "- %s: %.2f\n", entry.Name, entry.Cost)
+	}
+
+	return &exporter.Notification{
+		Kind:  exporter.NotificationKindCostReport,
+		Title: fmt.Sprintf("OpenCost report %s", cr.Name),
+		Text:  text.String(),
+		Data:  status,
+	}
+}
+
+// reconcilePricingOverrides merges the PricingOverrides in order of name and
+// applies the fields which changed to the cloud provider's custom pricing.
+// Fields which are no longer set by any override are restored to their value
+// before they were first overridden.
+func (c *Controller) reconcilePricingOverrides(ctx context.Context) error {
+	items, err := c.list(ctx, PricingOverrideResource)
+	if err != nil {
+		return err
+	}
+
+	overrides := make([]*PricingOverride, 0, len(items))
+	merged := make(map[string]string)
+	var errs []string
+	for i := range items {
+		po := &PricingOverride{}
+		if err := fromUnstructured(&items[i], po); err != nil {
+			errs = append(errs, err.Error())
+			overrides = append(overrides, nil)
+			continue
+		}
+		overrides = append(overrides, po)
+
+		for k, v := range po.Spec.Pricing {
+			merged[k] = v
+		}
+	}
+
+	update, restore, applyErr := c.pricingUpdate(merged)
+	changed := applyErr == nil && (len(update) > 0 || len(restore) > 0)
+	if changed {
+		if applyErr = c.applyPricing(update, restore); applyErr == nil {
+			c.appliedPricing = merged
+			for k := range restore {
+				delete(c.basePricing, k)
+			}
+			log.Infof("CRD controller: applied %d pricing overrides, restoring %d fields", len(items), len(restore))
+		}
+	}
+
+	for i, po := range overrides {
+		if po == nil {
+			continue
+		}
+
+		status := po.Status
+		status.ObservedGeneration = po.Generation
+		if applyErr != nil {
+			setCondition(&status.Conditions, po.Generation, ConditionApplied, false, "ApplyFailed", applyErr.Error())
+		} else {
+			setCondition(&status.Conditions, po.Generation, ConditionApplied, true, "Applied", "")
+			if changed || status.LastApplied == nil {
+				now := metav1.NewTime(c.now())
+				status.LastApplied = &now
+			}
+		}
+
+		if reflect.DeepEqual(status, po.Status) {
+			continue
+		}
+		if err := c.updateStatus(ctx, PricingOverrideResource, &items[i], status); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if applyErr != nil {
+		errs = append(errs, fmt.Sprintf("applying pricing: %s", applyErr))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// applyPricing applies the overridden fields to the provider's custom pricing,
+// and restores the fields which are no longer overridden. Overrides are priced
+// as in the pricing ConfigMap, e.g. CPU per month, while the base values are as
+// stored, e.g. CPU per hour, so are set as they are rather than converted.
+func (c *Controller) applyPricing(update, restore map[string]string) error {
+	if len(update) > 0 {
+		if _, err := c.provider.UpdateConfigFromConfigMap(update); err != nil {
+			return err
+		}
+	}
+	if len(restore) > 0 {
+		b, err := json.Marshal(restore)
+		if err != nil {
+			return err
+		}
+		if _, err := c.provider.UpdateConfig(bytes.NewReader(b), ""); err != nil {
+			return fmt.Errorf("restoring base pricing: %w", err)
+		}
+	}
+	return nil
+}
+
+// pricingUpdate returns the fields of the merged pricing which differ from the
+// applied pricing, and the base value of each field which is no longer
+// overridden. The base value of each newly overridden field is recorded from
+// the provider's custom pricing.
+func (c *Controller) pricingUpdate(merged map[string]string) (map[string]string, map[string]string, error) {
+	update := make(map[string]string)
+	for k, v := range merged {
+		if applied, ok := c.appliedPricing[k]; ok && applied == v {
+			continue
+		}
+		update[k] = v
+	}
+
+	var cp *models.CustomPricing
+	for k := range update {
+		if _, ok := c.basePricing[k]; ok {
+			continue
+		}
+		if cp == nil {
+			var err error
+			if cp, err = c.provider.GetConfig(); err != nil {
+				return nil, nil, fmt.Errorf("getting custom pricing: %w", err)
+			}
+		}
+		// Fields which do not exist fail to apply, so have no base value
+		if base, err := models.GetCustomPricingField(cp, utils.ToTitle.String(k)); err == nil {
+			c.basePricing[k] = base
+		}
+	}
+
+	restore := make(map[string]string)
+	for k, base := range c.basePricing {
+		if _, ok := merged[k]; !ok {
+			restore[k] = base
+		}
+	}
+
+	return update, restore, nil
+}
@@ -0,0 +1,329 @@
+package crd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+
+	"github.com/opencost/opencost/pkg/cloud/models"
+	"github.com/opencost/opencost/pkg/cloud/utils"
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/util/json"
+)
+
+type mockSource struct{}
+
+func (mockSource) ComputeAllocation(start, end time.Time, resolution time.Duration) (*kubecost.AllocationSet, error) {
+	as := kubecost.NewAllocationSet(start, end)
+	as.Set(kubecost.NewMockUnitAllocation("", start, end.Sub(start), nil))
+	return as, nil
+}
+
+func (mockSource) ComputeAssets(start, end time.Time) (*kubecost.AssetSet, error) {
+	return kubecost.NewAssetSet(start, end), nil
+}
+
+func newCostBudget(name, namespace string, limit float64) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": Group + "/" + Version,
+		"kind":       "CostBudget",
+		"metadata": map[string]interface{}{
+			"name": name,
+		},
+		"spec": map[string]interface{}{
+			"namespace": namespace,
+			"limit":     limit,
+			"period":    "month",
+		},
+	}}
+}
+
+func TestController_ReconcileBudgets(t *testing.T) {
+	ctx := context.Background()
+
+	client := newFakeClient(newCostBudget("breached", "namespace1", 1), newCostBudget("within", "namespace2", 1))
+
+	c := NewController(client, mockSource{}, nil, nil, &ControllerConfig{
+		Interval:     time.Minute,
+		BudgetWindow: time.Hour,
+		Resolution:   time.Hour,
+	})
+	c.now = func() time.Time { return time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+	if err := c.Reconcile(ctx); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := map[string]metav1.ConditionStatus{
+		"breached": metav1.ConditionTrue,
+		"within":   metav1.ConditionFalse,
+	}
+	for name, breached := range expected {
+		u, err := client.Resource(CostBudgetResource).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		cb := &CostBudget{}
+		if err := fromUnstructured(u, cb); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if !meta.IsStatusConditionTrue(cb.Status.Conditions, ConditionReady) {
+			t.Errorf("%s: expected Ready condition", name)
+		}
+		condition := meta.FindStatusCondition(cb.Status.Conditions, ConditionBreached)
+		if condition == nil || condition.Status != breached {
+			t.Errorf("%s: expected Breached condition %s; got %v", name, breached, condition)
+		}
+	}
+}
+
+func newFakeClient(objects ...runtime.Object) *fake.FakeDynamicClient {
+	return fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		CostBudgetResource:      "CostBudgetList",
+		CostReportResource:      "CostReportList",
+		PricingOverrideResource: "PricingOverrideList",
+	}, objects...)
+}
+
+func newCostReport(name string, generation int64, interval string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": Group + "/" + Version,
+		"kind":       "CostReport",
+		"metadata": map[string]interface{}{
+			"name":       name,
+			"generation": generation,
+		},
+		"spec": map[string]interface{}{
+			"interval": interval,
+		},
+	}}
+}
+
+func getCostReport(t *testing.T, client *fake.FakeDynamicClient, name string) (*unstructured.Unstructured, *CostReport) {
+	t.Helper()
+
+	u, err := client.Resource(CostReportResource).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	cr := &CostReport{}
+	if err := fromUnstructured(u, cr); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return u, cr
+}
+
+func TestController_ReconcileReports(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeClient()
+
+	c := NewController(client, mockSource{}, nil, nil, &ControllerConfig{
+		Interval:     time.Minute,
+		BudgetWindow: time.Hour,
+		Resolution:   time.Hour,
+	})
+	now := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	// a created report is scheduled for the end of the current interval
+	if _, err := client.Resource(CostReportResource).Create(ctx, newCostReport("daily", 1, "24h"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := c.Reconcile(ctx); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	_, cr := getCostReport(t, client, "daily")
+	if !meta.IsStatusConditionTrue(cr.Status.Conditions, ConditionReady) {
+		t.Errorf("expected Ready condition")
+	}
+	if cr.Status.LastRunTime != nil || !cr.Status.NextRunTime.Time.Equal(time.Date(2023, 3, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the first run at the end of the day; got %v", cr.Status.NextRunTime)
+	}
+
+	// once due, the report runs over the completed interval
+	now = time.Date(2023, 3, 2, 0, 30, 0, 0, time.UTC)
+	if err := c.Reconcile(ctx); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	u, cr := getCostReport(t, client, "daily")
+	if !meta.IsStatusConditionTrue(cr.Status.Conditions, ConditionSucceeded) {
+		t.Errorf("expected Succeeded condition")
+	}
+	if cr.Status.LastRunTime == nil || len(cr.Status.Entries) != 1 || cr.Status.Total <= 0 {
+		t.Errorf("expected a run with 1 entry; got %+v", cr.Status)
+	}
+	if !cr.Status.NextRunTime.Time.Equal(time.Date(2023, 3, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the next run at the end of the next day; got %v", cr.Status.NextRunTime)
+	}
+
+	// an updated interval reschedules the report
+	unstructured.SetNestedField(u.Object, "1h", "spec", "interval")
+	u.SetGeneration(2)
+	if _, err := client.Resource(CostReportResource).Update(ctx, u, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := c.Reconcile(ctx); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	u, cr = getCostReport(t, client, "daily")
+	if cr.Status.ObservedGeneration != 2 || !cr.Status.NextRunTime.Time.Equal(time.Date(2023, 3, 2, 1, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the next run at the end of the hour; got %v", cr.Status.NextRunTime)
+	}
+
+	// an invalid interval is reported
+	unstructured.SetNestedField(u.Object, "10m", "spec", "interval")
+	u.SetGeneration(3)
+	if _, err := client.Resource(CostReportResource).Update(ctx, u, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := c.Reconcile(ctx); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	_, cr = getCostReport(t, client, "daily")
+	if meta.IsStatusConditionTrue(cr.Status.Conditions, ConditionReady) {
+		t.Errorf("expected Ready condition to be false for an invalid interval")
+	}
+
+	// a deleted report is no longer reconciled
+	if err := client.Resource(CostReportResource).Delete(ctx, "daily", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := c.Reconcile(ctx); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+type pricingProvider struct {
+	models.Provider
+	pricing *models.CustomPricing
+}
+
+func (pp *pricingProvider) GetConfig() (*models.CustomPricing, error) {
+	return pp.pricing, nil
+}
+
+// UpdateConfigFromConfigMap converts the monthly prices of the resources to
+// hourly prices, as ProviderConfig.UpdateFromMap does.
+func (pp *pricingProvider) UpdateConfigFromConfigMap(update map[string]string) (*models.CustomPricing, error) {
+	for k, v := range update {
+		field := utils.ToTitle.String(k)
+		switch field {
+		case "CPU", "SpotCPU", "RAM", "SpotRAM", "GPU", "Storage":
+			val, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, err
+			}
+			v = fmt.Sprintf("%f", val/730)
+		}
+		if err := models.SetCustomPricingField(pp.pricing, field, v); err != nil {
+			return nil, err
+		}
+	}
+	return pp.pricing, nil
+}
+
+// UpdateConfig sets the fields as they are, as the providers do for updates
+// without a type.
+func (pp *pricingProvider) UpdateConfig(r io.Reader, updateType string) (*models.CustomPricing, error) {
+	update := map[string]string{}
+	if err := json.NewDecoder(r).Decode(&update); err != nil {
+		return nil, err
+	}
+	for k, v := range update {
+		if err := models.SetCustomPricingField(pp.pricing, utils.ToTitle.String(k), v); err != nil {
+			return nil, err
+		}
+	}
+	return pp.pricing, nil
+}
+
+func newPricingOverride(name string, pricing map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": Group + "/" + Version,
+		"kind":       "PricingOverride",
+		"metadata": map[string]interface{}{
+			"name": name,
+		},
+		"spec": map[string]interface{}{
+			"pricing": pricing,
+		},
+	}}
+}
+
+func TestController_ReconcilePricingOverrides(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeClient()
+
+	provider := &pricingProvider{pricing: &models.CustomPricing{CPU: "0.03", RAM: "0.004"}}
+	c := NewController(client, mockSource{}, provider, nil, &ControllerConfig{
+		Interval:     time.Minute,
+		BudgetWindow: time.Hour,
+		Resolution:   time.Hour,
+	})
+
+	reconcile := func(cpu, ram string) {
+		t.Helper()
+
+		if err := c.reconcilePricingOverrides(ctx); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if provider.pricing.CPU != cpu || provider.pricing.RAM != ram {
+			t.Errorf("expected CPU %s and RAM %s; got %s and %s", cpu, ram, provider.pricing.CPU, provider.pricing.RAM)
+		}
+	}
+
+	// later names take precedence for the same field, and are priced per
+	// month, as in the pricing ConfigMap
+	for _, u := range []*unstructured.Unstructured{
+		newPricingOverride("a", map[string]interface{}{"CPU": "36.5"}),
+		newPricingOverride("b", map[string]interface{}{"CPU": "43.8", "RAM": "7.3"}),
+	} {
+		if _, err := client.Resource(PricingOverrideResource).Create(ctx, u, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	reconcile("0.060000", "0.010000")
+
+	u, err := client.Resource(PricingOverrideResource).Get(ctx, "b", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	po := &PricingOverride{}
+	if err := fromUnstructured(u, po); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !meta.IsStatusConditionTrue(po.Status.Conditions, ConditionApplied) || po.Status.LastApplied == nil {
+		t.Errorf("expected Applied condition")
+	}
+
+	// a field no longer overridden is restored to its hourly price as it was
+	unstructured.SetNestedStringMap(u.Object, map[string]string{"CPU": "51.1"}, "spec", "pricing")
+	if _, err := client.Resource(PricingOverrideResource).Update(ctx, u, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	reconcile("0.070000", "0.004")
+
+	// without overrides, the base pricing is restored
+	for _, name := range []string{"a", "b"} {
+		if err := client.Resource(PricingOverrideResource).Delete(ctx, name, metav1.DeleteOptions{}); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	reconcile("0.03", "0.004")
+
+	// pricing changed since is not overwritten
+	provider.pricing.CPU = "0.04"
+	reconcile("0.04", "0.004")
+}
@@ -0,0 +1,197 @@
+package crd
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/opencost/opencost/pkg/exporter"
+	"github.com/opencost/opencost/pkg/util/json"
+)
+
+// Group and Version of the OpenCost custom resources, whose definitions are
+// in kubernetes/crds.yaml.
+const (
+	Group   = "opencost.io"
+	Version = "v1alpha1"
+)
+
+var (
+	CostBudgetResource      = schema.GroupVersionResource{Group: Group, Version: Version, Resource: "costbudgets"}
+	CostReportResource      = schema.GroupVersionResource{Group: Group, Version: Version, Resource: "costreports"}
+	PricingOverrideResource = schema.GroupVersionResource{Group: Group, Version: Version, Resource: "pricingoverrides"}
+)
+
+// Condition types set on the status of the custom resources
+const (
+	// ConditionReady is true if the spec is valid.
+	ConditionReady = "Ready"
+
+	// ConditionBreached is true if a namespace selected by a CostBudget is
+	// projected to exceed its limit.
+	ConditionBreached = "Breached"
+
+	// ConditionSucceeded is true if the last run of a CostReport succeeded.
+	ConditionSucceeded = "Succeeded"
+
+	// ConditionApplied is true if a PricingOverride is applied to the
+	// cloud provider's custom pricing.
+	ConditionApplied = "Applied"
+)
+
+// CostBudget is a cluster-scoped budget limiting the projected spend of the
+// namespaces it selects.
+type CostBudget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CostBudgetSpec   `json:"spec"`
+	Status CostBudgetStatus `json:"status,omitempty"`
+}
+
+// CostBudgetSpec selects namespaces by cluster, namespace and labels; empty
+// fields match everything.
+type CostBudgetSpec struct {
+	Cluster   string                `json:"cluster,omitempty"`
+	Namespace string                `json:"namespace,omitempty"`
+	Labels    map[string]string     `json:"labels,omitempty"`
+	Limit     float64               `json:"limit"`
+	Period    exporter.BudgetPeriod `json:"period,omitempty"`
+}
+
+// CostBudgetStatus is the result of the last evaluation of a CostBudget.
+type CostBudgetStatus struct {
+	ObservedGeneration int64        `json:"observedGeneration,omitempty"`
+	LastEvaluated      *metav1.Time `json:"lastEvaluated,omitempty"`
+
+	// ProjectedCost is the highest projected spend for the period among the
+	// selected namespaces.
+	ProjectedCost float64 `json:"projectedCost"`
+
+	// BreachedNamespaces are the cluster/namespace pairs projected to exceed
+	// the limit.
+	BreachedNamespaces []string `json:"breachedNamespaces,omitempty"`
+
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// Budget returns the exporter budget evaluated for the CostBudget.
+func (cb *CostBudget) Budget() *exporter.Budget {
+	return &exporter.Budget{
+		Name:      cb.Name,
+		Cluster:   cb.Spec.Cluster,
+		Namespace: cb.Spec.Namespace,
+		Labels:    cb.Spec.Labels,
+		Limit:     cb.Spec.Limit,
+		Period:    cb.Spec.Period,
+	}
+}
+
+// CostReport types
+const (
+	CostReportTypeAllocation = "allocation"
+	CostReportTypeSavings    = "savings"
+)
+
+// CostReport is a cluster-scoped report computed on a schedule, recorded in
+// its status and sent to the configured notification channels.
+type CostReport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CostReportSpec   `json:"spec"`
+	Status CostReportStatus `json:"status,omitempty"`
+}
+
+// CostReportSpec is the type, schedule and contents of a CostReport.
+type CostReportSpec struct {
+	// Type is "allocation", listing the top aggregates by total cost, or
+	// "savings", listing the top workloads by right-sizing savings.
+	Type string `json:"type,omitempty"`
+
+	// Interval is the duration between reports, which is also the window
+	// each report covers, e.g. "24h".
+	Interval metav1.Duration `json:"interval"`
+
+	// Aggregate is the comma-separated properties allocation reports are
+	// aggregated by, e.g. "namespace" or "cluster,label:team".
+	Aggregate string `json:"aggregate,omitempty"`
+
+	// Top is the number of entries listed in each report.
+	Top int `json:"top,omitempty"`
+}
+
+// CostReportEntry is a line of a report.
+type CostReportEntry struct {
+	Name string  `json:"name"`
+	Cost float64 `json:"cost"`
+}
+
+// CostReportStatus is the result of the last run of a CostReport.
+type CostReportStatus struct {
+	ObservedGeneration int64             `json:"observedGeneration,omitempty"`
+	LastRunTime        *metav1.Time      `json:"lastRunTime,omitempty"`
+	NextRunTime        *metav1.Time      `json:"nextRunTime,omitempty"`
+	Window             string            `json:"window,omitempty"`
+	Total              float64           `json:"total"`
+	Entries            []CostReportEntry `json:"entries,omitempty"`
+
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// PricingOverride is a cluster-scoped set of custom pricing fields, e.g.
+// "CPU" or "spotRAM", applied to the cloud provider's custom pricing in
+// place of the pricing ConfigMap. Overrides are applied in order of name, so
+// later names take precedence for the same field.
+type PricingOverride struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PricingOverrideSpec   `json:"spec"`
+	Status PricingOverrideStatus `json:"status,omitempty"`
+}
+
+// PricingOverrideSpec contains the custom pricing fields to set.
+type PricingOverrideSpec struct {
+	Pricing map[string]string `json:"pricing"`
+}
+
+// PricingOverrideStatus is the result of the last application of a
+// PricingOverride.
+type PricingOverrideStatus struct {
+	ObservedGeneration int64        `json:"observedGeneration,omitempty"`
+	LastApplied        *metav1.Time `json:"lastApplied,omitempty"`
+
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// fromUnstructured decodes a custom resource into its typed struct.
+func fromUnstructured(u *unstructured.Unstructured, into interface{}) error {
+	b, err := json.Marshal(u.Object)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, into); err != nil {
+		return fmt.Errorf("decoding %s %s: %w", u.GetKind(), u.GetName(), err)
+	}
+	return nil
+}
+
+// withStatus returns a copy of the custom resource with the status replaced.
+func withStatus(u *unstructured.Unstructured, status interface{}) (*unstructured.Unstructured, error) {
+	b, err := json.Marshal(status)
+	if err != nil {
+		return nil, err
+	}
+
+	var s map[string]interface{}
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, err
+	}
+
+	updated := u.DeepCopy()
+	updated.Object["status"] = s
+	return updated, nil
+}
@@ -0,0 +1,174 @@
+package currency
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opencost/opencost/pkg/log"
+)
+
+var (
+	// ErrUnknownCurrency is returned when converting from or to a code which
+	// is not an ISO 4217 currency code.
+	ErrUnknownCurrency = errors.New("unknown currency")
+
+	// ErrRatesUnavailable is returned when the rate source has not provided a
+	// rate for a conversion.
+	ErrRatesUnavailable = errors.New("exchange rates unavailable")
+)
+
+// Rates are the exchange rates of currencies against a base currency, as the
+// amount of each currency equal to one unit of the base currency.
+type Rates struct {
+	Base  string
+	Rates map[string]float64
+	Date  time.Time
+}
+
+// Rate returns the amount of currency "to" equal to one unit of currency
+// "from", crossing through the base currency if neither is the base.
+func (r *Rates) Rate(from, to string) (float64, error) {
+	fromRate, err := r.rate(from)
+	if err != nil {
+		return 0, err
+	}
+	toRate, err := r.rate(to)
+	if err != nil {
+		return 0, err
+	}
+	return toRate / fromRate, nil
+}
+
+func (r *Rates) rate(code string) (float64, error) {
+	code = Normalize(code)
+	if code == Normalize(r.Base) {
+		return 1, nil
+	}
+	rate, ok := r.Rates[code]
+	if !ok || rate <= 0 {
+		return 0, fmt.Errorf("%w: no exchange rate for %s against %s", ErrRatesUnavailable, code, r.Base)
+	}
+	return rate, nil
+}
+
+// RateSource is a source of exchange rates.
+type RateSource interface {
+	// Name identifies the source of the rates, as reported alongside
+	// converted amounts.
+	Name() string
+	// Rates returns the latest exchange rates of the source.
+	Rates() (*Rates, error)
+}
+
+// Conversion describes the conversion of amounts from the currency in which
+// they were priced to the currency in which they are displayed. The original
+// amount of any converted amount is the converted amount divided by the rate.
+type Conversion struct {
+	Code         string    `json:"code"`
+	OriginalCode string    `json:"originalCode"`
+	Rate         float64   `json:"rate"`
+	RateSource   string    `json:"rateSource,omitempty"`
+	RateDate     time.Time `json:"rateDate,omitempty"`
+}
+
+// IsIdentity returns true if the conversion leaves amounts unchanged.
+func (c *Conversion) IsIdentity() bool {
+	return c.Rate == 1
+}
+
+// Normalize returns the ISO 4217 form of a currency code.
+func Normalize(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Converter converts amounts between currencies using the rates of a source,
+// which are refreshed on use once older than the refresh interval. If the
+// source fails, the last rates retrieved continue to be used, as they are while
+// a refresh is in progress.
+type Converter struct {
+	source     RateSource
+	refresh    time.Duration
+	lock       sync.Mutex
+	rates      *Rates
+	fetched    time.Time
+	refreshing bool
+}
+
+// NewConverter creates a Converter using the rates of the source, refreshed at
+// the given interval.
+func NewConverter(source RateSource, refresh time.Duration) *Converter {
+	return &Converter{
+		source:  source,
+		refresh: refresh,
+	}
+}
+
+// Convert returns the conversion of amounts from one currency to another. The
+// error is ErrUnknownCurrency if either is not a currency code, or
+// ErrRatesUnavailable if no rate could be retrieved from the source.
+func (c *Converter) Convert(from, to string) (*Conversion, error) {
+	from, to = Normalize(from), Normalize(to)
+	for _, code := range []string{from, to} {
+		if !IsCode(code) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownCurrency, code)
+		}
+	}
+	if from == to {
+		return &Conversion{Code: to, OriginalCode: from, Rate: 1}, nil
+	}
+
+	rates, err := c.currentRates()
+	if err != nil {
+		return nil, err
+	}
+
+	rate, err := rates.Rate(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Conversion{
+		Code:         to,
+		OriginalCode: from,
+		Rate:         rate,
+		RateSource:   c.source.Name(),
+		RateDate:     rates.Date,
+	}, nil
+}
+
+// currentRates returns the rates of the source, refreshing them if they are
+// older than the refresh interval. The source is not called under the lock, so
+// that conversions are not blocked on a slow source while there are rates.
+func (c *Converter) currentRates() (*Rates, error) {
+	c.lock.Lock()
+	if c.rates != nil && (c.refreshing || time.Since(c.fetched) < c.refresh) {
+		rates := c.rates
+		c.lock.Unlock()
+		return rates, nil
+	}
+	c.refreshing = true
+	c.lock.Unlock()
+
+	rates, err := c.source.Rates()
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.refreshing = false
+	if err != nil {
+		if c.rates == nil {
+			return nil, fmt.Errorf("%w: retrieving exchange rates from %s: %s", ErrRatesUnavailable, c.source.Name(), err)
+		}
+		log.Warnf("Currency: failed to refresh exchange rates from %s, using rates of %s: %s", c.source.Name(), c.rates.Date.Format("2006-01-02"), err)
+		// Retry no sooner than the next refresh
+		c.fetched = time.Now()
+		return c.rates, nil
+	}
+
+	c.rates = rates
+	c.fetched = time.Now()
+	return c.rates, nil
+}
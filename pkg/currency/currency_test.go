@@ -0,0 +1,180 @@
+package currency
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const ecbDaily = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<gesmes:Sender>
+		<gesmes:name>European Central Bank</gesmes:name>
+	</gesmes:Sender>
+	<Cube>
+		<Cube time="2023-01-02">
+			<Cube currency="USD" rate="1.0678"/>
+			<Cube currency="JPY" rate="139.83"/>
+			<Cube currency="GBP" rate="0.88433"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestECBSource_Rates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(ecbDaily))
+	}))
+	defer server.Close()
+
+	source := NewECBSource()
+	source.URL = server.URL
+
+	rates, err := source.Rates()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if rates.Base != "EUR" {
+		t.Errorf("expected base EUR; got %s", rates.Base)
+	}
+	if !rates.Date.Equal(time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected date 2023-01-02; got %s", rates.Date)
+	}
+	if len(rates.Rates) != 3 || rates.Rates["USD"] != 1.0678 {
+		t.Errorf("expected 3 rates with USD 1.0678; got %v", rates.Rates)
+	}
+
+	// crossing through the base currency
+	rate, err := rates.Rate("usd", "GBP")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !approxEqual(rate, 0.88433/1.0678) {
+		t.Errorf("expected USD to GBP rate %f; got %f", 0.88433/1.0678, rate)
+	}
+
+	if _, err := rates.Rate("USD", "XYZ"); err == nil {
+		t.Errorf("expected error converting to an unknown currency")
+	}
+}
+
+func TestParseStaticRates(t *testing.T) {
+	source, err := ParseStaticRates("USD", "eur=0.92, GBP=0.79")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	rates, _ := source.Rates()
+	if rates.Rates["EUR"] != 0.92 || rates.Rates["GBP"] != 0.79 {
+		t.Errorf("unexpected rates: %v", rates.Rates)
+	}
+
+	for _, s := range []string{"EUR", "EUR=abc", "EUR=-1"} {
+		if _, err := ParseStaticRates("USD", s); err == nil {
+			t.Errorf("%s: expected error", s)
+		}
+	}
+}
+
+type failingSource struct {
+	fail  bool
+	calls int
+}
+
+func (fs *failingSource) Name() string { return "failing" }
+
+func (fs *failingSource) Rates() (*Rates, error) {
+	fs.calls++
+	if fs.fail {
+		return nil, errors.New("unavailable")
+	}
+	return &Rates{Base: "USD", Rates: map[string]float64{"EUR": 0.9}}, nil
+}
+
+func TestConverter_Convert(t *testing.T) {
+	source := &failingSource{}
+	c := NewConverter(source, 0)
+
+	conv, err := c.Convert("USD", "USD")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !conv.IsIdentity() || source.calls != 0 {
+		t.Errorf("expected identity conversion without retrieving rates")
+	}
+
+	conv, err = c.Convert("USD", "eur")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if conv.Code != "EUR" || conv.OriginalCode != "USD" || conv.Rate != 0.9 || conv.RateSource != "failing" {
+		t.Errorf("unexpected conversion: %+v", conv)
+	}
+
+	// the last rates are used when the source fails
+	source.fail = true
+	conv, err = c.Convert("EUR", "USD")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !approxEqual(conv.Rate, 1/0.9) {
+		t.Errorf("expected rate %f; got %f", 1/0.9, conv.Rate)
+	}
+
+	if _, err := NewConverter(source, time.Hour).Convert("USD", "EUR"); !errors.Is(err, ErrRatesUnavailable) {
+		t.Errorf("expected rates unavailable without any rates; got %v", err)
+	}
+
+	if _, err := c.Convert("USD", "XYZ"); !errors.Is(err, ErrUnknownCurrency) {
+		t.Errorf("expected unknown currency; got %v", err)
+	}
+}
+
+type blockingSource struct {
+	calls   chan struct{}
+	release chan struct{}
+}
+
+func (bs *blockingSource) Name() string { return "blocking" }
+
+func (bs *blockingSource) Rates() (*Rates, error) {
+	bs.calls <- struct{}{}
+	<-bs.release
+	return &Rates{Base: "USD", Rates: map[string]float64{"EUR": 0.8}}, nil
+}
+
+func TestConverter_Convert_Refreshing(t *testing.T) {
+	source := &blockingSource{calls: make(chan struct{}, 2), release: make(chan struct{})}
+	c := NewConverter(source, time.Hour)
+	c.rates = &Rates{Base: "USD", Rates: map[string]float64{"EUR": 0.9}}
+
+	refreshed := make(chan *Conversion)
+	go func() {
+		conv, _ := c.Convert("USD", "EUR")
+		refreshed <- conv
+	}()
+	<-source.calls
+
+	// conversions use the last rates while the source is slow to respond
+	conv, err := c.Convert("USD", "EUR")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if conv.Rate != 0.9 {
+		t.Errorf("expected the last rate 0.9 during a refresh; got %f", conv.Rate)
+	}
+
+	close(source.release)
+	if conv := <-refreshed; conv.Rate != 0.8 {
+		t.Errorf("expected the refreshed rate 0.8; got %f", conv.Rate)
+	}
+	if len(source.calls) != 0 {
+		t.Errorf("expected a single refresh")
+	}
+}
@@ -0,0 +1,155 @@
+package currency
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StaticSource is a RateSource serving a fixed table of rates.
+type StaticSource struct {
+	rates *Rates
+}
+
+// NewStaticSource creates a StaticSource of the rates against the base.
+func NewStaticSource(base string, rates map[string]float64) *StaticSource {
+	normalized := make(map[string]float64, len(rates))
+	for code, rate := range rates {
+		normalized[Normalize(code)] = rate
+	}
+
+	return &StaticSource{
+		rates: &Rates{
+			Base:  Normalize(base),
+			Rates: normalized,
+			Date:  time.Now().UTC(),
+		},
+	}
+}
+
+// ParseStaticRates parses a comma-separated list of rates against the base,
+// e.g. "EUR=0.92,GBP=0.79", into a StaticSource.
+func ParseStaticRates(base, s string) (*StaticSource, error) {
+	rates := make(map[string]float64)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		code, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rate %q: expected CODE=RATE", pair)
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("invalid rate %q: expected a positive number", pair)
+		}
+		rates[code] = rate
+	}
+
+	return NewStaticSource(base, rates), nil
+}
+
+// Name identifies the source of the rates.
+func (ss *StaticSource) Name() string {
+	return "static"
+}
+
+// Rates returns the table of rates.
+func (ss *StaticSource) Rates() (*Rates, error) {
+	return ss.rates, nil
+}
+
+// ECBDailyRatesURL is the URL of the euro foreign exchange reference rates
+// published daily by the European Central Bank.
+const ECBDailyRatesURL = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml"
+
+// ECBSource is a RateSource serving the euro reference rates of the European
+// Central Bank.
+type ECBSource struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewECBSource creates an ECBSource of the daily reference rates.
+func NewECBSource() *ECBSource {
+	return &ECBSource{
+		URL:    ECBDailyRatesURL,
+		Client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// ecbEnvelope is the document of reference rates published by the ECB, of the
+// form <Cube><Cube time="..."><Cube currency="USD" rate="1.0678"/>...
+type ecbEnvelope struct {
+	Cube struct {
+		Days []struct {
+			Time  string `xml:"time,attr"`
+			Rates []struct {
+				Currency string `xml:"currency,attr"`
+				Rate     string `xml:"rate,attr"`
+			} `xml:"Cube"`
+		} `xml:"Cube"`
+	} `xml:"Cube"`
+}
+
+// Name identifies the source of the rates.
+func (es *ECBSource) Name() string {
+	return "ecb"
+}
+
+// Rates retrieves the latest reference rates, against the euro.
+func (es *ECBSource) Rates() (*Rates, error) {
+	resp, err := es.Client.Get(es.URL)
+	if err != nil {
+		return nil, fmt.Errorf("requesting ECB rates: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("requesting ECB rates: status %s", resp.Status)
+	}
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading ECB rates: %w", err)
+	}
+
+	return parseECBRates(b)
+}
+
+func parseECBRates(b []byte) (*Rates, error) {
+	env := &ecbEnvelope{}
+	if err := xml.Unmarshal(b, env); err != nil {
+		return nil, fmt.Errorf("decoding ECB rates: %w", err)
+	}
+	if len(env.Cube.Days) == 0 {
+		return nil, fmt.Errorf("decoding ECB rates: no rates found")
+	}
+
+	// The daily document holds a single day; of more, the first is the latest
+	day := env.Cube.Days[0]
+	date, err := time.Parse("2006-01-02", day.Time)
+	if err != nil {
+		return nil, fmt.Errorf("decoding ECB rates: invalid date %q", day.Time)
+	}
+
+	rates := &Rates{
+		Base:  "EUR",
+		Rates: make(map[string]float64, len(day.Rates)),
+		Date:  date,
+	}
+	for _, r := range day.Rates {
+		rate, err := strconv.ParseFloat(r.Rate, 64)
+		if err != nil {
+			return nil, fmt.Errorf("decoding ECB rates: invalid rate %q for %s", r.Rate, r.Currency)
+		}
+		rates.Rates[Normalize(r.Currency)] = rate
+	}
+
+	return rates, nil
+}
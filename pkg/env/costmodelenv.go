@@ -3,6 +3,7 @@ package env
 import (
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/opencost/opencost/pkg/log"
@@ -81,14 +82,32 @@ const (
 	MetricsConfigmapName  = "METRICS_CONFIGMAP_NAME"
 	KubecostJobNameEnvVar = "KUBECOST_JOB_NAME"
 
-	KubecostConfigBucketEnvVar    = "KUBECOST_CONFIG_BUCKET"
-	ClusterInfoFileEnabledEnvVar  = "CLUSTER_INFO_FILE_ENABLED"
-	ClusterCacheFileEnabledEnvVar = "CLUSTER_CACHE_FILE_ENABLED"
+	MetricsRelabelConfigPathEnvVar    = "METRICS_RELABEL_CONFIG_PATH"
+	MetricsAllowEnvVar                = "METRICS_ALLOW"
+	MetricsDenyEnvVar                 = "METRICS_DENY"
+	MetricsPrefixEnvVar               = "METRICS_PREFIX"
+	MetricsPrefixKeepUnprefixedEnvVar = "METRICS_PREFIX_KEEP_UNPREFIXED"
+
+	KubecostConfigBucketEnvVar         = "KUBECOST_CONFIG_BUCKET"
+	ClusterInfoFileEnabledEnvVar       = "CLUSTER_INFO_FILE_ENABLED"
+	ClusterCacheFileEnabledEnvVar      = "CLUSTER_CACHE_FILE_ENABLED"
+	ClusterCacheTransformEnabledEnvVar = "CLUSTER_CACHE_TRANSFORM_ENABLED"
 
 	PrometheusQueryOffsetEnvVar                 = "PROMETHEUS_QUERY_OFFSET"
 	PrometheusRetryOnRateLimitResponseEnvVar    = "PROMETHEUS_RETRY_ON_RATE_LIMIT"
 	PrometheusRetryOnRateLimitMaxRetriesEnvVar  = "PROMETHEUS_RETRY_ON_RATE_LIMIT_MAX_RETRIES"
 	PrometheusRetryOnRateLimitDefaultWaitEnvVar = "PROMETHEUS_RETRY_ON_RATE_LIMIT_DEFAULT_WAIT"
+	PrometheusSlowQueryThresholdEnvVar          = "PROMETHEUS_SLOW_QUERY_THRESHOLD"
+	PrometheusKeepAliveEnvVar                   = "PROMETHEUS_KEEP_ALIVE"
+	PrometheusMaxIdleConnsEnvVar                = "PROMETHEUS_MAX_IDLE_CONNS"
+	PrometheusMaxIdleConnsPerHostEnvVar         = "PROMETHEUS_MAX_IDLE_CONNS_PER_HOST"
+	PrometheusIdleConnTimeoutEnvVar             = "PROMETHEUS_IDLE_CONN_TIMEOUT"
+	PrometheusResponseHeaderTimeoutEnvVar       = "PROMETHEUS_RESPONSE_HEADER_TIMEOUT"
+	PrometheusHTTP2EnabledEnvVar                = "PROMETHEUS_HTTP2_ENABLED"
+	PrometheusDedupEnabledEnvVar                = "PROMETHEUS_DEDUP_ENABLED"
+	PrometheusDedupIgnoreLabelsEnvVar           = "PROMETHEUS_DEDUP_IGNORE_LABELS"
+	PrometheusDedupDefaultPolicyEnvVar          = "PROMETHEUS_DEDUP_DEFAULT_POLICY"
+	PrometheusDedupPoliciesEnvVar               = "PROMETHEUS_DEDUP_POLICIES"
 
 	IngestPodUIDEnvVar = "INGEST_POD_UID"
 
@@ -96,12 +115,144 @@ const (
 
 	AllocationNodeLabelsEnabled     = "ALLOCATION_NODE_LABELS_ENABLED"
 	AllocationNodeLabelsIncludeList = "ALLOCATION_NODE_LABELS_INCLUDE_LIST"
+	AllocationUsagePercentiles      = "ALLOCATION_USAGE_PERCENTILES_ENABLED"
 
 	regionOverrideList = "REGION_OVERRIDE_LIST"
 
 	ExportCSVFile       = "EXPORT_CSV_FILE"
 	ExportCSVLabelsList = "EXPORT_CSV_LABELS_LIST"
 	ExportCSVLabelsAll  = "EXPORT_CSV_LABELS_ALL"
+
+	AllocationTabularColumnsEnvVar = "ALLOCATION_TABULAR_COLUMNS"
+	AssetsTabularColumnsEnvVar     = "ASSETS_TABULAR_COLUMNS"
+	ParquetCompressionEnvVar       = "PARQUET_COMPRESSION"
+
+	ExportBucketConfigEnvVar   = "EXPORT_BUCKET_CONFIG"
+	ExportIntervalEnvVar       = "EXPORT_INTERVAL"
+	ExportWindowDurationEnvVar = "EXPORT_WINDOW_DURATION"
+	ExportDelayEnvVar          = "EXPORT_DELAY"
+	ExportLookbackEnvVar       = "EXPORT_LOOKBACK"
+	ExportRetryAttemptsEnvVar  = "EXPORT_RETRY_ATTEMPTS"
+	ExportRetryDelayEnvVar     = "EXPORT_RETRY_DELAY"
+	ExportCompressionEnvVar    = "EXPORT_COMPRESSION"
+
+	ExportKafkaRESTURLEnvVar   = "EXPORT_KAFKA_REST_URL"
+	ExportKafkaTopicEnvVar     = "EXPORT_KAFKA_TOPIC"
+	ExportKafkaKeyEnvVar       = "EXPORT_KAFKA_KEY"
+	ExportKafkaEncodingEnvVar  = "EXPORT_KAFKA_ENCODING"
+	ExportKafkaBatchSizeEnvVar = "EXPORT_KAFKA_BATCH_SIZE"
+	ExportKafkaUsernameEnvVar  = "EXPORT_KAFKA_USERNAME"
+	ExportKafkaPasswordEnvVar  = "EXPORT_KAFKA_PASSWORD"
+
+	ExportBackfillStartEnvVar = "EXPORT_BACKFILL_START"
+
+	ExportBigQueryProjectIDEnvVar       = "EXPORT_BIGQUERY_PROJECT_ID"
+	ExportBigQueryDatasetEnvVar         = "EXPORT_BIGQUERY_DATASET"
+	ExportBigQueryLocationEnvVar        = "EXPORT_BIGQUERY_LOCATION"
+	ExportBigQueryAllocationTableEnvVar = "EXPORT_BIGQUERY_ALLOCATION_TABLE"
+	ExportBigQueryAssetTableEnvVar      = "EXPORT_BIGQUERY_ASSET_TABLE"
+	ExportBigQueryCredentialsEnvVar     = "EXPORT_BIGQUERY_CREDENTIALS_FILE"
+
+	ExportWarehouseBucketConfigEnvVar    = "EXPORT_WAREHOUSE_BUCKET_CONFIG"
+	ExportWarehouseStageEnvVar           = "EXPORT_WAREHOUSE_STAGE"
+	ExportWarehouseStagePrefixEnvVar     = "EXPORT_WAREHOUSE_STAGE_PREFIX"
+	ExportWarehouseFormatEnvVar          = "EXPORT_WAREHOUSE_FORMAT"
+	ExportWarehouseDialectFileEnvVar     = "EXPORT_WAREHOUSE_DIALECT_FILE"
+	ExportWarehouseAllocationTableEnvVar = "EXPORT_WAREHOUSE_ALLOCATION_TABLE"
+	ExportWarehouseAssetTableEnvVar      = "EXPORT_WAREHOUSE_ASSET_TABLE"
+	ExportWarehouseSQLDriverEnvVar       = "EXPORT_WAREHOUSE_SQL_DRIVER"
+	ExportWarehouseSQLDSNEnvVar          = "EXPORT_WAREHOUSE_SQL_DSN"
+	ExportSnowflakeAccountURLEnvVar      = "EXPORT_SNOWFLAKE_ACCOUNT_URL"
+	ExportSnowflakeTokenFileEnvVar       = "EXPORT_SNOWFLAKE_TOKEN_FILE"
+	ExportSnowflakeTokenTypeEnvVar       = "EXPORT_SNOWFLAKE_TOKEN_TYPE"
+	ExportSnowflakeDatabaseEnvVar        = "EXPORT_SNOWFLAKE_DATABASE"
+	ExportSnowflakeSchemaEnvVar          = "EXPORT_SNOWFLAKE_SCHEMA"
+	ExportSnowflakeWarehouseEnvVar       = "EXPORT_SNOWFLAKE_WAREHOUSE"
+	ExportSnowflakeRoleEnvVar            = "EXPORT_SNOWFLAKE_ROLE"
+
+	MetricsPushIntervalEnvVar = "METRICS_PUSH_INTERVAL"
+	MetricsPushWindowEnvVar   = "METRICS_PUSH_WINDOW"
+	OTLPEndpointEnvVar        = "OTLP_METRICS_ENDPOINT"
+	OTLPHeadersEnvVar         = "OTLP_METRICS_HEADERS"
+	OTLPResourceAttrsEnvVar   = "OTEL_RESOURCE_ATTRIBUTES"
+	BudgetsConfigPathEnvVar   = "BUDGETS_CONFIG_PATH"
+
+	NotificationsConfigPathEnvVar = "NOTIFICATIONS_CONFIG_PATH"
+	SavingsReportIntervalEnvVar   = "SAVINGS_REPORT_INTERVAL"
+	SavingsReportTopEnvVar        = "SAVINGS_REPORT_TOP"
+
+	DatadogAPIKeyEnvVar    = "DATADOG_API_KEY"
+	DatadogSiteEnvVar      = "DATADOG_SITE"
+	DatadogTagLabelsEnvVar = "DATADOG_TAG_LABELS"
+	DatadogTagsEnvVar      = "DATADOG_TAGS"
+	DatadogBatchSizeEnvVar = "DATADOG_BATCH_SIZE"
+
+	EventsWebhooksConfigPathEnvVar = "EVENTS_WEBHOOKS_CONFIG_PATH"
+	EventsRetryAttemptsEnvVar      = "EVENTS_RETRY_ATTEMPTS"
+	EventsQueueSizeEnvVar          = "EVENTS_QUEUE_SIZE"
+
+	StorePostgresDSNEnvVar        = "STORE_POSTGRES_DSN"
+	StoreBoltPathEnvVar           = "STORE_BOLT_PATH"
+	StoreBoltMaxSizeMBEnvVar      = "STORE_BOLT_MAX_SIZE_MB"
+	StoreQueryAfterEnvVar         = "STORE_QUERY_AFTER"
+	StoreRetentionEnvVar          = "STORE_RETENTION"
+	StoreRollupAfterEnvVar        = "STORE_ROLLUP_AFTER"
+	StoreCompactionIntervalEnvVar = "STORE_COMPACTION_INTERVAL"
+
+	CRDControllerEnabledEnvVar  = "CRD_CONTROLLER_ENABLED"
+	CRDControllerIntervalEnvVar = "CRD_CONTROLLER_INTERVAL"
+
+	AdmissionWebhookTLSCertFileEnvVar     = "ADMISSION_WEBHOOK_TLS_CERT_FILE"
+	AdmissionWebhookTLSKeyFileEnvVar      = "ADMISSION_WEBHOOK_TLS_KEY_FILE"
+	AdmissionWebhookPortEnvVar            = "ADMISSION_WEBHOOK_PORT"
+	AdmissionWebhookEnforceBudgetsEnvVar  = "ADMISSION_WEBHOOK_ENFORCE_BUDGETS"
+	AdmissionWebhookRefreshIntervalEnvVar = "ADMISSION_WEBHOOK_REFRESH_INTERVAL"
+
+	LeaderElectionEnabledEnvVar       = "LEADER_ELECTION_ENABLED"
+	LeaderElectionNamespaceEnvVar     = "LEADER_ELECTION_NAMESPACE"
+	LeaderElectionLeaseNameEnvVar     = "LEADER_ELECTION_LEASE_NAME"
+	LeaderElectionLeaseDurationEnvVar = "LEADER_ELECTION_LEASE_DURATION"
+	LeaderElectionRenewDeadlineEnvVar = "LEADER_ELECTION_RENEW_DEADLINE"
+	LeaderElectionRetryPeriodEnvVar   = "LEADER_ELECTION_RETRY_PERIOD"
+
+	WatchNamespacesEnvVar = "WATCH_NAMESPACES"
+
+	ConfigReloadPathEnvVar     = "CONFIG_RELOAD_PATH"
+	ConfigReloadIntervalEnvVar = "CONFIG_RELOAD_INTERVAL"
+
+	DiagnosticsBundleEnabledEnvVar = "DIAGNOSTICS_BUNDLE_ENABLED"
+	AllocationCaptureEnabledEnvVar = "ALLOCATION_CAPTURE_ENABLED"
+
+	AllocationPodLifetimesEnabledEnvVar = "ALLOCATION_POD_LIFETIMES_ENABLED"
+	AllocationComputeConcurrencyEnvVar  = "ALLOCATION_COMPUTE_CONCURRENCY"
+	AllocationCheckpointsEnabledEnvVar  = "ALLOCATION_CHECKPOINTS_ENABLED"
+
+	CustomPricingAPIEnabledEnvVar     = "CUSTOM_PRICING_API_ENABLED"
+	CustomPricingTrustedProxiesEnvVar = "CUSTOM_PRICING_TRUSTED_PROXIES"
+	PricingMaxAgeEnvVar               = "PRICING_MAX_AGE"
+	PricingRefreshIntervalEnvVar      = "PRICING_REFRESH_INTERVAL"
+
+	DisplayCurrencyEnvVar             = "DISPLAY_CURRENCY"
+	CurrencyRateSourceEnvVar          = "CURRENCY_RATE_SOURCE"
+	CurrencyStaticRatesEnvVar         = "CURRENCY_STATIC_RATES"
+	CurrencyRateRefreshIntervalEnvVar = "CURRENCY_RATE_REFRESH_INTERVAL"
+
+	CloudCostEnabledEnvVar         = "CLOUD_COST_ENABLED"
+	CloudCostRefreshIntervalEnvVar = "CLOUD_COST_REFRESH_INTERVAL"
+	CloudCostRunWindowDaysEnvVar   = "CLOUD_COST_RUN_WINDOW_DAYS"
+	CloudCostRetentionDaysEnvVar   = "CLOUD_COST_RETENTION_DAYS"
+
+	ExternalCostTagRulesEnvVar = "EXTERNAL_COST_TAG_RULES"
+
+	CostAnomalyEnabledEnvVar    = "COST_ANOMALY_ENABLED"
+	CostAnomalyIntervalEnvVar   = "COST_ANOMALY_INTERVAL"
+	CostAnomalyWindowDaysEnvVar = "COST_ANOMALY_WINDOW_DAYS"
+	CostAnomalyThresholdEnvVar  = "COST_ANOMALY_THRESHOLD"
+	CostAnomalyMinCostEnvVar    = "COST_ANOMALY_MIN_COST"
+
+	QueryMemoryBudgetMBEnvVar = "QUERY_MEMORY_BUDGET_MB"
+	QueryMaxQueuedEnvVar      = "QUERY_MAX_QUEUED"
+	QueryQueueTimeoutEnvVar   = "QUERY_QUEUE_TIMEOUT"
 )
 
 const DefaultConfigMountPath = "/var/configs"
@@ -124,6 +275,634 @@ func GetExportCSVLabelsList() []string {
 	return GetList(ExportCSVLabelsList, ",")
 }
 
+// GetAllocationTabularColumns returns the default set of columns written when allocation data is
+// requested in CSV or Parquet format. An empty list selects all available columns.
+func GetAllocationTabularColumns() []string {
+	return GetList(AllocationTabularColumnsEnvVar, ",")
+}
+
+// GetAssetsTabularColumns returns the default set of columns written when asset data is requested
+// in CSV or Parquet format. An empty list selects all available columns.
+func GetAssetsTabularColumns() []string {
+	return GetList(AssetsTabularColumnsEnvVar, ",")
+}
+
+// GetParquetCompression returns the compression codec used when writing parquet files. Supported
+// values are snappy (default), gzip, zstd and none.
+func GetParquetCompression() string {
+	return Get(ParquetCompressionEnvVar, "snappy")
+}
+
+// GetExportBucketConfig returns the path to a bucket storage configuration file (using the thanos
+// layout) to which finalized allocation and asset windows are exported as parquet. Export is
+// disabled when empty.
+func GetExportBucketConfig() string {
+	return Get(ExportBucketConfigEnvVar, "")
+}
+
+// GetExportInterval returns the duration between attempts to export finalized windows.
+func GetExportInterval() time.Duration {
+	return GetDuration(ExportIntervalEnvVar, time.Hour)
+}
+
+// GetExportWindowDuration returns the duration of each exported window.
+func GetExportWindowDuration() time.Duration {
+	return GetDuration(ExportWindowDurationEnvVar, 24*time.Hour)
+}
+
+// GetExportDelay returns the amount of time to wait after a window ends before exporting it, which
+// allows prometheus to collect all the data for the window.
+func GetExportDelay() time.Duration {
+	return GetDuration(ExportDelayEnvVar, 10*time.Minute)
+}
+
+// GetExportLookback returns the number of previous windows which are checked and exported if missing.
+func GetExportLookback() int {
+	return GetInt(ExportLookbackEnvVar, 3)
+}
+
+// GetExportRetryAttempts returns the number of attempts made to export a window before waiting for
+// the next export interval.
+func GetExportRetryAttempts() uint {
+	return GetUInt(ExportRetryAttemptsEnvVar, 3)
+}
+
+// GetExportRetryDelay returns the initial delay between export attempts.
+func GetExportRetryDelay() time.Duration {
+	return GetDuration(ExportRetryDelayEnvVar, 5*time.Second)
+}
+
+// GetExportCompression returns the compression codec used for exported parquet files, defaulting
+// to the codec used by the API.
+func GetExportCompression() string {
+	return Get(ExportCompressionEnvVar, GetParquetCompression())
+}
+
+// GetExportKafkaRESTURL returns the URL of the Kafka REST Proxy to which allocation events are
+// published for each finalized window. Publishing is disabled if empty.
+func GetExportKafkaRESTURL() string {
+	return Get(ExportKafkaRESTURLEnvVar, "")
+}
+
+// GetExportKafkaTopic returns the topic to which allocation events are published.
+func GetExportKafkaTopic() string {
+	return Get(ExportKafkaTopicEnvVar, "opencost.allocations")
+}
+
+// GetExportKafkaKey returns the comma-separated allocation properties used to build message keys,
+// which determine the partition of each event.
+func GetExportKafkaKey() string {
+	return Get(ExportKafkaKeyEnvVar, "cluster,namespace")
+}
+
+// GetExportKafkaEncoding returns the encoding of published events, either json or avro.
+func GetExportKafkaEncoding() string {
+	return Get(ExportKafkaEncodingEnvVar, "json")
+}
+
+// GetExportKafkaBatchSize returns the maximum number of events produced per request.
+func GetExportKafkaBatchSize() int {
+	return GetInt(ExportKafkaBatchSizeEnvVar, 500)
+}
+
+// GetExportKafkaUsername returns the basic auth username for the Kafka REST Proxy.
+func GetExportKafkaUsername() string {
+	return Get(ExportKafkaUsernameEnvVar, "")
+}
+
+// GetExportKafkaPassword returns the basic auth password for the Kafka REST Proxy.
+func GetExportKafkaPassword() string {
+	return Get(ExportKafkaPasswordEnvVar, "")
+}
+
+// GetExportBackfillStart returns the RFC3339 time or YYYY-MM-DD date from which all finalized
+// windows are exported when the exporter starts. Only the lookback is exported if empty.
+func GetExportBackfillStart() string {
+	return Get(ExportBackfillStartEnvVar, "")
+}
+
+// GetExportBigQueryProjectID returns the GCP project of the BigQuery dataset to which finalized
+// windows are exported. BigQuery export is disabled if empty.
+func GetExportBigQueryProjectID() string {
+	return Get(ExportBigQueryProjectIDEnvVar, "")
+}
+
+// GetExportBigQueryDataset returns the BigQuery dataset to which finalized windows are exported.
+func GetExportBigQueryDataset() string {
+	return Get(ExportBigQueryDatasetEnvVar, "opencost")
+}
+
+// GetExportBigQueryLocation returns the location used when creating the BigQuery dataset.
+func GetExportBigQueryLocation() string {
+	return Get(ExportBigQueryLocationEnvVar, "")
+}
+
+// GetExportBigQueryAllocationTable returns the name of the BigQuery allocation table.
+func GetExportBigQueryAllocationTable() string {
+	return Get(ExportBigQueryAllocationTableEnvVar, "allocations")
+}
+
+// GetExportBigQueryAssetTable returns the name of the BigQuery asset table.
+func GetExportBigQueryAssetTable() string {
+	return Get(ExportBigQueryAssetTableEnvVar, "assets")
+}
+
+// GetExportBigQueryCredentialsFile returns the path to a service account key used to write to
+// BigQuery. Application default credentials are used if empty.
+func GetExportBigQueryCredentialsFile() string {
+	return Get(ExportBigQueryCredentialsEnvVar, "")
+}
+
+// GetExportWarehouseBucketConfig returns the path to a bucket storage configuration file for the
+// storage to which files are staged before being loaded into a data warehouse. Warehouse export
+// is disabled if empty.
+func GetExportWarehouseBucketConfig() string {
+	return Get(ExportWarehouseBucketConfigEnvVar, "")
+}
+
+// GetExportWarehouseStage returns the warehouse's reference to the root of the staging bucket,
+// e.g. a Snowflake external stage such as @opencost_stage.
+func GetExportWarehouseStage() string {
+	return Get(ExportWarehouseStageEnvVar, "@opencost_stage")
+}
+
+// GetExportWarehouseStagePrefix returns the directory of the staging bucket to which files are written.
+func GetExportWarehouseStagePrefix() string {
+	return Get(ExportWarehouseStagePrefixEnvVar, "opencost")
+}
+
+// GetExportWarehouseFormat returns the format of staged files, either parquet or csv.
+func GetExportWarehouseFormat() string {
+	return Get(ExportWarehouseFormatEnvVar, "parquet")
+}
+
+// GetExportWarehouseDialectFile returns the path to a JSON file containing custom warehouse
+// statement templates. The Snowflake dialect is used if empty.
+func GetExportWarehouseDialectFile() string {
+	return Get(ExportWarehouseDialectFileEnvVar, "")
+}
+
+// GetExportWarehouseAllocationTable returns the name of the warehouse allocation table.
+func GetExportWarehouseAllocationTable() string {
+	return Get(ExportWarehouseAllocationTableEnvVar, "opencost_allocations")
+}
+
+// GetExportWarehouseAssetTable returns the name of the warehouse asset table.
+func GetExportWarehouseAssetTable() string {
+	return Get(ExportWarehouseAssetTableEnvVar, "opencost_assets")
+}
+
+// GetExportWarehouseSQLDriver returns the database/sql driver used to load staged files when
+// the Snowflake SQL API is not configured.
+func GetExportWarehouseSQLDriver() string {
+	return Get(ExportWarehouseSQLDriverEnvVar, "postgres")
+}
+
+// GetExportWarehouseSQLDSN returns the data source name used with the warehouse SQL driver.
+func GetExportWarehouseSQLDSN() string {
+	return Get(ExportWarehouseSQLDSNEnvVar, "")
+}
+
+// GetExportSnowflakeAccountURL returns the Snowflake account URL used to execute load statements
+// with the Snowflake SQL API.
+func GetExportSnowflakeAccountURL() string {
+	return Get(ExportSnowflakeAccountURLEnvVar, "")
+}
+
+// GetExportSnowflakeTokenFile returns the path to the OAuth token or key pair JWT for the
+// Snowflake SQL API.
+func GetExportSnowflakeTokenFile() string {
+	return Get(ExportSnowflakeTokenFileEnvVar, "")
+}
+
+// GetExportSnowflakeTokenType returns the type of the Snowflake token, OAUTH or KEYPAIR_JWT.
+func GetExportSnowflakeTokenType() string {
+	return Get(ExportSnowflakeTokenTypeEnvVar, "OAUTH")
+}
+
+// GetExportSnowflakeDatabase returns the Snowflake database containing the export tables.
+func GetExportSnowflakeDatabase() string {
+	return Get(ExportSnowflakeDatabaseEnvVar, "")
+}
+
+// GetExportSnowflakeSchema returns the Snowflake schema containing the export tables.
+func GetExportSnowflakeSchema() string {
+	return Get(ExportSnowflakeSchemaEnvVar, "")
+}
+
+// GetExportSnowflakeWarehouse returns the Snowflake warehouse used to run load statements.
+func GetExportSnowflakeWarehouse() string {
+	return Get(ExportSnowflakeWarehouseEnvVar, "")
+}
+
+// GetExportSnowflakeRole returns the Snowflake role used to run load statements.
+func GetExportSnowflakeRole() string {
+	return Get(ExportSnowflakeRoleEnvVar, "")
+}
+
+// GetMetricsPushInterval returns the duration between pushes of cost metrics to external
+// metrics backends.
+func GetMetricsPushInterval() time.Duration {
+	return GetDuration(MetricsPushIntervalEnvVar, 5*time.Minute)
+}
+
+// GetMetricsPushWindow returns the trailing window over which pushed cost rates are computed.
+func GetMetricsPushWindow() time.Duration {
+	return GetDuration(MetricsPushWindowEnvVar, time.Hour)
+}
+
+// GetOTLPEndpoint returns the base URL of the OTLP/HTTP receiver to which cost metrics are
+// pushed, e.g. http://otel-collector:4318. OTLP export is disabled if empty.
+func GetOTLPEndpoint() string {
+	return Get(OTLPEndpointEnvVar, "")
+}
+
+// GetOTLPHeaders returns the headers added to OTLP requests, configured as comma-separated
+// key=value pairs.
+func GetOTLPHeaders() map[string]string {
+	return getKeyValues(OTLPHeadersEnvVar)
+}
+
+// GetOTLPResourceAttributes returns the standard OpenTelemetry resource attributes, configured as
+// comma-separated key=value pairs.
+func GetOTLPResourceAttributes() map[string]string {
+	return getKeyValues(OTLPResourceAttrsEnvVar)
+}
+
+// GetBudgetsConfigPath returns the path to a JSON file containing namespace budgets, which are
+// evaluated each time cost metrics are pushed.
+func GetBudgetsConfigPath() string {
+	return Get(BudgetsConfigPathEnvVar, "")
+}
+
+// GetNotificationsConfigPath returns the path to a JSON file containing the Slack and Microsoft
+// Teams channels which receive budget breach and savings report notifications.
+func GetNotificationsConfigPath() string {
+	return Get(NotificationsConfigPathEnvVar, "")
+}
+
+// GetSavingsReportInterval returns the duration between savings report notifications. Savings
+// reports are disabled if zero.
+func GetSavingsReportInterval() time.Duration {
+	return GetDuration(SavingsReportIntervalEnvVar, 0)
+}
+
+// GetSavingsReportTop returns the number of workloads listed in each savings report.
+func GetSavingsReportTop() int {
+	return GetInt(SavingsReportTopEnvVar, 10)
+}
+
+// GetEventsWebhooksConfigPath returns the path to a JSON file containing the webhook endpoints
+// which receive lifecycle events as CloudEvents. Events are disabled if empty.
+func GetEventsWebhooksConfigPath() string {
+	return Get(EventsWebhooksConfigPathEnvVar, "")
+}
+
+// GetEventsRetryAttempts returns the number of attempts made to deliver an event to each webhook.
+func GetEventsRetryAttempts() int {
+	return GetInt(EventsRetryAttemptsEnvVar, 5)
+}
+
+// GetEventsQueueSize returns the number of events buffered for delivery, beyond which events are
+// dropped.
+func GetEventsQueueSize() int {
+	return GetInt(EventsQueueSizeEnvVar, 1000)
+}
+
+// GetStorePostgresDSN returns the connection string of the PostgreSQL database storing finalized
+// allocation and asset windows. The durable store is disabled if empty.
+func GetStorePostgresDSN() string {
+	return Get(StorePostgresDSNEnvVar, "")
+}
+
+// GetStoreBoltPath returns the path of the embedded database file storing finalized allocation and
+// asset windows, used when no PostgreSQL database is configured. It should be on a persistent volume.
+func GetStoreBoltPath() string {
+	return Get(StoreBoltPathEnvVar, "")
+}
+
+// GetStoreBoltMaxSizeMB returns the size, in megabytes, of the stored windows beyond which the
+// oldest windows are pruned from the embedded store. Windows are never pruned by size if zero.
+func GetStoreBoltMaxSizeMB() int {
+	return GetInt(StoreBoltMaxSizeMBEnvVar, 1024)
+}
+
+// GetStoreQueryAfter returns the age after which windows are queried from the durable store
+// rather than Prometheus, if the store covers them.
+func GetStoreQueryAfter() time.Duration {
+	return GetDuration(StoreQueryAfterEnvVar, 24*time.Hour)
+}
+
+// GetStoreRetention returns the age after which windows are deleted from the durable store. Windows
+// are kept forever if zero.
+func GetStoreRetention() time.Duration {
+	return GetDuration(StoreRetentionEnvVar, 0)
+}
+
+// GetStoreRollupAfter returns the age after which windows shorter than a day are rolled up into
+// daily windows in the durable store. Windows are never rolled up if zero.
+func GetStoreRollupAfter() time.Duration {
+	return GetDuration(StoreRollupAfterEnvVar, 7*24*time.Hour)
+}
+
+// GetStoreCompactionInterval returns the duration between compactions of the durable store.
+func GetStoreCompactionInterval() time.Duration {
+	return GetDuration(StoreCompactionIntervalEnvVar, time.Hour)
+}
+
+// IsCRDControllerEnabled returns true if the CostBudget, CostReport and PricingOverride custom
+// resources are reconciled. The CRDs in kubernetes/crds.yaml must be installed.
+func IsCRDControllerEnabled() bool {
+	return GetBool(CRDControllerEnabledEnvVar, false)
+}
+
+// GetCRDControllerInterval returns the duration between reconciliations of the custom resources.
+func GetCRDControllerInterval() time.Duration {
+	return GetDuration(CRDControllerIntervalEnvVar, time.Minute)
+}
+
+// GetAdmissionWebhookTLSCertFile returns the path to the TLS certificate served by the admission
+// webhook. The admission webhook is disabled if empty.
+func GetAdmissionWebhookTLSCertFile() string {
+	return Get(AdmissionWebhookTLSCertFileEnvVar, "")
+}
+
+// GetAdmissionWebhookTLSKeyFile returns the path to the private key of the admission webhook's
+// TLS certificate.
+func GetAdmissionWebhookTLSKeyFile() string {
+	return Get(AdmissionWebhookTLSKeyFileEnvVar, "")
+}
+
+// GetAdmissionWebhookPort returns the port the admission webhook listens on.
+func GetAdmissionWebhookPort() int {
+	return GetInt(AdmissionWebhookPortEnvVar, 8443)
+}
+
+// IsAdmissionWebhookEnforceBudgets returns true if the validating admission webhook rejects
+// workloads whose estimated cost exceeds a namespace budget from BUDGETS_CONFIG_PATH.
+func IsAdmissionWebhookEnforceBudgets() bool {
+	return GetBool(AdmissionWebhookEnforceBudgetsEnvVar, false)
+}
+
+// GetAdmissionWebhookRefreshInterval returns how often the admission webhook refreshes the node
+// pricing and namespace costs it estimates and admits workloads with.
+func GetAdmissionWebhookRefreshInterval() time.Duration {
+	return GetDuration(AdmissionWebhookRefreshIntervalEnvVar, 10*time.Minute)
+}
+
+// IsLeaderElectionEnabled returns true if replicas elect a leader through a Kubernetes Lease, and
+// only the leader emits metrics and runs exports, pushes and reconciliations. All replicas serve
+// the API.
+func IsLeaderElectionEnabled() bool {
+	return GetBool(LeaderElectionEnabledEnvVar, false)
+}
+
+// GetLeaderElectionNamespace returns the namespace of the leader election Lease. Defaults to the
+// KUBECOST_NAMESPACE.
+func GetLeaderElectionNamespace() string {
+	return Get(LeaderElectionNamespaceEnvVar, GetKubecostNamespace())
+}
+
+// GetLeaderElectionLeaseName returns the name of the leader election Lease.
+func GetLeaderElectionLeaseName() string {
+	return Get(LeaderElectionLeaseNameEnvVar, "opencost-leader")
+}
+
+// GetLeaderElectionLeaseDuration returns how long followers wait before taking over a Lease
+// which has not been renewed.
+func GetLeaderElectionLeaseDuration() time.Duration {
+	return GetDuration(LeaderElectionLeaseDurationEnvVar, 15*time.Second)
+}
+
+// GetLeaderElectionRenewDeadline returns how long the leader retries renewing its Lease before
+// giving up leadership.
+func GetLeaderElectionRenewDeadline() time.Duration {
+	return GetDuration(LeaderElectionRenewDeadlineEnvVar, 10*time.Second)
+}
+
+// GetLeaderElectionRetryPeriod returns the duration between attempts to acquire or renew the Lease.
+func GetLeaderElectionRetryPeriod() time.Duration {
+	return GetDuration(LeaderElectionRetryPeriodEnvVar, 2*time.Second)
+}
+
+// GetWatchNamespaces returns the namespaces OpenCost is restricted to. If set, only namespaced
+// resources in these namespaces are watched, requiring only namespaced RBAC, and Prometheus
+// results from other namespaces are discarded. If empty, the whole cluster is watched.
+func GetWatchNamespaces() []string {
+	return GetList(WatchNamespacesEnvVar, ",")
+}
+
+// IsNamespaceScoped returns true if OpenCost is restricted to the namespaces of WATCH_NAMESPACES.
+func IsNamespaceScoped() bool {
+	return len(GetWatchNamespaces()) > 0
+}
+
+// GetConfigReloadPath returns the directory, typically a mounted ConfigMap, from which custom
+// pricing, discounts, label mappings and exporter settings are reloaded without a restart.
+// Reloading is disabled if empty.
+func GetConfigReloadPath() string {
+	return Get(ConfigReloadPathEnvVar, "")
+}
+
+// GetConfigReloadInterval returns the duration between checks of CONFIG_RELOAD_PATH for changes.
+func GetConfigReloadInterval() time.Duration {
+	return GetDuration(ConfigReloadIntervalEnvVar, 30*time.Second)
+}
+
+// IsDiagnosticsBundleEnabled returns true if the /diagnostics/bundle endpoint, which serves
+// profiles and the redacted configuration of the process, is enabled.
+func IsDiagnosticsBundleEnabled() bool {
+	return GetBool(DiagnosticsBundleEnabledEnvVar, false)
+}
+
+// IsAllocationCaptureEnabled returns true if the /diagnostics/allocationCapture endpoint, which
+// records the raw Prometheus responses of an allocation compute pass for offline replay, is enabled.
+func IsAllocationCaptureEnabled() bool {
+	return GetBool(AllocationCaptureEnabledEnvVar, false)
+}
+
+// IsAllocationPodLifetimesEnabled returns true if the start and end times of pods in the
+// cluster cache are used to cost pods to a finer granularity than the query resolution.
+func IsAllocationPodLifetimesEnabled() bool {
+	return GetBool(AllocationPodLifetimesEnabledEnvVar, true)
+}
+
+// GetAllocationComputeConcurrency returns the number of time ranges, and of clusters, for which
+// allocations are computed in parallel. Each time range queries Prometheus separately.
+func GetAllocationComputeConcurrency() int {
+	return GetInt(AllocationComputeConcurrencyEnvVar, 4)
+}
+
+// IsAllocationCheckpointsEnabled returns true if the time ranges of long, finalized windows are
+// checkpointed to the durable store as they are computed, so that computation resumes from them
+// after a restart. Checkpoints require a durable store, and are disabled by default.
+func IsAllocationCheckpointsEnabled() bool {
+	return GetBool(AllocationCheckpointsEnabledEnvVar, false)
+}
+
+// IsCustomPricingAPIEnabled returns true if the /customPricing endpoints, which change the
+// custom pricing and record a versioned history of the changes, are enabled.
+func IsCustomPricingAPIEnabled() bool {
+	return GetBool(CustomPricingAPIEnabledEnvVar, false)
+}
+
+// GetCustomPricingTrustedProxies returns the addresses or CIDRs of the authenticating proxies
+// whose X-Forwarded-User, X-Auth-Request-User and X-Remote-User headers identify the authors of
+// custom pricing changes. The headers of other clients are ignored.
+func GetCustomPricingTrustedProxies() []string {
+	return GetList(CustomPricingTrustedProxiesEnvVar, ",")
+}
+
+// GetPricingMaxAge returns the age past which the provider's pricing data is stale, and API
+// responses are flagged as priced by stale data.
+func GetPricingMaxAge() time.Duration {
+	return GetDuration(PricingMaxAgeEnvVar, 7*24*time.Hour)
+}
+
+// GetPricingRefreshInterval returns the interval at which the provider's pricing data is downloaded
+// again, keeping it younger than the max age on long-running instances. 0 disables the refresh.
+func GetPricingRefreshInterval() time.Duration {
+	return GetDuration(PricingRefreshIntervalEnvVar, 24*time.Hour)
+}
+
+// GetDisplayCurrency returns the ISO 4217 code of the currency in which API responses report
+// costs, converted from the currency of the provider's prices. Costs are not converted if empty.
+func GetDisplayCurrency() string {
+	return Get(DisplayCurrencyEnvVar, "")
+}
+
+// GetCurrencyRateSource returns the source of the exchange rates used to convert costs between
+// currencies: "ecb", for the daily reference rates of the European Central Bank, or "static".
+func GetCurrencyRateSource() string {
+	return Get(CurrencyRateSourceEnvVar, "ecb")
+}
+
+// GetCurrencyStaticRates returns the exchange rates used by the "static" rate source, as a
+// comma-separated list of rates against USD, e.g. "EUR=0.92,GBP=0.79".
+func GetCurrencyStaticRates() string {
+	return Get(CurrencyStaticRatesEnvVar, "")
+}
+
+// GetCurrencyRateRefreshInterval returns how long exchange rates are used before being
+// retrieved again from their source.
+func GetCurrencyRateRefreshInterval() time.Duration {
+	return GetDuration(CurrencyRateRefreshIntervalEnvVar, 12*time.Hour)
+}
+
+// IsCloudCostEnabled returns true if cloud costs, including spend outside of Kubernetes, are
+// ingested from the provider's billing export and served by the /cloudCost endpoint.
+func IsCloudCostEnabled() bool {
+	return GetBool(CloudCostEnabledEnvVar, false)
+}
+
+// GetCloudCostRefreshInterval returns the time between ingestions of cloud costs.
+func GetCloudCostRefreshInterval() time.Duration {
+	return GetDuration(CloudCostRefreshIntervalEnvVar, 6*time.Hour)
+}
+
+// GetCloudCostRunWindowDays returns the number of trailing days of cloud costs ingested on each
+// refresh, over which billing data may still be revised by the provider.
+func GetCloudCostRunWindowDays() int {
+	return GetInt(CloudCostRunWindowDaysEnvVar, 3)
+}
+
+// GetCloudCostRetentionDays returns the number of days of cloud costs kept, all of which are
+// ingested on startup.
+func GetCloudCostRetentionDays() int {
+	return GetInt(CloudCostRetentionDaysEnvVar, 30)
+}
+
+// GetExternalCostTagRules returns the rules linking cloud resources outside of Kubernetes to
+// tenants, as a comma-separated list of tags, each joined to namespaces by the label of the same
+// name, or given as "<tag>=<label>", e.g. "team,cost-center=costcenter".
+func GetExternalCostTagRules() string {
+	return Get(ExternalCostTagRulesEnvVar, "")
+}
+
+// IsCostAnomalyEnabled returns true if daily namespace and asset costs are analyzed for spikes.
+func IsCostAnomalyEnabled() bool {
+	return GetBool(CostAnomalyEnabledEnvVar, false)
+}
+
+// GetCostAnomalyInterval returns the time between analyses of daily costs for anomalies.
+func GetCostAnomalyInterval() time.Duration {
+	return GetDuration(CostAnomalyIntervalEnvVar, time.Hour)
+}
+
+// GetCostAnomalyWindowDays returns the number of trailing days of cost against which each day's
+// cost is compared.
+func GetCostAnomalyWindowDays() int {
+	return GetInt(CostAnomalyWindowDaysEnvVar, 14)
+}
+
+// GetCostAnomalyThreshold returns the robust z-score above which a day's cost is anomalous.
+func GetCostAnomalyThreshold() float64 {
+	return GetFloat64(CostAnomalyThresholdEnvVar, 3.5)
+}
+
+// GetCostAnomalyMinCost returns the daily cost below which spikes are not reported, to ignore
+// noise in negligible costs.
+func GetCostAnomalyMinCost() float64 {
+	return GetFloat64(CostAnomalyMinCostEnvVar, 1)
+}
+
+// GetQueryMemoryBudgetMB returns the memory, in MB, which in-flight allocation queries are
+// estimated to hold, beyond which queries wait or are rejected. Admission is disabled if zero.
+func GetQueryMemoryBudgetMB() int {
+	return GetInt(QueryMemoryBudgetMBEnvVar, 0)
+}
+
+// GetQueryMaxQueued returns the number of queries which may wait for memory to be released,
+// beyond which queries are rejected.
+func GetQueryMaxQueued() int {
+	return GetInt(QueryMaxQueuedEnvVar, 16)
+}
+
+// GetQueryQueueTimeout returns how long a query waits for memory to be released before it is
+// rejected.
+func GetQueryQueueTimeout() time.Duration {
+	return GetDuration(QueryQueueTimeoutEnvVar, 30*time.Second)
+}
+
+// GetDatadogAPIKey returns the API key used to submit cost metrics and events to Datadog.
+// Datadog export is disabled if empty.
+func GetDatadogAPIKey() string {
+	return Get(DatadogAPIKeyEnvVar, "")
+}
+
+// GetDatadogSite returns the Datadog site, e.g. datadoghq.com or datadoghq.eu.
+func GetDatadogSite() string {
+	return Get(DatadogSiteEnvVar, "datadoghq.com")
+}
+
+// GetDatadogTagLabels returns the allocation labels added as tags to Datadog metrics and events.
+func GetDatadogTagLabels() []string {
+	return GetList(DatadogTagLabelsEnvVar, ",")
+}
+
+// GetDatadogTags returns additional tags added to all Datadog metrics and events.
+func GetDatadogTags() []string {
+	return GetList(DatadogTagsEnvVar, ",")
+}
+
+// GetDatadogBatchSize returns the maximum number of series submitted to Datadog per request.
+func GetDatadogBatchSize() int {
+	return GetInt(DatadogBatchSizeEnvVar, 1000)
+}
+
+// getKeyValues parses comma-separated key=value pairs. Malformed pairs are ignored.
+func getKeyValues(key string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range GetList(key, ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			continue
+		}
+		result[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return result
+}
+
 // GetKubecostConfigBucket returns a file location for a mounted bucket configuration which is used to store
 // a subset of kubecost configurations that require sharing via remote storage.
 func GetKubecostConfigBucket() string {
@@ -142,6 +921,15 @@ func IsClusterCacheFileEnabled() bool {
 	return GetBool(ClusterCacheFileEnabledEnvVar, false)
 }
 
+// IsClusterCacheTransformEnabled returns true if fields which do not affect cost, such as pod conditions,
+// container environments, commands, arguments and probes, and managed fields, are stripped from objects
+// before they are cached. This cuts the memory used by the cache on large clusters, but the stripped fields
+// are then missing from the cluster cache and from anything reading it, e.g. diagnostics and custom
+// integrations, so it is disabled by default.
+func IsClusterCacheTransformEnabled() bool {
+	return GetBool(ClusterCacheTransformEnabledEnvVar, false)
+}
+
 // IsPrometheusRetryOnRateLimitResponse will attempt to retry if a 429 response is received OR a 400 with a body containing
 // ThrottleException (common in AWS services like AMP)
 func IsPrometheusRetryOnRateLimitResponse() bool {
@@ -160,6 +948,78 @@ func GetPrometheusRetryOnRateLimitDefaultWait() time.Duration {
 	return GetDuration(PrometheusRetryOnRateLimitDefaultWaitEnvVar, 100*time.Millisecond)
 }
 
+// GetPrometheusSlowQueryThreshold returns the duration, including time spent queued, at or above which
+// a Prometheus query is recorded as slow for diagnostics. Slow queries are not recorded if zero.
+func GetPrometheusSlowQueryThreshold() time.Duration {
+	return GetDuration(PrometheusSlowQueryThresholdEnvVar, 10*time.Second)
+}
+
+// GetPrometheusKeepAlive returns the interval between keep-alive probes of connections to Prometheus.
+func GetPrometheusKeepAlive() time.Duration {
+	return GetDuration(PrometheusKeepAliveEnvVar, 120*time.Second)
+}
+
+// GetPrometheusMaxIdleConns returns the maximum number of idle connections kept open to Prometheus
+// across all hosts.
+func GetPrometheusMaxIdleConns() int {
+	return GetInt(PrometheusMaxIdleConnsEnvVar, 100)
+}
+
+// GetPrometheusMaxIdleConnsPerHost returns the maximum number of idle connections kept open to each
+// Prometheus host. Defaults to the max query concurrency, so that a burst of concurrent queries reuses
+// its connections rather than opening new ones for each subsequent burst.
+func GetPrometheusMaxIdleConnsPerHost() int {
+	return GetInt(PrometheusMaxIdleConnsPerHostEnvVar, GetMaxQueryConcurrency())
+}
+
+// GetPrometheusIdleConnTimeout returns the duration for which an idle connection to Prometheus is kept open.
+func GetPrometheusIdleConnTimeout() time.Duration {
+	return GetDuration(PrometheusIdleConnTimeoutEnvVar, 90*time.Second)
+}
+
+// GetPrometheusResponseHeaderTimeout returns the duration to wait for the response headers of a query
+// once it is sent to Prometheus. There is no timeout if zero.
+func GetPrometheusResponseHeaderTimeout() time.Duration {
+	return GetDuration(PrometheusResponseHeaderTimeoutEnvVar, 0)
+}
+
+// IsPrometheusHTTP2Enabled returns true if HTTP/2 is negotiated with Prometheus servers over TLS,
+// multiplexing concurrent queries over a single connection.
+func IsPrometheusHTTP2Enabled() bool {
+	return GetBool(PrometheusHTTP2EnabledEnvVar, false)
+}
+
+// IsPrometheusDedupEnabled returns true if series of query results which are identical except for
+// the dedup ignore labels, such as those of a target scraped by two jobs, are collapsed into one.
+// Only queries whose results retain the ignore labels are deduplicated; aggregating queries have
+// already combined the duplicate series.
+func IsPrometheusDedupEnabled() bool {
+	return GetBool(PrometheusDedupEnabledEnvVar, false)
+}
+
+// GetPrometheusDedupIgnoreLabels returns the labels ignored when comparing the series of query
+// results for duplicates, which default to those distinguishing scrape jobs.
+func GetPrometheusDedupIgnoreLabels() []string {
+	labels := GetList(PrometheusDedupIgnoreLabelsEnvVar, ",")
+	if len(labels) == 0 {
+		return []string{"job", "instance", "endpoint"}
+	}
+	return labels
+}
+
+// GetPrometheusDedupDefaultPolicy returns the policy, max or avg, by which the values of duplicate
+// series are collapsed for queries without a policy of their own.
+func GetPrometheusDedupDefaultPolicy() string {
+	return Get(PrometheusDedupDefaultPolicyEnvVar, "max")
+}
+
+// GetPrometheusDedupPolicies returns the dedup policies of queries, as a comma separated list of
+// metric=policy pairs, applied to the queries containing the metric name.
+// e.g. container_cpu_usage_seconds_total=avg,kube_pod_container_resource_requests=max
+func GetPrometheusDedupPolicies() []string {
+	return GetList(PrometheusDedupPoliciesEnvVar, ",")
+}
+
 // GetPrometheusQueryOffset returns the time.Duration to offset all prometheus queries by. NOTE: This env var is applied
 // to all non-range queries made via our query context. This should only be applied when there is a significant delay in
 // data arriving in the target prom db. For example, if supplying a thanos or cortex querier for the prometheus server, using
@@ -188,6 +1048,37 @@ func GetMetricsConfigmapName() string {
 	return Get(MetricsConfigmapName, "metrics-config")
 }
 
+// GetMetricsRelabelConfigPath returns the path to a JSON file controlling the cardinality of the
+// emitted metrics by dropping labels, allow-listing namespaces and capping series per metric.
+func GetMetricsRelabelConfigPath() string {
+	return Get(MetricsRelabelConfigPathEnvVar, "")
+}
+
+// GetMetricsAllow returns the comma-separated names or glob patterns of the only metric families
+// emitted, e.g. "node_*_hourly_cost,kube_pod_labels". All metric families are emitted if empty.
+func GetMetricsAllow() []string {
+	return GetList(MetricsAllowEnvVar, ",")
+}
+
+// GetMetricsDeny returns the comma-separated names or glob patterns of metric families which are
+// not emitted, e.g. "kube_pod_annotations".
+func GetMetricsDeny() []string {
+	return GetList(MetricsDenyEnvVar, ",")
+}
+
+// GetMetricsPrefix returns the prefix, e.g. "opencost_", added to the names of emitted metrics to avoid
+// collisions with other exporters. Metrics are not prefixed if empty.
+func GetMetricsPrefix() string {
+	return Get(MetricsPrefixEnvVar, "")
+}
+
+// IsMetricsPrefixKeepUnprefixed returns true if metrics are emitted under their original names as well as
+// prefixed. False is not supported, because the cost model's own queries use the original names, and the
+// metrics handler warns and emits both.
+func IsMetricsPrefixKeepUnprefixed() bool {
+	return GetBool(MetricsPrefixKeepUnprefixedEnvVar, true)
+}
+
 // IsEmitNamespaceAnnotationsMetric returns true if cost-model is configured to emit the kube_namespace_annotations metric
 // containing the namespace annotations
 func IsEmitNamespaceAnnotationsMetric() bool {
@@ -541,6 +1432,13 @@ func GetAllocationNodeLabelsEnabled() bool {
 	return GetBool(AllocationNodeLabelsEnabled, true)
 }
 
+// IsAllocationUsagePercentilesEnabled returns true if the p50, p95 and p99 CPU and RAM usage of
+// each allocation are computed. Computing them queries every usage sample of the window, so is
+// disabled by default.
+func IsAllocationUsagePercentilesEnabled() bool {
+	return GetBool(AllocationUsagePercentiles, false)
+}
+
 var defaultAllocationNodeLabelsIncludeList []string = []string{
 	"cloud.google.com/gke-nodepool",
 	"eks.amazonaws.com/nodegroup",
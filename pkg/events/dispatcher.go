@@ -0,0 +1,254 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/opencost/opencost/pkg/errors"
+	"github.com/opencost/opencost/pkg/log"
+	"github.com/opencost/opencost/pkg/util/json"
+	"github.com/opencost/opencost/pkg/util/retry"
+)
+
+// SignatureHeader contains the hex encoded HMAC-SHA256 of the request body,
+// keyed by the endpoint's secret and prefixed with "sha256=".
+const SignatureHeader = "X-OpenCost-Signature"
+
+// defaultDispatcher is the dispatcher used by Emit
+var defaultDispatcher atomic.Pointer[Dispatcher]
+
+// Endpoint is an HTTP endpoint receiving events.
+type Endpoint struct {
+	URL string `json:"url"`
+
+	// Secret signs each request if set, allowing the receiver to verify that
+	// the event originated from OpenCost.
+	Secret string `json:"secret,omitempty"`
+
+	// Types limits the events sent to the endpoint. All events are sent if
+	// empty.
+	Types []EventType `json:"types,omitempty"`
+
+	// Headers are added to each request.
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// Accepts returns true if the endpoint receives events of the type.
+func (e *Endpoint) Accepts(eventType EventType) bool {
+	return len(e.Types) == 0 || slices.Contains(e.Types, eventType)
+}
+
+// LoadEndpoints reads a JSON array of endpoints from a file.
+func LoadEndpoints(file string) ([]*Endpoint, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading event endpoints: %w", err)
+	}
+
+	var endpoints []*Endpoint
+	if err := json.Unmarshal(b, &endpoints); err != nil {
+		return nil, fmt.Errorf("parsing event endpoints: %w", err)
+	}
+
+	for i, endpoint := range endpoints {
+		if endpoint.URL == "" {
+			return nil, fmt.Errorf("event endpoint %d: url is required", i)
+		}
+	}
+
+	return endpoints, nil
+}
+
+// DispatcherConfig contains the delivery options of a Dispatcher.
+type DispatcherConfig struct {
+	// QueueSize is the number of events buffered for delivery. Events emitted
+	// while the queue is full are dropped.
+	QueueSize int
+
+	// RetryAttempts is the number of delivery attempts made per endpoint.
+	RetryAttempts uint
+
+	// RetryDelay is the initial delay between delivery attempts.
+	RetryDelay time.Duration
+
+	Timeout time.Duration
+}
+
+// DefaultDispatcherConfig returns the default delivery options.
+func DefaultDispatcherConfig() *DispatcherConfig {
+	return &DispatcherConfig{
+		QueueSize:     1000,
+		RetryAttempts: 5,
+		RetryDelay:    time.Second,
+		Timeout:       10 * time.Second,
+	}
+}
+
+// Dispatcher delivers events to endpoints asynchronously, in order of
+// emission. Delivery to each endpoint is retried independently.
+type Dispatcher struct {
+	endpoints []*Endpoint
+	config    *DispatcherConfig
+	client    *http.Client
+	queue     chan *Event
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// NewDispatcher creates a new Dispatcher and starts its delivery loop.
+func NewDispatcher(endpoints []*Endpoint, config *DispatcherConfig) *Dispatcher {
+	if config == nil {
+		config = DefaultDispatcherConfig()
+	}
+
+	d := &Dispatcher{
+		endpoints: endpoints,
+		config:    config,
+		client:    &http.Client{Timeout: config.Timeout},
+		queue:     make(chan *Event, config.QueueSize),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+
+	go d.run()
+	return d
+}
+
+// Dispatch queues the event for delivery without blocking.
+func (d *Dispatcher) Dispatch(event *Event) {
+	select {
+	case d.queue <- event:
+	default:
+		log.Warnf("Events: queue full, dropping %s event %s", event.Type, event.ID)
+	}
+}
+
+// Stop halts delivery once queued events have been delivered.
+func (d *Dispatcher) Stop() {
+	close(d.stop)
+	<-d.done
+}
+
+func (d *Dispatcher) run() {
+	defer close(d.done)
+	defer errors.HandlePanic()
+
+	ctx := context.Background()
+	for {
+		select {
+		case event := <-d.queue:
+			d.deliver(ctx, event)
+		case <-d.stop:
+			for {
+				select {
+				case event := <-d.queue:
+					d.deliver(ctx, event)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, event *Event) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Errorf("Events: failed to encode %s event: %s", event.Type, err)
+		return
+	}
+
+	for _, endpoint := range d.endpoints {
+		if !endpoint.Accepts(event.Type) {
+			continue
+		}
+
+		// Retries stop once the endpoint rejects the event, as it would be
+		// rejected again.
+		var rejected error
+		_, err := retry.Retry(ctx, func() (struct{}, error) {
+			err := d.send(ctx, endpoint, body)
+			if isPermanent(err) {
+				rejected = err
+				return struct{}{}, nil
+			}
+			return struct{}{}, err
+		}, d.config.RetryAttempts, d.config.RetryDelay)
+		if rejected != nil {
+			log.Errorf("Events: %s rejected %s event %s, not retrying: %s", endpoint.URL, event.Type, event.ID, rejected)
+			continue
+		}
+		if err != nil {
+			log.Errorf("Events: failed to deliver %s event %s to %s: %s", event.Type, event.ID, endpoint.URL, err)
+		}
+	}
+}
+
+// statusError is the error of a delivery to which the endpoint responded with
+// a non-2xx status.
+type statusError struct {
+	code int
+	body string
+}
+
+func (se *statusError) Error() string {
+	return fmt.Sprintf("status %d: %s", se.code, se.body)
+}
+
+// isPermanent returns true if the error is a client error status other than
+// 408 Request Timeout and 429 Too Many Requests, which will not succeed if the
+// delivery is retried.
+func isPermanent(err error) bool {
+	se, ok := err.(*statusError)
+	if !ok {
+		return false
+	}
+	if se.code == http.StatusRequestTimeout || se.code == http.StatusTooManyRequests {
+		return false
+	}
+	return se.code >= 400 && se.code < 500
+}
+
+func (d *Dispatcher) send(ctx context.Context, endpoint *Endpoint, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/cloudevents+json")
+	for k, v := range endpoint.Headers {
+		req.Header.Set(k, v)
+	}
+	if endpoint.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(endpoint.Secret, body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return &statusError{code: resp.StatusCode, body: string(respBody)}
+	}
+	return nil
+}
+
+// Sign returns the signature header value of the body for the secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,111 @@
+package events
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/pkg/util/json"
+)
+
+func TestDispatcher_Deliver(t *testing.T) {
+	var lock sync.Mutex
+	var received []*Event
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		// fail the first attempt to exercise retries
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		if sig := r.Header.Get(SignatureHeader); sig != Sign("secret", body) {
+			t.Errorf("expected signature %s; got %s", Sign("secret", body), sig)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/cloudevents+json" {
+			t.Errorf("expected cloudevents content type; got %s", ct)
+		}
+
+		var event Event
+		if err := json.Unmarshal(body, &event); err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+		received = append(received, &event)
+	}))
+	defer server.Close()
+
+	endpoints := []*Endpoint{
+		{URL: server.URL, Secret: "secret", Types: []EventType{BudgetBreached}},
+	}
+	d := NewDispatcher(endpoints, &DispatcherConfig{
+		QueueSize:     10,
+		RetryAttempts: 3,
+		RetryDelay:    time.Millisecond,
+		Timeout:       time.Second,
+	})
+
+	d.Dispatch(NewEvent(PricingRefreshFailed, "", nil))
+	d.Dispatch(NewEvent(BudgetBreached, "ns1", map[string]string{"budget": "monthly"}))
+	d.Stop()
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	if len(received) != 1 {
+		t.Fatalf("expected 1 event; got %d", len(received))
+	}
+	event := received[0]
+	if event.Type != BudgetBreached || event.Subject != "ns1" || event.SpecVersion != specVersion {
+		t.Errorf("unexpected event: %+v", event)
+	}
+	if attempts != 2 {
+		t.Errorf("expected 2 attempts; got %d", attempts)
+	}
+}
+
+func TestDispatcher_DeliverRejected(t *testing.T) {
+	for status, expected := range map[int]int{
+		http.StatusBadRequest:      1,
+		http.StatusUnauthorized:    1,
+		http.StatusNotFound:        1,
+		http.StatusRequestTimeout:  3,
+		http.StatusTooManyRequests: 3,
+		http.StatusBadGateway:      3,
+	} {
+		var lock sync.Mutex
+		attempts := 0
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			lock.Lock()
+			defer lock.Unlock()
+
+			attempts++
+			w.WriteHeader(status)
+		}))
+
+		d := NewDispatcher([]*Endpoint{{URL: server.URL}}, &DispatcherConfig{
+			QueueSize:     10,
+			RetryAttempts: 3,
+			RetryDelay:    time.Millisecond,
+			Timeout:       time.Second,
+		})
+		d.Dispatch(NewEvent(BudgetBreached, "ns1", nil))
+		d.Stop()
+		server.Close()
+
+		lock.Lock()
+		if attempts != expected {
+			t.Errorf("status %d: expected %d attempts; got %d", status, expected, attempts)
+		}
+		lock.Unlock()
+	}
+}
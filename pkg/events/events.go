@@ -0,0 +1,80 @@
+package events
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/opencost/opencost/pkg/env"
+)
+
+// EventType identifies a lifecycle event. Types follow the CloudEvents
+// reverse-DNS naming convention.
+type EventType string
+
+const (
+	// BudgetBreached is emitted when a namespace's projected spend first
+	// exceeds one of its budgets.
+	BudgetBreached EventType = "org.opencost.budget.breached"
+
+	// ReconciliationCompleted is emitted when the exporter has reconciled the
+	// windows exported to each sink with the finalized windows, re-exporting
+	// any which were missing.
+	ReconciliationCompleted EventType = "org.opencost.reconciliation.completed"
+
+	// PricingRefreshFailed is emitted when the cloud provider fails to
+	// download pricing data.
+	PricingRefreshFailed EventType = "org.opencost.pricing.refresh_failed"
+
+	// WindowFinalized is emitted when a finalized window has been computed
+	// and exported.
+	WindowFinalized EventType = "org.opencost.window.finalized"
+
+	// CostAnomalyDetected is emitted when a namespace's or asset's daily cost
+	// spikes significantly above its trailing costs.
+	CostAnomalyDetected EventType = "org.opencost.cost.anomaly_detected"
+)
+
+// specVersion is the CloudEvents specification version of emitted events
+const specVersion = "1.0"
+
+// Event is a CloudEvent in the structured JSON content mode.
+type Event struct {
+	ID              string      `json:"id"`
+	Source          string      `json:"source"`
+	SpecVersion     string      `json:"specversion"`
+	Type            EventType   `json:"type"`
+	Subject         string      `json:"subject,omitempty"`
+	Time            time.Time   `json:"time"`
+	DataContentType string      `json:"datacontenttype"`
+	Data            interface{} `json:"data,omitempty"`
+}
+
+// NewEvent creates a new event originating from this cluster. The subject
+// identifies what the event is about, e.g. a namespace or window.
+func NewEvent(eventType EventType, subject string, data interface{}) *Event {
+	return &Event{
+		ID:              uuid.NewString(),
+		Source:          fmt.Sprintf("/opencost/%s", env.GetClusterID()),
+		SpecVersion:     specVersion,
+		Type:            eventType,
+		Subject:         subject,
+		Time:            time.Now().UTC(),
+		DataContentType: "application/json",
+		Data:            data,
+	}
+}
+
+// Emit sends an event through the default dispatcher. It is a no-op if no
+// default dispatcher has been set.
+func Emit(eventType EventType, subject string, data interface{}) {
+	if d := defaultDispatcher.Load(); d != nil {
+		d.Dispatch(NewEvent(eventType, subject, data))
+	}
+}
+
+// SetDefaultDispatcher sets the dispatcher used by Emit.
+func SetDefaultDispatcher(d *Dispatcher) {
+	defaultDispatcher.Store(d)
+}
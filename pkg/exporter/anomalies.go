@@ -0,0 +1,468 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/opencost/opencost/pkg/config"
+	"github.com/opencost/opencost/pkg/errors"
+	"github.com/opencost/opencost/pkg/events"
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/log"
+	"github.com/opencost/opencost/pkg/util/atomic"
+	"github.com/opencost/opencost/pkg/util/json"
+)
+
+// CostAnomalyLevel is what a cost anomaly was detected in.
+type CostAnomalyLevel string
+
+const (
+	CostAnomalyLevelNamespace CostAnomalyLevel = "namespace"
+	CostAnomalyLevelAsset     CostAnomalyLevel = "asset"
+)
+
+// madScale scales the median absolute deviation such that the robust z-score
+// of normally distributed costs is comparable to their z-score.
+const madScale = 0.6745
+
+// meanADScale scales the mean absolute deviation in place of the median
+// absolute deviation, when more than half of the history is the median.
+const meanADScale = 0.7979
+
+// CostAnomaly is a day on which the cost of a namespace or asset spiked
+// significantly above its trailing daily costs.
+type CostAnomaly struct {
+	Level     CostAnomalyLevel `json:"level"`
+	Cluster   string           `json:"cluster"`
+	Namespace string           `json:"namespace,omitempty"`
+	AssetType string           `json:"assetType,omitempty"`
+	Asset     string           `json:"asset,omitempty"`
+	Date      time.Time        `json:"date"`
+	Cost      float64          `json:"cost"`
+	Median    float64          `json:"median"`
+	Deviation float64          `json:"deviation"`
+	Score     float64          `json:"score"`
+	History   int              `json:"historyDays"`
+}
+
+// Subject identifies the namespace or asset of the anomaly.
+func (ca *CostAnomaly) Subject() string {
+	if ca.Level == CostAnomalyLevelAsset {
+		return fmt.Sprintf("%s/%s/%s", ca.Cluster, ca.AssetType, ca.Asset)
+	}
+	return fmt.Sprintf("%s/%s", ca.Cluster, ca.Namespace)
+}
+
+func (ca *CostAnomaly) key() string {
+	return fmt.Sprintf("%s/%s/%s", ca.Level, ca.Subject(), ca.Date.Format("2006-01-02"))
+}
+
+func (ca *CostAnomaly) String() string {
+	return fmt.Sprintf("%s %s cost %.2f on %s, against a median of %.2f over the previous %d days (score %.1f)",
+		ca.Level, ca.Subject(), ca.Cost, ca.Date.Format("2006-01-02"), ca.Median, ca.History, ca.Score)
+}
+
+// RobustZScore returns the modified z-score of x against the history, along
+// with the history's median and median absolute deviation. The median and
+// median absolute deviation are used in place of the mean and standard
+// deviation such that earlier spikes in the history do not mask later ones.
+func RobustZScore(x float64, history []float64) (score, median, deviation float64) {
+	if len(history) == 0 {
+		return 0, 0, 0
+	}
+
+	median = medianOf(history)
+
+	deviations := make([]float64, len(history))
+	for i, h := range history {
+		deviations[i] = math.Abs(h - median)
+	}
+	deviation = medianOf(deviations)
+	if deviation > 0 {
+		return madScale * (x - median) / deviation, median, deviation
+	}
+
+	// Costs which are usually constant have no median absolute deviation, so
+	// fall back to the mean absolute deviation
+	var sum float64
+	for _, d := range deviations {
+		sum += d
+	}
+	if meanAD := sum / float64(len(deviations)); meanAD > 0 {
+		return meanADScale * (x - median) / meanAD, median, meanAD
+	}
+
+	// Any increase over a perfectly constant history is significant
+	if x > median {
+		return math.Inf(1), median, 0
+	}
+	return 0, median, 0
+}
+
+func medianOf(values []float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// dailyCost is the cost of a namespace or asset on one day.
+type dailyCost struct {
+	anomaly *CostAnomaly
+	cost    float64
+}
+
+// dailyCostsFor returns the cost of each namespace and asset in the sets,
+// keyed by subject. Idle and unmounted allocations are excluded.
+func dailyCostsFor(as *kubecost.AllocationSet, assets *kubecost.AssetSet) map[string]*dailyCost {
+	costs := make(map[string]*dailyCost)
+
+	if as != nil {
+		for _, alloc := range as.Allocations {
+			if alloc.IsIdle() || alloc.IsUnmounted() || alloc.Properties == nil {
+				continue
+			}
+			ca := &CostAnomaly{
+				Level:     CostAnomalyLevelNamespace,
+				Cluster:   alloc.Properties.Cluster,
+				Namespace: alloc.Properties.Namespace,
+			}
+			key := string(ca.Level) + "/" + ca.Subject()
+			if _, ok := costs[key]; !ok {
+				costs[key] = &dailyCost{anomaly: ca}
+			}
+			costs[key].cost += alloc.TotalCost()
+		}
+	}
+
+	if assets != nil {
+		for _, asset := range assets.Assets {
+			props := asset.GetProperties()
+			if props == nil || props.Name == "" {
+				continue
+			}
+			ca := &CostAnomaly{
+				Level:     CostAnomalyLevelAsset,
+				Cluster:   props.Cluster,
+				AssetType: asset.Type().String(),
+				Asset:     props.Name,
+			}
+			key := string(ca.Level) + "/" + ca.Subject()
+			if _, ok := costs[key]; !ok {
+				costs[key] = &dailyCost{anomaly: ca}
+			}
+			costs[key].cost += asset.TotalCost()
+		}
+	}
+
+	return costs
+}
+
+// NewCostAnomalyNotification creates the notification for a cost anomaly.
+func NewCostAnomalyNotification(anomaly *CostAnomaly) *Notification {
+	return &Notification{
+		Kind:      NotificationKindCostAnomaly,
+		Title:     fmt.Sprintf("Cost anomaly in %s %s", anomaly.Level, anomaly.Subject()),
+		Text:      anomaly.String(),
+		Cluster:   anomaly.Cluster,
+		Namespace: anomaly.Namespace,
+		Data:      anomaly,
+	}
+}
+
+// CostAnomalyDetectorConfig contains the options of a CostAnomalyDetector.
+type CostAnomalyDetectorConfig struct {
+	// Interval is the duration between analyses.
+	Interval time.Duration
+
+	// WindowDays is the number of trailing days against which each day's cost
+	// is compared.
+	WindowDays int
+
+	// Threshold is the robust z-score above which a day's cost is anomalous.
+	Threshold float64
+
+	// MinCost is the daily cost below which spikes are ignored.
+	MinCost float64
+
+	// Resolution is the query resolution used to compute allocations.
+	Resolution time.Duration
+}
+
+// CostAnomalyDetector periodically compares the cost of each namespace and
+// asset on the last complete day with its trailing daily costs, reporting
+// statistically significant spikes through events and notifications. Each
+// anomaly is reported once: the anomalies of the window are persisted to a
+// config file, if one is given, so that they are not reported again after a
+// restart.
+type CostAnomalyDetector struct {
+	source   Source
+	notifier *Notifier
+	file     *config.ConfigFile
+	config   *CostAnomalyDetectorConfig
+
+	// detectLock serializes detections, while lock guards the state read by
+	// Anomalies, such that it is not held while costs are computed or
+	// anomalies notified
+	detectLock sync.Mutex
+	lock       sync.Mutex
+	days       map[time.Time]map[string]*dailyCost
+	anomalies  []*CostAnomaly
+
+	runState atomic.AtomicRunState
+}
+
+// NewCostAnomalyDetector creates a new CostAnomalyDetector, loading the
+// anomalies persisted to the file. Anomalies are sent to the notifier, if not
+// nil.
+func NewCostAnomalyDetector(source Source, notifier *Notifier, file *config.ConfigFile, config *CostAnomalyDetectorConfig) (*CostAnomalyDetector, error) {
+	if config.Interval <= 0 {
+		return nil, fmt.Errorf("cost anomaly interval must be positive, got %s", config.Interval)
+	}
+
+	d := &CostAnomalyDetector{
+		source:   source,
+		notifier: notifier,
+		file:     file,
+		config:   config,
+		days:     make(map[time.Time]map[string]*dailyCost),
+	}
+
+	if file == nil {
+		return d, nil
+	}
+
+	exists, err := file.Exists()
+	if err != nil {
+		return nil, fmt.Errorf("checking cost anomalies: %w", err)
+	}
+	if !exists {
+		return d, nil
+	}
+
+	b, err := file.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading cost anomalies: %w", err)
+	}
+	if err := json.Unmarshal(b, &d.anomalies); err != nil {
+		return nil, fmt.Errorf("decoding cost anomalies: %w", err)
+	}
+
+	return d, nil
+}
+
+// Start begins analyzing daily costs immediately and then at each interval.
+// Returns false if the detector is already running.
+func (d *CostAnomalyDetector) Start() bool {
+	d.runState.WaitForReset()
+	if !d.runState.Start() {
+		log.Warnf("CostAnomalyDetector: attempted to start when already running")
+		return false
+	}
+
+	go func() {
+		defer errors.HandlePanic()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go func() {
+			<-d.runState.OnStop()
+			cancel()
+		}()
+
+		for {
+			anomalies, err := d.Detect(ctx, time.Now())
+			if err != nil {
+				log.Errorf("CostAnomalyDetector: %s", err)
+			} else if len(anomalies) > 0 {
+				log.Infof("CostAnomalyDetector: detected %d anomalies", len(anomalies))
+			}
+
+			select {
+			case <-d.runState.OnStop():
+				d.runState.Reset()
+				return
+			case <-time.After(d.config.Interval):
+			}
+		}
+	}()
+
+	return true
+}
+
+// Stop halts the analysis loop.
+func (d *CostAnomalyDetector) Stop() {
+	d.runState.Stop()
+}
+
+// Anomalies returns the anomalies detected over the window, newest first.
+func (d *CostAnomalyDetector) Anomalies() []*CostAnomaly {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	anomalies := make([]*CostAnomaly, len(d.anomalies))
+	copy(anomalies, d.anomalies)
+	return anomalies
+}
+
+// Detect compares the costs of the last day complete at now with the costs of
+// the preceding days of the window, returning the anomalies not previously
+// detected. Each anomaly is emitted as an event and sent to the notifier.
+func (d *CostAnomalyDetector) Detect(ctx context.Context, now time.Time) ([]*CostAnomaly, error) {
+	d.detectLock.Lock()
+	defer d.detectLock.Unlock()
+
+	latest := now.UTC().Truncate(24*time.Hour).AddDate(0, 0, -1)
+	oldest := latest.AddDate(0, 0, -d.config.WindowDays)
+
+	// Costs of past days are cached, as they do not change once complete
+	d.lock.Lock()
+	days := make(map[time.Time]map[string]*dailyCost, len(d.days))
+	for day, costs := range d.days {
+		if !day.Before(oldest) {
+			days[day] = costs
+		}
+	}
+	reported := make(map[string]bool, len(d.anomalies))
+	for _, anomaly := range d.anomalies {
+		reported[anomaly.key()] = true
+	}
+	d.lock.Unlock()
+
+	for day := oldest; !day.After(latest); day = day.AddDate(0, 0, 1) {
+		if _, ok := days[day]; ok {
+			continue
+		}
+		costs, err := d.dailyCosts(day)
+		if err != nil {
+			// Keep the costs of the days computed before the failure
+			d.lock.Lock()
+			d.days = days
+			d.lock.Unlock()
+			return nil, err
+		}
+		days[day] = costs
+	}
+
+	// Require enough history that a single noisy day is not a baseline
+	minHistory := d.config.WindowDays / 2
+	if minHistory < 3 {
+		minHistory = 3
+	}
+
+	var detected []*CostAnomaly
+	for key, current := range days[latest] {
+		if current.cost < d.config.MinCost {
+			continue
+		}
+
+		var history []float64
+		for day := oldest; day.Before(latest); day = day.AddDate(0, 0, 1) {
+			if c, ok := days[day][key]; ok {
+				history = append(history, c.cost)
+			}
+		}
+		if len(history) < minHistory {
+			continue
+		}
+
+		score, median, deviation := RobustZScore(current.cost, history)
+		if score < d.config.Threshold {
+			continue
+		}
+
+		anomaly := *current.anomaly
+		anomaly.Date = latest
+		anomaly.Cost = current.cost
+		anomaly.Median = median
+		anomaly.Deviation = deviation
+		anomaly.Score = score
+		anomaly.History = len(history)
+		if math.IsInf(score, 1) {
+			// JSON cannot encode infinity
+			anomaly.Score = math.MaxFloat64
+		}
+
+		if reported[anomaly.key()] {
+			continue
+		}
+		detected = append(detected, &anomaly)
+	}
+
+	sort.Slice(detected, func(i, j int) bool {
+		return detected[i].Score > detected[j].Score
+	})
+
+	// Keep the anomalies detected over the window, newest first
+	d.lock.Lock()
+	anomalies := make([]*CostAnomaly, 0, len(detected)+len(d.anomalies))
+	anomalies = append(anomalies, detected...)
+	for _, anomaly := range d.anomalies {
+		if !anomaly.Date.Before(oldest) {
+			anomalies = append(anomalies, anomaly)
+		}
+	}
+	d.days = days
+	d.anomalies = anomalies
+	d.lock.Unlock()
+
+	if len(detected) == 0 {
+		return detected, nil
+	}
+
+	// The anomalies are persisted before they are reported, so that none is
+	// reported twice
+	if err := d.persist(anomalies); err != nil {
+		log.Errorf("CostAnomalyDetector: failed to persist anomalies: %s", err)
+	}
+
+	for _, anomaly := range detected {
+		events.Emit(events.CostAnomalyDetected, anomaly.Subject(), anomaly)
+
+		if d.notifier != nil {
+			if err := d.notifier.Notify(ctx, NewCostAnomalyNotification(anomaly)); err != nil {
+				log.Errorf("CostAnomalyDetector: notifying %s: %s", anomaly.Subject(), err)
+			}
+		}
+	}
+
+	return detected, nil
+}
+
+func (d *CostAnomalyDetector) persist(anomalies []*CostAnomaly) error {
+	if d.file == nil {
+		return nil
+	}
+
+	b, err := json.Marshal(anomalies)
+	if err != nil {
+		return fmt.Errorf("encoding cost anomalies: %w", err)
+	}
+	return d.file.Write(b)
+}
+
+func (d *CostAnomalyDetector) dailyCosts(day time.Time) (map[string]*dailyCost, error) {
+	end := day.Add(24 * time.Hour)
+
+	as, err := d.source.ComputeAllocation(day, end, d.config.Resolution)
+	if err != nil {
+		return nil, fmt.Errorf("computing allocations for %s: %w", day.Format("2006-01-02"), err)
+	}
+
+	assets, err := d.source.ComputeAssets(day, end)
+	if err != nil {
+		return nil, fmt.Errorf("computing assets for %s: %w", day.Format("2006-01-02"), err)
+	}
+
+	return dailyCostsFor(as, assets), nil
+}
@@ -0,0 +1,143 @@
+package exporter
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/pkg/config"
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/storage"
+)
+
+func TestRobustZScore(t *testing.T) {
+	history := []float64{10, 11, 9, 10, 12, 10, 9}
+
+	score, median, deviation := RobustZScore(30, history)
+	if median != 10 || deviation != 1 {
+		t.Errorf("expected median 10 and deviation 1; got %f and %f", median, deviation)
+	}
+	if math.Abs(score-0.6745*20) > 1e-9 {
+		t.Errorf("expected score %f; got %f", 0.6745*20, score)
+	}
+
+	// an earlier spike does not mask a later one
+	if score, _, _ := RobustZScore(30, append(history, 100)); score < 3.5 {
+		t.Errorf("expected spike to be significant despite an earlier spike; got score %f", score)
+	}
+
+	// mostly constant history falls back to the mean absolute deviation
+	if score, _, deviation := RobustZScore(20, []float64{10, 10, 10, 10, 12}); deviation <= 0 || score < 3.5 {
+		t.Errorf("expected significant score from the mean absolute deviation; got %f and %f", score, deviation)
+	}
+
+	if score, _, _ := RobustZScore(11, []float64{10, 10, 10}); !math.IsInf(score, 1) {
+		t.Errorf("expected infinite score over a constant history; got %f", score)
+	}
+	if score, _, _ := RobustZScore(10, []float64{10, 10, 10}); score != 0 {
+		t.Errorf("expected zero score equal to a constant history; got %f", score)
+	}
+}
+
+// dailySource returns one allocation per day, costing the amount given for
+// that day, and no assets.
+type dailySource struct {
+	costs map[time.Time]float64
+	calls int
+}
+
+func (ds *dailySource) ComputeAllocation(start, end time.Time, resolution time.Duration) (*kubecost.AllocationSet, error) {
+	ds.calls++
+	as := kubecost.NewAllocationSet(start, end)
+	as.Set(&kubecost.Allocation{
+		Name:       "web",
+		Window:     kubecost.NewClosedWindow(start, end),
+		Start:      start,
+		End:        end,
+		Properties: &kubecost.AllocationProperties{Cluster: "cluster1", Namespace: "ns1"},
+		CPUCost:    ds.costs[start],
+	})
+	return as, nil
+}
+
+func (ds *dailySource) ComputeAssets(start, end time.Time) (*kubecost.AssetSet, error) {
+	return kubecost.NewAssetSet(start, end), nil
+}
+
+func TestCostAnomalyDetector_Detect(t *testing.T) {
+	now := time.Date(2023, 1, 15, 1, 0, 0, 0, time.UTC)
+	latest := time.Date(2023, 1, 14, 0, 0, 0, 0, time.UTC)
+
+	source := &dailySource{costs: map[time.Time]float64{}}
+	for i := 1; i <= 7; i++ {
+		source.costs[latest.AddDate(0, 0, -i)] = 10 + float64(i%2)
+	}
+	source.costs[latest] = 40
+
+	file := config.NewConfigFile(storage.NewFileStorage(t.TempDir()), "cost-anomalies.json")
+	detectorConfig := &CostAnomalyDetectorConfig{
+		Interval:   time.Hour,
+		WindowDays: 7,
+		Threshold:  3.5,
+		MinCost:    1,
+	}
+	d, err := NewCostAnomalyDetector(source, nil, file, detectorConfig)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	anomalies, err := d.Detect(context.Background(), now)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(anomalies) != 1 {
+		t.Fatalf("expected 1 anomaly; got %d", len(anomalies))
+	}
+	a := anomalies[0]
+	if a.Level != CostAnomalyLevelNamespace || a.Namespace != "ns1" || !a.Date.Equal(latest) || a.Cost != 40 || a.History != 7 {
+		t.Errorf("unexpected anomaly: %+v", a)
+	}
+
+	// the same anomaly is not reported twice, and past days are not recomputed
+	calls := source.calls
+	anomalies, err = d.Detect(context.Background(), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(anomalies) != 0 {
+		t.Errorf("expected no new anomalies; got %d", len(anomalies))
+	}
+	if source.calls != calls {
+		t.Errorf("expected cached daily costs; got %d more computations", source.calls-calls)
+	}
+	if len(d.Anomalies()) != 1 {
+		t.Errorf("expected 1 retained anomaly; got %d", len(d.Anomalies()))
+	}
+
+	// nor after a restart
+	d, err = NewCostAnomalyDetector(source, nil, file, detectorConfig)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(d.Anomalies()) != 1 {
+		t.Errorf("expected 1 persisted anomaly; got %d", len(d.Anomalies()))
+	}
+	anomalies, err = d.Detect(context.Background(), now.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(anomalies) != 0 {
+		t.Errorf("expected no new anomalies after a restart; got %d", len(anomalies))
+	}
+
+	// costs below the minimum are ignored
+	source.costs[latest.AddDate(0, 0, 1)] = 0.5
+	anomalies, err = d.Detect(context.Background(), now.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(anomalies) != 0 {
+		t.Errorf("expected no anomalies below the minimum cost; got %d", len(anomalies))
+	}
+}
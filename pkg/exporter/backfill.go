@@ -0,0 +1,217 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/opencost/opencost/pkg/errors"
+	"github.com/opencost/opencost/pkg/log"
+)
+
+// BackfillState is the state of a backfill job.
+type BackfillState string
+
+const (
+	BackfillStateRunning   BackfillState = "running"
+	BackfillStateCompleted BackfillState = "completed"
+	BackfillStateFailed    BackfillState = "failed"
+	BackfillStateCancelled BackfillState = "cancelled"
+)
+
+// BackfillJob reports the progress of recomputing and exporting the windows
+// of a past range.
+type BackfillJob struct {
+	Start time.Time     `json:"start"`
+	End   time.Time     `json:"end"`
+	State BackfillState `json:"state"`
+
+	// Windows is the number of windows in the range.
+	Windows int `json:"windows"`
+
+	// Exported is the number of windows exported by the job.
+	Exported int `json:"exported"`
+
+	// Skipped is the number of windows which had already been exported, e.g.
+	// by an earlier job which was interrupted.
+	Skipped int `json:"skipped"`
+
+	// Failed is the number of windows which failed to export to at least one
+	// sink. Re-running the job retries only these windows.
+	Failed int `json:"failed"`
+
+	// Current is the window most recently processed.
+	Current string `json:"current,omitempty"`
+
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Errors     []string   `json:"errors,omitempty"`
+}
+
+// Processed returns the number of windows processed so far.
+func (bj *BackfillJob) Processed() int {
+	return bj.Exported + bj.Skipped + bj.Failed
+}
+
+// String summarizes the progress of the job.
+func (bj *BackfillJob) String() string {
+	return fmt.Sprintf("%s: %d/%d windows (%d exported, %d skipped, %d failed)",
+		bj.State, bj.Processed(), bj.Windows, bj.Exported, bj.Skipped, bj.Failed)
+}
+
+// maxBackfillErrors limits the errors retained by a job
+const maxBackfillErrors = 20
+
+// Backfiller recomputes allocation and asset data for an arbitrary past range
+// from Prometheus, window by window, and exports it to the exporter's sinks.
+// Windows already exported, according to the exporter's checkpoints, are
+// skipped, so an interrupted backfill resumes where it stopped when re-run.
+// Only one job runs at a time.
+type Backfiller struct {
+	exporter *Exporter
+
+	lock   sync.Mutex
+	job    *BackfillJob
+	cancel context.CancelFunc
+}
+
+// NewBackfiller creates a new Backfiller exporting through the exporter.
+func NewBackfiller(exporter *Exporter) *Backfiller {
+	return &Backfiller{
+		exporter: exporter,
+	}
+}
+
+// Run backfills [start, end) and returns the finished job. The progress
+// function, if non-nil, is called with a copy of the job after each window.
+func (b *Backfiller) Run(ctx context.Context, start, end time.Time, progress func(*BackfillJob)) (*BackfillJob, error) {
+	ctx, err := b.begin(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	b.run(ctx, progress)
+	return b.Status(), nil
+}
+
+// Start backfills [start, end) in the background. Use Status to follow its
+// progress.
+func (b *Backfiller) Start(start, end time.Time) (*BackfillJob, error) {
+	ctx, err := b.begin(context.Background(), start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer errors.HandlePanic()
+
+		b.run(ctx, func(job *BackfillJob) {
+			log.Infof("Backfill: %s", job)
+		})
+	}()
+
+	return b.Status(), nil
+}
+
+// Status returns a copy of the current or most recent job, or nil if no job
+// has run.
+func (b *Backfiller) Status() *BackfillJob {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.snapshot()
+}
+
+// Cancel stops the running job after the current window.
+func (b *Backfiller) Cancel() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.cancel != nil {
+		b.cancel()
+	}
+}
+
+func (b *Backfiller) snapshot() *BackfillJob {
+	if b.job == nil {
+		return nil
+	}
+
+	job := *b.job
+	job.Errors = append([]string{}, b.job.Errors...)
+	return &job
+}
+
+func (b *Backfiller) begin(ctx context.Context, start, end time.Time) (context.Context, error) {
+	if !start.Before(end) {
+		return nil, fmt.Errorf("backfill start %s must be before end %s", start.Format(time.RFC3339), end.Format(time.RFC3339))
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.job != nil && b.job.State == BackfillStateRunning {
+		return nil, fmt.Errorf("a backfill of %s to %s is already running", b.job.Start.Format(time.RFC3339), b.job.End.Format(time.RFC3339))
+	}
+
+	ctx, b.cancel = context.WithCancel(ctx)
+	b.job = &BackfillJob{
+		Start:     start.UTC(),
+		End:       end.UTC(),
+		State:     BackfillStateRunning,
+		StartedAt: time.Now().UTC(),
+	}
+
+	return ctx, nil
+}
+
+func (b *Backfiller) run(ctx context.Context, progress func(*BackfillJob)) {
+	b.lock.Lock()
+	windows := b.exporter.RangeWindows(b.job.Start, b.job.End, b.exporter.now())
+	b.job.Windows = len(windows)
+	b.lock.Unlock()
+
+	log.Infof("Backfill: recomputing %d windows from %s to %s", len(windows), b.job.Start.Format(time.RFC3339), b.job.End.Format(time.RFC3339))
+
+	b.exporter.exportWindows(ctx, windows, func(result *windowResult) {
+		b.lock.Lock()
+		job := b.job
+		job.Current = result.window.String()
+		switch {
+		case result.skipped:
+			job.Skipped++
+		case result.err != nil:
+			job.Failed++
+			if len(job.Errors) < maxBackfillErrors {
+				job.Errors = append(job.Errors, result.err.Error())
+			}
+		default:
+			job.Exported++
+		}
+		snapshot := b.snapshot()
+		b.lock.Unlock()
+
+		if progress != nil {
+			progress(snapshot)
+		}
+	})
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := time.Now().UTC()
+	b.job.FinishedAt = &now
+	switch {
+	case ctx.Err() != nil:
+		b.job.State = BackfillStateCancelled
+	case b.job.Failed > 0:
+		b.job.State = BackfillStateFailed
+	default:
+		b.job.State = BackfillStateCompleted
+	}
+	b.cancel()
+	b.cancel = nil
+
+	log.Infof("Backfill: %s", b.job)
+}
@@ -0,0 +1,101 @@
+package exporter
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/pkg/store"
+)
+
+func TestBackfiller_Resumes(t *testing.T) {
+	source := &testSource{}
+	checkpoints := NewMemoryCheckpoints()
+	sink := &testSink{name: "store"}
+
+	e := NewExporter(source, checkpoints, DefaultExporterConfig(), sink)
+	e.now = func() time.Time { return time.Date(2023, 1, 10, 12, 0, 0, 0, time.UTC) }
+
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := NewBackfiller(e)
+
+	// an earlier, interrupted job exported the first 3 days
+	job, err := b.Run(context.Background(), start, start.Add(3*24*time.Hour), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if job.State != BackfillStateCompleted || job.Exported != 3 {
+		t.Fatalf("unexpected job: %s", job)
+	}
+
+	var updates int
+	job, err = b.Run(context.Background(), start, start.Add(7*24*time.Hour), func(*BackfillJob) { updates++ })
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if job.Windows != 7 || job.Skipped != 3 || job.Exported != 4 || job.Failed != 0 {
+		t.Errorf("expected 4 windows exported and 3 skipped; got %s", job)
+	}
+	if updates != 7 {
+		t.Errorf("expected progress after each of 7 windows; got %d", updates)
+	}
+	if source.allocationCalls != 7 {
+		t.Errorf("expected each window computed once; got %d computations", source.allocationCalls)
+	}
+
+	if _, err := b.Run(context.Background(), start, start, nil); err == nil {
+		t.Errorf("expected error for empty range")
+	}
+}
+
+func TestBackfiller_SkipsRolledUpWindows(t *testing.T) {
+	ctx := context.Background()
+	bs, err := store.NewBoltStore(&store.BoltConfig{
+		Path:            filepath.Join(t.TempDir(), "opencost.db"),
+		RetentionConfig: store.RetentionConfig{RollupAfter: 2 * 24 * time.Hour},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer bs.Close()
+
+	config := DefaultExporterConfig()
+	config.WindowDuration = time.Hour
+
+	source := &testSource{}
+	e := NewExporter(source, store.NewCheckpoints(bs), config, bs)
+	now := time.Date(2023, 1, 10, 12, 0, 0, 0, time.UTC)
+	e.now = func() time.Time { return now }
+
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := NewBackfiller(e)
+
+	job, err := b.Run(ctx, start, start.Add(24*time.Hour), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if job.Exported != 24 {
+		t.Fatalf("expected 24 hourly windows exported; got %s", job)
+	}
+
+	// the hourly windows are replaced by a daily window
+	if err := bs.Compact(ctx, now); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	sets, err := bs.QueryAllocations(ctx, start, start.Add(24*time.Hour))
+	if err != nil || len(sets) != 1 {
+		t.Fatalf("expected windows to be rolled up into 1; got %d, %v", len(sets), err)
+	}
+
+	job, err = b.Run(ctx, start, start.Add(24*time.Hour), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if job.Skipped != 24 || job.Exported != 0 {
+		t.Errorf("expected rolled up windows to be skipped; got %s", job)
+	}
+	if source.allocationCalls != 24 {
+		t.Errorf("expected rolled up windows not to be recomputed; got %d computations", source.allocationCalls)
+	}
+}
@@ -0,0 +1,599 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"cloud.google.com/go/bigquery/storage/apiv1/storagepb"
+	"cloud.google.com/go/bigquery/storage/managedwriter"
+	"cloud.google.com/go/bigquery/storage/managedwriter/adapt"
+	"github.com/google/uuid"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/util/json"
+)
+
+// bigQueryAppendBatchSize is the number of rows sent per append request, which
+// keeps requests well below the 10MB limit of the Storage Write API.
+const bigQueryAppendBatchSize = 500
+
+// BigQueryConfig contains the destination of a BigQuerySink.
+type BigQueryConfig struct {
+	ProjectID string
+	Dataset   string
+
+	// Location is used when the dataset needs to be created, e.g. "US".
+	Location string
+
+	AllocationTable string
+	AssetTable      string
+
+	// CredentialsFile is the path to a service account key. If empty,
+	// application default credentials are used.
+	CredentialsFile string
+}
+
+// bigQueryLabelSchema is the schema of the repeated key/value label records.
+var bigQueryLabelSchema = bigquery.Schema{
+	{Name: "key", Type: bigquery.StringFieldType},
+	{Name: "value", Type: bigquery.StringFieldType},
+}
+
+// BigQueryAllocationSchema is the schema of the allocation table. New columns
+// may be appended; existing tables are migrated by adding missing columns.
+var BigQueryAllocationSchema = bigquery.Schema{
+	{Name: "window_start", Type: bigquery.TimestampFieldType},
+	{Name: "window_end", Type: bigquery.TimestampFieldType},
+	{Name: "export_id", Type: bigquery.StringFieldType},
+	{Name: "name", Type: bigquery.StringFieldType},
+	{Name: "cluster", Type: bigquery.StringFieldType},
+	{Name: "node", Type: bigquery.StringFieldType},
+	{Name: "namespace", Type: bigquery.StringFieldType},
+	{Name: "controller_kind", Type: bigquery.StringFieldType},
+	{Name: "controller", Type: bigquery.StringFieldType},
+	{Name: "pod", Type: bigquery.StringFieldType},
+	{Name: "container", Type: bigquery.StringFieldType},
+	{Name: "labels", Type: bigquery.RecordFieldType, Repeated: true, Schema: bigQueryLabelSchema},
+	{Name: "minutes", Type: bigquery.FloatFieldType},
+	{Name: "cpu_core_hours", Type: bigquery.FloatFieldType},
+	{Name: "cpu_core_request_average", Type: bigquery.FloatFieldType},
+	{Name: "cpu_core_usage_average", Type: bigquery.FloatFieldType},
+	{Name: "ram_byte_hours", Type: bigquery.FloatFieldType},
+	{Name: "ram_bytes_request_average", Type: bigquery.FloatFieldType},
+	{Name: "ram_bytes_usage_average", Type: bigquery.FloatFieldType},
+	{Name: "gpu_hours", Type: bigquery.FloatFieldType},
+	{Name: "pv_byte_hours", Type: bigquery.FloatFieldType},
+	{Name: "network_transfer_bytes", Type: bigquery.FloatFieldType},
+	{Name: "network_receive_bytes", Type: bigquery.FloatFieldType},
+	{Name: "cpu_cost", Type: bigquery.FloatFieldType},
+	{Name: "gpu_cost", Type: bigquery.FloatFieldType},
+	{Name: "ram_cost", Type: bigquery.FloatFieldType},
+	{Name: "pv_cost", Type: bigquery.FloatFieldType},
+	{Name: "network_cost", Type: bigquery.FloatFieldType},
+	{Name: "load_balancer_cost", Type: bigquery.FloatFieldType},
+	{Name: "shared_cost", Type: bigquery.FloatFieldType},
+	{Name: "external_cost", Type: bigquery.FloatFieldType},
+	{Name: "total_cost", Type: bigquery.FloatFieldType},
+}
+
+// BigQueryAssetSchema is the schema of the asset table.
+var BigQueryAssetSchema = bigquery.Schema{
+	{Name: "window_start", Type: bigquery.TimestampFieldType},
+	{Name: "window_end", Type: bigquery.TimestampFieldType},
+	{Name: "export_id", Type: bigquery.StringFieldType},
+	{Name: "type", Type: bigquery.StringFieldType},
+	{Name: "category", Type: bigquery.StringFieldType},
+	{Name: "provider", Type: bigquery.StringFieldType},
+	{Name: "account", Type: bigquery.StringFieldType},
+	{Name: "project", Type: bigquery.StringFieldType},
+	{Name: "service", Type: bigquery.StringFieldType},
+	{Name: "cluster", Type: bigquery.StringFieldType},
+	{Name: "name", Type: bigquery.StringFieldType},
+	{Name: "provider_id", Type: bigquery.StringFieldType},
+	{Name: "labels", Type: bigquery.RecordFieldType, Repeated: true, Schema: bigQueryLabelSchema},
+	{Name: "minutes", Type: bigquery.FloatFieldType},
+	{Name: "adjustment", Type: bigquery.FloatFieldType},
+	{Name: "total_cost", Type: bigquery.FloatFieldType},
+}
+
+// bigQueryClustering lists the clustering columns of each table, in order of
+// expected filter selectivity.
+var (
+	bigQueryAllocationClustering = []string{"cluster", "namespace", "controller_kind", "controller"}
+	bigQueryAssetClustering      = []string{"cluster", "type", "provider_id"}
+)
+
+// BigQueryClient is the subset of BigQuery operations used by a BigQuerySink.
+// Datasets and tables are identified by name within the configured project.
+type BigQueryClient interface {
+	DatasetMetadata(ctx context.Context, dataset string) (*bigquery.DatasetMetadata, error)
+	CreateDataset(ctx context.Context, dataset string, meta *bigquery.DatasetMetadata) error
+	TableMetadata(ctx context.Context, dataset, table string) (*bigquery.TableMetadata, error)
+	CreateTable(ctx context.Context, dataset, table string, meta *bigquery.TableMetadata) error
+	UpdateTable(ctx context.Context, dataset, table string, update bigquery.TableMetadataToUpdate, etag string) error
+
+	// Query runs a statement in the location and waits for it to complete.
+	Query(ctx context.Context, statement string, params []bigquery.QueryParameter, location string) error
+
+	// AppendRows writes batches of rows, encoded with the descriptor, to the
+	// table and commits them atomically.
+	AppendRows(ctx context.Context, dataset, table string, descriptor *descriptorpb.DescriptorProto, batches [][][]byte) error
+
+	Close() error
+}
+
+// BigQuerySink writes finalized windows into BigQuery tables which are
+// partitioned by day on window_start and clustered by cluster and namespace
+// (or asset type), so they can be joined against the GCP billing export.
+//
+// Rows are written with the Storage Write API using a pending stream, so each
+// window is committed atomically. Every export of a window is tagged with a
+// unique export_id and, once committed, rows from previous exports of the
+// same window are deleted. Re-exporting a window, e.g. when backfilling, is
+// therefore idempotent.
+type BigQuerySink struct {
+	config *BigQueryConfig
+	client BigQueryClient
+
+	lock    sync.Mutex
+	ensured map[string]bool
+}
+
+// NewBigQuerySink creates a new BigQuerySink for the configured dataset.
+// Tables and the dataset are created on first export if they do not exist.
+func NewBigQuerySink(ctx context.Context, config *BigQueryConfig) (*BigQuerySink, error) {
+	if config == nil || config.ProjectID == "" || config.Dataset == "" {
+		return nil, fmt.Errorf("bigquery project id and dataset are required")
+	}
+
+	var opts []option.ClientOption
+	if config.CredentialsFile != "" {
+		opts = append(opts, option.WithCredentialsFile(config.CredentialsFile))
+	}
+
+	client, err := bigquery.NewClient(ctx, config.ProjectID, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("creating bigquery client: %w", err)
+	}
+
+	writer, err := managedwriter.NewClient(ctx, config.ProjectID, opts...)
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("creating bigquery storage write client: %w", err)
+	}
+
+	return NewBigQuerySinkWithClient(config, &bigQueryAPIClient{projectID: config.ProjectID, client: client, writer: writer})
+}
+
+// NewBigQuerySinkWithClient creates a new BigQuerySink writing through the
+// provided client.
+func NewBigQuerySinkWithClient(config *BigQueryConfig, client BigQueryClient) (*BigQuerySink, error) {
+	if config == nil || config.ProjectID == "" || config.Dataset == "" {
+		return nil, fmt.Errorf("bigquery project id and dataset are required")
+	}
+	if config.AllocationTable == "" {
+		config.AllocationTable = "allocations"
+	}
+	if config.AssetTable == "" {
+		config.AssetTable = "assets"
+	}
+
+	return &BigQuerySink{
+		config:  config,
+		client:  client,
+		ensured: make(map[string]bool),
+	}, nil
+}
+
+// Name returns the unique identifier for the sink.
+func (bs *BigQuerySink) Name() string {
+	return fmt.Sprintf("bigquery-%s.%s", bs.config.ProjectID, bs.config.Dataset)
+}
+
+// Close releases the clients held by the sink.
+func (bs *BigQuerySink) Close() error {
+	return bs.client.Close()
+}
+
+// ExportAllocations replaces the allocation rows of the window.
+func (bs *BigQuerySink) ExportAllocations(ctx context.Context, window kubecost.Window, as *kubecost.AllocationSet) error {
+	var rows []map[string]interface{}
+	if as != nil {
+		names := make([]string, 0, len(as.Allocations))
+		for name := range as.Allocations {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			rows = append(rows, BigQueryAllocationRow(as.Allocations[name]))
+		}
+	}
+
+	return bs.replaceWindow(ctx, bs.config.AllocationTable, BigQueryAllocationSchema, bigQueryAllocationClustering, window, rows)
+}
+
+// ExportAssets replaces the asset rows of the window.
+func (bs *BigQuerySink) ExportAssets(ctx context.Context, window kubecost.Window, as *kubecost.AssetSet) error {
+	var rows []map[string]interface{}
+	if as != nil {
+		keys := make([]string, 0, len(as.Assets))
+		for key := range as.Assets {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			rows = append(rows, BigQueryAssetRow(as.Assets[key]))
+		}
+	}
+
+	return bs.replaceWindow(ctx, bs.config.AssetTable, BigQueryAssetSchema, bigQueryAssetClustering, window, rows)
+}
+
+// BigQueryAllocationRow returns the column values of an allocation, keyed by
+// BigQueryAllocationSchema field name. Window columns are set on export.
+func BigQueryAllocationRow(alloc *kubecost.Allocation) map[string]interface{} {
+	props := alloc.Properties
+	if props == nil {
+		props = &kubecost.AllocationProperties{}
+	}
+
+	return map[string]interface{}{
+		"name":                      alloc.Name,
+		"cluster":                   props.Cluster,
+		"node":                      props.Node,
+		"namespace":                 props.Namespace,
+		"controller_kind":           props.ControllerKind,
+		"controller":                props.Controller,
+		"pod":                       props.Pod,
+		"container":                 props.Container,
+		"labels":                    bigQueryLabels(props.Labels),
+		"minutes":                   alloc.Minutes(),
+		"cpu_core_hours":            alloc.CPUCoreHours,
+		"cpu_core_request_average":  alloc.CPUCoreRequestAverage,
+		"cpu_core_usage_average":    alloc.CPUCoreUsageAverage,
+		"ram_byte_hours":            alloc.RAMByteHours,
+		"ram_bytes_request_average": alloc.RAMBytesRequestAverage,
+		"ram_bytes_usage_average":   alloc.RAMBytesUsageAverage,
+		"gpu_hours":                 alloc.GPUHours,
+		"pv_byte_hours":             alloc.PVByteHours(),
+		"network_transfer_bytes":    alloc.NetworkTransferBytes,
+		"network_receive_bytes":     alloc.NetworkReceiveBytes,
+		"cpu_cost":                  alloc.CPUTotalCost(),
+		"gpu_cost":                  alloc.GPUTotalCost(),
+		"ram_cost":                  alloc.RAMTotalCost(),
+		"pv_cost":                   alloc.PVTotalCost(),
+		"network_cost":              alloc.NetworkTotalCost(),
+		"load_balancer_cost":        alloc.LBTotalCost(),
+		"shared_cost":               alloc.SharedTotalCost(),
+		"external_cost":             alloc.ExternalCost,
+		"total_cost":                alloc.TotalCost(),
+	}
+}
+
+// BigQueryAssetRow returns the column values of an asset, keyed by
+// BigQueryAssetSchema field name. Window columns are set on export.
+func BigQueryAssetRow(asset kubecost.Asset) map[string]interface{} {
+	props := asset.GetProperties()
+	if props == nil {
+		props = &kubecost.AssetProperties{}
+	}
+
+	return map[string]interface{}{
+		"type":        asset.Type().String(),
+		"category":    props.Category,
+		"provider":    props.Provider,
+		"account":     props.Account,
+		"project":     props.Project,
+		"service":     props.Service,
+		"cluster":     props.Cluster,
+		"name":        props.Name,
+		"provider_id": props.ProviderID,
+		"labels":      bigQueryLabels(asset.GetLabels()),
+		"minutes":     asset.Minutes(),
+		"adjustment":  asset.GetAdjustment(),
+		"total_cost":  asset.TotalCost(),
+	}
+}
+
+func bigQueryLabels[M ~map[string]string](labels M) []map[string]string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	records := make([]map[string]string, 0, len(keys))
+	for _, k := range keys {
+		records = append(records, map[string]string{"key": k, "value": labels[k]})
+	}
+	return records
+}
+
+// replaceWindow atomically appends the rows for the window to the table, then
+// deletes any rows written by previous exports of the same window.
+func (bs *BigQuerySink) replaceWindow(ctx context.Context, tableID string, schema bigquery.Schema, clustering []string, window kubecost.Window, rows []map[string]interface{}) error {
+	if err := bs.ensureTable(ctx, tableID, schema, clustering); err != nil {
+		return err
+	}
+
+	exportID := uuid.NewString()
+	start, end := window.Start().UTC(), window.End().UTC()
+
+	if err := bs.appendRows(ctx, tableID, schema, start, end, exportID, rows); err != nil {
+		return fmt.Errorf("appending rows to %s: %w", tableID, err)
+	}
+
+	statement := fmt.Sprintf(
+		"DELETE FROM `%s.%s.%s` WHERE window_start = @start AND window_end = @end AND (export_id IS NULL OR export_id != @export_id)",
+		bs.config.ProjectID, bs.config.Dataset, tableID,
+	)
+	params := []bigquery.QueryParameter{
+		{Name: "start", Value: start},
+		{Name: "end", Value: end},
+		{Name: "export_id", Value: exportID},
+	}
+	if err := bs.client.Query(ctx, statement, params, bs.config.Location); err != nil {
+		return fmt.Errorf("deleting previous rows from %s: %w", tableID, err)
+	}
+
+	return nil
+}
+
+// appendRows encodes the rows in batches and appends them to the table.
+func (bs *BigQuerySink) appendRows(ctx context.Context, tableID string, schema bigquery.Schema, start, end time.Time, exportID string, rows []map[string]interface{}) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	descriptor, descriptorProto, err := bigQueryDescriptor(schema)
+	if err != nil {
+		return err
+	}
+
+	var batches [][][]byte
+	batch := make([][]byte, 0, bigQueryAppendBatchSize)
+	for _, row := range rows {
+		row["window_start"] = start.UnixMicro()
+		row["window_end"] = end.UnixMicro()
+		row["export_id"] = exportID
+
+		b, err := bigQueryEncodeRow(descriptor, row)
+		if err != nil {
+			return err
+		}
+
+		batch = append(batch, b)
+		if len(batch) == bigQueryAppendBatchSize {
+			batches = append(batches, batch)
+			batch = make([][]byte, 0, bigQueryAppendBatchSize)
+		}
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+
+	return bs.client.AppendRows(ctx, bs.config.Dataset, tableID, descriptorProto, batches)
+}
+
+// ensureTable creates the dataset and table if they do not exist and adds any
+// columns of the schema which are missing from an existing table. Each table
+// is checked once per sink.
+func (bs *BigQuerySink) ensureTable(ctx context.Context, tableID string, schema bigquery.Schema, clustering []string) error {
+	bs.lock.Lock()
+	defer bs.lock.Unlock()
+
+	if bs.ensured[tableID] {
+		return nil
+	}
+
+	dataset := bs.config.Dataset
+	if _, err := bs.client.DatasetMetadata(ctx, dataset); err != nil {
+		if !isBigQueryNotFound(err) {
+			return fmt.Errorf("reading dataset %s: %w", dataset, err)
+		}
+		if err := bs.client.CreateDataset(ctx, dataset, &bigquery.DatasetMetadata{Location: bs.config.Location}); err != nil {
+			return fmt.Errorf("creating dataset %s: %w", dataset, err)
+		}
+	}
+
+	meta, err := bs.client.TableMetadata(ctx, dataset, tableID)
+	if err != nil {
+		if !isBigQueryNotFound(err) {
+			return fmt.Errorf("reading table %s: %w", tableID, err)
+		}
+
+		err = bs.client.CreateTable(ctx, dataset, tableID, &bigquery.TableMetadata{
+			Schema: schema,
+			TimePartitioning: &bigquery.TimePartitioning{
+				Type:  bigquery.DayPartitioningType,
+				Field: "window_start",
+			},
+			Clustering: &bigquery.Clustering{Fields: clustering},
+		})
+		if err != nil {
+			return fmt.Errorf("creating table %s: %w", tableID, err)
+		}
+
+		bs.ensured[tableID] = true
+		return nil
+	}
+
+	existing := make(map[string]bool, len(meta.Schema))
+	for _, field := range meta.Schema {
+		existing[field.Name] = true
+	}
+
+	updated := append(bigquery.Schema{}, meta.Schema...)
+	for _, field := range schema {
+		if !existing[field.Name] {
+			updated = append(updated, field)
+		}
+	}
+
+	if len(updated) > len(meta.Schema) {
+		err := bs.client.UpdateTable(ctx, dataset, tableID, bigquery.TableMetadataToUpdate{Schema: updated}, meta.ETag)
+		if err != nil {
+			return fmt.Errorf("updating schema of table %s: %w", tableID, err)
+		}
+	}
+
+	bs.ensured[tableID] = true
+	return nil
+}
+
+// bigQueryDescriptor converts a table schema into the protocol buffer
+// descriptor used to encode rows for the Storage Write API.
+func bigQueryDescriptor(schema bigquery.Schema) (protoreflect.MessageDescriptor, *descriptorpb.DescriptorProto, error) {
+	storageSchema, err := adapt.BQSchemaToStorageTableSchema(schema)
+	if err != nil {
+		return nil, nil, fmt.Errorf("converting schema: %w", err)
+	}
+
+	d, err := adapt.StorageSchemaToProto2Descriptor(storageSchema, "root")
+	if err != nil {
+		return nil, nil, fmt.Errorf("building descriptor: %w", err)
+	}
+
+	md, ok := d.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, nil, fmt.Errorf("building descriptor: unexpected type %T", d)
+	}
+
+	dp, err := adapt.NormalizeDescriptor(md)
+	if err != nil {
+		return nil, nil, fmt.Errorf("normalizing descriptor: %w", err)
+	}
+
+	return md, dp, nil
+}
+
+// bigQueryEncodeRow serializes a row to the binary protocol buffer format of
+// the descriptor.
+func bigQueryEncodeRow(descriptor protoreflect.MessageDescriptor, row map[string]interface{}) ([]byte, error) {
+	b, err := json.Marshal(row)
+	if err != nil {
+		return nil, fmt.Errorf("encoding row: %w", err)
+	}
+
+	msg := dynamicpb.NewMessage(descriptor)
+	if err := protojson.Unmarshal(b, msg); err != nil {
+		return nil, fmt.Errorf("encoding row: %w", err)
+	}
+
+	return proto.Marshal(msg)
+}
+
+func isBigQueryNotFound(err error) bool {
+	apiErr, ok := err.(*googleapi.Error)
+	return ok && apiErr.Code == http.StatusNotFound
+}
+
+// bigQueryAPIClient is the BigQueryClient of a BigQuerySink, which manages
+// tables through the BigQuery API and writes rows with the Storage Write API.
+type bigQueryAPIClient struct {
+	projectID string
+	client    *bigquery.Client
+	writer    *managedwriter.Client
+}
+
+func (c *bigQueryAPIClient) DatasetMetadata(ctx context.Context, dataset string) (*bigquery.DatasetMetadata, error) {
+	return c.client.Dataset(dataset).Metadata(ctx)
+}
+
+func (c *bigQueryAPIClient) CreateDataset(ctx context.Context, dataset string, meta *bigquery.DatasetMetadata) error {
+	return c.client.Dataset(dataset).Create(ctx, meta)
+}
+
+func (c *bigQueryAPIClient) TableMetadata(ctx context.Context, dataset, table string) (*bigquery.TableMetadata, error) {
+	return c.client.Dataset(dataset).Table(table).Metadata(ctx)
+}
+
+func (c *bigQueryAPIClient) CreateTable(ctx context.Context, dataset, table string, meta *bigquery.TableMetadata) error {
+	return c.client.Dataset(dataset).Table(table).Create(ctx, meta)
+}
+
+func (c *bigQueryAPIClient) UpdateTable(ctx context.Context, dataset, table string, update bigquery.TableMetadataToUpdate, etag string) error {
+	_, err := c.client.Dataset(dataset).Table(table).Update(ctx, update, etag)
+	return err
+}
+
+func (c *bigQueryAPIClient) Query(ctx context.Context, statement string, params []bigquery.QueryParameter, location string) error {
+	q := c.client.Query(statement)
+	q.Parameters = params
+	q.Location = location
+
+	job, err := q.Run(ctx)
+	if err != nil {
+		return err
+	}
+	status, err := job.Wait(ctx)
+	if err != nil {
+		return err
+	}
+	return status.Err()
+}
+
+// AppendRows writes the batches to a pending stream and commits it once all
+// batches have been acknowledged.
+func (c *bigQueryAPIClient) AppendRows(ctx context.Context, dataset, table string, descriptor *descriptorpb.DescriptorProto, batches [][][]byte) error {
+	stream, err := c.writer.NewManagedStream(ctx,
+		managedwriter.WithDestinationTable(managedwriter.TableParentFromParts(c.projectID, dataset, table)),
+		managedwriter.WithType(managedwriter.PendingStream),
+		managedwriter.WithSchemaDescriptor(descriptor),
+	)
+	if err != nil {
+		return fmt.Errorf("opening write stream: %w", err)
+	}
+	defer stream.Close()
+
+	results := make([]*managedwriter.AppendResult, 0, len(batches))
+	for _, batch := range batches {
+		result, err := stream.AppendRows(ctx, batch)
+		if err != nil {
+			return err
+		}
+		results = append(results, result)
+	}
+
+	for _, result := range results {
+		if _, err := result.GetResult(ctx); err != nil {
+			return err
+		}
+	}
+
+	if _, err := stream.Finalize(ctx); err != nil {
+		return fmt.Errorf("finalizing write stream: %w", err)
+	}
+
+	resp, err := c.writer.BatchCommitWriteStreams(ctx, &storagepb.BatchCommitWriteStreamsRequest{
+		Parent:       managedwriter.TableParentFromStreamName(stream.StreamName()),
+		WriteStreams: []string{stream.StreamName()},
+	})
+	if err != nil {
+		return fmt.Errorf("committing write stream: %w", err)
+	}
+	if errs := resp.GetStreamErrors(); len(errs) > 0 {
+		return fmt.Errorf("committing write stream: %s", errs[0].GetErrorMessage())
+	}
+
+	return nil
+}
+
+func (c *bigQueryAPIClient) Close() error {
+	c.writer.Close()
+	return c.client.Close()
+}
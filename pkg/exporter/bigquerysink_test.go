@@ -0,0 +1,455 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+)
+
+type bigQueryQuery struct {
+	statement string
+	params    []bigquery.QueryParameter
+}
+
+type bigQueryAppend struct {
+	table   string
+	batches [][][]byte
+}
+
+// fakeBigQueryClient records the operations of a BigQuerySink. Errors queued
+// in appendErrs are returned by successive AppendRows calls.
+type fakeBigQueryClient struct {
+	lock sync.Mutex
+
+	datasets map[string]*bigquery.DatasetMetadata
+	tables   map[string]*bigquery.TableMetadata
+	updates  map[string]bigquery.TableMetadataToUpdate
+	queries  []bigQueryQuery
+	appends  []bigQueryAppend
+
+	datasetErr error
+	queryErr   error
+	appendErrs []error
+}
+
+func newFakeBigQueryClient() *fakeBigQueryClient {
+	return &fakeBigQueryClient{
+		datasets: make(map[string]*bigquery.DatasetMetadata),
+		tables:   make(map[string]*bigquery.TableMetadata),
+		updates:  make(map[string]bigquery.TableMetadataToUpdate),
+	}
+}
+
+func bigQueryNotFound() error {
+	return &googleapi.Error{Code: http.StatusNotFound}
+}
+
+func (c *fakeBigQueryClient) DatasetMetadata(ctx context.Context, dataset string) (*bigquery.DatasetMetadata, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.datasetErr != nil {
+		return nil, c.datasetErr
+	}
+	meta, ok := c.datasets[dataset]
+	if !ok {
+		return nil, bigQueryNotFound()
+	}
+	return meta, nil
+}
+
+func (c *fakeBigQueryClient) CreateDataset(ctx context.Context, dataset string, meta *bigquery.DatasetMetadata) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.datasets[dataset] = meta
+	return nil
+}
+
+func (c *fakeBigQueryClient) TableMetadata(ctx context.Context, dataset, table string) (*bigquery.TableMetadata, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	meta, ok := c.tables[dataset+"."+table]
+	if !ok {
+		return nil, bigQueryNotFound()
+	}
+	return meta, nil
+}
+
+func (c *fakeBigQueryClient) CreateTable(ctx context.Context, dataset, table string, meta *bigquery.TableMetadata) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.tables[dataset+"."+table] = meta
+	return nil
+}
+
+func (c *fakeBigQueryClient) UpdateTable(ctx context.Context, dataset, table string, update bigquery.TableMetadataToUpdate, etag string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.updates[dataset+"."+table] = update
+	return nil
+}
+
+func (c *fakeBigQueryClient) Query(ctx context.Context, statement string, params []bigquery.QueryParameter, location string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.queryErr != nil {
+		return c.queryErr
+	}
+	c.queries = append(c.queries, bigQueryQuery{statement: statement, params: params})
+	return nil
+}
+
+func (c *fakeBigQueryClient) AppendRows(ctx context.Context, dataset, table string, descriptor *descriptorpb.DescriptorProto, batches [][][]byte) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if len(c.appendErrs) > 0 {
+		err := c.appendErrs[0]
+		c.appendErrs = c.appendErrs[1:]
+		if err != nil {
+			return err
+		}
+	}
+	c.appends = append(c.appends, bigQueryAppend{table: table, batches: batches})
+	return nil
+}
+
+func (c *fakeBigQueryClient) Close() error {
+	return nil
+}
+
+// bigQuerySource computes windows with a single allocation and no assets.
+type bigQuerySource struct{}
+
+func (bigQuerySource) ComputeAllocation(start, end time.Time, resolution time.Duration) (*kubecost.AllocationSet, error) {
+	return testBigQueryAllocationSet(start, end, 1), nil
+}
+
+func (bigQuerySource) ComputeAssets(start, end time.Time) (*kubecost.AssetSet, error) {
+	return kubecost.NewAssetSet(start, end), nil
+}
+
+func newTestBigQuerySink(t *testing.T, client BigQueryClient) *BigQuerySink {
+	sink, err := NewBigQuerySinkWithClient(&BigQueryConfig{ProjectID: "project", Dataset: "opencost", Location: "US"}, client)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return sink
+}
+
+// decodeBigQueryRow decodes a row appended with the descriptor of the schema.
+func decodeBigQueryRow(t *testing.T, schema bigquery.Schema, b []byte) (protoreflect.MessageDescriptor, *dynamicpb.Message) {
+	descriptor, _, err := bigQueryDescriptor(schema)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	msg := dynamicpb.NewMessage(descriptor)
+	if err := proto.Unmarshal(b, msg); err != nil {
+		t.Fatalf("unexpected error decoding row: %s", err)
+	}
+	return descriptor, msg
+}
+
+func testBigQueryAllocationSet(start, end time.Time, count int) *kubecost.AllocationSet {
+	as := kubecost.NewAllocationSet(start, end)
+	for i := 0; i < count; i++ {
+		alloc := kubecost.NewMockUnitAllocation(fmt.Sprintf("cluster1/node1/ns1/pod%d/container", i), start, end.Sub(start), &kubecost.AllocationProperties{
+			Cluster:   "cluster1",
+			Node:      "node1",
+			Namespace: "ns1",
+			Pod:       fmt.Sprintf("pod%d", i),
+			Container: "container",
+			Labels:    kubecost.AllocationLabels{"team": "x", "app": "y"},
+		})
+		as.Set(alloc)
+	}
+	return as
+}
+
+func TestBigQueryAllocationRow(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	alloc := kubecost.NewMockUnitAllocation("cluster1/node1/ns1/pod1/container", start, 24*time.Hour, &kubecost.AllocationProperties{
+		Cluster:        "cluster1",
+		Node:           "node1",
+		Namespace:      "ns1",
+		ControllerKind: "deployment",
+		Controller:     "web",
+		Pod:            "pod1",
+		Container:      "container",
+		Labels:         kubecost.AllocationLabels{"team": "x", "app": "y"},
+	})
+
+	row := BigQueryAllocationRow(alloc)
+
+	for name, expected := range map[string]interface{}{
+		"name":            "cluster1/node1/ns1/pod1/container",
+		"cluster":         "cluster1",
+		"namespace":       "ns1",
+		"controller_kind": "deployment",
+		"controller":      "web",
+		"cpu_cost":        alloc.CPUTotalCost(),
+		"total_cost":      alloc.TotalCost(),
+		"minutes":         alloc.Minutes(),
+	} {
+		if row[name] != expected {
+			t.Errorf("expected %s to be %v; got %v", name, expected, row[name])
+		}
+	}
+
+	labels := row["labels"].([]map[string]string)
+	if len(labels) != 2 || labels[0]["key"] != "app" || labels[1]["key"] != "team" || labels[1]["value"] != "x" {
+		t.Errorf("expected labels sorted by key; got %v", labels)
+	}
+
+	// every column of the row is in the schema, and only the window columns
+	// are left to be set on export
+	columns := make(map[string]bool, len(BigQueryAllocationSchema))
+	for _, field := range BigQueryAllocationSchema {
+		columns[field.Name] = true
+	}
+	for name := range row {
+		if !columns[name] {
+			t.Errorf("column %s is not in the schema", name)
+		}
+	}
+	if len(row) != len(BigQueryAllocationSchema)-3 {
+		t.Errorf("expected %d columns; got %d", len(BigQueryAllocationSchema)-3, len(row))
+	}
+}
+
+func TestBigQueryAssetRow(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	node := kubecost.NewNode("node1", "cluster1", "gke-node1", start, end, kubecost.NewClosedWindow(start, end))
+	node.CPUCost = 2
+	node.RAMCost = 1
+	node.SetAdjustment(-0.5)
+	node.SetLabels(kubecost.AssetLabels{"pool": "default"})
+
+	row := BigQueryAssetRow(node)
+
+	for name, expected := range map[string]interface{}{
+		"type":        "Node",
+		"category":    kubecost.ComputeCategory,
+		"cluster":     "cluster1",
+		"name":        "node1",
+		"provider_id": "gke-node1",
+		"adjustment":  -0.5,
+		"total_cost":  2.5,
+	} {
+		if row[name] != expected {
+			t.Errorf("expected %s to be %v; got %v", name, expected, row[name])
+		}
+	}
+	if len(row) != len(BigQueryAssetSchema)-3 {
+		t.Errorf("expected %d columns; got %d", len(BigQueryAssetSchema)-3, len(row))
+	}
+}
+
+func TestBigQuerySink_EnsureTable(t *testing.T) {
+	ctx := context.Background()
+
+	client := newFakeBigQueryClient()
+	sink := newTestBigQuerySink(t, client)
+
+	if err := sink.ensureTable(ctx, "allocations", BigQueryAllocationSchema, bigQueryAllocationClustering); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if meta, ok := client.datasets["opencost"]; !ok || meta.Location != "US" {
+		t.Fatalf("expected dataset to be created in US; got %v", meta)
+	}
+	meta, ok := client.tables["opencost.allocations"]
+	if !ok {
+		t.Fatalf("expected table to be created")
+	}
+	if len(meta.Schema) != len(BigQueryAllocationSchema) {
+		t.Errorf("expected %d columns; got %d", len(BigQueryAllocationSchema), len(meta.Schema))
+	}
+	if meta.TimePartitioning == nil || meta.TimePartitioning.Field != "window_start" {
+		t.Errorf("expected partitioning on window_start; got %v", meta.TimePartitioning)
+	}
+	if meta.Clustering == nil || len(meta.Clustering.Fields) != 4 || meta.Clustering.Fields[0] != "cluster" {
+		t.Errorf("expected clustering by cluster first; got %v", meta.Clustering)
+	}
+
+	// an existing table missing columns is migrated by appending them
+	client = newFakeBigQueryClient()
+	client.datasets["opencost"] = &bigquery.DatasetMetadata{}
+	client.tables["opencost.assets"] = &bigquery.TableMetadata{Schema: BigQueryAssetSchema[:5], ETag: "etag"}
+	sink = newTestBigQuerySink(t, client)
+
+	for i := 0; i < 2; i++ {
+		if err := sink.ensureTable(ctx, "assets", BigQueryAssetSchema, bigQueryAssetClustering); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		// checked once per sink
+		delete(client.tables, "opencost.assets")
+	}
+	if _, ok := client.tables["opencost.assets"]; ok {
+		t.Errorf("expected table to be checked once")
+	}
+	update, ok := client.updates["opencost.assets"]
+	if !ok {
+		t.Fatalf("expected table schema to be updated")
+	}
+	if len(update.Schema) != len(BigQueryAssetSchema) || update.Schema[5].Name != BigQueryAssetSchema[5].Name {
+		t.Errorf("expected missing columns appended in schema order; got %v", update.Schema)
+	}
+
+	// errors other than not found are not treated as a missing dataset
+	client = newFakeBigQueryClient()
+	client.datasetErr = &googleapi.Error{Code: http.StatusForbidden}
+	sink = newTestBigQuerySink(t, client)
+	if err := sink.ensureTable(ctx, "assets", BigQueryAssetSchema, bigQueryAssetClustering); err == nil {
+		t.Errorf("expected error reading dataset")
+	}
+	if len(client.datasets) != 0 {
+		t.Errorf("expected dataset not to be created")
+	}
+}
+
+func TestBigQuerySink_ExportAllocations(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	window := kubecost.NewClosedWindow(start, end)
+
+	client := newFakeBigQueryClient()
+	sink := newTestBigQuerySink(t, client)
+
+	// rows are appended in batches
+	count := bigQueryAppendBatchSize + 1
+	if err := sink.ExportAllocations(ctx, window, testBigQueryAllocationSet(start, end, count)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(client.appends) != 1 {
+		t.Fatalf("expected 1 append; got %d", len(client.appends))
+	}
+	appended := client.appends[0]
+	if appended.table != "allocations" {
+		t.Errorf("expected append to allocations; got %s", appended.table)
+	}
+	if len(appended.batches) != 2 || len(appended.batches[0]) != bigQueryAppendBatchSize || len(appended.batches[1]) != 1 {
+		t.Fatalf("expected batches of %d and 1 rows; got %d batches", bigQueryAppendBatchSize, len(appended.batches))
+	}
+
+	descriptor, row := decodeBigQueryRow(t, BigQueryAllocationSchema, appended.batches[0][0])
+	field := func(name string) protoreflect.Value {
+		return row.Get(descriptor.Fields().ByName(protoreflect.Name(name)))
+	}
+	if field("name").String() != "cluster1/node1/ns1/pod0/container" {
+		t.Errorf("expected first row to be pod0; got %s", field("name").String())
+	}
+	if field("window_start").Int() != start.UnixMicro() || field("window_end").Int() != end.UnixMicro() {
+		t.Errorf("expected window columns to be set")
+	}
+	if field("cpu_cost").Float() != 1 {
+		t.Errorf("expected cpu_cost 1; got %f", field("cpu_cost").Float())
+	}
+	if field("labels").List().Len() != 2 {
+		t.Errorf("expected 2 labels; got %d", field("labels").List().Len())
+	}
+	exportID := field("export_id").String()
+	if exportID == "" {
+		t.Fatalf("expected export_id to be set")
+	}
+
+	// rows of previous exports of the window are deleted once appended
+	if len(client.queries) != 1 {
+		t.Fatalf("expected 1 query; got %d", len(client.queries))
+	}
+	query := client.queries[0]
+	expected := "DELETE FROM `project.opencost.allocations` WHERE window_start = @start AND window_end = @end AND (export_id IS NULL OR export_id != @export_id)"
+	if query.statement != expected {
+		t.Errorf("expected %s; got %s", expected, query.statement)
+	}
+	params := make(map[string]interface{}, len(query.params))
+	for _, param := range query.params {
+		params[param.Name] = param.Value
+	}
+	if params["export_id"] != exportID || params["start"] != start || params["end"] != end {
+		t.Errorf("unexpected query parameters: %v", params)
+	}
+
+	// an empty window deletes previous rows without appending
+	if err := sink.ExportAllocations(ctx, window, nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(client.appends) != 1 || len(client.queries) != 2 {
+		t.Errorf("expected only a delete; got %d appends and %d queries", len(client.appends), len(client.queries))
+	}
+}
+
+func TestBigQuerySink_ExportErrors(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	window := kubecost.NewClosedWindow(start, end)
+	as := testBigQueryAllocationSet(start, end, 1)
+
+	// a failed append does not delete the rows of previous exports
+	client := newFakeBigQueryClient()
+	client.appendErrs = []error{fmt.Errorf("append failed")}
+	sink := newTestBigQuerySink(t, client)
+	if err := sink.ExportAllocations(ctx, window, as); err == nil {
+		t.Errorf("expected append error")
+	}
+	if len(client.queries) != 0 {
+		t.Errorf("expected no delete after a failed append; got %d", len(client.queries))
+	}
+
+	client = newFakeBigQueryClient()
+	client.queryErr = fmt.Errorf("query failed")
+	sink = newTestBigQuerySink(t, client)
+	if err := sink.ExportAllocations(ctx, window, as); err == nil {
+		t.Errorf("expected delete error")
+	}
+}
+
+func TestBigQuerySink_ExportRetries(t *testing.T) {
+	client := newFakeBigQueryClient()
+	client.appendErrs = []error{fmt.Errorf("append failed")}
+	sink := newTestBigQuerySink(t, client)
+
+	config := DefaultExporterConfig()
+	config.RetryAttempts = 2
+	config.RetryDelay = time.Millisecond
+
+	checkpoints := NewMemoryCheckpoints()
+	e := NewExporter(bigQuerySource{}, checkpoints, config, sink)
+	now := time.Date(2023, 1, 2, 12, 0, 0, 0, time.UTC)
+	e.now = func() time.Time { return now }
+
+	windows := e.FinalizedWindows(now)
+	e.exportWindows(context.Background(), windows[len(windows)-1:], nil)
+
+	// the second attempt succeeds, and the window is checkpointed
+	exported, err := checkpoints.IsExported(sink.Name(), windows[len(windows)-1])
+	if err != nil || !exported {
+		t.Fatalf("expected window to be exported after a retry; got %t, %v", exported, err)
+	}
+	if len(client.appendErrs) != 0 || len(client.appends) != 1 {
+		t.Errorf("expected a failed and a successful append; got %d successful", len(client.appends))
+	}
+	if len(client.queries) != 2 {
+		t.Errorf("expected a delete for allocations and assets; got %d", len(client.queries))
+	}
+}
@@ -0,0 +1,221 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/util/json"
+	"github.com/opencost/opencost/pkg/util/timeutil"
+)
+
+// BudgetPeriod is the period over which a budget's limit applies.
+type BudgetPeriod string
+
+const (
+	BudgetPeriodDay   BudgetPeriod = "day"
+	BudgetPeriodWeek  BudgetPeriod = "week"
+	BudgetPeriodMonth BudgetPeriod = "month"
+)
+
+// Hours returns the length of the period in hours.
+func (bp BudgetPeriod) Hours() float64 {
+	switch bp {
+	case BudgetPeriodDay:
+		return timeutil.HoursPerDay
+	case BudgetPeriodWeek:
+		return 7 * timeutil.HoursPerDay
+	default:
+		return timeutil.HoursPerMonth
+	}
+}
+
+// Budget limits the projected spend of the namespaces it selects. A budget
+// selects each namespace matching its cluster, namespace and labels; empty
+// fields match everything.
+type Budget struct {
+	Name      string            `json:"name"`
+	Cluster   string            `json:"cluster,omitempty"`
+	Namespace string            `json:"namespace,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Limit     float64           `json:"limit"`
+	Period    BudgetPeriod      `json:"period,omitempty"`
+}
+
+// Matches returns true if the budget selects the namespace gauge.
+func (b *Budget) Matches(gauge *CostGauge) bool {
+	if gauge.Level != CostGaugeLevelNamespace {
+		return false
+	}
+	if b.Cluster != "" && b.Cluster != gauge.Cluster {
+		return false
+	}
+	if b.Namespace != "" && b.Namespace != gauge.Namespace {
+		return false
+	}
+	for k, v := range b.Labels {
+		if gauge.Labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// LoadBudgets reads a JSON array of budgets from a file.
+func LoadBudgets(file string) ([]*Budget, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading budgets: %w", err)
+	}
+
+	var budgets []*Budget
+	if err := json.Unmarshal(b, &budgets); err != nil {
+		return nil, fmt.Errorf("parsing budgets: %w", err)
+	}
+
+	for i, budget := range budgets {
+		if budget.Name == "" {
+			return nil, fmt.Errorf("budget %d: name is required", i)
+		}
+		if err := budget.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return budgets, nil
+}
+
+// Validate checks the budget's limit and period, defaulting the period to a
+// month.
+func (b *Budget) Validate() error {
+	if b.Limit <= 0 {
+		return fmt.Errorf("budget %s: limit must be positive", b.Name)
+	}
+	switch b.Period {
+	case "":
+		b.Period = BudgetPeriodMonth
+	case BudgetPeriodDay, BudgetPeriodWeek, BudgetPeriodMonth:
+	default:
+		return fmt.Errorf("budget %s: unsupported period '%s'", b.Name, b.Period)
+	}
+	return nil
+}
+
+// BudgetBreach is raised when the cost rate of a namespace over the trailing
+// window projects spend above a budget's limit for the budget's period.
+type BudgetBreach struct {
+	Budget        *Budget
+	Cluster       string
+	Namespace     string
+	Labels        map[string]string
+	HourlyCost    float64
+	ProjectedCost float64
+	Window        kubecost.Window
+}
+
+func (bb *BudgetBreach) key() string {
+	return bb.Budget.Name + "/" + bb.Cluster + "/" + bb.Namespace
+}
+
+// String describes the breach for notifications.
+func (bb *BudgetBreach) String() string {
+	return fmt.Sprintf("Namespace %s in cluster %s is projected to spend %.2f per %s, exceeding budget %s of %.2f",
+		bb.Namespace, bb.Cluster, bb.ProjectedCost, bb.Budget.Period, bb.Budget.Name, bb.Budget.Limit)
+}
+
+// BudgetBreachedEvent is the data of a BudgetBreached event.
+type BudgetBreachedEvent struct {
+	Budget        string       `json:"budget"`
+	Limit         float64      `json:"limit"`
+	Period        BudgetPeriod `json:"period"`
+	Cluster       string       `json:"cluster"`
+	Namespace     string       `json:"namespace"`
+	HourlyCost    float64      `json:"hourlyCost"`
+	ProjectedCost float64      `json:"projectedCost"`
+	WindowStart   time.Time    `json:"windowStart"`
+	WindowEnd     time.Time    `json:"windowEnd"`
+}
+
+// Event returns the data of the BudgetBreached event for the breach.
+func (bb *BudgetBreach) Event() *BudgetBreachedEvent {
+	return &BudgetBreachedEvent{
+		Budget:        bb.Budget.Name,
+		Limit:         bb.Budget.Limit,
+		Period:        bb.Budget.Period,
+		Cluster:       bb.Cluster,
+		Namespace:     bb.Namespace,
+		HourlyCost:    bb.HourlyCost,
+		ProjectedCost: bb.ProjectedCost,
+		WindowStart:   bb.Window.Start().UTC(),
+		WindowEnd:     bb.Window.End().UTC(),
+	}
+}
+
+// EvaluateBudgets returns a breach for each namespace gauge exceeding a
+// budget which selects it, ordered by budget and namespace.
+func EvaluateBudgets(budgets []*Budget, window kubecost.Window, gauges []*CostGauge) []*BudgetBreach {
+	var breaches []*BudgetBreach
+	for _, budget := range budgets {
+		for _, gauge := range gauges {
+			if !budget.Matches(gauge) {
+				continue
+			}
+
+			projected := gauge.TotalCost * budget.Period.Hours()
+			if projected <= budget.Limit {
+				continue
+			}
+
+			breaches = append(breaches, &BudgetBreach{
+				Budget:        budget,
+				Cluster:       gauge.Cluster,
+				Namespace:     gauge.Namespace,
+				Labels:        gauge.Labels,
+				HourlyCost:    gauge.TotalCost,
+				ProjectedCost: projected,
+				Window:        window,
+			})
+		}
+	}
+
+	sort.SliceStable(breaches, func(i, j int) bool {
+		return breaches[i].key() < breaches[j].key()
+	})
+	return breaches
+}
+
+// BudgetBreachPublisher is implemented by CostMetricsPublishers which also
+// deliver budget breaches, e.g. as events or notifications.
+type BudgetBreachPublisher interface {
+	PublishBudgetBreaches(ctx context.Context, breaches []*BudgetBreach) error
+}
+
+// budgetTracker reports each breach once when it begins, and again when it
+// recurs after having recovered.
+type budgetTracker struct {
+	active map[string]bool
+}
+
+func newBudgetTracker() *budgetTracker {
+	return &budgetTracker{active: make(map[string]bool)}
+}
+
+// New returns the breaches which were not active in the previous evaluation.
+func (bt *budgetTracker) New(breaches []*BudgetBreach) []*BudgetBreach {
+	active := make(map[string]bool, len(breaches))
+
+	var result []*BudgetBreach
+	for _, breach := range breaches {
+		key := breach.key()
+		active[key] = true
+		if !bt.active[key] {
+			result = append(result, breach)
+		}
+	}
+
+	bt.active = active
+	return result
+}
@@ -0,0 +1,91 @@
+package exporter
+
+import (
+	"fmt"
+	"path"
+	"sync"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/storage"
+)
+
+// Checkpoints records which windows have been successfully exported to each
+// sink, so that windows are exported exactly once across restarts.
+type Checkpoints interface {
+	// IsExported returns true if the window has been exported to the sink.
+	IsExported(sink string, window kubecost.Window) (bool, error)
+
+	// SetExported records the window as exported to the sink.
+	SetExported(sink string, window kubecost.Window) error
+}
+
+// checkpointKey returns a unique key for the window, independent of any offset
+// used to format the window.
+func checkpointKey(window kubecost.Window) string {
+	return fmt.Sprintf("%d-%d", window.Start().Unix(), window.End().Unix())
+}
+
+// MemoryCheckpoints is an in-memory Checkpoints implementation. Exported windows
+// are lost on restart, which causes the lookback windows to be exported again.
+type MemoryCheckpoints struct {
+	lock     sync.Mutex
+	exported map[string]map[string]struct{}
+}
+
+// NewMemoryCheckpoints creates a new in-memory Checkpoints implementation.
+func NewMemoryCheckpoints() *MemoryCheckpoints {
+	return &MemoryCheckpoints{
+		exported: make(map[string]map[string]struct{}),
+	}
+}
+
+// IsExported returns true if the window has been exported to the sink.
+func (mc *MemoryCheckpoints) IsExported(sink string, window kubecost.Window) (bool, error) {
+	mc.lock.Lock()
+	defer mc.lock.Unlock()
+
+	_, ok := mc.exported[sink][checkpointKey(window)]
+	return ok, nil
+}
+
+// SetExported records the window as exported to the sink.
+func (mc *MemoryCheckpoints) SetExported(sink string, window kubecost.Window) error {
+	mc.lock.Lock()
+	defer mc.lock.Unlock()
+
+	if _, ok := mc.exported[sink]; !ok {
+		mc.exported[sink] = make(map[string]struct{})
+	}
+	mc.exported[sink][checkpointKey(window)] = struct{}{}
+	return nil
+}
+
+// StorageCheckpoints persists checkpoints as empty marker files in a Storage
+// implementation, laid out as <dir>/<sink>/<start>-<end>.
+type StorageCheckpoints struct {
+	store storage.Storage
+	dir   string
+}
+
+// NewStorageCheckpoints creates a new Checkpoints implementation persisting
+// markers in the given directory of the store.
+func NewStorageCheckpoints(store storage.Storage, dir string) *StorageCheckpoints {
+	return &StorageCheckpoints{
+		store: store,
+		dir:   dir,
+	}
+}
+
+func (sc *StorageCheckpoints) pathFor(sink string, window kubecost.Window) string {
+	return path.Join(sc.dir, sink, checkpointKey(window))
+}
+
+// IsExported returns true if the window has been exported to the sink.
+func (sc *StorageCheckpoints) IsExported(sink string, window kubecost.Window) (bool, error) {
+	return sc.store.Exists(sc.pathFor(sink, window))
+}
+
+// SetExported records the window as exported to the sink.
+func (sc *StorageCheckpoints) SetExported(sink string, window kubecost.Window) error {
+	return sc.store.Write(sc.pathFor(sink, window), []byte{})
+}
@@ -0,0 +1,309 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/opencost/opencost/pkg/errors"
+	"github.com/opencost/opencost/pkg/events"
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/log"
+	"github.com/opencost/opencost/pkg/util/atomic"
+)
+
+// CostGaugeLevel is the aggregation level of a CostGauge.
+type CostGaugeLevel string
+
+const (
+	CostGaugeLevelNamespace CostGaugeLevel = "namespace"
+	CostGaugeLevelWorkload  CostGaugeLevel = "workload"
+)
+
+// CostGauge contains the hourly cost rates of a namespace or workload over a
+// trailing window. Workloads are identified by their controller; pods without
+// a controller are grouped under an empty controller of their namespace.
+type CostGauge struct {
+	Level          CostGaugeLevel
+	Cluster        string
+	Namespace      string
+	ControllerKind string
+	Controller     string
+
+	// Labels are the labels shared by every allocation in the gauge.
+	Labels map[string]string
+
+	CPUCost          float64
+	GPUCost          float64
+	RAMCost          float64
+	PVCost           float64
+	NetworkCost      float64
+	LoadBalancerCost float64
+	SharedCost       float64
+	TotalCost        float64
+}
+
+// Costs returns the cost rates of the gauge keyed by resource name.
+func (cg *CostGauge) Costs() map[string]float64 {
+	return map[string]float64{
+		"cpu":           cg.CPUCost,
+		"gpu":           cg.GPUCost,
+		"ram":           cg.RAMCost,
+		"pv":            cg.PVCost,
+		"network":       cg.NetworkCost,
+		"load_balancer": cg.LoadBalancerCost,
+		"shared":        cg.SharedCost,
+		"total":         cg.TotalCost,
+	}
+}
+
+func (cg *CostGauge) key() string {
+	return string(cg.Level) + "/" + cg.Cluster + "/" + cg.Namespace + "/" + cg.ControllerKind + "/" + cg.Controller
+}
+
+func (cg *CostGauge) add(alloc *kubecost.Allocation, labels map[string]string, hours float64) {
+	if cg.Labels == nil {
+		cg.Labels = labels
+	} else {
+		for k, v := range cg.Labels {
+			if labels[k] != v {
+				delete(cg.Labels, k)
+			}
+		}
+	}
+
+	cg.CPUCost += alloc.CPUTotalCost() / hours
+	cg.GPUCost += alloc.GPUTotalCost() / hours
+	cg.RAMCost += alloc.RAMTotalCost() / hours
+	cg.PVCost += alloc.PVTotalCost() / hours
+	cg.NetworkCost += alloc.NetworkTotalCost() / hours
+	cg.LoadBalancerCost += alloc.LBTotalCost() / hours
+	cg.SharedCost += alloc.SharedTotalCost() / hours
+	cg.TotalCost += alloc.TotalCost() / hours
+}
+
+// CostGaugesFor aggregates the allocations of the set into namespace and
+// workload gauges, ordered by level and identity. Idle and unmounted
+// allocations are excluded, as they do not belong to a namespace.
+func CostGaugesFor(as *kubecost.AllocationSet) []*CostGauge {
+	if as == nil {
+		return nil
+	}
+
+	hours := as.Window.Hours()
+	if hours <= 0 || math.IsInf(hours, 1) {
+		return nil
+	}
+
+	gauges := make(map[string]*CostGauge)
+	for _, alloc := range as.Allocations {
+		if alloc.IsIdle() || alloc.IsUnmounted() || alloc.Properties == nil {
+			continue
+		}
+		props := alloc.Properties
+
+		for _, gauge := range []*CostGauge{
+			{Level: CostGaugeLevelNamespace, Cluster: props.Cluster, Namespace: props.Namespace},
+			{Level: CostGaugeLevelWorkload, Cluster: props.Cluster, Namespace: props.Namespace, ControllerKind: props.ControllerKind, Controller: props.Controller},
+		} {
+			if existing, ok := gauges[gauge.key()]; ok {
+				gauge = existing
+			} else {
+				gauges[gauge.key()] = gauge
+			}
+			gauge.add(alloc, costGaugeLabels(props), hours)
+		}
+	}
+
+	keys := make([]string, 0, len(gauges))
+	for key := range gauges {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := make([]*CostGauge, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, gauges[key])
+	}
+	return result
+}
+
+func costGaugeLabels(props *kubecost.AllocationProperties) map[string]string {
+	labels := make(map[string]string, len(props.Labels))
+	for k, v := range props.Labels {
+		labels[k] = v
+	}
+	return labels
+}
+
+// CostMetricsPublisher pushes cost gauges to an external metrics backend.
+type CostMetricsPublisher interface {
+	// Name identifies the backend in logs.
+	Name() string
+
+	// PublishCostMetrics pushes the gauges computed over the window, observed
+	// at the window's end.
+	PublishCostMetrics(ctx context.Context, window kubecost.Window, gauges []*CostGauge) error
+}
+
+// MetricsPusherConfig contains the scheduling options of a MetricsPusher.
+type MetricsPusherConfig struct {
+	// Interval is the duration between pushes.
+	Interval time.Duration
+
+	// Window is the trailing duration over which cost rates are computed.
+	Window time.Duration
+
+	// Resolution is the query resolution used to compute allocations.
+	Resolution time.Duration
+
+	// Budgets are evaluated against the namespace gauges on each push. New
+	// breaches are delivered to publishers implementing BudgetBreachPublisher.
+	Budgets []*Budget
+}
+
+// Validate returns an error if the configuration cannot be used to run a
+// MetricsPusher, e.g. a non-positive interval, which would push continuously.
+func (c *MetricsPusherConfig) Validate() error {
+	if c.Interval <= 0 {
+		return fmt.Errorf("metrics push interval must be positive, got %s", c.Interval)
+	}
+	return nil
+}
+
+// MetricsPusher periodically computes namespace and workload cost gauges over
+// a trailing window and pushes them to each publisher.
+type MetricsPusher struct {
+	source     Source
+	publishers []CostMetricsPublisher
+	notifiers  []BudgetBreachPublisher
+	config     *MetricsPusherConfig
+	budgets    *budgetTracker
+	now        func() time.Time
+
+	runState atomic.AtomicRunState
+	lock     sync.Mutex
+}
+
+// NewMetricsPusher creates a new MetricsPusher. Use Start() to begin pushing.
+func NewMetricsPusher(source Source, config *MetricsPusherConfig, publishers ...CostMetricsPublisher) *MetricsPusher {
+	return &MetricsPusher{
+		source:     source,
+		publishers: publishers,
+		config:     config,
+		budgets:    newBudgetTracker(),
+		now:        time.Now,
+	}
+}
+
+// AddPublisher adds a publisher, which receives gauges starting with the
+// next push.
+func (mp *MetricsPusher) AddPublisher(publisher CostMetricsPublisher) {
+	mp.lock.Lock()
+	defer mp.lock.Unlock()
+
+	mp.publishers = append(mp.publishers, publisher)
+}
+
+// AddBudgetBreachPublisher adds a publisher which only receives budget
+// breaches, such as a Notifier.
+func (mp *MetricsPusher) AddBudgetBreachPublisher(publisher BudgetBreachPublisher) {
+	mp.lock.Lock()
+	defer mp.lock.Unlock()
+
+	mp.notifiers = append(mp.notifiers, publisher)
+}
+
+// Start begins pushing on the configured interval. Returns false if the
+// pusher is already running.
+func (mp *MetricsPusher) Start() bool {
+	mp.runState.WaitForReset()
+	if !mp.runState.Start() {
+		log.Warnf("MetricsPusher: attempted to start when already running")
+		return false
+	}
+
+	go func() {
+		defer errors.HandlePanic()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go func() {
+			<-mp.runState.OnStop()
+			cancel()
+		}()
+
+		for {
+			if err := mp.Push(ctx); err != nil {
+				log.Errorf("MetricsPusher: %s", err)
+			}
+
+			select {
+			case <-mp.runState.OnStop():
+				mp.runState.Reset()
+				return
+			case <-time.After(mp.config.Interval):
+			}
+		}
+	}()
+
+	return true
+}
+
+// Stop halts the push loop.
+func (mp *MetricsPusher) Stop() {
+	mp.runState.Stop()
+}
+
+// Push computes the gauges for the trailing window ending now and pushes them
+// to each publisher. Publisher failures are logged and do not affect others.
+func (mp *MetricsPusher) Push(ctx context.Context) error {
+	mp.lock.Lock()
+	publishers := append([]CostMetricsPublisher{}, mp.publishers...)
+	notifiers := append([]BudgetBreachPublisher{}, mp.notifiers...)
+	mp.lock.Unlock()
+
+	end := mp.now().UTC().Truncate(mp.config.Resolution)
+	start := end.Add(-mp.config.Window)
+	window := kubecost.NewClosedWindow(start, end)
+
+	as, err := mp.source.ComputeAllocation(start, end, mp.config.Resolution)
+	if err != nil {
+		return err
+	}
+	gauges := CostGaugesFor(as)
+
+	var breaches []*BudgetBreach
+	if len(mp.config.Budgets) > 0 {
+		breaches = mp.budgets.New(EvaluateBudgets(mp.config.Budgets, window, gauges))
+	}
+	for _, breach := range breaches {
+		events.Emit(events.BudgetBreached, breach.Namespace, breach.Event())
+	}
+
+	for _, publisher := range publishers {
+		if err := publisher.PublishCostMetrics(ctx, window, gauges); err != nil {
+			log.Errorf("MetricsPusher: failed to push to %s: %s", publisher.Name(), err)
+		}
+
+		if bp, ok := publisher.(BudgetBreachPublisher); ok && len(breaches) > 0 {
+			if err := bp.PublishBudgetBreaches(ctx, breaches); err != nil {
+				log.Errorf("MetricsPusher: failed to publish budget breaches to %s: %s", publisher.Name(), err)
+			}
+		}
+	}
+
+	if len(breaches) > 0 {
+		for _, notifier := range notifiers {
+			if err := notifier.PublishBudgetBreaches(ctx, breaches); err != nil {
+				log.Errorf("MetricsPusher: failed to publish budget breaches: %s", err)
+			}
+		}
+	}
+
+	return nil
+}
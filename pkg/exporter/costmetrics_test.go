@@ -0,0 +1,73 @@
+package exporter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+)
+
+func TestCostGaugesFor(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(2 * time.Hour)
+	as := kubecost.NewAllocationSet(start, end)
+
+	newAlloc := func(name, controller string, cpuCost float64, labels map[string]string) *kubecost.Allocation {
+		return &kubecost.Allocation{
+			Name:   name,
+			Window: kubecost.NewClosedWindow(start, end),
+			Start:  start,
+			End:    end,
+			Properties: &kubecost.AllocationProperties{
+				Cluster:        "cluster1",
+				Namespace:      "ns1",
+				ControllerKind: "deployment",
+				Controller:     controller,
+				Labels:         labels,
+			},
+			CPUCost: cpuCost,
+		}
+	}
+
+	as.Set(newAlloc("a", "web", 4, map[string]string{"team": "x", "app": "web"}))
+	as.Set(newAlloc("b", "api", 2, map[string]string{"team": "x", "app": "api"}))
+
+	gauges := CostGaugesFor(as)
+	if len(gauges) != 3 {
+		t.Fatalf("expected 3 gauges; got %d", len(gauges))
+	}
+
+	var namespace *CostGauge
+	for _, g := range gauges {
+		if g.Level == CostGaugeLevelNamespace {
+			namespace = g
+		}
+	}
+	if namespace == nil {
+		t.Fatalf("expected a namespace gauge")
+	}
+
+	// 6 in CPU cost over 2 hours
+	if namespace.CPUCost != 3 || namespace.TotalCost != 3 {
+		t.Errorf("expected hourly cpu and total cost of 3; got %f and %f", namespace.CPUCost, namespace.TotalCost)
+	}
+
+	// only labels shared by both workloads are kept
+	if len(namespace.Labels) != 1 || namespace.Labels["team"] != "x" {
+		t.Errorf("expected only the team label; got %v", namespace.Labels)
+	}
+}
+
+func TestMetricsPusherConfig_Validate(t *testing.T) {
+	config := &MetricsPusherConfig{Interval: 5 * time.Minute, Window: time.Hour}
+	if err := config.Validate(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	for _, interval := range []time.Duration{0, -time.Minute} {
+		config.Interval = interval
+		if err := config.Validate(); err == nil {
+			t.Errorf("expected error for interval %s", interval)
+		}
+	}
+}
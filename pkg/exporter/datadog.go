@@ -0,0 +1,284 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/log"
+	"github.com/opencost/opencost/pkg/util/json"
+)
+
+const (
+	datadogNamespaceCostMetric = "opencost.namespace.hourly_cost"
+	datadogWorkloadCostMetric  = "opencost.workload.hourly_cost"
+
+	// datadogGaugeType is the v2 series API intake type of gauges
+	datadogGaugeType = 3
+
+	// datadogMaxRetryWait bounds the time spent waiting for a rate limit to reset
+	datadogMaxRetryWait = time.Minute
+)
+
+// DatadogConfig contains the options of a DatadogPublisher.
+type DatadogConfig struct {
+	APIKey string
+
+	// Site is the Datadog site, e.g. datadoghq.com or datadoghq.eu.
+	Site string
+
+	// TagLabels are the allocation labels added as tags, e.g. "team", in
+	// addition to the cluster, namespace and controller tags.
+	TagLabels []string
+
+	// Tags are added to every series and event, e.g. "env:prod".
+	Tags []string
+
+	// BatchSize is the maximum number of series submitted per request.
+	BatchSize int
+
+	// MaxAttempts is the number of attempts made per request when rate limited
+	// or when Datadog returns a server error.
+	MaxAttempts int
+
+	Timeout time.Duration
+}
+
+// DatadogPublisher is a CostMetricsPublisher submitting cost gauges to the
+// Datadog metrics API and budget breaches as Datadog events.
+type DatadogPublisher struct {
+	config  *DatadogConfig
+	client  *http.Client
+	baseURL string
+	sleep   func(context.Context, time.Duration) error
+}
+
+// NewDatadogPublisher creates a new DatadogPublisher.
+func NewDatadogPublisher(config *DatadogConfig) (*DatadogPublisher, error) {
+	if config == nil || config.APIKey == "" {
+		return nil, fmt.Errorf("datadog api key is required")
+	}
+	if config.Site == "" {
+		config.Site = "datadoghq.com"
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 1000
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 5
+	}
+
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	return &DatadogPublisher{
+		config:  config,
+		client:  &http.Client{Timeout: timeout},
+		baseURL: "https://api." + config.Site,
+		sleep:   sleepContext,
+	}, nil
+}
+
+// Name identifies the backend in logs.
+func (dp *DatadogPublisher) Name() string {
+	return "datadog"
+}
+
+type datadogSeriesRequest struct {
+	Series []datadogSeries `json:"series"`
+}
+
+type datadogSeries struct {
+	Metric string         `json:"metric"`
+	Type   int            `json:"type"`
+	Unit   string         `json:"unit,omitempty"`
+	Points []datadogPoint `json:"points"`
+	Tags   []string       `json:"tags"`
+}
+
+type datadogPoint struct {
+	Timestamp int64   `json:"timestamp"`
+	Value     float64 `json:"value"`
+}
+
+type datadogEvent struct {
+	Title          string   `json:"title"`
+	Text           string   `json:"text"`
+	AlertType      string   `json:"alert_type"`
+	AggregationKey string   `json:"aggregation_key"`
+	SourceTypeName string   `json:"source_type_name"`
+	DateHappened   int64    `json:"date_happened"`
+	Tags           []string `json:"tags"`
+}
+
+// PublishCostMetrics submits a series per gauge and resource, in batches.
+func (dp *DatadogPublisher) PublishCostMetrics(ctx context.Context, window kubecost.Window, gauges []*CostGauge) error {
+	timestamp := window.End().Unix()
+
+	var series []datadogSeries
+	for _, gauge := range gauges {
+		metric := datadogNamespaceCostMetric
+		if gauge.Level == CostGaugeLevelWorkload {
+			metric = datadogWorkloadCostMetric
+		}
+
+		tags := dp.tags(gauge.Cluster, gauge.Namespace, gauge.Labels)
+		if gauge.Level == CostGaugeLevelWorkload {
+			tags = append(tags, datadogTag("controller_kind", gauge.ControllerKind), datadogTag("controller", gauge.Controller))
+		}
+
+		costs := gauge.Costs()
+		resources := make([]string, 0, len(costs))
+		for resource := range costs {
+			resources = append(resources, resource)
+		}
+		sort.Strings(resources)
+
+		for _, resource := range resources {
+			series = append(series, datadogSeries{
+				Metric: metric,
+				Type:   datadogGaugeType,
+				Points: []datadogPoint{{Timestamp: timestamp, Value: costs[resource]}},
+				Tags:   append(append([]string{}, tags...), datadogTag("resource", resource)),
+			})
+		}
+	}
+
+	for start := 0; start < len(series); start += dp.config.BatchSize {
+		end := start + dp.config.BatchSize
+		if end > len(series) {
+			end = len(series)
+		}
+
+		if err := dp.post(ctx, "/api/v2/series", datadogSeriesRequest{Series: series[start:end]}); err != nil {
+			return fmt.Errorf("submitting series: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// PublishBudgetBreaches submits an event per breach.
+func (dp *DatadogPublisher) PublishBudgetBreaches(ctx context.Context, breaches []*BudgetBreach) error {
+	for _, breach := range breaches {
+		tags := dp.tags(breach.Cluster, breach.Namespace, breach.Labels)
+		tags = append(tags, datadogTag("budget", breach.Budget.Name))
+
+		event := datadogEvent{
+			Title:          fmt.Sprintf("OpenCost budget %s exceeded by namespace %s", breach.Budget.Name, breach.Namespace),
+			Text:           breach.String(),
+			AlertType:      "warning",
+			AggregationKey: "opencost-budget-" + breach.key(),
+			SourceTypeName: "opencost",
+			DateHappened:   breach.Window.End().Unix(),
+			Tags:           tags,
+		}
+
+		if err := dp.post(ctx, "/api/v1/events", event); err != nil {
+			return fmt.Errorf("submitting event: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// tags returns the common tags of a namespace, including the configured labels.
+func (dp *DatadogPublisher) tags(cluster, namespace string, labels map[string]string) []string {
+	tags := append([]string{}, dp.config.Tags...)
+	tags = append(tags, datadogTag("cluster", cluster), datadogTag("kube_namespace", namespace))
+
+	for _, label := range dp.config.TagLabels {
+		if value, ok := labels[label]; ok {
+			tags = append(tags, datadogTag(label, value))
+		}
+	}
+	return tags
+}
+
+// datadogTag builds a tag, replacing characters which Datadog would otherwise
+// convert to underscores so that the resulting tags are predictable.
+func datadogTag(key, value string) string {
+	normalize := func(s string) string {
+		return strings.Map(func(r rune) rune {
+			switch {
+			case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '.', r == '/', r == '_':
+				return r
+			case r >= 'A' && r <= 'Z':
+				return r + ('a' - 'A')
+			default:
+				return '_'
+			}
+		}, s)
+	}
+	return normalize(key) + ":" + normalize(value)
+}
+
+// post submits the body, retrying when rate limited or on server errors. When
+// rate limited, the wait honors the X-RateLimit-Reset header.
+func (dp *DatadogPublisher) post(ctx context.Context, path string, body interface{}) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	backoff := time.Second
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, dp.baseURL+path, bytes.NewReader(b))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("DD-API-KEY", dp.config.APIKey)
+
+		resp, err := dp.client.Do(req)
+		if err != nil {
+			return err
+		}
+		respBody, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return nil
+		}
+
+		retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		if !retryable || attempt >= dp.config.MaxAttempts {
+			return fmt.Errorf("status %d: %s", resp.StatusCode, string(respBody))
+		}
+
+		wait := backoff
+		if resp.StatusCode == http.StatusTooManyRequests {
+			if reset, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Reset")); err == nil && reset > 0 {
+				wait = time.Duration(reset) * time.Second
+			}
+			log.Warnf("Datadog: rate limited on %s, retrying in %s", path, wait)
+		}
+		if wait > datadogMaxRetryWait {
+			wait = datadogMaxRetryWait
+		}
+
+		if err := dp.sleep(ctx, wait); err != nil {
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+// sleepContext waits for the duration or until the context is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
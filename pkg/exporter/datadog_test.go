@@ -0,0 +1,81 @@
+package exporter
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/util/json"
+)
+
+func TestDatadogPublisher_RateLimited(t *testing.T) {
+	var requests []datadogSeriesRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("DD-API-KEY") != "key" {
+			t.Errorf("expected api key header")
+		}
+
+		// rate limit the first request
+		if len(requests) == 0 {
+			requests = append(requests, datadogSeriesRequest{})
+			w.Header().Set("X-RateLimit-Reset", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		var req datadogSeriesRequest
+		json.Unmarshal(body, &req)
+		requests = append(requests, req)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	publisher, err := NewDatadogPublisher(&DatadogConfig{APIKey: "key", TagLabels: []string{"team"}, BatchSize: 8})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	publisher.baseURL = server.URL
+
+	var waits []time.Duration
+	publisher.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	window := kubecost.NewClosedWindow(start, start.Add(time.Hour))
+	gauges := []*CostGauge{
+		{Level: CostGaugeLevelNamespace, Cluster: "cluster1", Namespace: "ns1", Labels: map[string]string{"team": "Platform"}, TotalCost: 1},
+		{Level: CostGaugeLevelNamespace, Cluster: "cluster1", Namespace: "ns2", TotalCost: 2},
+	}
+
+	if err := publisher.PublishCostMetrics(context.Background(), window, gauges); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(waits) != 1 || waits[0] != 7*time.Second {
+		t.Errorf("expected a single 7s wait for the rate limit; got %v", waits)
+	}
+
+	// 2 gauges with 8 resources each, in batches of 8, following the rate limited request
+	if len(requests) != 3 {
+		t.Fatalf("expected 3 requests; got %d", len(requests))
+	}
+
+	series := requests[1].Series[0]
+	expectedTags := []string{"cluster:cluster1", "kube_namespace:ns1", "team:platform", "resource:cpu"}
+	if len(series.Tags) != len(expectedTags) {
+		t.Fatalf("expected tags %v; got %v", expectedTags, series.Tags)
+	}
+	for i, tag := range expectedTags {
+		if series.Tags[i] != tag {
+			t.Errorf("expected tag %s; got %s", tag, series.Tags[i])
+		}
+	}
+}
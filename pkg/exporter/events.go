@@ -0,0 +1,182 @@
+package exporter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+)
+
+// AllocationEvent is the flattened record published for each workload in a
+// finalized allocation window. All fields are always present so that the
+// record can be encoded with the AllocationEventAvroSchema without unions.
+type AllocationEvent struct {
+	WindowStart      string            `json:"windowStart"`
+	WindowEnd        string            `json:"windowEnd"`
+	Name             string            `json:"name"`
+	Cluster          string            `json:"cluster"`
+	Node             string            `json:"node"`
+	Namespace        string            `json:"namespace"`
+	ControllerKind   string            `json:"controllerKind"`
+	Controller       string            `json:"controller"`
+	Pod              string            `json:"pod"`
+	Container        string            `json:"container"`
+	Labels           map[string]string `json:"labels"`
+	Minutes          float64           `json:"minutes"`
+	CPUCoreHours     float64           `json:"cpuCoreHours"`
+	RAMByteHours     float64           `json:"ramByteHours"`
+	GPUHours         float64           `json:"gpuHours"`
+	PVByteHours      float64           `json:"pvByteHours"`
+	CPUCost          float64           `json:"cpuCost"`
+	GPUCost          float64           `json:"gpuCost"`
+	RAMCost          float64           `json:"ramCost"`
+	PVCost           float64           `json:"pvCost"`
+	NetworkCost      float64           `json:"networkCost"`
+	LoadBalancerCost float64           `json:"loadBalancerCost"`
+	SharedCost       float64           `json:"sharedCost"`
+	ExternalCost     float64           `json:"externalCost"`
+	TotalCost        float64           `json:"totalCost"`
+}
+
+// AllocationEventAvroSchema is the avro schema of an AllocationEvent, as
+// registered with a schema registry.
+const AllocationEventAvroSchema = `{
+  "type": "record",
+  "name": "AllocationEvent",
+  "namespace": "org.opencost",
+  "fields": [
+    {"name": "windowStart", "type": "string"},
+    {"name": "windowEnd", "type": "string"},
+    {"name": "name", "type": "string"},
+    {"name": "cluster", "type": "string"},
+    {"name": "node", "type": "string"},
+    {"name": "namespace", "type": "string"},
+    {"name": "controllerKind", "type": "string"},
+    {"name": "controller", "type": "string"},
+    {"name": "pod", "type": "string"},
+    {"name": "container", "type": "string"},
+    {"name": "labels", "type": {"type": "map", "values": "string"}},
+    {"name": "minutes", "type": "double"},
+    {"name": "cpuCoreHours", "type": "double"},
+    {"name": "ramByteHours", "type": "double"},
+    {"name": "gpuHours", "type": "double"},
+    {"name": "pvByteHours", "type": "double"},
+    {"name": "cpuCost", "type": "double"},
+    {"name": "gpuCost", "type": "double"},
+    {"name": "ramCost", "type": "double"},
+    {"name": "pvCost", "type": "double"},
+    {"name": "networkCost", "type": "double"},
+    {"name": "loadBalancerCost", "type": "double"},
+    {"name": "sharedCost", "type": "double"},
+    {"name": "externalCost", "type": "double"},
+    {"name": "totalCost", "type": "double"}
+  ]
+}`
+
+// NewAllocationEvent creates the event for a single allocation in the window.
+func NewAllocationEvent(window kubecost.Window, alloc *kubecost.Allocation) *AllocationEvent {
+	props := alloc.Properties
+	if props == nil {
+		props = &kubecost.AllocationProperties{}
+	}
+
+	labels := make(map[string]string, len(props.Labels))
+	for k, v := range props.Labels {
+		labels[k] = v
+	}
+
+	return &AllocationEvent{
+		WindowStart:      window.Start().UTC().Format(time.RFC3339),
+		WindowEnd:        window.End().UTC().Format(time.RFC3339),
+		Name:             alloc.Name,
+		Cluster:          props.Cluster,
+		Node:             props.Node,
+		Namespace:        props.Namespace,
+		ControllerKind:   props.ControllerKind,
+		Controller:       props.Controller,
+		Pod:              props.Pod,
+		Container:        props.Container,
+		Labels:           labels,
+		Minutes:          alloc.Minutes(),
+		CPUCoreHours:     alloc.CPUCoreHours,
+		RAMByteHours:     alloc.RAMByteHours,
+		GPUHours:         alloc.GPUHours,
+		PVByteHours:      alloc.PVByteHours(),
+		CPUCost:          alloc.CPUTotalCost(),
+		GPUCost:          alloc.GPUTotalCost(),
+		RAMCost:          alloc.RAMTotalCost(),
+		PVCost:           alloc.PVTotalCost(),
+		NetworkCost:      alloc.NetworkTotalCost(),
+		LoadBalancerCost: alloc.LBTotalCost(),
+		SharedCost:       alloc.SharedTotalCost(),
+		ExternalCost:     alloc.ExternalCost,
+		TotalCost:        alloc.TotalCost(),
+	}
+}
+
+// AllocationEventsFor creates an event per allocation in the set, ordered by
+// allocation name.
+func AllocationEventsFor(window kubecost.Window, as *kubecost.AllocationSet) []*AllocationEvent {
+	if as == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(as.Allocations))
+	for name := range as.Allocations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	events := make([]*AllocationEvent, 0, len(names))
+	for _, name := range names {
+		events = append(events, NewAllocationEvent(window, as.Allocations[name]))
+	}
+	return events
+}
+
+// EventKeyFunc returns the message key for an event. Message buses use the key
+// to select a partition, so events with the same key are delivered in order.
+type EventKeyFunc func(*AllocationEvent) string
+
+// ParseEventKeyFunc returns the EventKeyFunc for the provided comma-separated
+// list of event properties, e.g. "cluster,namespace". Supported properties are
+// cluster, node, namespace, controller, pod and name. An empty string produces
+// empty keys, letting the message bus distribute events freely.
+func ParseEventKeyFunc(properties string) (EventKeyFunc, error) {
+	var getters []func(*AllocationEvent) string
+
+	for _, prop := range strings.Split(properties, ",") {
+		switch strings.TrimSpace(strings.ToLower(prop)) {
+		case "":
+			continue
+		case "cluster":
+			getters = append(getters, func(e *AllocationEvent) string { return e.Cluster })
+		case "node":
+			getters = append(getters, func(e *AllocationEvent) string { return e.Node })
+		case "namespace":
+			getters = append(getters, func(e *AllocationEvent) string { return e.Namespace })
+		case "controller":
+			getters = append(getters, func(e *AllocationEvent) string { return e.ControllerKind + ":" + e.Controller })
+		case "pod":
+			getters = append(getters, func(e *AllocationEvent) string { return e.Pod })
+		case "name":
+			getters = append(getters, func(e *AllocationEvent) string { return e.Name })
+		default:
+			return nil, fmt.Errorf("unsupported key property '%s'", prop)
+		}
+	}
+
+	return func(e *AllocationEvent) string {
+		if len(getters) == 0 {
+			return ""
+		}
+
+		parts := make([]string, 0, len(getters))
+		for _, get := range getters {
+			parts = append(parts, get(e))
+		}
+		return strings.Join(parts, "/")
+	}, nil
+}
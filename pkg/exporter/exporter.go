@@ -0,0 +1,457 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/opencost/opencost/pkg/errors"
+	"github.com/opencost/opencost/pkg/events"
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/log"
+	"github.com/opencost/opencost/pkg/util/atomic"
+	"github.com/opencost/opencost/pkg/util/retry"
+)
+
+// Source provides the allocation and asset data to be exported. It is
+// implemented by the CostModel.
+type Source interface {
+	ComputeAllocation(start, end time.Time, resolution time.Duration) (*kubecost.AllocationSet, error)
+	ComputeAssets(start, end time.Time) (*kubecost.AssetSet, error)
+}
+
+// Sink receives finalized allocation and asset windows and writes them to an
+// external destination.
+type Sink interface {
+	// Name is a unique, stable identifier for the sink. It is used to track
+	// which windows have been exported to the sink.
+	Name() string
+
+	// ExportAllocations writes the allocations for a finalized window.
+	ExportAllocations(ctx context.Context, window kubecost.Window, as *kubecost.AllocationSet) error
+
+	// ExportAssets writes the assets for a finalized window.
+	ExportAssets(ctx context.Context, window kubecost.Window, as *kubecost.AssetSet) error
+}
+
+// ExporterConfig contains the scheduling options for an Exporter.
+type ExporterConfig struct {
+	// Interval is the duration between attempts to export finalized windows.
+	Interval time.Duration
+
+	// WindowDuration is the size of each exported window. Windows are aligned
+	// to multiples of the duration in UTC.
+	WindowDuration time.Duration
+
+	// Delay is the amount of time to wait after a window ends before it is
+	// considered final, allowing late-arriving metrics to be scraped.
+	Delay time.Duration
+
+	// Lookback is the number of windows prior to the most recent finalized
+	// window which will be exported if they are missing.
+	Lookback int
+
+	// Resolution is the query resolution used to compute allocations.
+	Resolution time.Duration
+
+	// RetryAttempts is the number of attempts made to export a window to a sink
+	// before giving up until the next interval.
+	RetryAttempts uint
+
+	// RetryDelay is the initial delay between export attempts.
+	RetryDelay time.Duration
+
+	// BackfillStart, if set, causes all finalized windows since the given time
+	// to be exported when the exporter starts, rather than only the lookback.
+	BackfillStart time.Time
+}
+
+// DefaultExporterConfig returns an ExporterConfig exporting daily windows.
+func DefaultExporterConfig() *ExporterConfig {
+	return &ExporterConfig{
+		Interval:       time.Hour,
+		WindowDuration: 24 * time.Hour,
+		Delay:          10 * time.Minute,
+		Lookback:       3,
+		Resolution:     5 * time.Minute,
+		RetryAttempts:  3,
+		RetryDelay:     5 * time.Second,
+	}
+}
+
+// Validate returns an error if the configuration cannot be used to run an
+// Exporter, e.g. a non-positive interval, which would export continuously.
+func (c *ExporterConfig) Validate() error {
+	if c.Interval <= 0 {
+		return fmt.Errorf("export interval must be positive, got %s", c.Interval)
+	}
+	return nil
+}
+
+// Exporter periodically computes finalized allocation and asset windows and
+// exports them to a set of sinks. Windows already exported to a sink, as
+// recorded by the Checkpoints, are skipped.
+type Exporter struct {
+	source      Source
+	sinks       []Sink
+	checkpoints Checkpoints
+	now         func() time.Time
+
+	configLock sync.RWMutex
+	config     *ExporterConfig
+
+	runState atomic.AtomicRunState
+	lock     sync.Mutex
+}
+
+// NewExporter creates a new Exporter for the provided sinks. Use Start() to begin exporting.
+func NewExporter(source Source, checkpoints Checkpoints, config *ExporterConfig, sinks ...Sink) *Exporter {
+	if config == nil {
+		config = DefaultExporterConfig()
+	}
+	if checkpoints == nil {
+		checkpoints = NewMemoryCheckpoints()
+	}
+
+	return &Exporter{
+		source:      source,
+		sinks:       sinks,
+		checkpoints: checkpoints,
+		config:      config,
+		now:         time.Now,
+	}
+}
+
+// AddSink adds a sink to the exporter. Sinks added while the exporter is running
+// will receive data starting with the next interval.
+func (e *Exporter) AddSink(sink Sink) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.sinks = append(e.sinks, sink)
+}
+
+// Config returns a copy of the exporter's current configuration.
+func (e *Exporter) Config() ExporterConfig {
+	e.configLock.RLock()
+	defer e.configLock.RUnlock()
+
+	return *e.config
+}
+
+// SetConfig replaces the exporter's configuration. A running exporter uses the
+// new configuration from its next interval.
+func (e *Exporter) SetConfig(config ExporterConfig) {
+	e.configLock.Lock()
+	defer e.configLock.Unlock()
+
+	e.config = &config
+}
+
+// Keys of the exporter settings which are reloaded by ReloadConfig. The window
+// duration is not reloadable, as changing it would invalidate the checkpoints.
+const (
+	ReloadKeyInterval      = "export_interval"
+	ReloadKeyDelay         = "export_delay"
+	ReloadKeyLookback      = "export_lookback"
+	ReloadKeyRetryAttempts = "export_retry_attempts"
+	ReloadKeyRetryDelay    = "export_retry_delay"
+)
+
+// ReloadConfig parses the exporter settings present in the configuration data
+// and returns a func applying them, for use as a config.ReloadFunc. Settings
+// which are absent keep their current values.
+func (e *Exporter) ReloadConfig(data map[string]string) (func(), error) {
+	config := e.Config()
+
+	durations := map[string]*time.Duration{
+		ReloadKeyInterval:   &config.Interval,
+		ReloadKeyDelay:      &config.Delay,
+		ReloadKeyRetryDelay: &config.RetryDelay,
+	}
+	for key, field := range durations {
+		v, ok := data[key]
+		if !ok {
+			continue
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("%s: invalid duration %q", key, v)
+		}
+		*field = d
+	}
+	if config.Interval <= 0 {
+		return nil, fmt.Errorf("%s: must be positive", ReloadKeyInterval)
+	}
+
+	if v, ok := data[ReloadKeyLookback]; ok {
+		lookback, err := strconv.Atoi(v)
+		if err != nil || lookback < 0 {
+			return nil, fmt.Errorf("%s: invalid count %q", ReloadKeyLookback, v)
+		}
+		config.Lookback = lookback
+	}
+
+	if v, ok := data[ReloadKeyRetryAttempts]; ok {
+		attempts, err := strconv.ParseUint(v, 10, 32)
+		if err != nil || attempts == 0 {
+			return nil, fmt.Errorf("%s: invalid count %q", ReloadKeyRetryAttempts, v)
+		}
+		config.RetryAttempts = uint(attempts)
+	}
+
+	return func() { e.SetConfig(config) }, nil
+}
+
+// IsRunning returns true if the exporter is running.
+func (e *Exporter) IsRunning() bool {
+	return e.runState.IsRunning()
+}
+
+// Start begins exporting on the configured interval. The first export is
+// attempted immediately. Returns false if the exporter is already running.
+func (e *Exporter) Start() bool {
+	e.runState.WaitForReset()
+	if !e.runState.Start() {
+		log.Warnf("Exporter: attempted to start when already running")
+		return false
+	}
+
+	go func() {
+		defer errors.HandlePanic()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go func() {
+			<-e.runState.OnStop()
+			cancel()
+		}()
+
+		if backfillStart := e.Config().BackfillStart; !backfillStart.IsZero() {
+			e.Backfill(ctx, backfillStart)
+		}
+
+		for {
+			e.Export(ctx)
+
+			select {
+			case <-e.runState.OnStop():
+				e.runState.Reset()
+				return
+			case <-time.After(e.Config().Interval):
+			}
+		}
+	}()
+
+	return true
+}
+
+// Stop halts the export loop.
+func (e *Exporter) Stop() {
+	e.runState.Stop()
+}
+
+// FinalizedWindows returns the windows which are considered final at the given
+// time, oldest first, limited by the configured lookback.
+func (e *Exporter) FinalizedWindows(now time.Time) []kubecost.Window {
+	config := e.Config()
+	dur := config.WindowDuration
+	if dur <= 0 {
+		return nil
+	}
+
+	// the latest window which has ended at least Delay ago
+	lastEnd := now.UTC().Add(-config.Delay).Truncate(dur)
+
+	count := config.Lookback + 1
+	windows := make([]kubecost.Window, 0, count)
+	for i := count; i > 0; i-- {
+		end := lastEnd.Add(-time.Duration(i-1) * dur)
+		start := end.Add(-dur)
+		windows = append(windows, kubecost.NewClosedWindow(start, end))
+	}
+
+	return windows
+}
+
+// BackfillWindows returns the finalized windows at the given time which start
+// at or after start, oldest first.
+func (e *Exporter) BackfillWindows(start, now time.Time) []kubecost.Window {
+	return e.RangeWindows(start, now, now)
+}
+
+// RangeWindows returns the finalized windows at the given time which are
+// contained in [start, end), oldest first.
+func (e *Exporter) RangeWindows(start, end, now time.Time) []kubecost.Window {
+	config := e.Config()
+	dur := config.WindowDuration
+	if dur <= 0 {
+		return nil
+	}
+
+	lastEnd := now.UTC().Add(-config.Delay).Truncate(dur)
+	if end.Before(lastEnd) {
+		lastEnd = end.UTC().Truncate(dur)
+	}
+
+	// round the start up to the next window boundary, so partial windows are skipped
+	first := start.UTC().Truncate(dur)
+	if first.Before(start) {
+		first = first.Add(dur)
+	}
+
+	var windows []kubecost.Window
+	for s := first; !s.Add(dur).After(lastEnd); s = s.Add(dur) {
+		windows = append(windows, kubecost.NewClosedWindow(s, s.Add(dur)))
+	}
+
+	return windows
+}
+
+// Export exports all finalized windows which have not yet been exported to
+// each sink. Failures are logged and retried on the next interval.
+func (e *Exporter) Export(ctx context.Context) {
+	e.exportWindows(ctx, e.FinalizedWindows(e.now()), nil)
+}
+
+// Backfill exports all finalized windows since start which have not yet been
+// exported to each sink.
+func (e *Exporter) Backfill(ctx context.Context, start time.Time) {
+	windows := e.BackfillWindows(start, e.now())
+	log.Infof("Exporter: backfilling %d windows since %s", len(windows), start.UTC().Format(time.RFC3339))
+
+	e.exportWindows(ctx, windows, nil)
+}
+
+// windowResult is the outcome of exporting a window to the pending sinks.
+type windowResult struct {
+	window kubecost.Window
+
+	// skipped is true if the window had already been exported to every sink
+	skipped bool
+
+	exported []string
+	err      error
+}
+
+// exportWindows exports each window to the sinks it has not been exported to,
+// passing the outcome of each window to onWindow if it is non-nil.
+func (e *Exporter) exportWindows(ctx context.Context, windows []kubecost.Window, onWindow func(*windowResult)) {
+	e.lock.Lock()
+	sinks := append([]Sink{}, e.sinks...)
+	e.lock.Unlock()
+
+	var exportedWindows, failedWindows int
+	for _, window := range windows {
+		if ctx.Err() != nil {
+			return
+		}
+
+		var pending []Sink
+		for _, sink := range sinks {
+			exported, err := e.checkpoints.IsExported(sink.Name(), window)
+			if err != nil {
+				log.Warnf("Exporter: failed to read checkpoint for %s %s: %s", sink.Name(), window, err)
+			}
+			if !exported {
+				pending = append(pending, sink)
+			}
+		}
+
+		if len(pending) == 0 {
+			if onWindow != nil {
+				onWindow(&windowResult{window: window, skipped: true})
+			}
+			continue
+		}
+
+		exported, err := e.exportWindow(ctx, window, pending)
+		if err != nil {
+			log.Errorf("Exporter: %s", err)
+		}
+		if err == nil && len(exported) < len(pending) {
+			err = fmt.Errorf("exported %s to %d of %d sinks", window, len(exported), len(pending))
+		}
+		if onWindow != nil {
+			onWindow(&windowResult{window: window, exported: exported, err: err})
+		}
+		if len(exported) < len(pending) {
+			failedWindows++
+		}
+		if len(exported) == 0 {
+			continue
+		}
+		exportedWindows++
+
+		events.Emit(events.WindowFinalized, window.String(), WindowFinalizedEvent{
+			WindowStart: window.Start().UTC(),
+			WindowEnd:   window.End().UTC(),
+			Sinks:       exported,
+		})
+	}
+
+	events.Emit(events.ReconciliationCompleted, "", ReconciliationCompletedEvent{
+		Windows:  len(windows),
+		Exported: exportedWindows,
+		Failed:   failedWindows,
+	})
+}
+
+// WindowFinalizedEvent is the data of a WindowFinalized event.
+type WindowFinalizedEvent struct {
+	WindowStart time.Time `json:"windowStart"`
+	WindowEnd   time.Time `json:"windowEnd"`
+	Sinks       []string  `json:"sinks"`
+}
+
+// ReconciliationCompletedEvent is the data of a ReconciliationCompleted
+// event, counting the windows checked, (re-)exported and failed in one pass.
+type ReconciliationCompletedEvent struct {
+	Windows  int `json:"windows"`
+	Exported int `json:"exported"`
+	Failed   int `json:"failed"`
+}
+
+// exportWindow computes the data for a single window once and exports it to
+// each of the pending sinks, returning the names of the sinks exported to.
+func (e *Exporter) exportWindow(ctx context.Context, window kubecost.Window, sinks []Sink) ([]string, error) {
+	start, end := *window.Start(), *window.End()
+	config := e.Config()
+
+	allocSet, err := e.source.ComputeAllocation(start, end, config.Resolution)
+	if err != nil {
+		return nil, fmt.Errorf("computing allocations for %s: %w", window, err)
+	}
+
+	assetSet, err := e.source.ComputeAssets(start, end)
+	if err != nil {
+		return nil, fmt.Errorf("computing assets for %s: %w", window, err)
+	}
+
+	var exported []string
+
+	for _, sink := range sinks {
+		_, err := retry.Retry(ctx, func() (struct{}, error) {
+			if err := sink.ExportAllocations(ctx, window, allocSet); err != nil {
+				return struct{}{}, err
+			}
+			return struct{}{}, sink.ExportAssets(ctx, window, assetSet)
+		}, config.RetryAttempts, config.RetryDelay)
+		if err != nil {
+			log.Errorf("Exporter: failed to export %s to %s: %s", window, sink.Name(), err)
+			continue
+		}
+
+		if err := e.checkpoints.SetExported(sink.Name(), window); err != nil {
+			log.Warnf("Exporter: failed to write checkpoint for %s %s: %s", sink.Name(), window, err)
+		}
+
+		log.Infof("Exporter: exported %s to %s", window, sink.Name())
+		exported = append(exported, sink.Name())
+	}
+
+	return exported, nil
+}
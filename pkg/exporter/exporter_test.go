@@ -0,0 +1,201 @@
+package exporter
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+)
+
+type testSource struct {
+	allocationCalls int
+	assetCalls      int
+}
+
+func (ts *testSource) ComputeAllocation(start, end time.Time, resolution time.Duration) (*kubecost.AllocationSet, error) {
+	ts.allocationCalls++
+	return kubecost.NewAllocationSet(start, end), nil
+}
+
+func (ts *testSource) ComputeAssets(start, end time.Time) (*kubecost.AssetSet, error) {
+	ts.assetCalls++
+	return kubecost.NewAssetSet(start, end), nil
+}
+
+type testSink struct {
+	name string
+	lock sync.Mutex
+	seen []kubecost.Window
+}
+
+func (ts *testSink) Name() string { return ts.name }
+
+func (ts *testSink) ExportAllocations(ctx context.Context, window kubecost.Window, as *kubecost.AllocationSet) error {
+	ts.lock.Lock()
+	defer ts.lock.Unlock()
+
+	ts.seen = append(ts.seen, window)
+	return nil
+}
+
+func (ts *testSink) ExportAssets(ctx context.Context, window kubecost.Window, as *kubecost.AssetSet) error {
+	return nil
+}
+
+func TestExporter_FinalizedWindows(t *testing.T) {
+	config := DefaultExporterConfig()
+	config.Lookback = 2
+
+	e := NewExporter(&testSource{}, nil, config)
+
+	// 00:05 is within the delay after the end of Jan 2, so Jan 1 is the most recent final window
+	now := time.Date(2023, 1, 3, 0, 5, 0, 0, time.UTC)
+	windows := e.FinalizedWindows(now)
+
+	if len(windows) != 3 {
+		t.Fatalf("expected 3 windows; got %d", len(windows))
+	}
+
+	expectedStarts := []time.Time{
+		time.Date(2022, 12, 30, 0, 0, 0, 0, time.UTC),
+		time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC),
+		time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for i, w := range windows {
+		if !w.Start().Equal(expectedStarts[i]) {
+			t.Errorf("window %d: expected start %s; got %s", i, expectedStarts[i], w.Start())
+		}
+		if w.Duration() != 24*time.Hour {
+			t.Errorf("window %d: expected 24h duration; got %s", i, w.Duration())
+		}
+	}
+}
+
+func TestExporter_ReloadConfig(t *testing.T) {
+	e := NewExporter(&testSource{}, nil, DefaultExporterConfig())
+
+	apply, err := e.ReloadConfig(map[string]string{
+		ReloadKeyInterval: "30m",
+		ReloadKeyLookback: "7",
+		"unrelated":       "ignored",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if e.Config().Interval != time.Hour {
+		t.Fatalf("expected config not to change before apply")
+	}
+
+	apply()
+	config := e.Config()
+	if config.Interval != 30*time.Minute || config.Lookback != 7 {
+		t.Fatalf("expected interval 30m and lookback 7; got %s and %d", config.Interval, config.Lookback)
+	}
+	if config.Delay != DefaultExporterConfig().Delay {
+		t.Fatalf("expected absent delay to be unchanged; got %s", config.Delay)
+	}
+
+	for _, data := range []map[string]string{
+		{ReloadKeyInterval: "0s"},
+		{ReloadKeyDelay: "soon"},
+		{ReloadKeyLookback: "-1"},
+		{ReloadKeyRetryAttempts: "0"},
+	} {
+		if _, err := e.ReloadConfig(data); err == nil {
+			t.Errorf("expected error for %v", data)
+		}
+	}
+}
+
+func TestExporterConfig_Validate(t *testing.T) {
+	if err := DefaultExporterConfig().Validate(); err != nil {
+		t.Errorf("unexpected error for the default config: %s", err)
+	}
+
+	for _, interval := range []time.Duration{0, -time.Minute} {
+		config := DefaultExporterConfig()
+		config.Interval = interval
+		if err := config.Validate(); err == nil {
+			t.Errorf("expected error for interval %s", interval)
+		}
+	}
+}
+
+func TestExporter_BackfillWindows(t *testing.T) {
+	e := NewExporter(&testSource{}, nil, DefaultExporterConfig())
+
+	now := time.Date(2023, 1, 10, 0, 5, 0, 0, time.UTC)
+
+	// a start within a window begins at the next full window
+	windows := e.BackfillWindows(time.Date(2023, 1, 5, 12, 0, 0, 0, time.UTC), now)
+	if len(windows) != 3 {
+		t.Fatalf("expected 3 windows; got %d", len(windows))
+	}
+	if !windows[0].Start().Equal(time.Date(2023, 1, 6, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected first window to start on Jan 6; got %s", windows[0].Start())
+	}
+	if !windows[2].End().Equal(time.Date(2023, 1, 9, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected last window to end on Jan 9; got %s", windows[2].End())
+	}
+
+	// a start after the last finalized window produces no windows
+	if windows := e.BackfillWindows(time.Date(2023, 1, 9, 0, 0, 0, 0, time.UTC), now); len(windows) != 0 {
+		t.Errorf("expected no windows; got %d", len(windows))
+	}
+}
+
+func TestExporter_ExportSkipsCheckpointedWindows(t *testing.T) {
+	config := DefaultExporterConfig()
+	config.Lookback = 1
+
+	source := &testSource{}
+	checkpoints := NewMemoryCheckpoints()
+	first := &testSink{name: "first"}
+
+	e := NewExporter(source, checkpoints, config, first)
+	e.now = func() time.Time { return time.Date(2023, 1, 3, 12, 0, 0, 0, time.UTC) }
+
+	e.Export(context.Background())
+	if len(first.seen) != 2 {
+		t.Fatalf("expected 2 windows exported; got %d", len(first.seen))
+	}
+
+	// a second pass should not recompute or re-export anything
+	e.Export(context.Background())
+	if len(first.seen) != 2 {
+		t.Fatalf("expected no additional windows exported; got %d", len(first.seen))
+	}
+	if source.allocationCalls != 2 {
+		t.Fatalf("expected 2 allocation computations; got %d", source.allocationCalls)
+	}
+
+	// a newly added sink receives the windows which it has not yet seen
+	second := &testSink{name: "second"}
+	e.AddSink(second)
+	e.Export(context.Background())
+	if len(second.seen) != 2 {
+		t.Fatalf("expected 2 windows exported to new sink; got %d", len(second.seen))
+	}
+	if len(first.seen) != 2 {
+		t.Fatalf("expected no additional windows exported to first sink; got %d", len(first.seen))
+	}
+}
+
+func TestPartitionPath(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	window := kubecost.NewClosedWindow(start, start.Add(24*time.Hour))
+
+	tests := map[string]string{
+		"cluster-one": "allocation/dt=2023-01-01/cluster=cluster-one/allocation_20230101T000000Z_20230102T000000Z.parquet",
+		"":            "allocation/dt=2023-01-01/cluster=__HIVE_DEFAULT_PARTITION__/allocation_20230101T000000Z_20230102T000000Z.parquet",
+		"a/b":         "allocation/dt=2023-01-01/cluster=a%2Fb/allocation_20230101T000000Z_20230102T000000Z.parquet",
+	}
+
+	for cluster, expected := range tests {
+		if actual := PartitionPath(allocationDataset, window, cluster); actual != expected {
+			t.Errorf("cluster '%s': expected %s; got %s", cluster, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opencost/opencost/pkg/util/json"
+)
+
+// MessageEncoding is the wire format of published messages.
+type MessageEncoding string
+
+const (
+	MessageEncodingJSON MessageEncoding = "json"
+	MessageEncodingAvro MessageEncoding = "avro"
+)
+
+const (
+	kafkaRESTAcceptType    = "application/vnd.kafka.v2+json"
+	kafkaRESTJSONType      = "application/vnd.kafka.json.v2+json"
+	kafkaRESTAvroType      = "application/vnd.kafka.avro.v2+json"
+	kafkaRESTKeyAvroSchema = `"string"`
+)
+
+// KafkaRESTConfig contains the options for publishing through a Kafka REST
+// Proxy (v2 API).
+type KafkaRESTConfig struct {
+	// URL is the base URL of the REST proxy, e.g. http://kafka-rest:8082
+	URL string
+
+	// Encoding selects json or avro records. Avro records are validated and
+	// registered against the schema registry configured on the REST proxy.
+	Encoding MessageEncoding
+
+	// Username and Password are used for basic authentication, if set.
+	Username string
+	Password string
+
+	Timeout time.Duration
+}
+
+// KafkaRESTPublisher is a MessagePublisher which produces records to Kafka via
+// a Kafka REST Proxy. When using avro, the AllocationEvent schema is sent with
+// the first request and the registered schema id is reused afterwards.
+type KafkaRESTPublisher struct {
+	config *KafkaRESTConfig
+	client *http.Client
+
+	lock      sync.Mutex
+	schemaIDs map[string]int
+}
+
+// NewKafkaRESTPublisher creates a new publisher for the REST proxy.
+func NewKafkaRESTPublisher(config *KafkaRESTConfig) (*KafkaRESTPublisher, error) {
+	if config == nil || config.URL == "" {
+		return nil, fmt.Errorf("kafka rest proxy url is required")
+	}
+	if _, err := url.Parse(config.URL); err != nil {
+		return nil, fmt.Errorf("invalid kafka rest proxy url: %w", err)
+	}
+
+	switch config.Encoding {
+	case "":
+		config.Encoding = MessageEncodingJSON
+	case MessageEncodingJSON, MessageEncodingAvro:
+	default:
+		return nil, fmt.Errorf("unsupported message encoding '%s'", config.Encoding)
+	}
+
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	return &KafkaRESTPublisher{
+		config:    config,
+		client:    &http.Client{Timeout: timeout},
+		schemaIDs: make(map[string]int),
+	}, nil
+}
+
+// Name identifies the message bus.
+func (kp *KafkaRESTPublisher) Name() string {
+	return "kafka"
+}
+
+type kafkaRESTRecord struct {
+	Key   *string          `json:"key,omitempty"`
+	Value *AllocationEvent `json:"value"`
+}
+
+type kafkaRESTRequest struct {
+	KeySchema     string            `json:"key_schema,omitempty"`
+	ValueSchema   string            `json:"value_schema,omitempty"`
+	ValueSchemaID int               `json:"value_schema_id,omitempty"`
+	Records       []kafkaRESTRecord `json:"records"`
+}
+
+type kafkaRESTResponse struct {
+	ValueSchemaID *int `json:"value_schema_id"`
+	Offsets       []struct {
+		Partition *int   `json:"partition"`
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+// Publish produces the messages to the topic in a single request.
+func (kp *KafkaRESTPublisher) Publish(ctx context.Context, topic string, messages []Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	req := kafkaRESTRequest{
+		Records: make([]kafkaRESTRecord, 0, len(messages)),
+	}
+	for _, msg := range messages {
+		record := kafkaRESTRecord{Value: msg.Value}
+		if msg.Key != "" {
+			key := msg.Key
+			record.Key = &key
+		}
+		req.Records = append(req.Records, record)
+	}
+
+	contentType := kafkaRESTJSONType
+	if kp.config.Encoding == MessageEncodingAvro {
+		contentType = kafkaRESTAvroType
+		req.KeySchema = kafkaRESTKeyAvroSchema
+
+		kp.lock.Lock()
+		if id, ok := kp.schemaIDs[topic]; ok {
+			req.ValueSchemaID = id
+		} else {
+			req.ValueSchema = AllocationEventAvroSchema
+		}
+		kp.lock.Unlock()
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("encoding kafka records: %w", err)
+	}
+
+	endpoint := strings.TrimSuffix(kp.config.URL, "/") + "/topics/" + url.PathEscape(topic)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", contentType)
+	httpReq.Header.Set("Accept", kafkaRESTAcceptType)
+	if kp.config.Username != "" {
+		httpReq.SetBasicAuth(kp.config.Username, kp.config.Password)
+	}
+
+	resp, err := kp.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("producing to topic %s: %w", topic, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading kafka rest proxy response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("producing to topic %s: status %d: %s", topic, resp.StatusCode, string(respBody))
+	}
+
+	var result kafkaRESTResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return fmt.Errorf("decoding kafka rest proxy response: %w", err)
+	}
+
+	failed := 0
+	var lastErr string
+	for _, offset := range result.Offsets {
+		if offset.ErrorCode != nil {
+			failed++
+			lastErr = offset.Error
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("producing to topic %s: %d of %d records failed: %s", topic, failed, len(messages), lastErr)
+	}
+
+	if kp.config.Encoding == MessageEncodingAvro && result.ValueSchemaID != nil {
+		kp.lock.Lock()
+		kp.schemaIDs[topic] = *result.ValueSchemaID
+		kp.lock.Unlock()
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package exporter
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/util/json"
+)
+
+func TestKafkaRESTPublisher_AvroSchemaID(t *testing.T) {
+	var requests []kafkaRESTRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/topics/allocations" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != kafkaRESTAvroType {
+			t.Errorf("unexpected content type %s", ct)
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		var req kafkaRESTRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("decoding request: %s", err)
+		}
+		requests = append(requests, req)
+
+		w.Header().Set("Content-Type", kafkaRESTAcceptType)
+		w.Write([]byte(`{"key_schema_id":1,"value_schema_id":42,"offsets":[{"partition":0,"offset":1}]}`))
+	}))
+	defer server.Close()
+
+	publisher, err := NewKafkaRESTPublisher(&KafkaRESTConfig{URL: server.URL, Encoding: MessageEncodingAvro})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	window := kubecost.NewClosedWindow(start, start.Add(time.Hour))
+	alloc := &kubecost.Allocation{
+		Name:       "cluster1/ns1",
+		Properties: &kubecost.AllocationProperties{Cluster: "cluster1", Namespace: "ns1"},
+		Window:     window,
+	}
+	messages := []Message{{Key: "cluster1/ns1", Value: NewAllocationEvent(window, alloc)}}
+
+	for i := 0; i < 2; i++ {
+		if err := publisher.Publish(context.Background(), "allocations", messages); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests; got %d", len(requests))
+	}
+	if requests[0].ValueSchema == "" || requests[0].ValueSchemaID != 0 {
+		t.Errorf("expected first request to send the schema")
+	}
+	if requests[1].ValueSchema != "" || requests[1].ValueSchemaID != 42 {
+		t.Errorf("expected second request to reuse schema id 42; got %d", requests[1].ValueSchemaID)
+	}
+}
+
+func TestKafkaRESTPublisher_RecordErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"offsets":[{"partition":0,"offset":1},{"error_code":50003,"error":"timeout"}]}`))
+	}))
+	defer server.Close()
+
+	publisher, err := NewKafkaRESTPublisher(&KafkaRESTConfig{URL: server.URL})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	messages := []Message{{Value: &AllocationEvent{}}, {Value: &AllocationEvent{}}}
+	if err := publisher.Publish(context.Background(), "allocations", messages); err == nil {
+		t.Fatalf("expected error for failed records")
+	}
+}
@@ -0,0 +1,87 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+)
+
+// Message is a single keyed event published to a message bus.
+type Message struct {
+	Key   string
+	Value *AllocationEvent
+}
+
+// MessagePublisher publishes batches of messages to a topic on a message bus,
+// such as Kafka.
+type MessagePublisher interface {
+	// Name identifies the message bus, e.g. "kafka".
+	Name() string
+
+	// Publish delivers the messages to the topic, returning an error if any
+	// message could not be delivered.
+	Publish(ctx context.Context, topic string, messages []Message) error
+}
+
+// MessageSink is a Sink publishing an AllocationEvent per workload in each
+// finalized window to a message bus topic. Assets are not published.
+//
+// Delivery is at-least-once: if any batch fails, the whole window is published
+// again on retry, so consumers should deduplicate on (windowStart, name).
+type MessageSink struct {
+	publisher MessagePublisher
+	topic     string
+	keyFunc   EventKeyFunc
+	batchSize int
+}
+
+// NewMessageSink creates a new MessageSink publishing to the topic in batches
+// of at most batchSize messages.
+func NewMessageSink(publisher MessagePublisher, topic string, keyFunc EventKeyFunc, batchSize int) *MessageSink {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	if keyFunc == nil {
+		keyFunc = func(*AllocationEvent) string { return "" }
+	}
+
+	return &MessageSink{
+		publisher: publisher,
+		topic:     topic,
+		keyFunc:   keyFunc,
+		batchSize: batchSize,
+	}
+}
+
+// Name returns the unique identifier for the sink.
+func (ms *MessageSink) Name() string {
+	return fmt.Sprintf("%s-%s", ms.publisher.Name(), ms.topic)
+}
+
+// ExportAllocations publishes an event per allocation in the window.
+func (ms *MessageSink) ExportAllocations(ctx context.Context, window kubecost.Window, as *kubecost.AllocationSet) error {
+	events := AllocationEventsFor(window, as)
+
+	batch := make([]Message, 0, ms.batchSize)
+	for _, event := range events {
+		batch = append(batch, Message{Key: ms.keyFunc(event), Value: event})
+
+		if len(batch) == ms.batchSize {
+			if err := ms.publisher.Publish(ctx, ms.topic, batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+
+	if len(batch) > 0 {
+		return ms.publisher.Publish(ctx, ms.topic, batch)
+	}
+	return nil
+}
+
+// ExportAssets is a no-op; only allocation events are published.
+func (ms *MessageSink) ExportAssets(ctx context.Context, window kubecost.Window, as *kubecost.AssetSet) error {
+	return nil
+}
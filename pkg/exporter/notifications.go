@@ -0,0 +1,273 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/opencost/opencost/pkg/log"
+	"github.com/opencost/opencost/pkg/util/json"
+)
+
+// NotificationKind identifies the source of a notification.
+type NotificationKind string
+
+const (
+	NotificationKindBudgetBreach  NotificationKind = "budget_breach"
+	NotificationKindSavingsReport NotificationKind = "savings_report"
+	NotificationKindCostReport    NotificationKind = "cost_report"
+	NotificationKindCostAnomaly   NotificationKind = "cost_anomaly"
+)
+
+// Notification is a human-readable message sent to chat channels.
+type Notification struct {
+	Kind      NotificationKind
+	Title     string
+	Text      string
+	Cluster   string
+	Namespace string
+	Labels    map[string]string
+
+	// Data is the value the notification was created from, e.g. a
+	// *BudgetBreach or *SavingsReport, for use in custom templates.
+	Data interface{}
+}
+
+// NotificationRoute selects the notifications delivered to a channel. Empty
+// fields match everything.
+type NotificationRoute struct {
+	Kinds      []NotificationKind `json:"kinds,omitempty"`
+	Clusters   []string           `json:"clusters,omitempty"`
+	Namespaces []string           `json:"namespaces,omitempty"`
+
+	// Labels must all be present on the notification's namespace, e.g. to
+	// route by a team label.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// Matches returns true if the route selects the notification.
+func (nr *NotificationRoute) Matches(n *Notification) bool {
+	if len(nr.Kinds) > 0 && !slices.Contains(nr.Kinds, n.Kind) {
+		return false
+	}
+	if len(nr.Clusters) > 0 && !slices.Contains(nr.Clusters, n.Cluster) {
+		return false
+	}
+	if len(nr.Namespaces) > 0 && !slices.Contains(nr.Namespaces, n.Namespace) {
+		return false
+	}
+	for k, v := range nr.Labels {
+		if n.Labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// NotificationChannelType is the chat service of a channel.
+type NotificationChannelType string
+
+const (
+	NotificationChannelSlack NotificationChannelType = "slack"
+	NotificationChannelTeams NotificationChannelType = "teams"
+)
+
+// NotificationChannel is an incoming webhook of a Slack or Microsoft Teams
+// channel. A channel without routes receives every notification.
+type NotificationChannel struct {
+	Name       string                  `json:"name"`
+	Type       NotificationChannelType `json:"type"`
+	WebhookURL string                  `json:"webhookURL"`
+
+	// Template is a text/template rendering the message body from the
+	// Notification. The notification's Text is used if empty.
+	Template string `json:"template,omitempty"`
+
+	Routes []NotificationRoute `json:"routes,omitempty"`
+
+	tmpl *template.Template
+}
+
+// Matches returns true if any of the channel's routes selects the notification.
+func (nc *NotificationChannel) Matches(n *Notification) bool {
+	if len(nc.Routes) == 0 {
+		return true
+	}
+	for i := range nc.Routes {
+		if nc.Routes[i].Matches(n) {
+			return true
+		}
+	}
+	return false
+}
+
+// Render returns the message body of the notification for the channel.
+func (nc *NotificationChannel) Render(n *Notification) (string, error) {
+	if nc.tmpl == nil {
+		return n.Text, nil
+	}
+
+	var buf strings.Builder
+	if err := nc.tmpl.Execute(&buf, n); err != nil {
+		return "", fmt.Errorf("rendering template of channel %s: %w", nc.Name, err)
+	}
+	return buf.String(), nil
+}
+
+// payload returns the webhook request body for the channel's service.
+func (nc *NotificationChannel) payload(title, text string) interface{} {
+	if nc.Type == NotificationChannelTeams {
+		return map[string]string{
+			"@type":    "MessageCard",
+			"@context": "http://schema.org/extensions",
+			"summary":  title,
+			"title":    title,
+			"text":     text,
+		}
+	}
+
+	return map[string]string{
+		"text": fmt.Sprintf("*%s*\n%s", title, text),
+	}
+}
+
+// NotificationConfig is the file format of the notification channels.
+type NotificationConfig struct {
+	Channels []*NotificationChannel `json:"channels"`
+}
+
+// LoadNotificationChannels reads and validates the channels of a
+// NotificationConfig file.
+func LoadNotificationChannels(file string) ([]*NotificationChannel, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading notification config: %w", err)
+	}
+
+	var config NotificationConfig
+	if err := json.Unmarshal(b, &config); err != nil {
+		return nil, fmt.Errorf("parsing notification config: %w", err)
+	}
+
+	for i, channel := range config.Channels {
+		if channel.Name == "" {
+			channel.Name = fmt.Sprintf("channel-%d", i)
+		}
+		if channel.WebhookURL == "" {
+			return nil, fmt.Errorf("channel %s: webhookURL is required", channel.Name)
+		}
+		switch channel.Type {
+		case NotificationChannelSlack, NotificationChannelTeams:
+		default:
+			return nil, fmt.Errorf("channel %s: unsupported type '%s'", channel.Name, channel.Type)
+		}
+
+		if channel.Template != "" {
+			channel.tmpl, err = template.New(channel.Name).Parse(channel.Template)
+			if err != nil {
+				return nil, fmt.Errorf("channel %s: parsing template: %w", channel.Name, err)
+			}
+		}
+	}
+
+	return config.Channels, nil
+}
+
+// Notifier routes notifications to Slack and Microsoft Teams channels. It is
+// a BudgetBreachPublisher, so it can be added to a MetricsPusher to notify
+// channels of budget breaches.
+type Notifier struct {
+	channels []*NotificationChannel
+	client   *http.Client
+}
+
+// NewNotifier creates a new Notifier for the channels.
+func NewNotifier(channels []*NotificationChannel) *Notifier {
+	return &Notifier{
+		channels: channels,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Notify sends the notification to every channel it is routed to. A failure
+// to deliver to one channel does not prevent delivery to the others.
+func (n *Notifier) Notify(ctx context.Context, notification *Notification) error {
+	var errs []string
+	for _, channel := range n.channels {
+		if !channel.Matches(notification) {
+			continue
+		}
+
+		if err := n.send(ctx, channel, notification); err != nil {
+			log.Warnf("Notifier: failed to notify channel %s: %s", channel.Name, err)
+			errs = append(errs, fmt.Sprintf("%s: %s", channel.Name, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("notifying channels: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// PublishBudgetBreaches sends a notification per breach.
+func (n *Notifier) PublishBudgetBreaches(ctx context.Context, breaches []*BudgetBreach) error {
+	var err error
+	for _, breach := range breaches {
+		if nerr := n.Notify(ctx, NewBudgetBreachNotification(breach)); nerr != nil {
+			err = nerr
+		}
+	}
+	return err
+}
+
+func (n *Notifier) send(ctx context.Context, channel *NotificationChannel, notification *Notification) error {
+	text, err := channel.Render(notification)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(channel.payload(notification.Title, text))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, channel.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("status %d: %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// NewBudgetBreachNotification creates the notification for a budget breach.
+func NewBudgetBreachNotification(breach *BudgetBreach) *Notification {
+	return &Notification{
+		Kind:      NotificationKindBudgetBreach,
+		Title:     fmt.Sprintf("Budget %s exceeded", breach.Budget.Name),
+		Text:      breach.String(),
+		Cluster:   breach.Cluster,
+		Namespace: breach.Namespace,
+		Labels:    breach.Labels,
+		Data:      breach,
+	}
+}
@@ -0,0 +1,72 @@
+package exporter
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencost/opencost/pkg/util/json"
+)
+
+func TestNotifier_RoutesByLabel(t *testing.T) {
+	received := make(map[string]map[string]string)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var payload map[string]string
+		json.Unmarshal(body, &payload)
+		received[r.URL.Path] = payload
+	}))
+	defer server.Close()
+
+	config := `{"channels": [
+		{"name": "platform", "type": "slack", "webhookURL": "` + server.URL + `/platform",
+		 "template": "{{.Namespace}} over budget {{.Data.Budget.Name}}",
+		 "routes": [{"kinds": ["budget_breach"], "labels": {"team": "platform"}}]},
+		{"name": "data", "type": "teams", "webhookURL": "` + server.URL + `/data",
+		 "routes": [{"labels": {"team": "data"}}]}
+	]}`
+
+	file := filepath.Join(t.TempDir(), "notifications.json")
+	if err := os.WriteFile(file, []byte(config), 0644); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	channels, err := LoadNotificationChannels(file)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	breach := &BudgetBreach{
+		Budget:    &Budget{Name: "monthly", Limit: 100, Period: BudgetPeriodMonth},
+		Cluster:   "cluster1",
+		Namespace: "ns1",
+		Labels:    map[string]string{"team": "platform"},
+	}
+
+	notifier := NewNotifier(channels)
+	if err := notifier.PublishBudgetBreaches(context.Background(), []*BudgetBreach{breach}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(received) != 1 {
+		t.Fatalf("expected 1 channel notified; got %d", len(received))
+	}
+	expected := "*Budget monthly exceeded*\nns1 over budget monthly"
+	if text := received["/platform"]["text"]; text != expected {
+		t.Errorf("expected text %q; got %q", expected, text)
+	}
+
+	// the teams channel receives a message card
+	breach.Labels = map[string]string{"team": "data"}
+	if err := notifier.Notify(context.Background(), NewBudgetBreachNotification(breach)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if card := received["/data"]; card["@type"] != "MessageCard" || card["title"] != "Budget monthly exceeded" {
+		t.Errorf("expected message card; got %v", card)
+	}
+}
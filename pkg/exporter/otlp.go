@@ -0,0 +1,252 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/util/json"
+	"github.com/opencost/opencost/pkg/version"
+)
+
+const (
+	otlpNamespaceCostMetric = "opencost.namespace.hourly_cost"
+	otlpWorkloadCostMetric  = "opencost.workload.hourly_cost"
+)
+
+// OTLPConfig contains the options of an OTLPPublisher.
+type OTLPConfig struct {
+	// Endpoint is the base URL of the OTLP/HTTP receiver, e.g.
+	// http://otel-collector:4318. Metrics are sent to <Endpoint>/v1/metrics.
+	Endpoint string
+
+	// Headers are added to each request, e.g. for authentication.
+	Headers map[string]string
+
+	// ResourceAttributes are added to the resource of all metrics, in addition
+	// to service.name and service.version.
+	ResourceAttributes map[string]string
+
+	// Currency returns the currency code of the costs, from which the unit of
+	// the gauges, e.g. "EUR/h", is derived. Costs are in USD if nil.
+	Currency func() string
+
+	Timeout time.Duration
+}
+
+// OTLPPublisher is a CostMetricsPublisher pushing gauges to an OpenTelemetry
+// collector using OTLP/HTTP with the JSON encoding. Each resource cost is a
+// data point of the namespace or workload gauge with a "resource" attribute.
+type OTLPPublisher struct {
+	config *OTLPConfig
+	client *http.Client
+}
+
+// NewOTLPPublisher creates a new OTLPPublisher.
+func NewOTLPPublisher(config *OTLPConfig) (*OTLPPublisher, error) {
+	if config == nil || config.Endpoint == "" {
+		return nil, fmt.Errorf("otlp endpoint is required")
+	}
+
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	return &OTLPPublisher{
+		config: config,
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// Name identifies the backend in logs.
+func (op *OTLPPublisher) Name() string {
+	return "otlp"
+}
+
+// The following types are the OTLP/JSON encoding of an
+// ExportMetricsServiceRequest. 64-bit integers are encoded as strings.
+
+type otlpMetricsRequest struct {
+	ResourceMetrics []otlpResourceMetrics `json:"resourceMetrics"`
+}
+
+type otlpResourceMetrics struct {
+	Resource     otlpResource       `json:"resource"`
+	ScopeMetrics []otlpScopeMetrics `json:"scopeMetrics"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeMetrics struct {
+	Scope   otlpScope    `json:"scope"`
+	Metrics []otlpMetric `json:"metrics"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type otlpMetric struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Unit        string    `json:"unit"`
+	Gauge       otlpGauge `json:"gauge"`
+}
+
+type otlpGauge struct {
+	DataPoints []otlpDataPoint `json:"dataPoints"`
+}
+
+type otlpDataPoint struct {
+	Attributes        []otlpKeyValue `json:"attributes"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	TimeUnixNano      string         `json:"timeUnixNano"`
+	AsDouble          float64        `json:"asDouble"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+func otlpAttributes(attrs map[string]string) []otlpKeyValue {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	kvs := make([]otlpKeyValue, 0, len(keys))
+	for _, k := range keys {
+		kvs = append(kvs, otlpKeyValue{Key: k, Value: otlpAnyValue{StringValue: attrs[k]}})
+	}
+	return kvs
+}
+
+// newRequest builds the OTLP request body for the gauges.
+func (op *OTLPPublisher) newRequest(window kubecost.Window, gauges []*CostGauge) *otlpMetricsRequest {
+	resource := map[string]string{
+		"service.name":    "opencost",
+		"service.version": version.Version,
+	}
+	for k, v := range op.config.ResourceAttributes {
+		resource[k] = v
+	}
+
+	startNano := strconv.FormatInt(window.Start().UnixNano(), 10)
+	endNano := strconv.FormatInt(window.End().UnixNano(), 10)
+
+	currencyCode := "USD"
+	if op.config.Currency != nil {
+		if code := op.config.Currency(); code != "" {
+			currencyCode = code
+		}
+	}
+	unit := currencyCode + "/h"
+
+	namespaceMetric := otlpMetric{
+		Name:        otlpNamespaceCostMetric,
+		Description: "Hourly cost rate of a namespace over the trailing window",
+		Unit:        unit,
+	}
+	workloadMetric := otlpMetric{
+		Name:        otlpWorkloadCostMetric,
+		Description: "Hourly cost rate of a workload over the trailing window",
+		Unit:        unit,
+	}
+
+	for _, gauge := range gauges {
+		attrs := map[string]string{
+			"k8s.cluster.name":   gauge.Cluster,
+			"k8s.namespace.name": gauge.Namespace,
+		}
+
+		metric := &namespaceMetric
+		if gauge.Level == CostGaugeLevelWorkload {
+			attrs["opencost.controller.kind"] = gauge.ControllerKind
+			attrs["opencost.controller.name"] = gauge.Controller
+			metric = &workloadMetric
+		}
+
+		costs := gauge.Costs()
+		resources := make([]string, 0, len(costs))
+		for resource := range costs {
+			resources = append(resources, resource)
+		}
+		sort.Strings(resources)
+
+		for _, resource := range resources {
+			attrs["resource"] = resource
+			metric.Gauge.DataPoints = append(metric.Gauge.DataPoints, otlpDataPoint{
+				Attributes:        otlpAttributes(attrs),
+				StartTimeUnixNano: startNano,
+				TimeUnixNano:      endNano,
+				AsDouble:          costs[resource],
+			})
+		}
+	}
+
+	return &otlpMetricsRequest{
+		ResourceMetrics: []otlpResourceMetrics{
+			{
+				Resource: otlpResource{Attributes: otlpAttributes(resource)},
+				ScopeMetrics: []otlpScopeMetrics{
+					{
+						Scope:   otlpScope{Name: "github.com/opencost/opencost", Version: version.Version},
+						Metrics: []otlpMetric{namespaceMetric, workloadMetric},
+					},
+				},
+			},
+		},
+	}
+}
+
+// PublishCostMetrics pushes the gauges to the collector.
+func (op *OTLPPublisher) PublishCostMetrics(ctx context.Context, window kubecost.Window, gauges []*CostGauge) error {
+	if len(gauges) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(op.newRequest(window, gauges))
+	if err != nil {
+		return fmt.Errorf("encoding otlp request: %w", err)
+	}
+
+	endpoint := strings.TrimSuffix(op.config.Endpoint, "/") + "/v1/metrics"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range op.config.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := op.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("pushing otlp metrics: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("pushing otlp metrics: status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package exporter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+)
+
+func TestOTLPPublisher_Unit(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	window := kubecost.NewClosedWindow(start, start.Add(time.Hour))
+	gauges := []*CostGauge{
+		{Level: CostGaugeLevelNamespace, Cluster: "cluster1", Namespace: "ns1"},
+	}
+
+	for expected, currency := range map[string]func() string{
+		"USD/h": nil,
+		"EUR/h": func() string { return "EUR" },
+	} {
+		op, err := NewOTLPPublisher(&OTLPConfig{Endpoint: "http://localhost:4318", Currency: currency})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		metrics := op.newRequest(window, gauges).ResourceMetrics[0].ScopeMetrics[0].Metrics
+		for _, metric := range metrics {
+			if metric.Unit != expected {
+				t.Errorf("%s: expected unit %s; got %s", metric.Name, expected, metric.Unit)
+			}
+		}
+	}
+}
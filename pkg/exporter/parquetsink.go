@@ -0,0 +1,141 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+
+	"github.com/apache/arrow/go/v10/parquet/compress"
+
+	"github.com/opencost/opencost/pkg/costmodel"
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/storage"
+)
+
+const (
+	allocationDataset = "allocation"
+	assetsDataset     = "assets"
+
+	// defaultPartition is the hive convention for rows with an empty partition value
+	defaultPartition = "__HIVE_DEFAULT_PARTITION__"
+)
+
+// ParquetStorageSink writes allocation and asset windows to a Storage
+// implementation (S3, GCS, Azure Blob or the local file system) as parquet
+// files, partitioned hive-style by date and cluster:
+//
+//	<dataset>/dt=YYYY-MM-DD/cluster=<cluster>/<dataset>_<start>_<end>.parquet
+//
+// Re-exporting a window overwrites the files previously written for it.
+type ParquetStorageSink struct {
+	store             storage.Storage
+	codec             compress.Compression
+	allocationColumns []costmodel.TabularColumn[costmodel.AllocationRow]
+	assetColumns      []costmodel.TabularColumn[costmodel.AssetRow]
+}
+
+// NewParquetStorageSink creates a new sink writing to the provided store with
+// the given compression. Nil column sets select all available columns.
+func NewParquetStorageSink(store storage.Storage, codec compress.Compression, allocationColumns []costmodel.TabularColumn[costmodel.AllocationRow], assetColumns []costmodel.TabularColumn[costmodel.AssetRow]) *ParquetStorageSink {
+	if allocationColumns == nil {
+		allocationColumns = costmodel.AllocationColumns
+	}
+	if assetColumns == nil {
+		assetColumns = costmodel.AssetColumns
+	}
+
+	return &ParquetStorageSink{
+		store:             store,
+		codec:             codec,
+		allocationColumns: allocationColumns,
+		assetColumns:      assetColumns,
+	}
+}
+
+// Name returns the unique identifier for the sink.
+func (ps *ParquetStorageSink) Name() string {
+	return "parquet"
+}
+
+// ExportAllocations writes one parquet file per cluster for the window.
+func (ps *ParquetStorageSink) ExportAllocations(ctx context.Context, window kubecost.Window, as *kubecost.AllocationSet) error {
+	rows := costmodel.AllocationRowsFor(kubecost.NewAllocationSetRange(as))
+
+	byCluster := make(map[string][]costmodel.AllocationRow)
+	for _, row := range rows {
+		cluster := ""
+		if row.Item.Properties != nil {
+			cluster = row.Item.Properties.Cluster
+		}
+		byCluster[cluster] = append(byCluster[cluster], row)
+	}
+
+	for cluster, clusterRows := range byCluster {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		buf := &bytes.Buffer{}
+		err := costmodel.WriteTabularParquet(buf, ps.allocationColumns, clusterRows, ps.codec)
+		if err != nil {
+			return fmt.Errorf("encoding allocations for cluster '%s': %w", cluster, err)
+		}
+
+		if err := ps.store.Write(PartitionPath(allocationDataset, window, cluster), buf.Bytes()); err != nil {
+			return fmt.Errorf("writing allocations for cluster '%s': %w", cluster, err)
+		}
+	}
+
+	return nil
+}
+
+// ExportAssets writes one parquet file per cluster for the window.
+func (ps *ParquetStorageSink) ExportAssets(ctx context.Context, window kubecost.Window, as *kubecost.AssetSet) error {
+	rows := costmodel.AssetRowsFor(as)
+
+	byCluster := make(map[string][]costmodel.AssetRow)
+	for _, row := range rows {
+		cluster := ""
+		if props := row.Item.GetProperties(); props != nil {
+			cluster = props.Cluster
+		}
+		byCluster[cluster] = append(byCluster[cluster], row)
+	}
+
+	for cluster, clusterRows := range byCluster {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		buf := &bytes.Buffer{}
+		err := costmodel.WriteTabularParquet(buf, ps.assetColumns, clusterRows, ps.codec)
+		if err != nil {
+			return fmt.Errorf("encoding assets for cluster '%s': %w", cluster, err)
+		}
+
+		if err := ps.store.Write(PartitionPath(assetsDataset, window, cluster), buf.Bytes()); err != nil {
+			return fmt.Errorf("writing assets for cluster '%s': %w", cluster, err)
+		}
+	}
+
+	return nil
+}
+
+// PartitionPath returns the hive-partitioned path of the file containing a
+// dataset's rows for the given window and cluster.
+func PartitionPath(dataset string, window kubecost.Window, cluster string) string {
+	start, end := window.Start().UTC(), window.End().UTC()
+
+	if cluster == "" {
+		cluster = defaultPartition
+	}
+
+	return path.Join(
+		dataset,
+		"dt="+start.Format("2006-01-02"),
+		"cluster="+url.PathEscape(cluster),
+		fmt.Sprintf("%s_%s_%s.parquet", dataset, start.Format("20060102T150405Z"), end.Format("20060102T150405Z")),
+	)
+}
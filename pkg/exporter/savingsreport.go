@@ -0,0 +1,201 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opencost/opencost/pkg/errors"
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/log"
+	"github.com/opencost/opencost/pkg/util/atomic"
+)
+
+// WorkloadSavings is the potential savings of right-sizing a workload's CPU
+// and RAM requests to its usage over a window.
+type WorkloadSavings struct {
+	Cluster        string
+	Namespace      string
+	ControllerKind string
+	Controller     string
+	CPUSavings     float64
+	RAMSavings     float64
+}
+
+// TotalSavings returns the combined CPU and RAM savings.
+func (ws *WorkloadSavings) TotalSavings() float64 {
+	return ws.CPUSavings + ws.RAMSavings
+}
+
+// SavingsReport lists the workloads with the largest potential savings.
+type SavingsReport struct {
+	Window    kubecost.Window
+	Workloads []*WorkloadSavings
+	Total     float64
+}
+
+// NewSavingsReport computes the potential savings of each workload in the set,
+// where the unused share of requested CPU and RAM is considered saveable, and
+// keeps the top workloads.
+func NewSavingsReport(as *kubecost.AllocationSet, top int) *SavingsReport {
+	report := &SavingsReport{}
+	if as == nil {
+		return report
+	}
+	report.Window = as.Window.Clone()
+
+	byWorkload := make(map[string]*WorkloadSavings)
+	for _, alloc := range as.Allocations {
+		if alloc.IsIdle() || alloc.IsUnmounted() || alloc.Properties == nil {
+			continue
+		}
+		props := alloc.Properties
+
+		key := strings.Join([]string{props.Cluster, props.Namespace, props.ControllerKind, props.Controller}, "/")
+		ws, ok := byWorkload[key]
+		if !ok {
+			ws = &WorkloadSavings{
+				Cluster:        props.Cluster,
+				Namespace:      props.Namespace,
+				ControllerKind: props.ControllerKind,
+				Controller:     props.Controller,
+			}
+			byWorkload[key] = ws
+		}
+
+		if eff := alloc.CPUEfficiency(); eff > 0 && eff < 1 {
+			ws.CPUSavings += alloc.CPUTotalCost() * (1 - eff)
+		}
+		if eff := alloc.RAMEfficiency(); eff > 0 && eff < 1 {
+			ws.RAMSavings += alloc.RAMTotalCost() * (1 - eff)
+		}
+	}
+
+	for _, ws := range byWorkload {
+		if ws.TotalSavings() <= 0 {
+			continue
+		}
+		report.Workloads = append(report.Workloads, ws)
+		report.Total += ws.TotalSavings()
+	}
+
+	sort.Slice(report.Workloads, func(i, j int) bool {
+		return report.Workloads[i].TotalSavings() > report.Workloads[j].TotalSavings()
+	})
+	if top > 0 && len(report.Workloads) > top {
+		report.Workloads = report.Workloads[:top]
+	}
+
+	return report
+}
+
+// NewSavingsReportNotification creates the notification for a savings report.
+func NewSavingsReportNotification(report *SavingsReport) *Notification {
+	var text strings.Builder
+	fmt.Fprintf(&text, "Right-sizing requests to usage could have saved %.2f from %s to %s.\n",
+		report.Total, report.Window.Start().UTC().Format(time.RFC3339), report.Window.End().UTC().Format(time.RFC3339))
+
+	for _, ws := range report.Workloads {
+		fmt.Fprintf(&text, "- %s/%s %s/%s: %.2f (CPU %.2f, RAM %.2f)\n",
+			ws.Cluster, ws.Namespace, ws.ControllerKind, ws.Controller, ws.TotalSavings(), ws.CPUSavings, ws.RAMSavings)
+	}
+
+	return &Notification{
+		Kind:  NotificationKindSavingsReport,
+		Title: "OpenCost savings report",
+		Text:  text.String(),
+		Data:  report,
+	}
+}
+
+// SavingsReporterConfig contains the options of a SavingsReporter.
+type SavingsReporterConfig struct {
+	// Interval is the duration between reports, which is also the window each
+	// report covers.
+	Interval time.Duration
+
+	// Top is the number of workloads listed in each report.
+	Top int
+
+	// Resolution is the query resolution used to compute allocations.
+	Resolution time.Duration
+}
+
+// SavingsReporter periodically sends a savings report notification covering
+// the previous interval.
+type SavingsReporter struct {
+	source   Source
+	notifier *Notifier
+	config   *SavingsReporterConfig
+
+	runState atomic.AtomicRunState
+}
+
+// NewSavingsReporter creates a new SavingsReporter.
+func NewSavingsReporter(source Source, notifier *Notifier, config *SavingsReporterConfig) *SavingsReporter {
+	return &SavingsReporter{
+		source:   source,
+		notifier: notifier,
+		config:   config,
+	}
+}
+
+// Start begins reporting at the end of each interval. Returns false if the
+// reporter is already running.
+func (sr *SavingsReporter) Start() bool {
+	sr.runState.WaitForReset()
+	if !sr.runState.Start() {
+		log.Warnf("SavingsReporter: attempted to start when already running")
+		return false
+	}
+
+	go func() {
+		defer errors.HandlePanic()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go func() {
+			<-sr.runState.OnStop()
+			cancel()
+		}()
+
+		for {
+			// report at the next interval boundary, once the interval is complete
+			now := time.Now().UTC()
+			next := now.Truncate(sr.config.Interval).Add(sr.config.Interval)
+
+			select {
+			case <-sr.runState.OnStop():
+				sr.runState.Reset()
+				return
+			case <-time.After(next.Sub(now)):
+			}
+
+			if err := sr.Report(ctx, next); err != nil {
+				log.Errorf("SavingsReporter: %s", err)
+			}
+		}
+	}()
+
+	return true
+}
+
+// Stop halts the report loop.
+func (sr *SavingsReporter) Stop() {
+	sr.runState.Stop()
+}
+
+// Report sends the savings report for the interval ending at end.
+func (sr *SavingsReporter) Report(ctx context.Context, end time.Time) error {
+	start := end.Add(-sr.config.Interval)
+
+	as, err := sr.source.ComputeAllocation(start, end, sr.config.Resolution)
+	if err != nil {
+		return fmt.Errorf("computing allocations: %w", err)
+	}
+
+	return sr.notifier.Notify(ctx, NewSavingsReportNotification(NewSavingsReport(as, sr.config.Top)))
+}
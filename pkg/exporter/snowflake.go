@@ -0,0 +1,190 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/opencost/opencost/pkg/util/json"
+)
+
+// SnowflakeConfig contains the connection options of the Snowflake SQL API.
+type SnowflakeConfig struct {
+	// AccountURL is the account endpoint, e.g.
+	// https://myorg-myaccount.snowflakecomputing.com
+	AccountURL string
+
+	// TokenFile contains an OAuth access token or key pair JWT. It is read on
+	// each request so that externally rotated tokens are picked up.
+	TokenFile string
+
+	// TokenType is either OAUTH or KEYPAIR_JWT.
+	TokenType string
+
+	Database  string
+	Schema    string
+	Warehouse string
+	Role      string
+
+	// Timeout limits the execution time of each request.
+	Timeout time.Duration
+}
+
+// SnowflakeLoader is a WarehouseLoader executing statements with the
+// Snowflake SQL API, which avoids depending on a native driver. Statements
+// are submitted as a single multi-statement request and polled until done.
+type SnowflakeLoader struct {
+	config *SnowflakeConfig
+	client *http.Client
+}
+
+// NewSnowflakeLoader creates a new SnowflakeLoader.
+func NewSnowflakeLoader(config *SnowflakeConfig) (*SnowflakeLoader, error) {
+	if config == nil || config.AccountURL == "" || config.TokenFile == "" {
+		return nil, fmt.Errorf("snowflake account url and token file are required")
+	}
+	if config.TokenType == "" {
+		config.TokenType = "OAUTH"
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Minute
+	}
+
+	return &SnowflakeLoader{
+		config: config,
+		client: &http.Client{Timeout: time.Minute},
+	}, nil
+}
+
+type snowflakeStatementRequest struct {
+	Statement  string            `json:"statement"`
+	Timeout    int               `json:"timeout"`
+	Database   string            `json:"database,omitempty"`
+	Schema     string            `json:"schema,omitempty"`
+	Warehouse  string            `json:"warehouse,omitempty"`
+	Role       string            `json:"role,omitempty"`
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+type snowflakeStatementResponse struct {
+	Code            string `json:"code"`
+	Message         string `json:"message"`
+	SQLState        string `json:"sqlState"`
+	StatementHandle string `json:"statementHandle"`
+}
+
+// Exec runs the statements in order as a single multi-statement request.
+func (sl *SnowflakeLoader) Exec(ctx context.Context, statements []string) error {
+	if len(statements) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(snowflakeStatementRequest{
+		Statement: strings.Join(statements, ";\n"),
+		Timeout:   int(sl.config.Timeout.Seconds()),
+		Database:  sl.config.Database,
+		Schema:    sl.config.Schema,
+		Warehouse: sl.config.Warehouse,
+		Role:      sl.config.Role,
+		Parameters: map[string]string{
+			"MULTI_STATEMENT_COUNT": strconv.Itoa(len(statements)),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// the request id makes resubmission of the same request idempotent
+	query := url.Values{}
+	query.Set("requestId", uuid.NewString())
+	endpoint := strings.TrimSuffix(sl.config.AccountURL, "/") + "/api/v2/statements?" + query.Encode()
+
+	resp, err := sl.do(ctx, http.MethodPost, endpoint, body)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(sl.config.Timeout)
+	for resp.Code == snowflakeCodeInProgress {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("snowflake statement %s did not complete within %s", resp.StatementHandle, sl.config.Timeout)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(2 * time.Second):
+		}
+
+		statusURL := strings.TrimSuffix(sl.config.AccountURL, "/") + "/api/v2/statements/" + url.PathEscape(resp.StatementHandle)
+		resp, err = sl.do(ctx, http.MethodGet, statusURL, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// snowflakeCodeInProgress is returned with a 202 while a statement is running.
+const snowflakeCodeInProgress = "333334"
+
+func (sl *SnowflakeLoader) do(ctx context.Context, method, endpoint string, body []byte) (*snowflakeStatementResponse, error) {
+	token, err := os.ReadFile(sl.config.TokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading snowflake token: %w", err)
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	req.Header.Set("X-Snowflake-Authorization-Token-Type", sl.config.TokenType)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	httpResp, err := sl.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("calling snowflake sql api: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading snowflake response: %w", err)
+	}
+
+	var result snowflakeStatementResponse
+	if err := json.Unmarshal(respBody, &result); err != nil && httpResp.StatusCode < 300 {
+		return nil, fmt.Errorf("decoding snowflake response: %w", err)
+	}
+
+	switch {
+	case httpResp.StatusCode == http.StatusAccepted:
+		result.Code = snowflakeCodeInProgress
+		return &result, nil
+	case httpResp.StatusCode == http.StatusOK:
+		return &result, nil
+	case result.Message != "":
+		return nil, fmt.Errorf("snowflake statement failed: %s (code %s, sql state %s)", result.Message, result.Code, result.SQLState)
+	default:
+		return nil, fmt.Errorf("snowflake sql api: status %d: %s", httpResp.StatusCode, string(respBody))
+	}
+}
@@ -0,0 +1,403 @@
+package exporter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/apache/arrow/go/v10/parquet/compress"
+
+	"github.com/opencost/opencost/pkg/costmodel"
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/storage"
+	"github.com/opencost/opencost/pkg/util/json"
+)
+
+// warehouseTimeColumns are the tabular columns containing timestamps.
+var warehouseTimeColumns = map[string]bool{
+	"WindowStart": true,
+	"WindowEnd":   true,
+	"Start":       true,
+	"End":         true,
+}
+
+// WarehouseLoader executes SQL statements against a data warehouse. The
+// statements for a single load are passed together and must be executed in
+// order within the same session, so that explicit transactions are honored.
+type WarehouseLoader interface {
+	Exec(ctx context.Context, statements []string) error
+}
+
+// WarehouseColumn is a column of a warehouse table.
+type WarehouseColumn struct {
+	Name string
+	Type string
+}
+
+// WarehouseStatementData is the data available to WarehouseDialect templates.
+type WarehouseStatementData struct {
+	// Table is the name of the destination table.
+	Table string
+
+	// Columns are the columns of the table, in the order of the staged file.
+	Columns []WarehouseColumn
+
+	// Column is the column being added, for AddColumn templates.
+	Column WarehouseColumn
+
+	// Location is the staged file, i.e. the configured stage joined with the
+	// path of the file in the staging storage.
+	Location string
+
+	// Format is the format of the staged file, "csv" (gzip compressed, with a
+	// header row) or "parquet".
+	Format string
+
+	// WindowStart and WindowEnd are the RFC3339 bounds of the loaded window.
+	WindowStart string
+	WindowEnd   string
+}
+
+// WarehouseDialect contains the column types and statement templates used to
+// manage and load warehouse tables. Templates are text/template strings
+// executed with WarehouseStatementData.
+type WarehouseDialect struct {
+	StringType string `json:"stringType"`
+	FloatType  string `json:"floatType"`
+	TimeType   string `json:"timeType"`
+
+	// CreateTable creates the table if it does not exist.
+	CreateTable string `json:"createTable"`
+
+	// AddColumn adds a column if it does not exist. It is executed for each
+	// column, so tables created by older versions gain new columns.
+	AddColumn string `json:"addColumn"`
+
+	// Load replaces the rows of a window with the staged file. Statements are
+	// executed in order, so they should delete the window's existing rows and
+	// copy the staged file within a single transaction.
+	Load []string `json:"load"`
+}
+
+// SnowflakeDialect loads staged files with COPY INTO from an external stage
+// which points at the staging storage. FORCE = TRUE makes Snowflake reload a
+// file even if it has been loaded before, so reloading a window is safe.
+var SnowflakeDialect = &WarehouseDialect{
+	StringType:  "VARCHAR",
+	FloatType:   "DOUBLE",
+	TimeType:    "TIMESTAMP_TZ",
+	CreateTable: `CREATE TABLE IF NOT EXISTS {{.Table}} ({{range $i, $c := .Columns}}{{if $i}}, {{end}}"{{$c.Name}}" {{$c.Type}}{{end}}) CLUSTER BY ("WindowStart")`,
+	AddColumn:   `ALTER TABLE {{.Table}} ADD COLUMN IF NOT EXISTS "{{.Column.Name}}" {{.Column.Type}}`,
+	Load: []string{
+		`BEGIN`,
+		`DELETE FROM {{.Table}} WHERE "WindowStart" = '{{.WindowStart}}' AND "WindowEnd" = '{{.WindowEnd}}'`,
+		`COPY INTO {{.Table}} FROM {{.Location}} ` +
+			`{{if eq .Format "parquet"}}FILE_FORMAT = (TYPE = PARQUET) MATCH_BY_COLUMN_NAME = CASE_SENSITIVE` +
+			`{{else}}FILE_FORMAT = (TYPE = CSV SKIP_HEADER = 1 FIELD_OPTIONALLY_ENCLOSED_BY = '"' COMPRESSION = GZIP){{end}} ` +
+			`FORCE = TRUE ON_ERROR = ABORT_STATEMENT`,
+		`COMMIT`,
+	},
+}
+
+// LoadWarehouseDialect reads a custom dialect from a JSON file. Unset fields
+// default to the Snowflake dialect.
+func LoadWarehouseDialect(file string) (*WarehouseDialect, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading warehouse dialect: %w", err)
+	}
+
+	dialect := *SnowflakeDialect
+	if err := json.Unmarshal(b, &dialect); err != nil {
+		return nil, fmt.Errorf("parsing warehouse dialect: %w", err)
+	}
+
+	return &dialect, nil
+}
+
+// WarehouseConfig contains the options of a WarehouseSink.
+type WarehouseConfig struct {
+	// Stage is the warehouse's reference to the root of the staging storage,
+	// e.g. "@opencost_stage" for a Snowflake external stage.
+	Stage string
+
+	// StagePrefix is the directory within the staging storage to which files
+	// are written.
+	StagePrefix string
+
+	// Format is the staged file format, either csv or parquet.
+	Format costmodel.ResponseFormat
+
+	// Codec is the compression of staged parquet files.
+	Codec compress.Compression
+
+	AllocationTable string
+	AssetTable      string
+}
+
+// WarehouseSink exports windows to a data warehouse by staging a file per
+// window to object storage and then loading it with the dialect's statements.
+// Staged file paths are keyed by window, so re-exporting a window overwrites
+// its staged file and replaces its rows in the table.
+type WarehouseSink struct {
+	store   storage.Storage
+	loader  WarehouseLoader
+	dialect *WarehouseDialect
+	config  *WarehouseConfig
+
+	allocationColumns []costmodel.TabularColumn[costmodel.AllocationRow]
+	assetColumns      []costmodel.TabularColumn[costmodel.AssetRow]
+
+	lock    sync.Mutex
+	ensured map[string]bool
+}
+
+// NewWarehouseSink creates a new WarehouseSink. Nil column sets select all
+// available columns; the WindowStart and WindowEnd columns are required.
+func NewWarehouseSink(store storage.Storage, loader WarehouseLoader, dialect *WarehouseDialect, config *WarehouseConfig, allocationColumns []costmodel.TabularColumn[costmodel.AllocationRow], assetColumns []costmodel.TabularColumn[costmodel.AssetRow]) (*WarehouseSink, error) {
+	if dialect == nil {
+		dialect = SnowflakeDialect
+	}
+	if config.Format != costmodel.ResponseFormatCSV && config.Format != costmodel.ResponseFormatParquet {
+		return nil, fmt.Errorf("unsupported warehouse staging format '%s'", config.Format)
+	}
+	if config.AllocationTable == "" {
+		config.AllocationTable = "opencost_allocations"
+	}
+	if config.AssetTable == "" {
+		config.AssetTable = "opencost_assets"
+	}
+
+	if allocationColumns == nil {
+		allocationColumns = costmodel.AllocationColumns
+	}
+	if assetColumns == nil {
+		assetColumns = costmodel.AssetColumns
+	}
+	if !hasWindowColumns(columnNames(allocationColumns)) || !hasWindowColumns(columnNames(assetColumns)) {
+		return nil, fmt.Errorf("warehouse export requires the WindowStart and WindowEnd columns")
+	}
+
+	return &WarehouseSink{
+		store:             store,
+		loader:            loader,
+		dialect:           dialect,
+		config:            config,
+		allocationColumns: allocationColumns,
+		assetColumns:      assetColumns,
+		ensured:           make(map[string]bool),
+	}, nil
+}
+
+// Name returns the unique identifier for the sink.
+func (ws *WarehouseSink) Name() string {
+	return "warehouse"
+}
+
+// ExportAllocations stages and loads the allocations of the window.
+func (ws *WarehouseSink) ExportAllocations(ctx context.Context, window kubecost.Window, as *kubecost.AllocationSet) error {
+	rows := costmodel.AllocationRowsFor(kubecost.NewAllocationSetRange(as))
+
+	var buf bytes.Buffer
+	if err := writeStaged(&buf, ws.config, ws.allocationColumns, rows); err != nil {
+		return fmt.Errorf("encoding allocations: %w", err)
+	}
+
+	columns := warehouseColumns(ws.dialect, ws.allocationColumns)
+	return ws.load(ctx, allocationDataset, ws.config.AllocationTable, columns, window, buf.Bytes())
+}
+
+// ExportAssets stages and loads the assets of the window.
+func (ws *WarehouseSink) ExportAssets(ctx context.Context, window kubecost.Window, as *kubecost.AssetSet) error {
+	rows := costmodel.AssetRowsFor(as)
+
+	var buf bytes.Buffer
+	if err := writeStaged(&buf, ws.config, ws.assetColumns, rows); err != nil {
+		return fmt.Errorf("encoding assets: %w", err)
+	}
+
+	columns := warehouseColumns(ws.dialect, ws.assetColumns)
+	return ws.load(ctx, assetsDataset, ws.config.AssetTable, columns, window, buf.Bytes())
+}
+
+// StagePath returns the path of the staged file for a dataset's window.
+func (ws *WarehouseSink) StagePath(dataset string, window kubecost.Window) string {
+	start, end := window.Start().UTC(), window.End().UTC()
+
+	ext := ws.config.Format.FileExtension()
+	if ws.config.Format == costmodel.ResponseFormatCSV {
+		ext += ".gz"
+	}
+
+	return path.Join(
+		ws.config.StagePrefix,
+		dataset,
+		fmt.Sprintf("%s_%s_%s%s", dataset, start.Format("20060102T150405Z"), end.Format("20060102T150405Z"), ext),
+	)
+}
+
+func (ws *WarehouseSink) load(ctx context.Context, dataset, table string, columns []WarehouseColumn, window kubecost.Window, data []byte) error {
+	if err := ws.ensureTable(ctx, table, columns); err != nil {
+		return err
+	}
+
+	stagePath := ws.StagePath(dataset, window)
+	if err := ws.store.Write(stagePath, data); err != nil {
+		return fmt.Errorf("staging %s: %w", stagePath, err)
+	}
+
+	statements, err := ws.render(ws.dialect.Load, WarehouseStatementData{
+		Table:       table,
+		Columns:     columns,
+		Location:    strings.TrimSuffix(ws.config.Stage, "/") + "/" + stagePath,
+		Format:      string(ws.config.Format),
+		WindowStart: window.Start().UTC().Format(time.RFC3339),
+		WindowEnd:   window.End().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := ws.loader.Exec(ctx, statements); err != nil {
+		return fmt.Errorf("loading %s into %s: %w", stagePath, table, err)
+	}
+
+	return nil
+}
+
+// ensureTable creates the table and adds missing columns once per sink.
+func (ws *WarehouseSink) ensureTable(ctx context.Context, table string, columns []WarehouseColumn) error {
+	ws.lock.Lock()
+	defer ws.lock.Unlock()
+
+	if ws.ensured[table] {
+		return nil
+	}
+
+	data := WarehouseStatementData{Table: table, Columns: columns}
+	statements, err := ws.render([]string{ws.dialect.CreateTable}, data)
+	if err != nil {
+		return err
+	}
+
+	if ws.dialect.AddColumn != "" {
+		for _, col := range columns {
+			data.Column = col
+			add, err := ws.render([]string{ws.dialect.AddColumn}, data)
+			if err != nil {
+				return err
+			}
+			statements = append(statements, add...)
+		}
+	}
+
+	if err := ws.loader.Exec(ctx, statements); err != nil {
+		return fmt.Errorf("managing table %s: %w", table, err)
+	}
+
+	ws.ensured[table] = true
+	return nil
+}
+
+func (ws *WarehouseSink) render(templates []string, data WarehouseStatementData) ([]string, error) {
+	statements := make([]string, 0, len(templates))
+	for _, text := range templates {
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		tmpl, err := template.New("statement").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("parsing statement template: %w", err)
+		}
+
+		var buf strings.Builder
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("rendering statement template: %w", err)
+		}
+		statements = append(statements, buf.String())
+	}
+	return statements, nil
+}
+
+// warehouseColumns maps tabular columns to the dialect's column types.
+// Timestamps are written as RFC3339 strings and typed as times in the table.
+func warehouseColumns[T any](dialect *WarehouseDialect, columns []costmodel.TabularColumn[T]) []WarehouseColumn {
+	result := make([]WarehouseColumn, 0, len(columns))
+	for _, col := range columns {
+		typ := dialect.StringType
+		switch {
+		case col.IsNumeric():
+			typ = dialect.FloatType
+		case warehouseTimeColumns[col.Name]:
+			typ = dialect.TimeType
+		}
+		result = append(result, WarehouseColumn{Name: col.Name, Type: typ})
+	}
+	return result
+}
+
+// writeStaged encodes the rows in the staged file format.
+func writeStaged[T any](buf *bytes.Buffer, config *WarehouseConfig, columns []costmodel.TabularColumn[T], rows []T) error {
+	if config.Format == costmodel.ResponseFormatParquet {
+		return costmodel.WriteTabularParquet(buf, columns, rows, config.Codec)
+	}
+
+	gz := gzip.NewWriter(buf)
+	if err := costmodel.WriteTabularCSV(gz, columns, rows); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func columnNames[T any](columns []costmodel.TabularColumn[T]) []string {
+	names := make([]string, 0, len(columns))
+	for _, col := range columns {
+		names = append(names, col.Name)
+	}
+	return names
+}
+
+func hasWindowColumns(names []string) bool {
+	var start, end bool
+	for _, name := range names {
+		start = start || name == "WindowStart"
+		end = end || name == "WindowEnd"
+	}
+	return start && end
+}
+
+// SQLLoader is a WarehouseLoader executing statements through a database/sql
+// driver, which allows loading into any warehouse with a registered driver.
+type SQLLoader struct {
+	db *sql.DB
+}
+
+// NewSQLLoader creates a new SQLLoader for the database.
+func NewSQLLoader(db *sql.DB) *SQLLoader {
+	return &SQLLoader{db: db}
+}
+
+// Exec runs the statements in order on a single connection.
+func (sl *SQLLoader) Exec(ctx context.Context, statements []string) error {
+	conn, err := sl.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for _, statement := range statements {
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("executing '%s': %w", statement, err)
+		}
+	}
+	return nil
+}
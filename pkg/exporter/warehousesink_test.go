@@ -0,0 +1,70 @@
+package exporter
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/pkg/costmodel"
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/storage"
+)
+
+type recordingLoader struct {
+	execs [][]string
+}
+
+func (rl *recordingLoader) Exec(ctx context.Context, statements []string) error {
+	rl.execs = append(rl.execs, statements)
+	return nil
+}
+
+func TestWarehouseSink_ExportAllocations(t *testing.T) {
+	store := storage.NewFileStorage(t.TempDir())
+	loader := &recordingLoader{}
+
+	sink, err := NewWarehouseSink(store, loader, SnowflakeDialect, &WarehouseConfig{
+		Stage:       "@stage",
+		StagePrefix: "opencost",
+		Format:      costmodel.ResponseFormatCSV,
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	window := kubecost.NewClosedWindow(start, start.Add(24*time.Hour))
+	as := kubecost.NewAllocationSet(start, start.Add(24*time.Hour))
+
+	for i := 0; i < 2; i++ {
+		if err := sink.ExportAllocations(context.Background(), window, as); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	// table management runs once, followed by a load per export
+	if len(loader.execs) != 3 {
+		t.Fatalf("expected 3 executions; got %d", len(loader.execs))
+	}
+	if !strings.HasPrefix(loader.execs[0][0], `CREATE TABLE IF NOT EXISTS opencost_allocations ("WindowStart" TIMESTAMP_TZ, "WindowEnd" TIMESTAMP_TZ, "Name" VARCHAR`) {
+		t.Errorf("unexpected create statement: %s", loader.execs[0][0])
+	}
+
+	stagePath := "opencost/allocation/allocation_20230101T000000Z_20230102T000000Z.csv.gz"
+	if exists, _ := store.Exists(stagePath); !exists {
+		t.Errorf("expected staged file %s", stagePath)
+	}
+
+	load := loader.execs[1]
+	if len(load) != 4 {
+		t.Fatalf("expected 4 load statements; got %d", len(load))
+	}
+	expectedDelete := `DELETE FROM opencost_allocations WHERE "WindowStart" = '2023-01-01T00:00:00Z' AND "WindowEnd" = '2023-01-02T00:00:00Z'`
+	if load[1] != expectedDelete {
+		t.Errorf("expected %s; got %s", expectedDelete, load[1])
+	}
+	if !strings.HasPrefix(load[2], "COPY INTO opencost_allocations FROM @stage/"+stagePath+" FILE_FORMAT = (TYPE = CSV") {
+		t.Errorf("unexpected copy statement: %s", load[2])
+	}
+}
@@ -114,9 +114,23 @@ type Allocation struct {
 // If we had types to differentiate between regular Allocations and AggregatedAllocations
 // then this type would be unnecessary and its fields would go into the regular Allocation
 // and not in the AggregatedAllocation.
+//
+// Usage percentiles belong here for the same reason: the percentiles of a
+// combination of Allocations cannot be computed from their percentiles. They
+// are zero unless computing them is enabled.
 type RawAllocationOnlyData struct {
 	CPUCoreUsageMax  float64 `json:"cpuCoreUsageMax"`
 	RAMBytesUsageMax float64 `json:"ramByteUsageMax"`
+	// The usage percentiles are those of the samples of the window when it is
+	// queried at once. When the window is computed in ranges, they are the max
+	// of the percentiles of its ranges, which is an upper bound of, rather
+	// than equal to, the percentiles of the window.
+	CPUCoreUsageP50  float64 `json:"cpuCoreUsageP50"` // @bingen:field[version=17]
+	CPUCoreUsageP95  float64 `json:"cpuCoreUsageP95"` // @bingen:field[version=17]
+	CPUCoreUsageP99  float64 `json:"cpuCoreUsageP99"` // @bingen:field[version=17]
+	RAMBytesUsageP50 float64 `json:"ramByteUsageP50"` // @bingen:field[version=17]
+	RAMBytesUsageP95 float64 `json:"ramByteUsageP95"` // @bingen:field[version=17]
+	RAMBytesUsageP99 float64 `json:"ramByteUsageP99"` // @bingen:field[version=17]
 }
 
 // Clone returns a deep copy of the given RawAllocationOnlyData
@@ -128,6 +142,12 @@ func (r *RawAllocationOnlyData) Clone() *RawAllocationOnlyData {
 	return &RawAllocationOnlyData{
 		CPUCoreUsageMax:  r.CPUCoreUsageMax,
 		RAMBytesUsageMax: r.RAMBytesUsageMax,
+		CPUCoreUsageP50:  r.CPUCoreUsageP50,
+		CPUCoreUsageP95:  r.CPUCoreUsageP95,
+		CPUCoreUsageP99:  r.CPUCoreUsageP99,
+		RAMBytesUsageP50: r.RAMBytesUsageP50,
+		RAMBytesUsageP95: r.RAMBytesUsageP95,
+		RAMBytesUsageP99: r.RAMBytesUsageP99,
 	}
 }
 
@@ -140,7 +160,13 @@ func (r *RawAllocationOnlyData) Equal(that *RawAllocationOnlyData) bool {
 		return false
 	}
 	return util.IsApproximately(r.CPUCoreUsageMax, that.CPUCoreUsageMax) &&
-		util.IsApproximately(r.RAMBytesUsageMax, that.RAMBytesUsageMax)
+		util.IsApproximately(r.RAMBytesUsageMax, that.RAMBytesUsageMax) &&
+		util.IsApproximately(r.CPUCoreUsageP50, that.CPUCoreUsageP50) &&
+		util.IsApproximately(r.CPUCoreUsageP95, that.CPUCoreUsageP95) &&
+		util.IsApproximately(r.CPUCoreUsageP99, that.CPUCoreUsageP99) &&
+		util.IsApproximately(r.RAMBytesUsageP50, that.RAMBytesUsageP50) &&
+		util.IsApproximately(r.RAMBytesUsageP95, that.RAMBytesUsageP95) &&
+		util.IsApproximately(r.RAMBytesUsageP99, that.RAMBytesUsageP99)
 }
 
 // PVAllocations is a map of Disk Asset Identifiers to the
@@ -1112,7 +1138,7 @@ func (as *AllocationSet) AggregateBy(aggregateBy []string, options *AllocationAg
 	}
 
 	if options.LabelConfig == nil {
-		options.LabelConfig = NewLabelConfig()
+		options.LabelConfig = DefaultLabelConfig()
 	}
 
 	// idleFiltrationCoefficients relies on this being explicitly set
@@ -2024,7 +2050,7 @@ func (a *Allocation) StringProperty(property string) (string, error) {
 			return "", nil
 		}
 		return a.Properties.Container, nil
-	case AllocationControllerProp:
+	case AllocationControllerProp, AllocationControllerNameProp:
 		if a.Properties == nil {
 			return "", nil
 		}
@@ -16,6 +16,7 @@ const (
 	AllocationContainerProp      string = "container"
 	AllocationControllerProp     string = "controller"
 	AllocationControllerKindProp string = "controllerKind"
+	AllocationControllerNameProp string = "controllerName"
 	AllocationNamespaceProp      string = "namespace"
 	AllocationPodProp            string = "pod"
 	AllocationProviderIDProp     string = "providerID"
@@ -26,6 +27,7 @@ const (
 	AllocationStatefulSetProp    string = "statefulset"
 	AllocationDaemonSetProp      string = "daemonset"
 	AllocationJobProp            string = "job"
+	AllocationCronJobProp        string = "cronjob"
 	AllocationDepartmentProp     string = "department"
 	AllocationEnvironmentProp    string = "environment"
 	AllocationOwnerProp          string = "owner"
@@ -45,6 +47,8 @@ func ParseProperty(text string) (string, error) {
 		return AllocationControllerProp, nil
 	case "controllerkind":
 		return AllocationControllerKindProp, nil
+	case "controllername":
+		return AllocationControllerNameProp, nil
 	case "namespace":
 		return AllocationNamespaceProp, nil
 	case "pod":
@@ -65,6 +69,8 @@ func ParseProperty(text string) (string, error) {
 		return AllocationStatefulSetProp, nil
 	case "job":
 		return AllocationJobProp, nil
+	case "cronjob":
+		return AllocationCronJobProp, nil
 	case "department":
 		return AllocationDepartmentProp, nil
 	case "environment":
@@ -240,7 +246,7 @@ func (p *AllocationProperties) GenerateKey(aggregateBy []string, labelConfig *La
 	}
 
 	if labelConfig == nil {
-		labelConfig = NewLabelConfig()
+		labelConfig = DefaultLabelConfig()
 	}
 
 	// Names will ultimately be joined into a single name, which uniquely
@@ -262,7 +268,7 @@ func (p *AllocationProperties) GenerateKey(aggregateBy []string, labelConfig *La
 				controllerKind = UnallocatedSuffix
 			}
 			names = append(names, controllerKind)
-		case agg == AllocationDaemonSetProp || agg == AllocationStatefulSetProp || agg == AllocationDeploymentProp || agg == AllocationJobProp:
+		case agg == AllocationDaemonSetProp || agg == AllocationStatefulSetProp || agg == AllocationDeploymentProp || agg == AllocationJobProp || agg == AllocationCronJobProp:
 			controller := p.Controller
 			if agg != p.ControllerKind || controller == "" {
 				// The allocation does not have the specified controller kind
@@ -278,6 +284,13 @@ func (p *AllocationProperties) GenerateKey(aggregateBy []string, labelConfig *La
 				controller = fmt.Sprintf("%s:%s", p.ControllerKind, controller)
 			}
 			names = append(names, controller)
+		case agg == AllocationControllerNameProp:
+			// Unlike controller, the name is not qualified by the kind
+			controller := p.Controller
+			if controller == "" {
+				controller = UnallocatedSuffix
+			}
+			names = append(names, controller)
 		case agg == AllocationPodProp:
 			names = append(names, p.Pod)
 		case agg == AllocationContainerProp:
@@ -209,6 +209,37 @@ func TestGenerateKey(t *testing.T) {
 			},
 			expected: "product-label/owner-label",
 		},
+		"aggregate by cronjob": {
+			aggregate: []string{"cronjob"},
+			allocationProps: &AllocationProperties{
+				ControllerKind: "cronjob",
+				Controller:     "nightly-backup",
+			},
+			expected: "nightly-backup",
+		},
+		"aggregate by cronjob without cronjob": {
+			aggregate: []string{"cronjob"},
+			allocationProps: &AllocationProperties{
+				ControllerKind: "job",
+				Controller:     "migrate",
+			},
+			expected: UnallocatedSuffix,
+		},
+		"aggregate by controllerKind and controllerName": {
+			aggregate: []string{"controllerKind", "controllerName"},
+			allocationProps: &AllocationProperties{
+				ControllerKind: "statefulset",
+				Controller:     "postgres",
+			},
+			expected: "statefulset/postgres",
+		},
+		"aggregate by controllerName without controller": {
+			aggregate: []string{"controllerName"},
+			allocationProps: &AllocationProperties{
+				Pod: "bare-pod",
+			},
+			expected: UnallocatedSuffix,
+		},
 		"user test": {
 			aggregate: []string{"owner"},
 			allocationProps: &AllocationProperties{
@@ -138,7 +138,7 @@ func AssetToExternalAllocation(asset Asset, aggregateBy []string, labelConfig *L
 
 	// Use default label config if one is not provided.
 	if labelConfig == nil {
-		labelConfig = NewLabelConfig()
+		labelConfig = DefaultLabelConfig()
 	}
 
 	// names will collect the slash-separated names accrued by iterating over
@@ -190,7 +190,7 @@ func AssetToExternalAllocation(asset Asset, aggregateBy []string, labelConfig *L
 				props.Namespace = name
 			case AllocationControllerKindProp:
 				props.ControllerKind = name
-			case AllocationControllerProp:
+			case AllocationControllerProp, AllocationControllerNameProp:
 				props.Controller = name
 			case AllocationPodProp:
 				props.Pod = name
@@ -260,7 +260,7 @@ func key(a Asset, aggregateBy []string, labelConfig *LabelConfig) (string, error
 	var buffer strings.Builder
 
 	if labelConfig == nil {
-		labelConfig = NewLabelConfig()
+		labelConfig = DefaultLabelConfig()
 	}
 
 	if aggregateBy == nil {
@@ -453,6 +453,15 @@ const (
 
 	// SharedAssetType describes the Shared AssetType
 	SharedAssetType
+
+	// GPUAssetType describes the GPU AssetType
+	GPUAssetType
+
+	// PublicIPAssetType describes the PublicIP AssetType
+	PublicIPAssetType
+
+	// NATGatewayAssetType describes the NATGateway AssetType
+	NATGatewayAssetType
 )
 
 // ParseAssetType attempts to parse the given string into an AssetType
@@ -472,6 +481,12 @@ func ParseAssetType(text string) (AssetType, error) {
 		return NodeAssetType, nil
 	case "shared":
 		return SharedAssetType, nil
+	case "gpu":
+		return GPUAssetType, nil
+	case "publicip":
+		return PublicIPAssetType, nil
+	case "natgateway":
+		return NATGatewayAssetType, nil
 	}
 	return AnyAssetType, fmt.Errorf("invalid asset type: %s", text)
 }
@@ -487,6 +502,9 @@ func (at AssetType) String() string {
 		"Network",
 		"Node",
 		"Shared",
+		"GPU",
+		"PublicIP",
+		"NATGateway",
 	}[at]
 }
 
@@ -2401,6 +2419,711 @@ func (lb *LoadBalancer) String() string {
 	return toString(lb)
 }
 
+// GPU is an Asset representing the GPUs attached to a single node. The cost of
+// a node's GPUs is itemized as a GPU, named after the node, rather than being
+// included in the cost of the Node.
+type GPU struct {
+	Properties *AssetProperties
+	Labels     AssetLabels
+	Start      time.Time
+	End        time.Time
+	Window     Window
+	Adjustment float64
+	Cost       float64
+	GPUHours   float64
+}
+
+// NewGPU instantiates and returns a new GPU
+func NewGPU(name, cluster, providerID string, start, end time.Time, window Window) *GPU {
+	properties := &AssetProperties{
+		Category:   ComputeCategory,
+		Name:       name,
+		Cluster:    cluster,
+		ProviderID: providerID,
+		Service:    KubernetesService,
+	}
+
+	return &GPU{
+		Properties: properties,
+		Labels:     AssetLabels{},
+		Start:      start,
+		End:        end,
+		Window:     window,
+	}
+}
+
+// Type returns the AssetType of the Asset
+func (gpu *GPU) Type() AssetType {
+	return GPUAssetType
+}
+
+// Properties returns the Asset's Properties
+func (gpu *GPU) GetProperties() *AssetProperties {
+	return gpu.Properties
+}
+
+// SetProperties sets the Asset's Properties
+func (gpu *GPU) SetProperties(props *AssetProperties) {
+	gpu.Properties = props
+}
+
+// Labels returns the Asset's labels
+func (gpu *GPU) GetLabels() AssetLabels {
+	return gpu.Labels
+}
+
+// SetLabels sets the Asset's labels
+func (gpu *GPU) SetLabels(labels AssetLabels) {
+	gpu.Labels = labels
+}
+
+// Adjustment returns the Asset's cost adjustment
+func (gpu *GPU) GetAdjustment() float64 {
+	return gpu.Adjustment
+}
+
+// SetAdjustment sets the Asset's cost adjustment
+func (gpu *GPU) SetAdjustment(adj float64) {
+	gpu.Adjustment = adj
+}
+
+// TotalCost returns the total cost of the Asset
+func (gpu *GPU) TotalCost() float64 {
+	return gpu.Cost + gpu.Adjustment
+}
+
+// Start returns the preceise start point of the Asset within the window
+func (gpu *GPU) GetStart() time.Time {
+	return gpu.Start
+}
+
+// End returns the preceise end point of the Asset within the window
+func (gpu *GPU) GetEnd() time.Time {
+	return gpu.End
+}
+
+// Minutes returns the number of minutes the Asset ran within the window
+func (gpu *GPU) Minutes() float64 {
+	return gpu.End.Sub(gpu.Start).Minutes()
+}
+
+// Window returns the window within which the Asset ran
+func (gpu *GPU) GetWindow() Window {
+	return gpu.Window
+}
+
+func (gpu *GPU) SetWindow(window Window) {
+	gpu.Window = window
+}
+
+// ExpandWindow expands the Asset's window by the given window
+func (gpu *GPU) ExpandWindow(w Window) {
+	gpu.Window = gpu.Window.Expand(w)
+}
+
+// SetStartEnd sets the Asset's Start and End fields
+func (gpu *GPU) SetStartEnd(start, end time.Time) {
+	if gpu.Window.Contains(start) {
+		gpu.Start = start
+	} else {
+		log.Warnf("GPU.SetStartEnd: start %s not in %s", start, gpu.Window)
+	}
+
+	if gpu.Window.Contains(end) {
+		gpu.End = end
+	} else {
+		log.Warnf("GPU.SetStartEnd: end %s not in %s", end, gpu.Window)
+	}
+}
+
+// Add sums the Asset with the given Asset to produce a new Asset, maintaining
+// as much relevant information as possible (i.e. type, Properties, labels).
+func (gpu *GPU) Add(a Asset) Asset {
+	// GPU + GPU = GPU
+	if that, ok := a.(*GPU); ok {
+		this := gpu.Clone().(*GPU)
+		this.add(that)
+		return this
+	}
+
+	props := gpu.GetProperties().Merge(a.GetProperties())
+	labels := gpu.Labels.Merge(a.GetLabels())
+
+	start := gpu.Start
+	if a.GetStart().Before(start) {
+		start = a.GetStart()
+	}
+	end := gpu.End
+	if a.GetEnd().After(end) {
+		end = a.GetEnd()
+	}
+	window := gpu.Window.Expand(a.GetWindow())
+
+	// GPU + !GPU = Any
+	any := NewAsset(start, end, window)
+	any.SetProperties(props)
+	any.SetLabels(labels)
+	any.Adjustment = gpu.Adjustment + a.GetAdjustment()
+	any.Cost = (gpu.TotalCost() - gpu.Adjustment) + (a.TotalCost() - a.GetAdjustment())
+
+	return any
+}
+
+func (gpu *GPU) add(that *GPU) {
+	if gpu == nil {
+		gpu = that
+		return
+	}
+
+	props := gpu.Properties.Merge(that.GetProperties())
+	labels := gpu.Labels.Merge(that.GetLabels())
+	gpu.SetProperties(props)
+	gpu.SetLabels(labels)
+
+	start := gpu.Start
+	if that.Start.Before(start) {
+		start = that.Start
+	}
+	end := gpu.End
+	if that.End.After(end) {
+		end = that.End
+	}
+	window := gpu.Window.Expand(that.Window)
+	gpu.Start = start
+	gpu.End = end
+	gpu.Window = window
+
+	gpu.Cost += that.Cost
+	gpu.Adjustment += that.Adjustment
+	gpu.GPUHours += that.GPUHours
+}
+
+// Clone returns a cloned instance of the given Asset
+func (gpu *GPU) Clone() Asset {
+	return &GPU{
+		Properties: gpu.Properties.Clone(),
+		Labels:     gpu.Labels.Clone(),
+		Start:      gpu.Start,
+		End:        gpu.End,
+		Window:     gpu.Window.Clone(),
+		Adjustment: gpu.Adjustment,
+		Cost:       gpu.Cost,
+		GPUHours:   gpu.GPUHours,
+	}
+}
+
+// Equal returns true if the tow Assets match precisely
+func (gpu *GPU) Equal(a Asset) bool {
+	that, ok := a.(*GPU)
+	if !ok {
+		return false
+	}
+
+	if !gpu.Labels.Equal(that.Labels) {
+		return false
+	}
+	if !gpu.Properties.Equal(that.Properties) {
+		return false
+	}
+	if !gpu.Start.Equal(that.Start) {
+		return false
+	}
+	if !gpu.End.Equal(that.End) {
+		return false
+	}
+	if !gpu.Window.Equal(that.Window) {
+		return false
+	}
+	if gpu.Adjustment != that.Adjustment {
+		return false
+	}
+	if gpu.Cost != that.Cost {
+		return false
+	}
+	if gpu.GPUHours != that.GPUHours {
+		return false
+	}
+
+	return true
+}
+
+// GPUs returns the amount of GPUs attached to the node, averaged over the
+// window. See Node.GPUs.
+func (gpu *GPU) GPUs() float64 {
+	return gpu.GPUHours * (60.0 / gpu.Minutes())
+}
+
+// String implements fmt.Stringer
+func (gpu *GPU) String() string {
+	return toString(gpu)
+}
+
+// PublicIP is an Asset representing a single public IP address allocated to a
+// cluster, e.g. the external address of a node, which is billed by the hour
+// independently of the node.
+type PublicIP struct {
+	Properties *AssetProperties
+	Labels     AssetLabels
+	Start      time.Time
+	End        time.Time
+	Window     Window
+	Adjustment float64
+	Cost       float64
+}
+
+// NewPublicIP instantiates and returns a new PublicIP
+func NewPublicIP(name, cluster, providerID string, start, end time.Time, window Window) *PublicIP {
+	properties := &AssetProperties{
+		Category:   NetworkCategory,
+		Name:       name,
+		Cluster:    cluster,
+		ProviderID: providerID,
+		Service:    KubernetesService,
+	}
+
+	return &PublicIP{
+		Properties: properties,
+		Labels:     AssetLabels{},
+		Start:      start,
+		End:        end,
+		Window:     window,
+	}
+}
+
+// Type returns the AssetType of the Asset
+func (ip *PublicIP) Type() AssetType {
+	return PublicIPAssetType
+}
+
+// Properties returns the Asset's Properties
+func (ip *PublicIP) GetProperties() *AssetProperties {
+	return ip.Properties
+}
+
+// SetProperties sets the Asset's Properties
+func (ip *PublicIP) SetProperties(props *AssetProperties) {
+	ip.Properties = props
+}
+
+// Labels returns the Asset's labels
+func (ip *PublicIP) GetLabels() AssetLabels {
+	return ip.Labels
+}
+
+// SetLabels sets the Asset's labels
+func (ip *PublicIP) SetLabels(labels AssetLabels) {
+	ip.Labels = labels
+}
+
+// Adjustment returns the Asset's cost adjustment
+func (ip *PublicIP) GetAdjustment() float64 {
+	return ip.Adjustment
+}
+
+// SetAdjustment sets the Asset's cost adjustment
+func (ip *PublicIP) SetAdjustment(adj float64) {
+	ip.Adjustment = adj
+}
+
+// TotalCost returns the total cost of the Asset
+func (ip *PublicIP) TotalCost() float64 {
+	return ip.Cost + ip.Adjustment
+}
+
+// Start returns the preceise start point of the Asset within the window
+func (ip *PublicIP) GetStart() time.Time {
+	return ip.Start
+}
+
+// End returns the preceise end point of the Asset within the window
+func (ip *PublicIP) GetEnd() time.Time {
+	return ip.End
+}
+
+// Minutes returns the number of minutes the Asset ran within the window
+func (ip *PublicIP) Minutes() float64 {
+	return ip.End.Sub(ip.Start).Minutes()
+}
+
+// Window returns the window within which the Asset ran
+func (ip *PublicIP) GetWindow() Window {
+	return ip.Window
+}
+
+func (ip *PublicIP) SetWindow(window Window) {
+	ip.Window = window
+}
+
+// ExpandWindow expands the Asset's window by the given window
+func (ip *PublicIP) ExpandWindow(w Window) {
+	ip.Window = ip.Window.Expand(w)
+}
+
+// SetStartEnd sets the Asset's Start and End fields
+func (ip *PublicIP) SetStartEnd(start, end time.Time) {
+	if ip.Window.Contains(start) {
+		ip.Start = start
+	} else {
+		log.Warnf("PublicIP.SetStartEnd: start %s not in %s", start, ip.Window)
+	}
+
+	if ip.Window.Contains(end) {
+		ip.End = end
+	} else {
+		log.Warnf("PublicIP.SetStartEnd: end %s not in %s", end, ip.Window)
+	}
+}
+
+// Add sums the Asset with the given Asset to produce a new Asset, maintaining
+// as much relevant information as possible (i.e. type, Properties, labels).
+func (ip *PublicIP) Add(a Asset) Asset {
+	// PublicIP + PublicIP = PublicIP
+	if that, ok := a.(*PublicIP); ok {
+		this := ip.Clone().(*PublicIP)
+		this.add(that)
+		return this
+	}
+
+	props := ip.GetProperties().Merge(a.GetProperties())
+	labels := ip.Labels.Merge(a.GetLabels())
+
+	start := ip.Start
+	if a.GetStart().Before(start) {
+		start = a.GetStart()
+	}
+	end := ip.End
+	if a.GetEnd().After(end) {
+		end = a.GetEnd()
+	}
+	window := ip.Window.Expand(a.GetWindow())
+
+	// PublicIP + !PublicIP = Any
+	any := NewAsset(start, end, window)
+	any.SetProperties(props)
+	any.SetLabels(labels)
+	any.Adjustment = ip.Adjustment + a.GetAdjustment()
+	any.Cost = (ip.TotalCost() - ip.Adjustment) + (a.TotalCost() - a.GetAdjustment())
+
+	return any
+}
+
+func (ip *PublicIP) add(that *PublicIP) {
+	if ip == nil {
+		ip = that
+		return
+	}
+
+	props := ip.Properties.Merge(that.GetProperties())
+	labels := ip.Labels.Merge(that.GetLabels())
+	ip.SetProperties(props)
+	ip.SetLabels(labels)
+
+	start := ip.Start
+	if that.Start.Before(start) {
+		start = that.Start
+	}
+	end := ip.End
+	if that.End.After(end) {
+		end = that.End
+	}
+	window := ip.Window.Expand(that.Window)
+	ip.Start = start
+	ip.End = end
+	ip.Window = window
+
+	ip.Cost += that.Cost
+	ip.Adjustment += that.Adjustment
+}
+
+// Clone returns a cloned instance of the given Asset
+func (ip *PublicIP) Clone() Asset {
+	return &PublicIP{
+		Properties: ip.Properties.Clone(),
+		Labels:     ip.Labels.Clone(),
+		Start:      ip.Start,
+		End:        ip.End,
+		Window:     ip.Window.Clone(),
+		Adjustment: ip.Adjustment,
+		Cost:       ip.Cost,
+	}
+}
+
+// Equal returns true if the tow Assets match precisely
+func (ip *PublicIP) Equal(a Asset) bool {
+	that, ok := a.(*PublicIP)
+	if !ok {
+		return false
+	}
+
+	if !ip.Labels.Equal(that.Labels) {
+		return false
+	}
+	if !ip.Properties.Equal(that.Properties) {
+		return false
+	}
+	if !ip.Start.Equal(that.Start) {
+		return false
+	}
+	if !ip.End.Equal(that.End) {
+		return false
+	}
+	if !ip.Window.Equal(that.Window) {
+		return false
+	}
+	if ip.Adjustment != that.Adjustment {
+		return false
+	}
+	if ip.Cost != that.Cost {
+		return false
+	}
+
+	return true
+}
+
+// String implements fmt.Stringer
+func (ip *PublicIP) String() string {
+	return toString(ip)
+}
+
+// NATGateway is an Asset representing a single NAT gateway through which a
+// cluster reaches the internet. A NAT gateway is billed by the hour, as Cost,
+// and by the data it processes, as DataProcessingCost.
+type NATGateway struct {
+	Properties         *AssetProperties
+	Labels             AssetLabels
+	Start              time.Time
+	End                time.Time
+	Window             Window
+	Adjustment         float64
+	Cost               float64
+	DataProcessingCost float64
+	DataProcessedBytes float64
+}
+
+// NewNATGateway instantiates and returns a new NATGateway
+func NewNATGateway(name, cluster, providerID string, start, end time.Time, window Window) *NATGateway {
+	properties := &AssetProperties{
+		Category:   NetworkCategory,
+		Name:       name,
+		Cluster:    cluster,
+		ProviderID: providerID,
+		Service:    KubernetesService,
+	}
+
+	return &NATGateway{
+		Properties: properties,
+		Labels:     AssetLabels{},
+		Start:      start,
+		End:        end,
+		Window:     window,
+	}
+}
+
+// Type returns the AssetType of the Asset
+func (nat *NATGateway) Type() AssetType {
+	return NATGatewayAssetType
+}
+
+// Properties returns the Asset's Properties
+func (nat *NATGateway) GetProperties() *AssetProperties {
+	return nat.Properties
+}
+
+// SetProperties sets the Asset's Properties
+func (nat *NATGateway) SetProperties(props *AssetProperties) {
+	nat.Properties = props
+}
+
+// Labels returns the Asset's labels
+func (nat *NATGateway) GetLabels() AssetLabels {
+	return nat.Labels
+}
+
+// SetLabels sets the Asset's labels
+func (nat *NATGateway) SetLabels(labels AssetLabels) {
+	nat.Labels = labels
+}
+
+// Adjustment returns the Asset's cost adjustment
+func (nat *NATGateway) GetAdjustment() float64 {
+	return nat.Adjustment
+}
+
+// SetAdjustment sets the Asset's cost adjustment
+func (nat *NATGateway) SetAdjustment(adj float64) {
+	nat.Adjustment = adj
+}
+
+// TotalCost returns the total cost of the Asset
+func (nat *NATGateway) TotalCost() float64 {
+	return nat.Cost + nat.DataProcessingCost + nat.Adjustment
+}
+
+// Start returns the preceise start point of the Asset within the window
+func (nat *NATGateway) GetStart() time.Time {
+	return nat.Start
+}
+
+// End returns the preceise end point of the Asset within the window
+func (nat *NATGateway) GetEnd() time.Time {
+	return nat.End
+}
+
+// Minutes returns the number of minutes the Asset ran within the window
+func (nat *NATGateway) Minutes() float64 {
+	return nat.End.Sub(nat.Start).Minutes()
+}
+
+// Window returns the window within which the Asset ran
+func (nat *NATGateway) GetWindow() Window {
+	return nat.Window
+}
+
+func (nat *NATGateway) SetWindow(window Window) {
+	nat.Window = window
+}
+
+// ExpandWindow expands the Asset's window by the given window
+func (nat *NATGateway) ExpandWindow(w Window) {
+	nat.Window = nat.Window.Expand(w)
+}
+
+// SetStartEnd sets the Asset's Start and End fields
+func (nat *NATGateway) SetStartEnd(start, end time.Time) {
+	if nat.Window.Contains(start) {
+		nat.Start = start
+	} else {
+		log.Warnf("NATGateway.SetStartEnd: start %s not in %s", start, nat.Window)
+	}
+
+	if nat.Window.Contains(end) {
+		nat.End = end
+	} else {
+		log.Warnf("NATGateway.SetStartEnd: end %s not in %s", end, nat.Window)
+	}
+}
+
+// Add sums the Asset with the given Asset to produce a new Asset, maintaining
+// as much relevant information as possible (i.e. type, Properties, labels).
+func (nat *NATGateway) Add(a Asset) Asset {
+	// NATGateway + NATGateway = NATGateway
+	if that, ok := a.(*NATGateway); ok {
+		this := nat.Clone().(*NATGateway)
+		this.add(that)
+		return this
+	}
+
+	props := nat.GetProperties().Merge(a.GetProperties())
+	labels := nat.Labels.Merge(a.GetLabels())
+
+	start := nat.Start
+	if a.GetStart().Before(start) {
+		start = a.GetStart()
+	}
+	end := nat.End
+	if a.GetEnd().After(end) {
+		end = a.GetEnd()
+	}
+	window := nat.Window.Expand(a.GetWindow())
+
+	// NATGateway + !NATGateway = Any
+	any := NewAsset(start, end, window)
+	any.SetProperties(props)
+	any.SetLabels(labels)
+	any.Adjustment = nat.Adjustment + a.GetAdjustment()
+	any.Cost = (nat.TotalCost() - nat.Adjustment) + (a.TotalCost() - a.GetAdjustment())
+
+	return any
+}
+
+func (nat *NATGateway) add(that *NATGateway) {
+	if nat == nil {
+		nat = that
+		return
+	}
+
+	props := nat.Properties.Merge(that.GetProperties())
+	labels := nat.Labels.Merge(that.GetLabels())
+	nat.SetProperties(props)
+	nat.SetLabels(labels)
+
+	start := nat.Start
+	if that.Start.Before(start) {
+		start = that.Start
+	}
+	end := nat.End
+	if that.End.After(end) {
+		end = that.End
+	}
+	window := nat.Window.Expand(that.Window)
+	nat.Start = start
+	nat.End = end
+	nat.Window = window
+
+	nat.Cost += that.Cost
+	nat.Adjustment += that.Adjustment
+	nat.DataProcessingCost += that.DataProcessingCost
+	nat.DataProcessedBytes += that.DataProcessedBytes
+}
+
+// Clone returns a cloned instance of the given Asset
+func (nat *NATGateway) Clone() Asset {
+	return &NATGateway{
+		Properties:         nat.Properties.Clone(),
+		Labels:             nat.Labels.Clone(),
+		Start:              nat.Start,
+		End:                nat.End,
+		Window:             nat.Window.Clone(),
+		Adjustment:         nat.Adjustment,
+		Cost:               nat.Cost,
+		DataProcessingCost: nat.DataProcessingCost,
+		DataProcessedBytes: nat.DataProcessedBytes,
+	}
+}
+
+// Equal returns true if the tow Assets match precisely
+func (nat *NATGateway) Equal(a Asset) bool {
+	that, ok := a.(*NATGateway)
+	if !ok {
+		return false
+	}
+
+	if !nat.Labels.Equal(that.Labels) {
+		return false
+	}
+	if !nat.Properties.Equal(that.Properties) {
+		return false
+	}
+	if !nat.Start.Equal(that.Start) {
+		return false
+	}
+	if !nat.End.Equal(that.End) {
+		return false
+	}
+	if !nat.Window.Equal(that.Window) {
+		return false
+	}
+	if nat.Adjustment != that.Adjustment {
+		return false
+	}
+	if nat.Cost != that.Cost {
+		return false
+	}
+	if nat.DataProcessingCost != that.DataProcessingCost {
+		return false
+	}
+	if nat.DataProcessedBytes != that.DataProcessedBytes {
+		return false
+	}
+
+	return true
+}
+
+// String implements fmt.Stringer
+func (nat *NATGateway) String() string {
+	return toString(nat)
+}
+
 // SharedAsset is an Asset representing a shared cost
 type SharedAsset struct {
 	Properties *AssetProperties
@@ -2610,6 +3333,9 @@ type AssetSet struct {
 	Nodes             map[string]*Node              //@bingen:field[ignore]
 	LoadBalancers     map[string]*LoadBalancer      //@bingen:field[ignore]
 	SharedAssets      map[string]*SharedAsset       //@bingen:field[ignore]
+	GPUs              map[string]*GPU               //@bingen:field[ignore]
+	PublicIPs         map[string]*PublicIP          //@bingen:field[ignore]
+	NATGateways       map[string]*NATGateway        //@bingen:field[ignore]
 	FromSource        string                        // stores the name of the source used to compute the data
 	Window            Window
 	Warnings          []string
@@ -2619,7 +3345,8 @@ type AssetSet struct {
 // This methid is executed before marshalling the AssetSet binary.
 func preProcessAssetSet(assetSet *AssetSet) {
 	length := len(assetSet.Any) + len(assetSet.Cloud) + len(assetSet.ClusterManagement) + len(assetSet.Disks) +
-		len(assetSet.Network) + len(assetSet.Nodes) + len(assetSet.LoadBalancers) + len(assetSet.SharedAssets)
+		len(assetSet.Network) + len(assetSet.Nodes) + len(assetSet.LoadBalancers) + len(assetSet.SharedAssets) +
+		len(assetSet.GPUs) + len(assetSet.PublicIPs) + len(assetSet.NATGateways)
 
 	if length != len(assetSet.Assets) {
 		log.Warnf("AssetSet concrete Asset maps are out of sync with AssetSet.Assets map.")
@@ -2684,6 +3411,24 @@ func addToConcreteMap(assetSet *AssetSet, key string, as Asset) {
 			assetSet.SharedAssets = make(map[string]*SharedAsset)
 		}
 		assetSet.SharedAssets[key] = asset
+
+	case *GPU:
+		if assetSet.GPUs == nil {
+			assetSet.GPUs = make(map[string]*GPU)
+		}
+		assetSet.GPUs[key] = asset
+
+	case *PublicIP:
+		if assetSet.PublicIPs == nil {
+			assetSet.PublicIPs = make(map[string]*PublicIP)
+		}
+		assetSet.PublicIPs[key] = asset
+
+	case *NATGateway:
+		if assetSet.NATGateways == nil {
+			assetSet.NATGateways = make(map[string]*NATGateway)
+		}
+		assetSet.NATGateways[key] = asset
 	}
 }
 
@@ -2713,6 +3458,15 @@ func removeFromConcreteMap(assetSet *AssetSet, key string, as Asset) {
 
 	case *SharedAsset:
 		delete(assetSet.SharedAssets, key)
+
+	case *GPU:
+		delete(assetSet.GPUs, key)
+
+	case *PublicIP:
+		delete(assetSet.PublicIPs, key)
+
+	case *NATGateway:
+		delete(assetSet.NATGateways, key)
 	}
 }
 
@@ -2866,6 +3620,21 @@ func (as *AssetSet) Clone() *AssetSet {
 		sharedAssetsMap = make(map[string]*SharedAsset, len(as.SharedAssets))
 	}
 
+	var gpusMap map[string]*GPU
+	if as.GPUs != nil {
+		gpusMap = make(map[string]*GPU, len(as.GPUs))
+	}
+
+	var publicIPsMap map[string]*PublicIP
+	if as.PublicIPs != nil {
+		publicIPsMap = make(map[string]*PublicIP, len(as.PublicIPs))
+	}
+
+	var natGatewaysMap map[string]*NATGateway
+	if as.NATGateways != nil {
+		natGatewaysMap = make(map[string]*NATGateway, len(as.NATGateways))
+	}
+
 	assetSet := &AssetSet{
 		Window:            NewWindow(&s, &e),
 		AggregationKeys:   aggregateBy,
@@ -2878,6 +3647,9 @@ func (as *AssetSet) Clone() *AssetSet {
 		Nodes:             nodesMap,
 		LoadBalancers:     loadBalancersMap,
 		SharedAssets:      sharedAssetsMap,
+		GPUs:              gpusMap,
+		PublicIPs:         publicIPsMap,
+		NATGateways:       natGatewaysMap,
 		Errors:            errors,
 		Warnings:          warnings,
 	}
@@ -680,6 +680,261 @@ func (lb *LoadBalancer) InterfaceToLoadBalancer(itf interface{}) error {
 
 }
 
+// GPU marshal and unmarshal
+
+// MarshalJSON implements json.Marshal
+func (gpu *GPU) MarshalJSON() ([]byte, error) {
+	buffer := bytes.NewBufferString("{")
+	jsonEncodeString(buffer, "type", gpu.Type().String(), ",")
+	jsonEncode(buffer, "properties", gpu.Properties, ",")
+	jsonEncode(buffer, "labels", gpu.Labels, ",")
+	jsonEncode(buffer, "window", gpu.Window, ",")
+	jsonEncodeString(buffer, "start", gpu.Start.Format(time.RFC3339), ",")
+	jsonEncodeString(buffer, "end", gpu.End.Format(time.RFC3339), ",")
+	jsonEncodeFloat64(buffer, "minutes", gpu.Minutes(), ",")
+	jsonEncodeFloat64(buffer, "GPUHours", gpu.GPUHours, ",")
+	jsonEncodeFloat64(buffer, "gpuCount", gpu.GPUs(), ",")
+	jsonEncodeFloat64(buffer, "adjustment", gpu.Adjustment, ",")
+	jsonEncodeFloat64(buffer, "totalCost", gpu.TotalCost(), "")
+	buffer.WriteString("}")
+	return buffer.Bytes(), nil
+}
+
+func (gpu *GPU) UnmarshalJSON(b []byte) error {
+
+	var f interface{}
+
+	err := json.Unmarshal(b, &f)
+	if err != nil {
+		return err
+	}
+
+	err = gpu.InterfaceToGPU(f)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Converts interface{} to GPU, carrying over relevant fields
+func (gpu *GPU) InterfaceToGPU(itf interface{}) error {
+
+	fmap := itf.(map[string]interface{})
+
+	// parse properties map to AssetProperties
+	fproperties := fmap["properties"].(map[string]interface{})
+	properties := toAssetProp(fproperties)
+
+	// parse labels map to AssetLabels
+	labels := make(map[string]string)
+	for k, v := range fmap["labels"].(map[string]interface{}) {
+		labels[k] = v.(string)
+	}
+
+	// parse start and end strings to time.Time
+	start, err := time.Parse(time.RFC3339, fmap["start"].(string))
+	if err != nil {
+		return err
+	}
+	end, err := time.Parse(time.RFC3339, fmap["end"].(string))
+	if err != nil {
+		return err
+	}
+
+	gpu.Properties = &properties
+	gpu.Labels = labels
+	gpu.Start = start
+	gpu.End = end
+	gpu.Window = Window{
+		start: &start,
+		end:   &end,
+	}
+
+	if adjustment, err := getTypedVal(fmap["adjustment"]); err == nil {
+		gpu.Adjustment = adjustment.(float64)
+	}
+	if Cost, err := getTypedVal(fmap["totalCost"]); err == nil {
+		gpu.Cost = Cost.(float64) - gpu.Adjustment
+	}
+
+	if GPUHours, err := getTypedVal(fmap["GPUHours"]); err == nil {
+		gpu.GPUHours = GPUHours.(float64)
+	}
+
+	return nil
+
+}
+
+// PublicIP marshal and unmarshal
+
+// MarshalJSON implements json.Marshal
+func (ip *PublicIP) MarshalJSON() ([]byte, error) {
+	buffer := bytes.NewBufferString("{")
+	jsonEncodeString(buffer, "type", ip.Type().String(), ",")
+	jsonEncode(buffer, "properties", ip.Properties, ",")
+	jsonEncode(buffer, "labels", ip.Labels, ",")
+	jsonEncode(buffer, "window", ip.Window, ",")
+	jsonEncodeString(buffer, "start", ip.Start.Format(time.RFC3339), ",")
+	jsonEncodeString(buffer, "end", ip.End.Format(time.RFC3339), ",")
+	jsonEncodeFloat64(buffer, "minutes", ip.Minutes(), ",")
+	jsonEncodeFloat64(buffer, "adjustment", ip.Adjustment, ",")
+	jsonEncodeFloat64(buffer, "totalCost", ip.TotalCost(), "")
+	buffer.WriteString("}")
+	return buffer.Bytes(), nil
+}
+
+func (ip *PublicIP) UnmarshalJSON(b []byte) error {
+
+	var f interface{}
+
+	err := json.Unmarshal(b, &f)
+	if err != nil {
+		return err
+	}
+
+	err = ip.InterfaceToPublicIP(f)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Converts interface{} to PublicIP, carrying over relevant fields
+func (ip *PublicIP) InterfaceToPublicIP(itf interface{}) error {
+
+	fmap := itf.(map[string]interface{})
+
+	// parse properties map to AssetProperties
+	fproperties := fmap["properties"].(map[string]interface{})
+	properties := toAssetProp(fproperties)
+
+	// parse labels map to AssetLabels
+	labels := make(map[string]string)
+	for k, v := range fmap["labels"].(map[string]interface{}) {
+		labels[k] = v.(string)
+	}
+
+	// parse start and end strings to time.Time
+	start, err := time.Parse(time.RFC3339, fmap["start"].(string))
+	if err != nil {
+		return err
+	}
+	end, err := time.Parse(time.RFC3339, fmap["end"].(string))
+	if err != nil {
+		return err
+	}
+
+	ip.Properties = &properties
+	ip.Labels = labels
+	ip.Start = start
+	ip.End = end
+	ip.Window = Window{
+		start: &start,
+		end:   &end,
+	}
+
+	if adjustment, err := getTypedVal(fmap["adjustment"]); err == nil {
+		ip.Adjustment = adjustment.(float64)
+	}
+	if Cost, err := getTypedVal(fmap["totalCost"]); err == nil {
+		ip.Cost = Cost.(float64) - ip.Adjustment
+	}
+
+	return nil
+
+}
+
+// NATGateway marshal and unmarshal
+
+// MarshalJSON implements json.Marshal
+func (nat *NATGateway) MarshalJSON() ([]byte, error) {
+	buffer := bytes.NewBufferString("{")
+	jsonEncodeString(buffer, "type", nat.Type().String(), ",")
+	jsonEncode(buffer, "properties", nat.Properties, ",")
+	jsonEncode(buffer, "labels", nat.Labels, ",")
+	jsonEncode(buffer, "window", nat.Window, ",")
+	jsonEncodeString(buffer, "start", nat.Start.Format(time.RFC3339), ",")
+	jsonEncodeString(buffer, "end", nat.End.Format(time.RFC3339), ",")
+	jsonEncodeFloat64(buffer, "minutes", nat.Minutes(), ",")
+	jsonEncodeFloat64(buffer, "cost", nat.Cost, ",")
+	jsonEncodeFloat64(buffer, "dataProcessingCost", nat.DataProcessingCost, ",")
+	jsonEncodeFloat64(buffer, "dataProcessedBytes", nat.DataProcessedBytes, ",")
+	jsonEncodeFloat64(buffer, "adjustment", nat.Adjustment, ",")
+	jsonEncodeFloat64(buffer, "totalCost", nat.TotalCost(), "")
+	buffer.WriteString("}")
+	return buffer.Bytes(), nil
+}
+
+func (nat *NATGateway) UnmarshalJSON(b []byte) error {
+
+	var f interface{}
+
+	err := json.Unmarshal(b, &f)
+	if err != nil {
+		return err
+	}
+
+	err = nat.InterfaceToNATGateway(f)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Converts interface{} to NATGateway, carrying over relevant fields
+func (nat *NATGateway) InterfaceToNATGateway(itf interface{}) error {
+
+	fmap := itf.(map[string]interface{})
+
+	// parse properties map to AssetProperties
+	fproperties := fmap["properties"].(map[string]interface{})
+	properties := toAssetProp(fproperties)
+
+	// parse labels map to AssetLabels
+	labels := make(map[string]string)
+	for k, v := range fmap["labels"].(map[string]interface{}) {
+		labels[k] = v.(string)
+	}
+
+	// parse start and end strings to time.Time
+	start, err := time.Parse(time.RFC3339, fmap["start"].(string))
+	if err != nil {
+		return err
+	}
+	end, err := time.Parse(time.RFC3339, fmap["end"].(string))
+	if err != nil {
+		return err
+	}
+
+	nat.Properties = &properties
+	nat.Labels = labels
+	nat.Start = start
+	nat.End = end
+	nat.Window = Window{
+		start: &start,
+		end:   &end,
+	}
+
+	if adjustment, err := getTypedVal(fmap["adjustment"]); err == nil {
+		nat.Adjustment = adjustment.(float64)
+	}
+	if Cost, err := getTypedVal(fmap["cost"]); err == nil {
+		nat.Cost = Cost.(float64)
+	}
+	if DataProcessingCost, err := getTypedVal(fmap["dataProcessingCost"]); err == nil {
+		nat.DataProcessingCost = DataProcessingCost.(float64)
+	}
+	if DataProcessedBytes, err := getTypedVal(fmap["dataProcessedBytes"]); err == nil {
+		nat.DataProcessedBytes = DataProcessedBytes.(float64)
+	}
+
+	return nil
+
+}
+
 // SharedAsset marshal and unmarshal
 
 // MarshalJSON implements json.Marshaler
@@ -868,6 +1123,39 @@ func (asr *AssetSetResponse) RawMessageToAssetSetResponse(assetMap map[string]*j
 
 			newAssetMap[key] = &lb
 
+		case "GPU":
+
+			var gpu GPU
+			err := gpu.InterfaceToGPU(f)
+
+			if err != nil {
+				return err
+			}
+
+			newAssetMap[key] = &gpu
+
+		case "PublicIP":
+
+			var ip PublicIP
+			err := ip.InterfaceToPublicIP(f)
+
+			if err != nil {
+				return err
+			}
+
+			newAssetMap[key] = &ip
+
+		case "NATGateway":
+
+			var nat NATGateway
+			err := nat.InterfaceToNATGateway(f)
+
+			if err != nil {
+				return err
+			}
+
+			newAssetMap[key] = &nat
+
 		case "Shared":
 
 			var sa SharedAsset
@@ -465,6 +465,37 @@ func TestLoadBalancer_Unmarshal(t *testing.T) {
 
 }
 
+func TestNATGateway_Unmarshal(t *testing.T) {
+
+	ng1 := NewNATGateway("nat-1", "cluster1", "nat-1", *unmarshalWindow.start, *unmarshalWindow.end, unmarshalWindow)
+	ng1.Cost = 1.08
+	ng1.DataProcessingCost = 4.5
+	ng1.DataProcessedBytes = 100 * 1024 * 1024 * 1024
+	ng1.SetAdjustment(0.5)
+
+	bytes, _ := json.Marshal(ng1)
+
+	var testng NATGateway
+	ng2 := &testng
+
+	err := json.Unmarshal(bytes, ng2)
+
+	// Check if unmarshal was successful
+	if err != nil {
+		t.Fatalf("NATGateway Unmarshal: unexpected error: %s", err)
+	}
+
+	if ng1.Cost != ng2.Cost || ng1.DataProcessingCost != ng2.DataProcessingCost || ng1.DataProcessedBytes != ng2.DataProcessedBytes {
+		t.Fatalf("NATGateway Unmarshal: costs mutated in unmarshal")
+	}
+
+	// As a final check, make sure the above checks out
+	if !ng1.Equal(ng2) {
+		t.Fatalf("NATGateway Unmarshal: NATGateway mutated in unmarshal")
+	}
+
+}
+
 func TestSharedAsset_Unmarshal(t *testing.T) {
 
 	sa1 := NewSharedAsset("sharedasset1", unmarshalWindow)
@@ -26,7 +26,7 @@ package kubecost
 // @bingen:generate:CoverageSet
 
 // Asset Version Set: Includes Asset pipeline specific resources
-// @bingen:set[name=Assets,version=19]
+// @bingen:set[name=Assets,version=20]
 // @bingen:generate:Any
 // @bingen:generate:Asset
 // @bingen:generate:AssetLabels
@@ -38,15 +38,18 @@ package kubecost
 // @bingen:generate:Cloud
 // @bingen:generate:ClusterManagement
 // @bingen:generate:Disk
+// @bingen:generate:GPU
 // @bingen:generate:LoadBalancer
+// @bingen:generate:NATGateway
 // @bingen:generate:Network
 // @bingen:generate:Node
 // @bingen:generate:NodeOverhead
+// @bingen:generate:PublicIP
 // @bingen:generate:SharedAsset
 // @bingen:end
 
 // Allocation Version Set: Includes Allocation pipeline specific resources
-// @bingen:set[name=Allocation,version=16]
+// @bingen:set[name=Allocation,version=17]
 // @bingen:generate:Allocation
 // @bingen:generate[stringtable]:AllocationSet
 // @bingen:generate:AllocationSetRange
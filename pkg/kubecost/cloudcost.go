@@ -204,7 +204,7 @@ func (ccs *CloudCostSet) Accumulate(that *CloudCostSet) (*CloudCostSet, error) {
 	}
 	acc := ccs.Clone()
 	err := acc.accumulateInto(that)
-	if err == nil {
+	if err != nil {
 		return nil, err
 	}
 	return acc, nil
@@ -224,7 +224,7 @@ func (ccs *CloudCostSet) accumulateInto(that *CloudCostSet) error {
 		ccs.Integration = ""
 	}
 
-	ccs.Window.Expand(that.Window)
+	ccs.Window = ccs.Window.Expand(that.Window)
 
 	for _, cc := range that.CloudCosts {
 		err := ccs.Insert(cc)
@@ -287,6 +287,34 @@ func (ccs *CloudCostSet) Filter(filters filter.Filter[*CloudCost]) *CloudCostSet
 	return result
 }
 
+// NonKubernetes returns a set of the portion of each CloudCost which is not
+// Kubernetes spend, such as managed databases and buckets, omitting CloudCosts
+// which are entirely Kubernetes.
+func (ccs *CloudCostSet) NonKubernetes() *CloudCostSet {
+	if ccs == nil {
+		return nil
+	}
+
+	result := ccs.cloneSet()
+
+	for _, cc := range ccs.CloudCosts {
+		ncc := cc.Clone()
+		ncc.ListCost = cc.ListCost.nonKubernetes()
+		ncc.NetCost = cc.NetCost.nonKubernetes()
+		ncc.AmortizedNetCost = cc.AmortizedNetCost.nonKubernetes()
+		ncc.InvoicedCost = cc.InvoicedCost.nonKubernetes()
+		ncc.AmortizedCost = cc.AmortizedCost.nonKubernetes()
+
+		if ncc.ListCost.Cost == 0 && ncc.NetCost.Cost == 0 && ncc.AmortizedNetCost.Cost == 0 &&
+			ncc.InvoicedCost.Cost == 0 && ncc.AmortizedCost.Cost == 0 {
+			continue
+		}
+		result.Insert(ncc)
+	}
+
+	return result
+}
+
 // Insert adds a CloudCost to a CloudCostSet using its AggregationProperties and LabelConfig
 // to determine the key where it will be inserted
 func (ccs *CloudCostSet) Insert(cc *CloudCost) error {
@@ -553,6 +581,14 @@ func (cm CostMetric) add(that CostMetric) CostMetric {
 	}
 }
 
+// nonKubernetes returns the portion of the cost which is not Kubernetes
+func (cm CostMetric) nonKubernetes() CostMetric {
+	return CostMetric{
+		Cost:              cm.Cost * (1.0 - cm.KubernetesPercent),
+		KubernetesPercent: 0.0,
+	}
+}
+
 // percent returns the product of the given percent and the cost, KubernetesPercent remains the same
 func (cm CostMetric) percent(pct float64) CostMetric {
 	return CostMetric{
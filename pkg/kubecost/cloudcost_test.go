@@ -280,3 +280,53 @@ func TestCloudCost_LoadCloudCost(t *testing.T) {
 	}
 
 }
+
+func TestCloudCostSet_NonKubernetes(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(timeutil.Day)
+
+	database := &CloudCostProperties{ProviderID: "db-1", Provider: "AWS", Service: "AmazonRDS", Labels: CloudCostLabels{"team": "payments"}}
+	node := &CloudCostProperties{ProviderID: "i-1", Provider: "AWS", Service: "AmazonEC2"}
+	shared := &CloudCostProperties{ProviderID: "nat-1", Provider: "AWS", Service: "AmazonVPC"}
+
+	ccs := NewCloudCostSet(start, end,
+		NewCloudCost(start, end, database, 0.0, 10, 10, 10, 10, 10),
+		NewCloudCost(start, end, node, 1.0, 20, 20, 20, 20, 20),
+		NewCloudCost(start, end, shared, 0.25, 4, 4, 4, 4, 4),
+	)
+
+	result := ccs.NonKubernetes()
+	if result.Length() != 2 {
+		t.Fatalf("expected 2 non-Kubernetes cloud costs; got %d", result.Length())
+	}
+	if cc := result.CloudCosts[database.GenerateKey(nil)]; cc == nil || cc.NetCost.Cost != 10 {
+		t.Errorf("expected database net cost 10; got %v", cc)
+	}
+	if cc := result.CloudCosts[shared.GenerateKey(nil)]; cc == nil || cc.NetCost.Cost != 3 || cc.NetCost.KubernetesPercent != 0 {
+		t.Errorf("expected shared net cost 3 and no Kubernetes percent; got %v", cc)
+	}
+}
+
+func TestCloudCostSetRange_Accumulate(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(2 * timeutil.Day)
+
+	ccsr, err := NewCloudCostSetRange(start, end, timeutil.Day, "integration")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for _, ccs := range ccsr.CloudCostSets {
+		ccs.Insert(NewCloudCost(*ccs.Window.Start(), *ccs.Window.End(), ccProperties1, 0.0, 1, 1, 1, 1, 1))
+	}
+
+	acc, err := ccsr.Accumulate()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !acc.Window.Equal(ccsr.Window) {
+		t.Errorf("expected accumulated window %s; got %s", ccsr.Window, acc.Window)
+	}
+	if cc := acc.CloudCosts[ccProperties1.GenerateKey(nil)]; cc == nil || cc.NetCost.Cost != 2 {
+		t.Errorf("expected accumulated net cost 2; got %v", cc)
+	}
+}
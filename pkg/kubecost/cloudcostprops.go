@@ -37,6 +37,35 @@ const (
 	CloudCostOtherCategory string = "Other"
 )
 
+// ParseCloudCostProperty attempts to parse a string into a CloudCost
+// aggregation property, which may be a label, given as "label:<name>".
+func ParseCloudCostProperty(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "label:") {
+		if strings.TrimPrefix(text, "label:") == "" {
+			return "", fmt.Errorf("invalid cloud cost property: %s: missing label name", text)
+		}
+		return text, nil
+	}
+
+	switch strings.ToLower(text) {
+	case "invoiceentityid":
+		return CloudCostInvoiceEntityIDProp, nil
+	case "accountid":
+		return CloudCostAccountIDProp, nil
+	case "provider":
+		return CloudCostProviderProp, nil
+	case "providerid":
+		return CloudCostProviderIDProp, nil
+	case "category":
+		return CloudCostCategoryProp, nil
+	case "service":
+		return CloudCostServiceProp, nil
+	}
+
+	return "", fmt.Errorf("invalid cloud cost property: %s", text)
+}
+
 type CloudCostLabels map[string]string
 
 func (ccl CloudCostLabels) Clone() CloudCostLabels {
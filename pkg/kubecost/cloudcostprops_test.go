@@ -163,3 +163,35 @@ func TestCloudCostPropertiesIntersection(t *testing.T) {
 		})
 	}
 }
+
+func TestParseCloudCostProperty(t *testing.T) {
+	testCases := map[string]struct {
+		text     string
+		expected string
+		err      bool
+	}{
+		"service":          {text: "service", expected: CloudCostServiceProp},
+		"case insensitive": {text: "AccountID", expected: CloudCostAccountIDProp},
+		"label":            {text: "label:Team", expected: "label:Team"},
+		"empty label":      {text: "label:", err: true},
+		"unknown":          {text: "cluster", err: true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prop, err := ParseCloudCostProperty(tc.text)
+			if tc.err {
+				if err == nil {
+					t.Errorf("expected error parsing %s", tc.text)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if prop != tc.expected {
+				t.Errorf("expected %s; got %s", tc.expected, prop)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package kubecost
 import (
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/opencost/opencost/pkg/prom"
 	"github.com/opencost/opencost/pkg/util/cloudutil"
@@ -56,6 +57,39 @@ func NewLabelConfig() *LabelConfig {
 	}
 }
 
+var (
+	defaultLabelConfigLock sync.RWMutex
+	defaultLabelConfig     *LabelConfig
+)
+
+// DefaultLabelConfig returns the LabelConfig used when none is provided to an
+// aggregation: the one set by SetDefaultLabelConfig, or else the defaults of
+// NewLabelConfig.
+func DefaultLabelConfig() *LabelConfig {
+	defaultLabelConfigLock.RLock()
+	defer defaultLabelConfigLock.RUnlock()
+
+	if defaultLabelConfig == nil {
+		return NewLabelConfig()
+	}
+	lc := *defaultLabelConfig
+	return &lc
+}
+
+// SetDefaultLabelConfig sets the LabelConfig returned by DefaultLabelConfig. A
+// nil LabelConfig restores the defaults of NewLabelConfig.
+func SetDefaultLabelConfig(lc *LabelConfig) {
+	defaultLabelConfigLock.Lock()
+	defer defaultLabelConfigLock.Unlock()
+
+	if lc == nil {
+		defaultLabelConfig = nil
+		return
+	}
+	clone := *lc
+	defaultLabelConfig = &clone
+}
+
 // Map returns the config as a basic string map, with default values if not set
 func (lc *LabelConfig) Map() map[string]string {
 	// Start with default values
@@ -197,7 +231,7 @@ func (lc *LabelConfig) GetExternalAllocationName(labels map[string]string, aggre
 	} else {
 		// If lc is nil, use a default LabelConfig to do a best-effort match
 		if lc == nil {
-			lc = NewLabelConfig()
+			lc = DefaultLabelConfig()
 		}
 
 		switch strings.ToLower(aggregateBy) {
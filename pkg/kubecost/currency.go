@@ -0,0 +1,224 @@
+package kubecost
+
+// ConvertCurrency multiplies every monetary value of the Allocation by the
+// exchange rate, converting it to another currency in place.
+func (a *Allocation) ConvertCurrency(rate float64) {
+	if a == nil {
+		return
+	}
+
+	a.CPUCost *= rate
+	a.CPUCostAdjustment *= rate
+	a.GPUCost *= rate
+	a.GPUCostAdjustment *= rate
+	a.NetworkCost *= rate
+	a.NetworkCrossZoneCost *= rate
+	a.NetworkCrossRegionCost *= rate
+	a.NetworkInternetCost *= rate
+	a.NetworkCostAdjustment *= rate
+	a.LoadBalancerCost *= rate
+	a.LoadBalancerCostAdjustment *= rate
+	a.PVCostAdjustment *= rate
+	a.RAMCost *= rate
+	a.RAMCostAdjustment *= rate
+	a.SharedCost *= rate
+	a.ExternalCost *= rate
+
+	for _, pv := range a.PVs {
+		if pv != nil {
+			pv.Cost *= rate
+		}
+	}
+
+	for key, parc := range a.ProportionalAssetResourceCosts {
+		parc.CPUTotalCost *= rate
+		parc.CPUProportionalCost *= rate
+		parc.GPUTotalCost *= rate
+		parc.GPUProportionalCost *= rate
+		parc.RAMTotalCost *= rate
+		parc.RAMProportionalCost *= rate
+		a.ProportionalAssetResourceCosts[key] = parc
+	}
+
+	for key, scb := range a.SharedCostBreakdown {
+		scb.TotalCost *= rate
+		scb.CPUCost *= rate
+		scb.GPUCost *= rate
+		scb.RAMCost *= rate
+		scb.PVCost *= rate
+		scb.NetworkCost *= rate
+		scb.LBCost *= rate
+		scb.ExternalCost *= rate
+		a.SharedCostBreakdown[key] = scb
+	}
+}
+
+// ConvertCurrency converts every Allocation in the set to another currency in
+// place, by the exchange rate.
+func (as *AllocationSet) ConvertCurrency(rate float64) {
+	if as == nil {
+		return
+	}
+
+	for _, a := range as.Allocations {
+		a.ConvertCurrency(rate)
+	}
+}
+
+// ConvertCurrency converts every AllocationSet in the range to another
+// currency in place, by the exchange rate.
+func (asr *AllocationSetRange) ConvertCurrency(rate float64) {
+	if asr == nil {
+		return
+	}
+
+	for _, as := range asr.Allocations {
+		as.ConvertCurrency(rate)
+	}
+}
+
+// ConvertCurrency multiplies every monetary value of the SummaryAllocation by
+// the exchange rate, converting it to another currency in place.
+func (sa *SummaryAllocation) ConvertCurrency(rate float64) {
+	if sa == nil {
+		return
+	}
+
+	sa.CPUCost *= rate
+	sa.GPUCost *= rate
+	sa.NetworkCost *= rate
+	sa.LoadBalancerCost *= rate
+	sa.PVCost *= rate
+	sa.RAMCost *= rate
+	sa.SharedCost *= rate
+	sa.ExternalCost *= rate
+}
+
+// ConvertCurrency converts every SummaryAllocation in the set to another
+// currency in place, by the exchange rate.
+func (sas *SummaryAllocationSet) ConvertCurrency(rate float64) {
+	if sas == nil {
+		return
+	}
+
+	sas.Lock()
+	defer sas.Unlock()
+
+	for _, sa := range sas.SummaryAllocations {
+		sa.ConvertCurrency(rate)
+	}
+}
+
+// ConvertCurrency converts every SummaryAllocationSet in the range to another
+// currency in place, by the exchange rate.
+func (sasr *SummaryAllocationSetRange) ConvertCurrency(rate float64) {
+	if sasr == nil {
+		return
+	}
+
+	sasr.Lock()
+	defer sasr.Unlock()
+
+	for _, sas := range sasr.SummaryAllocationSets {
+		sas.ConvertCurrency(rate)
+	}
+}
+
+// ConvertAssetCurrency multiplies every monetary value of the Asset by the
+// exchange rate, converting it to another currency in place.
+func ConvertAssetCurrency(asset Asset, rate float64) {
+	switch a := asset.(type) {
+	case *Any:
+		a.Cost *= rate
+	case *Cloud:
+		a.Cost *= rate
+		a.Credit *= rate
+	case *ClusterManagement:
+		a.Cost *= rate
+	case *Disk:
+		a.Cost *= rate
+	case *Network:
+		a.Cost *= rate
+	case *Node:
+		a.CPUCost *= rate
+		a.GPUCost *= rate
+		a.RAMCost *= rate
+	case *LoadBalancer:
+		a.Cost *= rate
+	case *SharedAsset:
+		a.Cost *= rate
+	case *GPU:
+		a.Cost *= rate
+	case *PublicIP:
+		a.Cost *= rate
+	case *NATGateway:
+		a.Cost *= rate
+		a.DataProcessingCost *= rate
+	case nil:
+		return
+	}
+
+	asset.SetAdjustment(asset.GetAdjustment() * rate)
+}
+
+// ConvertCurrency converts every Asset in the set to another currency in
+// place, by the exchange rate.
+func (as *AssetSet) ConvertCurrency(rate float64) {
+	if as == nil {
+		return
+	}
+
+	for _, a := range as.Assets {
+		ConvertAssetCurrency(a, rate)
+	}
+}
+
+// ConvertCurrency converts every AssetSet in the range to another currency in
+// place, by the exchange rate.
+func (asr *AssetSetRange) ConvertCurrency(rate float64) {
+	if asr == nil {
+		return
+	}
+
+	for _, as := range asr.Assets {
+		as.ConvertCurrency(rate)
+	}
+}
+
+// ConvertCurrency multiplies every cost of the CloudCost by the exchange rate,
+// converting it to another currency in place.
+func (cc *CloudCost) ConvertCurrency(rate float64) {
+	if cc == nil {
+		return
+	}
+
+	cc.ListCost.Cost *= rate
+	cc.NetCost.Cost *= rate
+	cc.AmortizedNetCost.Cost *= rate
+	cc.InvoicedCost.Cost *= rate
+	cc.AmortizedCost.Cost *= rate
+}
+
+// ConvertCurrency converts every CloudCost in the set to another currency in
+// place, by the exchange rate.
+func (ccs *CloudCostSet) ConvertCurrency(rate float64) {
+	if ccs == nil {
+		return
+	}
+
+	for _, cc := range ccs.CloudCosts {
+		cc.ConvertCurrency(rate)
+	}
+}
+
+// ConvertCurrency converts every CloudCostSet in the range to another currency
+// in place, by the exchange rate.
+func (ccsr *CloudCostSetRange) ConvertCurrency(rate float64) {
+	if ccsr == nil {
+		return
+	}
+
+	for _, ccs := range ccsr.CloudCostSets {
+		ccs.ConvertCurrency(rate)
+	}
+}
@@ -0,0 +1,65 @@
+package kubecost
+
+import (
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/pkg/util"
+)
+
+func TestAllocation_ConvertCurrency(t *testing.T) {
+	a := &Allocation{
+		CPUCost:           10,
+		CPUCostAdjustment: -1,
+		RAMCost:           4,
+		PVs: PVAllocations{
+			{Cluster: "cluster1", Name: "pv1"}: {ByteHours: 100, Cost: 2},
+		},
+		SharedCost:   1,
+		ExternalCost: 3,
+		SharedCostBreakdown: SharedCostBreakdowns{
+			"shared": {Name: "shared", TotalCost: 1, CPUCost: 1},
+		},
+	}
+	total := a.TotalCost()
+
+	a.ConvertCurrency(0.5)
+
+	if !util.IsApproximately(a.TotalCost(), total*0.5) {
+		t.Errorf("expected total cost %f; got %f", total*0.5, a.TotalCost())
+	}
+	if a.CPUCostAdjustment != -0.5 || a.PVCost() != 1 {
+		t.Errorf("expected adjustments and PV costs to be converted")
+	}
+	if a.PVs[PVKey{Cluster: "cluster1", Name: "pv1"}].ByteHours != 100 {
+		t.Errorf("expected resource usage not to be converted")
+	}
+	if a.SharedCostBreakdown["shared"].TotalCost != 0.5 {
+		t.Errorf("expected shared cost breakdown to be converted")
+	}
+}
+
+func TestAssetSet_ConvertCurrency(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+
+	node := NewNode("node1", "cluster1", "node1", start, end, NewWindow(&start, &end))
+	node.CPUCost = 10
+	node.RAMCost = 5
+	node.Adjustment = -1
+
+	disk := NewDisk("disk1", "cluster1", "disk1", start, end, NewWindow(&start, &end))
+	disk.Cost = 2
+
+	as := NewAssetSet(start, end, node, disk)
+	total := as.TotalCost()
+
+	as.ConvertCurrency(2)
+
+	if !util.IsApproximately(as.TotalCost(), total*2) {
+		t.Errorf("expected total cost %f; got %f", total*2, as.TotalCost())
+	}
+	if node.Adjustment != -2 {
+		t.Errorf("expected adjustment -2; got %f", node.Adjustment)
+	}
+}
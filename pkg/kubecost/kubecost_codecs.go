@@ -37,10 +37,10 @@ const (
 	DefaultCodecVersion uint8 = 17
 
 	// AssetsCodecVersion is used for any resources listed in the Assets version set
-	AssetsCodecVersion uint8 = 19
+	AssetsCodecVersion uint8 = 20
 
 	// AllocationCodecVersion is used for any resources listed in the Allocation version set
-	AllocationCodecVersion uint8 = 16
+	AllocationCodecVersion uint8 = 17
 
 	// AuditCodecVersion is used for any resources listed in the Audit version set
 	AuditCodecVersion uint8 = 1
@@ -83,12 +83,15 @@ var typeMap map[string]reflect.Type = map[string]reflect.Type{
 	"CoverageSet":                   reflect.TypeOf((*CoverageSet)(nil)).Elem(),
 	"Disk":                          reflect.TypeOf((*Disk)(nil)).Elem(),
 	"EqualityAudit":                 reflect.TypeOf((*EqualityAudit)(nil)).Elem(),
+	"GPU":                           reflect.TypeOf((*GPU)(nil)).Elem(),
 	"LoadBalancer":                  reflect.TypeOf((*LoadBalancer)(nil)).Elem(),
+	"NATGateway":                    reflect.TypeOf((*NATGateway)(nil)).Elem(),
 	"Network":                       reflect.TypeOf((*Network)(nil)).Elem(),
 	"Node":                          reflect.TypeOf((*Node)(nil)).Elem(),
 	"NodeOverhead":                  reflect.TypeOf((*NodeOverhead)(nil)).Elem(),
 	"PVAllocation":                  reflect.TypeOf((*PVAllocation)(nil)).Elem(),
 	"PVKey":                         reflect.TypeOf((*PVKey)(nil)).Elem(),
+	"PublicIP":                      reflect.TypeOf((*PublicIP)(nil)).Elem(),
 	"RawAllocationOnlyData":         reflect.TypeOf((*RawAllocationOnlyData)(nil)).Elem(),
 	"SharedAsset":                   reflect.TypeOf((*SharedAsset)(nil)).Elem(),
 	"TotalAudit":                    reflect.TypeOf((*TotalAudit)(nil)).Elem(),
@@ -7059,12 +7062,12 @@ func (target *EqualityAudit) UnmarshalBinaryWithContext(ctx *DecodingContext) (e
 }
 
 //--------------------------------------------------------------------------
-//  LoadBalancer
+//  GPU
 //--------------------------------------------------------------------------
 
-// MarshalBinary serializes the internal properties of this LoadBalancer instance
+// MarshalBinary serializes the internal properties of this GPU instance
 // into a byte array
-func (target *LoadBalancer) MarshalBinary() (data []byte, err error) {
+func (target *GPU) MarshalBinary() (data []byte, err error) {
 	ctx := &EncodingContext{
 		Buffer: util.NewBuffer(),
 		Table:  nil,
@@ -7079,9 +7082,9 @@ func (target *LoadBalancer) MarshalBinary() (data []byte, err error) {
 	return encBytes, nil
 }
 
-// MarshalBinaryWithContext serializes the internal properties of this LoadBalancer instance
+// MarshalBinaryWithContext serializes the internal properties of this GPU instance
 // into a byte array leveraging a predefined context.
-func (target *LoadBalancer) MarshalBinaryWithContext(ctx *EncodingContext) (err error) {
+func (target *GPU) MarshalBinaryWithContext(ctx *EncodingContext) (err error) {
 	// panics are recovered and propagated as errors
 	defer func() {
 		if r := recover(); r != nil {
@@ -7167,12 +7170,13 @@ func (target *LoadBalancer) MarshalBinaryWithContext(ctx *EncodingContext) (err
 
 	buff.WriteFloat64(target.Adjustment) // write float64
 	buff.WriteFloat64(target.Cost)       // write float64
+	buff.WriteFloat64(target.GPUHours)   // write float64
 	return nil
 }
 
 // UnmarshalBinary uses the data passed byte array to set all the internal properties of
-// the LoadBalancer type
-func (target *LoadBalancer) UnmarshalBinary(data []byte) error {
+// the GPU type
+func (target *GPU) UnmarshalBinary(data []byte) error {
 	var table []string
 	buff := util.NewBufferFromBytes(data)
 
@@ -7202,8 +7206,8 @@ func (target *LoadBalancer) UnmarshalBinary(data []byte) error {
 }
 
 // UnmarshalBinaryWithContext uses the context containing a string table and binary buffer to set all the internal properties of
-// the LoadBalancer type
-func (target *LoadBalancer) UnmarshalBinaryWithContext(ctx *DecodingContext) (err error) {
+// the GPU type
+func (target *GPU) UnmarshalBinaryWithContext(ctx *DecodingContext) (err error) {
 	// panics are recovered and propagated as errors
 	defer func() {
 		if r := recover(); r != nil {
@@ -7221,7 +7225,7 @@ func (target *LoadBalancer) UnmarshalBinaryWithContext(ctx *DecodingContext) (er
 	version := buff.ReadUInt8()
 
 	if version > AssetsCodecVersion {
-		return fmt.Errorf("Invalid Version Unmarshaling LoadBalancer. Expected %d or less, got %d", AssetsCodecVersion, version)
+		return fmt.Errorf("Invalid Version Unmarshaling GPU. Expected %d or less, got %d", AssetsCodecVersion, version)
 	}
 
 	if buff.ReadUInt8() == uint8(0) {
@@ -7316,16 +7320,19 @@ func (target *LoadBalancer) UnmarshalBinaryWithContext(ctx *DecodingContext) (er
 	u := buff.ReadFloat64() // read float64
 	target.Cost = u
 
+	v := buff.ReadFloat64() // read float64
+	target.GPUHours = v
+
 	return nil
 }
 
 //--------------------------------------------------------------------------
-//  Network
+//  LoadBalancer
 //--------------------------------------------------------------------------
 
-// MarshalBinary serializes the internal properties of this Network instance
+// MarshalBinary serializes the internal properties of this LoadBalancer instance
 // into a byte array
-func (target *Network) MarshalBinary() (data []byte, err error) {
+func (target *LoadBalancer) MarshalBinary() (data []byte, err error) {
 	ctx := &EncodingContext{
 		Buffer: util.NewBuffer(),
 		Table:  nil,
@@ -7340,9 +7347,9 @@ func (target *Network) MarshalBinary() (data []byte, err error) {
 	return encBytes, nil
 }
 
-// MarshalBinaryWithContext serializes the internal properties of this Network instance
+// MarshalBinaryWithContext serializes the internal properties of this LoadBalancer instance
 // into a byte array leveraging a predefined context.
-func (target *Network) MarshalBinaryWithContext(ctx *EncodingContext) (err error) {
+func (target *LoadBalancer) MarshalBinaryWithContext(ctx *EncodingContext) (err error) {
 	// panics are recovered and propagated as errors
 	defer func() {
 		if r := recover(); r != nil {
@@ -7432,8 +7439,8 @@ func (target *Network) MarshalBinaryWithContext(ctx *EncodingContext) (err error
 }
 
 // UnmarshalBinary uses the data passed byte array to set all the internal properties of
-// the Network type
-func (target *Network) UnmarshalBinary(data []byte) error {
+// the LoadBalancer type
+func (target *LoadBalancer) UnmarshalBinary(data []byte) error {
 	var table []string
 	buff := util.NewBufferFromBytes(data)
 
@@ -7463,8 +7470,8 @@ func (target *Network) UnmarshalBinary(data []byte) error {
 }
 
 // UnmarshalBinaryWithContext uses the context containing a string table and binary buffer to set all the internal properties of
-// the Network type
-func (target *Network) UnmarshalBinaryWithContext(ctx *DecodingContext) (err error) {
+// the LoadBalancer type
+func (target *LoadBalancer) UnmarshalBinaryWithContext(ctx *DecodingContext) (err error) {
 	// panics are recovered and propagated as errors
 	defer func() {
 		if r := recover(); r != nil {
@@ -7482,7 +7489,7 @@ func (target *Network) UnmarshalBinaryWithContext(ctx *DecodingContext) (err err
 	version := buff.ReadUInt8()
 
 	if version > AssetsCodecVersion {
-		return fmt.Errorf("Invalid Version Unmarshaling Network. Expected %d or less, got %d", AssetsCodecVersion, version)
+		return fmt.Errorf("Invalid Version Unmarshaling LoadBalancer. Expected %d or less, got %d", AssetsCodecVersion, version)
 	}
 
 	if buff.ReadUInt8() == uint8(0) {
@@ -7581,12 +7588,12 @@ func (target *Network) UnmarshalBinaryWithContext(ctx *DecodingContext) (err err
 }
 
 //--------------------------------------------------------------------------
-//  Node
+//  NATGateway
 //--------------------------------------------------------------------------
 
-// MarshalBinary serializes the internal properties of this Node instance
+// MarshalBinary serializes the internal properties of this NATGateway instance
 // into a byte array
-func (target *Node) MarshalBinary() (data []byte, err error) {
+func (target *NATGateway) MarshalBinary() (data []byte, err error) {
 	ctx := &EncodingContext{
 		Buffer: util.NewBuffer(),
 		Table:  nil,
@@ -7601,9 +7608,9 @@ func (target *Node) MarshalBinary() (data []byte, err error) {
 	return encBytes, nil
 }
 
-// MarshalBinaryWithContext serializes the internal properties of this Node instance
+// MarshalBinaryWithContext serializes the internal properties of this NATGateway instance
 // into a byte array leveraging a predefined context.
-func (target *Node) MarshalBinaryWithContext(ctx *EncodingContext) (err error) {
+func (target *NATGateway) MarshalBinaryWithContext(ctx *EncodingContext) (err error) {
 	// panics are recovered and propagated as errors
 	defer func() {
 		if r := recover(); r != nil {
@@ -7687,70 +7694,16 @@ func (target *Node) MarshalBinaryWithContext(ctx *EncodingContext) (err error) {
 	}
 	// --- [end][write][struct](Window) ---
 
-	buff.WriteFloat64(target.Adjustment) // write float64
-	if ctx.IsStringTable() {
-		e := ctx.Table.AddOrGet(target.NodeType)
-		buff.WriteInt(e) // write table index
-	} else {
-		buff.WriteString(target.NodeType) // write string
-	}
-	buff.WriteFloat64(target.CPUCoreHours) // write float64
-	buff.WriteFloat64(target.RAMByteHours) // write float64
-	buff.WriteFloat64(target.GPUHours)     // write float64
-	if target.CPUBreakdown == nil {
-		buff.WriteUInt8(uint8(0)) // write nil byte
-	} else {
-		buff.WriteUInt8(uint8(1)) // write non-nil byte
-
-		// --- [begin][write][struct](Breakdown) ---
-		buff.WriteInt(0) // [compatibility, unused]
-		errE := target.CPUBreakdown.MarshalBinaryWithContext(ctx)
-		if errE != nil {
-			return errE
-		}
-		// --- [end][write][struct](Breakdown) ---
-
-	}
-	if target.RAMBreakdown == nil {
-		buff.WriteUInt8(uint8(0)) // write nil byte
-	} else {
-		buff.WriteUInt8(uint8(1)) // write non-nil byte
-
-		// --- [begin][write][struct](Breakdown) ---
-		buff.WriteInt(0) // [compatibility, unused]
-		errF := target.RAMBreakdown.MarshalBinaryWithContext(ctx)
-		if errF != nil {
-			return errF
-		}
-		// --- [end][write][struct](Breakdown) ---
-
-	}
-	buff.WriteFloat64(target.CPUCost)     // write float64
-	buff.WriteFloat64(target.GPUCost)     // write float64
-	buff.WriteFloat64(target.GPUCount)    // write float64
-	buff.WriteFloat64(target.RAMCost)     // write float64
-	buff.WriteFloat64(target.Discount)    // write float64
-	buff.WriteFloat64(target.Preemptible) // write float64
-	if target.Overhead == nil {
-		buff.WriteUInt8(uint8(0)) // write nil byte
-	} else {
-		buff.WriteUInt8(uint8(1)) // write non-nil byte
-
-		// --- [begin][write][struct](NodeOverhead) ---
-		buff.WriteInt(0) // [compatibility, unused]
-		errG := target.Overhead.MarshalBinaryWithContext(ctx)
-		if errG != nil {
-			return errG
-		}
-		// --- [end][write][struct](NodeOverhead) ---
-
-	}
+	buff.WriteFloat64(target.Adjustment)         // write float64
+	buff.WriteFloat64(target.Cost)               // write float64
+	buff.WriteFloat64(target.DataProcessingCost) // write float64
+	buff.WriteFloat64(target.DataProcessedBytes) // write float64
 	return nil
 }
 
 // UnmarshalBinary uses the data passed byte array to set all the internal properties of
-// the Node type
-func (target *Node) UnmarshalBinary(data []byte) error {
+// the NATGateway type
+func (target *NATGateway) UnmarshalBinary(data []byte) error {
 	var table []string
 	buff := util.NewBufferFromBytes(data)
 
@@ -7780,8 +7733,8 @@ func (target *Node) UnmarshalBinary(data []byte) error {
 }
 
 // UnmarshalBinaryWithContext uses the context containing a string table and binary buffer to set all the internal properties of
-// the Node type
-func (target *Node) UnmarshalBinaryWithContext(ctx *DecodingContext) (err error) {
+// the NATGateway type
+func (target *NATGateway) UnmarshalBinaryWithContext(ctx *DecodingContext) (err error) {
 	// panics are recovered and propagated as errors
 	defer func() {
 		if r := recover(); r != nil {
@@ -7799,7 +7752,7 @@ func (target *Node) UnmarshalBinaryWithContext(ctx *DecodingContext) (err error)
 	version := buff.ReadUInt8()
 
 	if version > AssetsCodecVersion {
-		return fmt.Errorf("Invalid Version Unmarshaling Node. Expected %d or less, got %d", AssetsCodecVersion, version)
+		return fmt.Errorf("Invalid Version Unmarshaling NATGateway. Expected %d or less, got %d", AssetsCodecVersion, version)
 	}
 
 	if buff.ReadUInt8() == uint8(0) {
@@ -7891,102 +7844,25 @@ func (target *Node) UnmarshalBinaryWithContext(ctx *DecodingContext) (err error)
 	t := buff.ReadFloat64() // read float64
 	target.Adjustment = t
 
-	var w string
-	if ctx.IsStringTable() {
-		x := buff.ReadInt() // read string index
-		w = ctx.Table[x]
-	} else {
-		w = buff.ReadString() // read string
-	}
-	u := w
-	target.NodeType = u
-
-	y := buff.ReadFloat64() // read float64
-	target.CPUCoreHours = y
-
-	aa := buff.ReadFloat64() // read float64
-	target.RAMByteHours = aa
-
-	bb := buff.ReadFloat64() // read float64
-	target.GPUHours = bb
-
-	if buff.ReadUInt8() == uint8(0) {
-		target.CPUBreakdown = nil
-	} else {
-		// --- [begin][read][struct](Breakdown) ---
-		cc := &Breakdown{}
-		buff.ReadInt() // [compatibility, unused]
-		errE := cc.UnmarshalBinaryWithContext(ctx)
-		if errE != nil {
-			return errE
-		}
-		target.CPUBreakdown = cc
-		// --- [end][read][struct](Breakdown) ---
-
-	}
-	if buff.ReadUInt8() == uint8(0) {
-		target.RAMBreakdown = nil
-	} else {
-		// --- [begin][read][struct](Breakdown) ---
-		dd := &Breakdown{}
-		buff.ReadInt() // [compatibility, unused]
-		errF := dd.UnmarshalBinaryWithContext(ctx)
-		if errF != nil {
-			return errF
-		}
-		target.RAMBreakdown = dd
-		// --- [end][read][struct](Breakdown) ---
-
-	}
-	ee := buff.ReadFloat64() // read float64
-	target.CPUCost = ee
-
-	ff := buff.ReadFloat64() // read float64
-	target.GPUCost = ff
-
-	gg := buff.ReadFloat64() // read float64
-	target.GPUCount = gg
-
-	hh := buff.ReadFloat64() // read float64
-	target.RAMCost = hh
-
-	kk := buff.ReadFloat64() // read float64
-	target.Discount = kk
-
-	ll := buff.ReadFloat64() // read float64
-	target.Preemptible = ll
-
-	// field version check
-	if uint8(19) <= version {
-		if buff.ReadUInt8() == uint8(0) {
-			target.Overhead = nil
-		} else {
-			// --- [begin][read][struct](NodeOverhead) ---
-			mm := &NodeOverhead{}
-			buff.ReadInt() // [compatibility, unused]
-			errG := mm.UnmarshalBinaryWithContext(ctx)
-			if errG != nil {
-				return errG
-			}
-			target.Overhead = mm
-			// --- [end][read][struct](NodeOverhead) ---
+	u := buff.ReadFloat64() // read float64
+	target.Cost = u
 
-		}
-	} else {
-		target.Overhead = nil
+	v := buff.ReadFloat64() // read float64
+	target.DataProcessingCost = v
 
-	}
+	w := buff.ReadFloat64() // read float64
+	target.DataProcessedBytes = w
 
 	return nil
 }
 
 //--------------------------------------------------------------------------
-//  NodeOverhead
+//  Network
 //--------------------------------------------------------------------------
 
-// MarshalBinary serializes the internal properties of this NodeOverhead instance
+// MarshalBinary serializes the internal properties of this Network instance
 // into a byte array
-func (target *NodeOverhead) MarshalBinary() (data []byte, err error) {
+func (target *Network) MarshalBinary() (data []byte, err error) {
 	ctx := &EncodingContext{
 		Buffer: util.NewBuffer(),
 		Table:  nil,
@@ -8001,9 +7877,9 @@ func (target *NodeOverhead) MarshalBinary() (data []byte, err error) {
 	return encBytes, nil
 }
 
-// MarshalBinaryWithContext serializes the internal properties of this NodeOverhead instance
+// MarshalBinaryWithContext serializes the internal properties of this Network instance
 // into a byte array leveraging a predefined context.
-func (target *NodeOverhead) MarshalBinaryWithContext(ctx *EncodingContext) (err error) {
+func (target *Network) MarshalBinaryWithContext(ctx *EncodingContext) (err error) {
 	// panics are recovered and propagated as errors
 	defer func() {
 		if r := recover(); r != nil {
@@ -8020,38 +7896,699 @@ func (target *NodeOverhead) MarshalBinaryWithContext(ctx *EncodingContext) (err
 	buff := ctx.Buffer
 	buff.WriteUInt8(AssetsCodecVersion) // version
 
-	buff.WriteFloat64(target.CpuOverheadFraction)  // write float64
-	buff.WriteFloat64(target.RamOverheadFraction)  // write float64
-	buff.WriteFloat64(target.OverheadCostFraction) // write float64
-	return nil
-}
-
-// UnmarshalBinary uses the data passed byte array to set all the internal properties of
-// the NodeOverhead type
-func (target *NodeOverhead) UnmarshalBinary(data []byte) error {
-	var table []string
-	buff := util.NewBufferFromBytes(data)
+	if target.Properties == nil {
+		buff.WriteUInt8(uint8(0)) // write nil byte
+	} else {
+		buff.WriteUInt8(uint8(1)) // write non-nil byte
 
-	// string table header validation
-	if isBinaryTag(data, BinaryTagStringTable) {
-		buff.ReadBytes(len(BinaryTagStringTable)) // strip tag length
-		tl := buff.ReadInt()                      // table length
-		if tl > 0 {
-			table = make([]string, tl, tl)
-			for i := 0; i < tl; i++ {
-				table[i] = buff.ReadString()
-			}
+		// --- [begin][write][struct](AssetProperties) ---
+		buff.WriteInt(0) // [compatibility, unused]
+		errA := target.Properties.MarshalBinaryWithContext(ctx)
+		if errA != nil {
+			return errA
 		}
-	}
+		// --- [end][write][struct](AssetProperties) ---
 
-	ctx := &DecodingContext{
-		Buffer: buff,
-		Table:  table,
 	}
+	// --- [begin][write][alias](AssetLabels) ---
+	if map[string]string(target.Labels) == nil {
+		buff.WriteUInt8(uint8(0)) // write nil byte
+	} else {
+		buff.WriteUInt8(uint8(1)) // write non-nil byte
+
+		// --- [begin][write][map](map[string]string) ---
+		buff.WriteInt(len(map[string]string(target.Labels))) // map length
+		for v, z := range map[string]string(target.Labels) {
+			if ctx.IsStringTable() {
+				a := ctx.Table.AddOrGet(v)
+				buff.WriteInt(a) // write table index
+			} else {
+				buff.WriteString(v) // write string
+			}
+			if ctx.IsStringTable() {
+				b := ctx.Table.AddOrGet(z)
+				buff.WriteInt(b) // write table index
+			} else {
+				buff.WriteString(z) // write string
+			}
+		}
+		// --- [end][write][map](map[string]string) ---
 
-	err := target.UnmarshalBinaryWithContext(ctx)
-	if err != nil {
-		return err
+	}
+	// --- [end][write][alias](AssetLabels) ---
+
+	// --- [begin][write][reference](time.Time) ---
+	c, errB := target.Start.MarshalBinary()
+	if errB != nil {
+		return errB
+	}
+	buff.WriteInt(len(c))
+	buff.WriteBytes(c)
+	// --- [end][write][reference](time.Time) ---
+
+	// --- [begin][write][reference](time.Time) ---
+	d, errC := target.End.MarshalBinary()
+	if errC != nil {
+		return errC
+	}
+	buff.WriteInt(len(d))
+	buff.WriteBytes(d)
+	// --- [end][write][reference](time.Time) ---
+
+	// --- [begin][write][struct](Window) ---
+	buff.WriteInt(0) // [compatibility, unused]
+	errD := target.Window.MarshalBinaryWithContext(ctx)
+	if errD != nil {
+		return errD
+	}
+	// --- [end][write][struct](Window) ---
+
+	buff.WriteFloat64(target.Adjustment) // write float64
+	buff.WriteFloat64(target.Cost)       // write float64
+	return nil
+}
+
+// UnmarshalBinary uses the data passed byte array to set all the internal properties of
+// the Network type
+func (target *Network) UnmarshalBinary(data []byte) error {
+	var table []string
+	buff := util.NewBufferFromBytes(data)
+
+	// string table header validation
+	if isBinaryTag(data, BinaryTagStringTable) {
+		buff.ReadBytes(len(BinaryTagStringTable)) // strip tag length
+		tl := buff.ReadInt()                      // table length
+		if tl > 0 {
+			table = make([]string, tl, tl)
+			for i := 0; i < tl; i++ {
+				table[i] = buff.ReadString()
+			}
+		}
+	}
+
+	ctx := &DecodingContext{
+		Buffer: buff,
+		Table:  table,
+	}
+
+	err := target.UnmarshalBinaryWithContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UnmarshalBinaryWithContext uses the context containing a string table and binary buffer to set all the internal properties of
+// the Network type
+func (target *Network) UnmarshalBinaryWithContext(ctx *DecodingContext) (err error) {
+	// panics are recovered and propagated as errors
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(error); ok {
+				err = e
+			} else if s, ok := r.(string); ok {
+				err = fmt.Errorf("Unexpected panic: %s", s)
+			} else {
+				err = fmt.Errorf("Unexpected panic: %+v", r)
+			}
+		}
+	}()
+
+	buff := ctx.Buffer
+	version := buff.ReadUInt8()
+
+	if version > AssetsCodecVersion {
+		return fmt.Errorf("Invalid Version Unmarshaling Network. Expected %d or less, got %d", AssetsCodecVersion, version)
+	}
+
+	if buff.ReadUInt8() == uint8(0) {
+		target.Properties = nil
+	} else {
+		// --- [begin][read][struct](AssetProperties) ---
+		a := &AssetProperties{}
+		buff.ReadInt() // [compatibility, unused]
+		errA := a.UnmarshalBinaryWithContext(ctx)
+		if errA != nil {
+			return errA
+		}
+		target.Properties = a
+		// --- [end][read][struct](AssetProperties) ---
+
+	}
+	// --- [begin][read][alias](AssetLabels) ---
+	var b map[string]string
+	if buff.ReadUInt8() == uint8(0) {
+		b = nil
+	} else {
+		// --- [begin][read][map](map[string]string) ---
+		d := buff.ReadInt() // map len
+		c := make(map[string]string, d)
+		for i := 0; i < d; i++ {
+			var v string
+			var f string
+			if ctx.IsStringTable() {
+				g := buff.ReadInt() // read string index
+				f = ctx.Table[g]
+			} else {
+				f = buff.ReadString() // read string
+			}
+			e := f
+			v = e
+
+			var z string
+			var k string
+			if ctx.IsStringTable() {
+				l := buff.ReadInt() // read string index
+				k = ctx.Table[l]
+			} else {
+				k = buff.ReadString() // read string
+			}
+			h := k
+			z = h
+
+			c[v] = z
+		}
+		b = c
+		// --- [end][read][map](map[string]string) ---
+
+	}
+	target.Labels = AssetLabels(b)
+	// --- [end][read][alias](AssetLabels) ---
+
+	// --- [begin][read][reference](time.Time) ---
+	m := &time.Time{}
+	n := buff.ReadInt()    // byte array length
+	o := buff.ReadBytes(n) // byte array
+	errB := m.UnmarshalBinary(o)
+	if errB != nil {
+		return errB
+	}
+	target.Start = *m
+	// --- [end][read][reference](time.Time) ---
+
+	// --- [begin][read][reference](time.Time) ---
+	p := &time.Time{}
+	q := buff.ReadInt()    // byte array length
+	r := buff.ReadBytes(q) // byte array
+	errC := p.UnmarshalBinary(r)
+	if errC != nil {
+		return errC
+	}
+	target.End = *p
+	// --- [end][read][reference](time.Time) ---
+
+	// --- [begin][read][struct](Window) ---
+	s := &Window{}
+	buff.ReadInt() // [compatibility, unused]
+	errD := s.UnmarshalBinaryWithContext(ctx)
+	if errD != nil {
+		return errD
+	}
+	target.Window = *s
+	// --- [end][read][struct](Window) ---
+
+	t := buff.ReadFloat64() // read float64
+	target.Adjustment = t
+
+	u := buff.ReadFloat64() // read float64
+	target.Cost = u
+
+	return nil
+}
+
+//--------------------------------------------------------------------------
+//  Node
+//--------------------------------------------------------------------------
+
+// MarshalBinary serializes the internal properties of this Node instance
+// into a byte array
+func (target *Node) MarshalBinary() (data []byte, err error) {
+	ctx := &EncodingContext{
+		Buffer: util.NewBuffer(),
+		Table:  nil,
+	}
+
+	e := target.MarshalBinaryWithContext(ctx)
+	if e != nil {
+		return nil, e
+	}
+
+	encBytes := ctx.Buffer.Bytes()
+	return encBytes, nil
+}
+
+// MarshalBinaryWithContext serializes the internal properties of this Node instance
+// into a byte array leveraging a predefined context.
+func (target *Node) MarshalBinaryWithContext(ctx *EncodingContext) (err error) {
+	// panics are recovered and propagated as errors
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(error); ok {
+				err = e
+			} else if s, ok := r.(string); ok {
+				err = fmt.Errorf("Unexpected panic: %s", s)
+			} else {
+				err = fmt.Errorf("Unexpected panic: %+v", r)
+			}
+		}
+	}()
+
+	buff := ctx.Buffer
+	buff.WriteUInt8(AssetsCodecVersion) // version
+
+	if target.Properties == nil {
+		buff.WriteUInt8(uint8(0)) // write nil byte
+	} else {
+		buff.WriteUInt8(uint8(1)) // write non-nil byte
+
+		// --- [begin][write][struct](AssetProperties) ---
+		buff.WriteInt(0) // [compatibility, unused]
+		errA := target.Properties.MarshalBinaryWithContext(ctx)
+		if errA != nil {
+			return errA
+		}
+		// --- [end][write][struct](AssetProperties) ---
+
+	}
+	// --- [begin][write][alias](AssetLabels) ---
+	if map[string]string(target.Labels) == nil {
+		buff.WriteUInt8(uint8(0)) // write nil byte
+	} else {
+		buff.WriteUInt8(uint8(1)) // write non-nil byte
+
+		// --- [begin][write][map](map[string]string) ---
+		buff.WriteInt(len(map[string]string(target.Labels))) // map length
+		for v, z := range map[string]string(target.Labels) {
+			if ctx.IsStringTable() {
+				a := ctx.Table.AddOrGet(v)
+				buff.WriteInt(a) // write table index
+			} else {
+				buff.WriteString(v) // write string
+			}
+			if ctx.IsStringTable() {
+				b := ctx.Table.AddOrGet(z)
+				buff.WriteInt(b) // write table index
+			} else {
+				buff.WriteString(z) // write string
+			}
+		}
+		// --- [end][write][map](map[string]string) ---
+
+	}
+	// --- [end][write][alias](AssetLabels) ---
+
+	// --- [begin][write][reference](time.Time) ---
+	c, errB := target.Start.MarshalBinary()
+	if errB != nil {
+		return errB
+	}
+	buff.WriteInt(len(c))
+	buff.WriteBytes(c)
+	// --- [end][write][reference](time.Time) ---
+
+	// --- [begin][write][reference](time.Time) ---
+	d, errC := target.End.MarshalBinary()
+	if errC != nil {
+		return errC
+	}
+	buff.WriteInt(len(d))
+	buff.WriteBytes(d)
+	// --- [end][write][reference](time.Time) ---
+
+	// --- [begin][write][struct](Window) ---
+	buff.WriteInt(0) // [compatibility, unused]
+	errD := target.Window.MarshalBinaryWithContext(ctx)
+	if errD != nil {
+		return errD
+	}
+	// --- [end][write][struct](Window) ---
+
+	buff.WriteFloat64(target.Adjustment) // write float64
+	if ctx.IsStringTable() {
+		e := ctx.Table.AddOrGet(target.NodeType)
+		buff.WriteInt(e) // write table index
+	} else {
+		buff.WriteString(target.NodeType) // write string
+	}
+	buff.WriteFloat64(target.CPUCoreHours) // write float64
+	buff.WriteFloat64(target.RAMByteHours) // write float64
+	buff.WriteFloat64(target.GPUHours)     // write float64
+	if target.CPUBreakdown == nil {
+		buff.WriteUInt8(uint8(0)) // write nil byte
+	} else {
+		buff.WriteUInt8(uint8(1)) // write non-nil byte
+
+		// --- [begin][write][struct](Breakdown) ---
+		buff.WriteInt(0) // [compatibility, unused]
+		errE := target.CPUBreakdown.MarshalBinaryWithContext(ctx)
+		if errE != nil {
+			return errE
+		}
+		// --- [end][write][struct](Breakdown) ---
+
+	}
+	if target.RAMBreakdown == nil {
+		buff.WriteUInt8(uint8(0)) // write nil byte
+	} else {
+		buff.WriteUInt8(uint8(1)) // write non-nil byte
+
+		// --- [begin][write][struct](Breakdown) ---
+		buff.WriteInt(0) // [compatibility, unused]
+		errF := target.RAMBreakdown.MarshalBinaryWithContext(ctx)
+		if errF != nil {
+			return errF
+		}
+		// --- [end][write][struct](Breakdown) ---
+
+	}
+	buff.WriteFloat64(target.CPUCost)     // write float64
+	buff.WriteFloat64(target.GPUCost)     // write float64
+	buff.WriteFloat64(target.GPUCount)    // write float64
+	buff.WriteFloat64(target.RAMCost)     // write float64
+	buff.WriteFloat64(target.Discount)    // write float64
+	buff.WriteFloat64(target.Preemptible) // write float64
+	if target.Overhead == nil {
+		buff.WriteUInt8(uint8(0)) // write nil byte
+	} else {
+		buff.WriteUInt8(uint8(1)) // write non-nil byte
+
+		// --- [begin][write][struct](NodeOverhead) ---
+		buff.WriteInt(0) // [compatibility, unused]
+		errG := target.Overhead.MarshalBinaryWithContext(ctx)
+		if errG != nil {
+			return errG
+		}
+		// --- [end][write][struct](NodeOverhead) ---
+
+	}
+	return nil
+}
+
+// UnmarshalBinary uses the data passed byte array to set all the internal properties of
+// the Node type
+func (target *Node) UnmarshalBinary(data []byte) error {
+	var table []string
+	buff := util.NewBufferFromBytes(data)
+
+	// string table header validation
+	if isBinaryTag(data, BinaryTagStringTable) {
+		buff.ReadBytes(len(BinaryTagStringTable)) // strip tag length
+		tl := buff.ReadInt()                      // table length
+		if tl > 0 {
+			table = make([]string, tl, tl)
+			for i := 0; i < tl; i++ {
+				table[i] = buff.ReadString()
+			}
+		}
+	}
+
+	ctx := &DecodingContext{
+		Buffer: buff,
+		Table:  table,
+	}
+
+	err := target.UnmarshalBinaryWithContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UnmarshalBinaryWithContext uses the context containing a string table and binary buffer to set all the internal properties of
+// the Node type
+func (target *Node) UnmarshalBinaryWithContext(ctx *DecodingContext) (err error) {
+	// panics are recovered and propagated as errors
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(error); ok {
+				err = e
+			} else if s, ok := r.(string); ok {
+				err = fmt.Errorf("Unexpected panic: %s", s)
+			} else {
+				err = fmt.Errorf("Unexpected panic: %+v", r)
+			}
+		}
+	}()
+
+	buff := ctx.Buffer
+	version := buff.ReadUInt8()
+
+	if version > AssetsCodecVersion {
+		return fmt.Errorf("Invalid Version Unmarshaling Node. Expected %d or less, got %d", AssetsCodecVersion, version)
+	}
+
+	if buff.ReadUInt8() == uint8(0) {
+		target.Properties = nil
+	} else {
+		// --- [begin][read][struct](AssetProperties) ---
+		a := &AssetProperties{}
+		buff.ReadInt() // [compatibility, unused]
+		errA := a.UnmarshalBinaryWithContext(ctx)
+		if errA != nil {
+			return errA
+		}
+		target.Properties = a
+		// --- [end][read][struct](AssetProperties) ---
+
+	}
+	// --- [begin][read][alias](AssetLabels) ---
+	var b map[string]string
+	if buff.ReadUInt8() == uint8(0) {
+		b = nil
+	} else {
+		// --- [begin][read][map](map[string]string) ---
+		d := buff.ReadInt() // map len
+		c := make(map[string]string, d)
+		for i := 0; i < d; i++ {
+			var v string
+			var f string
+			if ctx.IsStringTable() {
+				g := buff.ReadInt() // read string index
+				f = ctx.Table[g]
+			} else {
+				f = buff.ReadString() // read string
+			}
+			e := f
+			v = e
+
+			var z string
+			var k string
+			if ctx.IsStringTable() {
+				l := buff.ReadInt() // read string index
+				k = ctx.Table[l]
+			} else {
+				k = buff.ReadString() // read string
+			}
+			h := k
+			z = h
+
+			c[v] = z
+		}
+		b = c
+		// --- [end][read][map](map[string]string) ---
+
+	}
+	target.Labels = AssetLabels(b)
+	// --- [end][read][alias](AssetLabels) ---
+
+	// --- [begin][read][reference](time.Time) ---
+	m := &time.Time{}
+	n := buff.ReadInt()    // byte array length
+	o := buff.ReadBytes(n) // byte array
+	errB := m.UnmarshalBinary(o)
+	if errB != nil {
+		return errB
+	}
+	target.Start = *m
+	// --- [end][read][reference](time.Time) ---
+
+	// --- [begin][read][reference](time.Time) ---
+	p := &time.Time{}
+	q := buff.ReadInt()    // byte array length
+	r := buff.ReadBytes(q) // byte array
+	errC := p.UnmarshalBinary(r)
+	if errC != nil {
+		return errC
+	}
+	target.End = *p
+	// --- [end][read][reference](time.Time) ---
+
+	// --- [begin][read][struct](Window) ---
+	s := &Window{}
+	buff.ReadInt() // [compatibility, unused]
+	errD := s.UnmarshalBinaryWithContext(ctx)
+	if errD != nil {
+		return errD
+	}
+	target.Window = *s
+	// --- [end][read][struct](Window) ---
+
+	t := buff.ReadFloat64() // read float64
+	target.Adjustment = t
+
+	var w string
+	if ctx.IsStringTable() {
+		x := buff.ReadInt() // read string index
+		w = ctx.Table[x]
+	} else {
+		w = buff.ReadString() // read string
+	}
+	u := w
+	target.NodeType = u
+
+	y := buff.ReadFloat64() // read float64
+	target.CPUCoreHours = y
+
+	aa := buff.ReadFloat64() // read float64
+	target.RAMByteHours = aa
+
+	bb := buff.ReadFloat64() // read float64
+	target.GPUHours = bb
+
+	if buff.ReadUInt8() == uint8(0) {
+		target.CPUBreakdown = nil
+	} else {
+		// --- [begin][read][struct](Breakdown) ---
+		cc := &Breakdown{}
+		buff.ReadInt() // [compatibility, unused]
+		errE := cc.UnmarshalBinaryWithContext(ctx)
+		if errE != nil {
+			return errE
+		}
+		target.CPUBreakdown = cc
+		// --- [end][read][struct](Breakdown) ---
+
+	}
+	if buff.ReadUInt8() == uint8(0) {
+		target.RAMBreakdown = nil
+	} else {
+		// --- [begin][read][struct](Breakdown) ---
+		dd := &Breakdown{}
+		buff.ReadInt() // [compatibility, unused]
+		errF := dd.UnmarshalBinaryWithContext(ctx)
+		if errF != nil {
+			return errF
+		}
+		target.RAMBreakdown = dd
+		// --- [end][read][struct](Breakdown) ---
+
+	}
+	ee := buff.ReadFloat64() // read float64
+	target.CPUCost = ee
+
+	ff := buff.ReadFloat64() // read float64
+	target.GPUCost = ff
+
+	gg := buff.ReadFloat64() // read float64
+	target.GPUCount = gg
+
+	hh := buff.ReadFloat64() // read float64
+	target.RAMCost = hh
+
+	kk := buff.ReadFloat64() // read float64
+	target.Discount = kk
+
+	ll := buff.ReadFloat64() // read float64
+	target.Preemptible = ll
+
+	// field version check
+	if uint8(19) <= version {
+		if buff.ReadUInt8() == uint8(0) {
+			target.Overhead = nil
+		} else {
+			// --- [begin][read][struct](NodeOverhead) ---
+			mm := &NodeOverhead{}
+			buff.ReadInt() // [compatibility, unused]
+			errG := mm.UnmarshalBinaryWithContext(ctx)
+			if errG != nil {
+				return errG
+			}
+			target.Overhead = mm
+			// --- [end][read][struct](NodeOverhead) ---
+
+		}
+	} else {
+		target.Overhead = nil
+
+	}
+
+	return nil
+}
+
+//--------------------------------------------------------------------------
+//  NodeOverhead
+//--------------------------------------------------------------------------
+
+// MarshalBinary serializes the internal properties of this NodeOverhead instance
+// into a byte array
+func (target *NodeOverhead) MarshalBinary() (data []byte, err error) {
+	ctx := &EncodingContext{
+		Buffer: util.NewBuffer(),
+		Table:  nil,
+	}
+
+	e := target.MarshalBinaryWithContext(ctx)
+	if e != nil {
+		return nil, e
+	}
+
+	encBytes := ctx.Buffer.Bytes()
+	return encBytes, nil
+}
+
+// MarshalBinaryWithContext serializes the internal properties of this NodeOverhead instance
+// into a byte array leveraging a predefined context.
+func (target *NodeOverhead) MarshalBinaryWithContext(ctx *EncodingContext) (err error) {
+	// panics are recovered and propagated as errors
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(error); ok {
+				err = e
+			} else if s, ok := r.(string); ok {
+				err = fmt.Errorf("Unexpected panic: %s", s)
+			} else {
+				err = fmt.Errorf("Unexpected panic: %+v", r)
+			}
+		}
+	}()
+
+	buff := ctx.Buffer
+	buff.WriteUInt8(AssetsCodecVersion) // version
+
+	buff.WriteFloat64(target.CpuOverheadFraction)  // write float64
+	buff.WriteFloat64(target.RamOverheadFraction)  // write float64
+	buff.WriteFloat64(target.OverheadCostFraction) // write float64
+	return nil
+}
+
+// UnmarshalBinary uses the data passed byte array to set all the internal properties of
+// the NodeOverhead type
+func (target *NodeOverhead) UnmarshalBinary(data []byte) error {
+	var table []string
+	buff := util.NewBufferFromBytes(data)
+
+	// string table header validation
+	if isBinaryTag(data, BinaryTagStringTable) {
+		buff.ReadBytes(len(BinaryTagStringTable)) // strip tag length
+		tl := buff.ReadInt()                      // table length
+		if tl > 0 {
+			table = make([]string, tl, tl)
+			for i := 0; i < tl; i++ {
+				table[i] = buff.ReadString()
+			}
+		}
+	}
+
+	ctx := &DecodingContext{
+		Buffer: buff,
+		Table:  table,
+	}
+
+	err := target.UnmarshalBinaryWithContext(ctx)
+	if err != nil {
+		return err
 	}
 
 	return nil
@@ -8169,8 +8706,126 @@ func (target *PVAllocation) UnmarshalBinary(data []byte) error {
 }
 
 // UnmarshalBinaryWithContext uses the context containing a string table and binary buffer to set all the internal properties of
-// the PVAllocation type
-func (target *PVAllocation) UnmarshalBinaryWithContext(ctx *DecodingContext) (err error) {
+// the PVAllocation type
+func (target *PVAllocation) UnmarshalBinaryWithContext(ctx *DecodingContext) (err error) {
+	// panics are recovered and propagated as errors
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(error); ok {
+				err = e
+			} else if s, ok := r.(string); ok {
+				err = fmt.Errorf("Unexpected panic: %s", s)
+			} else {
+				err = fmt.Errorf("Unexpected panic: %+v", r)
+			}
+		}
+	}()
+
+	buff := ctx.Buffer
+	version := buff.ReadUInt8()
+
+	if version > AllocationCodecVersion {
+		return fmt.Errorf("Invalid Version Unmarshaling PVAllocation. Expected %d or less, got %d", AllocationCodecVersion, version)
+	}
+
+	a := buff.ReadFloat64() // read float64
+	target.ByteHours = a
+
+	b := buff.ReadFloat64() // read float64
+	target.Cost = b
+
+	return nil
+}
+
+//--------------------------------------------------------------------------
+//  PVKey
+//--------------------------------------------------------------------------
+
+// MarshalBinary serializes the internal properties of this PVKey instance
+// into a byte array
+func (target *PVKey) MarshalBinary() (data []byte, err error) {
+	ctx := &EncodingContext{
+		Buffer: util.NewBuffer(),
+		Table:  nil,
+	}
+
+	e := target.MarshalBinaryWithContext(ctx)
+	if e != nil {
+		return nil, e
+	}
+
+	encBytes := ctx.Buffer.Bytes()
+	return encBytes, nil
+}
+
+// MarshalBinaryWithContext serializes the internal properties of this PVKey instance
+// into a byte array leveraging a predefined context.
+func (target *PVKey) MarshalBinaryWithContext(ctx *EncodingContext) (err error) {
+	// panics are recovered and propagated as errors
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(error); ok {
+				err = e
+			} else if s, ok := r.(string); ok {
+				err = fmt.Errorf("Unexpected panic: %s", s)
+			} else {
+				err = fmt.Errorf("Unexpected panic: %+v", r)
+			}
+		}
+	}()
+
+	buff := ctx.Buffer
+	buff.WriteUInt8(AllocationCodecVersion) // version
+
+	if ctx.IsStringTable() {
+		a := ctx.Table.AddOrGet(target.Cluster)
+		buff.WriteInt(a) // write table index
+	} else {
+		buff.WriteString(target.Cluster) // write string
+	}
+	if ctx.IsStringTable() {
+		b := ctx.Table.AddOrGet(target.Name)
+		buff.WriteInt(b) // write table index
+	} else {
+		buff.WriteString(target.Name) // write string
+	}
+	return nil
+}
+
+// UnmarshalBinary uses the data passed byte array to set all the internal properties of
+// the PVKey type
+func (target *PVKey) UnmarshalBinary(data []byte) error {
+	var table []string
+	buff := util.NewBufferFromBytes(data)
+
+	// string table header validation
+	if isBinaryTag(data, BinaryTagStringTable) {
+		buff.ReadBytes(len(BinaryTagStringTable)) // strip tag length
+		tl := buff.ReadInt()                      // table length
+		if tl > 0 {
+			table = make([]string, tl, tl)
+			for i := 0; i < tl; i++ {
+				table[i] = buff.ReadString()
+			}
+		}
+	}
+
+	ctx := &DecodingContext{
+		Buffer: buff,
+		Table:  table,
+	}
+
+	err := target.UnmarshalBinaryWithContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UnmarshalBinaryWithContext uses the context containing a string table and binary buffer to set all the internal properties of
+// the PVKey type
+func (target *PVKey) UnmarshalBinaryWithContext(ctx *DecodingContext) (err error) {
 	// panics are recovered and propagated as errors
 	defer func() {
 		if r := recover(); r != nil {
@@ -8188,25 +8843,39 @@ func (target *PVAllocation) UnmarshalBinaryWithContext(ctx *DecodingContext) (er
 	version := buff.ReadUInt8()
 
 	if version > AllocationCodecVersion {
-		return fmt.Errorf("Invalid Version Unmarshaling PVAllocation. Expected %d or less, got %d", AllocationCodecVersion, version)
+		return fmt.Errorf("Invalid Version Unmarshaling PVKey. Expected %d or less, got %d", AllocationCodecVersion, version)
 	}
 
-	a := buff.ReadFloat64() // read float64
-	target.ByteHours = a
+	var b string
+	if ctx.IsStringTable() {
+		c := buff.ReadInt() // read string index
+		b = ctx.Table[c]
+	} else {
+		b = buff.ReadString() // read string
+	}
+	a := b
+	target.Cluster = a
 
-	b := buff.ReadFloat64() // read float64
-	target.Cost = b
+	var e string
+	if ctx.IsStringTable() {
+		f := buff.ReadInt() // read string index
+		e = ctx.Table[f]
+	} else {
+		e = buff.ReadString() // read string
+	}
+	d := e
+	target.Name = d
 
 	return nil
 }
 
 //--------------------------------------------------------------------------
-//  PVKey
+//  PublicIP
 //--------------------------------------------------------------------------
 
-// MarshalBinary serializes the internal properties of this PVKey instance
+// MarshalBinary serializes the internal properties of this PublicIP instance
 // into a byte array
-func (target *PVKey) MarshalBinary() (data []byte, err error) {
+func (target *PublicIP) MarshalBinary() (data []byte, err error) {
 	ctx := &EncodingContext{
 		Buffer: util.NewBuffer(),
 		Table:  nil,
@@ -8221,9 +8890,9 @@ func (target *PVKey) MarshalBinary() (data []byte, err error) {
 	return encBytes, nil
 }
 
-// MarshalBinaryWithContext serializes the internal properties of this PVKey instance
+// MarshalBinaryWithContext serializes the internal properties of this PublicIP instance
 // into a byte array leveraging a predefined context.
-func (target *PVKey) MarshalBinaryWithContext(ctx *EncodingContext) (err error) {
+func (target *PublicIP) MarshalBinaryWithContext(ctx *EncodingContext) (err error) {
 	// panics are recovered and propagated as errors
 	defer func() {
 		if r := recover(); r != nil {
@@ -8238,26 +8907,83 @@ func (target *PVKey) MarshalBinaryWithContext(ctx *EncodingContext) (err error)
 	}()
 
 	buff := ctx.Buffer
-	buff.WriteUInt8(AllocationCodecVersion) // version
+	buff.WriteUInt8(AssetsCodecVersion) // version
 
-	if ctx.IsStringTable() {
-		a := ctx.Table.AddOrGet(target.Cluster)
-		buff.WriteInt(a) // write table index
+	if target.Properties == nil {
+		buff.WriteUInt8(uint8(0)) // write nil byte
 	} else {
-		buff.WriteString(target.Cluster) // write string
+		buff.WriteUInt8(uint8(1)) // write non-nil byte
+
+		// --- [begin][write][struct](AssetProperties) ---
+		buff.WriteInt(0) // [compatibility, unused]
+		errA := target.Properties.MarshalBinaryWithContext(ctx)
+		if errA != nil {
+			return errA
+		}
+		// --- [end][write][struct](AssetProperties) ---
+
 	}
-	if ctx.IsStringTable() {
-		b := ctx.Table.AddOrGet(target.Name)
-		buff.WriteInt(b) // write table index
+	// --- [begin][write][alias](AssetLabels) ---
+	if map[string]string(target.Labels) == nil {
+		buff.WriteUInt8(uint8(0)) // write nil byte
 	} else {
-		buff.WriteString(target.Name) // write string
+		buff.WriteUInt8(uint8(1)) // write non-nil byte
+
+		// --- [begin][write][map](map[string]string) ---
+		buff.WriteInt(len(map[string]string(target.Labels))) // map length
+		for v, z := range map[string]string(target.Labels) {
+			if ctx.IsStringTable() {
+				a := ctx.Table.AddOrGet(v)
+				buff.WriteInt(a) // write table index
+			} else {
+				buff.WriteString(v) // write string
+			}
+			if ctx.IsStringTable() {
+				b := ctx.Table.AddOrGet(z)
+				buff.WriteInt(b) // write table index
+			} else {
+				buff.WriteString(z) // write string
+			}
+		}
+		// --- [end][write][map](map[string]string) ---
+
+	}
+	// --- [end][write][alias](AssetLabels) ---
+
+	// --- [begin][write][reference](time.Time) ---
+	c, errB := target.Start.MarshalBinary()
+	if errB != nil {
+		return errB
+	}
+	buff.WriteInt(len(c))
+	buff.WriteBytes(c)
+	// --- [end][write][reference](time.Time) ---
+
+	// --- [begin][write][reference](time.Time) ---
+	d, errC := target.End.MarshalBinary()
+	if errC != nil {
+		return errC
+	}
+	buff.WriteInt(len(d))
+	buff.WriteBytes(d)
+	// --- [end][write][reference](time.Time) ---
+
+	// --- [begin][write][struct](Window) ---
+	buff.WriteInt(0) // [compatibility, unused]
+	errD := target.Window.MarshalBinaryWithContext(ctx)
+	if errD != nil {
+		return errD
 	}
+	// --- [end][write][struct](Window) ---
+
+	buff.WriteFloat64(target.Adjustment) // write float64
+	buff.WriteFloat64(target.Cost)       // write float64
 	return nil
 }
 
 // UnmarshalBinary uses the data passed byte array to set all the internal properties of
-// the PVKey type
-func (target *PVKey) UnmarshalBinary(data []byte) error {
+// the PublicIP type
+func (target *PublicIP) UnmarshalBinary(data []byte) error {
 	var table []string
 	buff := util.NewBufferFromBytes(data)
 
@@ -8287,8 +9013,8 @@ func (target *PVKey) UnmarshalBinary(data []byte) error {
 }
 
 // UnmarshalBinaryWithContext uses the context containing a string table and binary buffer to set all the internal properties of
-// the PVKey type
-func (target *PVKey) UnmarshalBinaryWithContext(ctx *DecodingContext) (err error) {
+// the PublicIP type
+func (target *PublicIP) UnmarshalBinaryWithContext(ctx *DecodingContext) (err error) {
 	// panics are recovered and propagated as errors
 	defer func() {
 		if r := recover(); r != nil {
@@ -8305,29 +9031,101 @@ func (target *PVKey) UnmarshalBinaryWithContext(ctx *DecodingContext) (err error
 	buff := ctx.Buffer
 	version := buff.ReadUInt8()
 
-	if version > AllocationCodecVersion {
-		return fmt.Errorf("Invalid Version Unmarshaling PVKey. Expected %d or less, got %d", AllocationCodecVersion, version)
+	if version > AssetsCodecVersion {
+		return fmt.Errorf("Invalid Version Unmarshaling PublicIP. Expected %d or less, got %d", AssetsCodecVersion, version)
 	}
 
-	var b string
-	if ctx.IsStringTable() {
-		c := buff.ReadInt() // read string index
-		b = ctx.Table[c]
+	if buff.ReadUInt8() == uint8(0) {
+		target.Properties = nil
 	} else {
-		b = buff.ReadString() // read string
-	}
-	a := b
-	target.Cluster = a
+		// --- [begin][read][struct](AssetProperties) ---
+		a := &AssetProperties{}
+		buff.ReadInt() // [compatibility, unused]
+		errA := a.UnmarshalBinaryWithContext(ctx)
+		if errA != nil {
+			return errA
+		}
+		target.Properties = a
+		// --- [end][read][struct](AssetProperties) ---
 
-	var e string
-	if ctx.IsStringTable() {
-		f := buff.ReadInt() // read string index
-		e = ctx.Table[f]
+	}
+	// --- [begin][read][alias](AssetLabels) ---
+	var b map[string]string
+	if buff.ReadUInt8() == uint8(0) {
+		b = nil
 	} else {
-		e = buff.ReadString() // read string
+		// --- [begin][read][map](map[string]string) ---
+		d := buff.ReadInt() // map len
+		c := make(map[string]string, d)
+		for i := 0; i < d; i++ {
+			var v string
+			var f string
+			if ctx.IsStringTable() {
+				g := buff.ReadInt() // read string index
+				f = ctx.Table[g]
+			} else {
+				f = buff.ReadString() // read string
+			}
+			e := f
+			v = e
+
+			var z string
+			var k string
+			if ctx.IsStringTable() {
+				l := buff.ReadInt() // read string index
+				k = ctx.Table[l]
+			} else {
+				k = buff.ReadString() // read string
+			}
+			h := k
+			z = h
+
+			c[v] = z
+		}
+		b = c
+		// --- [end][read][map](map[string]string) ---
+
 	}
-	d := e
-	target.Name = d
+	target.Labels = AssetLabels(b)
+	// --- [end][read][alias](AssetLabels) ---
+
+	// --- [begin][read][reference](time.Time) ---
+	m := &time.Time{}
+	n := buff.ReadInt()    // byte array length
+	o := buff.ReadBytes(n) // byte array
+	errB := m.UnmarshalBinary(o)
+	if errB != nil {
+		return errB
+	}
+	target.Start = *m
+	// --- [end][read][reference](time.Time) ---
+
+	// --- [begin][read][reference](time.Time) ---
+	p := &time.Time{}
+	q := buff.ReadInt()    // byte array length
+	r := buff.ReadBytes(q) // byte array
+	errC := p.UnmarshalBinary(r)
+	if errC != nil {
+		return errC
+	}
+	target.End = *p
+	// --- [end][read][reference](time.Time) ---
+
+	// --- [begin][read][struct](Window) ---
+	s := &Window{}
+	buff.ReadInt() // [compatibility, unused]
+	errD := s.UnmarshalBinaryWithContext(ctx)
+	if errD != nil {
+		return errD
+	}
+	target.Window = *s
+	// --- [end][read][struct](Window) ---
+
+	t := buff.ReadFloat64() // read float64
+	target.Adjustment = t
+
+	u := buff.ReadFloat64() // read float64
+	target.Cost = u
 
 	return nil
 }
@@ -8374,6 +9172,12 @@ func (target *RawAllocationOnlyData) MarshalBinaryWithContext(ctx *EncodingConte
 
 	buff.WriteFloat64(target.CPUCoreUsageMax)  // write float64
 	buff.WriteFloat64(target.RAMBytesUsageMax) // write float64
+	buff.WriteFloat64(target.CPUCoreUsageP50)  // write float64
+	buff.WriteFloat64(target.CPUCoreUsageP95)  // write float64
+	buff.WriteFloat64(target.CPUCoreUsageP99)  // write float64
+	buff.WriteFloat64(target.RAMBytesUsageP50) // write float64
+	buff.WriteFloat64(target.RAMBytesUsageP95) // write float64
+	buff.WriteFloat64(target.RAMBytesUsageP99) // write float64
 	return nil
 }
 
@@ -8437,6 +9241,60 @@ func (target *RawAllocationOnlyData) UnmarshalBinaryWithContext(ctx *DecodingCon
 	b := buff.ReadFloat64() // read float64
 	target.RAMBytesUsageMax = b
 
+	// field version check
+	if uint8(17) <= version {
+		c := buff.ReadFloat64() // read float64
+		target.CPUCoreUsageP50 = c
+
+	} else {
+		target.CPUCoreUsageP50 = float64(0) // default
+	}
+
+	// field version check
+	if uint8(17) <= version {
+		d := buff.ReadFloat64() // read float64
+		target.CPUCoreUsageP95 = d
+
+	} else {
+		target.CPUCoreUsageP95 = float64(0) // default
+	}
+
+	// field version check
+	if uint8(17) <= version {
+		e := buff.ReadFloat64() // read float64
+		target.CPUCoreUsageP99 = e
+
+	} else {
+		target.CPUCoreUsageP99 = float64(0) // default
+	}
+
+	// field version check
+	if uint8(17) <= version {
+		f := buff.ReadFloat64() // read float64
+		target.RAMBytesUsageP50 = f
+
+	} else {
+		target.RAMBytesUsageP50 = float64(0) // default
+	}
+
+	// field version check
+	if uint8(17) <= version {
+		g := buff.ReadFloat64() // read float64
+		target.RAMBytesUsageP95 = g
+
+	} else {
+		target.RAMBytesUsageP95 = float64(0) // default
+	}
+
+	// field version check
+	if uint8(17) <= version {
+		h := buff.ReadFloat64() // read float64
+		target.RAMBytesUsageP99 = h
+
+	} else {
+		target.RAMBytesUsageP99 = float64(0) // default
+	}
+
 	return nil
 }
 
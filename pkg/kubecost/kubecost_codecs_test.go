@@ -399,6 +399,67 @@ func TestDisk_BinaryEncoding(t *testing.T) {
 	}
 }
 
+func TestGPU_BinaryEncoding(t *testing.T) {
+	ws := time.Date(2020, time.September, 16, 0, 0, 0, 0, time.UTC)
+	we := ws.Add(24 * time.Hour)
+	window := NewWindow(&ws, &we)
+
+	var a0, a1 *GPU
+	var bs []byte
+	var err error
+
+	a0 = NewGPU("node1", "cluster1", "i-1", ws, we, window)
+	a0.Cost = 30.44
+	a0.GPUHours = 2.0 * window.Duration().Hours()
+	a0.SetAdjustment(-1.23)
+
+	bs, err = a0.MarshalBinary()
+	if err != nil {
+		t.Fatalf("GPU.Binary: unexpected error: %s", err)
+	}
+
+	a1 = &GPU{}
+	err = a1.UnmarshalBinary(bs)
+	if err != nil {
+		t.Fatalf("GPU.Binary: unexpected error: %s", err)
+	}
+
+	if !a0.Equal(a1) {
+		t.Fatalf("GPU.Binary: expected %v, found %v", a0, a1)
+	}
+}
+
+func TestNATGateway_BinaryEncoding(t *testing.T) {
+	ws := time.Date(2020, time.September, 16, 0, 0, 0, 0, time.UTC)
+	we := ws.Add(24 * time.Hour)
+	window := NewWindow(&ws, &we)
+
+	var a0, a1 *NATGateway
+	var bs []byte
+	var err error
+
+	a0 = NewNATGateway("nat-1", "cluster1", "nat-1", ws, we, window)
+	a0.Cost = 1.08
+	a0.DataProcessingCost = 4.5
+	a0.DataProcessedBytes = 100 * gb
+	a0.SetAdjustment(0.12)
+
+	bs, err = a0.MarshalBinary()
+	if err != nil {
+		t.Fatalf("NATGateway.Binary: unexpected error: %s", err)
+	}
+
+	a1 = &NATGateway{}
+	err = a1.UnmarshalBinary(bs)
+	if err != nil {
+		t.Fatalf("NATGateway.Binary: unexpected error: %s", err)
+	}
+
+	if !a0.Equal(a1) {
+		t.Fatalf("NATGateway.Binary: expected %v, found %v", a0, a1)
+	}
+}
+
 func TestNode_BinaryEncoding(t *testing.T) {
 	ws := time.Date(2020, time.September, 16, 0, 0, 0, 0, time.UTC)
 	we := ws.Add(24 * time.Hour)
@@ -525,6 +586,38 @@ func TestProperties_BinaryEncoding(t *testing.T) {
 	}
 }
 
+func TestRawAllocationOnlyData_BinaryEncoding(t *testing.T) {
+	var r0, r1 *RawAllocationOnlyData
+	var bs []byte
+	var err error
+
+	r0 = &RawAllocationOnlyData{
+		CPUCoreUsageMax:  2.5,
+		RAMBytesUsageMax: 4.0 * 1024 * 1024 * 1024,
+		CPUCoreUsageP50:  0.5,
+		CPUCoreUsageP95:  1.75,
+		CPUCoreUsageP99:  2.25,
+		RAMBytesUsageP50: 1.0 * 1024 * 1024 * 1024,
+		RAMBytesUsageP95: 3.0 * 1024 * 1024 * 1024,
+		RAMBytesUsageP99: 3.5 * 1024 * 1024 * 1024,
+	}
+
+	bs, err = r0.MarshalBinary()
+	if err != nil {
+		t.Fatalf("RawAllocationOnlyData.Binary: unexpected error: %s", err)
+	}
+
+	r1 = &RawAllocationOnlyData{}
+	err = r1.UnmarshalBinary(bs)
+	if err != nil {
+		t.Fatalf("RawAllocationOnlyData.Binary: unexpected error: %s", err)
+	}
+
+	if !r0.Equal(r1) {
+		t.Fatalf("RawAllocationOnlyData.Binary: expected %v, found %v", r0, r1)
+	}
+}
+
 func TestShared_BinaryEncoding(t *testing.T) {
 	ws := time.Date(2020, time.September, 16, 0, 0, 0, 0, time.UTC)
 	we := ws.Add(24 * time.Hour)
@@ -539,7 +539,7 @@ func (sas *SummaryAllocationSet) AggregateBy(aggregateBy []string, options *Allo
 	}
 
 	if options.LabelConfig == nil {
-		options.LabelConfig = NewLabelConfig()
+		options.LabelConfig = DefaultLabelConfig()
 	}
 
 	// Pre-flatten the filter so we can just check == nil to see if there are
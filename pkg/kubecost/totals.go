@@ -397,6 +397,27 @@ func ComputeAssetTotals(as *AssetSet, prop AssetProperty) map[string]*AssetTotal
 		arts[key].GPUCostAdjustment += gpuCostAdjustment
 	}
 
+	// GPUs itemized as assets of their own are named after the node to which
+	// they are attached, and count towards its GPU cost, rather than as nodes.
+	for _, gpu := range as.GPUs {
+		key := gpu.Properties.Cluster
+		if prop == AssetNodeProp {
+			key = fmt.Sprintf("%s/%s", gpu.Properties.Cluster, gpu.Properties.Name)
+		}
+
+		if _, ok := arts[key]; !ok {
+			arts[key] = &AssetTotals{
+				Start:   gpu.Start,
+				End:     gpu.End,
+				Cluster: gpu.Properties.Cluster,
+				Node:    gpu.Properties.Name,
+			}
+		}
+
+		arts[key].GPUCost += gpu.Cost
+		arts[key].GPUCostAdjustment += gpu.Adjustment
+	}
+
 	// Only record LoadBalancer and ClusterManagement when prop
 	// is cluster. We can't breakdown these types by Node.
 	if prop == AssetClusterProp {
@@ -3,6 +3,7 @@ package kubecost
 import (
 	"math"
 	"testing"
+	"time"
 )
 
 func TestComputeIdleCoefficients(t *testing.T) {
@@ -24,3 +25,47 @@ func TestComputeIdleCoefficients(t *testing.T) {
 		t.Errorf("Idle coefficients should not be NaN or Inf")
 	}
 }
+
+func TestComputeAssetTotals_GPUs(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	window := NewWindow(&start, &end)
+
+	as := NewAssetSet(start, end)
+
+	node := NewNode("node1", "cluster1", "i-1", start, end, window)
+	node.CPUCost = 10.0
+	node.RAMCost = 5.0
+	node.GPUCount = 1.0
+	node.GPUHours = 24.0
+	as.Insert(node, nil)
+
+	// GPUs itemized from the node count towards its GPU cost
+	gpu := NewGPU("node1", "cluster1", "i-1", start, end, window)
+	gpu.Cost = 20.0
+	gpu.GPUHours = 24.0
+	gpu.SetAdjustment(-2.0)
+	as.Insert(gpu, nil)
+
+	for _, prop := range []AssetProperty{AssetClusterProp, AssetNodeProp} {
+		key := "cluster1"
+		if prop == AssetNodeProp {
+			key = "cluster1/node1"
+		}
+
+		totals := ComputeAssetTotals(as, prop)
+		at, ok := totals[key]
+		if !ok {
+			t.Fatalf("%s: expected totals of %s", prop, key)
+		}
+		if at.Count != 1 {
+			t.Errorf("%s: expected a count of 1 node; got %d", prop, at.Count)
+		}
+		if at.GPUCost != 20.0 || at.GPUCostAdjustment != -2.0 {
+			t.Errorf("%s: expected GPU cost of 20.00 adjusted by -2.00; got %.2f adjusted by %.2f", prop, at.GPUCost, at.GPUCostAdjustment)
+		}
+		if at.CPUCost != 10.0 || at.RAMCost != 5.0 {
+			t.Errorf("%s: expected CPU and RAM costs of 10.00 and 5.00; got %.2f and %.2f", prop, at.CPUCost, at.RAMCost)
+		}
+	}
+}
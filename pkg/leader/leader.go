@@ -0,0 +1,264 @@
+package leader
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	"github.com/opencost/opencost/pkg/errors"
+	"github.com/opencost/opencost/pkg/log"
+)
+
+var (
+	isLeaderOnce sync.Once
+	isLeader     prometheus.Gauge
+)
+
+// initMetrics registers the leader election metrics with the default registry.
+func initMetrics() {
+	isLeaderOnce.Do(func() {
+		isLeader = prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "opencost_leader_election_is_leader",
+			Help: "opencost_leader_election_is_leader 1 if this replica holds the leader election lease, 0 otherwise",
+		})
+		prometheus.MustRegister(isLeader)
+	})
+}
+
+// Worker is a background task, such as a metrics emitter or an exporter, run
+// only by the elected leader. Start must be callable again after Stop.
+type Worker interface {
+	Start() bool
+	Stop()
+}
+
+// ElectorConfig contains the options of an Elector.
+type ElectorConfig struct {
+	// Namespace and LeaseName identify the Lease held by the leader.
+	Namespace string
+	LeaseName string
+
+	// Identity identifies this replica as the holder of the Lease, and must be
+	// unique across replicas.
+	Identity string
+
+	// LeaseDuration is how long followers wait before taking over a Lease
+	// which has not been renewed.
+	LeaseDuration time.Duration
+
+	// RenewDeadline is how long the leader retries renewing the Lease before
+	// giving up leadership.
+	RenewDeadline time.Duration
+
+	// RetryPeriod is the duration between attempts to acquire or renew the
+	// Lease.
+	RetryPeriod time.Duration
+}
+
+// Elector elects a leader among the replicas of a deployment through a
+// Kubernetes Lease. The workers added to it run only while this replica is the
+// leader: they are started when leadership is acquired and stopped when it is
+// lost.
+type Elector struct {
+	client kubernetes.Interface
+	config *ElectorConfig
+
+	lock    sync.Mutex
+	leading bool
+	leader  string
+	workers []Worker
+}
+
+// NewElector creates a new Elector. Use Run to begin participating in the
+// election.
+func NewElector(client kubernetes.Interface, config *ElectorConfig) (*Elector, error) {
+	if config.Identity == "" {
+		return nil, fmt.Errorf("leader election requires an identity")
+	}
+	if config.Namespace == "" || config.LeaseName == "" {
+		return nil, fmt.Errorf("leader election requires a lease namespace and name")
+	}
+
+	initMetrics()
+
+	return &Elector{
+		client: client,
+		config: config,
+	}, nil
+}
+
+// Add registers workers run while this replica is the leader. If it is
+// already the leader, they are started immediately.
+func (e *Elector) Add(workers ...Worker) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.workers = append(e.workers, workers...)
+	if e.leading {
+		for _, w := range workers {
+			w.Start()
+		}
+	}
+}
+
+// IsLeader returns true if this replica currently holds the Lease.
+func (e *Elector) IsLeader() bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return e.leading
+}
+
+// Leader returns the identity of the current leader, if known.
+func (e *Elector) Leader() string {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return e.leader
+}
+
+// Run participates in the election until the context is cancelled. After
+// losing leadership, the replica rejoins the election as a candidate. On
+// cancellation, a held Lease is released so that another replica can take
+// over without waiting for it to expire.
+func (e *Elector) Run(ctx context.Context) {
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Namespace: e.config.Namespace,
+			Name:      e.config.LeaseName,
+		},
+		Client: e.client.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: e.config.Identity,
+		},
+	}
+
+	for ctx.Err() == nil {
+		elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+			Lock:            lock,
+			LeaseDuration:   e.config.LeaseDuration,
+			RenewDeadline:   e.config.RenewDeadline,
+			RetryPeriod:     e.config.RetryPeriod,
+			ReleaseOnCancel: true,
+			Name:            e.config.LeaseName,
+			Callbacks: leaderelection.LeaderCallbacks{
+				OnStartedLeading: func(context.Context) { e.startLeading() },
+				OnStoppedLeading: e.stopLeading,
+				OnNewLeader:      e.observeLeader,
+			},
+		})
+		if err != nil {
+			log.Errorf("Leader election: %s", err)
+			return
+		}
+
+		// blocks until leadership is lost or the context is cancelled
+		elector.Run(ctx)
+	}
+}
+
+// Start runs the election in the background until the context is cancelled.
+func (e *Elector) Start(ctx context.Context) {
+	go func() {
+		defer errors.HandlePanic()
+		e.Run(ctx)
+	}()
+}
+
+func (e *Elector) startLeading() {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	log.Infof("Leader election: %s acquired lease %s/%s, starting %d worker(s)", e.config.Identity, e.config.Namespace, e.config.LeaseName, len(e.workers))
+	e.leading = true
+	isLeader.Set(1)
+	for _, w := range e.workers {
+		w.Start()
+	}
+}
+
+func (e *Elector) stopLeading() {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if !e.leading {
+		return
+	}
+
+	log.Infof("Leader election: %s lost lease %s/%s, stopping %d worker(s)", e.config.Identity, e.config.Namespace, e.config.LeaseName, len(e.workers))
+	e.leading = false
+	isLeader.Set(0)
+	for _, w := range e.workers {
+		w.Stop()
+	}
+}
+
+func (e *Elector) observeLeader(identity string) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if identity != e.leader {
+		log.Infof("Leader election: %s is the leader", identity)
+	}
+	e.leader = identity
+}
+
+// FuncWorker adapts a function running until its context is cancelled to a
+// Worker.
+type FuncWorker struct {
+	run func(ctx context.Context)
+
+	lock   sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewFuncWorker creates a Worker running the function in the background from
+// Start until Stop.
+func NewFuncWorker(run func(ctx context.Context)) *FuncWorker {
+	return &FuncWorker{run: run}
+}
+
+// Start runs the function in the background. Returns false if it is already
+// running.
+func (fw *FuncWorker) Start() bool {
+	fw.lock.Lock()
+	defer fw.lock.Unlock()
+
+	if fw.cancel != nil {
+		return false
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	fw.cancel, fw.done = cancel, done
+
+	go func() {
+		defer errors.HandlePanic()
+		defer close(done)
+		fw.run(ctx)
+	}()
+
+	return true
+}
+
+// Stop cancels the function's context and waits for it to return.
+func (fw *FuncWorker) Stop() {
+	fw.lock.Lock()
+	defer fw.lock.Unlock()
+
+	if fw.cancel == nil {
+		return
+	}
+
+	fw.cancel()
+	<-fw.done
+	fw.cancel, fw.done = nil, nil
+}
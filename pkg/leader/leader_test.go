@@ -0,0 +1,110 @@
+package leader
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type mockWorker struct {
+	running int32
+}
+
+func (mw *mockWorker) Start() bool {
+	return atomic.CompareAndSwapInt32(&mw.running, 0, 1)
+}
+
+func (mw *mockWorker) Stop() {
+	atomic.StoreInt32(&mw.running, 0)
+}
+
+func (mw *mockWorker) isRunning() bool {
+	return atomic.LoadInt32(&mw.running) == 1
+}
+
+func waitFor(t *testing.T, condition func() bool, msg string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting: %s", msg)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestElector(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	config := func(identity string) *ElectorConfig {
+		return &ElectorConfig{
+			Namespace:     "opencost",
+			LeaseName:     "opencost-leader",
+			Identity:      identity,
+			LeaseDuration: time.Second,
+			RenewDeadline: 500 * time.Millisecond,
+			RetryPeriod:   100 * time.Millisecond,
+		}
+	}
+
+	first, err := NewElector(client, config("first"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	firstWorker := &mockWorker{}
+	first.Add(firstWorker)
+
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	first.Start(firstCtx)
+	waitFor(t, first.IsLeader, "first replica to lead")
+	if !firstWorker.isRunning() {
+		t.Fatalf("expected the leader's worker to run")
+	}
+
+	second, err := NewElector(client, config("second"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	secondWorker := &mockWorker{}
+	second.Add(secondWorker)
+
+	secondCtx, cancelSecond := context.WithCancel(context.Background())
+	defer cancelSecond()
+	second.Start(secondCtx)
+	waitFor(t, func() bool { return second.Leader() == "first" }, "second replica to observe the leader")
+	if second.IsLeader() || secondWorker.isRunning() {
+		t.Fatalf("expected the follower's worker not to run")
+	}
+
+	// the first replica releases the lease on cancellation
+	cancelFirst()
+	waitFor(t, second.IsLeader, "second replica to take over")
+	waitFor(t, func() bool { return !firstWorker.isRunning() }, "first replica's worker to stop")
+	if !secondWorker.isRunning() {
+		t.Fatalf("expected the new leader's worker to run")
+	}
+}
+
+func TestFuncWorker(t *testing.T) {
+	stopped := make(chan struct{})
+	fw := NewFuncWorker(func(ctx context.Context) {
+		<-ctx.Done()
+		close(stopped)
+	})
+
+	if !fw.Start() {
+		t.Fatalf("expected first start to succeed")
+	}
+	if fw.Start() {
+		t.Fatalf("expected start of a running worker to fail")
+	}
+
+	fw.Stop()
+	select {
+	case <-stopped:
+	default:
+		t.Fatalf("expected Stop to wait for the function to return")
+	}
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/opencost/opencost/pkg/env"
+	"github.com/opencost/opencost/pkg/log"
+)
+
+// Handler returns the /metrics handler for the default registry. It applies
+// the relabel config at METRICS_RELABEL_CONFIG_PATH, if set, and the metric
+// families allowed and denied by METRICS_ALLOW and METRICS_DENY, then the
+// prefix set by METRICS_PREFIX.
+func Handler() http.Handler {
+	var gatherer prometheus.Gatherer = prometheus.DefaultGatherer
+
+	config := &RelabelConfig{}
+	if configPath := env.GetMetricsRelabelConfigPath(); configPath != "" {
+		var err error
+		config, err = LoadRelabelConfig(configPath)
+		if err != nil {
+			log.Errorf("Metrics: %s, emitting metrics without relabeling", err)
+			config = &RelabelConfig{}
+		}
+	}
+	config.AllowMetrics = append(config.AllowMetrics, env.GetMetricsAllow()...)
+	config.DenyMetrics = append(config.DenyMetrics, env.GetMetricsDeny()...)
+
+	if !config.IsEmpty() {
+		rg, err := NewRelabelingGatherer(gatherer, config)
+		if err != nil {
+			log.Errorf("Metrics: %s, emitting metrics without relabeling", err)
+		} else {
+			gatherer = rg
+		}
+	}
+
+	if prefix := env.GetMetricsPrefix(); prefix != "" {
+		// The cost model queries its own metrics, e.g. node_cpu_hourly_cost and
+		// kubecost_node_interruption, by their unprefixed names, so those must
+		// always be emitted alongside the prefixed ones.
+		if !env.IsMetricsPrefixKeepUnprefixed() {
+			log.Warnf("Metrics: %s=false is not supported, the cost model queries its own metrics by their unprefixed names; emitting metrics unprefixed as well as prefixed with '%s'", env.MetricsPrefixKeepUnprefixedEnvVar, prefix)
+		}
+		gatherer = NewPrefixingGatherer(gatherer, prefix, true)
+	}
+
+	if gatherer == prometheus.DefaultGatherer {
+		return promhttp.Handler()
+	}
+	return promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer, promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+}
@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/opencost/opencost/pkg/env"
+)
+
+func TestHandler_PrefixKeepsQueriedNames(t *testing.T) {
+	t.Setenv(env.MetricsPrefixEnvVar, "opencost_")
+	t.Setenv(env.MetricsPrefixKeepUnprefixedEnvVar, "false")
+
+	interruption := prometheus.NewGauge(prometheus.GaugeOpts{Name: "kubecost_node_interruption", Help: "Nodes being preempted or interrupted"})
+	interruption.Set(1)
+	if err := prometheus.Register(interruption); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer prometheus.Unregister(interruption)
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := io.ReadAll(rec.Body)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lines := strings.Split(string(body), "\n")
+	has := func(name string) bool {
+		for _, line := range lines {
+			if strings.HasPrefix(line, name+" ") {
+				return true
+			}
+		}
+		return false
+	}
+
+	// queryFmtNodeInterruptions in pkg/costmodel selects kubecost_node_interruption
+	if !has("kubecost_node_interruption") {
+		t.Errorf("expected kubecost_node_interruption to be emitted unprefixed for the cost model's queries")
+	}
+	if !has("opencost_kubecost_node_interruption") {
+		t.Errorf("expected opencost_kubecost_node_interruption to be emitted")
+	}
+}
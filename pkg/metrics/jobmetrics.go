@@ -1,6 +1,8 @@
 package metrics
 
 import (
+	"strconv"
+
 	"github.com/opencost/opencost/pkg/clustercache"
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
@@ -25,17 +27,20 @@ type KubeJobCollector struct {
 // collected by this Collector.
 func (kjc KubeJobCollector) Describe(ch chan<- *prometheus.Desc) {
 	disabledMetrics := kjc.metricsConfig.GetDisabledMetricsMap()
-	if _, disabled := disabledMetrics["kube_job_status_failed"]; disabled {
-		return
+	if _, disabled := disabledMetrics["kube_job_status_failed"]; !disabled {
+		ch <- prometheus.NewDesc("kube_job_status_failed", "The number of pods which reached Phase Failed and the reason for failure.", []string{}, nil)
+	}
+	if _, disabled := disabledMetrics["kube_job_owner"]; !disabled {
+		ch <- prometheus.NewDesc("kube_job_owner", "Information about the Job's owner", []string{}, nil)
 	}
-
-	ch <- prometheus.NewDesc("kube_job_status_failed", "The number of pods which reached Phase Failed and the reason for failure.", []string{}, nil)
 }
 
 // Collect is called by the Prometheus registry when collecting metrics.
 func (kjc KubeJobCollector) Collect(ch chan<- prometheus.Metric) {
 	disabledMetrics := kjc.metricsConfig.GetDisabledMetricsMap()
-	if _, disabled := disabledMetrics["kube_job_status_failed"]; disabled {
+	_, statusFailedDisabled := disabledMetrics["kube_job_status_failed"]
+	_, ownerDisabled := disabledMetrics["kube_job_owner"]
+	if statusFailedDisabled && ownerDisabled {
 		return
 	}
 
@@ -44,6 +49,20 @@ func (kjc KubeJobCollector) Collect(ch chan<- prometheus.Metric) {
 		jobName := job.GetName()
 		jobNS := job.GetNamespace()
 
+		// kube_job_owner
+		if !ownerDisabled {
+			if len(job.OwnerReferences) == 0 {
+				ch <- newKubeJobOwnerMetric("kube_job_owner", jobNS, jobName, "<none>", "<none>", false)
+			}
+			for _, owner := range job.OwnerReferences {
+				ch <- newKubeJobOwnerMetric("kube_job_owner", jobNS, jobName, owner.Name, owner.Kind, owner.Controller != nil && *owner.Controller)
+			}
+		}
+
+		if statusFailedDisabled {
+			continue
+		}
+
 		if job.Status.Failed == 0 {
 			ch <- newKubeJobStatusFailedMetric(jobName, jobNS, "kube_job_status_failed", "", 0)
 		} else {
@@ -126,3 +145,78 @@ func (kjsf KubeJobStatusFailedMetric) Write(m *dto.Metric) error {
 	}
 	return nil
 }
+
+//--------------------------------------------------------------------------
+//  KubeJobOwnerMetric
+//--------------------------------------------------------------------------
+
+// KubeJobOwnerMetric is a prometheus.Metric used to encode the owner of a Job,
+// which is a CronJob for the Jobs it schedules.
+type KubeJobOwnerMetric struct {
+	fqName            string
+	help              string
+	namespace         string
+	job               string
+	ownerName         string
+	ownerKind         string
+	ownerIsController bool
+}
+
+// Creates a new KubeJobOwnerMetric, implementation of prometheus.Metric
+func newKubeJobOwnerMetric(fqName, namespace, job, ownerName, ownerKind string, ownerIsController bool) KubeJobOwnerMetric {
+	return KubeJobOwnerMetric{
+		fqName:            fqName,
+		help:              "kube_job_owner Information about the Job's owner",
+		namespace:         namespace,
+		job:               job,
+		ownerName:         ownerName,
+		ownerKind:         ownerKind,
+		ownerIsController: ownerIsController,
+	}
+}
+
+// Desc returns the descriptor for the Metric. This method idempotently
+// returns the same descriptor throughout the lifetime of the Metric.
+func (kjo KubeJobOwnerMetric) Desc() *prometheus.Desc {
+	l := prometheus.Labels{
+		"namespace":           kjo.namespace,
+		"job_name":            kjo.job,
+		"owner_name":          kjo.ownerName,
+		"owner_kind":          kjo.ownerKind,
+		"owner_is_controller": strconv.FormatBool(kjo.ownerIsController),
+	}
+	return prometheus.NewDesc(kjo.fqName, kjo.help, []string{}, l)
+}
+
+// Write encodes the Metric into a "Metric" Protocol Buffer data
+// transmission object.
+func (kjo KubeJobOwnerMetric) Write(m *dto.Metric) error {
+	v := float64(1.0)
+	m.Gauge = &dto.Gauge{
+		Value: &v,
+	}
+
+	m.Label = []*dto.LabelPair{
+		{
+			Name:  toStringPtr("namespace"),
+			Value: &kjo.namespace,
+		},
+		{
+			Name:  toStringPtr("job_name"),
+			Value: &kjo.job,
+		},
+		{
+			Name:  toStringPtr("owner_name"),
+			Value: &kjo.ownerName,
+		},
+		{
+			Name:  toStringPtr("owner_kind"),
+			Value: &kjo.ownerKind,
+		},
+		{
+			Name:  toStringPtr("owner_is_controller"),
+			Value: toStringPtr(strconv.FormatBool(kjo.ownerIsController)),
+		},
+	}
+	return nil
+}
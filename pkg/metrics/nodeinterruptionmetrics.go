@@ -0,0 +1,144 @@
+package metrics
+
+import (
+	"github.com/opencost/opencost/pkg/clustercache"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	v1 "k8s.io/api/core/v1"
+)
+
+// interruptionTaints maps the taints applied to nodes on notice of a preemption
+// or spot interruption, by the provider or its termination handler, to the
+// reason reported for the interruption.
+var interruptionTaints = map[string]string{
+	"aws-node-termination-handler/spot-itn":              "SpotInterruption",
+	"aws-node-termination-handler/scheduled-maintenance": "ScheduledMaintenance",
+	"cloud.google.com/impending-node-termination":        "Preemption",
+}
+
+// interruptionConditions are the node conditions reporting a preemption or
+// spot interruption, as set from provider scheduled events by the node
+// problem detector.
+var interruptionConditions = map[v1.NodeConditionType]string{
+	"PreemptScheduled":   "Preemption",
+	"TerminateScheduled": "ScheduledTermination",
+}
+
+// NodeInterruptionReason returns the reason a node is being interrupted, if it
+// shows any of the known signals of a preemption or spot interruption.
+func NodeInterruptionReason(node *v1.Node) (string, bool) {
+	for _, c := range node.Status.Conditions {
+		if reason, ok := interruptionConditions[c.Type]; ok && c.Status == v1.ConditionTrue {
+			return reason, true
+		}
+	}
+
+	for _, taint := range node.Spec.Taints {
+		if reason, ok := interruptionTaints[taint.Key]; ok {
+			return reason, true
+		}
+	}
+
+	return "", false
+}
+
+//--------------------------------------------------------------------------
+//  KubecostNodeInterruptionCollector
+//--------------------------------------------------------------------------
+
+// KubecostNodeInterruptionCollector is a prometheus collector that generates
+// a metric for each node being preempted or interrupted.
+type KubecostNodeInterruptionCollector struct {
+	KubeClusterCache clustercache.ClusterCache
+	metricsConfig    MetricsConfig
+}
+
+// Describe sends the super-set of all possible descriptors of metrics
+// collected by this Collector.
+func (nic KubecostNodeInterruptionCollector) Describe(ch chan<- *prometheus.Desc) {
+	disabledMetrics := nic.metricsConfig.GetDisabledMetricsMap()
+	if _, disabled := disabledMetrics["kubecost_node_interruption"]; disabled {
+		return
+	}
+
+	ch <- prometheus.NewDesc("kubecost_node_interruption", "Nodes being preempted or interrupted", []string{}, nil)
+}
+
+// Collect is called by the Prometheus registry when collecting metrics.
+func (nic KubecostNodeInterruptionCollector) Collect(ch chan<- prometheus.Metric) {
+	disabledMetrics := nic.metricsConfig.GetDisabledMetricsMap()
+	if _, disabled := disabledMetrics["kubecost_node_interruption"]; disabled {
+		return
+	}
+
+	for _, node := range nic.KubeClusterCache.GetAllNodes() {
+		reason, ok := NodeInterruptionReason(node)
+		if !ok {
+			continue
+		}
+
+		ch <- newNodeInterruptionMetric("kubecost_node_interruption", node.GetName(), node.Spec.ProviderID, reason)
+	}
+}
+
+//--------------------------------------------------------------------------
+//  NodeInterruptionMetric
+//--------------------------------------------------------------------------
+
+// NodeInterruptionMetric is a prometheus.Metric used to encode the
+// interruption of a node
+type NodeInterruptionMetric struct {
+	fqName     string
+	help       string
+	node       string
+	providerID string
+	reason     string
+}
+
+// Creates a new NodeInterruptionMetric, implementation of prometheus.Metric
+func newNodeInterruptionMetric(fqName, node, providerID, reason string) NodeInterruptionMetric {
+	return NodeInterruptionMetric{
+		fqName:     fqName,
+		help:       "kubecost_node_interruption Node being preempted or interrupted",
+		node:       node,
+		providerID: providerID,
+		reason:     reason,
+	}
+}
+
+// Desc returns the descriptor for the Metric. This method idempotently
+// returns the same descriptor throughout the lifetime of the Metric.
+func (nim NodeInterruptionMetric) Desc() *prometheus.Desc {
+	l := prometheus.Labels{
+		"node":        nim.node,
+		"provider_id": nim.providerID,
+		"reason":      nim.reason,
+	}
+	return prometheus.NewDesc(nim.fqName, nim.help, []string{}, l)
+}
+
+// Write encodes the Metric into a "Metric" Protocol Buffer data
+// transmission object.
+func (nim NodeInterruptionMetric) Write(m *dto.Metric) error {
+	v := float64(1.0)
+	m.Gauge = &dto.Gauge{
+		Value: &v,
+	}
+
+	m.Label = []*dto.LabelPair{
+		{
+			Name:  toStringPtr("node"),
+			Value: &nim.node,
+		},
+		{
+			Name:  toStringPtr("provider_id"),
+			Value: &nim.providerID,
+		},
+		{
+			Name:  toStringPtr("reason"),
+			Value: &nim.reason,
+		},
+	}
+	return nil
+}
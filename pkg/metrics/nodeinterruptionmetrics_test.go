@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestNodeInterruptionReason(t *testing.T) {
+	cases := map[string]struct {
+		node     *v1.Node
+		reason   string
+		expected bool
+	}{
+		"no signal": {
+			node: &v1.Node{
+				Status: v1.NodeStatus{Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}}},
+			},
+		},
+		"spot interruption taint": {
+			node: &v1.Node{
+				Spec: v1.NodeSpec{Taints: []v1.Taint{{Key: "aws-node-termination-handler/spot-itn", Effect: v1.TaintEffectNoSchedule}}},
+			},
+			reason:   "SpotInterruption",
+			expected: true,
+		},
+		"preemption condition": {
+			node: &v1.Node{
+				Status: v1.NodeStatus{Conditions: []v1.NodeCondition{{Type: "PreemptScheduled", Status: v1.ConditionTrue}}},
+			},
+			reason:   "Preemption",
+			expected: true,
+		},
+		"cleared preemption condition": {
+			node: &v1.Node{
+				Status: v1.NodeStatus{Conditions: []v1.NodeCondition{{Type: "PreemptScheduled", Status: v1.ConditionFalse}}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			reason, ok := NodeInterruptionReason(tc.node)
+			if ok != tc.expected || reason != tc.reason {
+				t.Errorf("expected (%q, %t); got (%q, %t)", tc.reason, tc.expected, reason, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// unprefixedMetrics are the prefixes of the Go runtime, process and handler
+// metrics registered by the Prometheus client, which are never prefixed.
+var unprefixedMetrics = []string{"go_", "process_", "promhttp_"}
+
+// PrefixingGatherer prefixes the names of the metric families gathered from
+// another Gatherer, e.g. to avoid collisions with a Kubecost installation in
+// the same cluster. During migration, each family can be emitted both with
+// and without the prefix.
+type PrefixingGatherer struct {
+	gatherer       prometheus.Gatherer
+	prefix         string
+	keepUnprefixed bool
+}
+
+// NewPrefixingGatherer creates a new PrefixingGatherer. If keepUnprefixed is
+// true, each prefixed family is duplicated under its original name.
+func NewPrefixingGatherer(gatherer prometheus.Gatherer, prefix string, keepUnprefixed bool) *PrefixingGatherer {
+	return &PrefixingGatherer{
+		gatherer:       gatherer,
+		prefix:         prefix,
+		keepUnprefixed: keepUnprefixed,
+	}
+}
+
+// Gather gathers the metric families and prefixes their names.
+func (pg *PrefixingGatherer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := pg.gatherer.Gather()
+
+	result := make([]*dto.MetricFamily, 0, len(families))
+	for _, mf := range families {
+		name := mf.GetName()
+		if !pg.prefixes(name) {
+			result = append(result, mf)
+			continue
+		}
+
+		prefixed := &dto.MetricFamily{
+			Name:   toStringPtr(pg.prefix + name),
+			Help:   mf.Help,
+			Type:   mf.Type,
+			Metric: mf.Metric,
+		}
+		if mf.Help != nil {
+			prefixed.Help = toStringPtr(strings.Replace(mf.GetHelp(), name, pg.prefix+name, 1))
+		}
+		result = append(result, prefixed)
+
+		if pg.keepUnprefixed {
+			result = append(result, mf)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].GetName() < result[j].GetName()
+	})
+	return result, err
+}
+
+func (pg *PrefixingGatherer) prefixes(name string) bool {
+	if strings.HasPrefix(name, pg.prefix) {
+		return false
+	}
+	for _, p := range unprefixedMetrics {
+		if strings.HasPrefix(name, p) {
+			return false
+		}
+	}
+	return true
+}
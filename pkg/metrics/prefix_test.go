@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+func TestPrefixingGatherer(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector())
+	cost := prometheus.NewGauge(prometheus.GaugeOpts{Name: "node_total_hourly_cost", Help: "node_total_hourly_cost Total node cost per hour"})
+	cost.Set(1)
+	registry.MustRegister(cost)
+
+	names := func(keepUnprefixed bool) map[string]string {
+		families, err := NewPrefixingGatherer(registry, "opencost_", keepUnprefixed).Gather()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		result := make(map[string]string, len(families))
+		for _, mf := range families {
+			result[mf.GetName()] = mf.GetHelp()
+		}
+		return result
+	}
+
+	prefixed := names(false)
+	if help, ok := prefixed["opencost_node_total_hourly_cost"]; !ok || help != "opencost_node_total_hourly_cost Total node cost per hour" {
+		t.Errorf("expected prefixed family with prefixed help; got %q", help)
+	}
+	if _, ok := prefixed["node_total_hourly_cost"]; ok {
+		t.Errorf("expected unprefixed family to be removed")
+	}
+	if _, ok := prefixed["go_goroutines"]; !ok {
+		t.Errorf("expected runtime metrics not to be prefixed")
+	}
+
+	duplicated := names(true)
+	for _, name := range []string{"opencost_node_total_hourly_cost", "node_total_hourly_cost"} {
+		if _, ok := duplicated[name]; !ok {
+			t.Errorf("expected %s to be emitted", name)
+		}
+	}
+}
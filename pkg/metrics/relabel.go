@@ -0,0 +1,248 @@
+package metrics
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/opencost/opencost/pkg/util/json"
+)
+
+// Reasons for which series are dropped by a RelabelingGatherer
+const (
+	DropReasonNamespace   = "namespace"
+	DropReasonDuplicate   = "duplicate"
+	DropReasonSeriesLimit = "series_limit"
+)
+
+// droppedSeriesMetric counts the series dropped from each scrape
+const droppedSeriesMetric = "opencost_metrics_dropped_series_total"
+
+var (
+	droppedSeriesOnce sync.Once
+	droppedSeries     *prometheus.CounterVec
+)
+
+// RelabelConfig controls which metrics are emitted and their cardinality.
+type RelabelConfig struct {
+	// AllowMetrics, if set, are the only metric families emitted. Entries are
+	// names or glob patterns, e.g. "node_*_hourly_cost".
+	AllowMetrics []string `json:"allowMetrics,omitempty"`
+
+	// DenyMetrics are metric families which are not emitted, even if allowed.
+	// Entries are names or glob patterns, e.g. "kube_pod_annotations".
+	DenyMetrics []string `json:"denyMetrics,omitempty"`
+
+	// DropLabels are regular expressions matching the label names removed from
+	// every series, e.g. "^label_pod_template_hash$" or "^annotation_".
+	DropLabels []string `json:"dropLabels,omitempty"`
+
+	// Namespaces, if set, are the only namespaces whose series are emitted.
+	// Series without a namespace label are unaffected.
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// MaxSeriesPerMetric caps the number of series emitted per metric family.
+	// Series are unlimited if zero.
+	MaxSeriesPerMetric int `json:"maxSeriesPerMetric,omitempty"`
+
+	// MaxSeries overrides MaxSeriesPerMetric for individual metric families.
+	MaxSeries map[string]int `json:"maxSeries,omitempty"`
+}
+
+// LoadRelabelConfig reads a JSON RelabelConfig from a file.
+func LoadRelabelConfig(file string) (*RelabelConfig, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading relabel config: %w", err)
+	}
+
+	config := &RelabelConfig{}
+	if err := json.Unmarshal(b, config); err != nil {
+		return nil, fmt.Errorf("parsing relabel config: %w", err)
+	}
+
+	return config, nil
+}
+
+// IsEmpty returns true if the config has no effect.
+func (rc *RelabelConfig) IsEmpty() bool {
+	return len(rc.AllowMetrics) == 0 && len(rc.DenyMetrics) == 0 && len(rc.DropLabels) == 0 &&
+		len(rc.Namespaces) == 0 && rc.MaxSeriesPerMetric == 0 && len(rc.MaxSeries) == 0
+}
+
+// RelabelingGatherer applies a RelabelConfig to the metric families gathered
+// from another Gatherer, counting the series it drops.
+type RelabelingGatherer struct {
+	gatherer   prometheus.Gatherer
+	config     *RelabelConfig
+	dropLabels []*regexp.Regexp
+	namespaces map[string]struct{}
+	dropped    *prometheus.CounterVec
+}
+
+// NewRelabelingGatherer creates a new RelabelingGatherer. Dropped series are
+// counted by the opencost_metrics_dropped_series_total counter, which is
+// registered with the default registry.
+func NewRelabelingGatherer(gatherer prometheus.Gatherer, config *RelabelConfig) (*RelabelingGatherer, error) {
+	rg := &RelabelingGatherer{
+		gatherer: gatherer,
+		config:   config,
+	}
+
+	for _, pattern := range append(append([]string{}, config.AllowMetrics...), config.DenyMetrics...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid metric pattern '%s': %w", pattern, err)
+		}
+	}
+
+	for _, expr := range config.DropLabels {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid drop label expression '%s': %w", expr, err)
+		}
+		rg.dropLabels = append(rg.dropLabels, re)
+	}
+
+	if len(config.Namespaces) > 0 {
+		rg.namespaces = make(map[string]struct{}, len(config.Namespaces))
+		for _, ns := range config.Namespaces {
+			rg.namespaces[ns] = struct{}{}
+		}
+	}
+
+	droppedSeriesOnce.Do(func() {
+		droppedSeries = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: droppedSeriesMetric,
+			Help: "opencost_metrics_dropped_series_total Number of series dropped from scrapes by relabeling",
+		}, []string{"metric", "reason"})
+		prometheus.MustRegister(droppedSeries)
+	})
+	rg.dropped = droppedSeries
+
+	return rg, nil
+}
+
+// Gather gathers the metric families and applies the relabel config.
+func (rg *RelabelingGatherer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := rg.gatherer.Gather()
+
+	kept := families[:0]
+	for _, mf := range families {
+		if !rg.Emits(mf.GetName()) {
+			continue
+		}
+
+		// never relabel the dropped series counter itself
+		if mf.GetName() != droppedSeriesMetric {
+			rg.relabel(mf)
+		}
+		kept = append(kept, mf)
+	}
+	return kept, err
+}
+
+// Emits returns true if the metric family is allowed and not denied.
+func (rg *RelabelingGatherer) Emits(name string) bool {
+	if len(rg.config.AllowMetrics) > 0 && !matchesAny(rg.config.AllowMetrics, name) {
+		return false
+	}
+	return !matchesAny(rg.config.DenyMetrics, name)
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// maxSeries returns the series limit of the family, or zero if unlimited.
+func (rg *RelabelingGatherer) maxSeries(name string) int {
+	if limit, ok := rg.config.MaxSeries[name]; ok {
+		return limit
+	}
+	return rg.config.MaxSeriesPerMetric
+}
+
+func (rg *RelabelingGatherer) relabel(mf *dto.MetricFamily) {
+	name := mf.GetName()
+	limit := rg.maxSeries(name)
+
+	seen := make(map[string]struct{}, len(mf.Metric))
+	kept := mf.Metric[:0]
+	for _, m := range mf.Metric {
+		if rg.namespaces != nil && !rg.allowsNamespace(m) {
+			rg.dropped.WithLabelValues(name, DropReasonNamespace).Inc()
+			continue
+		}
+
+		if len(rg.dropLabels) > 0 {
+			m.Label = rg.filterLabels(m.Label)
+
+			// removing labels may make series indistinguishable
+			sig := signature(m.Label)
+			if _, ok := seen[sig]; ok {
+				rg.dropped.WithLabelValues(name, DropReasonDuplicate).Inc()
+				continue
+			}
+			seen[sig] = struct{}{}
+		}
+
+		if limit > 0 && len(kept) >= limit {
+			rg.dropped.WithLabelValues(name, DropReasonSeriesLimit).Inc()
+			continue
+		}
+
+		kept = append(kept, m)
+	}
+	mf.Metric = kept
+}
+
+func (rg *RelabelingGatherer) allowsNamespace(m *dto.Metric) bool {
+	for _, lp := range m.Label {
+		if lp.GetName() == "namespace" {
+			_, ok := rg.namespaces[lp.GetValue()]
+			return ok
+		}
+	}
+	return true
+}
+
+func (rg *RelabelingGatherer) filterLabels(labels []*dto.LabelPair) []*dto.LabelPair {
+	kept := labels[:0]
+	for _, lp := range labels {
+		if !rg.dropsLabel(lp.GetName()) {
+			kept = append(kept, lp)
+		}
+	}
+	return kept
+}
+
+func (rg *RelabelingGatherer) dropsLabel(name string) bool {
+	for _, re := range rg.dropLabels {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// signature uniquely identifies a series by its sorted labels
+func signature(labels []*dto.LabelPair) string {
+	var sb strings.Builder
+	for _, lp := range labels {
+		sb.WriteString(lp.GetName())
+		sb.WriteByte('=')
+		sb.WriteString(lp.GetValue())
+		sb.WriteByte(0)
+	}
+	return sb.String()
+}
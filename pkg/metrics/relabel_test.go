@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestRelabelingGatherer(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	labels := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "kube_pod_labels"}, []string{"namespace", "pod", "label_pod_template_hash"})
+	registry.MustRegister(labels)
+
+	labels.WithLabelValues("ns1", "pod1", "a").Set(1)
+	labels.WithLabelValues("ns1", "pod2", "b").Set(1)
+	labels.WithLabelValues("ns1", "pod2", "c").Set(1)
+	labels.WithLabelValues("ns1", "pod3", "d").Set(1)
+	labels.WithLabelValues("ns2", "pod4", "e").Set(1)
+
+	rg, err := NewRelabelingGatherer(registry, &RelabelConfig{
+		DropLabels: []string{"^label_pod_template_hash$"},
+		Namespaces: []string{"ns1"},
+		MaxSeries:  map[string]int{"kube_pod_labels": 2},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	families, err := rg.Gather()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(families) != 1 {
+		t.Fatalf("expected 1 family; got %d", len(families))
+	}
+
+	// ns2 is not allowed, pod2 collapses into one series once the hash is
+	// dropped, and pod3 exceeds the series limit
+	series := families[0].Metric
+	if len(series) != 2 {
+		t.Fatalf("expected 2 series; got %d", len(series))
+	}
+	for _, m := range series {
+		for _, lp := range m.Label {
+			if lp.GetName() == "label_pod_template_hash" {
+				t.Errorf("expected label_pod_template_hash to be dropped")
+			}
+		}
+	}
+}
+
+func TestRelabelingGatherer_Emits(t *testing.T) {
+	rg, err := NewRelabelingGatherer(prometheus.NewRegistry(), &RelabelConfig{
+		AllowMetrics: []string{"node_*_hourly_cost", "kube_pod_*"},
+		DenyMetrics:  []string{"kube_pod_annotations"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	cases := map[string]bool{
+		"node_total_hourly_cost":   true,
+		"node_cpu_hourly_cost":     true,
+		"kube_pod_labels":          true,
+		"kube_pod_annotations":     false,
+		"container_cpu_allocation": false,
+	}
+	for name, expected := range cases {
+		if actual := rg.Emits(name); actual != expected {
+			t.Errorf("%s: expected %t; got %t", name, expected, actual)
+		}
+	}
+
+	if _, err := NewRelabelingGatherer(prometheus.NewRegistry(), &RelabelConfig{DenyMetrics: []string{"["}}); err == nil {
+		t.Errorf("expected an error for an invalid pattern")
+	}
+}
@@ -0,0 +1,180 @@
+package prom
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	prometheus "github.com/prometheus/client_golang/api"
+)
+
+// timeParam is the evaluation time of an instant query, which is the time of
+// the request unless set explicitly.
+const timeParam = "time"
+
+// CapturedResponse is the raw response of a Prometheus request.
+type CapturedResponse struct {
+	Path       string `json:"path"`
+	Params     string `json:"params"`
+	StatusCode int    `json:"statusCode"`
+	Body       string `json:"body"`
+}
+
+// Capture is the set of raw Prometheus responses received by a
+// CapturingClient, which a ReplayClient serves in place of Prometheus.
+type Capture struct {
+	Responses []*CapturedResponse `json:"responses"`
+}
+
+// endpointPath trims any prefix, such as that of a Prometheus served under a
+// sub-path, from the path of a request to the API.
+func endpointPath(p string) string {
+	if i := strings.Index(p, "/api/"); i > 0 {
+		return p[i:]
+	}
+	return p
+}
+
+// requestKey identifies a request by its path and parameters, in canonical
+// order. If withoutTime is true, the evaluation time is excluded, so that
+// instant queries evaluated at the current time can be matched on replay.
+func requestKey(path string, params url.Values, withoutTime bool) string {
+	if withoutTime && params.Has(timeParam) {
+		trimmed := make(url.Values, len(params))
+		for k, v := range params {
+			if k != timeParam {
+				trimmed[k] = v
+			}
+		}
+		params = trimmed
+	}
+	return path + "?" + params.Encode()
+}
+
+// CapturingClient is a prometheus.Client which records the raw response of
+// each request it passes through to the wrapped client.
+type CapturingClient struct {
+	client prometheus.Client
+
+	lock      sync.Mutex
+	responses map[string]*CapturedResponse
+}
+
+// NewCapturingClient creates a client recording the responses of the client.
+func NewCapturingClient(client prometheus.Client) *CapturingClient {
+	return &CapturingClient{
+		client:    client,
+		responses: make(map[string]*CapturedResponse),
+	}
+}
+
+// URL passes through to the wrapped client.
+func (cc *CapturingClient) URL(ep string, args map[string]string) *url.URL {
+	return cc.client.URL(ep, args)
+}
+
+// Do passes the request through to the wrapped client, recording the response
+// if one was received.
+func (cc *CapturingClient) Do(ctx context.Context, req *http.Request) (*http.Response, []byte, error) {
+	res, body, err := cc.client.Do(ctx, req)
+	if res == nil {
+		return res, body, err
+	}
+
+	path, params := endpointPath(req.URL.Path), req.URL.Query()
+	cc.lock.Lock()
+	cc.responses[requestKey(path, params, false)] = &CapturedResponse{
+		Path:       path,
+		Params:     params.Encode(),
+		StatusCode: res.StatusCode,
+		Body:       string(body),
+	}
+	cc.lock.Unlock()
+
+	return res, body, err
+}
+
+// Capture returns the responses recorded so far, in canonical order.
+func (cc *CapturingClient) Capture() *Capture {
+	cc.lock.Lock()
+	defer cc.lock.Unlock()
+
+	keys := make([]string, 0, len(cc.responses))
+	for k := range cc.responses {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	capture := &Capture{Responses: make([]*CapturedResponse, 0, len(keys))}
+	for _, k := range keys {
+		capture.Responses = append(capture.Responses, cc.responses[k])
+	}
+	return capture
+}
+
+// ReplayClient is a prometheus.Client which serves the responses of a Capture
+// rather than querying Prometheus. Requests absent from the capture fail.
+type ReplayClient struct {
+	base      *url.URL
+	responses map[string]*CapturedResponse
+	untimed   map[string]*CapturedResponse
+}
+
+// NewReplayClient creates a client replaying the captured responses.
+func NewReplayClient(capture *Capture) (*ReplayClient, error) {
+	rc := &ReplayClient{
+		base:      &url.URL{Scheme: "http", Host: "replay"},
+		responses: make(map[string]*CapturedResponse, len(capture.Responses)),
+		untimed:   make(map[string]*CapturedResponse, len(capture.Responses)),
+	}
+
+	for _, r := range capture.Responses {
+		params, err := url.ParseQuery(r.Params)
+		if err != nil {
+			return nil, fmt.Errorf("parsing captured params of %s: %w", r.Path, err)
+		}
+		rc.responses[requestKey(r.Path, params, false)] = r
+		rc.untimed[requestKey(r.Path, params, true)] = r
+	}
+
+	return rc, nil
+}
+
+// URL returns the URL of the endpoint on a placeholder host.
+func (rc *ReplayClient) URL(ep string, args map[string]string) *url.URL {
+	p := ep
+	for k, v := range args {
+		p = strings.ReplaceAll(p, ":"+k, url.PathEscape(v))
+	}
+
+	u := *rc.base
+	u.Path = p
+	return &u
+}
+
+// Do returns the captured response of the request, matched by its path and
+// parameters, falling back to ignoring the evaluation time of instant queries.
+func (rc *ReplayClient) Do(_ context.Context, req *http.Request) (*http.Response, []byte, error) {
+	path, params := endpointPath(req.URL.Path), req.URL.Query()
+
+	r, ok := rc.responses[requestKey(path, params, false)]
+	if !ok {
+		r, ok = rc.untimed[requestKey(path, params, true)]
+	}
+	if !ok {
+		return nil, nil, fmt.Errorf("no captured response for %s?%s", path, params.Encode())
+	}
+
+	res := &http.Response{
+		StatusCode: r.StatusCode,
+		Status:     fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		Header:     make(http.Header),
+		Body:       http.NoBody,
+		Request:    req,
+	}
+	return res, []byte(r.Body), nil
+}
@@ -0,0 +1,92 @@
+package prom
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/pkg/util/json"
+)
+
+// echoPromClient responds to each query with a vector naming the query, as a
+// Prometheus served under a sub-path would.
+type echoPromClient struct {
+	requests int
+}
+
+func (epc *echoPromClient) URL(ep string, args map[string]string) *url.URL {
+	return &url.URL{Scheme: "http", Host: "prometheus", Path: "/prometheus" + ep}
+}
+
+func (epc *echoPromClient) Do(_ context.Context, req *http.Request) (*http.Response, []byte, error) {
+	epc.requests++
+	body := fmt.Sprintf(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"query":%q},"value":[0,"1"]}]}}`, req.URL.Query().Get("query"))
+	return &http.Response{StatusCode: http.StatusOK}, []byte(body), nil
+}
+
+func TestCaptureReplay(t *testing.T) {
+	at := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	queries := []string{`up`, `sum(container_cpu_usage_seconds_total) by (namespace)`}
+
+	echo := &echoPromClient{}
+	capturing := NewCapturingClient(echo)
+	captureCtx := NewNamedContext(capturing, AllocationContextName)
+
+	expected := make(map[string]string)
+	for _, q := range queries {
+		body, err := captureCtx.RawQuery(q, at)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		expected[q] = string(body)
+	}
+
+	// the capture survives serialization
+	b, err := json.Marshal(capturing.Capture())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	capture := &Capture{}
+	if err := json.Unmarshal(b, capture); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(capture.Responses) != len(queries) {
+		t.Fatalf("expected %d captured responses; got %d", len(queries), len(capture.Responses))
+	}
+	for _, r := range capture.Responses {
+		if !strings.HasPrefix(r.Path, "/api/") {
+			t.Errorf("expected path without sub-path prefix; got %s", r.Path)
+		}
+	}
+
+	replay, err := NewReplayClient(capture)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	replayCtx := NewNamedContext(replay, AllocationContextName)
+
+	for _, q := range queries {
+		// an instant query evaluated at another time matches regardless
+		for _, ts := range []time.Time{at, at.Add(time.Hour)} {
+			body, err := replayCtx.RawQuery(q, ts)
+			if err != nil {
+				t.Fatalf("unexpected error replaying %s: %s", q, err)
+			}
+			if string(body) != expected[q] {
+				t.Errorf("%s: expected %s; got %s", q, expected[q], body)
+			}
+		}
+	}
+
+	if echo.requests != len(queries) {
+		t.Errorf("expected replay not to query Prometheus; got %d requests", echo.requests)
+	}
+
+	if _, err := replayCtx.RawQuery(`absent(up)`, at); err == nil {
+		t.Errorf("expected error replaying a query absent from the capture")
+	}
+}
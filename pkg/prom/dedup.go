@@ -0,0 +1,249 @@
+package prom
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/opencost/opencost/pkg/env"
+	"github.com/opencost/opencost/pkg/log"
+	"github.com/opencost/opencost/pkg/util"
+)
+
+// DedupPolicy determines how the values of duplicate series are collapsed.
+type DedupPolicy string
+
+const (
+	// DedupMax keeps the largest of the duplicate values at each timestamp.
+	DedupMax DedupPolicy = "max"
+
+	// DedupAvg keeps the average of the duplicate values at each timestamp.
+	DedupAvg DedupPolicy = "avg"
+)
+
+// ParseDedupPolicy returns the DedupPolicy of the string, or an error if it is
+// not a known policy.
+func ParseDedupPolicy(s string) (DedupPolicy, error) {
+	switch DedupPolicy(strings.ToLower(strings.TrimSpace(s))) {
+	case DedupMax:
+		return DedupMax, nil
+	case DedupAvg:
+		return DedupAvg, nil
+	}
+	return "", fmt.Errorf("unknown dedup policy '%s'; expected max or avg", s)
+}
+
+// dedupRule applies its policy to the queries containing the metric.
+type dedupRule struct {
+	metric string
+	policy DedupPolicy
+}
+
+// ResultDeduper collapses the series of query results which are identical
+// except for a set of ignored labels, as returned when the same target is
+// scraped by more than one job. Only the results of queries which retain the
+// ignored labels are deduplicated. Queries which aggregate them away, e.g. by
+// sum by (namespace, pod), have already summed the duplicate series into one
+// before their results are returned, so must be deduplicated in PromQL.
+type ResultDeduper struct {
+	ignoreLabels  map[string]bool
+	defaultPolicy DedupPolicy
+	rules         []dedupRule
+}
+
+// NewResultDeduper creates a ResultDeduper ignoring the labels, which applies
+// the policy of the first rule whose metric the query contains, or the default
+// policy. Rules are of the form metric=policy.
+func NewResultDeduper(ignoreLabels []string, defaultPolicy DedupPolicy, rules []string) (*ResultDeduper, error) {
+	rd := &ResultDeduper{
+		ignoreLabels:  make(map[string]bool, len(ignoreLabels)),
+		defaultPolicy: defaultPolicy,
+	}
+	for _, label := range ignoreLabels {
+		rd.ignoreLabels[strings.TrimSpace(label)] = true
+	}
+
+	for _, rule := range rules {
+		metric, p, ok := strings.Cut(rule, "=")
+		if !ok || strings.TrimSpace(metric) == "" {
+			return nil, fmt.Errorf("invalid dedup rule '%s'; expected metric=policy", rule)
+		}
+		policy, err := ParseDedupPolicy(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dedup rule '%s': %w", rule, err)
+		}
+		rd.rules = append(rd.rules, dedupRule{metric: strings.TrimSpace(metric), policy: policy})
+	}
+
+	return rd, nil
+}
+
+// policyFor returns the policy applied to the results of the query.
+func (rd *ResultDeduper) policyFor(query string) DedupPolicy {
+	for _, rule := range rd.rules {
+		if strings.Contains(query, rule.metric) {
+			return rule.policy
+		}
+	}
+	return rd.defaultPolicy
+}
+
+// key identifies the series of the metric regardless of the ignored labels.
+func (rd *ResultDeduper) key(metric map[string]interface{}) string {
+	names := make([]string, 0, len(metric))
+	for name := range metric {
+		if !rd.ignoreLabels[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&sb, "%s=%v,", name, metric[name])
+	}
+	return sb.String()
+}
+
+// Dedup collapses the duplicate series of the results of the query, returning
+// the results in the order each series was first seen. Collapsed series keep
+// the labels of the first, and the values of each timestamp are collapsed by
+// the policy of the query.
+func (rd *ResultDeduper) Dedup(query string, results []*QueryResult) []*QueryResult {
+	if len(results) < 2 {
+		return results
+	}
+
+	groups := make(map[string][]*QueryResult, len(results))
+	var keys []string
+	for _, result := range results {
+		key := rd.key(result.Metric)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], result)
+	}
+
+	if len(keys) == len(results) {
+		return results
+	}
+
+	policy := rd.policyFor(query)
+	log.DedupedWarningf(5, "Prometheus: collapsed %d duplicate series by %s for query: %s", len(results)-len(keys), policy, query)
+
+	deduped := make([]*QueryResult, 0, len(keys))
+	for _, key := range keys {
+		group := groups[key]
+		if len(group) == 1 {
+			deduped = append(deduped, group[0])
+			continue
+		}
+		deduped = append(deduped, &QueryResult{
+			Metric: group[0].Metric,
+			Values: collapseValues(group, policy),
+		})
+	}
+	return deduped
+}
+
+// collapseValues merges the values of the series by timestamp, collapsing the
+// values sharing a timestamp by the policy.
+func collapseValues(series []*QueryResult, policy DedupPolicy) []*util.Vector {
+	type collapsed struct {
+		value float64
+		count int
+	}
+
+	byTimestamp := map[float64]*collapsed{}
+	var timestamps []float64
+	for _, s := range series {
+		for _, v := range s.Values {
+			c, ok := byTimestamp[v.Timestamp]
+			if !ok {
+				byTimestamp[v.Timestamp] = &collapsed{value: v.Value, count: 1}
+				timestamps = append(timestamps, v.Timestamp)
+				continue
+			}
+			switch policy {
+			case DedupAvg:
+				c.value += v.Value
+			default:
+				if v.Value > c.value {
+					c.value = v.Value
+				}
+			}
+			c.count++
+		}
+	}
+	sort.Float64s(timestamps)
+
+	values := make([]*util.Vector, 0, len(timestamps))
+	for _, ts := range timestamps {
+		c := byTimestamp[ts]
+		value := c.value
+		if policy == DedupAvg {
+			value /= float64(c.count)
+		}
+		values = append(values, &util.Vector{Timestamp: ts, Value: value})
+	}
+	return values
+}
+
+var (
+	resultDeduperOnce sync.Once
+	resultDeduperLock sync.RWMutex
+	resultDeduper     *ResultDeduper
+)
+
+// SetResultDeduper sets the ResultDeduper collapsing the duplicate series of
+// every query result in place of the one configured by the environment. A nil
+// deduper disables deduplication.
+func SetResultDeduper(rd *ResultDeduper) {
+	resultDeduperOnce.Do(func() {})
+
+	resultDeduperLock.Lock()
+	defer resultDeduperLock.Unlock()
+
+	resultDeduper = rd
+}
+
+// currentResultDeduper returns the ResultDeduper collapsing the duplicate
+// series of every query result, which is configured by the environment unless
+// set, or nil if deduplication is disabled.
+func currentResultDeduper() *ResultDeduper {
+	resultDeduperOnce.Do(func() {
+		rd := newResultDeduper()
+
+		resultDeduperLock.Lock()
+		defer resultDeduperLock.Unlock()
+
+		resultDeduper = rd
+	})
+
+	resultDeduperLock.RLock()
+	defer resultDeduperLock.RUnlock()
+
+	return resultDeduper
+}
+
+// newResultDeduper creates the ResultDeduper configured by the environment, or
+// returns nil if deduplication is disabled or misconfigured.
+func newResultDeduper() *ResultDeduper {
+	if !env.IsPrometheusDedupEnabled() {
+		return nil
+	}
+
+	policy, err := ParseDedupPolicy(env.GetPrometheusDedupDefaultPolicy())
+	if err != nil {
+		log.Errorf("Prometheus: invalid $%s: %s; defaulting to max", env.PrometheusDedupDefaultPolicyEnvVar, err)
+		policy = DedupMax
+	}
+
+	rd, err := NewResultDeduper(env.GetPrometheusDedupIgnoreLabels(), policy, env.GetPrometheusDedupPolicies())
+	if err != nil {
+		log.Errorf("Prometheus: invalid $%s: %s; results will not be deduplicated", env.PrometheusDedupPoliciesEnvVar, err)
+		return nil
+	}
+	return rd
+}
@@ -0,0 +1,114 @@
+package prom
+
+import (
+	"testing"
+
+	"github.com/opencost/opencost/pkg/util"
+)
+
+func dedupTestResults() []*QueryResult {
+	return []*QueryResult{
+		{
+			Metric: map[string]interface{}{"namespace": "ns1", "pod": "pod1", "job": "kubelet", "instance": "10.0.0.1"},
+			Values: []*util.Vector{{Timestamp: 1, Value: 2}, {Timestamp: 2, Value: 4}},
+		},
+		{
+			Metric: map[string]interface{}{"namespace": "ns1", "pod": "pod2", "job": "kubelet", "instance": "10.0.0.1"},
+			Values: []*util.Vector{{Timestamp: 1, Value: 1}},
+		},
+		{
+			Metric: map[string]interface{}{"namespace": "ns1", "pod": "pod1", "job": "cadvisor", "instance": "10.0.0.2"},
+			Values: []*util.Vector{{Timestamp: 1, Value: 4}, {Timestamp: 3, Value: 6}},
+		},
+	}
+}
+
+func TestResultDeduper_Dedup(t *testing.T) {
+	rd, err := NewResultDeduper([]string{"job", "instance"}, DedupMax, []string{"container_cpu_usage_seconds_total=avg"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	cases := map[string]struct {
+		query    string
+		expected []*util.Vector
+	}{
+		"max": {
+			query:    `avg_over_time(container_memory_working_set_bytes[1h])`,
+			expected: []*util.Vector{{Timestamp: 1, Value: 4}, {Timestamp: 2, Value: 4}, {Timestamp: 3, Value: 6}},
+		},
+		"avg": {
+			query:    `rate(container_cpu_usage_seconds_total[5m])`,
+			expected: []*util.Vector{{Timestamp: 1, Value: 3}, {Timestamp: 2, Value: 4}, {Timestamp: 3, Value: 6}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			results := rd.Dedup(tc.query, dedupTestResults())
+			if len(results) != 2 {
+				t.Fatalf("expected 2 results; got %d", len(results))
+			}
+
+			// the collapsed series keeps the position and labels of the first
+			if pod, _ := results[0].GetString("pod"); pod != "pod1" {
+				t.Fatalf("expected pod1 first; got %s", pod)
+			}
+			if job, _ := results[0].GetString("job"); job != "kubelet" {
+				t.Errorf("expected labels of the first series; got job %s", job)
+			}
+
+			values := results[0].Values
+			if len(values) != len(tc.expected) {
+				t.Fatalf("expected %d values; got %d", len(tc.expected), len(values))
+			}
+			for i, v := range values {
+				if v.Timestamp != tc.expected[i].Timestamp || v.Value != tc.expected[i].Value {
+					t.Errorf("expected %v at %d; got %v", tc.expected[i], i, v)
+				}
+			}
+
+			if len(results[1].Values) != 1 || results[1].Values[0].Value != 1 {
+				t.Errorf("expected pod2 unchanged; got %v", results[1].Values)
+			}
+		})
+	}
+}
+
+func TestNewResultDeduper_InvalidRule(t *testing.T) {
+	for _, rule := range []string{"container_cpu_usage_seconds_total", "=max", "container_cpu_usage_seconds_total=sum"} {
+		if _, err := NewResultDeduper([]string{"job"}, DedupMax, []string{rule}); err == nil {
+			t.Errorf("expected error for rule '%s'", rule)
+		}
+	}
+}
+
+func TestSetResultDeduper(t *testing.T) {
+	defer SetResultDeduper(currentResultDeduper())
+
+	rd, err := NewResultDeduper([]string{"job"}, DedupMax, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	raw := map[string]interface{}{
+		"status": "success",
+		"data": map[string]interface{}{
+			"resultType": "vector",
+			"result": []interface{}{
+				map[string]interface{}{"metric": map[string]interface{}{"pod": "pod1", "job": "kubelet"}, "value": []interface{}{1677628800.0, "1"}},
+				map[string]interface{}{"metric": map[string]interface{}{"pod": "pod1", "job": "cadvisor"}, "value": []interface{}{1677628800.0, "2"}},
+			},
+		},
+	}
+
+	SetResultDeduper(rd)
+	if qrs := NewQueryResults("query", raw); len(qrs.Results) != 1 || qrs.Results[0].Values[0].Value != 2 {
+		t.Errorf("expected the duplicate series to be collapsed to their max; got %+v", qrs.Results)
+	}
+
+	SetResultDeduper(nil)
+	if qrs := NewQueryResults("query", raw); len(qrs.Results) != 2 {
+		t.Errorf("expected both series without deduplication; got %d", len(qrs.Results))
+	}
+}
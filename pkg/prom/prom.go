@@ -2,9 +2,7 @@ package prom
 
 import (
 	"context"
-	"crypto/tls"
 	"fmt"
-	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -300,7 +298,9 @@ func (rlpc *RateLimitedPrometheusClient) worker() {
 
 		// Decrement outbound counter
 		rlpc.outbound.Add(-1)
-		LogQueryRequest(rlpc.fileLogger, req, timeInQueue, time.Since(roundTripStart))
+		sendTime := time.Since(roundTripStart)
+		LogQueryRequest(rlpc.fileLogger, req, timeInQueue, sendTime)
+		recordSlowQuery(rlpc.id, we, timeInQueue, sendTime, err)
 
 		// Pass back response data over channel to caller
 		we.respChan <- &workResponse{
@@ -353,22 +353,19 @@ type PrometheusClientConfig struct {
 	Auth                  *ClientAuth
 	QueryConcurrency      int
 	QueryLogFile          string
+
+	// Transport settings, which are the defaults of http.Transport if zero
+	MaxIdleConns          int
+	MaxIdleConnsPerHost   int
+	IdleConnTimeout       time.Duration
+	ResponseHeaderTimeout time.Duration
+	HTTP2                 bool
 }
 
 // NewPrometheusClient creates a new rate limited client which limits by outbound concurrent requests.
 func NewPrometheusClient(address string, config *PrometheusClientConfig) (prometheus.Client, error) {
 	// may be necessary for long prometheus queries
-	rt := httputil.NewUserAgentTransport(UserAgent, &http.Transport{
-		Proxy: http.ProxyFromEnvironment,
-		DialContext: (&net.Dialer{
-			Timeout:   config.Timeout,
-			KeepAlive: config.KeepAlive,
-		}).DialContext,
-		TLSHandshakeTimeout: config.TLSHandshakeTimeout,
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: config.TLSInsecureSkipVerify,
-		},
-	})
+	rt := httputil.NewUserAgentTransport(UserAgent, NewTransport(PrometheusClientID, config))
 	pc := prometheus.Config{
 		Address:      address,
 		RoundTripper: rt,
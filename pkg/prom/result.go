@@ -6,10 +6,42 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/opencost/opencost/pkg/env"
 	"github.com/opencost/opencost/pkg/log"
 	"github.com/opencost/opencost/pkg/util"
 )
 
+// scopedNamespaces, if not empty, are the namespaces OpenCost is restricted
+// to. Results labeled with any other namespace are discarded; results without
+// a namespace label, such as node costs, are kept.
+var scopedNamespaces = namespaceSet(env.GetWatchNamespaces())
+
+func namespaceSet(namespaces []string) map[string]bool {
+	if len(namespaces) == 0 {
+		return nil
+	}
+
+	set := make(map[string]bool, len(namespaces))
+	for _, ns := range namespaces {
+		set[ns] = true
+	}
+	return set
+}
+
+// inScope returns true if the metric has no namespace label or its namespace is
+// one of the scoped namespaces.
+func inScope(metric map[string]interface{}) bool {
+	if len(scopedNamespaces) == 0 {
+		return true
+	}
+
+	ns, ok := metric["namespace"].(string)
+	if !ok || ns == "" {
+		return true
+	}
+	return scopedNamespaces[ns]
+}
+
 var (
 	// Static Warnings for data point parsing
 	InfWarning warning = newWarning("Found Inf value parsing vector data point for metric")
@@ -153,6 +185,9 @@ func NewQueryResults(query string, queryResult interface{}) *QueryResults {
 			qrs.Error = MetricFieldFormatErr(query)
 			return qrs
 		}
+		if !inScope(metricMap) {
+			continue
+		}
 
 		// Define label string for values to ensure that we only run labelsForMetric once
 		// if we receive multiple warnings.
@@ -211,6 +246,10 @@ func NewQueryResults(query string, queryResult interface{}) *QueryResults {
 		})
 	}
 
+	if rd := currentResultDeduper(); rd != nil {
+		results = rd.Dedup(query, results)
+	}
+
 	qrs.Results = results
 	return qrs
 }
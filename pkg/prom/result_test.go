@@ -0,0 +1,48 @@
+package prom
+
+import (
+	"testing"
+
+	"github.com/opencost/opencost/pkg/util/json"
+)
+
+func TestNewQueryResults_ScopedNamespaces(t *testing.T) {
+	defer func(namespaces map[string]bool) { scopedNamespaces = namespaces }(scopedNamespaces)
+	scopedNamespaces = namespaceSet([]string{"tenant-a", "tenant-b"})
+
+	raw := `{"status":"success","data":{"resultType":"vector","result":[
+		{"metric":{"namespace":"tenant-a","pod":"a"},"value":[1677628800,"1"]},
+		{"metric":{"namespace":"tenant-c","pod":"c"},"value":[1677628800,"1"]},
+		{"metric":{"namespace":"tenant-b","pod":"b"},"value":[1677628800,"1"]},
+		{"metric":{"node":"node1"},"value":[1677628800,"1"]}
+	]}}`
+
+	var queryResult interface{}
+	if err := json.Unmarshal([]byte(raw), &queryResult); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	qrs := NewQueryResults("query", queryResult)
+	if qrs.Error != nil {
+		t.Fatalf("unexpected error: %s", qrs.Error)
+	}
+
+	var got []string
+	for _, result := range qrs.Results {
+		if ns, ok := result.Metric["namespace"]; ok {
+			got = append(got, ns.(string))
+		} else {
+			got = append(got, result.Metric["node"].(string))
+		}
+	}
+
+	expected := []string{"tenant-a", "tenant-b", "node1"}
+	if len(got) != len(expected) {
+		t.Fatalf("expected results %v; got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("expected results %v; got %v", expected, got)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package prom
+
+import (
+	"sync"
+	"time"
+
+	"github.com/opencost/opencost/pkg/env"
+)
+
+// maxSlowQueries is the number of most recent slow queries retained.
+const maxSlowQueries = 100
+
+// SlowQuery describes a Prometheus query which took at least the slow query
+// threshold to complete, including time spent waiting in the request queue.
+type SlowQuery struct {
+	ClientID    string        `json:"clientId"`
+	ContextName string        `json:"contextName"`
+	Query       string        `json:"query"`
+	Start       time.Time     `json:"start"`
+	QueueTime   time.Duration `json:"queueTime"`
+	SendTime    time.Duration `json:"sendTime"`
+	Error       string        `json:"error,omitempty"`
+}
+
+// slowQueryLog is a fixed size ring buffer of the most recent slow queries.
+type slowQueryLog struct {
+	lock    sync.Mutex
+	queries []SlowQuery
+	next    int
+}
+
+var slowQueries = &slowQueryLog{}
+
+func (sl *slowQueryLog) add(q SlowQuery) {
+	sl.lock.Lock()
+	defer sl.lock.Unlock()
+
+	if len(sl.queries) < maxSlowQueries {
+		sl.queries = append(sl.queries, q)
+		return
+	}
+	sl.queries[sl.next] = q
+	sl.next = (sl.next + 1) % maxSlowQueries
+}
+
+func (sl *slowQueryLog) recent() []SlowQuery {
+	sl.lock.Lock()
+	defer sl.lock.Unlock()
+
+	// newest first
+	recent := make([]SlowQuery, 0, len(sl.queries))
+	for i := len(sl.queries) - 1; i >= 0; i-- {
+		recent = append(recent, sl.queries[(sl.next+i)%len(sl.queries)])
+	}
+	return recent
+}
+
+// recordSlowQuery retains the query if its total duration reaches the slow
+// query threshold.
+func recordSlowQuery(clientID string, we *workRequest, queueTime, sendTime time.Duration, err error) {
+	threshold := env.GetPrometheusSlowQueryThreshold()
+	if threshold <= 0 || queueTime+sendTime < threshold {
+		return
+	}
+
+	q := SlowQuery{
+		ClientID:    clientID,
+		ContextName: we.contextName,
+		Query:       we.query,
+		Start:       we.start,
+		QueueTime:   queueTime,
+		SendTime:    sendTime,
+	}
+	if err != nil {
+		q.Error = err.Error()
+	}
+	slowQueries.add(q)
+}
+
+// RecentSlowQueries returns the most recent slow queries of all clients,
+// newest first.
+func RecentSlowQueries() []SlowQuery {
+	return slowQueries.recent()
+}
@@ -0,0 +1,75 @@
+package prom
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	connectionMetricsOnce sync.Once
+
+	connectionsTotal *prometheus.CounterVec
+)
+
+// initConnectionMetrics registers the connection metrics with the default
+// registry.
+func initConnectionMetrics() {
+	connectionMetricsOnce.Do(func() {
+		connectionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "opencost_prometheus_client_connections_total",
+			Help: "opencost_prometheus_client_connections_total Number of connections obtained for queries, by whether an idle connection was reused",
+		}, []string{"client", "reused"})
+
+		prometheus.MustRegister(connectionsTotal)
+	})
+}
+
+// connectionTracingTransport records whether each request it sends reuses an
+// idle connection, or must open a new one.
+type connectionTracingTransport struct {
+	id   string
+	base http.RoundTripper
+}
+
+func (ctt *connectionTracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			connectionsTotal.WithLabelValues(ctt.id, strconv.FormatBool(info.Reused)).Inc()
+		},
+	}
+	return ctt.base.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+}
+
+// NewTransport creates the transport of the client with the id, configured by
+// the config, which records whether each query reuses a connection.
+func NewTransport(id string, config *PrometheusClientConfig) http.RoundTripper {
+	initConnectionMetrics()
+
+	return &connectionTracingTransport{
+		id: id,
+		base: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   config.Timeout,
+				KeepAlive: config.KeepAlive,
+			}).DialContext,
+			TLSHandshakeTimeout: config.TLSHandshakeTimeout,
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: config.TLSInsecureSkipVerify,
+			},
+			MaxIdleConns:          config.MaxIdleConns,
+			MaxIdleConnsPerHost:   config.MaxIdleConnsPerHost,
+			IdleConnTimeout:       config.IdleConnTimeout,
+			ResponseHeaderTimeout: config.ResponseHeaderTimeout,
+			// A transport with a custom dialer or TLS config only negotiates
+			// HTTP/2 if forced to
+			ForceAttemptHTTP2: config.HTTP2,
+		},
+	}
+}
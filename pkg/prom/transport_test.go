@@ -0,0 +1,53 @@
+package prom
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+func connectionCount(t *testing.T, id string, reused string) float64 {
+	t.Helper()
+
+	m := &dto.Metric{}
+	if err := connectionsTotal.WithLabelValues(id, reused).Write(m); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return m.GetCounter().GetValue()
+}
+
+func TestNewTransport_ConnectionReuse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"success"}`))
+	}))
+	defer server.Close()
+
+	id := "TestNewTransport_ConnectionReuse"
+	rt := NewTransport(id, &PrometheusClientConfig{
+		Timeout:             time.Second,
+		KeepAlive:           time.Second,
+		MaxIdleConnsPerHost: 1,
+	})
+	client := &http.Client{Transport: rt}
+
+	// sequential requests reuse the idle connection of the first
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	if n := connectionCount(t, id, "false"); n != 1 {
+		t.Errorf("expected 1 new connection; got %f", n)
+	}
+	if n := connectionCount(t, id, "true"); n != 2 {
+		t.Errorf("expected 2 reused connections; got %f", n)
+	}
+}
@@ -0,0 +1,494 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/log"
+)
+
+var (
+	boltAllocationBucket = []byte("allocations")
+	boltAssetBucket      = []byte("assets")
+	boltCheckpointBucket = []byte("checkpoints")
+)
+
+// BoltConfig contains the file and retention options of a BoltStore.
+type BoltConfig struct {
+	// Path is the database file, which should be on a persistent volume.
+	Path string
+
+	// MaxSize is the total size, in bytes, of the stored windows beyond which
+	// the oldest windows are pruned. Windows are never pruned by size if zero.
+	// Space freed by pruning is reused, so the file does not grow beyond
+	// roughly this size, but it does not shrink.
+	MaxSize int64
+
+	RetentionConfig
+}
+
+// BoltStore is an embedded store for single-cluster deployments which cannot
+// run PostgreSQL. Each window's allocation and asset sets are stored in the
+// bingen binary encoding, keyed by window so that keys sort by start.
+type BoltStore struct {
+	db     *bolt.DB
+	config *BoltConfig
+}
+
+// NewBoltStore opens or creates the database file.
+func NewBoltStore(config *BoltConfig) (*BoltStore, error) {
+	if config.Path == "" {
+		return nil, fmt.Errorf("bolt store: path is required")
+	}
+
+	db, err := bolt.Open(config.Path, 0600, &bolt.Options{Timeout: 10 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("bolt store: opening %s: %w", config.Path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{boltAllocationBucket, boltAssetBucket, boltCheckpointBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("bolt store: creating buckets: %w", err)
+	}
+
+	return &BoltStore{
+		db:     db,
+		config: config,
+	}, nil
+}
+
+// boltKey encodes a window as its big-endian start and end in Unix seconds,
+// so that keys sort by start, then end.
+func boltKey(start, end time.Time) []byte {
+	key := make([]byte, 16)
+	binary.BigEndian.PutUint64(key[:8], uint64(start.Unix()))
+	binary.BigEndian.PutUint64(key[8:], uint64(end.Unix()))
+	return key
+}
+
+func boltWindow(key []byte) kubecost.Window {
+	start := time.Unix(int64(binary.BigEndian.Uint64(key[:8])), 0).UTC()
+	end := time.Unix(int64(binary.BigEndian.Uint64(key[8:])), 0).UTC()
+	return kubecost.NewClosedWindow(start, end)
+}
+
+// boltCheckpointPrefix returns the prefix of the keys of the checkpointed steps
+// of the window computed at the resolution: the window followed by the
+// resolution in seconds.
+func boltCheckpointPrefix(window kubecost.Window, resolution time.Duration) []byte {
+	prefix := make([]byte, 24)
+	copy(prefix, boltKey(*window.Start(), *window.End()))
+	binary.BigEndian.PutUint64(prefix[16:], uint64(resolution.Seconds()))
+	return prefix
+}
+
+// Name returns the name of the store.
+func (bs *BoltStore) Name() string {
+	return "bolt"
+}
+
+// ExportAllocations stores the allocations of a finalized window.
+func (bs *BoltStore) ExportAllocations(ctx context.Context, window kubecost.Window, as *kubecost.AllocationSet) error {
+	data, err := as.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("encoding allocations for %s: %w", window, err)
+	}
+
+	return bs.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltAllocationBucket).Put(boltKey(*window.Start(), *window.End()), data)
+	})
+}
+
+// ExportAssets stores the assets of a finalized window.
+func (bs *BoltStore) ExportAssets(ctx context.Context, window kubecost.Window, as *kubecost.AssetSet) error {
+	data, err := as.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("encoding assets for %s: %w", window, err)
+	}
+
+	return bs.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltAssetBucket).Put(boltKey(*window.Start(), *window.End()), data)
+	})
+}
+
+// QueryAllocations returns the stored allocation sets contained in [start, end).
+func (bs *BoltStore) QueryAllocations(ctx context.Context, start, end time.Time) ([]*kubecost.AllocationSet, error) {
+	var sets []*kubecost.AllocationSet
+	err := bs.db.View(func(tx *bolt.Tx) error {
+		return eachWindow(tx.Bucket(boltAllocationBucket), start, end, func(window kubecost.Window, data []byte) error {
+			as := &kubecost.AllocationSet{}
+			if err := as.UnmarshalBinary(data); err != nil {
+				return fmt.Errorf("decoding allocations for %s: %w", window, err)
+			}
+			sets = append(sets, as)
+			return nil
+		})
+	})
+	return sets, err
+}
+
+// QueryAssets returns the stored asset sets contained in [start, end).
+func (bs *BoltStore) QueryAssets(ctx context.Context, start, end time.Time) ([]*kubecost.AssetSet, error) {
+	var sets []*kubecost.AssetSet
+	err := bs.db.View(func(tx *bolt.Tx) error {
+		return eachWindow(tx.Bucket(boltAssetBucket), start, end, func(window kubecost.Window, data []byte) error {
+			as := &kubecost.AssetSet{}
+			if err := as.UnmarshalBinary(data); err != nil {
+				return fmt.Errorf("decoding assets for %s: %w", window, err)
+			}
+			sets = append(sets, as)
+			return nil
+		})
+	})
+	return sets, err
+}
+
+// HasWindow returns true if both the allocations and assets of the window are
+// stored, either under the window itself or under the daily window it was
+// rolled up into.
+func (bs *BoltStore) HasWindow(ctx context.Context, window kubecost.Window) (bool, error) {
+	keys := [][]byte{boltKey(*window.Start(), *window.End())}
+	if window.Duration() < day {
+		dayStart := window.Start().UTC().Truncate(day)
+		if dayEnd := dayStart.Add(day); !window.End().After(dayEnd) {
+			keys = append(keys, boltKey(dayStart, dayEnd))
+		}
+	}
+
+	exists := false
+	err := bs.db.View(func(tx *bolt.Tx) error {
+		for _, key := range keys {
+			if tx.Bucket(boltAllocationBucket).Get(key) != nil && tx.Bucket(boltAssetBucket).Get(key) != nil {
+				exists = true
+				break
+			}
+		}
+		return nil
+	})
+	return exists, err
+}
+
+// eachWindow calls f for each window of the bucket contained in [start, end),
+// in order of start. The data is only valid for the duration of f.
+func eachWindow(bucket *bolt.Bucket, start, end time.Time, f func(kubecost.Window, []byte) error) error {
+	c := bucket.Cursor()
+	last := boltKey(end, end)
+	for k, v := c.Seek(boltKey(start, start)); k != nil && bytes.Compare(k, last) <= 0; k, v = c.Next() {
+		window := boltWindow(k)
+		if window.End().After(end) {
+			continue
+		}
+		if err := f(window, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveCheckpoint stores the allocations of a step of the window computed at
+// the resolution, keyed by the window and resolution followed by the step. The
+// value is prefixed by the time it was saved, so that checkpoints which are
+// never resumed expire.
+func (bs *BoltStore) SaveCheckpoint(ctx context.Context, window kubecost.Window, resolution time.Duration, as *kubecost.AllocationSet) error {
+	data, err := as.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("encoding checkpoint of %s for %s: %w", as.Window, window, err)
+	}
+
+	key := append(boltCheckpointPrefix(window, resolution), boltKey(as.Start(), as.End())...)
+	value := make([]byte, 8+len(data))
+	binary.BigEndian.PutUint64(value[:8], uint64(time.Now().Unix()))
+	copy(value[8:], data)
+
+	return bs.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltCheckpointBucket).Put(key, value)
+	})
+}
+
+// LoadCheckpoints returns the stored steps of the window computed at the
+// resolution, ordered by start.
+func (bs *BoltStore) LoadCheckpoints(ctx context.Context, window kubecost.Window, resolution time.Duration) ([]*kubecost.AllocationSet, error) {
+	prefix := boltCheckpointPrefix(window, resolution)
+
+	var sets []*kubecost.AllocationSet
+	err := bs.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(boltCheckpointBucket).Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			as := &kubecost.AllocationSet{}
+			if err := as.UnmarshalBinary(v[8:]); err != nil {
+				return fmt.Errorf("decoding checkpoint of %s for %s: %w", boltWindow(k[len(prefix):]), window, err)
+			}
+			sets = append(sets, as)
+		}
+		return nil
+	})
+	return sets, err
+}
+
+// ClearCheckpoints deletes the stored steps of the window computed at the
+// resolution.
+func (bs *BoltStore) ClearCheckpoints(ctx context.Context, window kubecost.Window, resolution time.Duration) error {
+	prefix := boltCheckpointPrefix(window, resolution)
+
+	return bs.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltCheckpointBucket)
+
+		// collect keys first, as deleting while iterating skips keys
+		var keys [][]byte
+		c := bucket.Cursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			keys = append(keys, append([]byte(nil), k...))
+		}
+
+		for _, k := range keys {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// deleteCheckpointsBefore deletes checkpoints saved before the cutoff.
+func (bs *BoltStore) deleteCheckpointsBefore(cutoff time.Time) (int, error) {
+	n := 0
+	err := bs.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltCheckpointBucket)
+
+		var keys [][]byte
+		err := bucket.ForEach(func(k, v []byte) error {
+			if int64(binary.BigEndian.Uint64(v[:8])) < cutoff.Unix() {
+				keys = append(keys, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, k := range keys {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+			n++
+		}
+		return nil
+	})
+	return n, err
+}
+
+// Compact deletes windows beyond retention, rolls up sub-daily windows older
+// than the rollup period into daily windows, then prunes the oldest windows
+// until the store is within its maximum size. Checkpoints which were never
+// resumed are deleted.
+func (bs *BoltStore) Compact(ctx context.Context, now time.Time) error {
+	n, err := bs.deleteCheckpointsBefore(now.Add(-checkpointTTL))
+	if err != nil {
+		return fmt.Errorf("deleting expired checkpoints: %w", err)
+	}
+	if n > 0 {
+		log.Infof("BoltStore: deleted %d checkpoints which were not resumed", n)
+	}
+
+	if bs.config.Retention > 0 {
+		cutoff := now.Add(-bs.config.Retention)
+		n, err := bs.deleteWhile(func(window kubecost.Window, _ int64) bool {
+			return !window.End().After(cutoff)
+		})
+		if err != nil {
+			return fmt.Errorf("deleting expired windows: %w", err)
+		}
+		if n > 0 {
+			log.Infof("BoltStore: deleted %d windows ending before %s", n, cutoff.Format(time.RFC3339))
+		}
+	}
+
+	if bs.config.RollupAfter > 0 {
+		if err := bs.rollup(ctx, now.Add(-bs.config.RollupAfter)); err != nil {
+			return err
+		}
+	}
+
+	if bs.config.MaxSize > 0 {
+		size, err := bs.size()
+		if err != nil {
+			return err
+		}
+
+		n, err := bs.deleteWhile(func(_ kubecost.Window, windowSize int64) bool {
+			if size <= bs.config.MaxSize {
+				return false
+			}
+			size -= windowSize
+			return true
+		})
+		if err != nil {
+			return fmt.Errorf("pruning windows: %w", err)
+		}
+		if n > 0 {
+			log.Infof("BoltStore: pruned %d windows to remain within %d bytes", n, bs.config.MaxSize)
+		}
+	}
+
+	return nil
+}
+
+// size returns the total size of the stored windows.
+func (bs *BoltStore) size() (int64, error) {
+	var size int64
+	err := bs.db.View(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{boltAllocationBucket, boltAssetBucket} {
+			err := tx.Bucket(bucket).ForEach(func(k, v []byte) error {
+				size += int64(len(k) + len(v))
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return size, err
+}
+
+// deleteWhile deletes windows, oldest first, while f returns true. The
+// allocations and assets of a window are deleted together, and f is passed
+// their combined size.
+func (bs *BoltStore) deleteWhile(f func(kubecost.Window, int64) bool) (int, error) {
+	n := 0
+	err := bs.db.Update(func(tx *bolt.Tx) error {
+		allocs, assets := tx.Bucket(boltAllocationBucket), tx.Bucket(boltAssetBucket)
+
+		// collect keys first, as deleting while iterating skips keys
+		sizes := make(map[string]int64)
+		for _, bucket := range []*bolt.Bucket{allocs, assets} {
+			err := bucket.ForEach(func(k, v []byte) error {
+				sizes[string(k)] += int64(len(k) + len(v))
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		keys := make([]string, 0, len(sizes))
+		for key := range sizes {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			k := []byte(key)
+			if !f(boltWindow(k), sizes[key]) {
+				break
+			}
+			if err := allocs.Delete(k); err != nil {
+				return err
+			}
+			if err := assets.Delete(k); err != nil {
+				return err
+			}
+			n++
+		}
+		return nil
+	})
+	return n, err
+}
+
+// rollup replaces the sub-daily windows of each fully covered day ending
+// before the cutoff with a single daily window.
+func (bs *BoltStore) rollup(ctx context.Context, cutoff time.Time) error {
+	var windows []kubecost.Window
+	err := bs.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltAllocationBucket).ForEach(func(k, _ []byte) error {
+			if tx.Bucket(boltAssetBucket).Get(k) != nil {
+				windows = append(windows, boltWindow(k))
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("listing windows: %w", err)
+	}
+
+	for _, window := range RollupDays(windows, cutoff) {
+		start, end := *window.Start(), *window.End()
+
+		allocSets, err := bs.QueryAllocations(ctx, start, end)
+		if err != nil {
+			return err
+		}
+		allocSet, ok, err := AccumulateAllocations(allocSets, start, end)
+		if err != nil || !ok {
+			return err
+		}
+		allocData, err := allocSet.MarshalBinary()
+		if err != nil {
+			return fmt.Errorf("encoding allocations for %s: %w", window, err)
+		}
+
+		assetSets, err := bs.QueryAssets(ctx, start, end)
+		if err != nil {
+			return err
+		}
+		assetSet, ok, err := AccumulateAssets(assetSets, start, end)
+		if err != nil || !ok {
+			return err
+		}
+		assetData, err := assetSet.MarshalBinary()
+		if err != nil {
+			return fmt.Errorf("encoding assets for %s: %w", window, err)
+		}
+
+		err = bs.db.Update(func(tx *bolt.Tx) error {
+			for bucket, data := range map[string][]byte{string(boltAllocationBucket): allocData, string(boltAssetBucket): assetData} {
+				b := tx.Bucket([]byte(bucket))
+
+				var keys [][]byte
+				if err := eachWindow(b, start, end, func(w kubecost.Window, _ []byte) error {
+					keys = append(keys, boltKey(*w.Start(), *w.End()))
+					return nil
+				}); err != nil {
+					return err
+				}
+				for _, key := range keys {
+					if err := b.Delete(key); err != nil {
+						return err
+					}
+				}
+
+				if err := b.Put(boltKey(start, end), data); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("rolling up %s: %w", window, err)
+		}
+
+		log.Infof("BoltStore: rolled up %s into a daily window", window)
+	}
+
+	return nil
+}
+
+// Close closes the database file.
+func (bs *BoltStore) Close() error {
+	return bs.db.Close()
+}
@@ -0,0 +1,187 @@
+package store
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+)
+
+func newTestBoltStore(t *testing.T, config *BoltConfig) *BoltStore {
+	config.Path = filepath.Join(t.TempDir(), "opencost.db")
+	bs, err := NewBoltStore(config)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	t.Cleanup(func() { bs.Close() })
+	return bs
+}
+
+// exportHours exports a unit allocation and an empty asset set for each hour
+// of the day starting at start.
+func exportHours(t *testing.T, s Store, start time.Time, hours int) float64 {
+	ctx := context.Background()
+
+	total := 0.0
+	for h := 0; h < hours; h++ {
+		s1, e1 := start.Add(time.Duration(h)*time.Hour), start.Add(time.Duration(h+1)*time.Hour)
+		window := kubecost.NewClosedWindow(s1, e1)
+
+		as := kubecost.NewAllocationSet(s1, e1)
+		as.Set(kubecost.NewMockUnitAllocation("", s1, time.Hour, nil))
+		total += as.TotalCost()
+
+		if err := s.ExportAllocations(ctx, window, as); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if err := s.ExportAssets(ctx, window, kubecost.NewAssetSet(s1, e1)); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	return total
+}
+
+func TestBoltStore_Rollup(t *testing.T) {
+	ctx := context.Background()
+	day1 := time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.Add(day)
+
+	bs := newTestBoltStore(t, &BoltConfig{
+		RetentionConfig: RetentionConfig{RollupAfter: 2 * day},
+	})
+
+	total := exportHours(t, bs, day1, 24)
+	exportHours(t, bs, day2, 24)
+
+	// only day1 is older than the rollup period
+	if err := bs.Compact(ctx, day2.Add(2*day)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	sets, err := bs.QueryAllocations(ctx, day1, day2)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(sets) != 1 || !sets[0].Window.Equal(kubecost.NewClosedWindow(day1, day2)) {
+		t.Fatalf("expected a single daily set; got %d sets", len(sets))
+	}
+	if math.Abs(sets[0].TotalCost()-total) > 1e-9 {
+		t.Errorf("expected total cost %f; got %f", total, sets[0].TotalCost())
+	}
+
+	sets, err = bs.QueryAllocations(ctx, day2, day2.Add(day))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(sets) != 24 {
+		t.Errorf("expected 24 hourly sets; got %d", len(sets))
+	}
+
+	// rolled up windows are still reported as stored, so they are not
+	// exported again
+	for _, w := range []kubecost.Window{
+		kubecost.NewClosedWindow(day1.Add(time.Hour), day1.Add(2*time.Hour)),
+		kubecost.NewClosedWindow(day1, day2),
+		kubecost.NewClosedWindow(day2, day2.Add(time.Hour)),
+	} {
+		if exists, err := bs.HasWindow(ctx, w); err != nil || !exists {
+			t.Errorf("expected %s to be stored; got %t, %v", w, exists, err)
+		}
+	}
+	day3 := day2.Add(day)
+	if exists, _ := bs.HasWindow(ctx, kubecost.NewClosedWindow(day3, day3.Add(time.Hour))); exists {
+		t.Errorf("expected window which was never exported not to be stored")
+	}
+}
+
+func TestBoltStore_MaxSize(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	bs := newTestBoltStore(t, &BoltConfig{})
+	exportHours(t, bs, start, 4)
+
+	size, err := bs.size()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// allow roughly half of the windows to remain
+	bs.config.MaxSize = size / 2
+	if err := bs.Compact(ctx, start.Add(day)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	sets, err := bs.QueryAllocations(ctx, start, start.Add(day))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(sets) != 2 {
+		t.Fatalf("expected 2 sets to remain; got %d", len(sets))
+	}
+	if !sets[0].Window.Start().Equal(start.Add(2 * time.Hour)) {
+		t.Errorf("expected the oldest windows to be pruned; first remaining window is %s", sets[0].Window)
+	}
+}
+
+func TestBoltStore_Checkpoints(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)
+	window := kubecost.NewClosedWindow(start, start.Add(3*day))
+	other := kubecost.NewClosedWindow(start, start.Add(2*day))
+	resolution := 5 * time.Minute
+
+	bs := newTestBoltStore(t, &BoltConfig{})
+
+	// save the steps out of order, along with a step of another window and a
+	// step of the window computed at another resolution
+	for _, d := range []int{1, 0} {
+		s, e := start.Add(time.Duration(d)*day), start.Add(time.Duration(d+1)*day)
+		as := kubecost.NewAllocationSet(s, e)
+		as.Set(kubecost.NewMockUnitAllocation("", s, day, nil))
+		if err := bs.SaveCheckpoint(ctx, window, resolution, as); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	if err := bs.SaveCheckpoint(ctx, other, resolution, kubecost.NewAllocationSet(start, start.Add(day))); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := bs.SaveCheckpoint(ctx, window, time.Hour, kubecost.NewAllocationSet(start.Add(2*day), start.Add(3*day))); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	sets, err := bs.LoadCheckpoints(ctx, window, resolution)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(sets) != 2 || !sets[0].Start().Equal(start) || !sets[1].Start().Equal(start.Add(day)) {
+		t.Fatalf("expected 2 steps ordered by start; got %d", len(sets))
+	}
+	if sets[0].Length() != 1 {
+		t.Errorf("expected 1 allocation in the step; got %d", sets[0].Length())
+	}
+
+	if err := bs.ClearCheckpoints(ctx, window, resolution); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if sets, _ := bs.LoadCheckpoints(ctx, window, resolution); len(sets) != 0 {
+		t.Errorf("expected no steps once cleared; got %d", len(sets))
+	}
+	if sets, _ := bs.LoadCheckpoints(ctx, other, resolution); len(sets) != 1 {
+		t.Errorf("expected the steps of other windows to be kept; got %d", len(sets))
+	}
+	if sets, _ := bs.LoadCheckpoints(ctx, window, time.Hour); len(sets) != 1 {
+		t.Errorf("expected the steps of other resolutions to be kept; got %d", len(sets))
+	}
+
+	// checkpoints which are not resumed expire
+	if err := bs.Compact(ctx, time.Now().Add(checkpointTTL+time.Hour)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if sets, _ := bs.LoadCheckpoints(ctx, other, resolution); len(sets) != 0 {
+		t.Errorf("expected expired steps to be deleted; got %d", len(sets))
+	}
+}
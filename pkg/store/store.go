@@ -0,0 +1,294 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/opencost/opencost/pkg/errors"
+	"github.com/opencost/opencost/pkg/kubecost"
+	"github.com/opencost/opencost/pkg/log"
+	"github.com/opencost/opencost/pkg/util/atomic"
+)
+
+// day is the duration of the windows produced by compaction
+const day = 24 * time.Hour
+
+// checkpointTTL is the age after which checkpoints of a window whose
+// computation was never resumed are deleted by compaction.
+const checkpointTTL = 7 * day
+
+// Store is a durable store of finalized allocation and asset windows, allowing
+// historical queries beyond Prometheus retention. A Store is also an exporter
+// Sink, so it is populated by adding it to the exporter.
+type Store interface {
+	// Name identifies the store, e.g. in the FromStore of query results.
+	Name() string
+
+	// ExportAllocations stores the allocations of a finalized window,
+	// replacing any previously stored for the window.
+	ExportAllocations(ctx context.Context, window kubecost.Window, as *kubecost.AllocationSet) error
+
+	// ExportAssets stores the assets of a finalized window, replacing any
+	// previously stored for the window.
+	ExportAssets(ctx context.Context, window kubecost.Window, as *kubecost.AssetSet) error
+
+	// QueryAllocations returns the stored allocation sets whose windows are
+	// contained in [start, end), ordered by start.
+	QueryAllocations(ctx context.Context, start, end time.Time) ([]*kubecost.AllocationSet, error)
+
+	// QueryAssets returns the stored asset sets whose windows are contained in
+	// [start, end), ordered by start.
+	QueryAssets(ctx context.Context, start, end time.Time) ([]*kubecost.AssetSet, error)
+
+	// HasWindow returns true if both the allocations and assets of the window
+	// are stored, including after the window has been rolled up, so that
+	// rolled up windows are not exported again.
+	HasWindow(ctx context.Context, window kubecost.Window) (bool, error)
+
+	// Compact deletes windows beyond the retention period and rolls up
+	// windows older than the rollup period into daily windows.
+	Compact(ctx context.Context, now time.Time) error
+
+	Close() error
+}
+
+// Checkpointer is implemented by stores which persist the allocations of each
+// step of a window while the window is being computed, so that a computation
+// interrupted by a restart resumes from the steps already computed rather
+// than from the start of the window.
+type Checkpointer interface {
+	// SaveCheckpoint stores the allocations of a step of the window computed
+	// at the resolution, whose start and end are those of the set.
+	SaveCheckpoint(ctx context.Context, window kubecost.Window, resolution time.Duration, as *kubecost.AllocationSet) error
+
+	// LoadCheckpoints returns the stored steps of the window computed at the
+	// resolution, ordered by start.
+	LoadCheckpoints(ctx context.Context, window kubecost.Window, resolution time.Duration) ([]*kubecost.AllocationSet, error)
+
+	// ClearCheckpoints deletes the stored steps of the window computed at the
+	// resolution, once the window is computed.
+	ClearCheckpoints(ctx context.Context, window kubecost.Window, resolution time.Duration) error
+}
+
+// RetentionConfig contains the retention and compaction options of a Store.
+type RetentionConfig struct {
+	// Retention is the age after which windows are deleted. Windows are kept
+	// forever if zero.
+	Retention time.Duration
+
+	// RollupAfter is the age after which windows shorter than a day are
+	// rolled up into daily windows. Windows are never rolled up if zero.
+	RollupAfter time.Duration
+}
+
+// Covers returns true if the windows, ordered by start, cover [start, end)
+// without gaps.
+func Covers(windows []kubecost.Window, start, end time.Time) bool {
+	next := start
+	for _, w := range windows {
+		if w.Start().After(next) {
+			return false
+		}
+		if w.End().After(next) {
+			next = *w.End()
+		}
+		if !next.Before(end) {
+			return true
+		}
+	}
+	return !next.Before(end)
+}
+
+// outermost removes the sets, ordered by start, whose windows are contained in
+// the window of another set. This prevents double counting a window which was
+// re-exported after being rolled up into a daily window.
+func outermost[T any](sets []T, window func(T) kubecost.Window) []T {
+	result := make([]T, 0, len(sets))
+	for i, set := range sets {
+		contained := false
+		for j, other := range sets {
+			if i == j {
+				continue
+			}
+			w, o := window(set), window(other)
+			if o.ContainsWindow(w) && (!w.Equal(o) || j < i) {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			result = append(result, set)
+		}
+	}
+	return result
+}
+
+// AccumulateAllocations accumulates allocation sets, ordered by start, into a
+// single set covering [start, end). Returns false if the sets do not cover the
+// window.
+func AccumulateAllocations(sets []*kubecost.AllocationSet, start, end time.Time) (*kubecost.AllocationSet, bool, error) {
+	sets = outermost(sets, func(as *kubecost.AllocationSet) kubecost.Window { return as.Window })
+
+	windows := make([]kubecost.Window, 0, len(sets))
+	for _, as := range sets {
+		windows = append(windows, as.Window)
+	}
+	if !Covers(windows, start, end) {
+		return nil, false, nil
+	}
+
+	acc := kubecost.NewAllocationSet(start, end)
+	for _, as := range sets {
+		var err error
+		acc, err = acc.Accumulate(as)
+		if err != nil {
+			return nil, false, fmt.Errorf("accumulating allocations for %s: %w", as.Window, err)
+		}
+	}
+	acc.Window = kubecost.NewClosedWindow(start, end)
+
+	return acc, true, nil
+}
+
+// AccumulateAssets accumulates asset sets, ordered by start, into a single set
+// covering [start, end). Returns false if the sets do not cover the window.
+func AccumulateAssets(sets []*kubecost.AssetSet, start, end time.Time) (*kubecost.AssetSet, bool, error) {
+	sets = outermost(sets, func(as *kubecost.AssetSet) kubecost.Window { return as.Window })
+
+	windows := make([]kubecost.Window, 0, len(sets))
+	for _, as := range sets {
+		windows = append(windows, as.Window)
+	}
+	if !Covers(windows, start, end) {
+		return nil, false, nil
+	}
+
+	acc, err := kubecost.NewAssetSetRange(sets...).AccumulateToAssetSet()
+	if err != nil {
+		return nil, false, fmt.Errorf("accumulating assets for %s: %w", kubecost.NewClosedWindow(start, end), err)
+	}
+	if acc == nil {
+		acc = kubecost.NewAssetSet(start, end)
+	}
+	acc.Window = kubecost.NewClosedWindow(start, end)
+
+	return acc, true, nil
+}
+
+// RollupDays returns the days ending before the cutoff which are fully covered
+// by windows shorter than a day, and so can be rolled up into daily windows.
+func RollupDays(windows []kubecost.Window, cutoff time.Time) []kubecost.Window {
+	byDay := make(map[time.Time][]kubecost.Window)
+	for _, w := range windows {
+		if w.Duration() >= day || w.End().After(cutoff) {
+			continue
+		}
+
+		start := w.Start().UTC().Truncate(day)
+		byDay[start] = append(byDay[start], w)
+	}
+
+	var days []kubecost.Window
+	for start, ws := range byDay {
+		end := start.Add(day)
+		if end.After(cutoff) {
+			continue
+		}
+
+		sort.Slice(ws, func(i, j int) bool {
+			return ws[i].Start().Before(*ws[j].Start())
+		})
+		if Covers(ws, start, end) {
+			days = append(days, kubecost.NewClosedWindow(start, end))
+		}
+	}
+
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Start().Before(*days[j].Start())
+	})
+	return days
+}
+
+// Checkpoints records the windows exported to a Store as the windows it
+// contains, allowing exports to the store to resume after a restart. It
+// implements the exporter's Checkpoints for exporters whose only sink is the
+// store.
+type Checkpoints struct {
+	store Store
+}
+
+// NewCheckpoints creates Checkpoints backed by the store.
+func NewCheckpoints(store Store) *Checkpoints {
+	return &Checkpoints{store: store}
+}
+
+// IsExported returns true if the store contains the window, or the daily
+// window it was rolled up into.
+func (c *Checkpoints) IsExported(sink string, window kubecost.Window) (bool, error) {
+	return c.store.HasWindow(context.Background(), window)
+}
+
+// SetExported is a no-op, as exporting a window to the store records it.
+func (c *Checkpoints) SetExported(sink string, window kubecost.Window) error {
+	return nil
+}
+
+// Compactor periodically compacts a Store.
+type Compactor struct {
+	store    Store
+	interval time.Duration
+
+	runState atomic.AtomicRunState
+}
+
+// NewCompactor creates a new Compactor for the store.
+func NewCompactor(store Store, interval time.Duration) *Compactor {
+	return &Compactor{
+		store:    store,
+		interval: interval,
+	}
+}
+
+// Start begins compacting the store at each interval. Returns false if the
+// compactor is already running.
+func (c *Compactor) Start() bool {
+	c.runState.WaitForReset()
+	if !c.runState.Start() {
+		log.Warnf("Compactor: attempted to start when already running")
+		return false
+	}
+
+	go func() {
+		defer errors.HandlePanic()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go func() {
+			<-c.runState.OnStop()
+			cancel()
+		}()
+
+		for {
+			if err := c.store.Compact(ctx, time.Now().UTC()); err != nil {
+				log.Errorf("Compactor: compacting %s: %s", c.store.Name(), err)
+			}
+
+			select {
+			case <-c.runState.OnStop():
+				c.runState.Reset()
+				return
+			case <-time.After(c.interval):
+			}
+		}
+	}()
+
+	return true
+}
+
+// Stop halts compaction.
+func (c *Compactor) Stop() {
+	c.runState.Stop()
+}
@@ -0,0 +1,103 @@
+package store
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/pkg/kubecost"
+)
+
+func TestCovers(t *testing.T) {
+	start := time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)
+	hour := func(h int) kubecost.Window {
+		return kubecost.NewClosedWindow(start.Add(time.Duration(h)*time.Hour), start.Add(time.Duration(h+1)*time.Hour))
+	}
+
+	cases := map[string]struct {
+		windows  []kubecost.Window
+		expected bool
+	}{
+		"contiguous": {[]kubecost.Window{hour(0), hour(1), hour(2)}, true},
+		"gap":        {[]kubecost.Window{hour(0), hour(2)}, false},
+		"short":      {[]kubecost.Window{hour(0), hour(1)}, false},
+		"empty":      {nil, false},
+		"overlapping": {
+			[]kubecost.Window{kubecost.NewClosedWindow(start, start.Add(2*time.Hour)), hour(1), hour(2)},
+			true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if actual := Covers(tc.windows, start, start.Add(3*time.Hour)); actual != tc.expected {
+				t.Errorf("expected %t; got %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRollupDays(t *testing.T) {
+	day1 := time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.Add(day)
+
+	var windows []kubecost.Window
+	for h := 0; h < 24; h++ {
+		windows = append(windows, kubecost.NewClosedWindow(day1.Add(time.Duration(h)*time.Hour), day1.Add(time.Duration(h+1)*time.Hour)))
+	}
+	// day2 is missing its last hour, so cannot be rolled up
+	for h := 0; h < 23; h++ {
+		windows = append(windows, kubecost.NewClosedWindow(day2.Add(time.Duration(h)*time.Hour), day2.Add(time.Duration(h+1)*time.Hour)))
+	}
+
+	days := RollupDays(windows, day2.Add(2*day))
+	if len(days) != 1 || !days[0].Equal(kubecost.NewClosedWindow(day1, day2)) {
+		t.Fatalf("expected only %s to be rolled up; got %v", kubecost.NewClosedWindow(day1, day2), days)
+	}
+
+	// nothing ending after the cutoff is rolled up
+	if days := RollupDays(windows, day1.Add(12*time.Hour)); len(days) != 0 {
+		t.Errorf("expected no days to be rolled up; got %v", days)
+	}
+}
+
+func TestAccumulateAllocations(t *testing.T) {
+	start := time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	newSet := func(s time.Time, d time.Duration) *kubecost.AllocationSet {
+		as := kubecost.NewAllocationSet(s, s.Add(d))
+		as.Set(kubecost.NewMockUnitAllocation("", s, d, nil))
+		return as
+	}
+
+	hourly := []*kubecost.AllocationSet{newSet(start, time.Hour), newSet(start.Add(time.Hour), time.Hour)}
+	expected := hourly[0].TotalCost() + hourly[1].TotalCost()
+
+	as, ok, err := AccumulateAllocations(hourly, start, start.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !ok {
+		t.Fatalf("expected sets to cover window")
+	}
+	if math.Abs(as.TotalCost()-expected) > 1e-9 {
+		t.Errorf("expected total cost %f; got %f", expected, as.TotalCost())
+	}
+	if !as.Window.Equal(kubecost.NewClosedWindow(start, start.Add(2*time.Hour))) {
+		t.Errorf("unexpected window %s", as.Window)
+	}
+
+	// an hourly window re-exported after its day was rolled up is not counted twice
+	rolledUp := []*kubecost.AllocationSet{newSet(start, 2*time.Hour), newSet(start, time.Hour)}
+	as, ok, err = AccumulateAllocations(rolledUp, start, start.Add(2*time.Hour))
+	if err != nil || !ok {
+		t.Fatalf("expected sets to cover window; got %t, %v", ok, err)
+	}
+	if math.Abs(as.TotalCost()-rolledUp[0].TotalCost()) > 1e-9 {
+		t.Errorf("expected total cost %f; got %f", rolledUp[0].TotalCost(), as.TotalCost())
+	}
+
+	if _, ok, _ := AccumulateAllocations(hourly[:1], start, start.Add(2*time.Hour)); ok {
+		t.Errorf("expected partial sets not to cover window")
+	}
+}
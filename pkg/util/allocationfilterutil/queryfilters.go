@@ -37,6 +37,7 @@ var allocationFilterFieldMap = map[string]string{
 	kubecost.AllocationNamespaceProp:      ParamFilterNamespaces,
 	kubecost.AllocationControllerKindProp: ParamFilterControllerKinds,
 	kubecost.AllocationControllerProp:     ParamFilterControllers,
+	kubecost.AllocationControllerNameProp: ParamFilterControllers,
 	kubecost.AllocationPodProp:            ParamFilterPods,
 	kubecost.AllocationContainerProp:      ParamFilterContainers,
 	kubecost.AllocationDepartmentProp:     ParamFilterDepartments,